@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/mackeh/AegisClaw/internal/config"
+	"github.com/spf13/cobra"
+)
+
+func configCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Read and edit config.yaml using dotted keys",
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "get <key>",
+		Short: "Print a config value (e.g. security.require_approval)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.LoadDefault()
+			if err != nil {
+				return err
+			}
+			val, err := cfg.Get(args[0])
+			if err != nil {
+				return err
+			}
+			fmt.Println(val)
+			return nil
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Validate and save a config value (lists are comma-separated)",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfgDir, err := config.DefaultConfigDir()
+			if err != nil {
+				return err
+			}
+			path := filepath.Join(cfgDir, "config.yaml")
+			if err := config.SetValue(path, args[0], args[1]); err != nil {
+				return err
+			}
+			fmt.Printf("✅ Set %s = %s\n", args[0], args[1])
+			fmt.Printf("   Previous config saved to %s.bak\n", path)
+			return nil
+		},
+	})
+
+	return cmd
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/mackeh/AegisClaw/internal/approval"
 	"github.com/mackeh/AegisClaw/internal/audit"
 	"github.com/mackeh/AegisClaw/internal/config"
+	"github.com/mackeh/AegisClaw/internal/guardrails"
 	"github.com/mackeh/AegisClaw/internal/llmproxy"
 	"github.com/mackeh/AegisClaw/internal/mcp"
 	"github.com/mackeh/AegisClaw/internal/policy"
@@ -52,6 +53,9 @@ Example:
 			if rateLimit != 0 {
 				gw.SetRateLimit(rateLimit)
 			}
+			if gw.Guard, err = configuredGuardrails(); err != nil {
+				return err
+			}
 
 			if engine, perr := policy.LoadDefaultPolicy(ctx); perr == nil {
 				gw.Policy = engine
@@ -117,6 +121,9 @@ loops are detected, and each call is recorded to the audit log.`,
 				Budget:        &llmproxy.Budget{MaxTokens: maxTokens, MaxCostUSD: maxCost, MaxRequests: maxRequests},
 				LoopThreshold: loopThreshold,
 			})
+			if p.Guard, err = configuredGuardrails(); err != nil {
+				return err
+			}
 			url, err := p.Start()
 			if err != nil {
 				return err
@@ -214,3 +221,13 @@ func pinsCmd() *cobra.Command {
 
 	return cmd
 }
+
+// configuredGuardrails builds a guardrails engine with the language packs
+// from guardrails.packs in config.yaml (English only without a config).
+func configuredGuardrails() (*guardrails.Engine, error) {
+	var packs []string
+	if cfg, err := config.LoadDefault(); err == nil {
+		packs = cfg.Guardrails.Packs
+	}
+	return guardrails.NewEngineWithPacks(packs)
+}
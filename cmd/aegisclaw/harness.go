@@ -71,7 +71,11 @@ optionally selecting a stronger runtime with --runtime gvisor.`,
 			}
 
 			// Audit logger (best-effort: a missing config dir shouldn't block).
-			logger, err := audit.NewLogger(filepath.Join(cfgDir, "audit", "audit.log"))
+			var sinks []config.AuditSinkConfig
+			if cfg, lerr := config.LoadDefault(); lerr == nil && cfg != nil {
+				sinks = cfg.Audit.Sinks
+			}
+			logger, err := audit.NewLoggerWithSinks(filepath.Join(cfgDir, "audit", "audit.log"), sinks)
 			if err != nil {
 				return fmt.Errorf("failed to open audit log: %w", err)
 			}
@@ -80,12 +84,19 @@ optionally selecting a stronger runtime with --runtime gvisor.`,
 			// Egress allowlist comes from config (default-deny tightening lands
 			// with the dedicated egress plane). A missing config is non-fatal.
 			var allowlist []string
-			var allowPrivate bool
-			var guardMode string
+			var allowPrivate, dlp bool
+			var guardMode, upstreamProxy string
+			var noProxy, guardPacks, blockedDomains, blockedCIDRs []string
 			if cfg, lerr := config.LoadDefault(); lerr == nil && cfg != nil {
 				allowlist = cfg.Network.Allowlist
 				allowPrivate = cfg.Network.AllowPrivateEgress
+				blockedDomains = cfg.Network.BlockedDomains
+				blockedCIDRs = cfg.Network.BlockedCIDRs
+				upstreamProxy = cfg.Network.UpstreamProxy
+				noProxy = cfg.Network.NoProxy
+				dlp = cfg.Network.DLP
 				guardMode = cfg.Guardrails.Mode
+				guardPacks = cfg.Guardrails.Packs
 			}
 
 			sup := &harness.Supervisor{
@@ -94,7 +105,13 @@ optionally selecting a stronger runtime with --runtime gvisor.`,
 				Secrets:            secrets.NewManager(filepath.Join(cfgDir, "secrets")),
 				AllowedDomains:     allowlist,
 				AllowPrivateEgress: allowPrivate,
+				BlockedDomains:     blockedDomains,
+				BlockedCIDRs:       blockedCIDRs,
+				UpstreamProxy:      upstreamProxy,
+				NoProxy:            noProxy,
+				DLP:                dlp,
 				GuardMode:          guardMode,
+				GuardPacks:         guardPacks,
 				WorkDir:            workDir,
 				Image:              image,
 
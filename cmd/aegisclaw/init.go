@@ -44,14 +44,14 @@ decision = "allow" if {
 }
 
 decision = "require_approval" if {
-	input.scope.name == "shell.exec"
+	some family in {"shell", "secrets"}
+	family in input.scope.parents
+	not unsigned_critical
 }
 
-decision = "require_approval" if {
-	input.scope.name == "secrets.access"
-}
+decision = "deny" if unsigned_critical
 
-decision = "deny" if {
+unsigned_critical if {
 	input.scope.risk == "critical"
 	not input.skill_signed
 }
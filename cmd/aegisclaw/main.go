@@ -2,11 +2,18 @@ package main
 
 import (
 	"bufio"
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"syscall"
 	"time"
 
 	"encoding/json"
@@ -14,12 +21,14 @@ import (
 	"github.com/mackeh/AegisClaw/internal/agent"
 	"github.com/mackeh/AegisClaw/internal/audit"
 	"github.com/mackeh/AegisClaw/internal/cluster"
+	"github.com/mackeh/AegisClaw/internal/completion"
 	"github.com/mackeh/AegisClaw/internal/compliance"
 	"github.com/mackeh/AegisClaw/internal/config"
 	"github.com/mackeh/AegisClaw/internal/doctor"
 	"github.com/mackeh/AegisClaw/internal/guardrails"
 	"github.com/mackeh/AegisClaw/internal/marketplace"
 	"github.com/mackeh/AegisClaw/internal/mcp"
+	"github.com/mackeh/AegisClaw/internal/policy"
 	"github.com/mackeh/AegisClaw/internal/posture"
 	"github.com/mackeh/AegisClaw/internal/sandbox"
 	"github.com/mackeh/AegisClaw/internal/secrets"
@@ -34,26 +43,28 @@ import (
 
 var version = "0.10.0"
 
+// commit is the source revision, set at build time via
+// -ldflags "-X main.commit=<sha>".
+var commit = "unknown"
+
 func main() {
-	// Setup Telemetry
-	cfg, _ := config.LoadDefault()
-	var cleanup func(context.Context) error
+	agent.Version = version
+	skill.RunningVersion = version
 
-	if cfg != nil && cfg.Telemetry.Enabled {
-		cfgDir, _ := config.DefaultConfigDir()
-		tracePath := filepath.Join(cfgDir, "traces.json")
-		f, err := os.OpenFile(tracePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
-		if err == nil {
-			// Intentionally ignoring error for now to keep CLI clean
-			cleanup, _ = telemetry.Setup(context.Background(), "aegisclaw", version, true, f)
-		} else {
-			cleanup, _ = telemetry.Setup(context.Background(), "aegisclaw", version, false, nil)
-		}
-	} else {
-		cleanup, _ = telemetry.Setup(context.Background(), "aegisclaw", version, false, nil)
+	err := newRootCmd().Execute()
+	if telemetryCleanup != nil {
+		telemetryCleanup(context.Background())
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
-	defer cleanup(context.Background())
+}
 
+// telemetryCleanup flushes the tracer set up by setupCLI.
+var telemetryCleanup func(context.Context) error
+
+func newRootCmd() *cobra.Command {
 	rootCmd := &cobra.Command{
 		Use:   "aegisclaw",
 		Short: "Secure-by-default runtime for AI agents",
@@ -61,20 +72,31 @@ func main() {
 It provides sandboxed execution, capability-based permissions,
 human-in-the-loop approvals, encrypted secrets, and tamper-evident audit logging.`,
 		Version: version,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if p, _ := cmd.Flags().GetString("profile"); p != "" {
+				config.SetProfile(p)
+			}
+			setupCLI()
+			return nil
+		},
 	}
+	rootCmd.PersistentFlags().String("profile", "", "Config profile to apply over the base config (overrides $"+config.ProfileEnv+")")
 
 	rootCmd.AddCommand(initCmd())
 	rootCmd.AddCommand(runCmd())
+	rootCmd.AddCommand(runOnceCmd())
 	rootCmd.AddCommand(harnessCmd())
 	rootCmd.AddCommand(gatewayCmd())
 	rootCmd.AddCommand(policyCmd())
 	rootCmd.AddCommand(secretsCmd())
 	rootCmd.AddCommand(logsCmd())
+	rootCmd.AddCommand(runsCmd())
 	rootCmd.AddCommand(sandboxCmd())
 	rootCmd.AddCommand(skillsCmd())
 	rootCmd.AddCommand(serveCmd())
 	rootCmd.AddCommand(doctorCmd())
 	rootCmd.AddCommand(upgradeCmd())
+	rootCmd.AddCommand(versionCmd())
 	rootCmd.AddCommand(completionCmd())
 	rootCmd.AddCommand(postureCmd())
 	rootCmd.AddCommand(simulateCmd())
@@ -84,10 +106,37 @@ human-in-the-loop approvals, encrypted secrets, and tamper-evident audit logging
 	rootCmd.AddCommand(marketplaceCmd())
 	rootCmd.AddCommand(clusterCmd())
 	rootCmd.AddCommand(complianceCmd())
+	rootCmd.AddCommand(configCmd())
 
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+	return rootCmd
+}
+
+// setupCLI loads config.yaml, now that --profile is applied, and sets up
+// telemetry from it. A config that fails to load is reported but does not
+// stop the command: doctor and config set must still work, and skill runs
+// refuse an unloadable config on their own.
+func setupCLI() {
+	cfg, err := config.LoadDefault()
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		fmt.Fprintf(os.Stderr, "⚠️  config.yaml: %v (see: aegisclaw doctor)\n", err)
+	}
+
+	if cfg != nil {
+		telemetry.SetMaxLabelValues(cfg.Telemetry.MaxLabelValues)
+	}
+	if cfg != nil && cfg.Telemetry.Enabled {
+		cfgDir, _ := config.DefaultConfigDir()
+		tracePath := filepath.Join(cfgDir, "traces.json")
+		f, err := os.OpenFile(tracePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err == nil {
+			// Intentionally ignoring error for now to keep CLI clean
+			opts := telemetry.Options{SampleRatio: cfg.Telemetry.SampleRatio}
+			telemetryCleanup, _ = telemetry.SetupWithOptions(context.Background(), "aegisclaw", version, true, f, opts)
+		} else {
+			telemetryCleanup, _ = telemetry.Setup(context.Background(), "aegisclaw", version, false, nil)
+		}
+	} else {
+		telemetryCleanup, _ = telemetry.Setup(context.Background(), "aegisclaw", version, false, nil)
 	}
 }
 
@@ -109,11 +158,22 @@ func initCmd() *cobra.Command {
 }
 
 func runCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "run",
+	cmd := &cobra.Command{
+		Use:   "run [--detached <skill> <command> [args...]]",
 		Short: "Start the agent runtime",
-		Long:  "Launches the AegisClaw runtime with the configured agent and policies.",
+		Long: `Launches the AegisClaw runtime with the configured agent and policies.
+
+With --detached, starts a long-running skill instead: the skill passes
+policy and approval as usual, then 'run' waits until the manifest's health
+probe succeeds and returns while the container keeps running. Stop it with
+'aegisclaw runs kill <run-id>'.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if detached, _ := cmd.Flags().GetBool("detached"); detached {
+				return runDetached(cmd, args)
+			}
+			if len(args) > 0 {
+				return fmt.Errorf("unexpected arguments %v (did you mean --detached?)", args)
+			}
 			fmt.Println("🦅 AegisClaw runtime starting...")
 
 			cfgDir, err := config.DefaultConfigDir()
@@ -121,11 +181,8 @@ func runCmd() *cobra.Command {
 				return err
 			}
 
-			// Load skills
-			skillsDir := filepath.Join(cfgDir, "skills")
-			manifests, _ := skill.ListSkills(skillsDir)
-			localManifests, _ := skill.ListSkills("skills")
-			manifests = append(manifests, localManifests...)
+			// Load skills; installed ones shadow local ones of the same name.
+			manifests := skill.LoadSkills(skill.SearchPaths(cfgDir)...)
 
 			fmt.Printf("🧩 Loaded %d skills\n", len(manifests))
 			fmt.Println("🤖 Agent is ready. Type 'help' for commands or 'exit' to quit.")
@@ -191,6 +248,39 @@ func runCmd() *cobra.Command {
 			}
 		},
 	}
+	cmd.Flags().Bool("detached", false, "Start a long-running skill, wait for its health probe, and leave it running")
+	return cmd
+}
+
+// registryAuth resolves registry.auth_secret from the secret store; nil
+// when the registry is anonymous.
+func registryAuth(cfg *config.Config) (*skill.RegistryAuth, error) {
+	cfgDir, err := config.DefaultConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	return skill.ResolveRegistryAuth(cfg.Registry, secrets.NewManager(filepath.Join(cfgDir, "secrets")).Get)
+}
+
+// runDetached starts <skill> <command> [args...] detached.
+func runDetached(cmd *cobra.Command, args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("--detached needs <skill> <command> [args...]")
+	}
+	cfgDir, err := config.DefaultConfigDir()
+	if err != nil {
+		return err
+	}
+	m, err := agent.FindSkill(args[0], skill.SearchPaths(cfgDir)...)
+	if err != nil {
+		return err
+	}
+	res, err := agent.ExecuteSkillDetached(cmd.Context(), m, args[1], args[2:])
+	if err != nil {
+		return err
+	}
+	fmt.Printf("🛑 Stop it with: aegisclaw runs kill %s\n", res.Record.ID)
+	return nil
 }
 
 func policyCmd() *cobra.Command {
@@ -318,13 +408,20 @@ func secretsCmd() *cobra.Command {
 		},
 	})
 
+	cmd.AddCommand(secretsExportCmd())
+	cmd.AddCommand(secretsImportCmd())
+
 	return cmd
 }
 
 func logsCmd() *cobra.Command {
+	var q audit.Query
+	var since string
 	cmd := &cobra.Command{
 		Use:   "logs",
 		Short: "View audit logs",
+		Long: `View the audit log. Filtering by --action, --actor or --since uses the
+index kept next to the log (audit.log.idx), building it on first use.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cfgDir, err := config.DefaultConfigDir()
 			if err != nil {
@@ -332,7 +429,19 @@ func logsCmd() *cobra.Command {
 			}
 			logPath := filepath.Join(cfgDir, "audit", "audit.log")
 
-			entries, err := audit.ReadAll(logPath)
+			if since != "" {
+				d, err := time.ParseDuration(since)
+				if err != nil {
+					return fmt.Errorf("invalid --since %q (want a duration such as 24h): %w", since, err)
+				}
+				q.Since = time.Now().Add(-d)
+			}
+			var entries []audit.Entry
+			if q == (audit.Query{}) {
+				entries, err = audit.ReadAll(logPath)
+			} else {
+				entries, err = audit.Search(logPath, q)
+			}
 			if err != nil {
 				return err
 			}
@@ -356,7 +465,12 @@ func logsCmd() *cobra.Command {
 		},
 	}
 
-	cmd.AddCommand(&cobra.Command{
+	cmd.Flags().StringVar(&q.Action, "action", "", "Only show entries with this action (e.g. skill.exit)")
+	cmd.Flags().StringVar(&q.Actor, "actor", "", "Only show entries by this actor")
+	cmd.Flags().StringVar(&since, "since", "", "Only show entries from this long ago (e.g. 24h)")
+
+	var anchorsPath string
+	verifyCmd := &cobra.Command{
 		Use:   "verify",
 		Short: "Verify audit log integrity (hash chain)",
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -378,6 +492,59 @@ func logsCmd() *cobra.Command {
 			} else {
 				fmt.Println("❌ Log integrity check returned false.")
 			}
+
+			if anchorsPath == "" {
+				if cfg, err := config.LoadDefault(); err == nil {
+					anchorsPath = cfg.Audit.Anchor.File
+				}
+			}
+			if anchorsPath == "" {
+				return nil
+			}
+			anchors, err := audit.ReadAnchors(anchorsPath)
+			if err != nil {
+				return err
+			}
+			for _, a := range anchors {
+				if err := audit.CheckAnchor(logPath, a); err != nil {
+					fmt.Printf("❌ Anchor check FAILED: %v\n", err)
+					return nil
+				}
+			}
+			fmt.Printf("⚓ Log matches all %d anchor(s) in %s.\n", len(anchors), anchorsPath)
+			return nil
+		},
+	}
+	verifyCmd.Flags().StringVar(&anchorsPath, "anchors", "", "Also check the log against anchors in this file (default audit.anchor.file)")
+	cmd.AddCommand(verifyCmd)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "anchor",
+		Short: "Record the audit chain head to the configured anchor sinks",
+		Long: `Send the current head of the audit hash chain to audit.anchor.file and/or
+audit.anchor.webhook. Anchors kept off-host let 'logs verify' detect a log
+that was replaced wholesale, even with a valid new chain.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.LoadDefault()
+			if err != nil {
+				return err
+			}
+			sink, err := audit.NewAnchorSink(cfg.Audit.Anchor)
+			if err != nil {
+				return err
+			}
+			if sink == nil {
+				return fmt.Errorf("no anchor sink configured (set audit.anchor.file or audit.anchor.webhook)")
+			}
+			cfgDir, err := config.DefaultConfigDir()
+			if err != nil {
+				return err
+			}
+			a, err := audit.Anchor(filepath.Join(cfgDir, "audit", "audit.log"), sink, "manual")
+			if err != nil {
+				return err
+			}
+			fmt.Printf("⚓ Anchored entry %d (%.12s…)\n", a.Seq, a.Hash)
 			return nil
 		},
 	})
@@ -391,7 +558,8 @@ func sandboxCmd() *cobra.Command {
 		Short: "Manage and test sandbox execution",
 	}
 
-	cmd.AddCommand(&cobra.Command{
+	var pullPolicy string
+	runSandboxCmd := &cobra.Command{
 		Use:   "run-sandbox [IMAGE] [COMMAND]",
 		Short: "Run a command in the hardened sandbox",
 		Args:  cobra.MinimumNArgs(2),
@@ -411,9 +579,10 @@ func sandboxCmd() *cobra.Command {
 			// Capture output
 			ctx := cmd.Context()
 			result, err := exec.Run(ctx, sandbox.Config{
-				Image:   image,
-				Command: command,
-				Network: false, // Default deny
+				Image:      image,
+				Command:    command,
+				Network:    false, // Default deny
+				PullPolicy: pullPolicy,
 			})
 			if err != nil {
 				return fmt.Errorf("execution failed: %w", err)
@@ -422,9 +591,12 @@ func sandboxCmd() *cobra.Command {
 			fmt.Printf("✅ Execution complete (exit code %d)\n", result.ExitCode)
 			return nil
 		},
-	})
+	}
+	runSandboxCmd.Flags().StringVar(&pullPolicy, "pull", sandbox.PullIfMissing, "Image pull policy: missing or never (offline; fail if the image is absent)")
+	cmd.AddCommand(runSandboxCmd)
 
-	cmd.AddCommand(&cobra.Command{
+	var inputs []string
+	runSkillCmd := &cobra.Command{
 		Use:   "run-skill [MANIFEST_PATH] [COMMAND_NAME] [ARGS...]",
 		Short: "Run a named command from a skill manifest",
 		Args:  cobra.MinimumNArgs(2),
@@ -439,12 +611,19 @@ func sandboxCmd() *cobra.Command {
 				return err
 			}
 
-			if _, err := agent.ExecuteSkill(cmd.Context(), m, cmdName, userArgs); err != nil {
+			files, err := sandbox.ReadInputFiles(inputs)
+			if err != nil {
+				return err
+			}
+
+			if _, err := agent.ExecuteSkillWithInputs(cmd.Context(), m, cmdName, userArgs, files); err != nil {
 				return err
 			}
 			return nil
 		},
-	})
+	}
+	runSkillCmd.Flags().StringArrayVar(&inputs, "input", nil, "Copy a host file into "+sandbox.InputDir+" as [name=]path (repeatable; no host mount)")
+	cmd.AddCommand(runSkillCmd)
 
 	return cmd
 }
@@ -455,7 +634,8 @@ func skillsCmd() *cobra.Command {
 		Short: "Manage agent skills",
 	}
 
-	cmd.AddCommand(&cobra.Command{
+	var listJSON bool
+	listCmd := &cobra.Command{
 		Use:   "list",
 		Short: "List installed skills",
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -464,13 +644,28 @@ func skillsCmd() *cobra.Command {
 				return err
 			}
 
-			skillsDir := filepath.Join(cfgDir, "skills")
-
-			// Also check local skills directory if it exists
-			manifests, _ := skill.ListSkills(skillsDir)
+			if listJSON {
+				// Without a config there are no trust keys, so nothing is
+				// reported as trusted.
+				var trustKeys []string
+				if cfg, err := config.LoadDefault(); err == nil {
+					trustKeys = cfg.Registry.TrustKeys
+				}
+				listings := skill.ListDetailed(trustKeys, skill.SearchPaths(cfgDir)...)
+				if listings == nil {
+					listings = []skill.Listing{}
+				}
+				data, err := json.MarshalIndent(listings, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to encode skills: %w", err)
+				}
+				fmt.Println(string(data))
+				return nil
+			}
 
-			localManifests, _ := skill.ListSkills("skills")
-			manifests = append(manifests, localManifests...)
+			// Installed skills, then the local skills directory; an
+			// installed skill shadows a local one of the same name.
+			manifests := skill.LoadSkills(skill.SearchPaths(cfgDir)...)
 
 			if len(manifests) == 0 {
 				fmt.Println("📭 No skills installed.")
@@ -486,7 +681,9 @@ func skillsCmd() *cobra.Command {
 			}
 			return nil
 		},
-	})
+	}
+	listCmd.Flags().BoolVar(&listJSON, "json", false, "Print full manifests with source directory and signature status as JSON")
+	cmd.AddCommand(listCmd)
 
 	cmd.AddCommand(&cobra.Command{
 		Use:   "search [QUERY]",
@@ -502,8 +699,12 @@ func skillsCmd() *cobra.Command {
 				return nil
 			}
 
+			auth, err := registryAuth(cfg)
+			if err != nil {
+				return err
+			}
 			fmt.Printf("🔍 Searching registry: %s\n", cfg.Registry.URL)
-			index, err := skill.SearchRegistry(cfg.Registry.URL)
+			index, err := skill.SearchRegistryWithAuth(cfg.Registry.URL, auth)
 			if err != nil {
 				return err
 			}
@@ -530,7 +731,8 @@ func skillsCmd() *cobra.Command {
 		},
 	})
 
-	cmd.AddCommand(&cobra.Command{
+	var allowDowngrade bool
+	addCmd := &cobra.Command{
 		Use:   "add [SKILL_NAME]",
 		Short: "Install a signed skill from the registry",
 		Args:  cobra.ExactArgs(1),
@@ -549,10 +751,184 @@ func skillsCmd() *cobra.Command {
 			cfgDir, _ := config.DefaultConfigDir()
 			skillsDir := filepath.Join(cfgDir, "skills")
 
+			logger, _ := audit.NewLogger(filepath.Join(cfgDir, "audit", "audit.log"))
+			auth, err := registryAuth(cfg)
+			if err != nil {
+				return err
+			}
+
 			fmt.Printf("📥 Installing skill '%s'...\n", skillName)
-			return skill.InstallSkill(skillName, skillsDir, cfg.Registry.URL, cfg.Registry.TrustKeys)
+			return skill.InstallSkillWithOptions(skillName, skillsDir, cfg.Registry.URL, cfg.Registry.TrustKeys, skill.InstallOptions{
+				AllowDowngrade: allowDowngrade,
+				AuditLogger:    logger,
+				Auth:           auth,
+			})
 		},
-	})
+	}
+	addCmd.Flags().BoolVar(&allowDowngrade, "allow-downgrade", false, "Permit replacing an installed skill with an older version")
+	cmd.AddCommand(addCmd)
+
+	var yes bool
+	removeCmd := &cobra.Command{
+		Use:   "remove [SKILL_NAME]",
+		Short: "Uninstall a skill",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			skillName := args[0]
+			cfgDir, _ := config.DefaultConfigDir()
+			skillsDir := filepath.Join(cfgDir, "skills")
+
+			if !yes {
+				fmt.Printf("❓ Remove skill '%s'? [y/N] ", skillName)
+				var response string
+				fmt.Scanln(&response)
+				if strings.ToLower(response) != "y" {
+					fmt.Println("❌ Removal cancelled.")
+					return nil
+				}
+			}
+
+			logger, _ := audit.NewLogger(filepath.Join(cfgDir, "audit", "audit.log"))
+			if err := skill.RemoveSkill(skillName, skillsDir, logger); err != nil {
+				return err
+			}
+			fmt.Printf("🗑️  Removed skill: %s\n", skillName)
+			return nil
+		},
+	}
+	removeCmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip the confirmation prompt")
+	cmd.AddCommand(removeCmd)
+
+	var updateAll bool
+	updateCmd := &cobra.Command{
+		Use:   "update [SKILL_NAME]",
+		Short: "Update installed skills to newer signed registry versions",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if updateAll && len(args) > 0 {
+				return fmt.Errorf("pass a skill name or --all, not both")
+			}
+			if !updateAll && len(args) != 1 {
+				return fmt.Errorf("requires a skill name or --all")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.LoadDefault()
+			if err != nil {
+				return fmt.Errorf("failed to load configuration (run 'init' first): %w", err)
+			}
+			if cfg.Registry.URL == "" {
+				return fmt.Errorf("no registry URL configured")
+			}
+
+			cfgDir, _ := config.DefaultConfigDir()
+			skillsDir := filepath.Join(cfgDir, "skills")
+			logger, _ := audit.NewLogger(filepath.Join(cfgDir, "audit", "audit.log"))
+			auth, err := registryAuth(cfg)
+			if err != nil {
+				return err
+			}
+
+			names := args
+			if updateAll {
+				manifests, _ := skill.ListSkills(skillsDir)
+				for _, m := range manifests {
+					names = append(names, m.Name)
+				}
+				if len(names) == 0 {
+					fmt.Println("📭 No skills installed.")
+					return nil
+				}
+			}
+
+			var failed int
+			for _, name := range names {
+				st, err := skill.UpdateSkill(name, skillsDir, cfg.Registry.URL, cfg.Registry.TrustKeys, skill.InstallOptions{AuditLogger: logger, Auth: auth})
+				switch {
+				case err != nil:
+					fmt.Printf("❌ %s: %v\n", name, err)
+					failed++
+				case st.Updated:
+					fmt.Printf("⬆️  %s: v%s → v%s\n", name, st.Installed, st.Available)
+				default:
+					fmt.Printf("✨ %s is up to date (v%s)\n", name, st.Installed)
+				}
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d skill(s) failed to update", failed)
+			}
+			return nil
+		},
+	}
+	updateCmd.Flags().BoolVar(&updateAll, "all", false, "Update every installed skill")
+	cmd.AddCommand(updateCmd)
+
+	var prune, syncDowngrade bool
+	syncCmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Reconcile installed skills with the skills list in config",
+		Long: "Install every skill declared under skills: in config.yaml that is missing,\n" +
+			"update those at another version than the registry's (or the pinned\n" +
+			"version), and reinstall any whose signature no longer verifies. Every\n" +
+			"install is signature-verified. With --prune, installed skills that are\n" +
+			"not declared are removed.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.LoadDefault()
+			if err != nil {
+				return fmt.Errorf("failed to load configuration (run 'init' first): %w", err)
+			}
+			auth, err := registryAuth(cfg)
+			if err != nil {
+				return err
+			}
+			targets, err := skill.SyncTargets(cfg, auth)
+			if err != nil {
+				return err
+			}
+
+			cfgDir, _ := config.DefaultConfigDir()
+			skillsDir := filepath.Join(cfgDir, "skills")
+			logger, _ := audit.NewLogger(filepath.Join(cfgDir, "audit", "audit.log"))
+
+			fmt.Printf("🔄 Syncing %d declared skill(s)...\n", len(targets))
+			results, err := skill.Sync(targets, skillsDir, cfg.Registry.TrustKeys, skill.SyncOptions{
+				InstallOptions: skill.InstallOptions{AllowDowngrade: syncDowngrade, AuditLogger: logger},
+				Prune:          prune,
+			})
+			if err != nil {
+				return err
+			}
+
+			var failed int
+			for _, r := range results {
+				switch {
+				case r.Err != nil:
+					fmt.Printf("❌ %s: %v\n", r.Name, r.Err)
+					failed++
+				case r.Action == skill.SyncInstall:
+					fmt.Printf("📥 %s: installed v%s\n", r.Name, r.To)
+				case r.Action == skill.SyncUpdate:
+					fmt.Printf("⬆️  %s: v%s → v%s\n", r.Name, r.From, r.To)
+				case r.Action == skill.SyncReinstall:
+					fmt.Printf("🔁 %s: reinstalled v%s (installed copy failed verification)\n", r.Name, r.To)
+				case r.Action == skill.SyncPrune:
+					fmt.Printf("🗑️  %s: pruned v%s\n", r.Name, r.From)
+				case r.Action == skill.SyncUndeclared:
+					fmt.Printf("ℹ️  %s v%s is installed but not declared (use --prune to remove it)\n", r.Name, r.From)
+				default:
+					fmt.Printf("✨ %s is up to date (v%s)\n", r.Name, r.From)
+				}
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d skill(s) failed to sync", failed)
+			}
+			return nil
+		},
+	}
+	syncCmd.Flags().BoolVar(&prune, "prune", false, "Remove installed skills that are not declared in config")
+	syncCmd.Flags().BoolVar(&syncDowngrade, "allow-downgrade", false, "Permit moving a skill back to an older pinned version")
+	cmd.AddCommand(syncCmd)
 
 	return cmd
 }
@@ -582,11 +958,22 @@ func serveCmd() *cobra.Command {
 }
 
 func doctorCmd() *cobra.Command {
-	return &cobra.Command{
+	var fix bool
+	cmd := &cobra.Command{
 		Use:   "doctor",
 		Short: "Diagnose AegisClaw setup and environment",
-		Long:  "Runs health checks on OpenClaw adapter connectivity, Docker, secrets, audit logs, policy engine, and disk space.",
+		Long: `Runs health checks on OpenClaw adapter connectivity, Docker, secrets, audit logs, policy engine, and disk space.
+
+With --fix, safe remediations are applied first — creating the config
+directory, initializing the secret store, tightening file permissions and
+writing a missing default policy — and the checks then run again. Anything
+that would remove data is confirmed first; issues such as a missing Docker
+install stay advisory.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if fix {
+				runDoctorFix()
+			}
+
 			fmt.Println("🩺  AegisClaw Health Check")
 			fmt.Println()
 
@@ -614,6 +1001,9 @@ func doctorCmd() *cobra.Command {
 
 				if r.Fix != "" && r.Status != doctor.StatusPass {
 					fmt.Printf("   → %s\n", r.Fix)
+					if r.Remedy != nil && !fix {
+						fmt.Println("   → or run: aegisclaw doctor --fix")
+					}
 				}
 			}
 
@@ -640,6 +1030,35 @@ func doctorCmd() *cobra.Command {
 			return nil
 		},
 	}
+	cmd.Flags().BoolVar(&fix, "fix", false, "Apply safe remediations, then re-run the checks")
+	return cmd
+}
+
+// runDoctorFix applies the remedies for the current check results, asking
+// before destructive ones.
+func runDoctorFix() {
+	fmt.Println("🔧 Applying fixes")
+	confirm := func(r doctor.Result) bool {
+		fmt.Printf("❓ %s: %s? [y/N] ", r.Name, r.Remedy.Action)
+		var response string
+		fmt.Scanln(&response)
+		return strings.ToLower(response) == "y"
+	}
+	outcomes := doctor.Fix(doctor.RunAll(), confirm)
+	if len(outcomes) == 0 {
+		fmt.Println("   Nothing to fix automatically.")
+	}
+	for _, o := range outcomes {
+		switch {
+		case o.Skipped:
+			fmt.Printf("   ⏭️  %s: skipped %s\n", o.Name, o.Action)
+		case o.Err != nil:
+			fmt.Printf("   ❌ %s: %s failed: %v\n", o.Name, o.Action, o.Err)
+		default:
+			fmt.Printf("   ✅ %s: %s\n", o.Name, o.Action)
+		}
+	}
+	fmt.Println()
 }
 
 func upgradeCmd() *cobra.Command {
@@ -673,12 +1092,63 @@ func upgradeCmd() *cobra.Command {
 	}
 }
 
+func versionCmd() *cobra.Command {
+	var check, checkQuiet bool
+	cmd := &cobra.Command{
+		Use:   "version",
+		Short: "Show version and build information",
+		Long: `Show the AegisClaw version, build commit and Go version.
+
+With --check, also query the latest release. --check-quiet prints nothing
+and exits 1 when an update is available, for use in scripts.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if checkQuiet {
+				latest, err := updater.Check(version)
+				if err != nil {
+					return fmt.Errorf("failed to check for updates: %w", err)
+				}
+				if latest != "" {
+					os.Exit(1)
+				}
+				return nil
+			}
+
+			fmt.Printf("AegisClaw v%s\n", version)
+			fmt.Printf("   Commit:   %s\n", commit)
+			fmt.Printf("   Go:       %s\n", runtime.Version())
+			fmt.Printf("   Platform: %s/%s\n", runtime.GOOS, runtime.GOARCH)
+
+			if !check {
+				return nil
+			}
+			fmt.Println()
+			latest, err := updater.Check(version)
+			if err != nil {
+				return fmt.Errorf("failed to check for updates: %w", err)
+			}
+			if latest == "" {
+				fmt.Printf("✨ AegisClaw is up to date (v%s)\n", version)
+				return nil
+			}
+			fmt.Printf("🆕 A new version is available: v%s (current: v%s)\n", latest, version)
+			fmt.Println("   Run 'aegisclaw upgrade' to install it.")
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&check, "check", false, "Check whether a newer release is available")
+	cmd.Flags().BoolVar(&checkQuiet, "check-quiet", false, "Exit 1 if an update is available, printing nothing")
+	return cmd
+}
+
 func completionCmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "completion [bash|zsh|fish|powershell]",
 		Short: "Generate shell completion scripts",
 		Long: `Generate shell completion scripts for AegisClaw.
 
+To install for your shell in one step:
+  $ aegisclaw completion install
+
 To load completions:
 
 Bash:
@@ -697,26 +1167,81 @@ PowerShell:
   PS> aegisclaw completion powershell | Out-String | Invoke-Expression
 `,
 		Args:      cobra.ExactArgs(1),
-		ValidArgs: []string{"bash", "zsh", "fish", "powershell"},
+		ValidArgs: completion.Shells,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			switch args[0] {
-			case "bash":
-				return cmd.Root().GenBashCompletion(os.Stdout)
-			case "zsh":
-				return cmd.Root().GenZshCompletion(os.Stdout)
-			case "fish":
-				return cmd.Root().GenFishCompletion(os.Stdout, true)
-			case "powershell":
-				return cmd.Root().GenPowerShellCompletionWithDesc(os.Stdout)
-			default:
-				return fmt.Errorf("unsupported shell: %s", args[0])
+			return genCompletion(cmd.Root(), args[0], os.Stdout)
+		},
+	}
+
+	var path string
+	install := &cobra.Command{
+		Use:   "install [bash|zsh|fish|powershell]",
+		Short: "Install the completion script for your shell",
+		Long: `Write the completion script to the conventional per-user location for the
+shell (detected from $SHELL when not given) and print what to do next.`,
+		Args:      cobra.MaximumNArgs(1),
+		ValidArgs: completion.Shells,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var shell string
+			var err error
+			if len(args) == 1 {
+				shell = args[0]
+			} else if shell, err = completion.DetectShell(os.Getenv("SHELL")); err != nil {
+				return err
 			}
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return err
+			}
+			target, err := completion.TargetFor(shell, home, os.Getenv)
+			if err != nil {
+				return err
+			}
+			if path != "" {
+				target.Path = path
+			}
+
+			var buf bytes.Buffer
+			if err := genCompletion(cmd.Root(), shell, &buf); err != nil {
+				return err
+			}
+			if err := os.MkdirAll(filepath.Dir(target.Path), 0755); err != nil {
+				return fmt.Errorf("failed to create completion directory: %w", err)
+			}
+			if err := os.WriteFile(target.Path, buf.Bytes(), 0644); err != nil {
+				return fmt.Errorf("failed to write completion script: %w", err)
+			}
+			fmt.Printf("✅ Installed %s completion to %s\n", shell, target.Path)
+			if path == "" {
+				fmt.Println(target.Hint)
+			}
+			return nil
 		},
 	}
+	install.Flags().StringVar(&path, "path", "", "Write the script here instead of the shell's default location")
+	cmd.AddCommand(install)
+	return cmd
+}
+
+// genCompletion writes root's completion script for shell to w.
+func genCompletion(root *cobra.Command, shell string, w io.Writer) error {
+	switch shell {
+	case "bash":
+		return root.GenBashCompletion(w)
+	case "zsh":
+		return root.GenZshCompletion(w)
+	case "fish":
+		return root.GenFishCompletion(w, true)
+	case "powershell":
+		return root.GenPowerShellCompletionWithDesc(w)
+	default:
+		return fmt.Errorf("unsupported shell: %s", shell)
+	}
 }
 
 func postureCmd() *cobra.Command {
-	return &cobra.Command{
+	var advise bool
+	cmd := &cobra.Command{
 		Use:   "posture",
 		Short: "Show security posture score",
 		Long:  "Evaluates your AegisClaw configuration and assigns a security grade.",
@@ -727,6 +1252,9 @@ func postureCmd() *cobra.Command {
 			}
 
 			fmt.Println("🛡️  AegisClaw Security Posture")
+			if score.Profile != "" {
+				fmt.Printf("   Profile: %s\n", score.Profile)
+			}
 			fmt.Println()
 
 			for _, c := range score.Categories {
@@ -736,9 +1264,24 @@ func postureCmd() *cobra.Command {
 
 			fmt.Println()
 			fmt.Printf("  Total: %d/%d (%d%%) — Grade: %s\n", score.Total, score.Max, score.Percentage, score.Grade)
+
+			if advise {
+				recs := posture.Advise(score)
+				fmt.Println()
+				if len(recs) == 0 {
+					fmt.Println("✅ Nothing to improve — every category is at its maximum.")
+					return nil
+				}
+				fmt.Println("📋 Remediation plan (highest impact first):")
+				for i, r := range recs {
+					fmt.Printf("  %d. %s: +%d  [%s]\n", i+1, r.Action, r.Gain, r.Category)
+				}
+			}
 			return nil
 		},
 	}
+	cmd.Flags().BoolVar(&advise, "advise", false, "Show a remediation plan ranked by point gain")
+	return cmd
 }
 
 func renderBar(points, max int) string {
@@ -752,19 +1295,45 @@ func renderBar(points, max int) string {
 }
 
 func simulateCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "simulate [MANIFEST_PATH]",
+	var all, explain bool
+	var failOn string
+	cmd := &cobra.Command{
+		Use:   "simulate [MANIFEST_PATH | --all [DIR]]",
 		Short: "Dry-run a skill without executing it",
-		Long:  "Analyzes a skill manifest and predicts behaviour, scope usage, and policy decisions.",
-		Args:  cobra.ExactArgs(1),
+		Long: `Analyzes a skill manifest and predicts behaviour, scope usage, and policy decisions.
+
+With --all, simulates every skill in DIR (default: the installed skills
+directory) and prints a summary table. --fail-on makes the command exit
+non-zero when any skill reaches a risk level or policy decision, e.g.
+--fail-on high or --fail-on critical,deny, for use as a CI gate.
+
+With --explain, the report shows which override, Rego rule or time-window
+rule decided each scope.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if all {
+				return cobra.MaximumNArgs(1)(cmd, args)
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := simulateOptions()
+			if err != nil {
+				return err
+			}
+			opts.Explain = explain
+			if all {
+				return runSimulateAll(cmd, args, opts, failOn)
+			}
+			if failOn != "" {
+				return fmt.Errorf("--fail-on requires --all")
+			}
+
 			manifestPath := args[0]
 			m, err := skill.LoadManifest(manifestPath)
 			if err != nil {
 				return err
 			}
-
-			report, err := simulate.Run(cmd.Context(), m)
+			report, err := simulate.RunWithOptions(cmd.Context(), m, opts)
 			if err != nil {
 				return err
 			}
@@ -816,8 +1385,31 @@ func simulateCmd() *cobra.Command {
 				fmt.Println()
 			}
 
+			if p := report.Provenance; p != nil {
+				fmt.Println("   Provenance:")
+				if p.SourceRepo != "" {
+					fmt.Printf("     📦 Source:      %s", p.SourceRepo)
+					if p.Commit != "" {
+						fmt.Printf(" @ %s", p.Commit)
+					}
+					fmt.Println()
+				}
+				if p.SBOM != "" {
+					fmt.Printf("     📄 SBOM:        %s\n", p.SBOM)
+				}
+				if p.Attestation != "" {
+					fmt.Printf("     🔏 Attestation: %s\n", p.Attestation)
+				}
+				fmt.Println()
+			}
+
+			fmt.Printf("   Resource limits: %dMB memory, %.2g CPU, %d PIDs\n",
+				report.Resources.MemoryBytes>>20, report.Resources.CPUs, report.Resources.PidsLimit)
 			fmt.Printf("   Risk assessment: %s\n", strings.ToUpper(report.RiskLevel))
 			fmt.Printf("   Policy decision: %s\n", report.PolicyDecision)
+			for _, t := range report.PolicyTrace {
+				fmt.Printf("     %s", t)
+			}
 
 			if len(report.Warnings) > 0 {
 				fmt.Println()
@@ -830,10 +1422,93 @@ func simulateCmd() *cobra.Command {
 			return nil
 		},
 	}
+	cmd.Flags().BoolVar(&all, "all", false, "Simulate every skill in a directory")
+	cmd.Flags().BoolVar(&explain, "explain", false, "Show how the policy decided each scope")
+	cmd.Flags().StringVar(&failOn, "fail-on", "", "With --all, exit non-zero if any skill reaches this risk level and/or decision (e.g. high, critical,deny)")
+	return cmd
+}
+
+// simulateOptions reads the policy settings a simulation should honour from
+// config.yaml; without one, the defaults apply.
+func simulateOptions() (simulate.Options, error) {
+	var opts simulate.Options
+	if cfg, err := config.LoadDefault(); err == nil {
+		if mode, err := policy.ParseUnknownScopeMode(cfg.Policy.UnknownScope); err == nil {
+			opts.UnknownScope = mode
+		}
+		rules, err := agent.PolicyRules(cfg)
+		if err != nil {
+			return opts, err
+		}
+		opts.Rules = rules
+		opts.TrustKeys = cfg.Registry.TrustKeys
+	}
+	return opts, nil
+}
+
+// runSimulateAll implements 'simulate --all': one summary row per skill,
+// then the --fail-on verdict.
+func runSimulateAll(cmd *cobra.Command, args []string, opts simulate.Options, failOn string) error {
+	var gate simulate.Threshold
+	if failOn != "" {
+		var err error
+		if gate, err = simulate.ParseThreshold(failOn); err != nil {
+			return err
+		}
+	}
+	dir := ""
+	if len(args) == 1 {
+		dir = args[0]
+	} else {
+		cfgDir, err := config.DefaultConfigDir()
+		if err != nil {
+			return err
+		}
+		dir = filepath.Join(cfgDir, "skills")
+	}
+
+	results, err := simulate.RunDir(cmd.Context(), dir, opts)
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		fmt.Printf("📭 No skills found in %s\n", dir)
+		return nil
+	}
+
+	fmt.Printf("🔮 Simulation Summary: %s (%d skills)\n\n", dir, len(results))
+	fmt.Printf("  %-28s %-10s %-28s %s\n", "SKILL", "RISK", "DECISION", "WARNINGS")
+	for _, res := range results {
+		if res.Err != nil {
+			fmt.Printf("  %-28s %-10s %-28s %s\n", res.Name(), "-", "-", "❌ "+res.Err.Error())
+			continue
+		}
+		r := res.Report
+		fmt.Printf("  %-28s %-10s %-28s %d\n", r.SkillName, strings.ToUpper(r.RiskLevel), r.PolicyDecision, len(r.Warnings))
+	}
+
+	if failOn == "" {
+		return nil
+	}
+	failures := simulate.Failures(results, gate)
+	fmt.Println()
+	if len(failures) == 0 {
+		fmt.Printf("✅ No skill reached --fail-on %s\n", failOn)
+		return nil
+	}
+	fmt.Printf("❌ %d skill(s) reached --fail-on %s:\n", len(failures), failOn)
+	for _, res := range results {
+		if why, ok := failures[res.Path]; ok {
+			fmt.Printf("   - %s: %s\n", res.Name(), why)
+		}
+	}
+	os.Exit(1)
+	return nil
 }
 
 func mcpServerCmd() *cobra.Command {
 	var rateLimit int
+	var logFile string
 	cmd := &cobra.Command{
 		Use:   "mcp-server",
 		Short: "Start the MCP server for AI assistant integration",
@@ -841,7 +1516,8 @@ func mcpServerCmd() *cobra.Command {
 
 This allows AI assistants like Claude Code to interact with AegisClaw.
 Tool calls are rate-limited and recorded to a tamper-evident audit log
-at ~/.aegisclaw/audit/mcp.log.
+at ~/.aegisclaw/audit/mcp.log. Read-only tools are always available;
+tools that write state or execute need mcp.capabilities in config.yaml.
 
 Configure in your MCP settings:
   {
@@ -854,13 +1530,33 @@ Configure in your MCP settings:
   }`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			srv := mcp.NewServer()
+			// Without a config only the read-only tools are callable.
+			if cfg, err := config.LoadDefault(); err == nil {
+				if err := srv.SetCapabilities(cfg.MCP.Capabilities); err != nil {
+					return err
+				}
+			}
 			if cmd.Flags().Changed("rate-limit") {
 				srv.SetRateLimit(rateLimit)
 			}
+			if logFile != "" {
+				if logFile == "default" {
+					cfgDir, err := config.DefaultConfigDir()
+					if err != nil {
+						return err
+					}
+					logFile = filepath.Join(cfgDir, "mcp.log")
+				}
+				if err := srv.SetLogFile(logFile); err != nil {
+					return err
+				}
+			}
 			return srv.Run(cmd.Context())
 		},
 	}
 	cmd.Flags().IntVar(&rateLimit, "rate-limit", 120, "Max tool calls per minute (0 disables limiting)")
+	cmd.Flags().StringVar(&logFile, "log-file", "", "Trace requests, latency, and errors to a file (bare flag: ~/.aegisclaw/mcp.log)")
+	cmd.Flags().Lookup("log-file").NoOptDefVal = "default"
 	return cmd
 }
 
@@ -878,8 +1574,15 @@ func guardrailsCmd() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			text := strings.Join(args, " ")
 			mode, _ := cmd.Flags().GetString("mode")
+			failAt, err := failAtFlag(cmd)
+			if err != nil {
+				return err
+			}
 
-			engine := guardrails.NewEngine()
+			engine, err := guardrailsEngineFlag(cmd)
+			if err != nil {
+				return err
+			}
 
 			var result *guardrails.Result
 			switch mode {
@@ -894,17 +1597,17 @@ func guardrailsCmd() *cobra.Command {
 				return fmt.Errorf("mode must be 'input', 'output', or 'data'")
 			}
 
-			if result.Allowed {
-				fmt.Println("   ALLOWED")
-			} else {
+			if result.Blocked() {
 				fmt.Println("   BLOCKED")
+			} else {
+				fmt.Println("   ALLOWED")
 			}
 			if result.Source != "" {
 				fmt.Printf("   Source: %s\n", result.Source)
 			}
 
 			if len(result.Violations) > 0 {
-				fmt.Printf("\n   Violations (%d):\n", len(result.Violations))
+				fmt.Printf("\n   Violations (%d, most severe: %s):\n", len(result.Violations), strings.ToUpper(string(result.MaxSeverity)))
 				for _, v := range result.Violations {
 					fmt.Printf("     [%s] %s: %s\n", strings.ToUpper(string(v.Severity)), v.Rule, v.Message)
 				}
@@ -916,18 +1619,30 @@ func guardrailsCmd() *cobra.Command {
 				fmt.Printf("\n   Sanitized output:\n   %s\n", result.Sanitized)
 			}
 
+			if code := result.ExitCode(failAt); code != 0 {
+				os.Exit(code)
+			}
 			return nil
 		},
 	}
 	checkCmd.Flags().String("mode", "input", "Check mode: 'input' (prompt), 'output' (response), or 'data' (untrusted content)")
 	checkCmd.Flags().String("source", "", "Origin label for data-mode scans (e.g. 'web-fetch', 'file:report.md')")
+	checkCmd.Flags().String("fail-at", "", "Also exit non-zero when any violation is at or above this severity (low, medium, high, critical); blocked content always fails")
+	checkCmd.Flags().StringSlice("pack", nil, "Language rule packs to enable besides English (es, fr, de, or a .yaml file); default guardrails.packs from config")
 
 	scanCmd := &cobra.Command{
 		Use:   "scan",
 		Short: "Scan text from stdin against guardrail rules",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			mode, _ := cmd.Flags().GetString("mode")
-			engine := guardrails.NewEngine()
+			failAt, err := failAtFlag(cmd)
+			if err != nil {
+				return err
+			}
+			engine, err := guardrailsEngineFlag(cmd)
+			if err != nil {
+				return err
+			}
 
 			scanner := bufio.NewScanner(os.Stdin)
 			scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
@@ -953,7 +1668,7 @@ func guardrailsCmd() *cobra.Command {
 				return fmt.Errorf("mode must be 'input', 'output', or 'data'")
 			}
 
-			if result.Allowed {
+			if !result.Blocked() {
 				fmt.Println("ALLOWED")
 			} else {
 				fmt.Println("BLOCKED")
@@ -962,17 +1677,42 @@ func guardrailsCmd() *cobra.Command {
 				}
 			}
 
+			if code := result.ExitCode(failAt); code != 0 {
+				os.Exit(code)
+			}
 			return nil
 		},
 	}
 	scanCmd.Flags().String("mode", "input", "Check mode: 'input' (prompt), 'output' (response), or 'data' (untrusted content)")
 	scanCmd.Flags().String("source", "", "Origin label for data-mode scans (e.g. 'web-fetch', 'file:report.md')")
+	scanCmd.Flags().String("fail-at", "", "Also exit non-zero when any violation is at or above this severity (low, medium, high, critical); blocked content always fails")
+	scanCmd.Flags().StringSlice("pack", nil, "Language rule packs to enable besides English (es, fr, de, or a .yaml file); default guardrails.packs from config")
 
 	cmd.AddCommand(checkCmd)
 	cmd.AddCommand(scanCmd)
 	return cmd
 }
 
+// failAtFlag reads the guardrails --fail-at severity; empty means only
+// blocked results fail.
+func failAtFlag(cmd *cobra.Command) (guardrails.Severity, error) {
+	v, _ := cmd.Flags().GetString("fail-at")
+	if v == "" {
+		return "", nil
+	}
+	return guardrails.ParseSeverity(v)
+}
+
+// guardrailsEngineFlag builds the guardrails engine with the packs named by
+// --pack, or guardrails.packs from config when the flag is not given.
+func guardrailsEngineFlag(cmd *cobra.Command) (*guardrails.Engine, error) {
+	if !cmd.Flags().Changed("pack") {
+		return configuredGuardrails()
+	}
+	packs, _ := cmd.Flags().GetStringSlice("pack")
+	return guardrails.NewEngineWithPacks(packs)
+}
+
 func xrayCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "xray",
@@ -996,7 +1736,7 @@ func xrayCmd() *cobra.Command {
 
 			if len(snapshots) == 0 {
 				fmt.Println("No running AegisClaw containers found.")
-				fmt.Println("Hint: containers need the label 'aegisclaw.skill' to be detected.")
+				fmt.Println("Hint: containers need the label 'managed_by=aegisclaw' or 'aegisclaw.skill' to be detected.")
 				return nil
 			}
 
@@ -1041,11 +1781,203 @@ func xrayCmd() *cobra.Command {
 		},
 	}
 
+	var watchCPU, watchMem float64
+	var watchPIDs uint64
+	var watchSustain, watchInterval time.Duration
+	watchCmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Alert when a running container stays over resource thresholds",
+		Long: `Samples running AegisClaw containers and raises an anomaly when one stays
+over a CPU, memory, or PID threshold for the sustain period. Thresholds
+default to the xray section of config.yaml; flags override them. Anomalies
+are printed and written to the audit log, whose sinks forward them.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.LoadDefault()
+			if err != nil {
+				cfg = &config.Config{}
+			}
+			th, interval := xray.FromConfig(cfg.XRay)
+			if cmd.Flags().Changed("cpu") {
+				th.CPUPercent = watchCPU
+			}
+			if cmd.Flags().Changed("memory") {
+				th.MemoryPercent = watchMem
+			}
+			if cmd.Flags().Changed("pids") {
+				th.PIDs = watchPIDs
+			}
+			if cmd.Flags().Changed("for") {
+				th.Sustain = watchSustain
+			}
+			if cmd.Flags().Changed("interval") {
+				interval = watchInterval
+			}
+			if !th.Enabled() {
+				return fmt.Errorf("no thresholds set: pass --cpu, --memory, or --pids, or set them under xray in config.yaml")
+			}
+			if interval <= 0 {
+				return fmt.Errorf("--interval must be positive")
+			}
+
+			inspector, err := xray.NewInspector()
+			if err != nil {
+				return err
+			}
+			cfgDir, err := config.DefaultConfigDir()
+			if err != nil {
+				return err
+			}
+			logger, err := audit.NewLoggerWithSinks(filepath.Join(cfgDir, "audit", "audit.log"), cfg.Audit.Sinks)
+			if err != nil {
+				return fmt.Errorf("failed to open audit log: %w", err)
+			}
+			defer logger.Close()
+
+			fmt.Printf("🩻 Watching AegisClaw containers every %s (cpu>%.0f%% mem>%.0f%% pids>%d for %s; 0 = off)\n",
+				interval, th.CPUPercent, th.MemoryPercent, th.PIDs, th.Sustain)
+			ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+			return inspector.Watch(ctx, th, interval, func(a xray.Anomaly) {
+				fmt.Printf("🚨 %s  %s\n", time.Now().Format("15:04:05"), a)
+				_ = logger.LogAs("xray.anomaly", nil, "alert", audit.SystemActor("xray"), a.Details())
+			})
+		},
+	}
+	watchCmd.Flags().Float64Var(&watchCPU, "cpu", 0, "CPU percent threshold")
+	watchCmd.Flags().Float64Var(&watchMem, "memory", 0, "Memory percent (of the container limit) threshold")
+	watchCmd.Flags().Uint64Var(&watchPIDs, "pids", 0, "Process count threshold")
+	watchCmd.Flags().DurationVar(&watchSustain, "for", xray.DefaultSustain, "How long a threshold must be exceeded before alerting")
+	watchCmd.Flags().DurationVar(&watchInterval, "interval", xray.DefaultWatchInterval, "Sampling interval")
+
+	var captureOpts xray.CaptureOptions
+	captureCmd := &cobra.Command{
+		Use:   "capture [container-id]",
+		Short: "Save a forensic snapshot of a container for incident response",
+		Long: `Collects a container's inspect output (environment values redacted), stats
+sampled over a short window, its process list, and the proxy egress
+decisions and eBPF events recorded in the audit log since it started, into
+a timestamped JSON bundle under ~/.aegisclaw/incidents/ for attaching to a
+report.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inspector, err := xray.NewInspector()
+			if err != nil {
+				return err
+			}
+			cfgDir, err := config.DefaultConfigDir()
+			if err != nil {
+				return err
+			}
+			captureOpts.AuditLog = filepath.Join(cfgDir, "audit", "audit.log")
+
+			fmt.Printf("🩻 Capturing %s...\n", args[0])
+			bundle, err := inspector.Capture(cmd.Context(), args[0], captureOpts)
+			if err != nil {
+				return err
+			}
+			path, err := xray.WriteBundle(xray.IncidentsDir(cfgDir), bundle)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("   %d stats samples, %d processes, %d egress decisions, %d kernel events\n",
+				len(bundle.Stats), len(bundle.Processes), len(bundle.Egress), len(bundle.KernelEvents))
+			for section, msg := range bundle.Errors {
+				fmt.Printf("   ⚠️  %s not captured: %s\n", section, msg)
+			}
+			fmt.Printf("✅ Bundle saved to %s\n", path)
+			return nil
+		},
+	}
+	captureCmd.Flags().IntVar(&captureOpts.Samples, "samples", xray.DefaultCaptureSamples, "Number of stats samples to take")
+	captureCmd.Flags().DurationVar(&captureOpts.Interval, "interval", xray.DefaultCaptureInterval, "Time between stats samples")
+	captureCmd.Flags().DurationVar(&captureOpts.Lookback, "lookback", xray.DefaultCaptureLookback, "How far back to collect audit events (never before the container started)")
+
 	cmd.AddCommand(listCmd)
 	cmd.AddCommand(inspectCmd)
+	var diffLive time.Duration
+	diffCmd := &cobra.Command{
+		Use:   "diff <snapshot-a> <snapshot-b> | diff --live <interval> <container-id>",
+		Short: "Show what changed between two snapshots of a container",
+		Long: `Compares two snapshots of a container: saved 'xray inspect' output or
+'xray capture' bundles. With --live, takes two samples of a running
+container the given interval apart instead. Shows the CPU, memory and PID
+deltas, processes and network interfaces that appeared or went away, and
+the traffic in between.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if diffLive > 0 {
+				return cobra.ExactArgs(1)(cmd, args)
+			}
+			return cobra.ExactArgs(2)(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var a, b xray.Snapshot
+			if diffLive > 0 {
+				inspector, err := xray.NewInspector()
+				if err != nil {
+					return err
+				}
+				first, err := inspector.Inspect(cmd.Context(), args[0])
+				if err != nil {
+					return err
+				}
+				fmt.Printf("🩻 Sampling %s again in %s...\n", args[0], diffLive)
+				select {
+				case <-cmd.Context().Done():
+					return cmd.Context().Err()
+				case <-time.After(diffLive):
+				}
+				second, err := inspector.Inspect(cmd.Context(), args[0])
+				if err != nil {
+					return err
+				}
+				a, b = *first, *second
+			} else {
+				var err error
+				if a, err = xray.LoadSnapshot(args[0]); err != nil {
+					return err
+				}
+				if b, err = xray.LoadSnapshot(args[1]); err != nil {
+					return err
+				}
+			}
+			if a.ContainerID != b.ContainerID {
+				fmt.Printf("⚠️  Comparing different containers: %s and %s\n", a.ContainerID, b.ContainerID)
+			}
+			printSnapshotDiff(xray.Diff(a, b))
+			return nil
+		},
+	}
+	diffCmd.Flags().DurationVar(&diffLive, "live", 0, "Sample the running container twice, this far apart")
+
+	cmd.AddCommand(watchCmd)
+	cmd.AddCommand(captureCmd)
+	cmd.AddCommand(diffCmd)
 	return cmd
 }
 
+// printSnapshotDiff renders an xray diff for the terminal.
+func printSnapshotDiff(d xray.SnapshotDiff) {
+	fmt.Printf("🩻 %s: %s → %s\n", d.ContainerID, d.From, d.To)
+	fmt.Printf("     CPU:     %+.1f%%\n", d.CPUPercent)
+	fmt.Printf("     Memory:  %+.1f MB (%+.1f%%)\n", d.MemoryMB, d.MemoryPct)
+	fmt.Printf("     PIDs:    %+d\n", d.PIDs)
+	for _, n := range d.Network {
+		fmt.Printf("     Net[%s]: RX %+.1f KB / TX %+.1f KB\n", n.Interface, float64(n.RxBytes)/1024, float64(n.TxBytes)/1024)
+	}
+	for _, name := range d.AddedInterfaces {
+		fmt.Printf("   + interface %s\n", name)
+	}
+	for _, name := range d.RemovedInterfaces {
+		fmt.Printf("   - interface %s\n", name)
+	}
+	for _, p := range d.AddedProcesses {
+		fmt.Printf("   + [%s] %s %s\n", p.PID, p.User, p.Command)
+	}
+	for _, p := range d.RemovedProcesses {
+		fmt.Printf("   - [%s] %s %s\n", p.PID, p.User, p.Command)
+	}
+}
+
 func marketplaceCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "marketplace",
@@ -1099,6 +2031,7 @@ func marketplaceCmd() *cobra.Command {
 	}
 	searchCmd.Flags().String("sort", "rating", "Sort results by: rating, downloads")
 
+	var allowExternal bool
 	refreshCmd := &cobra.Command{
 		Use:   "refresh",
 		Short: "Refresh the marketplace index cache",
@@ -1107,33 +2040,54 @@ func marketplaceCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			if cfg.Registry.URL == "" {
+			var sources []marketplace.Source
+			if cfg.Registry.URL != "" {
+				sources = append(sources, marketplace.Source{URL: cfg.Registry.URL, Badge: marketplace.SecurityBadge(cfg.Registry.Badge)})
+			}
+			for _, s := range cfg.Registry.Sources {
+				sources = append(sources, marketplace.Source{Name: s.Name, URL: s.URL, Badge: marketplace.SecurityBadge(s.Badge)})
+			}
+			if len(sources) == 0 {
 				fmt.Println("Registry URL not configured in config.yaml.")
-				fmt.Println("Set registry.url to your marketplace endpoint.")
+				fmt.Println("Set registry.url (and optionally registry.sources) to your marketplace endpoints.")
 				return nil
 			}
 
-			regIdx, err := skill.SearchRegistry(cfg.Registry.URL)
-			if err != nil {
-				return fmt.Errorf("fetch registry: %w", err)
-			}
+			var lists [][]marketplace.SkillEntry
+			var names []string
+			for _, src := range sources {
+				regIdx, err := skill.SearchRegistry(src.URL)
+				if err != nil {
+					fmt.Printf("⚠️  Skipping registry %s: %v\n", src.Label(), err)
+					continue
+				}
+				if src.Name == "" && regIdx.RegistryName != "" {
+					src.Name = regIdx.RegistryName
+				}
+				entries := marketplace.FromRegistry(src, regIdx)
 
-			// Convert to marketplace index
-			var entries []marketplace.SkillEntry
-			for _, s := range regIdx.Skills {
-				entries = append(entries, marketplace.SkillEntry{
-					Name:        s.Name,
-					Version:     s.Version,
-					Description: s.Description,
-					Badge:       marketplace.BadgeCommunity,
-					ManifestURL: s.ManifestURL,
-				})
+				if !allowExternal {
+					var rejected []error
+					entries, rejected = marketplace.FilterOnRegistry(src.URL, entries, cfg.Registry.AllowedHosts)
+					for _, err := range rejected {
+						fmt.Printf("⚠️  Skipping %v\n", err)
+					}
+					if len(rejected) > 0 {
+						fmt.Println("   Add trusted hosts to registry.allowed_hosts or pass --allow-external to keep them.")
+					}
+				}
+				lists = append(lists, entries)
+				names = append(names, src.Label())
 			}
+			if len(lists) == 0 {
+				return fmt.Errorf("fetch registry: no registry could be reached")
+			}
+			entries := marketplace.Merge(lists...)
 
 			cfgDir, _ := config.DefaultConfigDir()
 			cache := marketplace.NewCache(filepath.Join(cfgDir, "marketplace"))
 			idx := &marketplace.Index{
-				Name:   regIdx.RegistryName,
+				Name:   strings.Join(names, ", "),
 				URL:    cfg.Registry.URL,
 				Skills: entries,
 			}
@@ -1141,10 +2095,11 @@ func marketplaceCmd() *cobra.Command {
 				return err
 			}
 
-			fmt.Printf("Refreshed marketplace index: %d skills cached.\n", len(entries))
+			fmt.Printf("Refreshed marketplace index: %d skills cached from %s.\n", len(entries), idx.Name)
 			return nil
 		},
 	}
+	refreshCmd.Flags().BoolVar(&allowExternal, "allow-external", false, "Keep entries whose manifest or bundle URL points off the registry host")
 
 	infoCmd := &cobra.Command{
 		Use:   "info [skill-name]",
@@ -1168,6 +2123,9 @@ func marketplaceCmd() *cobra.Command {
 				if e.Name == args[0] {
 					data, _ := json.MarshalIndent(e, "", "  ")
 					fmt.Println(string(data))
+					if e.Provenance == nil {
+						fmt.Println("⚠️  No provenance metadata published for this skill.")
+					}
 					return nil
 				}
 			}
@@ -1237,8 +2195,149 @@ func clusterCmd() *cobra.Command {
 	joinCmd.Flags().String("node-id", "node-1", "This node's ID")
 	joinCmd.Flags().String("address", "localhost:9091", "This node's gRPC address")
 
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Answer cluster requests (e.g. audit verification, skill distribution) from other nodes",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			nodeID, _ := cmd.Flags().GetString("node-id")
+			addr, _ := cmd.Flags().GetString("address")
+			role, _ := cmd.Flags().GetString("role")
+
+			cfgDir, err := config.DefaultConfigDir()
+			if err != nil {
+				return err
+			}
+			node := cluster.NewNode(nodeID, addr, cluster.NodeRole(role), version)
+			node.SetAuditLog(filepath.Join(cfgDir, "audit", "audit.log"))
+			if cfg, err := config.LoadDefault(); err == nil {
+				node.SetSkillStore(filepath.Join(cfgDir, "skills"), cfg.Registry.TrustKeys)
+			} else {
+				fmt.Printf("⚠️  No configuration loaded; distributed skills will be refused: %v\n", err)
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+			fmt.Printf("🛰️  Node %s serving cluster requests on %s\n", nodeID, addr)
+			return node.StartServer(ctx)
+		},
+	}
+	serveCmd.Flags().String("node-id", "node-1", "This node's ID")
+	serveCmd.Flags().String("address", "localhost:9091", "This node's gRPC address")
+	serveCmd.Flags().String("role", "follower", "Node role: leader or follower")
+
+	auditCmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Fleet-wide audit operations",
+	}
+	auditVerifyCmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify the audit chain on this node and every peer",
+		Long: `Verify this node's audit log and ask each --peer (running 'cluster serve')
+to verify its own, reporting every node's chain head. Exits non-zero if any
+node's chain is broken or a peer cannot be reached.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			nodeID, _ := cmd.Flags().GetString("node-id")
+			peers, _ := cmd.Flags().GetStringSlice("peer")
+			timeout, _ := cmd.Flags().GetDuration("timeout")
+
+			cfgDir, err := config.DefaultConfigDir()
+			if err != nil {
+				return err
+			}
+			node := cluster.NewNode(nodeID, "", cluster.RoleLeader, version)
+			node.SetAuditLog(filepath.Join(cfgDir, "audit", "audit.log"))
+			for _, p := range peers {
+				node.RegisterPeer(cluster.NodeInfo{ID: p, Address: p, Role: cluster.RoleFollower})
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+			defer cancel()
+			fleet := node.VerifyFleetAudit(ctx)
+
+			fmt.Printf("🔍 Audit verification across %d node(s):\n", len(fleet.Nodes))
+			for _, v := range fleet.Nodes {
+				if v.Valid {
+					fmt.Printf("  ✅ %-20s %d entries, head %.12s\n", v.NodeID, v.Entries, v.Head)
+				} else {
+					fmt.Printf("  ❌ %-20s %s\n", v.NodeID, v.Error)
+				}
+			}
+			if !fleet.Intact() {
+				fmt.Printf("❌ Audit integrity not verified on %d node(s): %s\n", len(fleet.Broken), strings.Join(fleet.Broken, ", "))
+				os.Exit(1)
+			}
+			fmt.Println("✅ All audit chains intact.")
+			return nil
+		},
+	}
+	auditVerifyCmd.Flags().String("node-id", "node-1", "This node's ID")
+	auditVerifyCmd.Flags().StringSlice("peer", nil, "Address of a peer node to verify (repeatable)")
+	auditVerifyCmd.Flags().Duration("timeout", 30*time.Second, "How long to wait for peers")
+	auditCmd.AddCommand(auditVerifyCmd)
+
+	skillsCmd := &cobra.Command{
+		Use:   "skills",
+		Short: "Fleet-wide skill operations",
+	}
+	distributeCmd := &cobra.Command{
+		Use:   "distribute [SKILL_NAME]",
+		Short: "Push an installed signed skill to every peer",
+		Long: `Send this node's installed copy of a signed skill to each --peer (running
+'cluster serve'), which verifies the signature against its own registry trust
+keys before installing it. Exits non-zero if any peer did not install it.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			nodeID, _ := cmd.Flags().GetString("node-id")
+			peers, _ := cmd.Flags().GetStringSlice("peer")
+			timeout, _ := cmd.Flags().GetDuration("timeout")
+
+			cfgDir, err := config.DefaultConfigDir()
+			if err != nil {
+				return err
+			}
+			bundle, err := cluster.LoadSkillBundle(filepath.Join(cfgDir, "skills"), args[0])
+			if err != nil {
+				return err
+			}
+			node := cluster.NewNode(nodeID, "", cluster.RoleLeader, version)
+			for _, p := range peers {
+				node.RegisterPeer(cluster.NodeInfo{ID: p, Address: p, Role: cluster.RoleFollower})
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+			defer cancel()
+			fmt.Printf("📦 Distributing skill '%s' v%s to %d node(s):\n", bundle.Name, bundle.Version, len(peers))
+			results, err := node.DistributeSkill(ctx, bundle)
+			if err != nil {
+				return err
+			}
+			failed := 0
+			for _, r := range results {
+				if r.Installed {
+					fmt.Printf("  ✅ %-20s installed v%s\n", r.NodeID, r.Version)
+				} else {
+					failed++
+					fmt.Printf("  ❌ %-20s %s\n", r.NodeID, r.Error)
+				}
+			}
+			if failed > 0 {
+				fmt.Printf("❌ Skill not installed on %d of %d node(s).\n", failed, len(results))
+				os.Exit(1)
+			}
+			fmt.Println("✅ Skill installed on every node.")
+			return nil
+		},
+	}
+	distributeCmd.Flags().String("node-id", "node-1", "This node's ID")
+	distributeCmd.Flags().StringSlice("peer", nil, "Address of a peer node to install on (repeatable)")
+	distributeCmd.Flags().Duration("timeout", 30*time.Second, "How long to wait for peers")
+	skillsCmd.AddCommand(distributeCmd)
+
 	cmd.AddCommand(statusCmd)
 	cmd.AddCommand(joinCmd)
+	cmd.AddCommand(serveCmd)
+	cmd.AddCommand(auditCmd)
+	cmd.AddCommand(skillsCmd)
 	return cmd
 }
 
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mackeh/AegisClaw/internal/config"
+	"github.com/mackeh/AegisClaw/internal/telemetry"
+)
+
+func TestRootCmd_ProfileFlagAppliesBeforeConfigLoad(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(config.ProfileEnv, "")
+	t.Cleanup(func() { config.SetProfile(""); telemetry.SetMaxLabelValues(0) })
+
+	cfgDir := filepath.Join(home, ".aegisclaw")
+	if err := os.MkdirAll(cfgDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	cfg := `guardrails:
+  mode: warn
+telemetry:
+  max_label_values: 10
+profiles:
+  prod:
+    guardrails:
+      mode: block
+    telemetry:
+      max_label_values: 50
+`
+	if err := os.WriteFile(filepath.Join(cfgDir, "config.yaml"), []byte(cfg), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	root := newRootCmd()
+	root.SetArgs([]string{"--profile", "prod", "config", "get", "guardrails.mode"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("aegisclaw --profile prod config get: %v", err)
+	}
+	// setupCLI applied the prod profile's telemetry.max_label_values, so
+	// exactly 50 skill names get their own series.
+	kept := 0
+	for i := 0; i < 60; i++ {
+		if telemetry.SkillLabel(fmt.Sprintf("profile-test-%d", i)) != telemetry.OtherLabel {
+			kept++
+		}
+	}
+	if kept != 50 {
+		t.Errorf("%d skill labels kept, want 50 from the prod profile", kept)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mackeh/AegisClaw/internal/agent"
+	"github.com/mackeh/AegisClaw/internal/config"
+	"github.com/mackeh/AegisClaw/internal/skill"
+	"github.com/spf13/cobra"
+)
+
+func runOnceCmd() *cobra.Command {
+	var pipeStdin, explain bool
+	cmd := &cobra.Command{
+		Use:   "run-once <skill> <command> [args...]",
+		Short: "Run a single skill command and exit with its exit code",
+		Long: `Run one command of an installed skill through the full agent path
+(policy, approval, audit, redacted output) and exit with the skill's exit
+code. This is the scriptable counterpart to the interactive 'run' REPL.
+
+Without a terminal, commands that need approval are refused unless the
+scopes were approved with "always" before. Exit codes: the skill's own code
+if it ran, 77 if refused by policy or approval, 75 during an emergency
+lockdown, 127 if the skill is not installed, and 1 for other failures.
+
+With --stdin, this command's standard input is piped into the skill
+(e.g. 'aegisclaw run-once --stdin csv2json convert < data.csv'), after the
+input guardrails have checked it.
+
+A denial always prints the policy rule that caused it; --explain prints how
+every requested scope was decided.`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfgDir, err := config.DefaultConfigDir()
+			if err != nil {
+				return err
+			}
+			var stdin io.Reader
+			if pipeStdin {
+				stdin = os.Stdin
+			}
+			ctx := cmd.Context()
+			if explain {
+				ctx = agent.WithExplain(ctx)
+			}
+			code, err := agent.RunOnceWithStdin(ctx, skill.SearchPaths(cfgDir), args[0], args[1], args[2:], stdin)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			}
+			if code != 0 {
+				os.Exit(code)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&pipeStdin, "stdin", false, fmt.Sprintf("Pipe standard input into the skill (up to %d MB)", agent.MaxStdinBytes>>20))
+	cmd.Flags().BoolVar(&explain, "explain", false, "Show how the policy decided each scope")
+	return cmd
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mackeh/AegisClaw/internal/agent"
+	"github.com/mackeh/AegisClaw/internal/audit"
+	"github.com/mackeh/AegisClaw/internal/config"
+	"github.com/mackeh/AegisClaw/internal/sandbox"
+	"github.com/spf13/cobra"
+)
+
+func runsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "runs",
+		Short: "Inspect skill execution provenance records",
+	}
+
+	var limit int
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List recent skill runs",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfgDir, err := config.DefaultConfigDir()
+			if err != nil {
+				return err
+			}
+			records, err := agent.ListRunRecords(agent.RunsDir(cfgDir))
+			if err != nil {
+				return err
+			}
+			if len(records) == 0 {
+				fmt.Println("📭 No runs recorded.")
+				return nil
+			}
+			if limit > 0 && len(records) > limit {
+				records = records[:limit]
+			}
+
+			fmt.Println("🧾 Skill Runs:")
+			for _, r := range records {
+				status := "✅"
+				if r.Error != "" || r.ExitCode != 0 {
+					status = "❌"
+				} else if r.Detached {
+					status = "🔁"
+				}
+				exit := fmt.Sprintf("exit=%d", r.ExitCode)
+				if r.Reason != "" && r.Reason != sandbox.ExitCompleted {
+					exit += " (" + r.Reason + ")"
+				}
+				fmt.Printf("  %s %s  %s %s/%s  %s  %s\n",
+					status, r.ID, r.StartedAt.Local().Format(time.RFC3339), r.Skill, r.Command, exit,
+					time.Duration(r.DurationMS)*time.Millisecond)
+			}
+			return nil
+		},
+	}
+	listCmd.Flags().IntVarP(&limit, "limit", "n", 20, "Maximum number of runs to show (0 for all)")
+	cmd.AddCommand(listCmd)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "show [RUN_ID]",
+		Short: "Show the full provenance record of a run",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfgDir, err := config.DefaultConfigDir()
+			if err != nil {
+				return err
+			}
+			r, err := agent.LoadRunRecord(agent.RunsDir(cfgDir), args[0])
+			if err != nil {
+				return err
+			}
+			data, err := json.MarshalIndent(r, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(data))
+			return nil
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "kill [RUN_ID]",
+		Short: "Stop a running skill and its container",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := agent.KillRun(cmd.Context(), args[0], audit.CLIActor()); err != nil {
+				return err
+			}
+			fmt.Printf("🛑 Run %s killed.\n", args[0])
+			return nil
+		},
+	})
+
+	return cmd
+}
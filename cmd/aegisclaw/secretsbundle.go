@@ -0,0 +1,149 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/charmbracelet/huh"
+	"github.com/mackeh/AegisClaw/internal/config"
+	"github.com/mackeh/AegisClaw/internal/secrets"
+	"github.com/spf13/cobra"
+)
+
+// bundlePassphraseEnv supplies the bundle passphrase non-interactively, e.g.
+// when provisioning a machine from a script.
+const bundlePassphraseEnv = "AEGISCLAW_BUNDLE_PASSPHRASE"
+
+func secretsExportCmd() *cobra.Command {
+	var out string
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export secrets to a passphrase-encrypted bundle",
+		Long: `Writes every stored secret to an age bundle encrypted with a passphrase
+(scrypt), so it can be moved to another machine with "secrets import".
+The bundle does not depend on this machine's key file.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfgDir, err := config.DefaultConfigDir()
+			if err != nil {
+				return err
+			}
+			mgr := secrets.NewManager(filepath.Join(cfgDir, "secrets"))
+
+			passphrase, err := bundlePassphrase(true)
+			if err != nil {
+				return err
+			}
+
+			f, err := os.OpenFile(out, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+			if err != nil {
+				return fmt.Errorf("failed to create bundle: %w", err)
+			}
+			n, err := mgr.Export(f, passphrase)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				os.Remove(out)
+				return err
+			}
+
+			fmt.Printf("📦 Exported %d secret(s) to %s\n", n, out)
+			fmt.Println("⚠️  Anyone with the bundle and passphrase can read these secrets.")
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&out, "out", "o", "secrets.age", "Bundle file to write")
+	return cmd
+}
+
+func secretsImportCmd() *cobra.Command {
+	var overwrite bool
+	cmd := &cobra.Command{
+		Use:   "import [BUNDLE]",
+		Short: "Import secrets from a passphrase-encrypted bundle",
+		Long: `Decrypts a bundle written by "secrets export" and merges it into the
+local store. Secrets that already exist are kept unless --overwrite is set.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfgDir, err := config.DefaultConfigDir()
+			if err != nil {
+				return err
+			}
+			mgr := secrets.NewManager(filepath.Join(cfgDir, "secrets"))
+
+			f, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to open bundle: %w", err)
+			}
+			defer f.Close()
+
+			passphrase, err := bundlePassphrase(false)
+			if err != nil {
+				return err
+			}
+
+			imported, skipped, err := mgr.Import(f, passphrase, overwrite)
+			if errors.Is(err, secrets.ErrBadPassphrase) {
+				return fmt.Errorf("could not decrypt %s: %w", args[0], err)
+			}
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("🔐 Imported %d secret(s) from %s\n", len(imported), args[0])
+			for _, k := range imported {
+				fmt.Printf("  • %s\n", k)
+			}
+			if len(skipped) > 0 {
+				fmt.Printf("⚠️  Kept %d existing secret(s) (use --overwrite to replace):\n", len(skipped))
+				for _, k := range skipped {
+					fmt.Printf("  • %s\n", k)
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&overwrite, "overwrite", false, "Replace secrets that already exist locally")
+	return cmd
+}
+
+// bundlePassphrase reads the bundle passphrase from the environment or, on
+// a terminal, a hidden prompt. confirm asks for it twice.
+func bundlePassphrase(confirm bool) (string, error) {
+	if p := os.Getenv(bundlePassphraseEnv); p != "" {
+		return p, nil
+	}
+
+	var passphrase, again string
+	fields := []huh.Field{
+		huh.NewInput().
+			Title("Bundle passphrase").
+			EchoMode(huh.EchoModePassword).
+			Validate(func(s string) error {
+				if s == "" {
+					return errors.New("passphrase cannot be empty")
+				}
+				return nil
+			}).
+			Value(&passphrase),
+	}
+	if confirm {
+		fields = append(fields, huh.NewInput().
+			Title("Confirm passphrase").
+			EchoMode(huh.EchoModePassword).
+			Validate(func(s string) error {
+				if s != passphrase {
+					return errors.New("passphrases do not match")
+				}
+				return nil
+			}).
+			Value(&again))
+	}
+	if err := huh.NewForm(huh.NewGroup(fields...)).Run(); err != nil {
+		return "", fmt.Errorf("passphrase required (or set %s): %w", bundlePassphraseEnv, err)
+	}
+	return passphrase, nil
+}
@@ -17,18 +17,19 @@ decision = "allow" if {
 	input.skill_signed == true
 }
 
-# Always require approval for shell execution.
+# Always require approval for the shell and secrets families, e.g. shell.exec,
+# secrets.access and secrets.write: input.scope.parents lists the broader
+# scopes a scope belongs to. Unsigned critical requests are denied instead.
 decision = "require_approval" if {
-	input.scope.name == "shell.exec"
-}
-
-# Always require approval for secret access.
-decision = "require_approval" if {
-	input.scope.name == "secrets.access"
+	some family in {"shell", "secrets"}
+	family in input.scope.parents
+	not unsigned_critical
 }
 
 # Deny unsigned skills requesting critical scopes.
-decision = "deny" if {
+decision = "deny" if unsigned_critical
+
+unsigned_critical if {
 	input.scope.risk == "critical"
 	not input.skill_signed
 }
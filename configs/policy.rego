@@ -10,9 +10,12 @@ decision = "allow" if {
 	is_safe_path(input.scope.resource)
 }
 
-# Always require approval for shell execution (redundant due to default, but explicit)
+# Always require approval for the shell and secrets families (redundant due to
+# default, but explicit). input.scope.parents lists the broader scopes a scope
+# belongs to, so "secrets" covers secrets.access and secrets.write alike.
 decision = "require_approval" if {
-	input.scope.name == "shell.exec"
+	some family in {"shell", "secrets"}
+	family in input.scope.parents
 }
 
 # Allow specific low risk actions (example)
@@ -0,0 +1,168 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mackeh/AegisClaw/internal/audit"
+	"github.com/mackeh/AegisClaw/internal/config"
+	"github.com/mackeh/AegisClaw/internal/sandbox"
+)
+
+// ErrRunNotFound is returned by KillRun when no active run has the given ID.
+var ErrRunNotFound = errors.New("no active run with that ID")
+
+// ActiveRun describes a skill run that is currently executing in this
+// process.
+type ActiveRun struct {
+	ID          string    `json:"id"`
+	Skill       string    `json:"skill"`
+	Command     string    `json:"command"`
+	ContainerID string    `json:"container_id,omitempty"`
+	StartedAt   time.Time `json:"started_at"`
+}
+
+type activeRun struct {
+	ActiveRun
+	cancel context.CancelFunc
+	logger *audit.Logger
+	killed bool
+}
+
+// activeRuns is the in-process registry of executing runs, keyed by run ID.
+var activeRuns = struct {
+	sync.Mutex
+	runs map[string]*activeRun
+}{runs: map[string]*activeRun{}}
+
+// killContainer and findRunContainers are variables so tests can stub out
+// Docker.
+var (
+	killContainer = func(ctx context.Context, id string) error {
+		exec, err := sandbox.NewDockerExecutor()
+		if err != nil {
+			return err
+		}
+		return exec.KillContainer(ctx, id)
+	}
+	findRunContainers = func(ctx context.Context, runID string) ([]string, error) {
+		exec, err := sandbox.NewDockerExecutor()
+		if err != nil {
+			return nil, err
+		}
+		return exec.FindByLabel(ctx, sandbox.RunIDLabel, runID)
+	}
+)
+
+// registerRun adds a run to the registry and returns a func that removes it.
+func registerRun(rec *RunRecord, cancel context.CancelFunc) func() {
+	activeRuns.Lock()
+	activeRuns.runs[rec.ID] = &activeRun{
+		ActiveRun: ActiveRun{ID: rec.ID, Skill: rec.Skill, Command: rec.Command, StartedAt: time.Now().UTC()},
+		cancel:    cancel,
+	}
+	activeRuns.Unlock()
+	return func() {
+		activeRuns.Lock()
+		delete(activeRuns.runs, rec.ID)
+		activeRuns.Unlock()
+	}
+}
+
+// updateRun applies fn to a registered run, if it is still active.
+func updateRun(id string, fn func(r *activeRun)) {
+	activeRuns.Lock()
+	defer activeRuns.Unlock()
+	if r, ok := activeRuns.runs[id]; ok {
+		fn(r)
+	}
+}
+
+// runKilled reports whether KillRun was called for an active run.
+func runKilled(id string) bool {
+	activeRuns.Lock()
+	defer activeRuns.Unlock()
+	r, ok := activeRuns.runs[id]
+	return ok && r.killed
+}
+
+// ActiveRuns lists the runs executing in this process, oldest first.
+func ActiveRuns() []ActiveRun {
+	activeRuns.Lock()
+	defer activeRuns.Unlock()
+	out := make([]ActiveRun, 0, len(activeRuns.runs))
+	for _, r := range activeRuns.runs {
+		out = append(out, r.ActiveRun)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].StartedAt.Before(out[j].StartedAt) })
+	return out
+}
+
+// KillRun stops the run with the given ID: it cancels the run's context,
+// kills its container, and records a skill.killed audit entry attributed to
+// actor. Runs started by another process (e.g. `aegisclaw serve`) are found
+// by their container label instead.
+func KillRun(ctx context.Context, id string, actor audit.Actor) error {
+	activeRuns.Lock()
+	r, ok := activeRuns.runs[id]
+	var cancel context.CancelFunc
+	var containerID string
+	var logger *audit.Logger
+	var skillName string
+	if ok {
+		r.killed = true
+		cancel, containerID, logger, skillName = r.cancel, r.ContainerID, r.logger, r.Skill
+	}
+	activeRuns.Unlock()
+
+	var containers []string
+	if ok {
+		if containerID != "" {
+			containers = []string{containerID}
+		}
+	} else {
+		found, err := findRunContainers(ctx, id)
+		if err != nil {
+			return err
+		}
+		if len(found) == 0 {
+			return fmt.Errorf("%w: %s", ErrRunNotFound, id)
+		}
+		containers = found
+	}
+
+	var killErr error
+	for _, c := range containers {
+		if err := killContainer(ctx, c); err != nil {
+			killErr = err
+		}
+	}
+	if cancel != nil {
+		cancel()
+	}
+
+	details := map[string]any{"run_id": id, "containers": containers}
+	if skillName != "" {
+		details["skill"] = skillName
+	}
+	if killErr != nil {
+		details["error"] = killErr.Error()
+	}
+	if logger == nil {
+		if cfgDir, err := config.DefaultConfigDir(); err == nil {
+			if l, err := audit.NewLogger(filepath.Join(cfgDir, "audit", "audit.log")); err == nil {
+				defer l.Close()
+				logger = l
+			}
+		}
+	}
+	if logger != nil {
+		_ = logger.LogAs("skill.killed", nil, "kill", actor, details)
+	}
+	return killErr
+}
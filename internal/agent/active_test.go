@@ -0,0 +1,88 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/mackeh/AegisClaw/internal/audit"
+)
+
+func stubRunDocker(t *testing.T, found map[string][]string) *[]string {
+	t.Helper()
+	var killed []string
+	origKill, origFind := killContainer, findRunContainers
+	killContainer = func(ctx context.Context, id string) error {
+		killed = append(killed, id)
+		return nil
+	}
+	findRunContainers = func(ctx context.Context, runID string) ([]string, error) {
+		return found[runID], nil
+	}
+	t.Cleanup(func() { killContainer, findRunContainers = origKill, origFind })
+	return &killed
+}
+
+func TestKillRun_CancelsRegisteredRun(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	killed := stubRunDocker(t, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	rec := &RunRecord{ID: "run-1", Skill: "demo", Command: "go"}
+	unregister := registerRun(rec, cancel)
+	defer unregister()
+	updateRun(rec.ID, func(r *activeRun) { r.ContainerID = "c0ffee" })
+
+	if got := ActiveRuns(); len(got) != 1 || got[0].ContainerID != "c0ffee" {
+		t.Fatalf("ActiveRuns = %+v", got)
+	}
+
+	if err := KillRun(context.Background(), "run-1", audit.SystemActor("test")); err != nil {
+		t.Fatalf("KillRun: %v", err)
+	}
+	if ctx.Err() == nil {
+		t.Error("run context should be cancelled")
+	}
+	if len(*killed) != 1 || (*killed)[0] != "c0ffee" {
+		t.Errorf("killed containers = %v, want [c0ffee]", *killed)
+	}
+	if !runKilled("run-1") {
+		t.Error("run should be marked killed")
+	}
+
+	entries, err := audit.ReadAll(filepath.Join(home, ".aegisclaw", "audit", "audit.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Action != "skill.killed" || entries[0].Actor != "test" {
+		t.Errorf("expected one skill.killed entry by test, got %+v", entries)
+	}
+}
+
+func TestKillRun_FallsBackToContainerLabel(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	killed := stubRunDocker(t, map[string][]string{"run-remote": {"abc"}})
+
+	if err := KillRun(context.Background(), "run-remote", audit.CLIActor()); err != nil {
+		t.Fatalf("KillRun: %v", err)
+	}
+	if len(*killed) != 1 || (*killed)[0] != "abc" {
+		t.Errorf("killed containers = %v, want [abc]", *killed)
+	}
+}
+
+func TestKillRun_Unknown(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	killed := stubRunDocker(t, nil)
+
+	err := KillRun(context.Background(), "nope", audit.CLIActor())
+	if !errors.Is(err, ErrRunNotFound) {
+		t.Fatalf("err = %v, want ErrRunNotFound", err)
+	}
+	if len(*killed) != 0 {
+		t.Errorf("nothing should be killed, got %v", *killed)
+	}
+}
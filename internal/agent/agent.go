@@ -3,17 +3,20 @@ package agent
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
-	"sync"
+	"strings"
 	"time"
 
 	"github.com/mackeh/AegisClaw/internal/approval"
 	"github.com/mackeh/AegisClaw/internal/audit"
 	"github.com/mackeh/AegisClaw/internal/config"
 	"github.com/mackeh/AegisClaw/internal/ebpf"
+	"github.com/mackeh/AegisClaw/internal/guardrails"
 	"github.com/mackeh/AegisClaw/internal/policy"
 	"github.com/mackeh/AegisClaw/internal/sandbox"
 	"github.com/mackeh/AegisClaw/internal/scope"
@@ -29,19 +32,69 @@ import (
 // ExecutionResult holds the captured output of a skill run
 type ExecutionResult struct {
 	ExitCode int
+	Reason   string // how the container stopped; one of the sandbox.Exit* constants
 	Stdout   string
 	Stderr   string
+	Record   *RunRecord // provenance record, also persisted under ~/.aegisclaw/runs
+	// ContainerID is set for detached runs, whose container is still
+	// running when the result is returned.
+	ContainerID string
+	// Hooks holds the output of the skill's before and after hooks, in the
+	// order they ran.
+	Hooks []HookResult
 }
 
 // ExecuteSkill is a wrapper for ExecuteSkillWithStream using default outputs
 func ExecuteSkill(ctx context.Context, m *skill.Manifest, cmdName string, userArgs []string) (*ExecutionResult, error) {
-	return ExecuteSkillWithStream(ctx, m, cmdName, userArgs, nil, nil)
+	return ExecuteSkillWithStream(ctx, m, cmdName, userArgs, nil, nil, nil)
 }
 
-// ExecuteSkillWithStream handles execution with optional real-time streaming
-func ExecuteSkillWithStream(ctx context.Context, m *skill.Manifest, cmdName string, userArgs []string, stdoutStream, stderrStream io.Writer) (*ExecutionResult, error) {
+// ExecuteSkillWithStream handles execution with optional real-time streaming.
+// A non-nil stdin is piped into the skill's standard input (at most
+// MaxStdinBytes) after the input guardrails have checked it. Every run,
+// successful or not, leaves a RunRecord in ~/.aegisclaw/runs.
+func ExecuteSkillWithStream(ctx context.Context, m *skill.Manifest, cmdName string, userArgs []string, stdin io.Reader, stdoutStream, stderrStream io.Writer) (*ExecutionResult, error) {
+	return executeWithRecord(ctx, m, cmdName, userArgs, nil, stdin, stdoutStream, stderrStream)
+}
+
+// ExecuteSkillWithInputs runs a skill with files placed under
+// sandbox.InputDir inside the container, keyed by relative path. No host
+// directory is mounted.
+func ExecuteSkillWithInputs(ctx context.Context, m *skill.Manifest, cmdName string, userArgs []string, files map[string][]byte) (*ExecutionResult, error) {
+	return executeWithRecord(ctx, m, cmdName, userArgs, files, nil, nil, nil)
+}
+
+// ExecuteSkillDetached starts a long-running skill, waits until its health
+// probe passes, and returns while the container keeps running. The result
+// carries the container ID and no output; stop the run with KillRun.
+func ExecuteSkillDetached(ctx context.Context, m *skill.Manifest, cmdName string, userArgs []string) (*ExecutionResult, error) {
+	rec := newRunRecord(m, cmdName)
+	rec.Detached = true
+	res, err := executeSkill(ctx, m, cmdName, userArgs, nil, nil, nil, nil, true, rec)
+	return saveRecord(rec, res, err)
+}
+
+func executeWithRecord(ctx context.Context, m *skill.Manifest, cmdName string, userArgs []string, files map[string][]byte, stdin io.Reader, stdoutStream, stderrStream io.Writer) (*ExecutionResult, error) {
+	rec := newRunRecord(m, cmdName)
+	res, err := executeSkill(ctx, m, cmdName, userArgs, files, stdin, stdoutStream, stderrStream, false, rec)
+	return saveRecord(rec, res, err)
+}
+
+// saveRecord finishes and persists rec, attaching it to res.
+func saveRecord(rec *RunRecord, res *ExecutionResult, err error) (*ExecutionResult, error) {
+	rec.finish(res, err)
+	if cfgDir, dirErr := config.DefaultConfigDir(); dirErr == nil {
+		_ = SaveRunRecord(RunsDir(cfgDir), rec)
+	}
+	if res != nil {
+		res.Record = rec
+	}
+	return res, err
+}
+
+func executeSkill(ctx context.Context, m *skill.Manifest, cmdName string, userArgs []string, files map[string][]byte, stdin io.Reader, stdoutStream, stderrStream io.Writer, detached bool, rec *RunRecord) (*ExecutionResult, error) {
 	if system.IsLockedDown() {
-		return nil, fmt.Errorf("SECURITY LOCKDOWN: Agent is in emergency stop mode")
+		return nil, ErrLockedDown
 	}
 
 	tr := otel.Tracer("agent")
@@ -52,20 +105,48 @@ func ExecuteSkillWithStream(ctx context.Context, m *skill.Manifest, cmdName stri
 		attribute.String("skill.name", m.Name),
 		attribute.String("skill.command", cmdName),
 	)
+	if sc := span.SpanContext(); sc.IsValid() {
+		rec.TraceID = sc.TraceID().String()
+		rec.SpanID = sc.SpanID().String()
+	}
 
 	// 1. Find Command
 	skillCmd, ok := m.Commands[cmdName]
 	if !ok {
 		return nil, fmt.Errorf("command '%s' not found in skill '%s'", cmdName, m.Name)
 	}
+	finalArgs, err := skillCmd.Render(userArgs)
+	if err != nil {
+		return nil, fmt.Errorf("command '%s': %w", cmdName, err)
+	}
+	var stdinData []byte
+	if stdin != nil {
+		if stdinData, err = readStdin(stdin); err != nil {
+			return nil, err
+		}
+	}
 
 	// 2. Prepare Scopes
 	var reqScopes []scope.Scope
 	var allowedDomains []string
 	needsNetwork := false
+	// Policy and approvals see the stored secret key; the name it is
+	// injected under is only a delivery detail.
+	secretRefs, err := m.SecretRefs()
+	if err != nil {
+		return nil, fmt.Errorf("skill '%s': %w", m.Name, err)
+	}
 	for _, sStr := range m.Scopes {
 		s, _ := scope.Parse(sStr)
+		if s.Name == scope.SecretsAccess.Name {
+			if ref, err := skill.ParseSecretRef(s.Resource); err == nil {
+				s.Resource = ref.Key
+			}
+		}
 		reqScopes = append(reqScopes, s)
+		if s.Exclude {
+			continue
+		}
 		if s.Name == "http.request" || s.Name == "email.send" {
 			needsNetwork = true
 			if s.Resource != "" {
@@ -74,6 +155,71 @@ func ExecuteSkillWithStream(ctx context.Context, m *skill.Manifest, cmdName stri
 		}
 	}
 
+	// An OpenClaw skill runs no container: each command is one request to
+	// the adapter's endpoint, which policy sees as an http.request scope.
+	var ocReq *openClawRequest
+	if m.IsOpenClaw() {
+		cfgDir, err := config.DefaultConfigDir()
+		if err != nil {
+			return nil, err
+		}
+		if ocReq, err = newOpenClawRequest(cfgDir, finalArgs); err != nil {
+			return nil, fmt.Errorf("skill '%s': %w", m.Name, err)
+		}
+		reqScopes = append(reqScopes, ocReq.scope)
+	}
+
+	// Exclusions always win: policy denies the scopes they carve out, the
+	// egress proxy refuses excluded destinations, and sandbox mounts mask
+	// excluded paths.
+	deniedDomains := scope.ExcludedResources(reqScopes, "http.request", "email.send")
+	excludedPaths := scope.ExcludedResources(reqScopes, "files.read", "files.write")
+
+	// Capabilities are re-added on top of CapDrop ALL only after policy (and,
+	// for critical ones, the user) has approved them.
+	capScopes, err := sandbox.CapabilityScopes(m.Capabilities)
+	if err != nil {
+		return nil, fmt.Errorf("skill '%s': %w", m.Name, err)
+	}
+	if err := sandbox.ValidateOutputs(m.Outputs); err != nil {
+		return nil, fmt.Errorf("skill '%s': %w", m.Name, err)
+	}
+	tmpBytes, tmpfs, err := tmpfsLimits(m.Resources)
+	if err != nil {
+		return nil, fmt.Errorf("skill '%s': %w", m.Name, err)
+	}
+	if detached {
+		filtered := append(append([]string{}, allowedDomains...), deniedDomains...)
+		if err := checkDetachable(m, needsNetwork, filtered); err != nil {
+			return nil, fmt.Errorf("skill '%s': %w", m.Name, err)
+		}
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	// Root in the container needs security.allow_root_user and then, like
+	// a critical capability, approval for the run.
+	runAs, rootScope, err := sandboxUser(cfg, m)
+	if err != nil {
+		return nil, fmt.Errorf("skill '%s': %w", m.Name, err)
+	}
+	if rootScope != nil {
+		reqScopes = append(reqScopes, *rootScope)
+	}
+	reqScopes = append(reqScopes, capScopes...)
+	var capAdd []string
+	for _, s := range capScopes {
+		capAdd = append(capAdd, s.Resource)
+	}
+	if len(capAdd) > 0 && len(sandbox.EffectiveCapabilities(runAs, capAdd)) == 0 {
+		return nil, fmt.Errorf("skill '%s': capabilities %s would not be held by user %s; only root holds added capabilities, so set user: \"0:0\" (with security.allow_root_user) or remove them", m.Name, strings.Join(capAdd, ", "), runAs)
+	}
+	for _, s := range reqScopes {
+		rec.Scopes = append(rec.Scopes, s.String())
+	}
+
 	req := scope.ScopeRequest{
 		RequestedBy: m.Name,
 		Reason:      fmt.Sprintf("Executing action '%s'", cmdName),
@@ -85,13 +231,79 @@ func ExecuteSkillWithStream(ctx context.Context, m *skill.Manifest, cmdName stri
 	if err != nil {
 		return nil, fmt.Errorf("failed to load policy: %w", err)
 	}
-
-	decision, riskyScopes, err := engine.EvaluateRequest(ctx, req)
+	engine.SetUnknownScope(unknownScopeMode(cfg))
+	rules, err := PolicyRules(cfg)
+	if err != nil {
+		return nil, err
+	}
+	engine.SetRules(rules)
+	override, skippedSigners := skillOverride(cfg, m)
+	engine.SetOverride(override)
+	engine.SetSkillSigned(skillSigned(cfg, m))
+
+	decision, riskyScopes, traces, err := engine.ExplainRequest(ctx, req)
+	if explainRequested(ctx) {
+		printTraces(os.Stdout, traces)
+	}
 	if err != nil {
 		telemetry.PolicyDecisionsTotal.WithLabelValues("error").Inc()
 		return nil, fmt.Errorf("policy evaluation failed: %w", err)
 	}
+	if decision == policy.Allow {
+		// Critical capabilities, secret writes and root are never granted
+		// on policy alone.
+		for _, s := range reqScopes {
+			if s.Exclude {
+				continue
+			}
+			if s.Name == scope.SecretsWrite.Name || s.Name == sandbox.RootUserScope || (s.Name == sandbox.CapabilityScope && s.RiskLevel == scope.RiskCritical) {
+				riskyScopes = append(riskyScopes, s)
+			}
+		}
+		if len(riskyScopes) > 0 {
+			decision = policy.RequireApproval
+		}
+	}
+	// Substituted user arguments can make a harmless command destructive in
+	// ways simulation cannot see, so the resolved argv is checked as well.
+	// In block mode a flagged command never runs; otherwise it always needs
+	// a fresh approval.
+	gMode := guardrailMode(cfg)
+	cmdCheck := checkResolvedCommand(gMode, guardrailEngine(cfg), finalArgs)
+	harmfulCmd := cmdCheck != nil && cmdCheck.Blocked()
+	if harmfulCmd {
+		reportCommandViolations(os.Stdout, m.Name, cmdCheck)
+		rec.GuardrailViolations = append(rec.GuardrailViolations, cmdCheck.Violations...)
+		if gMode == GuardrailBlock {
+			telemetry.PolicyDecisionsTotal.WithLabelValues(policy.Deny.String()).Inc()
+			rec.PolicyDecision = policy.Deny.String()
+			rec.Approval = ApprovalPolicyDeny
+			return nil, fmt.Errorf("%w: guardrails blocked the resolved command", ErrPolicyDenied)
+		}
+		if decision == policy.Allow {
+			decision = policy.RequireApproval
+		}
+		req.Reason += " (guardrails flagged the resolved command)"
+	}
+	// Piped input gets the same treatment as the command line.
+	stdinCheck := checkStdin(gMode, guardrailEngine(cfg), stdinData)
+	harmfulStdin := stdinCheck != nil && stdinCheck.Blocked()
+	if harmfulStdin {
+		reportStdinViolations(os.Stdout, m.Name, stdinCheck)
+		rec.GuardrailViolations = append(rec.GuardrailViolations, stdinCheck.Violations...)
+		if gMode == GuardrailBlock {
+			telemetry.PolicyDecisionsTotal.WithLabelValues(policy.Deny.String()).Inc()
+			rec.PolicyDecision = policy.Deny.String()
+			rec.Approval = ApprovalPolicyDeny
+			return nil, fmt.Errorf("%w: guardrails blocked the piped input", ErrPolicyDenied)
+		}
+		if decision == policy.Allow {
+			decision = policy.RequireApproval
+		}
+		req.Reason += " (guardrails flagged the piped input)"
+	}
 	telemetry.PolicyDecisionsTotal.WithLabelValues(decision.String()).Inc()
+	rec.PolicyDecision = decision.String()
 
 	finalDecision := "deny"
 
@@ -99,7 +311,14 @@ func ExecuteSkillWithStream(ctx context.Context, m *skill.Manifest, cmdName stri
 	switch decision {
 	case policy.Deny:
 		fmt.Println("❌ Policy DENIED this action.")
-		return nil, fmt.Errorf("policy denied action")
+		// ExplainRequest stops at the denied scope, so its trace is last.
+		denial := traces[len(traces)-1]
+		if !explainRequested(ctx) {
+			printTraces(os.Stdout, []policy.Trace{denial})
+		}
+		rec.Approval = ApprovalPolicyDeny
+		auditDenial(cfg, m, cmdName, rec, reqScopes, denial)
+		return nil, ErrPolicyDenied
 
 	case policy.RequireApproval:
 		// Check persistent approvals
@@ -108,7 +327,7 @@ func ExecuteSkillWithStream(ctx context.Context, m *skill.Manifest, cmdName stri
 			return nil, err
 		}
 
-		allApproved := true
+		allApproved := !harmfulCmd && !harmfulStdin
 		for _, s := range riskyScopes {
 			if store.Check(s.String()) != "always" {
 				allApproved = false
@@ -118,7 +337,12 @@ func ExecuteSkillWithStream(ctx context.Context, m *skill.Manifest, cmdName stri
 
 		if allApproved {
 			finalDecision = "allow"
+			rec.Approval = ApprovalRemembered
 			fmt.Println("✅ Auto-approved based on previous settings.")
+		} else if !interactive() {
+			fmt.Println("❌ Approval required, but there is no terminal to ask on.")
+			rec.Approval = ApprovalUnavailable
+			return nil, ErrApprovalUnavailable
 		} else {
 			// Prompt User
 			userDec, err := approval.RequestApproval(req)
@@ -128,10 +352,12 @@ func ExecuteSkillWithStream(ctx context.Context, m *skill.Manifest, cmdName stri
 
 			if userDec == "deny" {
 				fmt.Println("❌ User denied the request.")
-				return nil, fmt.Errorf("user denied request")
+				rec.Approval = ApprovalUserDenied
+				return nil, ErrUserDenied
 			}
 
 			finalDecision = "allow"
+			rec.Approval = ApprovalUser
 			if userDec == "always" {
 				for _, s := range riskyScopes {
 					_ = store.Grant(s.String(), "always")
@@ -142,99 +368,277 @@ func ExecuteSkillWithStream(ctx context.Context, m *skill.Manifest, cmdName stri
 
 	case policy.Allow:
 		finalDecision = "allow"
+		rec.Approval = ApprovalNotRequired
 	}
 
 	// 5. Audit Log (Pre-execution)
 	cfgDir, _ := config.DefaultConfigDir()
-	auditPath := filepath.Join(cfgDir, "audit", "audit.log")
-	logger, err := audit.NewLogger(auditPath)
-	if err == nil {
+	logger, err := openAuditLogger(cfg, cfgDir)
+	if err != nil {
+		logger = nil
+		if err := auditFailure(cfg, err); err != nil {
+			return nil, err
+		}
+	}
+	if logger != nil {
+		defer logger.Close()
+
+		// Anchor this run's entries to the config and policy in effect.
+		if err := logSessionStart(logger, cfgDir); err != nil {
+			fmt.Printf("⚠️  Failed to record session start: %v\n", err)
+		}
+		logOverride(logger, m.Name, override, skippedSigners, reqScopes)
+
 		// Log the attempt
-		_ = logger.Log("skill.exec", reqScopes, finalDecision, m.Name, map[string]any{
+		details := map[string]any{
 			"command": cmdName,
 			"image":   m.Image,
-		})
+			"run_id":  rec.ID,
+		}
+		if rec.TraceID != "" {
+			details["trace_id"] = rec.TraceID
+			details["span_id"] = rec.SpanID
+		}
+		if err := logger.LogAs("skill.exec", reqScopes, finalDecision, audit.SkillActor(m.Name), details); err != nil {
+			if err := auditFailure(cfg, err); err != nil {
+				return nil, err
+			}
+		}
+		if harmfulCmd {
+			for _, v := range cmdCheck.Violations {
+				_ = logger.LogAs("guardrail.violation", nil, string(v.Severity), audit.SkillActor(m.Name), map[string]any{
+					"rule":    v.Rule,
+					"message": v.Message,
+					"source":  "command:" + cmdName,
+					"run_id":  rec.ID,
+				})
+			}
+		}
+		if harmfulStdin {
+			for _, v := range stdinCheck.Violations {
+				_ = logger.LogAs("guardrail.violation", nil, string(v.Severity), audit.SkillActor(m.Name), map[string]any{
+					"rule":    v.Rule,
+					"message": v.Message,
+					"source":  "stdin:" + cmdName,
+					"run_id":  rec.ID,
+				})
+			}
+		}
 
-		// Start eBPF monitoring if supported (only on Linux)
-		mon := ebpf.NewMonitor(ebpf.ProbeConfig{
-			TraceSyscalls: true,
-			TraceFiles:    true,
-			TraceNetwork:  true,
-		})
-		mon.OnEvent(func(e ebpf.Event) {
+		// Kernel-level monitoring is opt-in via security.ebpf.
+		stopMonitor, err := startKernelMonitor(ctx, cfg, func(e ebpf.Event) {
 			_ = logger.LogKernelEvent(string(e.Type), e.Comm, e.PID, map[string]any{
 				"syscall": e.Syscall,
 				"path":    e.FilePath,
 			})
+			if e.Type == ebpf.EventNetConnect && !needsNetwork {
+				rec.addAnomaly(fmt.Sprintf("network connect by %s (pid %d) without a network scope", e.Comm, e.PID))
+			}
 		})
-		if err := mon.Start(ctx); err == nil {
-			defer mon.Stop()
-			fmt.Println("🛡️  Kernel-level eBPF monitoring active.")
+		if err != nil {
+			return nil, err
 		}
+		defer stopMonitor()
 	}
 
 	if finalDecision != "allow" {
-		return nil, fmt.Errorf("execution blocked")
+		return nil, fmt.Errorf("%w: execution blocked", ErrPolicyDenied)
+	}
+	if ocReq != nil {
+		return runOpenClaw(ctx, cfg, cfgDir, m, ocReq, stdinData, stdoutStream, logger, rec)
 	}
 
 	// 6. Prepare Execution Environment
-	finalArgs := append(skillCmd.Args, userArgs...)
 	env := append([]string{}, skillCmd.Env...)
+	env = append(env, traceContextEnv(ctx)...)
 
-	// Inject Secrets if allowed
+	// Inject Secrets if allowed, as env vars or, where the manifest asks,
+	// as files under /run/secrets.
 	var activeSecrets []string
+	var secretFiles map[string][]byte
 	if finalDecision == "allow" {
 		secretsDir := filepath.Join(cfgDir, "secrets")
 		mgr := secrets.NewManager(secretsDir)
+		mgr.OnAccess(secretAccessAuditor(logger, m.Name, cmdName, rec.ID))
 
-		for _, s := range reqScopes {
-			if s.Name == "secrets.access" && s.Resource != "" {
-				val, err := mgr.Get(s.Resource)
-				if err == nil {
-					env = append(env, fmt.Sprintf("%s=%s", s.Resource, val))
-					activeSecrets = append(activeSecrets, val)
-				} else {
-					fmt.Printf("⚠️  Warning: Secret '%s' requested but not found.\n", s.Resource)
+		secretEnv, files, values, err := injectSecrets(mgr.Get, m, secretRefs)
+		if err != nil {
+			return nil, err
+		}
+		env = append(env, secretEnv...)
+		secretFiles = files
+		activeSecrets = append(activeSecrets, values...)
+	}
+
+	// Expose the secrets write callback only for keys granted via
+	// secrets.write. The skill reaches it over the container network, so a
+	// skill without network access gets no callback.
+	var writeKeys []string
+	for _, s := range reqScopes {
+		if s.Name == scope.SecretsWrite.Name && s.Resource != "" && !s.Exclude {
+			writeKeys = append(writeKeys, s.Resource)
+		}
+	}
+	if len(writeKeys) > 0 {
+		if !needsNetwork {
+			fmt.Println("⚠️  secrets.write requires network access (http.request scope); write callback disabled.")
+		} else {
+			api, err := secrets.NewWriteAPI(secrets.NewAgeStore(filepath.Join(cfgDir, "secrets")), writeKeys)
+			if err != nil {
+				return nil, err
+			}
+			api.ListenAddr = sandbox.HostGatewayAddr()
+			api.OnWrite = func(key string) {
+				if logger != nil {
+					_ = logger.LogAs("secrets.write", []scope.Scope{{Name: scope.SecretsWrite.Name, Resource: key, RiskLevel: scope.RiskCritical}}, "allow", audit.SkillActor(m.Name), nil)
 				}
 			}
+			if err := api.Start(); err != nil {
+				return nil, err
+			}
+			defer api.Stop()
+			env = append(env,
+				fmt.Sprintf("AEGISCLAW_SECRETS_URL=http://%s:%d/v1/secrets", sandbox.ContainerHostAlias, api.Port),
+				"AEGISCLAW_SECRETS_TOKEN="+api.Token(),
+			)
+			activeSecrets = append(activeSecrets, api.Token())
 		}
 	}
 
-	// Initialize Redactor
-	scrubber := redactor.New(activeSecrets...)
+	// Initialize Redactor: known secret values plus credential-shaped
+	// patterns, so keys the store never held are scrubbed too.
+	scrubber := redactor.NewWithPatterns(activeSecrets, guardrails.SecretPatterns())
+	if logger != nil {
+		logger.SetRedactor(scrubber)
+	}
 
 	// 7. Execute
 	fmt.Printf("🚀 Running skill: %s\n", m.Name)
 
-	cfg, _ := config.LoadDefault()
 	runtime := ""
+	requireUserns := false
+	var allowedRegistries []string
+	var pullPolicy string
+	var retainFailed bool
+	var retainFor time.Duration
+	var upstreamProxy string
+	var noProxy, blockedDomains, blockedCIDRs []string
+	var dlp, ipv6 bool
+	var dns []string
 	if cfg != nil {
 		runtime = cfg.Security.SandboxRuntime
+		requireUserns = cfg.Security.RequireUsernsRemap
+		allowedRegistries = cfg.Security.AllowedRegistries
+		pullPolicy = cfg.Security.ImagePullPolicy
+		retainFailed = cfg.Security.RetainFailedContainers
+		retainFor = cfg.Security.RetainFor
+		upstreamProxy = cfg.Network.UpstreamProxy
+		noProxy = cfg.Network.NoProxy
+		blockedDomains = cfg.Network.BlockedDomains
+		blockedCIDRs = cfg.Network.BlockedCIDRs
+		dlp = cfg.Network.DLP
+		dns = cfg.Network.DNS
+		ipv6 = cfg.Network.IPv6
+	}
+
+	memory, err := m.Resources.MemoryBytes()
+	if err != nil {
+		return nil, fmt.Errorf("skill '%s': %w", m.Name, err)
+	}
+	var nanoCPUs, pids int64
+	if m.Resources != nil {
+		nanoCPUs = int64(m.Resources.CPUs * 1e9)
+		pids = m.Resources.Pids
+	}
+
+	var artifactsDir string
+	if cfgDir != "" && len(m.Outputs) > 0 {
+		artifactsDir = filepath.Join(RunsDir(cfgDir), rec.ID, "artifacts")
+	}
+
+	sbCfg := sandbox.Config{
+		Image:              m.Image,
+		Command:            finalArgs,
+		Env:                env,
+		Network:            needsNetwork,
+		AllowedDomains:     allowedDomains,
+		DeniedDomains:      deniedDomains,
+		BlockedDomains:     blockedDomains,
+		BlockedCIDRs:       blockedCIDRs,
+		ExcludedPaths:      excludedPaths,
+		AuditLogger:        logger,
+		UpstreamProxy:      upstreamProxy,
+		NoProxy:            noProxy,
+		DLP:                dlp,
+		DNS:                dns,
+		IPv6:               ipv6,
+		Runtime:            runtime,
+		CapAdd:             capAdd,
+		Files:              files,
+		SecretFiles:        secretFiles,
+		Stdin:              stdinReader(stdin, stdinData),
+		Outputs:            m.Outputs,
+		ArtifactsDir:       artifactsDir,
+		RequireUsernsRemap: requireUserns,
+		AllowedRegistries:  allowedRegistries,
+		PullPolicy:         pullPolicy,
+		MemoryBytes:        memory,
+		NanoCPUs:           nanoCPUs,
+		PidsLimit:          pids,
+		TmpBytes:           tmpBytes,
+		Tmpfs:              tmpfs,
+		User:               runAs.String(),
+		AllowRoot:          rootScope != nil,
+		RetainOnFailure:    retainFailed,
+		RetainFor:          retainFor,
+		Labels:             map[string]string{sandbox.RunIDLabel: rec.ID},
+		OnStart: func(containerID string) {
+			updateRun(rec.ID, func(r *activeRun) { r.ContainerID = containerID })
+		},
+	}
+
+	// A detached run outlives this call, so it holds no concurrency slot
+	// and no execution timeout; it runs until killed or it exits.
+	if detached {
+		return runDetached(ctx, m, sbCfg, logger, rec)
 	}
 
 	exec, err := sandbox.NewDockerExecutor()
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize executor: %w", err)
+		return nil, fmt.Errorf("%w: failed to initialize executor: %w", ErrExecutionFailed, err)
 	}
+	// Reap containers kept from earlier failed runs whose window has
+	// passed. Best effort: a failure here must not block the run.
+	_ = exec.Cleanup(ctx)
+
+	// Bound concurrent executions; time spent queued does not count
+	// against the execution timeout.
+	maxRuns, queue := concurrencyLimit(cfg)
+	release, err := limiter.acquire(ctx, maxRuns, queue)
+	if err != nil {
+		telemetry.SkillExecutionsTotal.WithLabelValues(telemetry.SkillLabel(m.Name), "rejected").Inc()
+		return nil, err
+	}
+	defer release()
 
 	// Set a default timeout for execution
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	ctx, cancel := context.WithTimeout(ctx, executionTimeout)
 	defer cancel()
 
-	result, err := exec.Run(ctx, sandbox.Config{
-		Image:          m.Image,
-		Command:        finalArgs,
-		Env:            env,
-		Network:        needsNetwork,
-		AllowedDomains: allowedDomains,
-		AuditLogger:    logger,
-		Runtime:        runtime,
-	})
-	if err != nil {
-		telemetry.SkillExecutionsTotal.WithLabelValues(m.Name, "error").Inc()
-		return nil, fmt.Errorf("execution failed: %w", err)
+	// Register the run so KillRun can stop it by ID.
+	defer registerRun(rec, cancel)()
+	if logger != nil {
+		updateRun(rec.ID, func(r *activeRun) { r.logger = logger })
 	}
-	telemetry.SkillExecutionsTotal.WithLabelValues(m.Name, "success").Inc()
+
+	// Stream pull progress to the caller (e.g. the dashboard's SSE stream)
+	// too, so a first run of a large image does not look like a hang.
+	var pullProgress io.Writer = os.Stdout
+	if stdoutStream != nil {
+		pullProgress = io.MultiWriter(os.Stdout, stdoutStream)
+	}
+
+	sbCfg.PullProgress = pullProgress
 
 	// Capture output
 	stdoutBuf := new(bytes.Buffer)
@@ -245,45 +649,226 @@ func ExecuteSkillWithStream(ctx context.Context, m *skill.Manifest, cmdName stri
 	if stdoutStream != nil {
 		stdoutWriters = append(stdoutWriters, stdoutStream)
 	}
-
 	stderrWriters := []io.Writer{os.Stderr, stderrBuf}
 	if stderrStream != nil {
 		stderrWriters = append(stderrWriters, stderrStream)
 	}
 
-	stdoutTarget := io.MultiWriter(stdoutWriters...)
-	stderrTarget := io.MultiWriter(stderrWriters...)
-
-	safeStdout := redactor.NewRedactingWriter(stdoutTarget, scrubber)
-	safeStderr := redactor.NewRedactingWriter(stderrTarget, scrubber)
-
-	var wg sync.WaitGroup
-	wg.Add(2)
-
-	go func() {
-		defer wg.Done()
-		io.Copy(safeStdout, result.Stdout)
-	}()
-
-	go func() {
-		defer wg.Done()
-		io.Copy(safeStderr, result.Stderr)
-	}()
-
-	wg.Wait()
+	// The main command runs between the skill's hooks, if it declares any.
+	var result *sandbox.Result
+	hooks := newHookRunner(exec.Run, sbCfg, m, scrubber, logger, rec.ID)
+	err = hooks.around(ctx, func() error {
+		var runErr error
+		if result, runErr = exec.Run(ctx, sbCfg); runErr == nil {
+			copyOutput(result, io.MultiWriter(stdoutWriters...), io.MultiWriter(stderrWriters...), scrubber)
+		}
+		return runErr
+	})
+	killed := runKilled(rec.ID)
+	rec.Reason = runExitReason(result, err, killed)
+	logExit(logger, m.Name, rec, result)
+	if killed {
+		telemetry.SkillExecutionsTotal.WithLabelValues(telemetry.SkillLabel(m.Name), "killed").Inc()
+		fmt.Printf("🛑 Run %s was killed.\n", rec.ID)
+		return nil, fmt.Errorf("%w: %s", ErrRunKilled, rec.ID)
+	}
+	if err != nil {
+		telemetry.SkillExecutionsTotal.WithLabelValues(telemetry.SkillLabel(m.Name), "error").Inc()
+		if rec.Reason == sandbox.ExitTimeout {
+			fmt.Printf("⏱️  Run %s timed out after %s.\n", rec.ID, executionTimeout)
+		}
+		return nil, fmt.Errorf("%w: %w", ErrExecutionFailed, err)
+	}
+	telemetry.SkillExecutionsTotal.WithLabelValues(telemetry.SkillLabel(m.Name), "success").Inc()
+	if result.Reason == sandbox.ExitOOMKilled {
+		fmt.Printf("💥 Skill '%s' was killed for exceeding its memory limit.\n", m.Name)
+	}
+	rec.ImageDigest = result.ImageDigest
+	rec.Artifacts = result.Artifacts
+	if len(result.Artifacts) > 0 {
+		fmt.Printf("📦 Collected %d artifact(s) into %s\n", len(result.Artifacts), artifactsDir)
+	}
+	if result.ArtifactErr != nil {
+		fmt.Printf("⚠️  Some outputs were not collected: %v\n", result.ArtifactErr)
+		if logger != nil {
+			_ = logger.LogAs("skill.artifacts", nil, "partial", audit.SkillActor(m.Name), map[string]any{"run_id": rec.ID, "error": result.ArtifactErr.Error()})
+		}
+	}
 
 	// 8. Scan skill output for indirect prompt injection before it can be fed
 	//    back into an agent's model context.
-	if gRes, blocked := inspectSkillOutput(guardrailMode(cfg), m.Name, stdoutBuf.String(), logger); gRes != nil && len(gRes.Violations) > 0 {
+	if gRes, blocked := inspectSkillOutput(guardrailMode(cfg), guardrailEngine(cfg), m.Name, stdoutBuf.String(), logger); gRes != nil && len(gRes.Violations) > 0 {
 		reportViolations(os.Stderr, m.Name, gRes)
+		rec.GuardrailViolations = gRes.Violations
 		if blocked {
-			return nil, fmt.Errorf("guardrails blocked skill output: %d violation(s) — treat returned data as an untrusted injection attempt", len(gRes.Violations))
+			return nil, &OutputBlockedError{Skill: m.Name, Violations: gRes.Violations}
 		}
 	}
 
 	return &ExecutionResult{
 		ExitCode: result.ExitCode,
+		Reason:   result.Reason,
 		Stdout:   stdoutBuf.String(),
 		Stderr:   stderrBuf.String(),
+		Hooks:    hooks.results,
 	}, nil
 }
+
+// loadConfig loads config.yaml for a run. A missing file means defaults
+// (nil); a file that exists but fails to load is ErrConfigInvalid, since
+// running without it would silently drop the operator's policy rules,
+// blocklists and audit requirements.
+func loadConfig() (*config.Config, error) {
+	cfg, err := config.LoadDefault()
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrConfigInvalid, err)
+	}
+	return cfg, nil
+}
+
+// unknownScopeMode returns the configured policy.unknown_scope mode. Invalid
+// values are rejected by config.Validate; here they fall back to approve.
+func unknownScopeMode(cfg *config.Config) policy.UnknownScopeMode {
+	if cfg == nil {
+		return policy.UnknownScopeApprove
+	}
+	mode, _ := policy.ParseUnknownScopeMode(cfg.Policy.UnknownScope)
+	return mode
+}
+
+// tmpfsLimits converts a manifest's tmpfs sizes for the sandbox.
+func tmpfsLimits(r *skill.Resources) (int64, []sandbox.Tmpfs, error) {
+	tmpBytes, err := r.TmpBytes()
+	if err != nil || r == nil {
+		return tmpBytes, nil, err
+	}
+	var mounts []sandbox.Tmpfs
+	for _, t := range r.Tmpfs {
+		var size int64
+		if t.Size != "" {
+			if size, err = skill.ParseMemory(t.Size); err != nil {
+				return 0, nil, fmt.Errorf("tmpfs %s: %w", t.Path, err)
+			}
+		}
+		mounts = append(mounts, sandbox.Tmpfs{Target: t.Path, SizeBytes: size})
+	}
+	return tmpBytes, mounts, sandbox.ValidateTmpfs(mounts)
+}
+
+// sandboxUser resolves the user m runs as: its manifest's user, else
+// security.sandbox_user, else sandbox.DefaultUser. Root is refused unless
+// security.allow_root_user is set, in which case the returned scope puts the
+// run through policy and approval.
+func sandboxUser(cfg *config.Config, m *skill.Manifest) (sandbox.User, *scope.Scope, error) {
+	spec, from := m.User, "user"
+	if spec == "" && cfg != nil && cfg.Security.SandboxUser != "" {
+		spec, from = cfg.Security.SandboxUser, "security.sandbox_user"
+	}
+	u, err := sandbox.ParseUser(spec)
+	if err != nil {
+		return sandbox.User{}, nil, fmt.Errorf("%s: %w", from, err)
+	}
+	if !u.IsRoot() {
+		return u, nil, nil
+	}
+	if cfg == nil || !cfg.Security.AllowRootUser {
+		return sandbox.User{}, nil, fmt.Errorf("%s %s runs as root; set security.allow_root_user to permit it (each run still needs approval)", from, u)
+	}
+	return u, &scope.Scope{Name: sandbox.RootUserScope, Resource: u.String(), RiskLevel: scope.RiskCritical}, nil
+}
+
+// skillOverride merges the policy.overrides entries for m. An entry with a
+// signer applies only if m's signature verifies against that key; the
+// signers of entries that failed the check are returned as skipped.
+func skillOverride(cfg *config.Config, m *skill.Manifest) (o *policy.Override, skipped []string) {
+	if cfg == nil {
+		return nil, nil
+	}
+	for _, so := range cfg.Policy.Overrides {
+		if so.Skill != m.Name {
+			continue
+		}
+		if so.Signer != "" {
+			if ok, _ := m.VerifySignature([]string{so.Signer}); !ok {
+				skipped = append(skipped, so.Signer)
+				continue
+			}
+		}
+		if o == nil {
+			o = &policy.Override{}
+		}
+		o.Merge(policy.Override{Allow: so.Allow, RequireApproval: so.RequireApproval, Deny: so.Deny})
+	}
+	return o, skipped
+}
+
+// skillSigned reports whether m's signature verifies against one of
+// registry.trust_keys.
+func skillSigned(cfg *config.Config, m *skill.Manifest) bool {
+	if cfg == nil {
+		return false
+	}
+	ok, _ := m.VerifySignature(cfg.Registry.TrustKeys)
+	return ok
+}
+
+// logOverride records the scopes a skill override decided, and each
+// override skipped because the manifest was not signed by its signer.
+func logOverride(logger *audit.Logger, skillName string, o *policy.Override, skipped []string, scopes []scope.Scope) {
+	for _, signer := range skipped {
+		_ = logger.LogAs("policy.override", nil, "skipped", audit.SkillActor(skillName), map[string]any{
+			"signer": signer,
+			"reason": "manifest not signed by this key",
+		})
+	}
+	decisions := map[string]any{}
+	var covered []scope.Scope
+	for _, s := range scopes {
+		if d, ok := o.Decide(s.Name); ok {
+			decisions[s.String()] = d.String()
+			covered = append(covered, s)
+		}
+	}
+	if len(covered) > 0 {
+		_ = logger.LogAs("policy.override", covered, "applied", audit.SkillActor(skillName), map[string]any{"decisions": decisions})
+	}
+}
+
+// secretAccessAuditor returns a secrets.AccessFunc that records each read
+// as a secret.access entry naming the key, never the value, and the skill
+// and command that asked for it.
+func secretAccessAuditor(logger *audit.Logger, skillName, cmdName, runID string) secrets.AccessFunc {
+	return func(key string, err error) {
+		if logger == nil {
+			return
+		}
+		decision := "allow"
+		details := map[string]any{"secret": key, "command": cmdName, "run_id": runID}
+		if err != nil {
+			decision = "error"
+			details["error"] = err.Error()
+		}
+		_ = logger.LogAs("secret.access", []scope.Scope{{Name: scope.SecretsAccess.Name, Resource: key, RiskLevel: scope.SecretsAccess.RiskLevel}}, decision, audit.SkillActor(skillName), details)
+	}
+}
+
+// PolicyRules compiles the configured policy.rules. An invalid rule is an
+// error rather than being skipped, so a typo cannot lift a restriction.
+func PolicyRules(cfg *config.Config) ([]policy.Rule, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	rules := make([]policy.Rule, 0, len(cfg.Policy.Rules))
+	for i, r := range cfg.Policy.Rules {
+		c := policy.Constraints{Hours: r.Constraints.Hours, Days: r.Constraints.Days, TZ: r.Constraints.TZ}
+		rule, err := policy.NewRule(r.Scope, c, r.Outside)
+		if err != nil {
+			return nil, fmt.Errorf("invalid policy.rules[%d]: %w", i, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
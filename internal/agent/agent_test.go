@@ -0,0 +1,294 @@
+package agent
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mackeh/AegisClaw/internal/config"
+	"github.com/mackeh/AegisClaw/internal/sandbox"
+	"github.com/mackeh/AegisClaw/internal/scope"
+	"github.com/mackeh/AegisClaw/internal/secrets"
+	"github.com/mackeh/AegisClaw/internal/skill"
+)
+
+func TestExecuteSkill_AuditsSecretAccess(t *testing.T) {
+	runOnceHome(t, allowAllPolicy)
+	// Fail the run at the sandbox, after secrets have been injected.
+	t.Setenv("DOCKER_HOST", "unix:///nonexistent/docker.sock")
+	cfgDir := filepath.Join(os.Getenv("HOME"), ".aegisclaw")
+
+	mgr := secrets.NewManager(filepath.Join(cfgDir, "secrets"))
+	if err := os.MkdirAll(filepath.Join(cfgDir, "secrets"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mgr.Init(); err != nil {
+		t.Fatal(err)
+	}
+	const value = "tok-4f1c9a7e"
+	if err := mgr.Set("API_TOKEN", value); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &skill.Manifest{
+		Name:     "tokenuser",
+		Image:    "alpine:latest",
+		Scopes:   []string{"secrets.access:API_TOKEN"},
+		Commands: map[string]skill.Command{"call": {Args: []string{"true"}}},
+	}
+	_, _ = ExecuteSkill(context.Background(), m, "call", nil)
+
+	data, err := os.ReadFile(filepath.Join(cfgDir, "audit", "audit.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entry string
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.Contains(line, `"secret.access"`) {
+			entry = line
+		}
+	}
+	if entry == "" {
+		t.Fatalf("no secret.access entry in audit log:\n%s", data)
+	}
+	for _, want := range []string{"API_TOKEN", "tokenuser", `"call"`} {
+		if !strings.Contains(entry, want) {
+			t.Errorf("secret.access entry missing %s: %s", want, entry)
+		}
+	}
+	if strings.Contains(string(data), value) {
+		t.Error("audit log contains the secret value")
+	}
+}
+
+func TestExecuteSkill_SignedSkillOverride(t *testing.T) {
+	runOnceHome(t, "") // default policy: require approval
+	t.Setenv("DOCKER_HOST", "unix:///nonexistent/docker.sock")
+	origInteractive := interactive
+	interactive = func() bool { return false }
+	t.Cleanup(func() { interactive = origInteractive })
+	cfgDir := filepath.Join(os.Getenv("HOME"), ".aegisclaw")
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherPriv, _ := ed25519.GenerateKey(nil)
+	cfg := "policy:\n  overrides:\n    - skill: backup\n      signer: " + hex.EncodeToString(pub) + "\n      allow: [files]\n"
+	if err := os.WriteFile(filepath.Join(cfgDir, "config.yaml"), []byte(cfg), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	manifest := func(name string, key ed25519.PrivateKey) *skill.Manifest {
+		m := &skill.Manifest{
+			Name:     name,
+			Image:    "alpine:latest",
+			Scopes:   []string{"files.read:/srv"},
+			Commands: map[string]skill.Command{"run": {Args: []string{"true"}}},
+		}
+		if key != nil {
+			data, _ := json.Marshal(m)
+			m.Signature = hex.EncodeToString(ed25519.Sign(key, data))
+		}
+		return m
+	}
+
+	// The signed skill is allowed by its override and reaches the sandbox.
+	if _, err := ExecuteSkill(context.Background(), manifest("backup", priv), "run", nil); !errors.Is(err, ErrExecutionFailed) {
+		t.Errorf("signed skill: err = %v, want to pass policy and fail in the sandbox", err)
+	}
+	// Anything else falls back to the global policy's approval.
+	for name, m := range map[string]*skill.Manifest{
+		"unsigned":     manifest("backup", nil),
+		"other signer": manifest("backup", otherPriv),
+		"other skill":  manifest("restore", priv),
+	} {
+		if _, err := ExecuteSkill(context.Background(), m, "run", nil); !errors.Is(err, ErrApprovalUnavailable) {
+			t.Errorf("%s: err = %v, want ErrApprovalUnavailable", name, err)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(cfgDir, "audit", "audit.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"action":"policy.override","scopes":["files.read:/srv"],"decision":"applied"`) {
+		t.Errorf("expected an applied policy.override entry:\n%s", data)
+	}
+}
+
+func TestExecuteSkill_PolicySeesTrustedSignature(t *testing.T) {
+	runOnceHome(t, `package aegisclaw.policy
+
+import rego.v1
+
+default decision = "require_approval"
+
+decision = "allow" if input.skill_signed
+`)
+	t.Setenv("DOCKER_HOST", "unix:///nonexistent/docker.sock")
+	origInteractive := interactive
+	interactive = func() bool { return false }
+	t.Cleanup(func() { interactive = origInteractive })
+	cfgDir := filepath.Join(os.Getenv("HOME"), ".aegisclaw")
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherPriv, _ := ed25519.GenerateKey(nil)
+	cfg := "registry:\n  trust_keys: [" + hex.EncodeToString(pub) + "]\n"
+	if err := os.WriteFile(filepath.Join(cfgDir, "config.yaml"), []byte(cfg), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	manifest := func(key ed25519.PrivateKey) *skill.Manifest {
+		m := &skill.Manifest{
+			Name:     "reader",
+			Image:    "alpine:latest",
+			Scopes:   []string{"files.read:/srv"},
+			Commands: map[string]skill.Command{"run": {Args: []string{"true"}}},
+		}
+		if key != nil {
+			data, _ := json.Marshal(m)
+			m.Signature = hex.EncodeToString(ed25519.Sign(key, data))
+		}
+		return m
+	}
+
+	if _, err := ExecuteSkill(context.Background(), manifest(priv), "run", nil); !errors.Is(err, ErrExecutionFailed) {
+		t.Errorf("trusted signature: err = %v, want to pass policy and fail in the sandbox", err)
+	}
+	for name, m := range map[string]*skill.Manifest{
+		"unsigned":         manifest(nil),
+		"untrusted signer": manifest(otherPriv),
+	} {
+		if _, err := ExecuteSkill(context.Background(), m, "run", nil); !errors.Is(err, ErrApprovalUnavailable) {
+			t.Errorf("%s: err = %v, want ErrApprovalUnavailable", name, err)
+		}
+	}
+}
+
+func TestExecuteSkill_GuardrailsCheckResolvedCommand(t *testing.T) {
+	runOnceHome(t, allowAllPolicy)
+	t.Setenv("DOCKER_HOST", "unix:///nonexistent/docker.sock")
+	origInteractive := interactive
+	interactive = func() bool { return false }
+	t.Cleanup(func() { interactive = origInteractive })
+	cfgPath := filepath.Join(os.Getenv("HOME"), ".aegisclaw", "config.yaml")
+
+	m := &skill.Manifest{
+		Name:   "cleaner",
+		Image:  "alpine:latest",
+		Scopes: []string{"files.write:/workspace"},
+		Commands: map[string]skill.Command{"clean": {
+			Args:   []string{"sh", "-c", "rm -rf {{.Args.dir}}"},
+			Params: []skill.Param{{Name: "dir"}},
+		}},
+	}
+
+	// A harmless argument passes policy and reaches the sandbox.
+	if _, err := ExecuteSkill(context.Background(), m, "clean", []string{"build"}); !errors.Is(err, ErrExecutionFailed) {
+		t.Errorf("benign args: err = %v, want ErrExecutionFailed", err)
+	}
+
+	// In the default warn mode a harmful resolved command needs approval,
+	// even though policy allows everything.
+	if _, err := ExecuteSkill(context.Background(), m, "clean", []string{"/"}); !errors.Is(err, ErrApprovalUnavailable) {
+		t.Errorf("warn mode: err = %v, want ErrApprovalUnavailable", err)
+	}
+
+	// In block mode it is denied outright.
+	if err := os.WriteFile(cfgPath, []byte("guardrails:\n  mode: block\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ExecuteSkill(context.Background(), m, "clean", []string{"/"}); !errors.Is(err, ErrPolicyDenied) {
+		t.Errorf("block mode: err = %v, want ErrPolicyDenied", err)
+	}
+}
+
+func TestSandboxUser(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Security.SandboxUser = "2000:2000"
+
+	u, root, err := sandboxUser(cfg, &skill.Manifest{User: "1001:3000"})
+	if err != nil || u.String() != "1001:3000" || root != nil {
+		t.Errorf("manifest user: %v %v %v, want 1001:3000", u, root, err)
+	}
+	u, _, err = sandboxUser(cfg, &skill.Manifest{})
+	if err != nil || u.String() != "2000:2000" {
+		t.Errorf("config default: %v %v, want 2000:2000", u, err)
+	}
+	u, _, err = sandboxUser(nil, &skill.Manifest{})
+	if err != nil || u.String() != sandbox.DefaultUser {
+		t.Errorf("no config: %v %v, want %s", u, err, sandbox.DefaultUser)
+	}
+	if _, _, err := sandboxUser(cfg, &skill.Manifest{User: "app"}); err == nil {
+		t.Error("expected a non-numeric user to be rejected")
+	}
+
+	if _, _, err := sandboxUser(cfg, &skill.Manifest{User: "0:0"}); err == nil || !strings.Contains(err.Error(), "allow_root_user") {
+		t.Errorf("root without override: err = %v, want a pointer to allow_root_user", err)
+	}
+	cfg.Security.AllowRootUser = true
+	u, root, err = sandboxUser(cfg, &skill.Manifest{User: "0:0"})
+	if err != nil || !u.IsRoot() || root == nil || root.Name != sandbox.RootUserScope || root.RiskLevel != scope.RiskCritical {
+		t.Errorf("root with override: %v %+v %v, want a critical root scope", u, root, err)
+	}
+}
+
+func TestExecuteSkill_RootUserNeedsOverrideAndApproval(t *testing.T) {
+	runOnceHome(t, allowAllPolicy)
+	t.Setenv("DOCKER_HOST", "unix:///nonexistent/docker.sock")
+	origInteractive := interactive
+	interactive = func() bool { return false }
+	t.Cleanup(func() { interactive = origInteractive })
+	cfgPath := filepath.Join(os.Getenv("HOME"), ".aegisclaw", "config.yaml")
+
+	m := &skill.Manifest{
+		Name:     "rooty",
+		Image:    "alpine:latest",
+		User:     "0:0",
+		Commands: map[string]skill.Command{"run": {Args: []string{"true"}}},
+	}
+	if _, err := ExecuteSkill(context.Background(), m, "run", nil); err == nil || !strings.Contains(err.Error(), "root") {
+		t.Errorf("without override: err = %v, want root refused", err)
+	}
+
+	// With the override, root still needs approval even though policy
+	// allows everything.
+	if err := os.WriteFile(cfgPath, []byte("security:\n  allow_root_user: true\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ExecuteSkill(context.Background(), m, "run", nil); !errors.Is(err, ErrApprovalUnavailable) {
+		t.Errorf("with override: err = %v, want ErrApprovalUnavailable", err)
+	}
+
+	// A declared non-root user passes policy and reaches the sandbox.
+	m.User = "1001:2000"
+	if _, err := ExecuteSkill(context.Background(), m, "run", nil); !errors.Is(err, ErrExecutionFailed) {
+		t.Errorf("non-root user: err = %v, want ErrExecutionFailed", err)
+	}
+}
+
+func TestExecuteSkill_CapabilitiesNeedRootUser(t *testing.T) {
+	runOnceHome(t, allowAllPolicy)
+	t.Setenv("DOCKER_HOST", "unix:///nonexistent/docker.sock")
+
+	m := &skill.Manifest{
+		Name:         "binder",
+		Image:        "alpine:latest",
+		Capabilities: []string{"NET_BIND_SERVICE"},
+		Commands:     map[string]skill.Command{"run": {Args: []string{"true"}}},
+	}
+	_, err := ExecuteSkill(context.Background(), m, "run", nil)
+	if err == nil || !strings.Contains(err.Error(), "would not be held by user 1000:1000") {
+		t.Errorf("err = %v, want the capability refused for the non-root default user", err)
+	}
+}
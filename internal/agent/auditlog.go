@@ -0,0 +1,38 @@
+package agent
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/mackeh/AegisClaw/internal/audit"
+	"github.com/mackeh/AegisClaw/internal/config"
+)
+
+// openAuditLogger opens the audit log under cfgDir with the configured
+// sinks, falling back to the local log alone if a sink cannot be set up.
+func openAuditLogger(cfg *config.Config, cfgDir string) (*audit.Logger, error) {
+	auditPath := filepath.Join(cfgDir, "audit", "audit.log")
+	var sinks []config.AuditSinkConfig
+	if cfg != nil {
+		sinks = cfg.Audit.Sinks
+	}
+	logger, err := audit.NewLoggerWithSinks(auditPath, sinks)
+	if err != nil && len(sinks) > 0 {
+		fmt.Printf("⚠️  Audit sinks unavailable, logging locally only: %v\n", err)
+		logger, err = audit.NewLogger(auditPath)
+	}
+	return logger, err
+}
+
+// auditFailure decides what a run does when its audit entry cannot be
+// written. With security.audit_required the run is refused, since an
+// unrecorded run is what an attacker would want; otherwise the run goes
+// ahead after a warning that is hard to miss.
+func auditFailure(cfg *config.Config, err error) error {
+	if cfg != nil && cfg.Security.AuditRequired {
+		return fmt.Errorf("%w: %w (security.audit_required is on)", ErrAuditUnavailable, err)
+	}
+	fmt.Printf("🚨 AUDIT LOG UNAVAILABLE — this run will not be recorded: %v\n", err)
+	fmt.Println("   Set security.audit_required: true to refuse runs that cannot be audited.")
+	return nil
+}
@@ -0,0 +1,57 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// unwritableAuditHome installs the echoer skill and puts a plain file where
+// the audit directory should be, so the audit log cannot be opened.
+func unwritableAuditHome(t *testing.T, config string) string {
+	t.Helper()
+	skillsDir := runOnceHome(t, allowAllPolicy)
+	t.Setenv("DOCKER_HOST", "unix:///nonexistent/docker.sock")
+	cfgDir := filepath.Dir(skillsDir)
+	if err := os.WriteFile(filepath.Join(cfgDir, "audit"), []byte("not a directory"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if config != "" {
+		if err := os.WriteFile(filepath.Join(cfgDir, "config.yaml"), []byte(config), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return skillsDir
+}
+
+func TestExecuteSkill_AuditRequiredFailsClosed(t *testing.T) {
+	skillsDir := unwritableAuditHome(t, "security:\n  audit_required: true\n")
+	m, err := FindSkill("echoer", skillsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = ExecuteSkill(context.Background(), m, "hello", nil)
+	if !errors.Is(err, ErrAuditUnavailable) {
+		t.Fatalf("err = %v, want ErrAuditUnavailable", err)
+	}
+	if errors.Is(err, ErrExecutionFailed) {
+		t.Error("the run should be refused before the sandbox is started")
+	}
+}
+
+func TestExecuteSkill_AuditFailureWarnsAndProceeds(t *testing.T) {
+	skillsDir := unwritableAuditHome(t, "")
+	m, err := FindSkill("echoer", skillsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Without audit_required the run goes on to the (unreachable) sandbox.
+	_, err = ExecuteSkill(context.Background(), m, "hello", nil)
+	if errors.Is(err, ErrAuditUnavailable) || !errors.Is(err, ErrExecutionFailed) {
+		t.Fatalf("err = %v, want the run to proceed to execution", err)
+	}
+}
@@ -0,0 +1,155 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/mackeh/AegisClaw/internal/audit"
+	"github.com/mackeh/AegisClaw/internal/sandbox"
+	"github.com/mackeh/AegisClaw/internal/scope"
+	"github.com/mackeh/AegisClaw/internal/skill"
+	"github.com/mackeh/AegisClaw/internal/telemetry"
+)
+
+// detachedProcess is the handle of a container left running by a detached
+// run; *sandbox.Process satisfies it.
+type detachedProcess interface {
+	ContainerID() string
+	Done() <-chan struct{}
+	Stop() error
+}
+
+// startDetached and probeHealth are variables so tests can stub out Docker.
+var (
+	startDetached = func(cfg sandbox.Config) (detachedProcess, error) {
+		exec, err := sandbox.NewDockerExecutor()
+		if err != nil {
+			return nil, err
+		}
+		// Not tied to the caller's context: the container must outlive it.
+		return exec.Start(context.Background(), cfg, nil, nil)
+	}
+	probeHealth = func(ctx context.Context, containerID string, h *skill.Health) error {
+		exec, err := sandbox.NewDockerExecutor()
+		if err != nil {
+			return err
+		}
+		if h.HTTP == nil {
+			return exec.ExecProbe(ctx, containerID, h.Command)
+		}
+		ip, err := exec.ContainerIP(ctx, containerID)
+		if err != nil {
+			return err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.HTTP.URL(ip), nil)
+		if err != nil {
+			return err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return fmt.Errorf("health endpoint returned %s", resp.Status)
+		}
+		return nil
+	}
+)
+
+// checkDetachable rejects skills that cannot run detached. Everything the
+// agent would otherwise keep alive for the run — the egress proxy, the
+// secrets write callback, artifact collection — ends when the call returns,
+// so skills relying on them must run in the foreground.
+// filtered lists the allowed and excluded egress destinations, either of
+// which needs the proxy.
+func checkDetachable(m *skill.Manifest, needsNetwork bool, filtered []string) error {
+	switch {
+	case m.IsOpenClaw():
+		return fmt.Errorf("OpenClaw skills make a single request and cannot run detached")
+	case m.Health == nil:
+		return fmt.Errorf("detached runs need a health probe in the manifest")
+	case m.Health.HTTP != nil && !needsNetwork:
+		return fmt.Errorf("an http health probe needs a network scope; use a command probe")
+	case len(filtered) > 0:
+		return fmt.Errorf("detached runs cannot keep the egress proxy for %v alive", filtered)
+	case len(m.Outputs) > 0:
+		return fmt.Errorf("outputs are not collected from detached runs")
+	case m.Hooks != nil:
+		return fmt.Errorf("hooks are not run around detached runs")
+	}
+	for _, s := range m.Scopes {
+		if p, _ := scope.Parse(s); p.Name == scope.SecretsWrite.Name && !p.Exclude {
+			return fmt.Errorf("secrets.write is not available to detached runs")
+		}
+	}
+	return m.Health.Validate()
+}
+
+// runDetached starts the container, waits for the skill's health probe and
+// returns with the container still running. The run stays in the registry
+// until the container exits, and can be stopped with KillRun; a skill that
+// never becomes healthy is stopped.
+func runDetached(ctx context.Context, m *skill.Manifest, cfg sandbox.Config, logger *audit.Logger, rec *RunRecord) (*ExecutionResult, error) {
+	interval, timeout, err := m.Health.Timing()
+	if err != nil {
+		return nil, err
+	}
+	proc, err := startDetached(cfg)
+	if err != nil {
+		telemetry.SkillExecutionsTotal.WithLabelValues(telemetry.SkillLabel(m.Name), "error").Inc()
+		return nil, fmt.Errorf("%w: %w", ErrExecutionFailed, err)
+	}
+	id := proc.ContainerID()
+	unregister := registerRun(rec, func() { _ = proc.Stop() })
+	updateRun(rec.ID, func(r *activeRun) { r.ContainerID = id })
+
+	fmt.Printf("⏳ Waiting for '%s' to become healthy...\n", m.Name)
+	probe := func(ctx context.Context) error { return probeHealth(ctx, id, m.Health) }
+	if err := waitHealthy(ctx, probe, proc.Done(), interval, timeout); err != nil {
+		_ = proc.Stop()
+		unregister()
+		telemetry.SkillExecutionsTotal.WithLabelValues(telemetry.SkillLabel(m.Name), "error").Inc()
+		return nil, fmt.Errorf("%w: skill '%s' did not become healthy: %w", ErrExecutionFailed, m.Name, err)
+	}
+	go func() {
+		<-proc.Done()
+		unregister()
+	}()
+
+	telemetry.SkillExecutionsTotal.WithLabelValues(telemetry.SkillLabel(m.Name), "detached").Inc()
+	if logger != nil {
+		_ = logger.LogAs("skill.detached", nil, "healthy", audit.SkillActor(m.Name), map[string]any{"run_id": rec.ID, "container_id": id})
+	}
+	fmt.Printf("✅ '%s' is healthy and running detached (run %s, container %.12s)\n", m.Name, rec.ID, id)
+	return &ExecutionResult{ContainerID: id}, nil
+}
+
+// waitHealthy calls probe every interval until it succeeds. It fails when
+// timeout passes, ctx is done, or exited is closed because the container
+// stopped first. Each probe gets at most one interval to answer.
+func waitHealthy(ctx context.Context, probe func(context.Context) error, exited <-chan struct{}, interval, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var lastErr error
+	for {
+		attemptCtx, attemptCancel := context.WithTimeout(ctx, max(interval, time.Second))
+		lastErr = probe(attemptCtx)
+		attemptCancel()
+		if lastErr == nil {
+			return nil
+		}
+		select {
+		case <-exited:
+			return fmt.Errorf("container exited before it was healthy (last probe: %w)", lastErr)
+		case <-ctx.Done():
+			return fmt.Errorf("no healthy probe within %s: %w", timeout, lastErr)
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mackeh/AegisClaw/internal/sandbox"
+	"github.com/mackeh/AegisClaw/internal/skill"
+)
+
+type fakeProcess struct {
+	done    chan struct{}
+	once    sync.Once
+	stopped bool
+}
+
+func newFakeProcess() *fakeProcess { return &fakeProcess{done: make(chan struct{})} }
+
+func (p *fakeProcess) ContainerID() string   { return "c0ffee0123456789" }
+func (p *fakeProcess) Done() <-chan struct{} { return p.done }
+func (p *fakeProcess) exit()                 { p.once.Do(func() { close(p.done) }) }
+func (p *fakeProcess) Stop() error {
+	p.stopped = true
+	p.exit()
+	return nil
+}
+
+// stubDetached replaces Docker with proc and a probe that fails until
+// healthyAfter attempts have been made, returning the attempt counter.
+func stubDetached(t *testing.T, proc *fakeProcess, healthyAfter int) *int {
+	t.Helper()
+	origStart, origProbe := startDetached, probeHealth
+	t.Cleanup(func() { startDetached, probeHealth = origStart, origProbe })
+
+	attempts := 0
+	startDetached = func(cfg sandbox.Config) (detachedProcess, error) {
+		if cfg.Labels[sandbox.RunIDLabel] == "" {
+			t.Error("detached container is not labelled with its run ID")
+		}
+		return proc, nil
+	}
+	probeHealth = func(ctx context.Context, id string, h *skill.Health) error {
+		attempts++
+		if healthyAfter > 0 && attempts >= healthyAfter {
+			return nil
+		}
+		return errors.New("connection refused")
+	}
+	return &attempts
+}
+
+func serverSkill(timeout string) *skill.Manifest {
+	return &skill.Manifest{
+		Name:     "server",
+		Image:    "alpine:latest",
+		Scopes:   []string{"files.read:/srv"},
+		Commands: map[string]skill.Command{"serve": {Args: []string{"httpd", "-f"}}},
+		Health:   &skill.Health{Command: []string{"true"}, Interval: "5ms", Timeout: timeout},
+	}
+}
+
+func findActive(id string) (ActiveRun, bool) {
+	for _, r := range ActiveRuns() {
+		if r.ID == id {
+			return r, true
+		}
+	}
+	return ActiveRun{}, false
+}
+
+func TestExecuteSkillDetached_WaitsForHealth(t *testing.T) {
+	runOnceHome(t, allowAllPolicy)
+	proc := newFakeProcess()
+	attempts := stubDetached(t, proc, 3)
+
+	res, err := ExecuteSkillDetached(context.Background(), serverSkill("5s"), "serve", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *attempts != 3 {
+		t.Errorf("returned after %d probes, want 3", *attempts)
+	}
+	if res.ContainerID != proc.ContainerID() || !res.Record.Detached || proc.stopped {
+		t.Errorf("result = %+v, stopped = %v", res, proc.stopped)
+	}
+
+	// The run stays registered while the container runs...
+	if r, ok := findActive(res.Record.ID); !ok || r.ContainerID != proc.ContainerID() {
+		t.Fatalf("detached run not in the registry: %+v", ActiveRuns())
+	}
+	// ...and leaves it once the container exits.
+	proc.exit()
+	deadline := time.Now().Add(time.Second)
+	for _, ok := findActive(res.Record.ID); ok; _, ok = findActive(res.Record.ID) {
+		if time.Now().After(deadline) {
+			t.Fatal("run still registered after its container exited")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestExecuteSkillDetached_NeverHealthy(t *testing.T) {
+	runOnceHome(t, allowAllPolicy)
+	proc := newFakeProcess()
+	stubDetached(t, proc, 0)
+
+	res, err := ExecuteSkillDetached(context.Background(), serverSkill("30ms"), "serve", nil)
+	if !errors.Is(err, ErrExecutionFailed) {
+		t.Fatalf("err = %v, want ErrExecutionFailed", err)
+	}
+	if res != nil || !proc.stopped {
+		t.Errorf("an unhealthy skill must be stopped (res = %+v, stopped = %v)", res, proc.stopped)
+	}
+	for _, r := range ActiveRuns() {
+		if r.Skill == "server" {
+			t.Errorf("unhealthy run left in the registry: %+v", r)
+		}
+	}
+}
+
+func TestExecuteSkillDetached_ExitsBeforeHealthy(t *testing.T) {
+	runOnceHome(t, allowAllPolicy)
+	proc := newFakeProcess()
+	proc.exit()
+	stubDetached(t, proc, 0)
+
+	start := time.Now()
+	if _, err := ExecuteSkillDetached(context.Background(), serverSkill("5s"), "serve", nil); !errors.Is(err, ErrExecutionFailed) {
+		t.Fatalf("err = %v, want ErrExecutionFailed", err)
+	}
+	if time.Since(start) > 2*time.Second {
+		t.Error("waited for the timeout although the container had exited")
+	}
+}
+
+func TestExecuteSkillDetached_RequiresHealth(t *testing.T) {
+	runOnceHome(t, allowAllPolicy)
+	stubDetached(t, newFakeProcess(), 1)
+
+	m := serverSkill("")
+	m.Health = nil
+	if _, err := ExecuteSkillDetached(context.Background(), m, "serve", nil); err == nil {
+		t.Error("a skill without a health probe should not run detached")
+	}
+	m = serverSkill("")
+	m.Scopes = append(m.Scopes, "http.request:api.example.com")
+	if _, err := ExecuteSkillDetached(context.Background(), m, "serve", nil); err == nil {
+		t.Error("a detached skill cannot keep its egress proxy")
+	}
+}
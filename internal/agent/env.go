@@ -0,0 +1,56 @@
+package agent
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mackeh/AegisClaw/internal/skill"
+)
+
+// envNamePattern is the strict POSIX-style shape an injected environment
+// variable name must have. Anything else (an '=', whitespace, a newline) could
+// smuggle extra variables into the container environment.
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// secretEnvVar builds the KEY=VALUE entry used to inject a secret into a skill
+// container. It rejects key names that are not plain identifiers and values
+// containing NUL or newline characters.
+func secretEnvVar(key, value string) (string, error) {
+	if !envNamePattern.MatchString(key) {
+		return "", fmt.Errorf("invalid secret name %q: must match [A-Za-z_][A-Za-z0-9_]*", key)
+	}
+	if strings.ContainsAny(value, "\x00\n\r") {
+		return "", fmt.Errorf("invalid value for secret %q: contains NUL or newline characters", key)
+	}
+	return key + "=" + value, nil
+}
+
+// injectSecrets looks up each requested secret and delivers it under the
+// name the skill expects: as a KEY=VALUE env entry or, where the manifest
+// asks, as a file for the sandbox to mount. values lists every secret
+// delivered, for output redaction. A missing secret is warned about and
+// skipped.
+func injectSecrets(get func(key string) (string, error), m *skill.Manifest, refs []skill.SecretRef) (env []string, files map[string][]byte, values []string, err error) {
+	for _, ref := range refs {
+		val, err := get(ref.Key)
+		if err != nil {
+			fmt.Printf("⚠️  Warning: Secret '%s' requested but not found.\n", ref.Key)
+			continue
+		}
+		if m.SecretMode(ref.Key) == skill.SecretFile {
+			if files == nil {
+				files = map[string][]byte{}
+			}
+			files[ref.EnvName] = []byte(val)
+		} else {
+			kv, err := secretEnvVar(ref.EnvName, val)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("refusing to inject secret: %w", err)
+			}
+			env = append(env, kv)
+		}
+		values = append(values, val)
+	}
+	return env, files, values, nil
+}
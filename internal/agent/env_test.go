@@ -0,0 +1,75 @@
+package agent
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mackeh/AegisClaw/internal/skill"
+)
+
+func TestSecretEnvVar(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"valid", "OPENAI_API_KEY", "sk-abc", "OPENAI_API_KEY=sk-abc", false},
+		{"leading underscore", "_TOKEN", "x", "_TOKEN=x", false},
+		{"value may contain equals", "TOKEN", "a=b", "TOKEN=a=b", false},
+		{"key with equals", "FOO=bar", "x", "", true},
+		{"key with newline", "FOO\nPATH", "x", "", true},
+		{"key with leading digit", "1FOO", "x", "", true},
+		{"empty key", "", "x", "", true},
+		{"value with newline", "TOKEN", "abc\nPATH=/evil", "", true},
+		{"value with NUL", "TOKEN", "abc\x00def", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := secretEnvVar(tt.key, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("secretEnvVar(%q) error = %v, wantErr %v", tt.key, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("secretEnvVar(%q) = %q, want %q", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInjectSecrets_Remapped(t *testing.T) {
+	store := map[string]string{"PROD_OPENAI": "sk-prod", "STAGING_DB": "pg-pass"}
+	get := func(key string) (string, error) {
+		if v, ok := store[key]; ok {
+			return v, nil
+		}
+		return "", errors.New("not found")
+	}
+	m := &skill.Manifest{
+		Scopes: []string{
+			"secrets.access:PROD_OPENAI->OPENAI_API_KEY",
+			"secrets.access:STAGING_DB->DB_PASSWORD",
+			"secrets.access:MISSING",
+		},
+		SecretDelivery: map[string]string{"STAGING_DB": skill.SecretFile},
+	}
+	refs, err := m.SecretRefs()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	env, files, values, err := injectSecrets(get, m, refs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(env) != 1 || env[0] != "OPENAI_API_KEY=sk-prod" {
+		t.Errorf("env = %q, want the secret under its mapped name", env)
+	}
+	if len(files) != 1 || string(files["DB_PASSWORD"]) != "pg-pass" {
+		t.Errorf("files = %q, want DB_PASSWORD", files)
+	}
+	if len(values) != 2 {
+		t.Errorf("values for redaction = %d, want 2", len(values))
+	}
+}
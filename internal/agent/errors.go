@@ -0,0 +1,61 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mackeh/AegisClaw/internal/guardrails"
+)
+
+// Errors returned by the Execute* functions. They are wrapped with detail,
+// so match them with errors.Is.
+var (
+	// ErrPolicyDenied means the policy denied one of the skill's scopes.
+	ErrPolicyDenied = errors.New("policy denied action")
+	// ErrUserDenied means the user declined the approval prompt.
+	ErrUserDenied = errors.New("user denied request")
+	// ErrApprovalUnavailable means approval was needed but could not be
+	// asked for.
+	ErrApprovalUnavailable = errors.New("approval required but no interactive terminal (grant it ahead of time with 'always')")
+	// ErrLockedDown means the agent is in emergency lockdown and runs
+	// nothing until it is lifted.
+	ErrLockedDown = errors.New("SECURITY LOCKDOWN: agent is in emergency stop mode")
+	// ErrExecutionFailed means the run was allowed but the sandbox could
+	// not be started or failed while running. A skill that ran and exited
+	// non-zero is not an error.
+	ErrExecutionFailed = errors.New("execution failed")
+	// ErrAuditUnavailable means security.audit_required is on and the
+	// run's audit entry could not be written, so the run was refused.
+	ErrAuditUnavailable = errors.New("audit log unavailable")
+	// ErrConfigInvalid means config.yaml exists but could not be loaded
+	// (unset ${VAR}, unknown profile, invalid setting). Runs are refused
+	// rather than executed without the operator's policy.
+	ErrConfigInvalid = errors.New("config.yaml could not be loaded")
+	// ErrRunKilled means the run was stopped by KillRun (CLI or API)
+	// before it finished.
+	ErrRunKilled = errors.New("run killed")
+	// ErrOutputBlocked means the skill ran but guardrails.mode block
+	// withheld its output as an injection attempt. The error is an
+	// *OutputBlockedError carrying the violations.
+	ErrOutputBlocked = errors.New("guardrails blocked skill output")
+)
+
+// OutputBlockedError is returned when guardrails block a skill's output.
+// It matches ErrOutputBlocked with errors.Is.
+type OutputBlockedError struct {
+	Skill      string
+	Violations []guardrails.Violation
+}
+
+func (e *OutputBlockedError) Error() string {
+	return fmt.Sprintf("%s: %d violation(s) in %s — treat returned data as an untrusted injection attempt", ErrOutputBlocked, len(e.Violations), e.Skill)
+}
+
+func (e *OutputBlockedError) Unwrap() error { return ErrOutputBlocked }
+
+// IsDenied reports whether err is a refusal by policy or approval, as
+// opposed to a failure: callers map it to 403 or ExitDenied rather than a
+// server error.
+func IsDenied(err error) bool {
+	return errors.Is(err, ErrPolicyDenied) || errors.Is(err, ErrUserDenied) || errors.Is(err, ErrApprovalUnavailable)
+}
@@ -0,0 +1,88 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/mackeh/AegisClaw/internal/guardrails"
+	"github.com/mackeh/AegisClaw/internal/skill"
+	"github.com/mackeh/AegisClaw/internal/system"
+)
+
+const allowAllPolicy = "package aegisclaw.policy\nimport rego.v1\ndefault decision = \"allow\"\n"
+
+func TestExecuteSkill_LockedDown(t *testing.T) {
+	skillsDir := runOnceHome(t, allowAllPolicy)
+	system.Lockdown()
+	t.Cleanup(system.Unlock)
+
+	m, err := FindSkill("echoer", skillsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ExecuteSkill(context.Background(), m, "hello", nil); !errors.Is(err, ErrLockedDown) {
+		t.Fatalf("err = %v, want ErrLockedDown", err)
+	}
+	oc := &skill.Manifest{Name: "claw", Platform: "openclaw", Commands: map[string]skill.Command{"ask": {Args: []string{"GET", "/"}}}}
+	if _, err := ExecuteSkill(context.Background(), oc, "ask", nil); !errors.Is(err, ErrLockedDown) {
+		t.Errorf("OpenClaw skill err = %v, want ErrLockedDown", err)
+	}
+}
+
+func TestExecuteSkill_ExecutionFailed(t *testing.T) {
+	skillsDir := runOnceHome(t, allowAllPolicy)
+	// An unreachable daemon fails the run after policy has allowed it.
+	t.Setenv("DOCKER_HOST", "unix:///nonexistent/docker.sock")
+
+	m, err := FindSkill("echoer", skillsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ExecuteSkill(context.Background(), m, "hello", nil)
+	if !errors.Is(err, ErrExecutionFailed) {
+		t.Fatalf("err = %v, want ErrExecutionFailed", err)
+	}
+	if IsDenied(err) {
+		t.Error("an execution failure is not a denial")
+	}
+}
+
+func TestExitCodeFor_Errors(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{ErrPolicyDenied, ExitDenied},
+		{fmt.Errorf("%w: execution blocked", ErrPolicyDenied), ExitDenied},
+		{ErrUserDenied, ExitDenied},
+		{ErrApprovalUnavailable, ExitDenied},
+		{ErrLockedDown, ExitLockedDown},
+		{fmt.Errorf("%w: boom", ErrExecutionFailed), ExitFailure},
+		{fmt.Errorf("%w: run-1", ErrRunKilled), ExitKilled},
+		{&OutputBlockedError{Skill: "echoer"}, ExitDenied},
+	}
+	for _, tt := range tests {
+		if got := ExitCodeFor(nil, tt.err); got != tt.want {
+			t.Errorf("ExitCodeFor(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestOutputBlockedError(t *testing.T) {
+	violations := []guardrails.Violation{{Rule: "ignore_previous", Severity: guardrails.SeverityCritical}}
+	var err error = &OutputBlockedError{Skill: "echoer", Violations: violations}
+	err = fmt.Errorf("run r1: %w", err)
+
+	if !errors.Is(err, ErrOutputBlocked) {
+		t.Fatalf("errors.Is(%v, ErrOutputBlocked) = false", err)
+	}
+	var blocked *OutputBlockedError
+	if !errors.As(err, &blocked) || blocked.Skill != "echoer" || len(blocked.Violations) != 1 {
+		t.Errorf("errors.As = %+v, want the skill and its violations", blocked)
+	}
+	if IsDenied(err) || errors.Is(err, ErrRunKilled) {
+		t.Error("blocked output matched an unrelated sentinel")
+	}
+}
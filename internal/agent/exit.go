@@ -0,0 +1,44 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/mackeh/AegisClaw/internal/audit"
+	"github.com/mackeh/AegisClaw/internal/sandbox"
+)
+
+// executionTimeout bounds a foreground skill run.
+const executionTimeout = 5 * time.Minute
+
+// runExitReason classifies how a sandbox run ended: a run stopped through
+// KillRun or a cancelled context is killed, one that hit executionTimeout
+// timed out, any other executor failure is an error, and otherwise the
+// sandbox's own reason (completed, oom_killed, killed) stands.
+func runExitReason(result *sandbox.Result, err error, killed bool) string {
+	switch {
+	case killed || errors.Is(err, context.Canceled):
+		return sandbox.ExitKilled
+	case errors.Is(err, context.DeadlineExceeded):
+		return sandbox.ExitTimeout
+	case err != nil || result == nil:
+		return sandbox.ExitError
+	case result.Reason == "":
+		return sandbox.ExitCompleted
+	}
+	return result.Reason
+}
+
+// logExit records how the run's container stopped as a "skill.exit" audit
+// entry, so an OOM kill or timeout is not just another non-zero exit.
+func logExit(logger *audit.Logger, skillName string, rec *RunRecord, result *sandbox.Result) {
+	if logger == nil {
+		return
+	}
+	details := map[string]any{"run_id": rec.ID}
+	if result != nil {
+		details["exit_code"] = result.ExitCode
+	}
+	_ = logger.LogAs("skill.exit", nil, rec.Reason, audit.SkillActor(skillName), details)
+}
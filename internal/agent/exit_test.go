@@ -0,0 +1,33 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/mackeh/AegisClaw/internal/sandbox"
+)
+
+func TestRunExitReason(t *testing.T) {
+	tests := []struct {
+		name   string
+		result *sandbox.Result
+		err    error
+		killed bool
+		want   string
+	}{
+		{"completed", &sandbox.Result{Reason: sandbox.ExitCompleted}, nil, false, sandbox.ExitCompleted},
+		{"oom", &sandbox.Result{ExitCode: 137, Reason: sandbox.ExitOOMKilled}, nil, false, sandbox.ExitOOMKilled},
+		{"no reason", &sandbox.Result{ExitCode: 2}, nil, false, sandbox.ExitCompleted},
+		{"timeout", nil, fmt.Errorf("wait: %w", context.DeadlineExceeded), false, sandbox.ExitTimeout},
+		{"kill run", nil, context.Canceled, true, sandbox.ExitKilled},
+		{"cancelled", nil, context.Canceled, false, sandbox.ExitKilled},
+		{"executor error", nil, errors.New("failed to create container"), false, sandbox.ExitError},
+	}
+	for _, tt := range tests {
+		if got := runExitReason(tt.result, tt.err, tt.killed); got != tt.want {
+			t.Errorf("%s: runExitReason = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
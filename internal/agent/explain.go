@@ -0,0 +1,63 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/mackeh/AegisClaw/internal/audit"
+	"github.com/mackeh/AegisClaw/internal/config"
+	"github.com/mackeh/AegisClaw/internal/policy"
+	"github.com/mackeh/AegisClaw/internal/scope"
+	"github.com/mackeh/AegisClaw/internal/skill"
+)
+
+type explainKey struct{}
+
+// WithExplain returns a context under which skill executions print how the
+// policy decided every requested scope, not only a denied one.
+func WithExplain(ctx context.Context) context.Context {
+	return context.WithValue(ctx, explainKey{}, true)
+}
+
+func explainRequested(ctx context.Context) bool {
+	on, _ := ctx.Value(explainKey{}).(bool)
+	return on
+}
+
+// printTraces writes policy decision traces for the terminal.
+func printTraces(w io.Writer, traces []policy.Trace) {
+	if len(traces) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "🔎 Policy decision trace:")
+	for _, t := range traces {
+		fmt.Fprintf(w, "   %s", t)
+	}
+}
+
+// auditDenial records a policy denial with the trace that explains it, so
+// the audit log says which rule refused the run.
+func auditDenial(cfg *config.Config, m *skill.Manifest, cmdName string, rec *RunRecord, scopes []scope.Scope, denial policy.Trace) {
+	cfgDir, err := config.DefaultConfigDir()
+	if err != nil {
+		return
+	}
+	logger, err := openAuditLogger(cfg, cfgDir)
+	if err != nil {
+		fmt.Printf("⚠️  Failed to record the denial: %v\n", err)
+		return
+	}
+	defer logger.Close()
+	details := map[string]any{
+		"command": cmdName,
+		"image":   m.Image,
+		"run_id":  rec.ID,
+		"explain": denial,
+	}
+	if rec.TraceID != "" {
+		details["trace_id"] = rec.TraceID
+		details["span_id"] = rec.SpanID
+	}
+	_ = logger.LogAs("skill.exec", scopes, policy.Deny.String(), audit.SkillActor(m.Name), details)
+}
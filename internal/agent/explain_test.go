@@ -0,0 +1,65 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mackeh/AegisClaw/internal/audit"
+	"github.com/mackeh/AegisClaw/internal/policy"
+)
+
+const denyReadPolicy = `package aegisclaw.policy
+
+import rego.v1
+
+default decision = "allow"
+
+decision = "deny" if input.scope.name == "files.read"
+`
+
+func TestExecuteSkill_DenialAuditsTrace(t *testing.T) {
+	skillsDir := runOnceHome(t, denyReadPolicy)
+
+	code, err := RunOnce(WithExplain(context.Background()), []string{skillsDir}, "echoer", "hello", nil)
+	if !errors.Is(err, ErrPolicyDenied) || code != ExitDenied {
+		t.Fatalf("RunOnce = %d, %v; want a policy denial", code, err)
+	}
+
+	entries, err := audit.Search(filepath.Join(os.Getenv("HOME"), ".aegisclaw", "audit", "audit.log"), audit.Query{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var denial *audit.Entry
+	for i, e := range entries {
+		if e.Action == "skill.exec" && e.Decision == "deny" {
+			denial = &entries[i]
+		}
+	}
+	if denial == nil {
+		t.Fatalf("no deny entry in the audit log: %+v", entries)
+	}
+	explain := fmt.Sprint(denial.Details["explain"])
+	if !strings.Contains(explain, "policy.rego:7:") || !strings.Contains(explain, `input.scope.name == "files.read"`) {
+		t.Errorf("deny entry should name the rule that matched, got %s", explain)
+	}
+}
+
+func TestPrintTraces(t *testing.T) {
+	var buf bytes.Buffer
+	printTraces(&buf, nil)
+	if buf.Len() != 0 {
+		t.Errorf("no traces printed %q", buf.String())
+	}
+
+	printTraces(&buf, []policy.Trace{{Scope: "shell.exec", Decision: "deny", Steps: []string{"policy.rego:4: rule matched"}}})
+	want := "🔎 Policy decision trace:\n   shell.exec → deny\n   • policy.rego:4: rule matched\n"
+	if buf.String() != want {
+		t.Errorf("printTraces wrote %q, want %q", buf.String(), want)
+	}
+}
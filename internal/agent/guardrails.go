@@ -40,24 +40,42 @@ func guardrailMode(cfg *config.Config) GuardrailMode {
 	}
 }
 
+// guardrailEngine builds the engine used to scan skill output, with the
+// configured language packs. If a pack cannot be loaded the English rules
+// still run, so a bad pack never turns scanning off.
+func guardrailEngine(cfg *config.Config) *guardrails.Engine {
+	if cfg == nil || len(cfg.Guardrails.Packs) == 0 {
+		return guardrails.NewEngine()
+	}
+	e, err := guardrails.NewEngineWithPacks(cfg.Guardrails.Packs)
+	if err != nil {
+		fmt.Printf("⚠️  Guardrail packs not loaded, using English rules only: %v\n", err)
+		return guardrails.NewEngine()
+	}
+	return e
+}
+
 // inspectSkillOutput scans a skill's captured output for indirect prompt
 // injection — instructions smuggled into returned data that would hijack the
 // agent if fed back into the model. Violations are written to the audit log.
 // It reports the guardrail result and whether the output should be treated as
-// blocked under the given mode.
-func inspectSkillOutput(mode GuardrailMode, skillName, output string, logger *audit.Logger) (*guardrails.Result, bool) {
+// blocked under the given mode. A nil guard uses the default rules.
+func inspectSkillOutput(mode GuardrailMode, guard *guardrails.Engine, skillName, output string, logger *audit.Logger) (*guardrails.Result, bool) {
 	if mode == GuardrailOff || output == "" {
 		return nil, false
 	}
+	if guard == nil {
+		guard = guardrails.NewEngine()
+	}
 
-	res := guardrails.NewEngine().CheckData("skill:"+skillName, output)
+	res := guard.CheckData("skill:"+skillName, output)
 	if len(res.Violations) == 0 {
 		return res, false
 	}
 
 	if logger != nil {
 		for _, v := range res.Violations {
-			_ = logger.Log("guardrail.violation", nil, string(v.Severity), skillName, map[string]any{
+			_ = logger.LogAs("guardrail.violation", nil, string(v.Severity), audit.SkillActor(skillName), map[string]any{
 				"rule":    v.Rule,
 				"message": v.Message,
 				"source":  res.Source,
@@ -65,10 +83,35 @@ func inspectSkillOutput(mode GuardrailMode, skillName, output string, logger *au
 		}
 	}
 
-	blocked := mode == GuardrailBlock && !res.Allowed
+	blocked := mode == GuardrailBlock && res.Blocked()
 	return res, blocked
 }
 
+// checkResolvedCommand scans the argv a run is about to execute, after user
+// arguments have been substituted. It returns nil when guardrails are off or
+// nothing was flagged.
+func checkResolvedCommand(mode GuardrailMode, guard *guardrails.Engine, argv []string) *guardrails.Result {
+	if mode == GuardrailOff {
+		return nil
+	}
+	if guard == nil {
+		guard = guardrails.NewEngine()
+	}
+	res := guard.CheckCommand(argv)
+	if len(res.Violations) == 0 {
+		return nil
+	}
+	return res
+}
+
+// reportCommandViolations prints why a resolved command was flagged.
+func reportCommandViolations(w io.Writer, skillName string, res *guardrails.Result) {
+	fmt.Fprintf(w, "⚠️  Guardrails flagged the command '%s' is about to run:\n", skillName)
+	for _, v := range res.Violations {
+		fmt.Fprintf(w, "   [%s] %s: %s\n", strings.ToUpper(string(v.Severity)), v.Rule, v.Message)
+	}
+}
+
 // reportViolations prints a human-readable summary of guardrail violations.
 func reportViolations(w io.Writer, skillName string, res *guardrails.Result) {
 	fmt.Fprintf(w, "⚠️  Guardrails flagged %d issue(s) in '%s' output — possible prompt injection in returned data:\n",
@@ -0,0 +1,172 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mackeh/AegisClaw/internal/audit"
+	"github.com/mackeh/AegisClaw/internal/guardrails"
+	"github.com/mackeh/AegisClaw/internal/sandbox"
+	"github.com/mackeh/AegisClaw/internal/security/redactor"
+	"github.com/mackeh/AegisClaw/internal/skill"
+)
+
+// hookTimeout bounds each hook. The after hook gets a fresh one, so it
+// still runs when the main command used up (or was killed within) its own.
+const hookTimeout = time.Minute
+
+// Hook phases, as reported in HookResult.Phase.
+const (
+	HookBefore = "before"
+	HookAfter  = "after"
+)
+
+// HookResult is the captured outcome of one of a skill's hooks.
+type HookResult struct {
+	Phase    string
+	ExitCode int
+	Reason   string // how the container stopped; one of the sandbox.Exit* constants
+	Stdout   string
+	Stderr   string
+	Err      string // set if the hook could not be run
+}
+
+// Failed reports whether the hook could not run or exited non-zero.
+func (h HookResult) Failed() bool {
+	return h.Err != "" || h.ExitCode != 0
+}
+
+// hookRunner runs a skill's hooks around its main command, each in a
+// sandbox configured like the main command's.
+type hookRunner struct {
+	run      func(context.Context, sandbox.Config) (*sandbox.Result, error)
+	cfg      sandbox.Config // the main command's sandbox
+	hooks    *skill.Hooks
+	scrubber *redactor.Redactor
+	logger   *audit.Logger
+	skill    string
+	runID    string
+	stdout   io.Writer // where hook output is shown, after redaction
+	stderr   io.Writer
+	results  []HookResult
+}
+
+// around runs the before hook, then main, then the after hook. If the
+// before hook fails main is skipped and its failure returned; the after
+// hook runs regardless, so it can undo a partial setup or clean up after a
+// failed main command. A failing after hook is reported but does not
+// change main's outcome.
+func (h *hookRunner) around(ctx context.Context, main func() error) error {
+	err := h.runPhase(ctx, HookBefore)
+	if err == nil {
+		err = main()
+	}
+	// Not tied to ctx: teardown must run even once the run's own timeout
+	// has passed or it was cancelled.
+	afterCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), hookTimeout)
+	defer cancel()
+	if afterErr := h.runPhase(afterCtx, HookAfter); afterErr != nil {
+		fmt.Printf("⚠️  %v\n", afterErr)
+	}
+	return err
+}
+
+// runPhase runs the hook for phase, if the skill declares one, and records
+// its result.
+func (h *hookRunner) runPhase(ctx context.Context, phase string) error {
+	cmd := h.hook(phase)
+	if cmd == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, hookTimeout)
+	defer cancel()
+
+	cfg := h.cfg
+	cfg.Command = cmd.Args
+	cfg.Env = append(append([]string{}, h.cfg.Env...), cmd.Env...)
+	cfg.Stdin = nil
+	cfg.Outputs, cfg.ArtifactsDir = nil, "" // artifacts come from the main command only
+
+	fmt.Printf("🪝 Running %s hook for %s\n", phase, h.skill)
+	res := HookResult{Phase: phase}
+	result, err := h.run(ctx, cfg)
+	res.Reason = runExitReason(result, err, false)
+	if err != nil {
+		res.Err = err.Error()
+	} else {
+		res.ExitCode = result.ExitCode
+		var stdout, stderr bytes.Buffer
+		copyOutput(result, io.MultiWriter(h.stdout, &stdout), io.MultiWriter(h.stderr, &stderr), h.scrubber)
+		res.Stdout, res.Stderr = stdout.String(), stderr.String()
+	}
+	h.results = append(h.results, res)
+	if h.logger != nil {
+		details := map[string]any{"run_id": h.runID, "phase": phase, "exit_code": res.ExitCode}
+		if res.Err != "" {
+			details["error"] = res.Err
+		}
+		_ = h.logger.LogAs("skill.hook", nil, res.Reason, audit.SkillActor(h.skill), details)
+	}
+	if res.Failed() {
+		if res.Err != "" {
+			return fmt.Errorf("%s hook failed: %s", phase, res.Err)
+		}
+		return fmt.Errorf("%s hook exited with code %d", phase, res.ExitCode)
+	}
+	return nil
+}
+
+func (h *hookRunner) hook(phase string) *skill.Command {
+	if h.hooks == nil {
+		return nil
+	}
+	if phase == HookBefore {
+		return h.hooks.Before
+	}
+	return h.hooks.After
+}
+
+// copyOutput drains a finished run's output into stdout and stderr,
+// redacting it first. The output arrives in arbitrary chunks, so it goes
+// through a StreamGuard, which holds back enough of each stream to catch a
+// secret split across two writes.
+func copyOutput(result *sandbox.Result, stdout, stderr io.Writer, scrubber *redactor.Redactor) {
+	secrets := scrubber.Secrets()
+	safeStdout := guardrails.NewStreamGuard(stdout, secrets...)
+	safeStderr := guardrails.NewStreamGuard(stderr, secrets...)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		io.Copy(safeStdout, result.Stdout)
+		safeStdout.Flush()
+	}()
+	go func() {
+		defer wg.Done()
+		io.Copy(safeStderr, result.Stderr)
+		safeStderr.Flush()
+	}()
+	wg.Wait()
+}
+
+// newHookRunner returns a runner for m's hooks that shows their output on
+// the console.
+func newHookRunner(run func(context.Context, sandbox.Config) (*sandbox.Result, error), cfg sandbox.Config, m *skill.Manifest, scrubber *redactor.Redactor, logger *audit.Logger, runID string) *hookRunner {
+	return &hookRunner{
+		run:      run,
+		cfg:      cfg,
+		hooks:    m.Hooks,
+		scrubber: scrubber,
+		logger:   logger,
+		skill:    m.Name,
+		runID:    runID,
+		stdout:   os.Stdout,
+		stderr:   os.Stderr,
+	}
+}
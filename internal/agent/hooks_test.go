@@ -0,0 +1,190 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mackeh/AegisClaw/internal/audit"
+	"github.com/mackeh/AegisClaw/internal/sandbox"
+	"github.com/mackeh/AegisClaw/internal/security/redactor"
+	"github.com/mackeh/AegisClaw/internal/skill"
+)
+
+// fakeSandbox answers runs by the first argument of the command: "fail"
+// exits 1, "broken" cannot start, anything else echoes its arguments.
+type fakeSandbox struct {
+	ran []string
+}
+
+func (f *fakeSandbox) run(ctx context.Context, cfg sandbox.Config) (*sandbox.Result, error) {
+	f.ran = append(f.ran, strings.Join(cfg.Command, " "))
+	if cfg.Command[0] == "broken" {
+		return nil, errors.New("image not found")
+	}
+	res := &sandbox.Result{
+		Stdout: strings.NewReader(strings.Join(cfg.Command[1:], " ") + "\n"),
+		Stderr: strings.NewReader("env=" + strings.Join(cfg.Env, ",")),
+	}
+	if cfg.Command[0] == "fail" {
+		res.ExitCode = 1
+	}
+	return res, nil
+}
+
+func testHookRunner(f *fakeSandbox, hooks *skill.Hooks) *hookRunner {
+	return &hookRunner{
+		run:      f.run,
+		cfg:      sandbox.Config{Image: "alpine:3.20", Env: []string{"BASE=1"}, Outputs: []string{"report.txt"}},
+		hooks:    hooks,
+		scrubber: redactor.New("s3cr3t"),
+		skill:    "seeder",
+		runID:    "run-1",
+		stdout:   io.Discard,
+		stderr:   io.Discard,
+	}
+}
+
+func TestHookRunner_AfterRunsWhenMainFails(t *testing.T) {
+	f := &fakeSandbox{}
+	h := testHookRunner(f, &skill.Hooks{
+		Before: &skill.Command{Args: []string{"echo", "seeding"}},
+		After:  &skill.Command{Args: []string{"echo", "cleaning", "s3cr3t"}, Env: []string{"PHASE=after"}},
+	})
+
+	mainErr := errors.New("main command failed")
+	err := h.around(context.Background(), func() error {
+		f.ran = append(f.ran, "main")
+		return mainErr
+	})
+	if !errors.Is(err, mainErr) {
+		t.Fatalf("around returned %v, want the main command's error", err)
+	}
+	if got := strings.Join(f.ran, " | "); got != "echo seeding | main | echo cleaning s3cr3t" {
+		t.Errorf("ran %q, want before, main, after", got)
+	}
+
+	if len(h.results) != 2 {
+		t.Fatalf("expected 2 hook results, got %+v", h.results)
+	}
+	before, after := h.results[0], h.results[1]
+	if before.Phase != HookBefore || before.Stdout != "seeding\n" || before.Failed() {
+		t.Errorf("before hook = %+v", before)
+	}
+	if after.Phase != HookAfter || after.Stdout != "cleaning [REDACTED]\n" {
+		t.Errorf("after hook output %q, want it captured and redacted", after.Stdout)
+	}
+	if after.Stderr != "env=BASE=1,PHASE=after" {
+		t.Errorf("after hook env %q, want the main env plus the hook's", after.Stderr)
+	}
+}
+
+func TestHookRunner_BeforeFailureSkipsMain(t *testing.T) {
+	f := &fakeSandbox{}
+	h := testHookRunner(f, &skill.Hooks{
+		Before: &skill.Command{Args: []string{"fail", "half-seeded"}},
+		After:  &skill.Command{Args: []string{"echo", "undo"}},
+	})
+
+	mainRan := false
+	err := h.around(context.Background(), func() error {
+		mainRan = true
+		return nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "before hook exited with code 1") {
+		t.Fatalf("around returned %v, want the before hook's failure", err)
+	}
+	if mainRan {
+		t.Error("main command ran after a failed before hook")
+	}
+	if len(h.results) != 2 || h.results[1].Phase != HookAfter || h.results[1].Stdout != "undo\n" {
+		t.Errorf("after hook did not run to undo the partial setup: %+v", h.results)
+	}
+}
+
+func TestHookRunner_AfterFailureKeepsMainResult(t *testing.T) {
+	f := &fakeSandbox{}
+	h := testHookRunner(f, &skill.Hooks{After: &skill.Command{Args: []string{"broken"}}})
+
+	if err := h.around(context.Background(), func() error { return nil }); err != nil {
+		t.Errorf("a failing after hook changed the run's outcome: %v", err)
+	}
+	if len(h.results) != 1 || h.results[0].Err != "image not found" || h.results[0].Reason != sandbox.ExitError {
+		t.Errorf("after hook result = %+v", h.results)
+	}
+}
+
+func TestHookRunner_HookSandboxMatchesMain(t *testing.T) {
+	var got sandbox.Config
+	h := testHookRunner(&fakeSandbox{}, &skill.Hooks{Before: &skill.Command{Args: []string{"echo"}}})
+	h.cfg.Network = true
+	h.cfg.AllowedDomains = []string{"api.example.com"}
+	h.run = func(ctx context.Context, cfg sandbox.Config) (*sandbox.Result, error) {
+		got = cfg
+		return &sandbox.Result{Stdout: &bytes.Buffer{}, Stderr: &bytes.Buffer{}}, nil
+	}
+	if err := h.around(context.Background(), func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if got.Image != "alpine:3.20" || !got.Network || len(got.AllowedDomains) != 1 {
+		t.Errorf("hook sandbox %+v does not carry the main command's image and scopes", got)
+	}
+	if len(got.Outputs) != 0 {
+		t.Errorf("hook sandbox collects outputs %v; only the main command should", got.Outputs)
+	}
+}
+
+func TestHookRunner_AuditsHooks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	logger, err := audit.NewLogger(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := testHookRunner(&fakeSandbox{}, &skill.Hooks{After: &skill.Command{Args: []string{"fail"}}})
+	h.logger = logger
+	_ = h.around(context.Background(), func() error { return nil })
+	logger.Close()
+
+	entries, err := audit.ReadAll(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Action != "skill.hook" || entries[0].Actor != "seeder" ||
+		entries[0].Details["phase"] != HookAfter || entries[0].Details["run_id"] != "run-1" {
+		t.Errorf("audit entries = %+v", entries)
+	}
+}
+
+func TestCheckDetachable_RefusesHooks(t *testing.T) {
+	m := &skill.Manifest{
+		Health: &skill.Health{Command: []string{"true"}},
+		Hooks:  &skill.Hooks{After: &skill.Command{Args: []string{"true"}}},
+	}
+	if err := checkDetachable(m, false, nil); err == nil {
+		t.Error("expected a skill with hooks to be refused for detached runs")
+	}
+}
+
+func TestCopyOutput_RedactsSecretSplitAcrossWrites(t *testing.T) {
+	const secret = "tok-4f1c9a7e"
+	result := &sandbox.Result{
+		Stdout: io.MultiReader(strings.NewReader("token="+secret[:6]), strings.NewReader(secret[6:]+" done\n")),
+		Stderr: io.MultiReader(strings.NewReader(secret[:3]), strings.NewReader(secret[3:])),
+	}
+	var stdout, stderr bytes.Buffer
+	copyOutput(result, &stdout, &stderr, redactor.New(secret))
+
+	if strings.Contains(stdout.String(), secret) || strings.Contains(stderr.String(), secret) {
+		t.Fatalf("secret leaked: stdout %q, stderr %q", stdout.String(), stderr.String())
+	}
+	if got := stdout.String(); got != "token=[REDACTED] done\n" {
+		t.Errorf("stdout = %q", got)
+	}
+	if got := stderr.String(); got != "[REDACTED]" {
+		t.Errorf("stderr = %q", got)
+	}
+}
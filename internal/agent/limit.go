@@ -0,0 +1,94 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/mackeh/AegisClaw/internal/config"
+	"github.com/mackeh/AegisClaw/internal/telemetry"
+)
+
+// ErrAtCapacity is returned when agent.max_concurrent_runs runs are already
+// executing and agent.on_capacity is "reject".
+var ErrAtCapacity = errors.New("at capacity: too many concurrent skill runs (agent.max_concurrent_runs)")
+
+// runLimiter bounds concurrent skill executions in this process. The limit
+// is passed per acquire so config changes apply to the next run.
+type runLimiter struct {
+	mu      sync.Mutex
+	running int
+	queued  int
+	freed   chan struct{} // closed, then replaced, whenever a slot frees
+}
+
+var limiter = &runLimiter{freed: make(chan struct{})}
+
+// acquire takes a run slot. With max <= 0 there is no limit. When all max
+// slots are taken it fails with ErrAtCapacity, or, if wait is set, blocks
+// until a slot frees or ctx is done. The returned func releases the slot.
+func (l *runLimiter) acquire(ctx context.Context, max int, wait bool) (func(), error) {
+	l.mu.Lock()
+	for max > 0 && l.running >= max {
+		if !wait {
+			l.mu.Unlock()
+			return nil, ErrAtCapacity
+		}
+		l.setQueued(l.queued + 1)
+		freed := l.freed
+		l.mu.Unlock()
+
+		select {
+		case <-freed:
+		case <-ctx.Done():
+			l.mu.Lock()
+			l.setQueued(l.queued - 1)
+			l.mu.Unlock()
+			return nil, ctx.Err()
+		}
+
+		l.mu.Lock()
+		l.setQueued(l.queued - 1)
+	}
+	l.setRunning(l.running + 1)
+	l.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			l.setRunning(l.running - 1)
+			close(l.freed)
+			l.freed = make(chan struct{})
+		})
+	}, nil
+}
+
+func (l *runLimiter) setRunning(n int) {
+	l.running = n
+	telemetry.ActiveExecutions.Set(float64(n))
+}
+
+func (l *runLimiter) setQueued(n int) {
+	l.queued = n
+	telemetry.QueuedExecutions.Set(float64(n))
+}
+
+// RunCounts reports how many skill runs are executing and how many are
+// waiting for a slot.
+func RunCounts() (running, queued int) {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+	return limiter.running, limiter.queued
+}
+
+// concurrencyLimit returns agent.max_concurrent_runs and whether runs over
+// it should queue rather than be rejected.
+func concurrencyLimit(cfg *config.Config) (int, bool) {
+	if cfg == nil {
+		return 0, true
+	}
+	return cfg.Agent.MaxConcurrentRuns, strings.ToLower(strings.TrimSpace(cfg.Agent.OnCapacity)) != "reject"
+}
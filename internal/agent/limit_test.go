@@ -0,0 +1,112 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mackeh/AegisClaw/internal/config"
+)
+
+func TestRunLimiter_RejectsOverLimit(t *testing.T) {
+	l := &runLimiter{freed: make(chan struct{})}
+	var releases []func()
+	for i := 0; i < 2; i++ {
+		release, err := l.acquire(context.Background(), 2, false)
+		if err != nil {
+			t.Fatalf("run %d: %v", i+1, err)
+		}
+		releases = append(releases, release)
+	}
+	if _, err := l.acquire(context.Background(), 2, false); !errors.Is(err, ErrAtCapacity) {
+		t.Fatalf("3rd run: err = %v, want ErrAtCapacity", err)
+	}
+
+	releases[0]()
+	releases[0]() // releasing twice must not free a second slot
+	release, err := l.acquire(context.Background(), 2, false)
+	if err != nil {
+		t.Fatalf("slot should be free after release: %v", err)
+	}
+	release()
+	if _, err := l.acquire(context.Background(), 2, false); err != nil {
+		t.Fatalf("slot should be free again: %v", err)
+	}
+	if _, err := l.acquire(context.Background(), 2, false); !errors.Is(err, ErrAtCapacity) {
+		t.Fatalf("double release freed an extra slot: %v", err)
+	}
+}
+
+func TestRunLimiter_QueuesOverLimit(t *testing.T) {
+	l := &runLimiter{freed: make(chan struct{})}
+	release, err := l.acquire(context.Background(), 1, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	acquired := make(chan func())
+	go func() {
+		r, err := l.acquire(context.Background(), 1, true)
+		if err != nil {
+			t.Error(err)
+		}
+		acquired <- r
+	}()
+
+	// The second run waits while the first holds the only slot.
+	deadline := time.Now().Add(time.Second)
+	for {
+		l.mu.Lock()
+		queued := l.queued
+		l.mu.Unlock()
+		if queued == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("second run never queued")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case <-acquired:
+		t.Fatal("second run started while the limit was reached")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	release()
+	select {
+	case r := <-acquired:
+		r()
+	case <-time.After(time.Second):
+		t.Fatal("queued run did not start after a slot freed")
+	}
+	if l.running != 0 || l.queued != 0 {
+		t.Errorf("running=%d queued=%d, want 0/0", l.running, l.queued)
+	}
+}
+
+func TestRunLimiter_QueuedRunHonoursContext(t *testing.T) {
+	l := &runLimiter{freed: make(chan struct{})}
+	release, _ := l.acquire(context.Background(), 1, true)
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := l.acquire(ctx, 1, true); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want DeadlineExceeded", err)
+	}
+	if l.queued != 0 {
+		t.Errorf("queued = %d after giving up, want 0", l.queued)
+	}
+}
+
+func TestConcurrencyLimit(t *testing.T) {
+	if max, queue := concurrencyLimit(nil); max != 0 || !queue {
+		t.Errorf("nil config = %d/%v, want unlimited queue", max, queue)
+	}
+	cfg := &config.Config{Agent: config.AgentConfig{MaxConcurrentRuns: 3, OnCapacity: "reject"}}
+	if max, queue := concurrencyLimit(cfg); max != 3 || queue {
+		t.Errorf("got %d/%v, want 3/reject", max, queue)
+	}
+}
@@ -0,0 +1,53 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/mackeh/AegisClaw/internal/config"
+	"github.com/mackeh/AegisClaw/internal/ebpf"
+)
+
+// kernelMonitor is the part of *ebpf.Monitor the agent drives.
+type kernelMonitor interface {
+	OnEvent(ebpf.EventHandler)
+	Start(context.Context) error
+	Stop()
+}
+
+// newKernelMonitor is a variable so tests can observe which probes start.
+var newKernelMonitor = func(pc ebpf.ProbeConfig) kernelMonitor {
+	return ebpf.NewMonitor(pc)
+}
+
+// ebpfUnsupported ensures an unsupported host is reported once per process
+// rather than on every skill run.
+var ebpfUnsupported sync.Once
+
+// startKernelMonitor starts eBPF monitoring when security.ebpf.enabled is
+// set. The returned stop func is always safe to call. A host that cannot run
+// the probes is reported once and the run continues unmonitored; an invalid
+// probe list is an error.
+func startKernelMonitor(ctx context.Context, cfg *config.Config, handler ebpf.EventHandler) (func(), error) {
+	noop := func() {}
+	if cfg == nil || !cfg.Security.EBPF.Enabled {
+		return noop, nil
+	}
+	pc, err := ebpf.ProbeConfigFromNames(cfg.Security.EBPF.Probes)
+	if err != nil {
+		return noop, fmt.Errorf("invalid security.ebpf.probes: %w", err)
+	}
+
+	mon := newKernelMonitor(pc)
+	mon.OnEvent(handler)
+	if err := mon.Start(ctx); err != nil {
+		mon.Stop()
+		ebpfUnsupported.Do(func() {
+			fmt.Printf("⚠️  eBPF monitoring enabled but unavailable: %v\n", err)
+		})
+		return noop, nil
+	}
+	fmt.Println("🛡️  Kernel-level eBPF monitoring active.")
+	return mon.Stop, nil
+}
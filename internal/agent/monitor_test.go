@@ -0,0 +1,99 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mackeh/AegisClaw/internal/config"
+	"github.com/mackeh/AegisClaw/internal/ebpf"
+)
+
+type fakeMonitor struct {
+	probes   ebpf.ProbeConfig
+	startErr error
+	started  bool
+	stopped  bool
+}
+
+func (f *fakeMonitor) OnEvent(ebpf.EventHandler) {}
+func (f *fakeMonitor) Start(context.Context) error {
+	f.started = f.startErr == nil
+	return f.startErr
+}
+func (f *fakeMonitor) Stop() { f.stopped = true }
+
+func withFakeMonitor(t *testing.T, startErr error) **fakeMonitor {
+	t.Helper()
+	var created *fakeMonitor
+	orig := newKernelMonitor
+	newKernelMonitor = func(pc ebpf.ProbeConfig) kernelMonitor {
+		created = &fakeMonitor{probes: pc, startErr: startErr}
+		return created
+	}
+	t.Cleanup(func() { newKernelMonitor = orig })
+	return &created
+}
+
+func TestStartKernelMonitor_Disabled(t *testing.T) {
+	created := withFakeMonitor(t, nil)
+
+	stop, err := startKernelMonitor(context.Background(), &config.Config{}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stop()
+	if *created != nil {
+		t.Error("monitor was created although security.ebpf is disabled")
+	}
+
+	if _, err := startKernelMonitor(context.Background(), nil, nil); err != nil || *created != nil {
+		t.Errorf("nil config should not start a monitor (err=%v)", err)
+	}
+}
+
+func TestStartKernelMonitor_EnabledWithProbes(t *testing.T) {
+	created := withFakeMonitor(t, nil)
+
+	cfg := &config.Config{}
+	cfg.Security.EBPF = config.EBPFConfig{Enabled: true, Probes: []string{"network", "files"}}
+	stop, err := startKernelMonitor(context.Background(), cfg, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	mon := *created
+	if mon == nil || !mon.started {
+		t.Fatal("expected monitor to be started")
+	}
+	want := ebpf.ProbeConfig{TraceNetwork: true, TraceFiles: true}
+	if mon.probes.TraceNetwork != want.TraceNetwork || mon.probes.TraceFiles != want.TraceFiles ||
+		mon.probes.TraceSyscalls || mon.probes.TraceProcess {
+		t.Errorf("probes = %+v, want only network and files", mon.probes)
+	}
+	stop()
+	if !mon.stopped {
+		t.Error("stop func did not stop the monitor")
+	}
+}
+
+func TestStartKernelMonitor_UnsupportedContinues(t *testing.T) {
+	withFakeMonitor(t, errors.New("eBPF not supported"))
+
+	cfg := &config.Config{}
+	cfg.Security.EBPF.Enabled = true
+	stop, err := startKernelMonitor(context.Background(), cfg, nil)
+	if err != nil {
+		t.Fatalf("unsupported host should not fail the run: %v", err)
+	}
+	stop()
+}
+
+func TestStartKernelMonitor_InvalidProbe(t *testing.T) {
+	withFakeMonitor(t, nil)
+
+	cfg := &config.Config{}
+	cfg.Security.EBPF = config.EBPFConfig{Enabled: true, Probes: []string{"bogus"}}
+	if _, err := startKernelMonitor(context.Background(), cfg, nil); err == nil {
+		t.Error("expected error for unknown probe name")
+	}
+}
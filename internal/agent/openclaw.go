@@ -0,0 +1,124 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/mackeh/AegisClaw/internal/audit"
+	"github.com/mackeh/AegisClaw/internal/config"
+	"github.com/mackeh/AegisClaw/internal/guardrails"
+	"github.com/mackeh/AegisClaw/internal/openclaw"
+	"github.com/mackeh/AegisClaw/internal/sandbox"
+	"github.com/mackeh/AegisClaw/internal/scope"
+	"github.com/mackeh/AegisClaw/internal/skill"
+	"github.com/mackeh/AegisClaw/internal/telemetry"
+)
+
+// openClawRequest is one command of an OpenClaw-backed skill: its resolved
+// args are the HTTP method and the path relative to the adapter endpoint.
+type openClawRequest struct {
+	method string
+	path   string
+	// scope is the http.request scope for the endpoint's host, which
+	// policy decides like any scope the manifest declares.
+	scope scope.Scope
+}
+
+// newOpenClawRequest builds the request for a command's resolved args,
+// reading the adapter config in cfgDir for the endpoint.
+func newOpenClawRequest(cfgDir string, args []string) (*openClawRequest, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("openclaw commands take two args, the method and the path; got %d", len(args))
+	}
+	adapter, err := openclaw.LoadAdapterConfig(cfgDir)
+	if err != nil {
+		return nil, err
+	}
+	if !adapter.Enabled {
+		return nil, fmt.Errorf("openclaw adapter is disabled")
+	}
+	u, err := url.Parse(strings.TrimSpace(adapter.Endpoint))
+	if err != nil || u.Hostname() == "" {
+		return nil, fmt.Errorf("openclaw adapter: invalid endpoint URL %q", adapter.Endpoint)
+	}
+	s, err := scope.Parse(scope.HTTPRequest.Name + ":" + u.Hostname())
+	if err != nil {
+		return nil, err
+	}
+	return &openClawRequest{method: strings.ToUpper(args[0]), path: args[1], scope: s}, nil
+}
+
+// runOpenClaw sends r, with body (the run's piped input) if any, once
+// policy has allowed the run. The response has been scanned by guardrails
+// and redacted by the client; it is the run's stdout, and a 4xx or 5xx
+// status is exit code 1.
+func runOpenClaw(ctx context.Context, cfg *config.Config, cfgDir string, m *skill.Manifest, r *openClawRequest, body []byte, stdoutStream io.Writer, logger *audit.Logger, rec *RunRecord) (*ExecutionResult, error) {
+	client, err := openclaw.NewClient(cfgDir)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrExecutionFailed, err)
+	}
+	client.GuardMode = string(guardrailMode(cfg))
+
+	fmt.Printf("🚀 Running skill: %s (OpenClaw %s %s)\n", m.Name, r.method, r.path)
+
+	ctx, cancel := context.WithTimeout(ctx, executionTimeout)
+	defer cancel()
+	defer registerRun(rec, cancel)()
+
+	resp, err := client.Do(ctx, r.method, r.path, body)
+	if resp != nil && len(resp.Violations) > 0 {
+		if logger != nil {
+			for _, v := range resp.Violations {
+				_ = logger.LogAs("guardrail.violation", nil, string(v.Severity), audit.SkillActor(m.Name), map[string]any{
+					"rule":   v.Rule,
+					"source": "openclaw:" + r.path,
+					"run_id": rec.ID,
+				})
+			}
+		}
+		reportViolations(os.Stderr, m.Name, &guardrails.Result{Violations: resp.Violations})
+		rec.GuardrailViolations = resp.Violations
+	}
+	if runKilled(rec.ID) {
+		telemetry.SkillExecutionsTotal.WithLabelValues(telemetry.SkillLabel(m.Name), "killed").Inc()
+		rec.Reason = sandbox.ExitKilled
+		return nil, fmt.Errorf("%w: %s", ErrRunKilled, rec.ID)
+	}
+	if errors.Is(err, openclaw.ErrResponseBlocked) {
+		return nil, &OutputBlockedError{Skill: m.Name, Violations: resp.Violations}
+	}
+	if err != nil {
+		telemetry.SkillExecutionsTotal.WithLabelValues(telemetry.SkillLabel(m.Name), "error").Inc()
+		rec.Reason = sandbox.ExitError
+		return nil, fmt.Errorf("%w: %w", ErrExecutionFailed, err)
+	}
+	telemetry.SkillExecutionsTotal.WithLabelValues(telemetry.SkillLabel(m.Name), "success").Inc()
+	rec.Reason = sandbox.ExitCompleted
+	if logger != nil {
+		_ = logger.LogAs("openclaw.request", []scope.Scope{r.scope}, "allow", audit.SkillActor(m.Name), map[string]any{
+			"method": r.method,
+			"path":   r.path,
+			"status": resp.StatusCode,
+			"run_id": rec.ID,
+		})
+	}
+
+	fmt.Fprint(os.Stdout, resp.Body)
+	if stdoutStream != nil {
+		io.WriteString(stdoutStream, resp.Body)
+	}
+	exitCode := 0
+	if resp.StatusCode >= 400 {
+		exitCode = 1
+	}
+	return &ExecutionResult{
+		ExitCode: exitCode,
+		Reason:   sandbox.ExitCompleted,
+		Stdout:   resp.Body,
+	}, nil
+}
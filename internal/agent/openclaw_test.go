@@ -0,0 +1,113 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mackeh/AegisClaw/internal/audit"
+	"github.com/mackeh/AegisClaw/internal/secrets"
+	"github.com/mackeh/AegisClaw/internal/skill"
+)
+
+// openClawHome configures the OpenClaw adapter for a runOnceHome, pointing
+// it at endpoint with a stored API key.
+func openClawHome(t *testing.T, policy, endpoint string) string {
+	t.Helper()
+	runOnceHome(t, policy)
+	cfgDir := filepath.Join(os.Getenv("HOME"), ".aegisclaw")
+	if err := os.MkdirAll(filepath.Join(cfgDir, "adapters"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	adapter := "enabled: true\nendpoint: " + endpoint + "\napi_key_secret: OPENCLAW_KEY\n"
+	if err := os.WriteFile(filepath.Join(cfgDir, "adapters", "openclaw.yaml"), []byte(adapter), 0600); err != nil {
+		t.Fatal(err)
+	}
+	mgr := secrets.NewManager(filepath.Join(cfgDir, "secrets"))
+	if err := os.MkdirAll(filepath.Join(cfgDir, "secrets"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mgr.Init(); err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.Set("OPENCLAW_KEY", "oc-key-5e2d7a91"); err != nil {
+		t.Fatal(err)
+	}
+	return cfgDir
+}
+
+var openClawSkill = &skill.Manifest{
+	Name:     "claw",
+	Platform: "openclaw",
+	Commands: map[string]skill.Command{"task": {Args: []string{"post", "/v1/tasks"}}},
+}
+
+func TestExecuteSkill_OpenClawSkill(t *testing.T) {
+	var gotAuth, gotMethod, gotPath, gotBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth, gotMethod, gotPath = r.Header.Get("Authorization"), r.Method, r.URL.Path
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		w.Write([]byte(`{"reply":"done"}`))
+	}))
+	defer srv.Close()
+	cfgDir := openClawHome(t, allowAllPolicy, srv.URL)
+
+	var stream bytes.Buffer
+	res, err := ExecuteSkillWithStream(context.Background(), openClawSkill, "task", nil, strings.NewReader(`{"task":"x"}`), &stream, nil)
+	if err != nil {
+		t.Fatalf("ExecuteSkillWithStream: %v", err)
+	}
+	if gotAuth != "Bearer oc-key-5e2d7a91" || gotMethod != http.MethodPost || gotPath != "/v1/tasks" || gotBody != `{"task":"x"}` {
+		t.Errorf("OpenClaw got %s %s %q with auth %q", gotMethod, gotPath, gotBody, gotAuth)
+	}
+	if res.ExitCode != 0 || res.Stdout != `{"reply":"done"}` || stream.String() != res.Stdout {
+		t.Errorf("result %+v, streamed %q", res, stream.String())
+	}
+
+	entries, err := audit.ReadAll(filepath.Join(cfgDir, "audit", "audit.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actions []string
+	for _, e := range entries {
+		actions = append(actions, e.Action)
+		if e.Action == "openclaw.request" && (e.Identity == nil || e.Identity.ID != "claw") {
+			t.Errorf("openclaw.request attributed to %q", e.Actor)
+		}
+	}
+	if got := strings.Join(actions, ","); !strings.Contains(got, "skill.exec") || !strings.Contains(got, "openclaw.request") {
+		t.Errorf("audit actions = %s, want skill.exec then openclaw.request", got)
+	}
+}
+
+func TestExecuteSkill_OpenClawSkillNeedsPolicy(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer srv.Close()
+	openClawHome(t, `package aegisclaw.policy
+
+import rego.v1
+
+default decision = "allow"
+
+decision = "deny" if input.scope.name == "http.request"
+`, srv.URL)
+
+	if _, err := ExecuteSkill(context.Background(), openClawSkill, "task", nil); !errors.Is(err, ErrPolicyDenied) {
+		t.Errorf("err = %v, want ErrPolicyDenied", err)
+	}
+	if hits.Load() != 0 {
+		t.Error("OpenClaw was called although policy denied the request")
+	}
+}
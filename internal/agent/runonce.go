@@ -0,0 +1,83 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mackeh/AegisClaw/internal/skill"
+)
+
+// Exit codes of RunOnce when the skill itself did not run to completion.
+// A skill that ran exits with its own code.
+const (
+	ExitFailure    = 1
+	ExitDenied     = 77  // EX_NOPERM: refused by policy or approval, or output blocked
+	ExitLockedDown = 75  // EX_TEMPFAIL: emergency lockdown; retry once lifted
+	ExitKilled     = 137 // 128+SIGKILL: stopped by 'aegisclaw runs kill' or the API
+	ExitNotFound   = 127
+)
+
+// interactive reports whether approvals can be prompted for on stdin.
+var interactive = func() bool {
+	fi, err := os.Stdin.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// runOnceExec executes the skill; tests replace it to avoid Docker.
+var runOnceExec = func(ctx context.Context, m *skill.Manifest, cmdName string, args []string, stdin io.Reader) (*ExecutionResult, error) {
+	return ExecuteSkillWithStream(ctx, m, cmdName, args, stdin, nil, nil)
+}
+
+// RunOnce runs one command of an installed skill through the full agent
+// path (policy, approval, audit, redacted output) and returns the exit code
+// a scripting caller should exit with, alongside any error.
+func RunOnce(ctx context.Context, skillsDirs []string, name, cmdName string, args []string) (int, error) {
+	return RunOnceWithStdin(ctx, skillsDirs, name, cmdName, args, nil)
+}
+
+// RunOnceWithStdin is RunOnce with stdin, if non-nil, piped into the skill.
+func RunOnceWithStdin(ctx context.Context, skillsDirs []string, name, cmdName string, args []string, stdin io.Reader) (int, error) {
+	m, err := FindSkill(name, skillsDirs...)
+	if err != nil {
+		return ExitNotFound, err
+	}
+	res, err := runOnceExec(ctx, m, cmdName, args, stdin)
+	return ExitCodeFor(res, err), err
+}
+
+// ExitCodeFor maps the outcome of an execution to a process exit code.
+func ExitCodeFor(res *ExecutionResult, err error) int {
+	switch {
+	case errors.Is(err, ErrLockedDown):
+		return ExitLockedDown
+	case IsDenied(err), errors.Is(err, ErrOutputBlocked):
+		return ExitDenied
+	case errors.Is(err, ErrRunKilled):
+		return ExitKilled
+	case err != nil || res == nil:
+		return ExitFailure
+	default:
+		return res.ExitCode
+	}
+}
+
+// FindSkill returns the skill called name from the first directory that has
+// it, so pass dirs in precedence order (see skill.SearchPaths). Missing
+// directories are skipped.
+func FindSkill(name string, dirs ...string) (*skill.Manifest, error) {
+	for _, dir := range dirs {
+		manifests, err := skill.ListSkills(dir)
+		if err != nil {
+			continue
+		}
+		for _, m := range manifests {
+			if m.Name == name {
+				return m, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("skill %q is not installed", name)
+}
@@ -0,0 +1,151 @@
+package agent
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mackeh/AegisClaw/internal/guardrails"
+	"github.com/mackeh/AegisClaw/internal/skill"
+)
+
+// Approval outcomes recorded on a RunRecord.
+const (
+	ApprovalNotRequired = "not_required"
+	ApprovalRemembered  = "remembered"
+	ApprovalUser        = "user_approved"
+	ApprovalUserDenied  = "user_denied"
+	ApprovalPolicyDeny  = "policy_denied"
+	ApprovalUnavailable = "no_approver" // approval needed but no terminal to ask on
+)
+
+// RunRecord is the provenance record of a single skill execution: what ran,
+// with which permissions, who approved it, and what happened.
+type RunRecord struct {
+	ID                  string                 `json:"id"`
+	Skill               string                 `json:"skill"`
+	Version             string                 `json:"version"`
+	Command             string                 `json:"command"`
+	Image               string                 `json:"image"`
+	ImageDigest         string                 `json:"image_digest,omitempty"`
+	Scopes              []string               `json:"scopes"`
+	PolicyDecision      string                 `json:"policy_decision,omitempty"`
+	Approval            string                 `json:"approval,omitempty"`
+	StartedAt           time.Time              `json:"started_at"`
+	DurationMS          int64                  `json:"duration_ms"`
+	ExitCode            int                    `json:"exit_code"`
+	Reason              string                 `json:"reason,omitempty"` // how the container stopped, e.g. oom_killed
+	Error               string                 `json:"error,omitempty"`
+	Anomalies           []string               `json:"anomalies,omitempty"`
+	GuardrailViolations []guardrails.Violation `json:"guardrail_violations,omitempty"`
+	Artifacts           []string               `json:"artifacts,omitempty"` // host paths of collected outputs
+	TraceID             string                 `json:"trace_id,omitempty"`
+	SpanID              string                 `json:"span_id,omitempty"`
+	Detached            bool                   `json:"detached,omitempty"` // left running once healthy; see ExecuteSkillDetached
+
+	mu sync.Mutex
+}
+
+func newRunRecord(m *skill.Manifest, cmdName string) *RunRecord {
+	buf := make([]byte, 4)
+	_, _ = rand.Read(buf)
+	now := time.Now().UTC()
+	return &RunRecord{
+		ID:        now.Format("20060102T150405") + "-" + hex.EncodeToString(buf),
+		Skill:     m.Name,
+		Version:   m.Version,
+		Command:   cmdName,
+		Image:     m.Image,
+		StartedAt: now,
+	}
+}
+
+// addAnomaly is safe to call from monitor callbacks.
+func (r *RunRecord) addAnomaly(msg string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Anomalies = append(r.Anomalies, msg)
+}
+
+// finish stamps the duration and outcome of the run.
+func (r *RunRecord) finish(res *ExecutionResult, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.DurationMS = time.Since(r.StartedAt).Milliseconds()
+	if res != nil {
+		r.ExitCode = res.ExitCode
+	}
+	if err != nil {
+		r.Error = err.Error()
+		if res == nil {
+			r.ExitCode = -1
+		}
+	}
+}
+
+// RunsDir returns the directory run records are persisted to.
+func RunsDir(cfgDir string) string {
+	return filepath.Join(cfgDir, "runs")
+}
+
+// SaveRunRecord writes r to dir/<id>.json.
+func SaveRunRecord(dir string, r *RunRecord) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create runs directory: %w", err)
+	}
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode run record: %w", err)
+	}
+	return os.WriteFile(filepath.Join(dir, r.ID+".json"), data, 0600)
+}
+
+// LoadRunRecord reads the record with the given ID from dir.
+func LoadRunRecord(dir, id string) (*RunRecord, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || strings.Contains(id, "..") {
+		return nil, fmt.Errorf("invalid run id %q", id)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, id+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read run record: %w", err)
+	}
+	var r RunRecord
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("failed to parse run record: %w", err)
+	}
+	return &r, nil
+}
+
+// ListRunRecords returns all records in dir, newest first. Unreadable files
+// are skipped.
+func ListRunRecords(dir string) ([]*RunRecord, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var records []*RunRecord
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		r, err := LoadRunRecord(dir, strings.TrimSuffix(e.Name(), ".json"))
+		if err != nil {
+			continue
+		}
+		records = append(records, r)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].StartedAt.After(records[j].StartedAt) })
+	return records, nil
+}
@@ -0,0 +1,76 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mackeh/AegisClaw/internal/skill"
+)
+
+func TestExecuteSkill_PolicyDenyProducesRunRecord(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	cfgDir := filepath.Join(home, ".aegisclaw")
+	if err := os.MkdirAll(cfgDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	policy := "package aegisclaw.policy\nimport rego.v1\ndefault decision = \"deny\"\n"
+	if err := os.WriteFile(filepath.Join(cfgDir, "policy.rego"), []byte(policy), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &skill.Manifest{
+		Name:     "demo",
+		Version:  "1.2.3",
+		Image:    "alpine:latest",
+		Scopes:   []string{"files.read:/tmp"},
+		Commands: map[string]skill.Command{"hello": {Args: []string{"echo", "hi"}}},
+	}
+	if _, err := ExecuteSkill(context.Background(), m, "hello", nil); err == nil {
+		t.Fatal("expected policy denial")
+	}
+
+	records, err := ListRunRecords(RunsDir(cfgDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("expected 1 run record, got %d", len(records))
+	}
+	r := records[0]
+	if r.ID == "" || r.Skill != "demo" || r.Version != "1.2.3" || r.Command != "hello" || r.Image != "alpine:latest" {
+		t.Errorf("identity fields not populated: %+v", r)
+	}
+	if len(r.Scopes) != 1 || r.Scopes[0] != "files.read:/tmp" {
+		t.Errorf("scopes = %v", r.Scopes)
+	}
+	if r.PolicyDecision != "deny" || r.Approval != ApprovalPolicyDeny {
+		t.Errorf("decision/approval = %q/%q", r.PolicyDecision, r.Approval)
+	}
+	if r.Error == "" || r.ExitCode != -1 || r.StartedAt.IsZero() {
+		t.Errorf("outcome fields not populated: %+v", r)
+	}
+
+	loaded, err := LoadRunRecord(RunsDir(cfgDir), r.ID)
+	if err != nil || loaded.ID != r.ID {
+		t.Fatalf("LoadRunRecord: %v", err)
+	}
+}
+
+func TestRunRecord_FinishSuccess(t *testing.T) {
+	rec := newRunRecord(&skill.Manifest{Name: "s", Version: "1"}, "c")
+	rec.finish(&ExecutionResult{ExitCode: 3}, nil)
+	if rec.ExitCode != 3 || rec.Error != "" {
+		t.Errorf("got exit %d err %q", rec.ExitCode, rec.Error)
+	}
+	rec.finish(nil, errors.New("boom"))
+	if rec.ExitCode != -1 || rec.Error != "boom" {
+		t.Errorf("got exit %d err %q", rec.ExitCode, rec.Error)
+	}
+	if _, err := LoadRunRecord(t.TempDir(), "../etc/passwd"); err == nil {
+		t.Error("expected traversal id to be rejected")
+	}
+}
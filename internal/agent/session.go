@@ -0,0 +1,83 @@
+package agent
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/mackeh/AegisClaw/internal/audit"
+)
+
+// Version is the AegisClaw version recorded in session.start entries. The
+// CLI sets it from its build version.
+var Version = "dev"
+
+// SessionInfo fingerprints the configuration a session ran under. Hashes
+// are hex SHA-256 of the files in the config directory, or "none" if the
+// file does not exist (built-in defaults apply).
+type SessionInfo struct {
+	ConfigHash string
+	PolicyHash string
+	Version    string
+}
+
+// lastSession is the fingerprint most recently written by this process, so
+// session.start is logged once at startup and again only if config or
+// policy change underneath a long-running process.
+var lastSession struct {
+	sync.Mutex
+	info SessionInfo
+}
+
+// CurrentSession hashes config.yaml and policy.rego in cfgDir.
+func CurrentSession(cfgDir string) (SessionInfo, error) {
+	cfgHash, err := hashFile(filepath.Join(cfgDir, "config.yaml"))
+	if err != nil {
+		return SessionInfo{}, err
+	}
+	policyHash, err := hashFile(filepath.Join(cfgDir, "policy.rego"))
+	if err != nil {
+		return SessionInfo{}, err
+	}
+	return SessionInfo{ConfigHash: cfgHash, PolicyHash: policyHash, Version: Version}, nil
+}
+
+// logSessionStart writes a session.start entry anchoring the entries that
+// follow to the config and policy in effect, unless this process already
+// logged the same fingerprint.
+func logSessionStart(logger *audit.Logger, cfgDir string) error {
+	info, err := CurrentSession(cfgDir)
+	if err != nil {
+		return err
+	}
+	lastSession.Lock()
+	defer lastSession.Unlock()
+	if info == lastSession.info {
+		return nil
+	}
+	if err := logger.LogAs("session.start", nil, "observed", audit.SystemActor("aegisclaw"), map[string]any{
+		"config_sha256": info.ConfigHash,
+		"policy_sha256": info.PolicyHash,
+		"version":       info.Version,
+	}); err != nil {
+		return err
+	}
+	lastSession.info = info
+	return nil
+}
+
+func hashFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "none", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to hash %s: %w", path, err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
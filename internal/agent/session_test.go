@@ -0,0 +1,91 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mackeh/AegisClaw/internal/audit"
+)
+
+func sessionEntries(t *testing.T, path string) []audit.Entry {
+	t.Helper()
+	entries, err := audit.ReadAll(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []audit.Entry
+	for _, e := range entries {
+		if e.Action == "session.start" {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func TestLogSessionStart_RecordsHashes(t *testing.T) {
+	lastSession.info = SessionInfo{}
+	cfgDir := t.TempDir()
+	writeFile := func(name, body string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(cfgDir, name), []byte(body), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("config.yaml", "version: \"1\"\n")
+	writeFile("policy.rego", "package aegisclaw.policy\ndefault decision = \"deny\"\n")
+
+	auditPath := filepath.Join(cfgDir, "audit", "audit.log")
+	logger, err := audit.NewLogger(auditPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	if err := logSessionStart(logger, cfgDir); err != nil {
+		t.Fatalf("logSessionStart: %v", err)
+	}
+	// Unchanged config in the same process is not logged again.
+	if err := logSessionStart(logger, cfgDir); err != nil {
+		t.Fatal(err)
+	}
+	entries := sessionEntries(t, auditPath)
+	if len(entries) != 1 {
+		t.Fatalf("got %d session.start entries, want 1", len(entries))
+	}
+	first := entries[0].Details
+	for _, k := range []string{"config_sha256", "policy_sha256"} {
+		if v, _ := first[k].(string); len(v) != 64 {
+			t.Errorf("%s = %q, want a SHA-256 hex digest", k, v)
+		}
+	}
+	if first["version"] != Version {
+		t.Errorf("version = %v, want %s", first["version"], Version)
+	}
+
+	writeFile("policy.rego", "package aegisclaw.policy\ndefault decision = \"allow\"\n")
+	if err := logSessionStart(logger, cfgDir); err != nil {
+		t.Fatal(err)
+	}
+	entries = sessionEntries(t, auditPath)
+	if len(entries) != 2 {
+		t.Fatalf("a policy change should log a new session.start, got %d entries", len(entries))
+	}
+	second := entries[1].Details
+	if second["policy_sha256"] == first["policy_sha256"] {
+		t.Error("policy hash did not change with the policy")
+	}
+	if second["config_sha256"] != first["config_sha256"] {
+		t.Error("config hash changed although config.yaml did not")
+	}
+}
+
+func TestCurrentSession_MissingFiles(t *testing.T) {
+	info, err := CurrentSession(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.ConfigHash != "none" || info.PolicyHash != "none" {
+		t.Errorf("missing files should hash to none, got %+v", info)
+	}
+}
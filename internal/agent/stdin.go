@@ -0,0 +1,62 @@
+package agent
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mackeh/AegisClaw/internal/guardrails"
+)
+
+// MaxStdinBytes caps the payload piped into a skill. Stdin is buffered in
+// full so guardrails can inspect it before the container sees any of it.
+const MaxStdinBytes = 16 << 20 // 16MB
+
+// readStdin buffers a skill's stdin payload, refusing one over
+// MaxStdinBytes rather than silently truncating it.
+func readStdin(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxStdinBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stdin: %w", err)
+	}
+	if len(data) > MaxStdinBytes {
+		return nil, fmt.Errorf("stdin exceeds %d bytes", MaxStdinBytes)
+	}
+	return data, nil
+}
+
+// checkStdin scans a piped payload with the input guardrails, since it
+// reaches the skill (and often a model behind it) like a prompt would. It
+// returns nil when guardrails are off or nothing was flagged.
+func checkStdin(mode GuardrailMode, guard *guardrails.Engine, data []byte) *guardrails.Result {
+	if mode == GuardrailOff || len(data) == 0 {
+		return nil
+	}
+	if guard == nil {
+		guard = guardrails.NewEngine()
+	}
+	res := guard.CheckInput(string(data))
+	if len(res.Violations) == 0 {
+		return nil
+	}
+	return res
+}
+
+// reportStdinViolations prints why a piped payload was flagged.
+func reportStdinViolations(w io.Writer, skillName string, res *guardrails.Result) {
+	fmt.Fprintf(w, "⚠️  Guardrails flagged the input piped to '%s':\n", skillName)
+	for _, v := range res.Violations {
+		fmt.Fprintf(w, "   [%s] %s: %s\n", strings.ToUpper(string(v.Severity)), v.Rule, v.Message)
+	}
+}
+
+// stdinReader returns the sandbox stdin for a buffered payload, or nil when
+// the caller supplied none. An empty payload still opens stdin so the skill
+// reads EOF instead of blocking.
+func stdinReader(stdin io.Reader, data []byte) io.Reader {
+	if stdin == nil {
+		return nil
+	}
+	return bytes.NewReader(data)
+}
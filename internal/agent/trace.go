@@ -0,0 +1,29 @@
+package agent
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// traceContextEnv renders the active span's W3C trace context as
+// TRACEPARENT/TRACESTATE environment variables so an instrumented skill can
+// continue the trace inside the sandbox. It returns nil when ctx carries no
+// valid span.
+func traceContextEnv(ctx context.Context) []string {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return nil
+	}
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+
+	var env []string
+	for _, key := range []string{"traceparent", "tracestate"} {
+		if v := carrier.Get(key); v != "" {
+			env = append(env, strings.ToUpper(key)+"="+v)
+		}
+	}
+	return env
+}
@@ -0,0 +1,41 @@
+package agent
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+var traceparentRe = regexp.MustCompile(`^TRACEPARENT=00-([0-9a-f]{32})-([0-9a-f]{16})-0[0-9a-f]$`)
+
+func TestTraceContextEnv(t *testing.T) {
+	if env := traceContextEnv(context.Background()); env != nil {
+		t.Errorf("expected no env without an active span, got %v", env)
+	}
+
+	tp := sdktrace.NewTracerProvider()
+	defer tp.Shutdown(context.Background())
+	ctx, span := tp.Tracer("test").Start(context.Background(), "ExecuteSkill")
+	defer span.End()
+
+	env := traceContextEnv(ctx)
+	if len(env) == 0 {
+		t.Fatal("expected TRACEPARENT in env")
+	}
+	match := traceparentRe.FindStringSubmatch(env[0])
+	if match == nil {
+		t.Fatalf("invalid traceparent: %q", env[0])
+	}
+	sc := span.SpanContext()
+	if match[1] != sc.TraceID().String() || match[2] != sc.SpanID().String() {
+		t.Errorf("traceparent %q does not match span %s/%s", env[0], sc.TraceID(), sc.SpanID())
+	}
+	for _, kv := range env {
+		if !strings.HasPrefix(kv, "TRACEPARENT=") && !strings.HasPrefix(kv, "TRACESTATE=") {
+			t.Errorf("unexpected env var %q", kv)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package audit
+
+import "os/user"
+
+// ActorKind is the kind of identity behind an audited action.
+type ActorKind string
+
+const (
+	// ActorCLIUser is a local user running the aegisclaw CLI; the ID is
+	// their OS username.
+	ActorCLIUser ActorKind = "cli-user"
+	// ActorAPIKey is a caller of the HTTP API; the ID is the API key's
+	// name, empty when authentication is off.
+	ActorAPIKey ActorKind = "api-key"
+	// ActorSkill is a skill acting inside its sandbox; the ID is its name.
+	ActorSkill ActorKind = "skill"
+	// ActorNode is a cluster node; the ID is its node ID.
+	ActorNode ActorKind = "node"
+	// ActorMCPClient is an MCP client; the ID is the name it announced.
+	ActorMCPClient ActorKind = "mcp-client"
+	// ActorSystem is one of AegisClaw's own components, such as the
+	// egress proxy; the ID names it.
+	ActorSystem ActorKind = "system"
+)
+
+// Actor identifies who an audited action is attributed to. Entries record
+// it as Identity alongside its String form in Actor, which is what entries
+// carried before identities were structured.
+type Actor struct {
+	Kind ActorKind `json:"kind"`
+	ID   string    `json:"id"`
+}
+
+// String renders the actor in the free-form style of Entry.Actor: skills
+// and system components by bare name, the rest prefixed by kind, e.g.
+// "api:ops-dashboard", "cli:alice" or "node:node-2".
+func (a Actor) String() string {
+	prefix := ""
+	switch a.Kind {
+	case ActorSkill, ActorSystem, "":
+		return a.ID
+	case ActorAPIKey:
+		prefix = "api"
+	case ActorCLIUser:
+		prefix = "cli"
+	case ActorMCPClient:
+		prefix = "mcp"
+	default:
+		prefix = string(a.Kind)
+	}
+	if a.ID == "" {
+		return prefix
+	}
+	return prefix + ":" + a.ID
+}
+
+// SkillActor attributes an action to the named skill.
+func SkillActor(name string) Actor { return Actor{Kind: ActorSkill, ID: name} }
+
+// APIKeyActor attributes an action to the API key with the given name.
+func APIKeyActor(name string) Actor { return Actor{Kind: ActorAPIKey, ID: name} }
+
+// NodeActor attributes an action to the cluster node with the given ID.
+func NodeActor(id string) Actor { return Actor{Kind: ActorNode, ID: id} }
+
+// MCPClientActor attributes an action to the named MCP client.
+func MCPClientActor(name string) Actor { return Actor{Kind: ActorMCPClient, ID: name} }
+
+// SystemActor attributes an action to an AegisClaw component.
+func SystemActor(name string) Actor { return Actor{Kind: ActorSystem, ID: name} }
+
+// CLIActor attributes an action to the OS user running the CLI, or to an
+// anonymous CLI user if it cannot be determined.
+func CLIActor() Actor {
+	a := Actor{Kind: ActorCLIUser}
+	if u, err := user.Current(); err == nil {
+		a.ID = u.Username
+	}
+	return a
+}
@@ -0,0 +1,200 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/mackeh/AegisClaw/internal/config"
+)
+
+// AnchorRecord is the head of the local chain at one moment. Stored where
+// the host cannot rewrite it, it proves what the log contained then: a log
+// replaced wholesale, even with a valid new chain, no longer matches.
+type AnchorRecord struct {
+	Time   time.Time `json:"time"`
+	Seq    uint64    `json:"seq"`  // number of entries covered; 0 for an empty log
+	Hash   string    `json:"hash"` // hash of entry Seq, or "genesis"
+	Reason string    `json:"reason,omitempty"`
+}
+
+// AnchorSink stores anchors off the host.
+type AnchorSink interface {
+	WriteAnchor(AnchorRecord) error
+}
+
+// Anchor verifies the log at path and sends its current head to sink. A
+// broken chain is not anchored, so an anchor never vouches for a tampered
+// log.
+func Anchor(path string, sink AnchorSink, reason string) (AnchorRecord, error) {
+	if _, err := Verify(path); err != nil {
+		return AnchorRecord{}, fmt.Errorf("refusing to anchor: %w", err)
+	}
+	entries, err := ReadAll(path)
+	if err != nil {
+		return AnchorRecord{}, err
+	}
+	a := AnchorRecord{Time: time.Now().UTC(), Seq: uint64(len(entries)), Hash: "genesis", Reason: reason}
+	if len(entries) > 0 {
+		a.Hash = entries[len(entries)-1].Hash
+	}
+	if err := sink.WriteAnchor(a); err != nil {
+		return a, fmt.Errorf("failed to write anchor: %w", err)
+	}
+	return a, nil
+}
+
+// CheckAnchor reports whether the log at path still holds the anchored
+// head: entry a.Seq must exist with the anchored hash, and the chain up to
+// it must verify.
+func CheckAnchor(path string, a AnchorRecord) error {
+	if _, err := Verify(path); err != nil {
+		return err
+	}
+	entries, err := ReadAll(path)
+	if err != nil {
+		return err
+	}
+	if a.Seq == 0 {
+		return nil
+	}
+	if uint64(len(entries)) < a.Seq {
+		return fmt.Errorf("log has %d entries but the anchor of %s covers %d (entries were removed)", len(entries), a.Time.Format(time.RFC3339), a.Seq)
+	}
+	if got := entries[a.Seq-1].Hash; got != a.Hash {
+		return fmt.Errorf("log diverges from the anchor of %s at entry %d: hash %.12s, anchored %.12s", a.Time.Format(time.RFC3339), a.Seq, got, a.Hash)
+	}
+	return nil
+}
+
+// FileAnchorSink appends anchors as JSON lines, e.g. to separate media.
+type FileAnchorSink struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileAnchorSink appends anchors to path, creating it if needed.
+func NewFileAnchorSink(path string) (*FileAnchorSink, error) {
+	if path == "" {
+		return nil, fmt.Errorf("audit anchor file: path is required")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create anchor directory: %w", err)
+	}
+	return &FileAnchorSink{path: path}, nil
+}
+
+// WriteAnchor appends one anchor.
+func (s *FileAnchorSink) WriteAnchor(a AnchorRecord) error {
+	data, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ReadAnchors reads the anchors a FileAnchorSink wrote, oldest first.
+func ReadAnchors(path string) ([]AnchorRecord, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read anchors: %w", err)
+	}
+	var anchors []AnchorRecord
+	for i, line := range splitLines(data) {
+		if len(line) == 0 {
+			continue
+		}
+		var a AnchorRecord
+		if err := json.Unmarshal(line, &a); err != nil {
+			return nil, fmt.Errorf("failed to parse anchor %d: %w", i+1, err)
+		}
+		anchors = append(anchors, a)
+	}
+	return anchors, nil
+}
+
+// WebhookAnchorSink POSTs each anchor as JSON, e.g. to a notification
+// service that keeps its own history.
+type WebhookAnchorSink struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewWebhookAnchorSink validates rawURL.
+func NewWebhookAnchorSink(rawURL string) (*WebhookAnchorSink, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("audit anchor webhook: invalid url %q", rawURL)
+	}
+	return &WebhookAnchorSink{URL: rawURL, Client: &http.Client{Timeout: 10 * time.Second}}, nil
+}
+
+// WriteAnchor posts one anchor; any non-2xx response is an error.
+func (s *WebhookAnchorSink) WriteAnchor(a AnchorRecord) error {
+	body, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	resp, err := s.Client.Post(s.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("anchor webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// multiAnchorSink writes to every sink, reporting the first error.
+type multiAnchorSink []AnchorSink
+
+func (m multiAnchorSink) WriteAnchor(a AnchorRecord) error {
+	var first error
+	for _, s := range m {
+		if err := s.WriteAnchor(a); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// NewAnchorSink builds the sink for audit.anchor. It returns nil when no
+// file or webhook is configured.
+func NewAnchorSink(c config.AuditAnchorConfig) (AnchorSink, error) {
+	var sinks multiAnchorSink
+	if c.File != "" {
+		s, err := NewFileAnchorSink(c.File)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, s)
+	}
+	if c.Webhook != "" {
+		s, err := NewWebhookAnchorSink(c.Webhook)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, s)
+	}
+	if len(sinks) == 0 {
+		return nil, nil
+	}
+	return sinks, nil
+}
@@ -0,0 +1,129 @@
+package audit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeEntries appends n entries to the log at path.
+func writeEntries(t *testing.T, path, actor string, n int) {
+	t.Helper()
+	l, err := NewLogger(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	for i := 0; i < n; i++ {
+		if err := l.Log("test", nil, "allow", actor, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestAnchor_CapturesHead(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "audit.log")
+	writeEntries(t, logPath, "alice", 3)
+
+	sink, err := NewFileAnchorSink(filepath.Join(dir, "anchors", "anchors.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := Anchor(logPath, sink, "manual")
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := ReadAll(logPath)
+	if a.Seq != 3 || a.Hash != entries[2].Hash || a.Reason != "manual" {
+		t.Errorf("anchor = %+v, want seq 3 and hash %s", a, entries[2].Hash)
+	}
+
+	anchors, err := ReadAnchors(sink.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(anchors) != 1 || anchors[0].Hash != a.Hash {
+		t.Errorf("stored anchors = %+v", anchors)
+	}
+
+	// Appending keeps the anchor valid.
+	writeEntries(t, logPath, "alice", 2)
+	if err := CheckAnchor(logPath, a); err != nil {
+		t.Errorf("log grown after anchoring: %v", err)
+	}
+}
+
+func TestCheckAnchor_DetectsRewrittenLog(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "audit.log")
+	writeEntries(t, logPath, "alice", 3)
+	sink, _ := NewFileAnchorSink(filepath.Join(dir, "anchors.jsonl"))
+	a, err := Anchor(logPath, sink, "interval")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Replace the whole log with a fresh chain that verifies on its own.
+	if err := os.Remove(logPath); err != nil {
+		t.Fatal(err)
+	}
+	writeEntries(t, logPath, "mallory", 4)
+	if ok, err := Verify(logPath); !ok || err != nil {
+		t.Fatalf("rewritten chain should verify by itself: %v", err)
+	}
+	if err := CheckAnchor(logPath, a); err == nil || !strings.Contains(err.Error(), "diverges") {
+		t.Errorf("CheckAnchor = %v, want divergence", err)
+	}
+
+	// A truncated log is caught too.
+	if err := os.Remove(logPath); err != nil {
+		t.Fatal(err)
+	}
+	writeEntries(t, logPath, "mallory", 1)
+	if err := CheckAnchor(logPath, a); err == nil || !strings.Contains(err.Error(), "removed") {
+		t.Errorf("CheckAnchor = %v, want removed entries", err)
+	}
+}
+
+func TestAnchor_RefusesBrokenChain(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "audit.log")
+	writeEntries(t, logPath, "alice", 2)
+	data, _ := os.ReadFile(logPath)
+	lines := strings.SplitN(string(data), "\n", 2)
+	if err := os.WriteFile(logPath, []byte(lines[1]), 0600); err != nil {
+		t.Fatal(err)
+	}
+	sink, _ := NewFileAnchorSink(filepath.Join(dir, "anchors.jsonl"))
+	if _, err := Anchor(logPath, sink, "manual"); err == nil {
+		t.Error("a broken chain must not be anchored")
+	}
+}
+
+func TestWebhookAnchorSink(t *testing.T) {
+	var got AnchorRecord
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "audit.log")
+	writeEntries(t, logPath, "alice", 1)
+	sink, err := NewWebhookAnchorSink(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := Anchor(logPath, sink, "lockdown")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Hash != a.Hash || got.Seq != 1 || got.Reason != "lockdown" {
+		t.Errorf("webhook received %+v, want %+v", got, a)
+	}
+}
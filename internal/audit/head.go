@@ -0,0 +1,65 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// The head is a sidecar file (HeadPath) holding the seq and hash of the
+// last entry appended. The chain alone cannot tell a log whose trailing
+// entries were cut off from one that simply ended there; Verify compares
+// the log against the head to catch that. The head is only ever behind the
+// log after a crash between the two writes, which Verify tolerates.
+
+// logHead is the content of the head file.
+type logHead struct {
+	Seq  uint64 `json:"seq"`
+	Hash string `json:"hash"`
+}
+
+// HeadPath returns the head file for the log at logPath.
+func HeadPath(logPath string) string {
+	return logPath + ".head"
+}
+
+// writeHead records e as the log's head. It is written atomically so a
+// crash never leaves a torn head; failures are ignored since the log entry
+// itself is already persisted.
+func (l *Logger) writeHead(e Entry) {
+	if l.path == "" || e.Seq == 0 {
+		return
+	}
+	data, err := json.Marshal(logHead{Seq: e.Seq, Hash: e.Hash})
+	if err != nil {
+		return
+	}
+	tmp := HeadPath(l.path) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return
+	}
+	_ = os.Rename(tmp, HeadPath(l.path))
+}
+
+// checkHead compares the last entry of the log at path (lastSeq, lastHash)
+// with the recorded head. A log without a head file predates it and passes.
+func checkHead(path string, lastSeq uint64, lastHash string) error {
+	data, err := os.ReadFile(HeadPath(path))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read audit log head: %w", err)
+	}
+	var h logHead
+	if err := json.Unmarshal(data, &h); err != nil {
+		return fmt.Errorf("failed to parse audit log head: %w", err)
+	}
+	switch {
+	case lastSeq < h.Seq:
+		return fmt.Errorf("log ends at seq %d but entries up to seq %d were written (trailing entries were removed)", lastSeq, h.Seq)
+	case lastSeq == h.Seq && lastHash != h.Hash:
+		return fmt.Errorf("last entry (seq %d) does not match the recorded head: hash %.12s, recorded %.12s", lastSeq, lastHash, h.Hash)
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+package audit
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// SignatureHeader carries the hex HMAC-SHA256 of an HTTP sink batch body,
+// prefixed "sha256=", when an HMAC key is configured.
+const SignatureHeader = "X-AegisClaw-Signature"
+
+// HTTPSinkOptions configures an HTTPSink. Zero values use the defaults
+// noted on each field.
+type HTTPSinkOptions struct {
+	URL           string
+	HMACKey       []byte        // optional; signs each batch body
+	BatchSize     int           // entries per push (default 50)
+	FlushInterval time.Duration // max delay before a partial batch is pushed (default 5s)
+	MaxRetries    int           // retries per push after the first attempt (default 3)
+	RetryBackoff  time.Duration // initial backoff, doubled per retry (default 500ms)
+	MaxBuffer     int           // pending entries kept while the collector is down (default 10000)
+	Client        *http.Client  // default: 10s timeout
+}
+
+// HTTPSink pushes entries to a central collector as JSON arrays. Entries are
+// buffered and sent in batches; failed pushes are retried with backoff and,
+// if the collector stays down, kept (up to MaxBuffer, oldest dropped first)
+// for the next flush.
+type HTTPSink struct {
+	opts HTTPSinkOptions
+
+	mu      sync.Mutex
+	pending []Entry
+	dropped int
+
+	flushMu sync.Mutex // serialises pushes so batches stay in order
+	kick    chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// NewHTTPSink validates opts and starts the background flusher.
+func NewHTTPSink(opts HTTPSinkOptions) (*HTTPSink, error) {
+	u, err := url.Parse(opts.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("audit http sink: invalid url %q", opts.URL)
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 50
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = 5 * time.Second
+	}
+	if opts.MaxRetries < 0 {
+		opts.MaxRetries = 0
+	} else if opts.MaxRetries == 0 {
+		opts.MaxRetries = 3
+	}
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = 500 * time.Millisecond
+	}
+	if opts.MaxBuffer <= 0 {
+		opts.MaxBuffer = 10000
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	s := &HTTPSink{
+		opts: opts,
+		kick: make(chan struct{}, 1),
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go s.loop()
+	return s, nil
+}
+
+// Write queues an entry, triggering a push once a full batch is pending.
+func (s *HTTPSink) Write(e Entry) error {
+	s.mu.Lock()
+	s.pending = append(s.pending, e)
+	s.trimLocked()
+	full := len(s.pending) >= s.opts.BatchSize
+	s.mu.Unlock()
+
+	if full {
+		select {
+		case s.kick <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// Flush pushes all pending entries, batch by batch. It stops at the first
+// batch that still fails after retries, leaving it queued.
+func (s *HTTPSink) Flush() error {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+
+	for {
+		s.mu.Lock()
+		n := len(s.pending)
+		if n == 0 {
+			s.mu.Unlock()
+			return nil
+		}
+		if n > s.opts.BatchSize {
+			n = s.opts.BatchSize
+		}
+		batch := append([]Entry(nil), s.pending[:n]...)
+		s.pending = s.pending[n:]
+		s.mu.Unlock()
+
+		if err := s.push(batch); err != nil {
+			s.mu.Lock()
+			s.pending = append(batch, s.pending...)
+			s.trimLocked()
+			s.mu.Unlock()
+			return err
+		}
+	}
+}
+
+// Pending returns the number of queued entries.
+func (s *HTTPSink) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.pending)
+}
+
+// Close stops the flusher and makes a final push attempt.
+func (s *HTTPSink) Close() error {
+	select {
+	case <-s.stop:
+		return nil
+	default:
+		close(s.stop)
+	}
+	<-s.done
+	return s.Flush()
+}
+
+func (s *HTTPSink) loop() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.opts.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		case <-s.kick:
+		}
+		_ = s.Flush()
+	}
+}
+
+// push sends one batch, retrying network errors, 429s and 5xx responses.
+func (s *HTTPSink) push(batch []Entry) error {
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit batch: %w", err)
+	}
+
+	backoff := s.opts.RetryBackoff
+	var lastErr error
+	for attempt := 0; attempt <= s.opts.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(backoff):
+			case <-s.stop:
+				// Shutting down: skip the wait but still try once more below.
+			}
+			backoff *= 2
+		}
+
+		retry, err := s.post(body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+	return fmt.Errorf("audit http sink: push of %d entries failed: %w", len(batch), lastErr)
+}
+
+func (s *HTTPSink) post(body []byte) (retry bool, err error) {
+	req, err := http.NewRequest(http.MethodPost, s.opts.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(s.opts.HMACKey) > 0 {
+		req.Header.Set(SignatureHeader, "sha256="+SignBatch(s.opts.HMACKey, body))
+	}
+
+	resp, err := s.opts.Client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("collector returned %s", resp.Status)
+	default:
+		return false, fmt.Errorf("collector rejected batch: %s", resp.Status)
+	}
+}
+
+// trimLocked drops the oldest entries beyond MaxBuffer.
+func (s *HTTPSink) trimLocked() {
+	if over := len(s.pending) - s.opts.MaxBuffer; over > 0 {
+		s.pending = s.pending[over:]
+		s.dropped += over
+	}
+}
+
+// SignBatch returns the hex HMAC-SHA256 of body, as sent in SignatureHeader.
+// Collectors use it to authenticate batches.
+func SignBatch(key, body []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,377 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// The index is an optional sidecar file (IndexPath) with one JSON line per
+// log entry: its byte offset and length, hour bucket, action and actor.
+// Loaded, it maps actions, actors and hours to offsets so Search reads only
+// the entries a query can match. It is never authoritative: a missing,
+// stale or corrupt index is rebuilt from the log, and deleting it is always
+// safe.
+
+// indexRef locates one log entry.
+type indexRef struct {
+	Off    int64  `json:"off"`
+	Len    int    `json:"len"`
+	Hour   int64  `json:"hour"` // Unix hour of the entry's timestamp
+	Action string `json:"action"`
+	Actor  string `json:"actor"`
+}
+
+// errIndexCorrupt means the index disagrees with the log it describes.
+var errIndexCorrupt = errors.New("audit index does not match the log")
+
+// IndexPath returns the index file for the log at logPath.
+func IndexPath(logPath string) string {
+	return logPath + ".idx"
+}
+
+// Query selects audit entries. Zero fields match everything; Since and
+// Until bound the timestamp inclusively.
+type Query struct {
+	Action string
+	Actor  string
+	Since  time.Time
+	Until  time.Time
+}
+
+// Match reports whether e satisfies q.
+func (q Query) Match(e Entry) bool {
+	switch {
+	case q.Action != "" && e.Action != q.Action:
+		return false
+	case q.Actor != "" && e.Actor != q.Actor:
+		return false
+	case !q.Since.IsZero() && e.Timestamp.Before(q.Since):
+		return false
+	case !q.Until.IsZero() && e.Timestamp.After(q.Until):
+		return false
+	}
+	return true
+}
+
+// Scan returns the entries of the log at path matching q by reading the
+// whole file.
+func Scan(path string, q Query) ([]Entry, error) {
+	entries, err := ReadAll(path)
+	if err != nil {
+		return nil, err
+	}
+	var out []Entry
+	for _, e := range entries {
+		if q.Match(e) {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+// Search returns the same entries as Scan, in log order, using the index
+// to read only candidate entries. The index is created on first use,
+// extended over entries appended since it was last written, and rebuilt if
+// it no longer matches the log.
+func Search(path string, q Query) ([]Entry, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, nil
+	}
+	ix, err := loadIndex(path)
+	if err != nil {
+		if ix, err = rebuildIndex(path); err != nil {
+			return nil, err
+		}
+	}
+	out, err := ix.search(path, q)
+	if errors.Is(err, errIndexCorrupt) {
+		if ix, err = rebuildIndex(path); err != nil {
+			return nil, err
+		}
+		out, err = ix.search(path, q)
+	}
+	return out, err
+}
+
+// RebuildIndex discards the index of the log at path and rebuilds it.
+func RebuildIndex(path string) error {
+	_, err := rebuildIndex(path)
+	return err
+}
+
+// logIndex is an index loaded into memory.
+type logIndex struct {
+	refs     []indexRef
+	byAction map[string][]int // positions in refs, ascending
+	byActor  map[string][]int
+	end      int64 // log offset just past the last indexed entry
+}
+
+func newLogIndex() *logIndex {
+	return &logIndex{byAction: map[string][]int{}, byActor: map[string][]int{}}
+}
+
+func (ix *logIndex) add(r indexRef) {
+	i := len(ix.refs)
+	ix.refs = append(ix.refs, r)
+	ix.byAction[r.Action] = append(ix.byAction[r.Action], i)
+	ix.byActor[r.Actor] = append(ix.byActor[r.Actor], i)
+	ix.end = r.Off + int64(r.Len)
+}
+
+// loadIndex reads the index of the log at path and indexes any entries
+// appended to the log after it. A missing or unreadable index, or one
+// extending past the end of the log, is an error.
+func loadIndex(path string) (*logIndex, error) {
+	f, err := os.Open(IndexPath(path))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	ix := newLogIndex()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var r indexRef
+		if err := json.Unmarshal(sc.Bytes(), &r); err != nil {
+			return nil, fmt.Errorf("%w: %w", errIndexCorrupt, err)
+		}
+		if r.Off != ix.end {
+			return nil, errIndexCorrupt
+		}
+		ix.add(r)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if ix.end > info.Size() {
+		return nil, errIndexCorrupt // the log was truncated or replaced
+	}
+	if err := ix.spotCheck(path); err != nil {
+		return nil, err
+	}
+	if ix.end < info.Size() {
+		if err := ix.catchUp(path); err != nil {
+			return nil, err
+		}
+	}
+	return ix, nil
+}
+
+// rebuildIndex indexes the log at path from scratch.
+func rebuildIndex(path string) (*logIndex, error) {
+	_ = os.Remove(IndexPath(path))
+	ix := newLogIndex()
+	if err := ix.catchUp(path); err != nil {
+		return nil, err
+	}
+	return ix, nil
+}
+
+// catchUp indexes the log from ix.end to its end and appends the new refs
+// to the index file.
+func (ix *logIndex) catchUp(path string) error {
+	log, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer log.Close()
+	if _, err := log.Seek(ix.end, io.SeekStart); err != nil {
+		return err
+	}
+
+	var added []indexRef
+	r := bufio.NewReader(log)
+	off := ix.end
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(line) > 0 && line[len(line)-1] == '\n' {
+			if ref, ok := refFor(line, off); ok {
+				added = append(added, ref)
+				ix.add(ref)
+			}
+			off += int64(len(line))
+		}
+		if err == io.EOF {
+			break // a partial last line is picked up once it is complete
+		}
+		if err != nil {
+			return fmt.Errorf("failed to index audit log: %w", err)
+		}
+	}
+	ix.end = off
+	return appendIndex(path, added)
+}
+
+// refFor describes the log line at off, including its newline. Blank and
+// unparsable lines are skipped, as ReadAll skips or rejects them.
+func refFor(line []byte, off int64) (indexRef, bool) {
+	var e Entry
+	if len(bytes.TrimSpace(line)) == 0 || json.Unmarshal(line, &e) != nil {
+		return indexRef{}, false
+	}
+	return indexRef{Off: off, Len: len(line), Hour: e.Timestamp.Unix() / 3600, Action: e.Action, Actor: e.Actor}, true
+}
+
+// appendIndex appends refs to the index file of the log at path.
+func appendIndex(path string, refs []indexRef) error {
+	if len(refs) == 0 {
+		if _, err := os.Stat(IndexPath(path)); err == nil {
+			return nil
+		}
+	}
+	f, err := os.OpenFile(IndexPath(path), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit index: %w", err)
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	for _, r := range refs {
+		data, _ := json.Marshal(r)
+		w.Write(append(data, '\n'))
+	}
+	return w.Flush()
+}
+
+// candidates returns the positions of refs that may match q, ascending:
+// the shorter of the action and actor postings, narrowed by the other
+// field and the hour buckets covering Since..Until.
+func (ix *logIndex) candidates(q Query) []int {
+	var list []int
+	switch {
+	case q.Action != "" && q.Actor != "":
+		list = ix.byAction[q.Action]
+		if len(ix.byActor[q.Actor]) < len(list) {
+			list = ix.byActor[q.Actor]
+		}
+	case q.Action != "":
+		list = ix.byAction[q.Action]
+	case q.Actor != "":
+		list = ix.byActor[q.Actor]
+	default:
+		list = make([]int, len(ix.refs))
+		for i := range list {
+			list[i] = i
+		}
+	}
+
+	var out []int
+	for _, i := range list {
+		r := ix.refs[i]
+		switch {
+		case q.Action != "" && r.Action != q.Action:
+		case q.Actor != "" && r.Actor != q.Actor:
+		case !q.Since.IsZero() && r.Hour < q.Since.Unix()/3600:
+		case !q.Until.IsZero() && r.Hour > q.Until.Unix()/3600:
+		default:
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// spotCheck reads the first and last indexed entries back from the log, so
+// a log replaced by one of at least the same size is noticed even by
+// queries whose candidates would all come from elsewhere. Entries edited in
+// place in between are only caught when a query reads them; audit verify is
+// what detects tampering.
+func (ix *logIndex) spotCheck(path string) error {
+	if len(ix.refs) == 0 {
+		return nil
+	}
+	_, err := ix.read(path, []int{0, len(ix.refs) - 1}, Query{})
+	return err
+}
+
+// search reads the candidate entries of q from the log at path.
+func (ix *logIndex) search(path string, q Query) ([]Entry, error) {
+	return ix.read(path, ix.candidates(q), q)
+}
+
+// read returns the entries at positions cands of refs that match q. An
+// entry that does not match its ref means the index is stale:
+// errIndexCorrupt.
+func (ix *logIndex) read(path string, cands []int, q Query) ([]Entry, error) {
+	if len(cands) == 0 {
+		return nil, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	defer f.Close()
+
+	var out []Entry
+	for _, i := range cands {
+		r := ix.refs[i]
+		buf := make([]byte, r.Len)
+		if _, err := f.ReadAt(buf, r.Off); err != nil {
+			return nil, fmt.Errorf("%w: %w", errIndexCorrupt, err)
+		}
+		var e Entry
+		if err := json.Unmarshal(buf, &e); err != nil || e.Action != r.Action || e.Actor != r.Actor {
+			return nil, errIndexCorrupt
+		}
+		if q.Match(e) {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+// openIndexForAppend prepares l to extend the index of the log at path as
+// entries are written. It does nothing unless an index exists: indexing is
+// opt-in through the first Search. An index that cannot be brought level
+// with the log is left for Search to rebuild.
+func (l *Logger) openIndexForAppend(path string) {
+	if _, err := os.Stat(IndexPath(path)); err != nil {
+		return
+	}
+	ix, err := loadIndex(path)
+	if err != nil {
+		return
+	}
+	info, err := l.file.Stat()
+	if err != nil || ix.end != info.Size() {
+		return
+	}
+	f, err := os.OpenFile(IndexPath(path), os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	l.index, l.indexEnd = f, ix.end
+}
+
+// indexEntry records the entry just written as line. Another writer
+// appending to the same log in between leaves a gap; the index then stops
+// being maintained here and Search catches it up instead.
+func (l *Logger) indexEntry(e Entry, line []byte) {
+	if l.index == nil {
+		return
+	}
+	end, err := l.file.Seek(0, io.SeekCurrent)
+	start := end - int64(len(line))
+	if err != nil || start != l.indexEnd {
+		l.index.Close()
+		l.index = nil
+		return
+	}
+	data, _ := json.Marshal(indexRef{Off: start, Len: len(line), Hour: e.Timestamp.Unix() / 3600, Action: e.Action, Actor: e.Actor})
+	if _, err := l.index.Write(append(data, '\n')); err != nil {
+		l.index.Close()
+		l.index = nil
+		return
+	}
+	l.indexEnd = end
+}
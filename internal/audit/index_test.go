@@ -0,0 +1,209 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// writeIndexFixture writes entries spread over several hours, actions and
+// actors, bypassing the Logger so timestamps can be chosen.
+func writeIndexFixture(t *testing.T, path string) time.Time {
+	t.Helper()
+	base := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	actions := []string{"skill.run", "policy.decision", "secret.read"}
+	actors := []string{"alice", "bob"}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	for i := 0; i < 60; i++ {
+		e := Entry{
+			Seq:       uint64(i + 1),
+			Timestamp: base.Add(time.Duration(i) * 10 * time.Minute),
+			Action:    actions[i%len(actions)],
+			Actor:     actors[i%len(actors)],
+			Decision:  "allow",
+		}
+		data, _ := json.Marshal(e)
+		f.Write(append(data, '\n'))
+	}
+	return base
+}
+
+func indexQueries(base time.Time) map[string]Query {
+	return map[string]Query{
+		"all":          {},
+		"action":       {Action: "skill.run"},
+		"actor":        {Actor: "bob"},
+		"action+actor": {Action: "secret.read", Actor: "alice"},
+		"since":        {Since: base.Add(4*time.Hour + 5*time.Minute)},
+		"window":       {Actor: "alice", Since: base.Add(90 * time.Minute), Until: base.Add(3*time.Hour + 20*time.Minute)},
+		"no match":     {Action: "nothing"},
+	}
+}
+
+func assertSearchMatchesScan(t *testing.T, path string, queries map[string]Query) {
+	t.Helper()
+	for name, q := range queries {
+		want, err := Scan(path, q)
+		if err != nil {
+			t.Fatalf("%s: scan: %v", name, err)
+		}
+		got, err := Search(path, q)
+		if err != nil {
+			t.Fatalf("%s: search: %v", name, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: search returned %d entries, scan %d", name, len(got), len(want))
+		}
+	}
+}
+
+func TestSearch_MatchesScan(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	base := writeIndexFixture(t, path)
+
+	assertSearchMatchesScan(t, path, indexQueries(base))
+	if _, err := os.Stat(IndexPath(path)); err != nil {
+		t.Fatalf("search should create the index: %v", err)
+	}
+	// Now answered from the index built by the first pass.
+	assertSearchMatchesScan(t, path, indexQueries(base))
+
+	got, _ := Search(path, Query{Action: "skill.run"})
+	if len(got) != 20 {
+		t.Errorf("skill.run entries = %d, want 20", len(got))
+	}
+}
+
+func TestSearch_RebuildsMissingOrCorruptIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	base := writeIndexFixture(t, path)
+	queries := indexQueries(base)
+	if err := RebuildIndex(path); err != nil {
+		t.Fatal(err)
+	}
+	built, err := os.ReadFile(IndexPath(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Remove(IndexPath(path)); err != nil {
+		t.Fatal(err)
+	}
+	assertSearchMatchesScan(t, path, queries)
+	rebuilt, err := os.ReadFile(IndexPath(path))
+	if err != nil {
+		t.Fatalf("index not rebuilt after deletion: %v", err)
+	}
+	if string(rebuilt) != string(built) {
+		t.Error("rebuilt index differs from the original")
+	}
+
+	corruptions := map[string][]byte{
+		"garbage":   []byte("not json\n"),
+		"truncated": built[:len(built)/2],
+		"shifted":   []byte(`{"off":7,"len":10,"hour":0,"action":"x","actor":"y"}` + "\n"),
+		"past end":  append(append([]byte{}, built...), []byte(`{"off":999999,"len":10,"hour":0,"action":"x","actor":"y"}`+"\n")...),
+	}
+	for name, data := range corruptions {
+		if err := os.WriteFile(IndexPath(path), data, 0600); err != nil {
+			t.Fatal(err)
+		}
+		assertSearchMatchesScan(t, path, queries)
+		if got, _ := os.ReadFile(IndexPath(path)); string(got) != string(built) {
+			t.Errorf("%s: index not rebuilt", name)
+		}
+	}
+}
+
+func TestSearch_StaleIndexWithReplacedLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	writeIndexFixture(t, path)
+	if err := RebuildIndex(path); err != nil {
+		t.Fatal(err)
+	}
+	// Same size, different entries: offsets still line up but their content
+	// does not, which only reading entries back can notice.
+	data, _ := os.ReadFile(path)
+	swapped := bytes.ReplaceAll(data, []byte(`"alice"`), []byte(`"carol"`))
+	if err := os.WriteFile(path, swapped, 0600); err != nil {
+		t.Fatal(err)
+	}
+	assertSearchMatchesScan(t, path, map[string]Query{"carol": {Actor: "carol"}, "alice": {Actor: "alice"}})
+}
+
+func TestLogger_MaintainsIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	logger, err := NewLogger(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Log("skill.run", nil, "allow", "alice", nil)
+	logger.Close()
+
+	// No index until the first search asks for one.
+	if _, err := os.Stat(IndexPath(path)); !os.IsNotExist(err) {
+		t.Fatalf("index created before any search: %v", err)
+	}
+	if _, err := Search(path, Query{}); err != nil {
+		t.Fatal(err)
+	}
+
+	logger, err = NewLogger(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+	for i := 0; i < 5; i++ {
+		logger.Log("skill.run", nil, "allow", "bob", nil)
+		logger.LogKernelEvent("exec", "sh", 42, nil)
+	}
+
+	// The logger extended the index itself: loading it needs no catch-up.
+	ix, err := loadIndex(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, _ := os.Stat(path)
+	if len(ix.refs) != 11 || ix.end != info.Size() {
+		t.Errorf("index has %d refs ending at %d, want 11 ending at %d", len(ix.refs), ix.end, info.Size())
+	}
+	assertSearchMatchesScan(t, path, map[string]Query{
+		"bob":    {Actor: "bob"},
+		"kernel": {Action: "kernel.exec"},
+		"all":    {},
+	})
+}
+
+func TestSearch_CatchesUpAppendedEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	base := writeIndexFixture(t, path)
+	if err := RebuildIndex(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// Appended by a writer that does not maintain the index.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(Entry{Seq: 61, Timestamp: base.Add(12 * time.Hour), Action: "skill.run", Actor: "carol"})
+	f.Write(append(data, '\n'))
+	f.Close()
+
+	got, err := Search(path, Query{Actor: "carol"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Seq != 61 {
+		t.Errorf("appended entry not found: %+v", got)
+	}
+	assertSearchMatchesScan(t, path, indexQueries(base))
+}
@@ -2,35 +2,51 @@
 package audit
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sync"
 	"time"
 
+	"github.com/mackeh/AegisClaw/internal/guardrails"
 	"github.com/mackeh/AegisClaw/internal/scope"
+	"github.com/mackeh/AegisClaw/internal/security/redactor"
 )
 
 // Entry represents a single audit log entry
 type Entry struct {
+	Seq       uint64         `json:"seq,omitempty"` // monotonic position in the log, starting at 1
 	Timestamp time.Time      `json:"timestamp"`
 	Action    string         `json:"action"`
 	Scopes    []string       `json:"scopes"`
 	Decision  string         `json:"decision"`
 	Actor     string         `json:"actor"`
+	Identity  *Actor         `json:"identity,omitempty"` // structured Actor; only set by LogAs
 	Details   map[string]any `json:"details,omitempty"`
 	PrevHash  string         `json:"prev_hash"`
 	Hash      string         `json:"hash"`
 }
 
-// Logger provides append-only, tamper-evident logging
+// Logger provides append-only, tamper-evident logging. Several Loggers,
+// in this process or others, may append to the same file: each append takes
+// a file lock and re-reads the chain head, so entries always link to the
+// true last entry.
 type Logger struct {
 	file     *os.File
 	mu       sync.Mutex
 	lastHash string
+	lastSeq  uint64
+	size     int64 // log size when lastHash/lastSeq were read or written
+	path     string
+	redactor *redactor.Redactor
+	sinks    []Sink
+	index    *os.File // see IndexPath; nil unless an index exists
+	indexEnd int64
 }
 
 // NewLogger creates a new audit logger
@@ -41,27 +57,64 @@ func NewLogger(path string) (*Logger, error) {
 		return nil, fmt.Errorf("failed to create audit directory: %w", err)
 	}
 
-	// Open file in append mode
-	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	// Open file in append mode; reads are only of the chain head.
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0600)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open audit log: %w", err)
 	}
 
 	logger := &Logger{
 		file:     file,
+		path:     path,
 		lastHash: "genesis",
+		// Audit logs are routinely handed to auditors, so credential-shaped
+		// detail values are scrubbed even if no secrets were registered.
+		redactor: redactor.NewWithPatterns(nil, guardrails.SecretPatterns()),
 	}
 
-	// Read last hash from existing log if present
-	if err := logger.loadLastHash(path); err != nil {
-		// Ignore errors - start fresh if can't read
-	}
+	// Read the chain head from an existing log; an unreadable head is
+	// retried (and reported) by the first append.
+	_ = logger.syncHead()
+	logger.openIndexForAppend(path)
 
 	return logger, nil
 }
 
+// SetRedactor replaces the redactor applied to entry details, e.g. with one
+// that also knows the secret values loaded for the current run.
+func (l *Logger) SetRedactor(r *redactor.Redactor) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.redactor = r
+}
+
+// AddSink mirrors every subsequent entry to s after it is written locally.
+func (l *Logger) AddSink(s Sink) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sinks = append(l.sinks, s)
+}
+
+// fanOut forwards an entry to the sinks. Sink errors are deliberately
+// ignored: the local chain has already been persisted.
+func (l *Logger) fanOut(e Entry) {
+	for _, s := range l.sinks {
+		_ = s.Write(e)
+	}
+}
+
 // Log records an action to the audit log
 func (l *Logger) Log(action string, scopes []scope.Scope, decision string, actor string, details map[string]any) error {
+	return l.log(action, scopes, decision, actor, nil, details)
+}
+
+// LogAs records an action attributed to a structured identity: the entry
+// carries actor as Identity and its String form as Actor.
+func (l *Logger) LogAs(action string, scopes []scope.Scope, decision string, actor Actor, details map[string]any) error {
+	return l.log(action, scopes, decision, actor.String(), &actor, details)
+}
+
+func (l *Logger) log(action string, scopes []scope.Scope, decision string, actor string, identity *Actor, details map[string]any) error {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
@@ -71,31 +124,15 @@ func (l *Logger) Log(action string, scopes []scope.Scope, decision string, actor
 		scopeNames[i] = s.String()
 	}
 
-	entry := Entry{
+	return l.append(Entry{
 		Timestamp: time.Now().UTC(),
 		Action:    action,
 		Scopes:    scopeNames,
 		Decision:  decision,
 		Actor:     actor,
-		Details:   details,
-		PrevHash:  l.lastHash,
-	}
-
-	// Compute hash of entry (excluding hash field)
-	entry.Hash = l.computeHash(entry)
-	l.lastHash = entry.Hash
-
-	// Serialize and write
-	data, err := json.Marshal(entry)
-	if err != nil {
-		return fmt.Errorf("failed to marshal entry: %w", err)
-	}
-
-	if _, err := l.file.Write(append(data, '\n')); err != nil {
-		return fmt.Errorf("failed to write entry: %w", err)
-	}
-
-	return l.file.Sync()
+		Identity:  identity,
+		Details:   l.redactDetails(details),
+	})
 }
 
 // LogKernelEvent records a kernel-level event from eBPF monitoring.
@@ -108,44 +145,163 @@ func (l *Logger) LogKernelEvent(evtType string, comm string, pid uint32, details
 		Action:    "kernel." + evtType,
 		Actor:     comm,
 		Decision:  "observed",
-		Details:   details,
-		PrevHash:  l.lastHash,
+		Details:   l.redactDetails(details),
+	}
+	if entry.Details == nil {
+		entry.Details = map[string]any{}
 	}
 	entry.Details["pid"] = pid
 
-	entry.Hash = l.computeHash(entry)
+	return l.append(entry)
+}
+
+// append chains entry onto the log and writes it. The file lock is held
+// from reading the head to the synced write, so a concurrent writer can
+// never leave this entry pointing at a stale hash. Callers hold l.mu.
+func (l *Logger) append(entry Entry) error {
+	if err := lockFile(l.file); err != nil {
+		return fmt.Errorf("failed to lock audit log: %w", err)
+	}
+	defer unlockFile(l.file)
+
+	if err := l.syncHead(); err != nil {
+		return err
+	}
+
+	l.lastSeq++
+	entry.Seq = l.lastSeq
+	entry.PrevHash = l.lastHash
+
+	// Compute hash of entry (excluding hash field)
+	entry.Hash = computeHash(entry)
 	l.lastHash = entry.Hash
 
-	data, _ := json.Marshal(entry)
-	if _, err := l.file.Write(append(data, '\n')); err != nil {
+	// Serialize and write
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal entry: %w", err)
+	}
+
+	line := append(data, '\n')
+	if _, err := l.file.Write(line); err != nil {
+		return fmt.Errorf("failed to write entry: %w", err)
+	}
+	if err := l.file.Sync(); err != nil {
 		return err
 	}
-	return l.file.Sync()
+	l.size += int64(len(line))
+	l.writeHead(entry)
+	l.indexEntry(entry, line)
+
+	l.fanOut(entry)
+	return nil
+}
+
+// syncHead reloads lastHash and lastSeq from the log's last entry if the
+// file changed since this Logger last wrote or read it.
+func (l *Logger) syncHead() error {
+	info, err := l.file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat audit log: %w", err)
+	}
+	if info.Size() == l.size {
+		return nil
+	}
+	last, ok, err := lastEntry(l.file, info.Size())
+	if err != nil {
+		return fmt.Errorf("failed to read audit log head: %w", err)
+	}
+	l.lastHash, l.lastSeq = "genesis", 0
+	if ok {
+		l.lastHash, l.lastSeq = last.Hash, last.Seq
+	}
+	l.size = info.Size()
+	return nil
 }
 
-// Close closes the audit log file
+// Close closes the audit log file and any sinks, flushing buffered ones.
 func (l *Logger) Close() error {
 	l.mu.Lock()
 	defer l.mu.Unlock()
+	for _, s := range l.sinks {
+		_ = s.Close()
+	}
+	l.sinks = nil
+	if l.index != nil {
+		l.index.Close()
+		l.index = nil
+	}
 	return l.file.Close()
 }
 
-func (l *Logger) computeHash(entry Entry) string {
+// redactDetails returns a copy of details with secrets scrubbed from every
+// string value, including strings nested in slices and maps. It runs before
+// hashing so the chain covers exactly what is persisted.
+func (l *Logger) redactDetails(details map[string]any) map[string]any {
+	if details == nil || l.redactor == nil {
+		return details
+	}
+	out := make(map[string]any, len(details))
+	for k, v := range details {
+		out[k] = l.redactValue(v)
+	}
+	return out
+}
+
+func (l *Logger) redactValue(v any) any {
+	switch val := v.(type) {
+	case string:
+		return l.redactor.Redact(val)
+	case []string:
+		out := make([]string, len(val))
+		for i, s := range val {
+			out[i] = l.redactor.Redact(s)
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, e := range val {
+			out[i] = l.redactValue(e)
+		}
+		return out
+	case map[string]any:
+		return l.redactDetails(val)
+	case map[string]string:
+		out := make(map[string]string, len(val))
+		for k, s := range val {
+			out[k] = l.redactor.Redact(s)
+		}
+		return out
+	case error:
+		return l.redactor.Redact(val.Error())
+	case fmt.Stringer:
+		return l.redactor.Redact(val.String())
+	default:
+		return v
+	}
+}
+
+// computeHash is the chain hash of entry: SHA-256 over every field but Hash.
+func computeHash(entry Entry) string {
 	// Create a copy without the hash field for hashing
 	hashInput := struct {
+		Seq       uint64         `json:"seq,omitempty"`
 		Timestamp time.Time      `json:"timestamp"`
 		Action    string         `json:"action"`
 		Scopes    []string       `json:"scopes"`
 		Decision  string         `json:"decision"`
 		Actor     string         `json:"actor"`
+		Identity  *Actor         `json:"identity,omitempty"`
 		Details   map[string]any `json:"details,omitempty"`
 		PrevHash  string         `json:"prev_hash"`
 	}{
+		Seq:       entry.Seq,
 		Timestamp: entry.Timestamp,
 		Action:    entry.Action,
 		Scopes:    entry.Scopes,
 		Decision:  entry.Decision,
 		Actor:     entry.Actor,
+		Identity:  entry.Identity,
 		Details:   entry.Details,
 		PrevHash:  entry.PrevHash,
 	}
@@ -155,25 +311,39 @@ func (l *Logger) computeHash(entry Entry) string {
 	return hex.EncodeToString(hash[:])
 }
 
-func (l *Logger) loadLastHash(path string) error {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return err
-	}
+// lastEntry parses the last non-empty line of the size bytes in r, reading
+// backwards so the cost does not grow with the log. ok is false for an
+// empty log.
+func lastEntry(r io.ReaderAt, size int64) (Entry, bool, error) {
+	const chunk = 64 << 10
+	var buf []byte
+	for off := size; off > 0; {
+		n := min(int64(chunk), off)
+		off -= n
+		b := make([]byte, n)
+		if _, err := r.ReadAt(b, off); err != nil && err != io.EOF {
+			return Entry{}, false, err
+		}
+		buf = append(b, buf...)
 
-	// Find last non-empty line
-	lines := splitLines(data)
-	for i := len(lines) - 1; i >= 0; i-- {
-		if len(lines[i]) > 0 {
-			var entry Entry
-			if err := json.Unmarshal(lines[i], &entry); err == nil {
-				l.lastHash = entry.Hash
-				return nil
+		tail := bytes.TrimRight(buf, "\n")
+		i := bytes.LastIndexByte(tail, '\n')
+		if i < 0 && off > 0 {
+			continue // the last line starts in an earlier chunk
+		}
+		if len(tail) == 0 {
+			if off > 0 {
+				continue
 			}
+			return Entry{}, false, nil
+		}
+		var e Entry
+		if err := json.Unmarshal(tail[i+1:], &e); err != nil {
+			return Entry{}, false, err
 		}
+		return e, true, nil
 	}
-
-	return nil
+	return Entry{}, false, nil
 }
 
 func splitLines(data []byte) [][]byte {
@@ -216,7 +386,10 @@ func ReadAll(path string) ([]Entry, error) {
 	return entries, nil
 }
 
-// Verify checks the integrity of the audit log
+// Verify checks the integrity of the audit log: sequence numbers are
+// contiguous, every entry links to its predecessor, every hash matches the
+// entry's content, and the log still reaches the head recorded at the last
+// append (see HeadPath), so trailing entries cannot be cut off unnoticed.
 func Verify(path string) (bool, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -228,6 +401,7 @@ func Verify(path string) (bool, error) {
 
 	lines := splitLines(data)
 	prevHash := "genesis"
+	var prevSeq uint64
 
 	for i, line := range lines {
 		if len(line) == 0 {
@@ -239,18 +413,32 @@ func Verify(path string) (bool, error) {
 			return false, fmt.Errorf("failed to parse entry %d: %w", i, err)
 		}
 
+		// Verify sequence. Entries written before sequence numbers existed
+		// carry Seq 0 and are exempt; once numbering starts it must be
+		// contiguous, so a deleted entry shows up as a gap.
+		if entry.Seq != 0 {
+			if prevSeq != 0 && entry.Seq != prevSeq+1 {
+				return false, fmt.Errorf("sequence gap at entry %d: expected seq %d, got %d (entries may have been deleted)", i, prevSeq+1, entry.Seq)
+			}
+			prevSeq = entry.Seq
+		}
+
 		// Verify chain
 		if entry.PrevHash != prevHash {
 			return false, fmt.Errorf("chain broken at entry %d (timestamp: %s)", i, entry.Timestamp)
 		}
 
-		// Verify hash (recompute)
-		// Note: computeHash is a method on Logger, but we can't use it easily here without an instance.
-		// Detailed verification would need to replicate the hashing logic. 
-		// For MVP, checking the chain links is a good first step.
-		
+		// Verify hash (recompute), so an edited entry is caught even if
+		// its stored hash was left alone.
+		if got := computeHash(entry); got != entry.Hash {
+			return false, fmt.Errorf("hash mismatch at entry %d (timestamp: %s): entry was modified", i, entry.Timestamp)
+		}
+
 		prevHash = entry.Hash
 	}
 
+	if err := checkHead(path, prevSeq, prevHash); err != nil {
+		return false, err
+	}
 	return true, nil
 }
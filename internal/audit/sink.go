@@ -0,0 +1,106 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/mackeh/AegisClaw/internal/config"
+)
+
+// Sink receives every entry after it has been hashed into the local chain.
+// Sinks are best-effort mirrors: a failing sink never blocks or fails the
+// local write, which remains the source of truth for Verify.
+type Sink interface {
+	Write(Entry) error
+	Close() error
+}
+
+// NewSink builds a sink from its config block.
+func NewSink(c config.AuditSinkConfig) (Sink, error) {
+	switch c.Type {
+	case "file":
+		return NewFileSink(c.Path)
+	case "syslog":
+		return NewSyslogSink(c.Network, c.Address)
+	case "http":
+		var key []byte
+		if c.HMACKeyEnv != "" {
+			v := os.Getenv(c.HMACKeyEnv)
+			if v == "" {
+				return nil, fmt.Errorf("audit http sink: %s is not set", c.HMACKeyEnv)
+			}
+			key = []byte(v)
+		}
+		return NewHTTPSink(HTTPSinkOptions{
+			URL:           c.URL,
+			HMACKey:       key,
+			BatchSize:     c.BatchSize,
+			FlushInterval: c.FlushInterval,
+		})
+	default:
+		return nil, fmt.Errorf("unknown audit sink type %q (supported: file, syslog, http)", c.Type)
+	}
+}
+
+// NewLoggerWithSinks opens the local log at path and attaches the configured
+// sinks. A sink that cannot be built is an error so misconfiguration is not
+// silently ignored.
+func NewLoggerWithSinks(path string, sinks []config.AuditSinkConfig) (*Logger, error) {
+	l, err := NewLogger(path)
+	if err != nil {
+		return nil, err
+	}
+	for i, c := range sinks {
+		s, err := NewSink(c)
+		if err != nil {
+			l.Close()
+			return nil, fmt.Errorf("audit.sinks[%d]: %w", i, err)
+		}
+		l.AddSink(s)
+	}
+	return l, nil
+}
+
+// FileSink appends entries as JSON lines to a file, e.g. a mirror on a
+// separate volume.
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileSink opens (or creates) path for appending.
+func NewFileSink(path string) (*FileSink, error) {
+	if path == "" {
+		return nil, fmt.Errorf("audit file sink: path is required")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create audit sink directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit sink file: %w", err)
+	}
+	return &FileSink{file: f}, nil
+}
+
+// Write appends one entry.
+func (s *FileSink) Write(e Entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.file.Write(append(data, '\n'))
+	return err
+}
+
+// Close closes the file.
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
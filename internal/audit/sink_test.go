@@ -0,0 +1,254 @@
+package audit
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mackeh/AegisClaw/internal/config"
+)
+
+func sampleEntry(seq uint64, decision string) Entry {
+	return Entry{
+		Seq:       seq,
+		Timestamp: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		Action:    "skill.exec",
+		Decision:  decision,
+		Actor:     `weird "actor"]`,
+		PrevHash:  "genesis",
+		Hash:      "abc123",
+	}
+}
+
+func TestFormatRFC5424(t *testing.T) {
+	msg := FormatRFC5424(sampleEntry(7, "allow"), "host-1")
+
+	header := regexp.MustCompile(`^<134>1 2026-03-01T12:00:00Z host-1 aegisclaw \d+ skill\.exec \[aegisclaw@32473 `)
+	if !header.MatchString(msg) {
+		t.Fatalf("unexpected header: %s", msg)
+	}
+	if !strings.Contains(msg, `seq="7"`) || !strings.Contains(msg, `hash="abc123"`) {
+		t.Errorf("structured data missing chain fields: %s", msg)
+	}
+	if !strings.Contains(msg, `actor="weird \"actor\"\]"`) {
+		t.Errorf("structured data not escaped: %s", msg)
+	}
+
+	body := msg[strings.Index(msg, "] {")+2:]
+	var e Entry
+	if err := json.Unmarshal([]byte(body), &e); err != nil || e.Seq != 7 {
+		t.Errorf("message body is not the JSON entry: %q (%v)", body, err)
+	}
+
+	if deny := FormatRFC5424(sampleEntry(1, "deny"), "h"); !strings.HasPrefix(deny, "<132>1 ") {
+		t.Errorf("deny should be logged at warning severity: %s", deny[:8])
+	}
+	if nohost := FormatRFC5424(sampleEntry(1, "allow"), ""); !strings.Contains(nohost, "Z - aegisclaw") {
+		t.Errorf("empty hostname should be NILVALUE: %s", nohost)
+	}
+}
+
+func TestSyslogSink_UDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("udp unavailable: %v", err)
+	}
+	defer pc.Close()
+
+	s, err := NewSyslogSink("udp", pc.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if err := s.Write(sampleEntry(1, "allow")); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 4096)
+	_ = pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("no datagram received: %v", err)
+	}
+	if !strings.HasPrefix(string(buf[:n]), "<134>1 ") {
+		t.Errorf("unexpected datagram: %s", buf[:n])
+	}
+}
+
+type collector struct {
+	mu       sync.Mutex
+	batches  [][]Entry
+	sigs     []string
+	failures int32 // respond 503 this many times first
+	calls    int32
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	atomic.AddInt32(&c.calls, 1)
+	if atomic.AddInt32(&c.failures, -1) >= 0 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	var batch []Entry
+	_ = json.Unmarshal(body, &batch)
+	c.mu.Lock()
+	c.batches = append(c.batches, batch)
+	c.sigs = append(c.sigs, r.Header.Get(SignatureHeader)+"|"+SignBatch([]byte("k3y"), body))
+	c.mu.Unlock()
+}
+
+func TestHTTPSink_Batching(t *testing.T) {
+	col := &collector{}
+	srv := httptest.NewServer(col)
+	defer srv.Close()
+
+	s, err := NewHTTPSink(HTTPSinkOptions{URL: srv.URL, HMACKey: []byte("k3y"), BatchSize: 2, FlushInterval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 5; i++ {
+		_ = s.Write(sampleEntry(uint64(i), "allow"))
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	col.mu.Lock()
+	defer col.mu.Unlock()
+	var seqs []uint64
+	for _, b := range col.batches {
+		if len(b) > 2 {
+			t.Errorf("batch of %d exceeds BatchSize 2", len(b))
+		}
+		for _, e := range b {
+			seqs = append(seqs, e.Seq)
+		}
+	}
+	if len(seqs) != 5 {
+		t.Fatalf("delivered %v, want 5 entries", seqs)
+	}
+	for i, s := range seqs {
+		if s != uint64(i+1) {
+			t.Errorf("entries out of order: %v", seqs)
+			break
+		}
+	}
+	for _, sig := range col.sigs {
+		parts := strings.SplitN(sig, "|", 2)
+		if parts[0] != "sha256="+parts[1] {
+			t.Errorf("bad HMAC signature header %q", parts[0])
+		}
+	}
+}
+
+func TestHTTPSink_RetriesThenDelivers(t *testing.T) {
+	col := &collector{failures: 2}
+	srv := httptest.NewServer(col)
+	defer srv.Close()
+
+	s, err := NewHTTPSink(HTTPSinkOptions{URL: srv.URL, BatchSize: 10, FlushInterval: time.Hour, RetryBackoff: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	_ = s.Write(sampleEntry(1, "allow"))
+	if err := s.Flush(); err != nil {
+		t.Fatalf("Flush should succeed after retries: %v", err)
+	}
+	if got := atomic.LoadInt32(&col.calls); got != 3 {
+		t.Errorf("collector called %d times, want 3 (2 failures + success)", got)
+	}
+	if s.Pending() != 0 {
+		t.Errorf("pending = %d after successful flush", s.Pending())
+	}
+}
+
+func TestHTTPSink_KeepsEntriesWhileCollectorDown(t *testing.T) {
+	col := &collector{failures: 1000}
+	srv := httptest.NewServer(col)
+	defer srv.Close()
+
+	s, err := NewHTTPSink(HTTPSinkOptions{URL: srv.URL, BatchSize: 10, FlushInterval: time.Hour, MaxRetries: 1, RetryBackoff: time.Millisecond, MaxBuffer: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 4; i++ {
+		_ = s.Write(sampleEntry(uint64(i), "allow"))
+	}
+	if err := s.Flush(); err == nil {
+		t.Error("expected flush error while collector is down")
+	}
+	if s.Pending() != 3 {
+		t.Errorf("pending = %d, want 3 (bounded by MaxBuffer)", s.Pending())
+	}
+
+	atomic.StoreInt32(&col.failures, 0)
+	if err := s.Flush(); err != nil {
+		t.Fatalf("flush after recovery: %v", err)
+	}
+	col.mu.Lock()
+	defer col.mu.Unlock()
+	if len(col.batches) != 1 || col.batches[0][0].Seq != 2 {
+		t.Errorf("expected the 3 newest entries redelivered, got %+v", col.batches)
+	}
+}
+
+type recordingSink struct {
+	entries []Entry
+	closed  bool
+}
+
+func (r *recordingSink) Write(e Entry) error { r.entries = append(r.entries, e); return nil }
+func (r *recordingSink) Close() error        { r.closed = true; return nil }
+
+func TestLogger_FansOutToSinks(t *testing.T) {
+	dir := t.TempDir()
+	logger, err := NewLogger(filepath.Join(dir, "audit.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := &recordingSink{}
+	logger.AddSink(rec)
+
+	_ = logger.Log("a", nil, "allow", "me", nil)
+	_ = logger.Log("b", nil, "deny", "me", nil)
+	logger.Close()
+
+	if len(rec.entries) != 2 || rec.entries[1].PrevHash != rec.entries[0].Hash {
+		t.Errorf("sink should receive chained entries, got %+v", rec.entries)
+	}
+	if !rec.closed {
+		t.Error("Logger.Close should close sinks")
+	}
+}
+
+func TestNewLoggerWithSinks_FileMirror(t *testing.T) {
+	dir := t.TempDir()
+	mirror := filepath.Join(dir, "mirror", "audit.log")
+	logger, err := NewLoggerWithSinks(filepath.Join(dir, "audit.log"), []config.AuditSinkConfig{{Type: "file", Path: mirror}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = logger.Log("a", nil, "allow", "me", nil)
+	logger.Close()
+
+	entries, err := ReadAll(mirror)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("mirror entries = %v, err = %v", entries, err)
+	}
+
+	if _, err := NewLoggerWithSinks(filepath.Join(dir, "x.log"), []config.AuditSinkConfig{{Type: "kafka"}}); err == nil {
+		t.Error("expected error for unknown sink type")
+	}
+}
@@ -0,0 +1,156 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// syslogEnterpriseID scopes AegisClaw's structured-data element. 32473 is
+// the IANA example PEN reserved for documentation and private use.
+const syslogEnterpriseID = "32473"
+
+// Syslog facility local0, and the severities entries map onto.
+const (
+	syslogFacility    = 16
+	syslogSevWarning  = 4
+	syslogSevNotice   = 5
+	syslogSevInfo     = 6
+	syslogAppName     = "aegisclaw"
+	syslogMaxMsgIDLen = 32
+)
+
+// SyslogSink ships entries to a syslog collector in RFC 5424 format.
+type SyslogSink struct {
+	mu       sync.Mutex
+	network  string
+	address  string
+	conn     net.Conn
+	hostname string
+}
+
+// NewSyslogSink dials the collector. network defaults to "udp".
+func NewSyslogSink(network, address string) (*SyslogSink, error) {
+	if address == "" {
+		return nil, fmt.Errorf("audit syslog sink: address is required")
+	}
+	if network == "" {
+		network = "udp"
+	}
+	host, _ := os.Hostname()
+	s := &SyslogSink{network: network, address: address, hostname: host}
+	if err := s.dial(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *SyslogSink) dial() error {
+	conn, err := net.DialTimeout(s.network, s.address, 5*time.Second)
+	if err != nil {
+		return fmt.Errorf("failed to connect to syslog %s://%s: %w", s.network, s.address, err)
+	}
+	s.conn = conn
+	return nil
+}
+
+// Write sends one entry, redialing once if the connection dropped.
+func (s *SyslogSink) Write(e Entry) error {
+	msg := FormatRFC5424(e, s.hostname)
+	if s.network != "udp" && s.network != "unixgram" {
+		// Stream transports use octet-counting framing (RFC 6587).
+		msg = fmt.Sprintf("%d %s", len(msg), msg)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		if err := s.dial(); err != nil {
+			return err
+		}
+	}
+	if _, err := s.conn.Write([]byte(msg)); err != nil {
+		s.conn.Close()
+		s.conn = nil
+		if err := s.dial(); err != nil {
+			return err
+		}
+		_, err = s.conn.Write([]byte(msg))
+		return err
+	}
+	return nil
+}
+
+// Close closes the connection.
+func (s *SyslogSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// FormatRFC5424 renders an entry as an RFC 5424 syslog message:
+//
+//	<PRI>1 TIMESTAMP HOSTNAME aegisclaw PROCID MSGID [aegisclaw@32473 ...] JSON
+//
+// Denied actions are logged at warning severity, everything else at info.
+// The structured data carries the chain fields so a collector can
+// cross-check the local log; the message body is the full JSON entry.
+func FormatRFC5424(e Entry, hostname string) string {
+	sev := syslogSevInfo
+	switch e.Decision {
+	case "deny", "block", "blocked":
+		sev = syslogSevWarning
+	case "warn", "require_approval":
+		sev = syslogSevNotice
+	}
+	pri := syslogFacility*8 + sev
+
+	body, _ := json.Marshal(e)
+	sd := fmt.Sprintf(`[%s@%s seq="%d" decision="%s" actor="%s" hash="%s" prev_hash="%s"]`,
+		syslogAppName, syslogEnterpriseID, e.Seq,
+		sdEscape(e.Decision), sdEscape(e.Actor), sdEscape(e.Hash), sdEscape(e.PrevHash))
+
+	return fmt.Sprintf("<%d>1 %s %s %s %d %s %s %s",
+		pri,
+		e.Timestamp.UTC().Format(time.RFC3339Nano),
+		headerField(hostname, 255),
+		syslogAppName,
+		os.Getpid(),
+		headerField(e.Action, syslogMaxMsgIDLen),
+		sd,
+		body,
+	)
+}
+
+// headerField makes a value safe for an RFC 5424 header: printable ASCII
+// without spaces, truncated to max, and "-" (NILVALUE) when empty.
+func headerField(v string, max int) string {
+	v = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return '_'
+		}
+		return r
+	}, v)
+	if len(v) > max {
+		v = v[:max]
+	}
+	if v == "" {
+		return "-"
+	}
+	return v
+}
+
+// sdEscape escapes '"', '\' and ']' in a structured-data parameter value.
+func sdEscape(v string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+	return r.Replace(v)
+}
@@ -10,6 +10,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/mackeh/AegisClaw/internal/audit"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
@@ -35,11 +36,14 @@ type NodeInfo struct {
 }
 
 // AuditEvent is an audit entry forwarded from a follower to the leader.
+// Actor is who the entry is attributed to on the follower; events without
+// one are attributed to the forwarding node itself.
 type AuditEvent struct {
 	NodeID    string         `json:"node_id"`
 	Timestamp time.Time      `json:"timestamp"`
 	Action    string         `json:"action"`
 	Decision  string         `json:"decision"`
+	Actor     audit.Actor    `json:"actor"`
 	Details   map[string]any `json:"details,omitempty"`
 }
 
@@ -60,6 +64,11 @@ type Node struct {
 	leader   string // address of the leader node
 	events   chan AuditEvent
 	policies chan PolicyUpdate
+	auditLog string // local audit log answered for in VerifyAudit
+
+	skillsDir string                  // where distributed skills are installed
+	trustKeys []string                // keys distributed skills must be signed by
+	installs  map[string]SkillInstall // latest outcome per node/skill, on the leader
 }
 
 // NewNode creates a new cluster node.
@@ -76,6 +85,7 @@ func NewNode(id, address string, role NodeRole, version string) *Node {
 		peers:    make(map[string]*NodeInfo),
 		events:   make(chan AuditEvent, 1024),
 		policies: make(chan PolicyUpdate, 16),
+		installs: make(map[string]SkillInstall),
 	}
 }
 
@@ -113,6 +123,13 @@ func (n *Node) RemovePeer(id string) {
 
 // ForwardAudit sends an audit event to the leader for aggregation.
 func (n *Node) ForwardAudit(evt AuditEvent) {
+	id := n.Info().ID
+	if evt.NodeID == "" {
+		evt.NodeID = id
+	}
+	if evt.Actor.Kind == "" {
+		evt.Actor = audit.NodeActor(id)
+	}
 	select {
 	case n.events <- evt:
 	default:
@@ -120,23 +137,36 @@ func (n *Node) ForwardAudit(evt AuditEvent) {
 	}
 }
 
+// SetAuditLog sets the local audit log this node verifies for the fleet.
+func (n *Node) SetAuditLog(path string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.auditLog = path
+}
+
 // StartServer starts the gRPC server for inter-node communication.
 func (n *Node) StartServer(ctx context.Context) error {
 	lis, err := net.Listen("tcp", n.info.Address)
 	if err != nil {
 		return fmt.Errorf("listen: %w", err)
 	}
+	return n.Serve(ctx, lis)
+}
 
-	n.server = grpc.NewServer()
-	// TODO: Register gRPC service implementations
-	// pb.RegisterClusterServiceServer(n.server, &clusterServiceImpl{node: n})
+// Serve answers cluster RPCs on lis until ctx is done.
+func (n *Node) Serve(ctx context.Context, lis net.Listener) error {
+	server := grpc.NewServer(grpc.ForceServerCodec(jsonCodec{}))
+	server.RegisterService(&serviceDesc, n)
+	n.mu.Lock()
+	n.server = server
+	n.mu.Unlock()
 
 	go func() {
 		<-ctx.Done()
-		n.server.GracefulStop()
+		server.GracefulStop()
 	}()
 
-	return n.server.Serve(lis)
+	return server.Serve(lis)
 }
 
 // Stop gracefully shuts down the node.
@@ -176,6 +206,9 @@ type ClusterStatus struct {
 	Leader      string     `json:"leader"`
 	OnlineNodes int        `json:"online_nodes"`
 	Nodes       []NodeInfo `json:"nodes"`
+	// SkillInstalls is each follower's latest outcome per distributed
+	// skill, as recorded by DistributeSkill on the leader.
+	SkillInstalls []SkillInstall `json:"skill_installs,omitempty"`
 }
 
 // Status returns the current cluster status from this node's perspective.
@@ -197,6 +230,7 @@ func (n *Node) Status() ClusterStatus {
 		}
 	}
 	status.OnlineNodes = online
+	status.SkillInstalls = n.skillInstalls()
 	return status
 }
 
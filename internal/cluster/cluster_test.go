@@ -2,6 +2,8 @@ package cluster
 
 import (
 	"testing"
+
+	"github.com/mackeh/AegisClaw/internal/audit"
 )
 
 func TestNewNode(t *testing.T) {
@@ -84,6 +86,16 @@ func TestNode_ForwardAudit(t *testing.T) {
 	if evt.Action != "test" {
 		t.Errorf("expected action 'test', got '%s'", evt.Action)
 	}
+	if evt.Actor != audit.NodeActor("node-1") {
+		t.Errorf("expected the event attributed to node-1, got %+v", evt.Actor)
+	}
+
+	// An actor set on the follower is kept.
+	n.ForwardAudit(AuditEvent{Action: "skill.exec", Actor: audit.SkillActor("echoer")})
+	<-n.events
+	if evt := <-n.events; evt.Actor != audit.SkillActor("echoer") || evt.NodeID != "node-1" {
+		t.Errorf("forwarded event = %+v", evt)
+	}
 }
 
 func TestNode_Stop(t *testing.T) {
@@ -0,0 +1,135 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mackeh/AegisClaw/internal/skill"
+)
+
+// SkillBundle is a signed skill pushed from the leader to followers. The
+// manifest travels as the raw skill.yaml so followers verify exactly the
+// bytes the publisher signed.
+type SkillBundle struct {
+	Name     string `json:"name"`
+	Version  string `json:"version"`
+	Manifest []byte `json:"manifest"`
+}
+
+// SkillInstall is one node's outcome of installing a distributed skill.
+type SkillInstall struct {
+	NodeID      string    `json:"node_id"`
+	Skill       string    `json:"skill"`
+	Version     string    `json:"version,omitempty"`
+	Installed   bool      `json:"installed"`
+	Error       string    `json:"error,omitempty"` // why the install was refused or the node was unreachable
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+// LoadSkillBundle reads the installed skill name from skillsDir, ready to
+// distribute.
+func LoadSkillBundle(skillsDir, name string) (SkillBundle, error) {
+	path := filepath.Join(skillsDir, name, "skill.yaml")
+	m, err := skill.LoadManifest(path)
+	if err != nil {
+		return SkillBundle{}, fmt.Errorf("load skill %s: %w", name, err)
+	}
+	if m.Signature == "" {
+		return SkillBundle{}, fmt.Errorf("skill %s is unsigned; followers only install signed skills", name)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return SkillBundle{}, fmt.Errorf("read skill %s: %w", name, err)
+	}
+	return SkillBundle{Name: m.Name, Version: m.Version, Manifest: data}, nil
+}
+
+// SetSkillStore sets where this node installs distributed skills and the
+// keys their signatures must verify against. A node without a store
+// refuses every bundle.
+func (n *Node) SetSkillStore(dir string, trustKeys []string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.skillsDir = dir
+	n.trustKeys = append([]string(nil), trustKeys...)
+}
+
+// DistributeSkill pushes bundle to every peer in parallel and records each
+// node's outcome for Status (Leader only). An unreachable peer counts as
+// not installed.
+func (n *Node) DistributeSkill(ctx context.Context, bundle SkillBundle) ([]SkillInstall, error) {
+	if !n.IsLeader() {
+		return nil, fmt.Errorf("only the leader distributes skills")
+	}
+	peers := n.Peers()
+	results := make([]SkillInstall, len(peers))
+
+	var wg sync.WaitGroup
+	for i, p := range peers {
+		wg.Add(1)
+		go func(i int, p NodeInfo) {
+			defer wg.Done()
+			res, err := DistributeSkillToPeer(ctx, p.Address, bundle)
+			if err != nil {
+				res = SkillInstall{Skill: bundle.Name, Version: bundle.Version, Error: err.Error(), CompletedAt: time.Now()}
+			}
+			if res.NodeID == "" {
+				res.NodeID = p.ID
+			}
+			results[i] = res
+		}(i, p)
+	}
+	wg.Wait()
+
+	sort.SliceStable(results, func(i, j int) bool { return results[i].NodeID < results[j].NodeID })
+	n.mu.Lock()
+	for _, res := range results {
+		n.installs[res.NodeID+"/"+res.Skill] = res
+	}
+	n.mu.Unlock()
+	return results, nil
+}
+
+// skillInstalls returns the recorded install outcomes sorted by node, then
+// skill. The caller holds n.mu.
+func (n *Node) skillInstalls() []SkillInstall {
+	var out []SkillInstall
+	for _, res := range n.installs {
+		out = append(out, res)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].NodeID != out[j].NodeID {
+			return out[i].NodeID < out[j].NodeID
+		}
+		return out[i].Skill < out[j].Skill
+	})
+	return out
+}
+
+// installSkill verifies bundle against this node's trust keys and installs
+// it into the skill store.
+func (n *Node) installSkill(bundle SkillBundle) (res SkillInstall) {
+	n.mu.RLock()
+	id, dir, keys := n.info.ID, n.skillsDir, n.trustKeys
+	n.mu.RUnlock()
+
+	res = SkillInstall{NodeID: id, Skill: bundle.Name, Version: bundle.Version}
+	defer func() { res.CompletedAt = time.Now() }()
+	if dir == "" {
+		res.Error = "no skill store configured on this node"
+		return res
+	}
+	// The signed manifest, not the bundle's labels, says what was installed.
+	m, err := skill.InstallSignedManifest(bundle.Manifest, dir, keys, skill.InstallOptions{})
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.Skill, res.Version, res.Installed = m.Name, m.Version, true
+	return res
+}
@@ -0,0 +1,146 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mackeh/AegisClaw/internal/skill"
+)
+
+// writeSignedSkill writes a minimal manifest under dir, signs it with a
+// fresh key and returns the key's hex public half.
+func writeSignedSkill(t *testing.T, dir, name string) string {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name, "skill.yaml")
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	data := "name: " + name + "\nversion: 1.2.0\nimage: alpine:3.20\n"
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	m, err := skill.LoadManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	canonical, _ := json.Marshal(m)
+	data += "signature: " + hex.EncodeToString(ed25519.Sign(priv, canonical)) + "\n"
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return hex.EncodeToString(pub)
+}
+
+func TestNode_DistributeSkill(t *testing.T) {
+	leaderSkills := t.TempDir()
+	key := writeSignedSkill(t, leaderSkills, "echoer")
+	bundle, err := LoadSkillBundle(leaderSkills, "echoer")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	leader := NewNode("leader", "", RoleLeader, "test")
+	followerSkills := t.TempDir()
+	follower := NewNode("worker-1", "", RoleFollower, "test")
+	follower.SetSkillStore(followerSkills, []string{key})
+	untrusting := NewNode("worker-2", "", RoleFollower, "test")
+	untrusting.SetSkillStore(t.TempDir(), nil)
+	leader.RegisterPeer(NodeInfo{ID: "worker-1", Address: serveNode(t, follower)})
+	leader.RegisterPeer(NodeInfo{ID: "worker-2", Address: serveNode(t, untrusting)})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	results, err := leader.DistributeSkill(ctx, bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %+v", results)
+	}
+	if r := results[0]; r.NodeID != "worker-1" || !r.Installed || r.Version != "1.2.0" {
+		t.Errorf("worker-1: %+v, want installed v1.2.0", r)
+	}
+	if r := results[1]; r.NodeID != "worker-2" || r.Installed || !strings.Contains(r.Error, "signature") {
+		t.Errorf("worker-2: %+v, want refused for lack of a trusted key", r)
+	}
+
+	installed, err := os.ReadFile(filepath.Join(followerSkills, "echoer", "skill.yaml"))
+	if err != nil {
+		t.Fatalf("skill not installed on follower: %v", err)
+	}
+	m, err := skill.LoadManifest(filepath.Join(followerSkills, "echoer", "skill.yaml"))
+	if err != nil || m.Signature == "" {
+		t.Fatalf("installed manifest = %s (%v)", installed, err)
+	}
+	if ok, _ := m.VerifySignature([]string{key}); !ok {
+		t.Error("installed manifest no longer verifies")
+	}
+
+	status := leader.Status()
+	if len(status.SkillInstalls) != 2 || !status.SkillInstalls[0].Installed || status.SkillInstalls[1].Installed {
+		t.Errorf("status skill installs = %+v", status.SkillInstalls)
+	}
+}
+
+func TestNode_DistributeSkill_RejectsTamperedBundle(t *testing.T) {
+	leaderSkills := t.TempDir()
+	key := writeSignedSkill(t, leaderSkills, "echoer")
+	bundle, err := LoadSkillBundle(leaderSkills, "echoer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle.Manifest = bytes.Replace(bundle.Manifest, []byte("alpine:3.20"), []byte("evil/backdoor:latest"), 1)
+
+	leader := NewNode("leader", "", RoleLeader, "test")
+	followerSkills := t.TempDir()
+	follower := NewNode("worker-1", "", RoleFollower, "test")
+	follower.SetSkillStore(followerSkills, []string{key})
+	leader.RegisterPeer(NodeInfo{ID: "worker-1", Address: serveNode(t, follower)})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	results, err := leader.DistributeSkill(ctx, bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Installed || !strings.Contains(results[0].Error, "signature verification failed") {
+		t.Fatalf("results = %+v, want the tampered bundle refused", results)
+	}
+	if _, err := os.Stat(filepath.Join(followerSkills, "echoer", "skill.yaml")); !os.IsNotExist(err) {
+		t.Errorf("tampered skill was written on the follower (stat err %v)", err)
+	}
+}
+
+func TestNode_DistributeSkill_LeaderOnly(t *testing.T) {
+	follower := NewNode("worker-1", "", RoleFollower, "test")
+	if _, err := follower.DistributeSkill(context.Background(), SkillBundle{Name: "echoer"}); err == nil {
+		t.Error("expected a follower to refuse to distribute skills")
+	}
+}
+
+func TestLoadSkillBundle_RefusesUnsigned(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "plain"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	data := []byte("name: plain\nversion: 1.0.0\nimage: alpine:3.20\n")
+	if err := os.WriteFile(filepath.Join(dir, "plain", "skill.yaml"), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadSkillBundle(dir, "plain"); err == nil || !strings.Contains(err.Error(), "unsigned") {
+		t.Errorf("err = %v, want an unsigned skill refused", err)
+	}
+}
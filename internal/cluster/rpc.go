@@ -0,0 +1,101 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// The cluster service has no generated protobuf stubs; its messages are
+// the JSON-tagged structs of this package, carried by jsonCodec.
+const (
+	serviceName           = "aegisclaw.cluster.Cluster"
+	verifyAuditMethod     = "/" + serviceName + "/VerifyAudit"
+	distributeSkillMethod = "/" + serviceName + "/DistributeSkill"
+)
+
+// jsonCodec marshals cluster RPC messages as JSON.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+func (jsonCodec) Name() string                       { return "json" }
+
+// verifyAuditRequest is empty: a node always verifies its own log.
+type verifyAuditRequest struct{}
+
+// clusterService is implemented by *Node.
+type clusterService interface {
+	verifyLocalAudit() AuditVerification
+	installSkill(bundle SkillBundle) SkillInstall
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*clusterService)(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "VerifyAudit",
+		Handler: func(srv any, ctx context.Context, dec func(any) error, _ grpc.UnaryServerInterceptor) (any, error) {
+			var req verifyAuditRequest
+			if err := dec(&req); err != nil {
+				return nil, err
+			}
+			return srv.(clusterService).verifyLocalAudit(), nil
+		},
+	}, {
+		MethodName: "DistributeSkill",
+		Handler: func(srv any, ctx context.Context, dec func(any) error, _ grpc.UnaryServerInterceptor) (any, error) {
+			var bundle SkillBundle
+			if err := dec(&bundle); err != nil {
+				return nil, err
+			}
+			return srv.(clusterService).installSkill(bundle), nil
+		},
+	}},
+}
+
+// VerifyPeerAudit asks the node at addr to verify its local audit log.
+func VerifyPeerAudit(ctx context.Context, addr string) (AuditVerification, error) {
+	conn, err := dialPeer(addr)
+	if err != nil {
+		return AuditVerification{}, err
+	}
+	defer conn.Close()
+
+	var v AuditVerification
+	if err := conn.Invoke(ctx, verifyAuditMethod, &verifyAuditRequest{}, &v); err != nil {
+		return AuditVerification{}, fmt.Errorf("verify audit on %s: %w", addr, err)
+	}
+	return v, nil
+}
+
+// DistributeSkillToPeer pushes bundle to the node at addr, which verifies
+// and installs it.
+func DistributeSkillToPeer(ctx context.Context, addr string, bundle SkillBundle) (SkillInstall, error) {
+	conn, err := dialPeer(addr)
+	if err != nil {
+		return SkillInstall{}, err
+	}
+	defer conn.Close()
+
+	var res SkillInstall
+	if err := conn.Invoke(ctx, distributeSkillMethod, &bundle, &res); err != nil {
+		return SkillInstall{}, fmt.Errorf("distribute skill to %s: %w", addr, err)
+	}
+	return res, nil
+}
+
+// dialPeer opens a client connection speaking jsonCodec to the node at addr.
+func dialPeer(addr string) (*grpc.ClientConn, error) {
+	conn, err := grpc.NewClient(addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(jsonCodec{})),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("connect to %s: %w", addr, err)
+	}
+	return conn, nil
+}
@@ -0,0 +1,108 @@
+package cluster
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/mackeh/AegisClaw/internal/audit"
+)
+
+// AuditVerification is one node's report on its local audit log.
+type AuditVerification struct {
+	NodeID  string `json:"node_id"`
+	Address string `json:"address,omitempty"`
+	Valid   bool   `json:"valid"`
+	Entries int    `json:"entries"`
+	Head    string `json:"head,omitempty"`  // hash of the last entry, or "genesis"
+	Error   string `json:"error,omitempty"` // why the chain is broken or the node was unreachable
+}
+
+// FleetAudit aggregates the audit verification of every node.
+type FleetAudit struct {
+	Nodes  []AuditVerification `json:"nodes"`
+	Broken []string            `json:"broken,omitempty"` // IDs of nodes not verified intact
+}
+
+// Intact reports whether every node's chain verified.
+func (f FleetAudit) Intact() bool {
+	return len(f.Broken) == 0
+}
+
+// VerifyAuditLog runs audit.Verify on the log at path and reports the
+// chain head, the same head an anchor would record.
+func VerifyAuditLog(nodeID, path string) AuditVerification {
+	v := AuditVerification{NodeID: nodeID}
+	if _, err := audit.Verify(path); err != nil {
+		v.Error = err.Error()
+		return v
+	}
+	entries, err := audit.ReadAll(path)
+	if err != nil {
+		v.Error = err.Error()
+		return v
+	}
+	v.Valid = true
+	v.Entries = len(entries)
+	v.Head = "genesis"
+	if len(entries) > 0 {
+		v.Head = entries[len(entries)-1].Hash
+	}
+	return v
+}
+
+// AggregateAuditVerifications sorts results by node and collects the nodes
+// whose chain is broken or that could not be asked.
+func AggregateAuditVerifications(results []AuditVerification) FleetAudit {
+	nodes := append([]AuditVerification(nil), results...)
+	sort.SliceStable(nodes, func(i, j int) bool { return nodes[i].NodeID < nodes[j].NodeID })
+	f := FleetAudit{Nodes: nodes}
+	for _, v := range nodes {
+		if !v.Valid {
+			f.Broken = append(f.Broken, v.NodeID)
+		}
+	}
+	return f
+}
+
+// VerifyFleetAudit verifies this node's log and asks every peer, in
+// parallel, to verify its own. An unreachable peer counts as broken: its
+// integrity cannot be asserted.
+func (n *Node) VerifyFleetAudit(ctx context.Context) FleetAudit {
+	self := n.Info()
+	peers := n.Peers()
+
+	results := make([]AuditVerification, len(peers)+1)
+	results[0] = n.verifyLocalAudit()
+	results[0].Address = self.Address
+
+	var wg sync.WaitGroup
+	for i, p := range peers {
+		wg.Add(1)
+		go func(i int, p NodeInfo) {
+			defer wg.Done()
+			v, err := VerifyPeerAudit(ctx, p.Address)
+			if err != nil {
+				v = AuditVerification{Error: err.Error()}
+			}
+			if v.NodeID == "" {
+				v.NodeID = p.ID
+			}
+			v.Address = p.Address
+			results[i+1] = v
+		}(i, p)
+	}
+	wg.Wait()
+	return AggregateAuditVerifications(results)
+}
+
+// verifyLocalAudit verifies the log set with SetAuditLog.
+func (n *Node) verifyLocalAudit() AuditVerification {
+	n.mu.RLock()
+	id, path := n.info.ID, n.auditLog
+	n.mu.RUnlock()
+	if path == "" {
+		return AuditVerification{NodeID: id, Error: "no audit log configured on this node"}
+	}
+	return VerifyAuditLog(id, path)
+}
@@ -0,0 +1,144 @@
+package cluster
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mackeh/AegisClaw/internal/audit"
+)
+
+// writeAuditLog writes n chained entries and returns the log path.
+func writeAuditLog(t *testing.T, n int) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "audit.log")
+	logger, err := audit.NewLogger(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < n; i++ {
+		if err := logger.Log("skill.exec", nil, "allow", "test", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	logger.Close()
+	return path
+}
+
+// tamper rewrites the first entry's prev_hash so the chain breaks.
+func tamper(t *testing.T, path string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = []byte(strings.Replace(string(data), `"prev_hash":"genesis"`, `"prev_hash":"forged"`, 1))
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyAuditLog(t *testing.T) {
+	path := writeAuditLog(t, 3)
+	v := VerifyAuditLog("node-1", path)
+	if !v.Valid || v.Entries != 3 || v.Head == "" || v.Head == "genesis" {
+		t.Fatalf("intact log: got %+v", v)
+	}
+
+	tamper(t, path)
+	v = VerifyAuditLog("node-1", path)
+	if v.Valid || v.Error == "" {
+		t.Errorf("tampered log: got %+v, want a broken chain", v)
+	}
+
+	v = VerifyAuditLog("node-1", filepath.Join(t.TempDir(), "missing.log"))
+	if !v.Valid || v.Head != "genesis" {
+		t.Errorf("empty log: got %+v, want valid at genesis", v)
+	}
+}
+
+func TestAggregateAuditVerifications(t *testing.T) {
+	f := AggregateAuditVerifications([]AuditVerification{
+		{NodeID: "node-3", Valid: true, Head: "c"},
+		{NodeID: "node-2", Valid: false, Error: "chain broken at entry 4"},
+		{NodeID: "node-1", Valid: true, Head: "a"},
+	})
+	if f.Intact() {
+		t.Error("fleet with a broken node reported intact")
+	}
+	if len(f.Broken) != 1 || f.Broken[0] != "node-2" {
+		t.Errorf("broken = %v, want [node-2]", f.Broken)
+	}
+	if f.Nodes[0].NodeID != "node-1" || f.Nodes[2].NodeID != "node-3" {
+		t.Errorf("nodes not sorted by ID: %+v", f.Nodes)
+	}
+
+	if !AggregateAuditVerifications([]AuditVerification{{NodeID: "node-1", Valid: true}}).Intact() {
+		t.Error("all-valid fleet reported broken")
+	}
+}
+
+// serveNode starts n's cluster RPC server on a loopback port.
+func serveNode(t *testing.T, n *Node) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	go n.Serve(ctx, lis)
+	t.Cleanup(cancel)
+	return lis.Addr().String()
+}
+
+func TestNode_VerifyFleetAudit(t *testing.T) {
+	leader := NewNode("leader", "127.0.0.1:0", RoleLeader, "test")
+	leader.SetAuditLog(writeAuditLog(t, 2))
+
+	good := NewNode("worker-1", "", RoleFollower, "test")
+	good.SetAuditLog(writeAuditLog(t, 5))
+	bad := NewNode("worker-2", "", RoleFollower, "test")
+	badLog := writeAuditLog(t, 3)
+	tamper(t, badLog)
+	bad.SetAuditLog(badLog)
+
+	leader.RegisterPeer(NodeInfo{ID: "worker-1", Address: serveNode(t, good)})
+	leader.RegisterPeer(NodeInfo{ID: "worker-2", Address: serveNode(t, bad)})
+
+	// A closed port: the peer cannot be asked, so it cannot count as intact.
+	lis, _ := net.Listen("tcp", "127.0.0.1:0")
+	gone := lis.Addr().String()
+	lis.Close()
+	leader.RegisterPeer(NodeInfo{ID: "worker-3", Address: gone})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	f := leader.VerifyFleetAudit(ctx)
+
+	if len(f.Nodes) != 4 {
+		t.Fatalf("expected 4 node results, got %+v", f.Nodes)
+	}
+	byID := map[string]AuditVerification{}
+	for _, v := range f.Nodes {
+		byID[v.NodeID] = v
+	}
+	if v := byID["leader"]; !v.Valid || v.Entries != 2 {
+		t.Errorf("leader: %+v", v)
+	}
+	if v := byID["worker-1"]; !v.Valid || v.Entries != 5 || v.Head == "" {
+		t.Errorf("worker-1: %+v", v)
+	}
+	if v := byID["worker-2"]; v.Valid || !strings.Contains(v.Error, "chain broken") {
+		t.Errorf("worker-2: %+v, want a broken chain", v)
+	}
+	if v := byID["worker-3"]; v.Valid || v.Error == "" {
+		t.Errorf("worker-3: %+v, want unreachable", v)
+	}
+	if strings.Join(f.Broken, ",") != "worker-2,worker-3" {
+		t.Errorf("broken = %v, want [worker-2 worker-3]", f.Broken)
+	}
+}
@@ -0,0 +1,85 @@
+// Package completion resolves where shell completion scripts are installed.
+package completion
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Shells lists the shells completion scripts can be generated for.
+var Shells = []string{"bash", "zsh", "fish", "powershell"}
+
+// Target is where a shell's completion script goes and what the user has
+// to do afterwards for the shell to pick it up.
+type Target struct {
+	Path string
+	Hint string
+}
+
+// DetectShell names the shell in shellEnv (usually $SHELL), e.g. "zsh" for
+// "/usr/bin/zsh".
+func DetectShell(shellEnv string) (string, error) {
+	name := strings.TrimSuffix(filepath.Base(shellEnv), ".exe")
+	switch name {
+	case "bash", "zsh", "fish":
+		return name, nil
+	case "pwsh", "powershell":
+		return "powershell", nil
+	}
+	if shellEnv == "" {
+		return "", fmt.Errorf("cannot detect your shell ($SHELL is not set); pass one of %s", strings.Join(Shells, ", "))
+	}
+	return "", fmt.Errorf("unsupported shell %q; pass one of %s", name, strings.Join(Shells, ", "))
+}
+
+// TargetFor returns the conventional per-user location for shell's
+// completion script under home. getenv supplies XDG_DATA_HOME,
+// XDG_CONFIG_HOME and ZDOTDIR when set.
+func TargetFor(shell, home string, getenv func(string) string) (Target, error) {
+	dataHome := getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	configHome := getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		configHome = filepath.Join(home, ".config")
+	}
+
+	switch shell {
+	case "bash":
+		// Loaded on demand by bash-completion 2.x.
+		return Target{
+			Path: filepath.Join(dataHome, "bash-completion", "completions", "aegisclaw"),
+			Hint: "Start a new shell to load it (requires the bash-completion package).",
+		}, nil
+	case "zsh":
+		zdot := getenv("ZDOTDIR")
+		if zdot == "" {
+			zdot = home
+		}
+		dir := filepath.Join(zdot, ".zfunc")
+		return Target{
+			Path: filepath.Join(dir, "_aegisclaw"),
+			Hint: fmt.Sprintf("Add this to your .zshrc before compinit, then start a new shell:\n  fpath=(%s $fpath)\n  autoload -U compinit && compinit", dir),
+		}, nil
+	case "fish":
+		return Target{
+			Path: filepath.Join(configHome, "fish", "completions", "aegisclaw.fish"),
+			Hint: "Start a new fish session to load it.",
+		}, nil
+	case "powershell":
+		dir := filepath.Join(configHome, "powershell")
+		if runtime.GOOS == "windows" {
+			dir = filepath.Join(home, "Documents", "PowerShell")
+		}
+		path := filepath.Join(dir, "aegisclaw-completion.ps1")
+		return Target{
+			Path: path,
+			Hint: fmt.Sprintf("Add this line to your $PROFILE, then start a new session:\n  . '%s'", path),
+		}, nil
+	default:
+		return Target{}, fmt.Errorf("unsupported shell %q; pass one of %s", shell, strings.Join(Shells, ", "))
+	}
+}
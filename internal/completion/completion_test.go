@@ -0,0 +1,71 @@
+package completion
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTargetFor(t *testing.T) {
+	home := t.TempDir()
+	noEnv := func(string) string { return "" }
+
+	tests := []struct {
+		shell string
+		want  string
+	}{
+		{"bash", filepath.Join(home, ".local", "share", "bash-completion", "completions", "aegisclaw")},
+		{"zsh", filepath.Join(home, ".zfunc", "_aegisclaw")},
+		{"fish", filepath.Join(home, ".config", "fish", "completions", "aegisclaw.fish")},
+		{"powershell", filepath.Join(home, ".config", "powershell", "aegisclaw-completion.ps1")},
+	}
+	for _, tt := range tests {
+		got, err := TargetFor(tt.shell, home, noEnv)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.shell, err)
+		}
+		if got.Path != tt.want {
+			t.Errorf("%s: path = %s, want %s", tt.shell, got.Path, tt.want)
+		}
+		if got.Hint == "" {
+			t.Errorf("%s: no follow-up hint", tt.shell)
+		}
+	}
+
+	if _, err := TargetFor("tcsh", home, noEnv); err == nil {
+		t.Error("unknown shell should fail")
+	}
+}
+
+func TestTargetFor_XDGOverrides(t *testing.T) {
+	home := t.TempDir()
+	env := map[string]string{
+		"XDG_DATA_HOME":   filepath.Join(home, "data"),
+		"XDG_CONFIG_HOME": filepath.Join(home, "cfg"),
+		"ZDOTDIR":         filepath.Join(home, "zsh"),
+	}
+	getenv := func(k string) string { return env[k] }
+
+	for shell, prefix := range map[string]string{"bash": env["XDG_DATA_HOME"], "fish": env["XDG_CONFIG_HOME"], "zsh": env["ZDOTDIR"]} {
+		got, err := TargetFor(shell, home, getenv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(got.Path, prefix+string(filepath.Separator)) {
+			t.Errorf("%s: path %s not under %s", shell, got.Path, prefix)
+		}
+	}
+}
+
+func TestDetectShell(t *testing.T) {
+	for in, want := range map[string]string{"/bin/bash": "bash", "/usr/bin/zsh": "zsh", "/usr/local/bin/fish": "fish", "/usr/bin/pwsh": "powershell"} {
+		if got, err := DetectShell(in); err != nil || got != want {
+			t.Errorf("DetectShell(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "/bin/tcsh"} {
+		if _, err := DetectShell(in); err == nil {
+			t.Errorf("DetectShell(%q) should fail", in)
+		}
+	}
+}
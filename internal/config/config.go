@@ -2,9 +2,13 @@
 package config
 
 import (
+	"encoding/hex"
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -18,6 +22,20 @@ type Config struct {
 	Registry   RegistryConfig   `yaml:"registry"`
 	Telemetry  TelemetryConfig  `yaml:"telemetry"`
 	Guardrails GuardrailsConfig `yaml:"guardrails"`
+	Audit      AuditConfig      `yaml:"audit,omitempty"`
+	Policy     PolicyConfig     `yaml:"policy,omitempty"`
+	Server     ServerConfig     `yaml:"server,omitempty"`
+	XRay       XRayConfig       `yaml:"xray,omitempty"`
+	MCP        MCPConfig        `yaml:"mcp,omitempty"`
+	// Skills declares the skills that should be installed; `aegisclaw
+	// skills sync` reconciles the skills directory against it.
+	Skills []SkillSpec `yaml:"skills,omitempty"`
+
+	// Profiles holds named overlays (e.g. dev, staging, prod) merged over
+	// the base settings when selected via --profile or AEGISCLAW_PROFILE.
+	Profiles map[string]yaml.Node `yaml:"profiles,omitempty"`
+	// Profile is the name of the profile applied by Load, if any.
+	Profile string `yaml:"-"`
 }
 
 // GuardrailsConfig controls how the agent reacts to prompt-injection guardrail
@@ -25,24 +43,177 @@ type Config struct {
 // "block"; an empty value is treated as "warn".
 type GuardrailsConfig struct {
 	Mode string `yaml:"mode"`
+	// Packs enables injection patterns for languages besides English, by
+	// built-in name (es, fr, de) or path to a YAML pack file.
+	Packs []string `yaml:"packs,omitempty"`
+}
+
+// PolicyConfig tunes policy evaluation. UnknownScope is "approve" (default)
+// or "deny" and decides scope names AegisClaw does not recognise.
+type PolicyConfig struct {
+	UnknownScope string `yaml:"unknown_scope,omitempty"`
+	// Rules restrict when scopes the Rego policy allows may run.
+	Rules []PolicyRule `yaml:"rules,omitempty"`
+	// Overrides decide scopes for named skills ahead of the Rego policy.
+	Overrides []SkillOverride `yaml:"overrides,omitempty"`
+}
+
+// SkillOverride decides scopes for one skill before the global policy, e.g.
+//
+//	skill: backup
+//	signer: 3b6a27bc...   # only if the manifest is signed by this key
+//	allow: [files]        # covers files.read and files.write
+//	deny: [http.request]
+//
+// All overrides matching a skill are merged; the most specific scope
+// pattern wins and, on a tie, deny beats require_approval beats allow.
+// Scopes they do not cover fall through to the global policy, and
+// secrets.write and critical capabilities still always need approval.
+type SkillOverride struct {
+	Skill string `yaml:"skill"`
+	// Signer is a hex Ed25519 public key. When set, the override applies
+	// only to a manifest whose signature verifies against it.
+	Signer          string   `yaml:"signer,omitempty"`
+	Allow           []string `yaml:"allow,omitempty"`
+	RequireApproval []string `yaml:"require_approval,omitempty"`
+	Deny            []string `yaml:"deny,omitempty"`
+}
+
+// PolicyRule limits one scope, or a family such as "files" (covering
+// files.read and files.write), to a time window, e.g.
+//
+//	scope: shell.exec
+//	constraints: {hours: "09:00-17:00", days: "Mon-Fri", tz: "Europe/London"}
+//	outside: deny
+type PolicyRule struct {
+	Scope       string            `yaml:"scope"`
+	Constraints PolicyConstraints `yaml:"constraints"`
+	// Outside is "require_approval" (default) or "deny".
+	Outside string `yaml:"outside,omitempty"`
+}
+
+// PolicyConstraints is the time window of a PolicyRule.
+type PolicyConstraints struct {
+	Hours string `yaml:"hours,omitempty"` // "HH:MM-HH:MM"; may wrap midnight
+	Days  string `yaml:"days,omitempty"`  // e.g. "Mon-Fri" or "Sat,Sun"
+	TZ    string `yaml:"tz,omitempty"`    // IANA zone; default local time
+}
+
+// ServerConfig contains dashboard API settings.
+type ServerConfig struct {
+	CORS CORSConfig `yaml:"cors,omitempty"`
+}
+
+// CORSConfig controls cross-origin access to the dashboard API. With no
+// AllowedOrigins, browsers on other origins get no CORS headers and cannot
+// read responses.
+type CORSConfig struct {
+	AllowedOrigins []string `yaml:"allowed_origins,omitempty"` // e.g. "https://dash.corp"; "*" allows any
+	AllowedMethods []string `yaml:"allowed_methods,omitempty"` // default GET, POST
+	AllowedHeaders []string `yaml:"allowed_headers,omitempty"` // default Authorization, Content-Type, X-API-Key
+}
+
+// XRayConfig sets the resource thresholds "xray watch" and the dashboard
+// server alert on. Zero limits are off.
+type XRayConfig struct {
+	CPUPercent    float64       `yaml:"cpu_percent,omitempty"`
+	MemoryPercent float64       `yaml:"memory_percent,omitempty"`
+	PIDs          uint64        `yaml:"pids,omitempty"`
+	Sustain       time.Duration `yaml:"sustain,omitempty"`  // how long a limit must be exceeded (default 30s)
+	Interval      time.Duration `yaml:"interval,omitempty"` // sampling interval (default 5s)
+}
+
+// MCPConfig gates the tools "aegisclaw mcp-server" exposes to assistants.
+// Read-only tools are always callable; Capabilities enables the gated
+// kinds, "write" (changes AegisClaw state) and "exec" (runs skills or
+// commands). Both are off by default.
+type MCPConfig struct {
+	Capabilities []string `yaml:"capabilities,omitempty"`
 }
 
 // TelemetryConfig contains observability settings
 type TelemetryConfig struct {
 	Enabled  bool   `yaml:"enabled"`
 	Exporter string `yaml:"exporter"` // e.g., "stdout", "otlp", "none"
+	// Endpoint is the collector the otlp exporter sends to, e.g.
+	// "otel-collector:4317". Required for, and only used by, otlp.
+	Endpoint string `yaml:"endpoint,omitempty"`
+	// SampleRatio is the fraction of traces recorded, between 0 and 1;
+	// unset records every trace.
+	SampleRatio float64 `yaml:"sample_ratio,omitempty"`
+	// MaxLabelValues caps the distinct values of open-ended metric labels
+	// (skill, domain); further values are counted as "other". Unset means
+	// 100.
+	MaxLabelValues int `yaml:"max_label_values,omitempty"`
 }
 
 // RegistryConfig contains skill registry settings
 type RegistryConfig struct {
 	URL       string   `yaml:"url"`
 	TrustKeys []string `yaml:"trust_keys"` // Public keys of trusted signers
+	// AllowedHosts lists extra hosts (e.g. a CDN) that registry entries may
+	// point manifest and bundle URLs at, besides the registry's own host.
+	AllowedHosts []string `yaml:"allowed_hosts,omitempty"`
+	// Badge is the marketplace trust level (verified, signed, or community)
+	// given to entries from URL. Default community.
+	Badge string `yaml:"badge,omitempty"`
+	// Sources lists further registries merged into the marketplace index
+	// after URL, e.g. a private registry beside the official one.
+	Sources []RegistrySource `yaml:"sources,omitempty"`
+	// AuthSecret names a secret holding credentials for URL, sent on index
+	// and manifest requests to that host. AuthType is "bearer" (default;
+	// the secret is the token) or "basic" (the secret is "user:password").
+	AuthSecret string `yaml:"auth_secret,omitempty"`
+	AuthType   string `yaml:"auth_type,omitempty"`
+}
+
+// SkillSpec declares one skill in the skills list.
+type SkillSpec struct {
+	Name string `yaml:"name"`
+	// Version pins an exact release; empty follows the registry's latest.
+	Version string `yaml:"version,omitempty"`
+	// Registry is the name of a registry.sources entry or a registry URL;
+	// empty uses registry.url.
+	Registry string `yaml:"registry,omitempty"`
+}
+
+// SkillRegistryURL resolves the registry a declared skill is installed from.
+func (c *Config) SkillRegistryURL(s SkillSpec) (string, error) {
+	r := strings.TrimSpace(s.Registry)
+	if r == "" {
+		if c.Registry.URL == "" {
+			return "", fmt.Errorf("skill %s names no registry and registry.url is not set", s.Name)
+		}
+		return c.Registry.URL, nil
+	}
+	for _, src := range c.Registry.Sources {
+		if src.Name != "" && src.Name == r {
+			return src.URL, nil
+		}
+	}
+	if strings.HasPrefix(r, "https://") || strings.HasPrefix(r, "http://") {
+		return r, nil
+	}
+	return "", fmt.Errorf("skill %s: registry %q is neither a registry.sources name nor a URL", s.Name, s.Registry)
+}
+
+// RegistrySource is an additional marketplace registry.
+type RegistrySource struct {
+	Name  string `yaml:"name,omitempty"`
+	URL   string `yaml:"url"`
+	Badge string `yaml:"badge,omitempty"` // as RegistryConfig.Badge
 }
 
 // AgentConfig contains agent-specific settings
 type AgentConfig struct {
 	Name    string `yaml:"name"`
 	Enabled bool   `yaml:"enabled"`
+	// MaxConcurrentRuns caps simultaneous skill executions in this process;
+	// zero means unlimited.
+	MaxConcurrentRuns int `yaml:"max_concurrent_runs,omitempty"`
+	// OnCapacity decides what a run does when the cap is reached: "queue"
+	// (default) waits for a free slot, "reject" fails immediately.
+	OnCapacity string `yaml:"on_capacity,omitempty"`
 }
 
 // SecurityConfig contains security-related settings
@@ -51,6 +222,76 @@ type SecurityConfig struct {
 	SandboxRuntime  string `yaml:"sandbox_runtime"` // e.g. "runsc"
 	RequireApproval bool   `yaml:"require_approval"`
 	AuditEnabled    bool   `yaml:"audit_enabled"`
+	// AuditRequired refuses to run a skill whose audit entry cannot be
+	// written. Off, a failing audit log only produces a warning.
+	AuditRequired bool `yaml:"audit_required,omitempty"`
+	// RequireUsernsRemap refuses skill execution unless the Docker daemon
+	// has userns-remap enabled, so container UIDs never map to real host UIDs.
+	RequireUsernsRemap bool `yaml:"require_userns_remap,omitempty"`
+	// SandboxUser is the numeric "UID:GID" skills run as unless their
+	// manifest sets user; empty means 1000:1000.
+	SandboxUser string `yaml:"sandbox_user,omitempty"`
+	// AllowRootUser permits skills to run as UID or GID 0. Even then each
+	// such run needs approval; without it they are refused outright.
+	AllowRootUser bool `yaml:"allow_root_user,omitempty"`
+	// AllowedRegistries restricts skill images to these registry hosts,
+	// e.g. "ghcr.io" or "docker.io". Empty allows any registry.
+	AllowedRegistries []string `yaml:"allowed_registries,omitempty"`
+	// ImagePullPolicy is "missing" (default: pull absent images) or "never",
+	// for air-gapped hosts where any registry request would hang.
+	ImagePullPolicy string `yaml:"image_pull_policy,omitempty"`
+	// RetainFailedContainers keeps the container of a failed skill run for
+	// post-mortem `docker logs`/`docker inspect` instead of removing it.
+	// Kept containers are removed once RetainFor (default 24h) has passed
+	// since they stopped, when the next skill run cleans up.
+	RetainFailedContainers bool          `yaml:"retain_failed_containers,omitempty"`
+	RetainFor              time.Duration `yaml:"retain_for,omitempty"`
+	// EBPF controls kernel-level monitoring of skill runs. Off by default:
+	// the probes trace host-wide and add overhead.
+	EBPF EBPFConfig `yaml:"ebpf,omitempty"`
+}
+
+// EBPFConfig selects whether and how eBPF monitoring runs.
+type EBPFConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Probes to attach: syscalls, files, network, process. Empty attaches
+	// syscalls, files, and network.
+	Probes []string `yaml:"probes,omitempty"`
+}
+
+// AuditConfig controls where audit entries are shipped in addition to the
+// local hash-chained log, which is always written.
+type AuditConfig struct {
+	Sinks []AuditSinkConfig `yaml:"sinks,omitempty"`
+	// Anchor records the chain head off-host so a rewritten log is
+	// detectable; see 'aegisclaw logs verify --anchors'.
+	Anchor AuditAnchorConfig `yaml:"anchor,omitempty"`
+}
+
+// AuditAnchorConfig says where chain-head anchors go and how often the
+// dashboard server takes one. Anchors are also taken on every lockdown and
+// by 'aegisclaw logs anchor'.
+type AuditAnchorConfig struct {
+	File     string        `yaml:"file,omitempty"`     // append anchors here, ideally on separate media
+	Webhook  string        `yaml:"webhook,omitempty"`  // POST each anchor as JSON
+	Interval time.Duration `yaml:"interval,omitempty"` // 0 disables periodic anchors
+}
+
+// AuditSinkConfig describes one audit sink.
+type AuditSinkConfig struct {
+	Type string `yaml:"type"` // "file", "syslog", or "http"
+
+	Path string `yaml:"path,omitempty"` // file: mirror log path
+
+	Network string `yaml:"network,omitempty"` // syslog: "udp" (default), "tcp", or "unix"
+	Address string `yaml:"address,omitempty"` // syslog: e.g. "logs.corp:514"
+
+	URL string `yaml:"url,omitempty"` // http: collector endpoint
+	// HMACKeyEnv names the environment variable holding the key used to
+	// sign HTTP batches, so the key never sits in config.yaml.
+	HMACKeyEnv    string        `yaml:"hmac_key_env,omitempty"`
+	BatchSize     int           `yaml:"batch_size,omitempty"`     // http: entries per push (default 50)
+	FlushInterval time.Duration `yaml:"flush_interval,omitempty"` // http: max delay before a push (default 5s)
 }
 
 // NetworkConfig contains network isolation settings
@@ -61,6 +302,25 @@ type NetworkConfig struct {
 	// loopback, and link-local addresses. Default false (SSRF protection on).
 	// Cloud instance-metadata endpoints stay blocked regardless.
 	AllowPrivateEgress bool `yaml:"allow_private_egress"`
+	// BlockedDomains and BlockedCIDRs are refused by the egress proxy even
+	// when the allowlist would admit them, e.g. known-malicious domains in a
+	// default-allow setup. The cloud metadata endpoints are always blocked.
+	BlockedDomains []string `yaml:"blocked_domains,omitempty"`
+	BlockedCIDRs   []string `yaml:"blocked_cidrs,omitempty"`
+	// UpstreamProxy is a parent proxy (e.g. a corporate proxy) that allowed
+	// egress is forwarded through. Empty falls back to HTTPS_PROXY/HTTP_PROXY.
+	UpstreamProxy string `yaml:"upstream_proxy,omitempty"`
+	// NoProxy lists hosts, domains, or CIDRs reached directly rather than via
+	// UpstreamProxy. Empty falls back to NO_PROXY.
+	NoProxy []string `yaml:"no_proxy,omitempty"`
+	// DLP makes the egress proxy block plaintext requests whose URL or body
+	// matches a credential pattern, not only registered secret values.
+	DLP bool `yaml:"dlp,omitempty"`
+	// DNS lists resolvers for sandbox containers with unproxied network
+	// access. Proxied containers never resolve names themselves.
+	DNS []string `yaml:"dns,omitempty"`
+	// IPv6 keeps IPv6 enabled in networked sandbox containers (default off).
+	IPv6 bool `yaml:"ipv6,omitempty"`
 }
 
 // DefaultConfigDir returns the default configuration directory path
@@ -72,7 +332,10 @@ func DefaultConfigDir() (string, error) {
 	return filepath.Join(home, ".aegisclaw"), nil
 }
 
-// Load reads the configuration from the specified path
+// Load reads the configuration from the specified path, applying the
+// active profile (see ActiveProfile) if one is selected and expanding
+// ${ENV_VAR} references in string values. The result must pass Validate;
+// Warnings are left for doctor to report.
 func Load(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -84,6 +347,16 @@ func Load(path string) (*Config, error) {
 		return nil, fmt.Errorf("failed to parse config: %w", err)
 	}
 
+	if err := applyProfile(&cfg, path, ActiveProfile()); err != nil {
+		return nil, err
+	}
+	if err := expandEnv(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to expand config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
 	return &cfg, nil
 }
 
@@ -96,6 +369,195 @@ func LoadDefault() (*Config, error) {
 	return Load(filepath.Join(dir, "config.yaml"))
 }
 
+// Validate reports semantic errors that YAML decoding alone does not catch.
+func (c *Config) Validate() error {
+	switch strings.ToLower(strings.TrimSpace(c.Guardrails.Mode)) {
+	case "", "off", "warn", "block":
+	default:
+		return fmt.Errorf("invalid guardrails.mode %q (want off, warn, or block)", c.Guardrails.Mode)
+	}
+	if c.Agent.MaxConcurrentRuns < 0 {
+		return fmt.Errorf("invalid agent.max_concurrent_runs %d (want 0 for unlimited, or more)", c.Agent.MaxConcurrentRuns)
+	}
+	switch strings.ToLower(strings.TrimSpace(c.Agent.OnCapacity)) {
+	case "", "queue", "reject":
+	default:
+		return fmt.Errorf("invalid agent.on_capacity %q (want queue or reject)", c.Agent.OnCapacity)
+	}
+	switch c.Security.ImagePullPolicy {
+	case "", "missing", "never":
+	default:
+		return fmt.Errorf("invalid security.image_pull_policy %q (want missing or never)", c.Security.ImagePullPolicy)
+	}
+	if u := strings.TrimSpace(c.Security.SandboxUser); u != "" {
+		uid, gid, hasGID := strings.Cut(u, ":")
+		if !isNumericID(uid) || (hasGID && !isNumericID(gid)) {
+			return fmt.Errorf("invalid security.sandbox_user %q (want a numeric UID:GID, e.g. 1000:1000)", c.Security.SandboxUser)
+		}
+		if (uid == "0" || gid == "0") && !c.Security.AllowRootUser {
+			return fmt.Errorf("security.sandbox_user %q is root; set security.allow_root_user to permit it", c.Security.SandboxUser)
+		}
+	}
+	switch strings.ToLower(strings.TrimSpace(c.Registry.AuthType)) {
+	case "", "bearer", "basic":
+	default:
+		return fmt.Errorf("invalid registry.auth_type %q (want bearer or basic)", c.Registry.AuthType)
+	}
+	switch strings.ToLower(strings.TrimSpace(c.Policy.UnknownScope)) {
+	case "", "approve", "deny":
+	default:
+		return fmt.Errorf("invalid policy.unknown_scope %q (want approve or deny)", c.Policy.UnknownScope)
+	}
+	for i, r := range c.Policy.Rules {
+		if strings.TrimSpace(r.Scope) == "" {
+			return fmt.Errorf("policy.rules[%d].scope is empty", i)
+		}
+		switch r.Outside {
+		case "", "require_approval", "deny":
+		default:
+			return fmt.Errorf("invalid policy.rules[%d].outside %q (want require_approval or deny)", i, r.Outside)
+		}
+	}
+	for i, o := range c.Policy.Overrides {
+		if strings.TrimSpace(o.Skill) == "" {
+			return fmt.Errorf("policy.overrides[%d].skill is empty", i)
+		}
+		if o.Signer != "" {
+			if key, err := hex.DecodeString(o.Signer); err != nil || len(key) != 32 {
+				return fmt.Errorf("invalid policy.overrides[%d].signer (want a hex Ed25519 public key)", i)
+			}
+		}
+		if len(o.Allow)+len(o.RequireApproval)+len(o.Deny) == 0 {
+			return fmt.Errorf("policy.overrides[%d] for %s lists no scopes", i, o.Skill)
+		}
+		for _, s := range append(append(append([]string{}, o.Allow...), o.RequireApproval...), o.Deny...) {
+			if strings.TrimSpace(s) == "" {
+				return fmt.Errorf("policy.overrides[%d] for %s has an empty scope", i, o.Skill)
+			}
+		}
+	}
+	badges := []string{c.Registry.Badge}
+	for i, s := range c.Registry.Sources {
+		if strings.TrimSpace(s.URL) == "" {
+			return fmt.Errorf("registry.sources[%d].url is empty", i)
+		}
+		badges = append(badges, s.Badge)
+	}
+	for _, b := range badges {
+		switch b {
+		case "", "verified", "signed", "community":
+		default:
+			return fmt.Errorf("invalid registry badge %q (want verified, signed, or community)", b)
+		}
+	}
+	declared := map[string]bool{}
+	for i, s := range c.Skills {
+		name := strings.TrimSpace(s.Name)
+		if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+			return fmt.Errorf("invalid skills[%d].name %q", i, s.Name)
+		}
+		if declared[name] {
+			return fmt.Errorf("skill %s is declared more than once in skills", name)
+		}
+		declared[name] = true
+		if s.Registry != "" {
+			if _, err := c.SkillRegistryURL(s); err != nil {
+				return err
+			}
+		}
+	}
+	if c.XRay.CPUPercent < 0 || c.XRay.MemoryPercent < 0 || c.XRay.Sustain < 0 || c.XRay.Interval < 0 {
+		return fmt.Errorf("xray thresholds and durations must not be negative")
+	}
+	if c.Audit.Anchor.Interval < 0 {
+		return fmt.Errorf("audit.anchor.interval must not be negative")
+	}
+	if c.Audit.Anchor.Interval > 0 && c.Audit.Anchor.File == "" && c.Audit.Anchor.Webhook == "" {
+		return fmt.Errorf("audit.anchor.interval is set but neither audit.anchor.file nor audit.anchor.webhook is")
+	}
+	for i, d := range c.Network.Allowlist {
+		if strings.TrimSpace(d) == "" {
+			return fmt.Errorf("network.allowlist[%d] is empty", i)
+		}
+	}
+	for i, d := range c.Network.BlockedDomains {
+		if strings.TrimSpace(d) == "" {
+			return fmt.Errorf("network.blocked_domains[%d] is empty", i)
+		}
+	}
+	for i, cidr := range c.Network.BlockedCIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil && net.ParseIP(cidr) == nil {
+			return fmt.Errorf("network.blocked_cidrs[%d] %q is not a CIDR or IP address", i, cidr)
+		}
+	}
+	switch strings.ToLower(strings.TrimSpace(c.Telemetry.Exporter)) {
+	case "", "none", "stdout":
+	case "otlp":
+		if strings.TrimSpace(c.Telemetry.Endpoint) == "" {
+			return fmt.Errorf("telemetry.exporter is otlp but telemetry.endpoint is not set (e.g. endpoint: otel-collector:4317)")
+		}
+	default:
+		return fmt.Errorf("invalid telemetry.exporter %q (want none, stdout, or otlp)", c.Telemetry.Exporter)
+	}
+	if c.Telemetry.SampleRatio < 0 || c.Telemetry.SampleRatio > 1 {
+		return fmt.Errorf("telemetry.sample_ratio must be between 0 and 1, got %v", c.Telemetry.SampleRatio)
+	}
+	if c.Telemetry.MaxLabelValues < 0 {
+		return fmt.Errorf("telemetry.max_label_values must not be negative")
+	}
+	if c.Security.RetainFor < 0 {
+		return fmt.Errorf("security.retain_for must not be negative")
+	}
+	for _, capability := range c.MCP.Capabilities {
+		switch capability {
+		case "read", "write", "exec":
+		default:
+			return fmt.Errorf("invalid mcp.capabilities entry %q (want read, write, or exec)", capability)
+		}
+	}
+	return nil
+}
+
+// isNumericID reports whether s is a plain decimal user or group ID, in
+// the canonical form the sandbox accepts (no sign or leading zeros).
+func isNumericID(s string) bool {
+	if s == "" || (len(s) > 1 && s[0] == '0') {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Warnings reports settings that are valid on their own but contradict or
+// depend on others, so they likely do not do what was intended. Validate
+// rejects outright errors; these are for 'aegisclaw doctor' to point out.
+func (c *Config) Warnings() []string {
+	var warnings []string
+	if c.Network.DefaultDeny && len(c.Network.Allowlist) == 0 {
+		warnings = append(warnings, "network.default_deny is on and network.allowlist is empty, so harnessed agents can reach no host; list the hosts they need in network.allowlist")
+	}
+	if c.Agent.OnCapacity != "" && c.Agent.MaxConcurrentRuns == 0 {
+		warnings = append(warnings, "agent.on_capacity has no effect without agent.max_concurrent_runs; set a limit or remove it")
+	}
+	if c.Registry.AuthType != "" && c.Registry.AuthSecret == "" {
+		warnings = append(warnings, "registry.auth_type is set but registry.auth_secret is not, so registry requests are anonymous; name the secret holding the credentials")
+	}
+	if len(c.Security.EBPF.Probes) > 0 && !c.Security.EBPF.Enabled {
+		warnings = append(warnings, "security.ebpf.probes are listed but security.ebpf.enabled is false, so none attach")
+	}
+	if len(c.Server.CORS.AllowedOrigins) == 0 && (len(c.Server.CORS.AllowedMethods) > 0 || len(c.Server.CORS.AllowedHeaders) > 0) {
+		warnings = append(warnings, "server.cors allows methods or headers but no allowed_origins, so no cross-origin request is allowed")
+	}
+	if c.Telemetry.Endpoint != "" && strings.ToLower(strings.TrimSpace(c.Telemetry.Exporter)) != "otlp" {
+		warnings = append(warnings, "telemetry.endpoint is set but telemetry.exporter is not otlp, so it is unused")
+	}
+	return warnings
+}
+
 // Save writes the configuration to the specified path
 func (c *Config) Save(path string) error {
 	data, err := yaml.Marshal(c)
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -94,6 +95,28 @@ func TestLoad_InvalidYAML(t *testing.T) {
 	}
 }
 
+func TestLoad_RejectsInvalidSettings(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "config.yaml")
+	// Valid YAML, but an unknown guardrails mode: Load runs Validate.
+	os.WriteFile(path, []byte("guardrails:\n  mode: maybe\n"), 0600)
+
+	_, err := Load(path)
+	if err == nil || !strings.Contains(err.Error(), "guardrails.mode") {
+		t.Fatalf("Load of invalid config: err = %v, want the guardrails.mode error", err)
+	}
+
+	// Warnings alone do not fail the load.
+	os.WriteFile(path, []byte("agent:\n  on_capacity: queue\n"), 0600)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load with only warnings: %v", err)
+	}
+	if len(cfg.Warnings()) == 0 {
+		t.Error("expected a warning for on_capacity without max_concurrent_runs")
+	}
+}
+
 func TestSave_Permissions(t *testing.T) {
 	tmp := t.TempDir()
 	path := filepath.Join(tmp, "config.yaml")
@@ -113,3 +136,215 @@ func TestSave_Permissions(t *testing.T) {
 	}
 }
 
+
+func TestValidate_RegistryAuthType(t *testing.T) {
+	for _, v := range []string{"", "bearer", "Basic"} {
+		cfg := &Config{}
+		cfg.Registry.AuthType = v
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("auth_type %q: unexpected error %v", v, err)
+		}
+	}
+	cfg := &Config{}
+	cfg.Registry.AuthType = "digest"
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected an error for auth_type digest")
+	}
+}
+
+func TestValidate_ImagePullPolicy(t *testing.T) {
+	for _, v := range []string{"", "missing", "never"} {
+		cfg := &Config{}
+		cfg.Security.ImagePullPolicy = v
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("image_pull_policy %q: unexpected error %v", v, err)
+		}
+	}
+	cfg := &Config{}
+	cfg.Security.ImagePullPolicy = "always"
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected an error for image_pull_policy always")
+	}
+}
+
+func TestValidate_TelemetryExporter(t *testing.T) {
+	tests := []struct {
+		exporter, endpoint string
+		wantErr            string
+	}{
+		{"", "", ""},
+		{"none", "", ""},
+		{"stdout", "", ""},
+		{"otlp", "otel-collector:4317", ""},
+		{"otlp", "", "telemetry.endpoint is not set"},
+		{"jaeger", "", "invalid telemetry.exporter"},
+	}
+	for _, tt := range tests {
+		cfg := &Config{}
+		cfg.Telemetry.Exporter = tt.exporter
+		cfg.Telemetry.Endpoint = tt.endpoint
+		err := cfg.Validate()
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("exporter %q endpoint %q: unexpected error %v", tt.exporter, tt.endpoint, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("exporter %q endpoint %q: error %v, want %q", tt.exporter, tt.endpoint, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidate_TelemetryLimits(t *testing.T) {
+	tests := []struct {
+		ratio   float64
+		max     int
+		wantErr string
+	}{
+		{0, 0, ""},
+		{0.1, 50, ""},
+		{1, 0, ""},
+		{1.5, 0, "telemetry.sample_ratio"},
+		{-0.1, 0, "telemetry.sample_ratio"},
+		{0, -1, "telemetry.max_label_values"},
+	}
+	for _, tt := range tests {
+		cfg := &Config{}
+		cfg.Telemetry.SampleRatio = tt.ratio
+		cfg.Telemetry.MaxLabelValues = tt.max
+		err := cfg.Validate()
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("ratio %v max %d: unexpected error %v", tt.ratio, tt.max, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("ratio %v max %d: error %v, want %q", tt.ratio, tt.max, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidate_SandboxUser(t *testing.T) {
+	tests := []struct {
+		user      string
+		allowRoot bool
+		wantErr   string
+	}{
+		{"", false, ""},
+		{"1001:2000", false, ""},
+		{"65534", false, ""},
+		{"app", false, "invalid security.sandbox_user"},
+		{"1000:-1", false, "invalid security.sandbox_user"},
+		{"01000", false, "invalid security.sandbox_user"},
+		{"0:0", false, "allow_root_user"},
+		{"1000:0", false, "allow_root_user"},
+		{"0", false, "allow_root_user"},
+		{"0:0", true, ""},
+	}
+	for _, tt := range tests {
+		cfg := &Config{}
+		cfg.Security.SandboxUser = tt.user
+		cfg.Security.AllowRootUser = tt.allowRoot
+		err := cfg.Validate()
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("sandbox_user %q allow_root_user %v: unexpected error %v", tt.user, tt.allowRoot, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("sandbox_user %q allow_root_user %v: error %v, want %q", tt.user, tt.allowRoot, err, tt.wantErr)
+		}
+	}
+}
+
+func TestWarnings(t *testing.T) {
+	tests := []struct {
+		name string
+		set  func(*Config)
+		want string
+	}{
+		{"default deny without allowlist", func(c *Config) { c.Network.DefaultDeny = true }, "network.allowlist is empty"},
+		{"on_capacity without limit", func(c *Config) { c.Agent.OnCapacity = "reject" }, "agent.on_capacity has no effect"},
+		{"auth_type without secret", func(c *Config) { c.Registry.AuthType = "basic" }, "registry.auth_secret is not"},
+		{"probes with ebpf off", func(c *Config) { c.Security.EBPF.Probes = []string{"files"} }, "security.ebpf.enabled is false"},
+		{"cors without origins", func(c *Config) { c.Server.CORS.AllowedMethods = []string{"PUT"} }, "no allowed_origins"},
+		{"endpoint without otlp", func(c *Config) { c.Telemetry.Endpoint = "collector:4317" }, "telemetry.endpoint is set"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{}
+			tt.set(cfg)
+			if err := cfg.Validate(); err != nil {
+				t.Fatalf("warnings must not fail validation: %v", err)
+			}
+			warnings := cfg.Warnings()
+			if len(warnings) != 1 || !strings.Contains(warnings[0], tt.want) {
+				t.Errorf("warnings = %q, want one containing %q", warnings, tt.want)
+			}
+		})
+	}
+
+	cfg := &Config{}
+	cfg.Network.DefaultDeny = true
+	cfg.Network.Allowlist = []string{"api.example.com"}
+	cfg.Agent.OnCapacity = "reject"
+	cfg.Agent.MaxConcurrentRuns = 2
+	cfg.Telemetry.Exporter = "otlp"
+	cfg.Telemetry.Endpoint = "collector:4317"
+	if w := cfg.Warnings(); len(w) != 0 {
+		t.Errorf("consistent config warned: %q", w)
+	}
+}
+
+func TestValidate_BlockedCIDRs(t *testing.T) {
+	cfg := &Config{}
+	cfg.Network.BlockedCIDRs = []string{"10.0.0.0/8", "192.0.2.1", "fd00::/8"}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("valid blocklist rejected: %v", err)
+	}
+	cfg.Network.BlockedCIDRs = []string{"evil.example"}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "network.blocked_cidrs[0]") {
+		t.Errorf("a domain in blocked_cidrs: %v", err)
+	}
+}
+
+func TestValidate_MCPCapabilities(t *testing.T) {
+	cfg := &Config{}
+	cfg.MCP.Capabilities = []string{"write", "exec"}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("valid mcp.capabilities rejected: %v", err)
+	}
+	cfg.MCP.Capabilities = []string{"admin"}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "mcp.capabilities") {
+		t.Errorf("unknown capability: %v", err)
+	}
+}
+
+func TestValidate_Skills(t *testing.T) {
+	cfg := &Config{}
+	cfg.Registry.URL = "https://registry.example.com"
+	cfg.Registry.Sources = []RegistrySource{{Name: "internal", URL: "https://skills.corp.example"}}
+	cfg.Skills = []SkillSpec{{Name: "fetcher", Version: "1.2.0"}, {Name: "builder", Registry: "internal"}, {Name: "linter", Registry: "https://other.example"}}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("valid skills rejected: %v", err)
+	}
+	if url, _ := cfg.SkillRegistryURL(cfg.Skills[1]); url != "https://skills.corp.example" {
+		t.Errorf("registry for builder = %q, want the internal source", url)
+	}
+
+	invalid := [][]SkillSpec{
+		{{Name: ""}},
+		{{Name: "../etc"}},
+		{{Name: "fetcher"}, {Name: "fetcher", Version: "2.0.0"}},
+		{{Name: "fetcher", Registry: "nowhere"}},
+	}
+	for _, skills := range invalid {
+		cfg.Skills = skills
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Validate accepted skills %+v", skills)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Get returns the value at a dotted key (e.g. "security.require_approval")
+// from the effective config, formatted for display. Lists are
+// comma-separated; whole sections are rendered as YAML.
+func (c *Config) Get(key string) (string, error) {
+	v, err := lookupField(reflect.ValueOf(c).Elem(), key)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case v.Type() == durationType:
+		return time.Duration(v.Int()).String(), nil
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String:
+		return strings.Join(v.Interface().([]string), ","), nil
+	case v.Kind() == reflect.Struct || v.Kind() == reflect.Map || v.Kind() == reflect.Slice:
+		out, err := yaml.Marshal(v.Interface())
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(out), "\n"), nil
+	default:
+		return fmt.Sprint(v.Interface()), nil
+	}
+}
+
+// SetValue sets a dotted key in the config file at path. The value is parsed
+// according to the field's type (bool, number, duration, string, or a
+// comma-separated string list) and the result must decode and pass Validate
+// before anything is written. The file is edited as a YAML node tree so
+// comments and unrelated formatting survive, and the previous version is
+// kept as path+".bak".
+func SetValue(path, key, value string) error {
+	field, err := lookupField(reflect.ValueOf(&Config{}).Elem(), key)
+	if err != nil {
+		return err
+	}
+	valNode, err := scalarNode(field.Type(), key, value)
+	if err != nil {
+		return err
+	}
+
+	orig, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(orig, &doc); err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	if err := setNode(doc.Content[0], strings.Split(key, "."), valNode); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	enc.Close()
+
+	var check Config
+	if err := yaml.Unmarshal(buf.Bytes(), &check); err != nil {
+		return fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+	if err := check.Validate(); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path+".bak", orig, 0600); err != nil {
+		return fmt.Errorf("failed to back up config: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
+
+// lookupField walks v by yaml tag names.
+func lookupField(v reflect.Value, key string) (reflect.Value, error) {
+	if strings.TrimSpace(key) == "" {
+		return reflect.Value{}, fmt.Errorf("empty config key")
+	}
+	for _, part := range strings.Split(key, ".") {
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, fmt.Errorf("unknown config key %q", key)
+		}
+		found := false
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+			if name == part && name != "-" {
+				v = v.Field(i)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return reflect.Value{}, fmt.Errorf("unknown config key %q", key)
+		}
+	}
+	return v, nil
+}
+
+// scalarNode parses value for a field of type t into a YAML node.
+func scalarNode(t reflect.Type, key, value string) (*yaml.Node, error) {
+	scalar := func(tag, v string) *yaml.Node {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: v}
+	}
+	switch {
+	case t == durationType:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s expects a duration (e.g. 30s), got %q", key, value)
+		}
+		return scalar("!!str", d.String()), nil
+	case t.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s expects true or false, got %q", key, value)
+		}
+		return scalar("!!bool", strconv.FormatBool(b)), nil
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s expects an integer, got %q", key, value)
+		}
+		return scalar("!!int", strconv.FormatInt(n, 10)), nil
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s expects a number, got %q", key, value)
+		}
+		return scalar("!!float", strconv.FormatFloat(f, 'g', -1, 64)), nil
+	case t.Kind() == reflect.String:
+		return scalar("!!str", value), nil
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.String:
+		seq := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				seq.Content = append(seq.Content, scalar("!!str", item))
+			}
+		}
+		return seq, nil
+	default:
+		return nil, fmt.Errorf("%s is a section, not a single value; edit it in config.yaml", key)
+	}
+}
+
+// setNode sets path under mapping m to val, creating intermediate mappings.
+func setNode(m *yaml.Node, path []string, val *yaml.Node) error {
+	if m.Kind != yaml.MappingNode {
+		return fmt.Errorf("config key %q is not a mapping in config.yaml", path[0])
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value != path[0] {
+			continue
+		}
+		if len(path) == 1 {
+			// Keep any comments attached to the old value.
+			val.HeadComment = m.Content[i+1].HeadComment
+			val.LineComment = m.Content[i+1].LineComment
+			val.FootComment = m.Content[i+1].FootComment
+			m.Content[i+1] = val
+			return nil
+		}
+		child := m.Content[i+1]
+		if child.Kind != yaml.MappingNode {
+			// e.g. "security: null" — replace with an empty mapping.
+			child = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			m.Content[i+1] = child
+		}
+		return setNode(child, path[1:], val)
+	}
+
+	keyNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: path[0]}
+	if len(path) == 1 {
+		m.Content = append(m.Content, keyNode, val)
+		return nil
+	}
+	child := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	m.Content = append(m.Content, keyNode, child)
+	return setNode(child, path[1:], val)
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const editFixture = `# AegisClaw config
+version: "0.5.0"
+security:
+  # Ask before risky actions
+  require_approval: false
+  sandbox_backend: docker
+`
+
+func writeEditFixture(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(editFixture), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSetValue_Bool(t *testing.T) {
+	path := writeEditFixture(t)
+	if err := SetValue(path, "security.require_approval", "true"); err != nil {
+		t.Fatalf("SetValue: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.Security.RequireApproval {
+		t.Error("expected security.require_approval to be true")
+	}
+	data, _ := os.ReadFile(path)
+	for _, comment := range []string{"# AegisClaw config", "# Ask before risky actions"} {
+		if !strings.Contains(string(data), comment) {
+			t.Errorf("comment %q lost:\n%s", comment, data)
+		}
+	}
+	backup, err := os.ReadFile(path + ".bak")
+	if err != nil {
+		t.Fatalf("expected backup: %v", err)
+	}
+	if string(backup) != editFixture {
+		t.Errorf("backup does not match original:\n%s", backup)
+	}
+}
+
+func TestSetValue_NestedString(t *testing.T) {
+	path := writeEditFixture(t)
+	if err := SetValue(path, "registry.url", "https://registry.example.com"); err != nil {
+		t.Fatalf("SetValue: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Registry.URL != "https://registry.example.com" {
+		t.Errorf("registry.url = %q", cfg.Registry.URL)
+	}
+	if got, _ := cfg.Get("registry.url"); got != "https://registry.example.com" {
+		t.Errorf("Get(registry.url) = %q", got)
+	}
+	if cfg.Security.SandboxBackend != "docker" {
+		t.Error("unrelated keys should be preserved")
+	}
+}
+
+func TestSetValue_RejectsInvalid(t *testing.T) {
+	path := writeEditFixture(t)
+	cases := map[string]string{
+		"security.require_approval": "maybe",
+		"guardrails.mode":           "loud",
+		"security.nonexistent":      "x",
+		"security":                  "x",
+	}
+	for key, value := range cases {
+		if err := SetValue(path, key, value); err == nil {
+			t.Errorf("SetValue(%s=%s) should fail", key, value)
+		}
+	}
+
+	data, _ := os.ReadFile(path)
+	if string(data) != editFixture {
+		t.Errorf("config changed after rejected edits:\n%s", data)
+	}
+	if _, err := os.Stat(path + ".bak"); !os.IsNotExist(err) {
+		t.Error("no backup should be written for rejected edits")
+	}
+}
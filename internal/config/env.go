@@ -0,0 +1,100 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// envRef matches ${NAME} and ${NAME:-default} references in string values.
+var envRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+var yamlNodeType = reflect.TypeOf(yaml.Node{})
+
+// expandEnv replaces ${NAME} references in every string value of cfg with
+// the environment variable's value. ${NAME:-default} falls back to default
+// when NAME is unset or empty; a plain ${NAME} that is unset is an error
+// naming the config key, so a missing deployment variable fails loudly
+// rather than silently becoming "".
+func expandEnv(cfg *Config) error {
+	return expandValue(reflect.ValueOf(cfg).Elem(), "")
+}
+
+func expandValue(v reflect.Value, key string) error {
+	switch v.Kind() {
+	case reflect.String:
+		s, err := expandString(v.String(), key)
+		if err != nil {
+			return err
+		}
+		v.SetString(s)
+	case reflect.Struct:
+		if v.Type() == yamlNodeType {
+			return nil // unapplied profiles; expanded only if selected
+		}
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			name := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+			if name == "-" || !t.Field(i).IsExported() {
+				continue
+			}
+			if err := expandValue(v.Field(i), joinKey(key, name)); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if err := expandValue(v.Index(i), fmt.Sprintf("%s[%d]", key, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if v.Type().Elem().Kind() != reflect.String {
+			return nil
+		}
+		for _, k := range v.MapKeys() {
+			s, err := expandString(v.MapIndex(k).String(), joinKey(key, fmt.Sprint(k.Interface())))
+			if err != nil {
+				return err
+			}
+			v.SetMapIndex(k, reflect.ValueOf(s).Convert(v.Type().Elem()))
+		}
+	}
+	return nil
+}
+
+func expandString(s, key string) (string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+	var missing string
+	out := envRef.ReplaceAllStringFunc(s, func(ref string) string {
+		m := envRef.FindStringSubmatch(ref)
+		val, ok := os.LookupEnv(m[1])
+		if strings.Contains(ref, ":-") {
+			if val == "" {
+				return m[2]
+			}
+			return val
+		}
+		if !ok && missing == "" {
+			missing = m[1]
+		}
+		return val
+	})
+	if missing != "" {
+		return "", fmt.Errorf("%s references environment variable %s, which is not set (use ${%s:-default} to supply a default)", key, missing, missing)
+	}
+	return out, nil
+}
+
+func joinKey(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
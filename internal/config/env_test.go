@@ -0,0 +1,76 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func loadEnvConfig(t *testing.T, body string) (*Config, error) {
+	t.Helper()
+	SetProfile("")
+	t.Setenv(ProfileEnv, "")
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(body), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return Load(path)
+}
+
+func TestLoad_ExpandsEnvVars(t *testing.T) {
+	t.Setenv("AEGIS_TEST_REGISTRY", "https://registry.corp")
+	t.Setenv("AEGIS_TEST_HOST", "api.corp")
+	cfg, err := loadEnvConfig(t, `registry:
+  url: ${AEGIS_TEST_REGISTRY}/v1
+network:
+  allowlist: ["${AEGIS_TEST_HOST}", static.example.com]
+`)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Registry.URL != "https://registry.corp/v1" {
+		t.Errorf("registry.url = %q", cfg.Registry.URL)
+	}
+	if got := cfg.Network.Allowlist; len(got) != 2 || got[0] != "api.corp" || got[1] != "static.example.com" {
+		t.Errorf("network.allowlist = %v", got)
+	}
+}
+
+func TestLoad_EnvVarDefaults(t *testing.T) {
+	t.Setenv("AEGIS_TEST_EMPTY", "")
+	t.Setenv("AEGIS_TEST_SET", "block")
+	cfg, err := loadEnvConfig(t, `registry:
+  url: ${AEGIS_TEST_UNSET_URL:-https://default.example}
+guardrails:
+  mode: ${AEGIS_TEST_SET:-warn}
+agent:
+  name: ${AEGIS_TEST_EMPTY:-fallback}
+`)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Registry.URL != "https://default.example" {
+		t.Errorf("unset var should use its default, got %q", cfg.Registry.URL)
+	}
+	if cfg.Guardrails.Mode != "block" {
+		t.Errorf("set var should win over its default, got %q", cfg.Guardrails.Mode)
+	}
+	if cfg.Agent.Name != "fallback" {
+		t.Errorf("empty var should use its default, got %q", cfg.Agent.Name)
+	}
+}
+
+func TestLoad_MissingEnvVarErrors(t *testing.T) {
+	_, err := loadEnvConfig(t, `registry:
+  url: ${AEGIS_TEST_DEFINITELY_UNSET}
+`)
+	if err == nil {
+		t.Fatal("expected an error for an unset variable")
+	}
+	for _, want := range []string{"registry.url", "AEGIS_TEST_DEFINITELY_UNSET"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q should mention %s", err, want)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProfileEnv names the environment variable that selects a config profile.
+const ProfileEnv = "AEGISCLAW_PROFILE"
+
+var (
+	profileMu   sync.RWMutex
+	flagProfile string
+)
+
+// SetProfile selects the profile to apply on Load, taking precedence over
+// AEGISCLAW_PROFILE. It is wired to the CLI's --profile flag.
+func SetProfile(name string) {
+	profileMu.Lock()
+	defer profileMu.Unlock()
+	flagProfile = strings.TrimSpace(name)
+}
+
+// ActiveProfile returns the selected profile name: the --profile flag if set,
+// otherwise AEGISCLAW_PROFILE, otherwise "" (base config only).
+func ActiveProfile() string {
+	profileMu.RLock()
+	defer profileMu.RUnlock()
+	if flagProfile != "" {
+		return flagProfile
+	}
+	return strings.TrimSpace(os.Getenv(ProfileEnv))
+}
+
+// applyProfile overlays profile name onto cfg. Settings come from, in
+// increasing precedence: the base config, the profiles.<name> section of the
+// same file, and config.d/<name>.yaml beside it. Only keys present in an
+// overlay replace base values; lists are replaced, not appended.
+func applyProfile(cfg *Config, path, name string) error {
+	if name == "" {
+		return nil
+	}
+	if strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return fmt.Errorf("invalid profile name %q", name)
+	}
+
+	found := false
+	if node, ok := cfg.Profiles[name]; ok {
+		if err := node.Decode(cfg); err != nil {
+			return fmt.Errorf("failed to apply profile %q: %w", name, err)
+		}
+		found = true
+	}
+
+	overlay := filepath.Join(filepath.Dir(path), "config.d", name+".yaml")
+	data, err := os.ReadFile(overlay)
+	switch {
+	case err == nil:
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", overlay, err)
+		}
+		found = true
+	case !errors.Is(err, os.ErrNotExist):
+		return fmt.Errorf("failed to read %s: %w", overlay, err)
+	}
+
+	if !found {
+		return fmt.Errorf("config profile %q not found", name)
+	}
+	cfg.Profile = name
+	return nil
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const profileBase = `version: "1"
+guardrails:
+  mode: warn
+network:
+  default_deny: false
+  allowlist: [example.com]
+profiles:
+  prod:
+    guardrails:
+      mode: block
+    network:
+      default_deny: true
+  dev:
+    guardrails:
+      mode: "off"
+`
+
+func writeProfileConfig(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte(profileBase), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad_ProfileMergePrecedence(t *testing.T) {
+	t.Setenv(ProfileEnv, "prod")
+	SetProfile("")
+	path := writeProfileConfig(t)
+
+	// config.d overrides the inline profile section.
+	if err := os.MkdirAll(filepath.Join(filepath.Dir(path), "config.d"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	overlay := "network:\n  allowlist: [api.prod.example.com]\n"
+	if err := os.WriteFile(filepath.Join(filepath.Dir(path), "config.d", "prod.yaml"), []byte(overlay), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Profile != "prod" {
+		t.Errorf("Profile = %q, want prod", cfg.Profile)
+	}
+	if cfg.Guardrails.Mode != "block" {
+		t.Errorf("guardrails.mode = %q, want block from profile", cfg.Guardrails.Mode)
+	}
+	if !cfg.Network.DefaultDeny {
+		t.Error("expected default_deny from profile")
+	}
+	if len(cfg.Network.Allowlist) != 1 || cfg.Network.Allowlist[0] != "api.prod.example.com" {
+		t.Errorf("allowlist = %v, want config.d override", cfg.Network.Allowlist)
+	}
+	if cfg.Version != "1" {
+		t.Errorf("version = %q, want base value kept", cfg.Version)
+	}
+}
+
+func TestLoad_ProfileFlagOverridesEnv(t *testing.T) {
+	t.Setenv(ProfileEnv, "prod")
+	SetProfile("dev")
+	defer SetProfile("")
+	path := writeProfileConfig(t)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Profile != "dev" || cfg.Guardrails.Mode != "off" {
+		t.Errorf("got profile %q mode %q, want dev/off", cfg.Profile, cfg.Guardrails.Mode)
+	}
+}
+
+func TestLoad_NoProfileUsesBase(t *testing.T) {
+	t.Setenv(ProfileEnv, "")
+	SetProfile("")
+	cfg, err := Load(writeProfileConfig(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Profile != "" || cfg.Guardrails.Mode != "warn" {
+		t.Errorf("got profile %q mode %q, want base warn", cfg.Profile, cfg.Guardrails.Mode)
+	}
+}
+
+func TestLoad_UnknownProfile(t *testing.T) {
+	t.Setenv(ProfileEnv, "staging")
+	SetProfile("")
+	if _, err := Load(writeProfileConfig(t)); err == nil {
+		t.Fatal("expected error for unknown profile")
+	}
+}
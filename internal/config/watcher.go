@@ -0,0 +1,123 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDebounce coalesces the burst of events editors emit for one save
+// (truncate + write, or write-temp + rename) into a single reload.
+const reloadDebounce = 100 * time.Millisecond
+
+// Watcher keeps a live, validated copy of a config file and reloads it when
+// the file changes. Invalid reloads are logged and the previous config is kept.
+type Watcher struct {
+	path    string
+	current atomic.Pointer[Config]
+
+	mu        sync.Mutex
+	listeners []func(old, new *Config)
+
+	// Logf reports reload outcomes. Defaults to log.Printf.
+	Logf func(format string, args ...any)
+}
+
+// NewWatcher loads and validates the config at path. Call Run to start
+// watching for changes.
+func NewWatcher(path string) (*Watcher, error) {
+	cfg, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	w := &Watcher{path: path, Logf: log.Printf}
+	w.current.Store(cfg)
+	return w, nil
+}
+
+// Config returns the most recently loaded valid config. The returned value
+// must be treated as read-only; it is shared with other callers.
+func (w *Watcher) Config() *Config {
+	return w.current.Load()
+}
+
+// OnChange registers fn to be called after each successful reload. Use it to
+// re-initialize components that cache config values.
+func (w *Watcher) OnChange(fn func(old, new *Config)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.listeners = append(w.listeners, fn)
+}
+
+// Reload re-reads the config file, swapping it in only if it parses and
+// validates.
+func (w *Watcher) Reload() error {
+	cfg, err := Load(w.path)
+	if err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	old := w.current.Swap(cfg)
+
+	w.mu.Lock()
+	listeners := append([]func(old, new *Config){}, w.listeners...)
+	w.mu.Unlock()
+	for _, fn := range listeners {
+		fn(old, cfg)
+	}
+	return nil
+}
+
+// Run watches the config file until ctx is cancelled. The parent directory is
+// watched rather than the file itself so atomic-rename saves are picked up.
+func (w *Watcher) Run(ctx context.Context) error {
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	defer fw.Close()
+
+	if err := fw.Add(filepath.Dir(w.path)); err != nil {
+		return fmt.Errorf("failed to watch %s: %w", filepath.Dir(w.path), err)
+	}
+
+	target := filepath.Clean(w.path)
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev, ok := <-fw.Events:
+			if !ok {
+				return nil
+			}
+			if filepath.Clean(ev.Name) != target || !ev.Has(fsnotify.Write|fsnotify.Create|fsnotify.Rename) {
+				continue
+			}
+			debounce = time.After(reloadDebounce)
+		case <-debounce:
+			debounce = nil
+			if err := w.Reload(); err != nil {
+				w.Logf("config reload failed, keeping previous config: %v", err)
+			} else {
+				w.Logf("config reloaded from %s", w.path)
+			}
+		case err, ok := <-fw.Errors:
+			if !ok {
+				return nil
+			}
+			w.Logf("config watcher error: %v", err)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatcher_ReloadsOnChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := (&Config{Version: "1", Guardrails: GuardrailsConfig{Mode: "warn"}}).Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := NewWatcher(path)
+	if err != nil {
+		t.Fatalf("NewWatcher: %v", err)
+	}
+	w.Logf = t.Logf
+	changed := make(chan *Config, 1)
+	w.OnChange(func(_, c *Config) { changed <- c })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Run(ctx)
+	time.Sleep(50 * time.Millisecond) // let the watcher register
+
+	if err := (&Config{Version: "2", Guardrails: GuardrailsConfig{Mode: "block"}}).Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case c := <-changed:
+		if c.Guardrails.Mode != "block" {
+			t.Errorf("listener got mode %q, want block", c.Guardrails.Mode)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for reload")
+	}
+	if got := w.Config().Version; got != "2" {
+		t.Errorf("Config().Version = %q, want 2", got)
+	}
+}
+
+func TestWatcher_InvalidReloadKeepsPrevious(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := (&Config{Version: "1"}).Save(path); err != nil {
+		t.Fatal(err)
+	}
+	w, err := NewWatcher(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, []byte("version: \"2\"\nguardrails:\n  mode: panic\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Reload(); err == nil {
+		t.Fatal("expected invalid guardrails mode to be rejected")
+	}
+	if got := w.Config().Version; got != "1" {
+		t.Errorf("Config().Version = %q, want previous value 1", got)
+	}
+}
@@ -2,15 +2,22 @@
 package doctor
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
+	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/mackeh/AegisClaw/internal/audit"
 	"github.com/mackeh/AegisClaw/internal/config"
 	"github.com/mackeh/AegisClaw/internal/openclaw"
+	"github.com/mackeh/AegisClaw/internal/sandbox"
+	"github.com/mackeh/AegisClaw/internal/skill"
 )
 
 // Status represents the result of a health check.
@@ -28,6 +35,8 @@ type Result struct {
 	Status Status
 	Detail string
 	Fix    string // suggested remediation
+	// Remedy, if set, is a fix `doctor --fix` can apply automatically.
+	Remedy *Remedy
 }
 
 // RunAll executes all health checks and returns the results.
@@ -47,10 +56,14 @@ func RunAll() []Result {
 		checkConfig,
 		checkOpenClawAdapter,
 		checkDocker,
+		checkUsernsRemap,
+		checkSandboxRuntime,
 		checkGVisor,
 		checkPolicy,
+		checkSkills,
 		checkSecrets,
 		checkAuditLog,
+		checkClock,
 		checkDiskSpace,
 	}
 
@@ -69,6 +82,7 @@ func checkConfigDir(cfgDir string) Result {
 			Status: StatusFail,
 			Detail: "~/.aegisclaw not found",
 			Fix:    "Run: aegisclaw init",
+			Remedy: createDir(cfgDir),
 		}
 	}
 	if !info.IsDir() {
@@ -77,6 +91,26 @@ func checkConfigDir(cfgDir string) Result {
 			Status: StatusFail,
 			Detail: "~/.aegisclaw exists but is not a directory",
 			Fix:    "Remove the file and run: aegisclaw init",
+			Remedy: &Remedy{
+				Action:      "remove the file " + cfgDir + " and create the directory",
+				Destructive: true,
+				Apply: func() error {
+					if err := os.Remove(cfgDir); err != nil && !os.IsNotExist(err) {
+						return err
+					}
+					return os.MkdirAll(cfgDir, 0700)
+				},
+			},
+		}
+	}
+	// Anyone who can write here can swap policy.rego or config.yaml.
+	if writableByOthers(info) {
+		return Result{
+			Name:   "Config directory",
+			Status: StatusFail,
+			Detail: fmt.Sprintf("%s is writable by other users (mode %o)", cfgDir, info.Mode().Perm()),
+			Fix:    "Run: chmod 700 " + cfgDir,
+			Remedy: tightenPerms(cfgDir, 0700),
 		}
 	}
 	return Result{
@@ -88,19 +122,35 @@ func checkConfigDir(cfgDir string) Result {
 
 func checkConfig(cfgDir string) Result {
 	configPath := filepath.Join(cfgDir, "config.yaml")
-	_, err := config.Load(configPath)
+	cfg, err := config.Load(configPath)
 	if err != nil {
+		fix := "Edit " + configPath
+		if errors.Is(err, fs.ErrNotExist) {
+			fix = "Run: aegisclaw init"
+		}
 		return Result{
 			Name:   "Configuration",
 			Status: StatusFail,
 			Detail: err.Error(),
-			Fix:    "Run: aegisclaw init",
+			Fix:    fix,
 		}
 	}
+	if warnings := cfg.Warnings(); len(warnings) > 0 {
+		return Result{
+			Name:   "Configuration",
+			Status: StatusWarn,
+			Detail: strings.Join(warnings, "; "),
+			Fix:    "Edit " + configPath,
+		}
+	}
+	detail := configPath
+	if cfg.Profile != "" {
+		detail = fmt.Sprintf("%s (profile: %s)", configPath, cfg.Profile)
+	}
 	return Result{
 		Name:   "Configuration",
 		Status: StatusPass,
-		Detail: configPath,
+		Detail: detail,
 	}
 }
 
@@ -122,6 +172,103 @@ func checkDocker(cfgDir string) Result {
 	}
 }
 
+// dockerSecurityOptions returns the daemon's SecurityOptions from docker info.
+// It is a variable so tests can substitute canned daemon info.
+var dockerSecurityOptions = func() ([]string, error) {
+	out, err := exec.Command("docker", "info", "--format", "{{json .SecurityOptions}}").Output()
+	if err != nil {
+		return nil, err
+	}
+	var opts []string
+	if err := json.Unmarshal(out, &opts); err != nil {
+		return nil, fmt.Errorf("unexpected docker info output: %w", err)
+	}
+	return opts, nil
+}
+
+func checkUsernsRemap(cfgDir string) Result {
+	opts, err := dockerSecurityOptions()
+	if err != nil {
+		return Result{
+			Name:   "User namespace remap",
+			Status: StatusWarn,
+			Detail: "could not query Docker daemon",
+			Fix:    "Ensure Docker is running, then re-run: aegisclaw doctor",
+		}
+	}
+	if !sandbox.UsernsRemapEnabled(opts) {
+		return Result{
+			Name:   "User namespace remap",
+			Status: StatusWarn,
+			Detail: "disabled — container UID 1000 maps to host UID 1000",
+			Fix:    `Set "userns-remap": "default" in /etc/docker/daemon.json and restart Docker`,
+		}
+	}
+	return Result{
+		Name:   "User namespace remap",
+		Status: StatusPass,
+		Detail: "enabled",
+	}
+}
+
+// dockerRuntimes returns the names of the daemon's registered OCI runtimes.
+// It is a variable so tests can substitute canned daemon info.
+var dockerRuntimes = func() ([]string, error) {
+	out, err := exec.Command("docker", "info", "--format", "{{json .Runtimes}}").Output()
+	if err != nil {
+		return nil, err
+	}
+	var runtimes map[string]json.RawMessage
+	if err := json.Unmarshal(out, &runtimes); err != nil {
+		return nil, fmt.Errorf("unexpected docker info output: %w", err)
+	}
+	names := make([]string, 0, len(runtimes))
+	for name := range runtimes {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// checkSandboxRuntime verifies the configured security.sandbox_runtime is
+// registered with Docker. Runs refuse to start when it is missing, so this
+// is a failure rather than a warning.
+func checkSandboxRuntime(cfgDir string) Result {
+	cfg, err := config.Load(filepath.Join(cfgDir, "config.yaml"))
+	if err != nil {
+		return Result{Name: "Sandbox runtime", Status: StatusWarn, Detail: "config not loaded"}
+	}
+	rt, err := sandbox.ResolveRuntime(cfg.Security.SandboxRuntime)
+	if err != nil {
+		return Result{
+			Name:   "Sandbox runtime",
+			Status: StatusFail,
+			Detail: err.Error(),
+			Fix:    "Set security.sandbox_runtime to docker, gvisor, kata, or firecracker",
+		}
+	}
+	if rt == "" {
+		return Result{Name: "Sandbox runtime", Status: StatusPass, Detail: "docker default (runc)"}
+	}
+	runtimes, err := dockerRuntimes()
+	if err != nil {
+		return Result{
+			Name:   "Sandbox runtime",
+			Status: StatusWarn,
+			Detail: "could not query Docker daemon",
+			Fix:    "Ensure Docker is running, then re-run: aegisclaw doctor",
+		}
+	}
+	if !sandbox.RuntimeInstalled(runtimes, rt) {
+		return Result{
+			Name:   "Sandbox runtime",
+			Status: StatusFail,
+			Detail: fmt.Sprintf("%s is configured but not registered with Docker — skill runs will be refused", rt),
+			Fix:    fmt.Sprintf("Register %q in /etc/docker/daemon.json \"runtimes\" and restart Docker, or change security.sandbox_runtime", rt),
+		}
+	}
+	return Result{Name: "Sandbox runtime", Status: StatusPass, Detail: rt + " (registered with Docker)"}
+}
+
 func checkGVisor(cfgDir string) Result {
 	out, err := exec.Command("runsc", "--version").Output()
 	if err != nil {
@@ -153,6 +300,7 @@ func checkPolicy(cfgDir string) Result {
 			Status: StatusFail,
 			Detail: "policy.rego not found",
 			Fix:    "Run: aegisclaw init",
+			Remedy: writeDefaultPolicy(cfgDir),
 		}
 	}
 	return Result{
@@ -162,6 +310,33 @@ func checkPolicy(cfgDir string) Result {
 	}
 }
 
+func checkSkills(cfgDir string) Result {
+	return checkSkillCollisions(skill.SearchPaths(cfgDir))
+}
+
+// checkSkillCollisions warns about skill names defined in more than one
+// search directory; only the first (see skill.SearchPaths) is ever run.
+func checkSkillCollisions(dirs []string) Result {
+	collisions := skill.FindCollisions(dirs...)
+	if len(collisions) == 0 {
+		return Result{
+			Name:   "Skills",
+			Status: StatusPass,
+			Detail: fmt.Sprintf("%d skill(s), no duplicate names", len(skill.LoadSkills(dirs...))),
+		}
+	}
+	var parts []string
+	for _, c := range collisions {
+		parts = append(parts, fmt.Sprintf("%s in %s (using %s)", c.Name, strings.Join(c.Dirs, ", "), c.Dirs[0]))
+	}
+	return Result{
+		Name:   "Skills",
+		Status: StatusWarn,
+		Detail: "duplicate skill names: " + strings.Join(parts, "; "),
+		Fix:    "Rename or remove the shadowed copies; installed skills take precedence over ./skills",
+	}
+}
+
 func checkSecrets(cfgDir string) Result {
 	secretsDir := filepath.Join(cfgDir, "secrets")
 	keyFile := filepath.Join(secretsDir, "keys.txt")
@@ -172,15 +347,28 @@ func checkSecrets(cfgDir string) Result {
 			Status: StatusWarn,
 			Detail: "secrets directory not found",
 			Fix:    "Run: aegisclaw secrets init",
+			Remedy: initSecrets(secretsDir),
 		}
 	}
 
-	if _, err := os.Stat(keyFile); os.IsNotExist(err) {
+	keyInfo, err := os.Stat(keyFile)
+	if os.IsNotExist(err) {
 		return Result{
 			Name:   "Secret store",
 			Status: StatusWarn,
 			Detail: "not initialized (no keypair)",
 			Fix:    "Run: aegisclaw secrets init",
+			Remedy: initSecrets(secretsDir),
+		}
+	}
+	// The private key decrypts every secret.
+	if err == nil && accessibleToOthers(keyInfo) {
+		return Result{
+			Name:   "Secret store",
+			Status: StatusFail,
+			Detail: fmt.Sprintf("keys.txt is accessible to other users (mode %o)", keyInfo.Mode().Perm()),
+			Fix:    "Run: chmod 600 " + keyFile,
+			Remedy: tightenPerms(keyFile, 0600),
 		}
 	}
 
@@ -293,3 +481,74 @@ func checkOpenClawAdapter(cfgDir string) Result {
 
 	return result
 }
+
+// maxClockSkew is the largest host clock offset checkClock tolerates. Audit
+// entries are timestamped with the host clock, so beyond this their ordering
+// against other systems' logs, time-range queries, and grant expiry become
+// unreliable.
+const maxClockSkew = 5 * time.Second
+
+// clockReferenceURL serves the trusted HTTP Date header checkClock compares
+// the host clock against.
+const clockReferenceURL = "https://www.cloudflare.com"
+
+// referenceTime returns the reference clock reading and the round-trip time
+// of the request that fetched it. It is a variable so tests can substitute a
+// fixed reference.
+var referenceTime = func() (time.Time, time.Duration, error) {
+	client := &http.Client{Timeout: 5 * time.Second}
+	start := time.Now()
+	resp, err := client.Head(clockReferenceURL)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	resp.Body.Close()
+	rtt := time.Since(start)
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("no usable Date header: %w", err)
+	}
+	// Date has one-second resolution; take the middle of that second.
+	return date.Add(500 * time.Millisecond), rtt, nil
+}
+
+// checkClock warns when the host clock disagrees with a trusted time source.
+func checkClock(cfgDir string) Result {
+	ref, rtt, err := referenceTime()
+	if err != nil {
+		return Result{
+			Name:   "Clock sync",
+			Status: StatusWarn,
+			Detail: "could not reach time reference: " + err.Error(),
+			Fix:    "Verify the host clock is NTP-synchronised (e.g. timedatectl status)",
+		}
+	}
+	return evaluateClockSkew(time.Now(), ref, rtt)
+}
+
+// evaluateClockSkew judges local against ref, a reading taken roughly rtt/2
+// before the response reached us.
+func evaluateClockSkew(local, ref time.Time, rtt time.Duration) Result {
+	skew := local.Sub(ref.Add(rtt / 2))
+	abs := skew
+	if abs < 0 {
+		abs = -abs
+	}
+	direction := "ahead"
+	if skew < 0 {
+		direction = "behind"
+	}
+	if abs > maxClockSkew {
+		return Result{
+			Name:   "Clock sync",
+			Status: StatusWarn,
+			Detail: fmt.Sprintf("host clock is %s %s the reference — audit timestamps are unreliable", abs.Round(time.Millisecond), direction),
+			Fix:    "Enable NTP synchronisation (e.g. timedatectl set-ntp true) and re-run: aegisclaw doctor",
+		}
+	}
+	return Result{
+		Name:   "Clock sync",
+		Status: StatusPass,
+		Detail: fmt.Sprintf("within %s of reference", abs.Round(time.Millisecond)),
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/mackeh/AegisClaw/internal/secrets"
 )
@@ -190,3 +191,145 @@ func TestCheckOpenClawAdapter_ReachableMissingSecret(t *testing.T) {
 		t.Fatalf("unexpected detail: %s", result.Detail)
 	}
 }
+
+func TestCheckUsernsRemap(t *testing.T) {
+	orig := dockerSecurityOptions
+	defer func() { dockerSecurityOptions = orig }()
+
+	dockerSecurityOptions = func() ([]string, error) {
+		return []string{"name=apparmor", "name=seccomp,profile=builtin", "name=userns"}, nil
+	}
+	if r := checkUsernsRemap(""); r.Status != StatusPass {
+		t.Errorf("expected StatusPass with userns enabled, got %d (%s)", r.Status, r.Detail)
+	}
+
+	dockerSecurityOptions = func() ([]string, error) {
+		return []string{"name=apparmor", "name=seccomp,profile=builtin"}, nil
+	}
+	if r := checkUsernsRemap(""); r.Status != StatusWarn {
+		t.Errorf("expected StatusWarn with userns disabled, got %d", r.Status)
+	}
+
+	dockerSecurityOptions = func() ([]string, error) { return nil, os.ErrNotExist }
+	if r := checkUsernsRemap(""); r.Status != StatusWarn {
+		t.Errorf("expected StatusWarn when docker is unavailable, got %d", r.Status)
+	}
+}
+
+func TestCheckSandboxRuntime(t *testing.T) {
+	orig := dockerRuntimes
+	defer func() { dockerRuntimes = orig }()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("security:\n  sandbox_runtime: gvisor\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	dockerRuntimes = func() ([]string, error) { return []string{"runc", "runsc"}, nil }
+	if r := checkSandboxRuntime(dir); r.Status != StatusPass {
+		t.Errorf("expected StatusPass with runsc registered, got %d (%s)", r.Status, r.Detail)
+	}
+
+	dockerRuntimes = func() ([]string, error) { return []string{"runc"}, nil }
+	if r := checkSandboxRuntime(dir); r.Status != StatusFail {
+		t.Errorf("expected StatusFail with runsc missing, got %d (%s)", r.Status, r.Detail)
+	}
+}
+
+func TestEvaluateClockSkew(t *testing.T) {
+	ref := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	cases := []struct {
+		local time.Time
+		rtt   time.Duration
+		want  Status
+	}{
+		{ref, 0, StatusPass},
+		{ref.Add(3 * time.Second), 0, StatusPass},
+		{ref.Add(-4 * time.Second), 0, StatusPass},
+		{ref.Add(10 * time.Second), 0, StatusWarn},
+		{ref.Add(-2 * time.Minute), 0, StatusWarn},
+		// A slow round trip is credited to the reference, not the host.
+		{ref.Add(6 * time.Second), 4 * time.Second, StatusPass},
+	}
+	for _, c := range cases {
+		if r := evaluateClockSkew(c.local, ref, c.rtt); r.Status != c.want {
+			t.Errorf("local=%s rtt=%s: status %d (%s), want %d", c.local.Sub(ref), c.rtt, r.Status, r.Detail, c.want)
+		}
+	}
+	if r := evaluateClockSkew(ref.Add(-time.Minute), ref, 0); !strings.Contains(r.Detail, "behind") {
+		t.Errorf("detail should say the clock is behind: %s", r.Detail)
+	}
+}
+
+func TestCheckClock_ReferenceUnavailable(t *testing.T) {
+	orig := referenceTime
+	defer func() { referenceTime = orig }()
+
+	referenceTime = func() (time.Time, time.Duration, error) { return time.Time{}, 0, os.ErrDeadlineExceeded }
+	if r := checkClock(""); r.Status != StatusWarn {
+		t.Errorf("expected StatusWarn when the reference is unreachable, got %d", r.Status)
+	}
+
+	referenceTime = func() (time.Time, time.Duration, error) { return time.Now().Add(-time.Hour), 0, nil }
+	if r := checkClock(""); r.Status != StatusWarn {
+		t.Errorf("expected StatusWarn for an hour of skew, got %d (%s)", r.Status, r.Detail)
+	}
+}
+
+func writeSkill(t *testing.T, dir, subdir, name string) {
+	t.Helper()
+	path := filepath.Join(dir, subdir)
+	if err := os.MkdirAll(path, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	manifest := "name: " + name + "\nimage: alpine:latest\ncommands:\n  run:\n    args: [\"true\"]\n"
+	if err := os.WriteFile(filepath.Join(path, "skill.yaml"), []byte(manifest), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckSkillCollisions(t *testing.T) {
+	installed, local := t.TempDir(), t.TempDir()
+	writeSkill(t, installed, "scanner", "scanner")
+	writeSkill(t, local, "other", "other")
+
+	if result := checkSkillCollisions([]string{installed, local}); result.Status != StatusPass {
+		t.Errorf("distinct names: status %d (%s)", result.Status, result.Detail)
+	}
+
+	// Same name under a different directory name is still a collision.
+	writeSkill(t, local, "scanner-dev", "scanner")
+	result := checkSkillCollisions([]string{installed, local})
+	if result.Status != StatusWarn {
+		t.Fatalf("expected StatusWarn for a duplicate name, got %d", result.Status)
+	}
+	if !strings.Contains(result.Detail, "scanner") || !strings.Contains(result.Detail, "using "+installed) {
+		t.Errorf("detail should name the skill and the copy in use: %s", result.Detail)
+	}
+}
+
+func TestCheckConfig_CrossFieldValidation(t *testing.T) {
+	write := func(t *testing.T, content string) string {
+		t.Helper()
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return dir
+	}
+
+	res := checkConfig(write(t, "telemetry:\n  exporter: otlp\n"))
+	if res.Status != StatusFail || !strings.Contains(res.Detail, "telemetry.endpoint") {
+		t.Errorf("otlp without endpoint: %+v", res)
+	}
+
+	res = checkConfig(write(t, "network:\n  default_deny: true\n  allowlist: []\n"))
+	if res.Status != StatusWarn || !strings.Contains(res.Detail, "network.allowlist") {
+		t.Errorf("default deny without allowlist: %+v", res)
+	}
+
+	res = checkConfig(write(t, "network:\n  default_deny: true\n  allowlist: [api.example.com]\n"))
+	if res.Status != StatusPass {
+		t.Errorf("consistent config: %+v", res)
+	}
+}
@@ -0,0 +1,125 @@
+package doctor
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/mackeh/AegisClaw/internal/policy"
+	"github.com/mackeh/AegisClaw/internal/secrets"
+)
+
+// Remedy is a fix doctor can apply itself, as opposed to Result.Fix, which
+// only tells the user what to do. Remedies are idempotent: applying one to
+// an already-fixed setup does nothing.
+type Remedy struct {
+	Action string // what Apply does, e.g. "create ~/.aegisclaw/secrets"
+	// Destructive remedies remove or replace existing data, so Fix asks
+	// before applying them.
+	Destructive bool
+	Apply       func() error
+}
+
+// FixOutcome reports what Fix did about one result.
+type FixOutcome struct {
+	Name    string
+	Action  string
+	Skipped bool // a destructive remedy the user declined
+	Err     error
+}
+
+// Fix applies the remedies of every result that has one, in order, and
+// reports what it did. confirm is asked before each destructive remedy; a
+// nil confirm declines them all. Run the checks again afterwards to see
+// what is left.
+func Fix(results []Result, confirm func(Result) bool) []FixOutcome {
+	var outcomes []FixOutcome
+	for _, r := range results {
+		if r.Remedy == nil || r.Status == StatusPass {
+			continue
+		}
+		o := FixOutcome{Name: r.Name, Action: r.Remedy.Action}
+		if r.Remedy.Destructive && (confirm == nil || !confirm(r)) {
+			o.Skipped = true
+		} else {
+			o.Err = r.Remedy.Apply()
+		}
+		outcomes = append(outcomes, o)
+	}
+	return outcomes
+}
+
+// createDir is the remedy for a missing private directory.
+func createDir(dir string) *Remedy {
+	return &Remedy{
+		Action: "create " + dir,
+		Apply:  func() error { return os.MkdirAll(dir, 0700) },
+	}
+}
+
+// tightenPerms is the remedy for a file or directory others can access.
+func tightenPerms(path string, mode os.FileMode) *Remedy {
+	return &Remedy{
+		Action: fmt.Sprintf("chmod %o %s", mode, path),
+		Apply:  func() error { return os.Chmod(path, mode) },
+	}
+}
+
+// accessibleToOthers reports whether users other than the owner can use
+// the file at all.
+func accessibleToOthers(info os.FileInfo) bool {
+	return info.Mode().Perm()&0077 != 0
+}
+
+// writableByOthers reports whether users other than the owner can modify
+// the file or, for a directory, replace what is in it.
+func writableByOthers(info os.FileInfo) bool {
+	return info.Mode().Perm()&0022 != 0
+}
+
+// writeDefaultPolicy is the remedy for a missing policy.rego. It writes
+// policy.DefaultPolicy, which only asks for approval, so it never grants
+// more than running without a policy already did.
+func writeDefaultPolicy(cfgDir string) *Remedy {
+	path := filepath.Join(cfgDir, "policy.rego")
+	return &Remedy{
+		Action: "write a default require-approval policy to " + path,
+		Apply: func() error {
+			if err := os.MkdirAll(cfgDir, 0700); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+			if errors.Is(err, fs.ErrExist) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if _, err := f.WriteString(policy.DefaultPolicy); err != nil {
+				f.Close()
+				return err
+			}
+			return f.Close()
+		},
+	}
+}
+
+// initSecrets is the remedy for a missing or uninitialised secret store:
+// the same as `aegisclaw secrets init`, minus printing the public key.
+func initSecrets(secretsDir string) *Remedy {
+	return &Remedy{
+		Action: "initialize the secret store in " + secretsDir,
+		Apply: func() error {
+			if err := os.MkdirAll(secretsDir, 0700); err != nil {
+				return err
+			}
+			if _, err := os.Stat(filepath.Join(secretsDir, "keys.txt")); err == nil {
+				return nil
+			}
+			_, err := secrets.NewManager(secretsDir).Init()
+			return err
+		},
+	}
+}
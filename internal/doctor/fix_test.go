@@ -0,0 +1,107 @@
+package doctor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mackeh/AegisClaw/internal/policy"
+)
+
+func TestFix_CreatesMissingSecretStore(t *testing.T) {
+	dir := t.TempDir()
+	before := checkSecrets(dir)
+	if before.Status != StatusWarn || before.Remedy == nil {
+		t.Fatalf("missing secrets dir: %+v, want a warning with a remedy", before)
+	}
+
+	outcomes := Fix([]Result{before}, nil)
+	if len(outcomes) != 1 || outcomes[0].Err != nil || outcomes[0].Skipped {
+		t.Fatalf("Fix = %+v", outcomes)
+	}
+	if after := checkSecrets(dir); after.Status != StatusPass {
+		t.Errorf("re-check after --fix: %+v, want pass", after)
+	}
+	info, err := os.Stat(filepath.Join(dir, "secrets"))
+	if err != nil || info.Mode().Perm() != 0700 {
+		t.Errorf("secrets dir: %v %v, want mode 0700", info, err)
+	}
+
+	// Applying the remedy again is harmless.
+	if err := before.Remedy.Apply(); err != nil {
+		t.Errorf("second apply: %v", err)
+	}
+}
+
+func TestFix_PolicyAndPermissions(t *testing.T) {
+	dir := t.TempDir()
+	secretsDir := filepath.Join(dir, "secrets")
+	if err := initSecrets(secretsDir).Apply(); err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(secretsDir, "keys.txt")
+	if err := os.Chmod(keyFile, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(dir, 0777); err != nil {
+		t.Fatal(err)
+	}
+
+	results := []Result{checkConfigDir(dir), checkPolicy(dir), checkSecrets(dir)}
+	for _, r := range results {
+		if r.Status != StatusFail || r.Remedy == nil {
+			t.Fatalf("%s: %+v, want a failure with a remedy", r.Name, r)
+		}
+	}
+	for _, o := range Fix(results, nil) {
+		if o.Err != nil {
+			t.Errorf("%s: %v", o.Name, o.Err)
+		}
+	}
+	for _, r := range []Result{checkConfigDir(dir), checkPolicy(dir), checkSecrets(dir)} {
+		if r.Status != StatusPass {
+			t.Errorf("re-check %s: %+v, want pass", r.Name, r)
+		}
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "policy.rego"))
+	if string(data) != policy.DefaultPolicy {
+		t.Errorf("policy.rego = %q, want the default policy", data)
+	}
+
+	// A policy written meanwhile is never overwritten.
+	custom := filepath.Join(dir, "policy.rego")
+	os.WriteFile(custom, []byte("package aegisclaw.policy"), 0600)
+	if err := writeDefaultPolicy(dir).Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(custom); string(data) != "package aegisclaw.policy" {
+		t.Errorf("existing policy overwritten: %q", data)
+	}
+}
+
+func TestFix_DestructiveNeedsConfirmation(t *testing.T) {
+	cfgDir := filepath.Join(t.TempDir(), ".aegisclaw")
+	if err := os.WriteFile(cfgDir, []byte("oops"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	r := checkConfigDir(cfgDir)
+	if r.Remedy == nil || !r.Remedy.Destructive {
+		t.Fatalf("config dir is a file: %+v, want a destructive remedy", r)
+	}
+
+	if o := Fix([]Result{r}, nil); len(o) != 1 || !o[0].Skipped {
+		t.Errorf("without confirmation: %+v, want skipped", o)
+	}
+	if info, err := os.Stat(cfgDir); err != nil || info.IsDir() {
+		t.Fatalf("declined remedy changed %s", cfgDir)
+	}
+
+	asked := false
+	Fix([]Result{r}, func(Result) bool { asked = true; return true })
+	if !asked {
+		t.Error("confirm was not asked")
+	}
+	if after := checkConfigDir(cfgDir); after.Status != StatusPass {
+		t.Errorf("after confirmed fix: %+v", after)
+	}
+}
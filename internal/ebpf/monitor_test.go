@@ -95,3 +95,25 @@ func TestMonitor_EmitDropped(t *testing.T) {
 			stats.EventsTotal, stats.DroppedEvents, stats.EventsTotal+stats.DroppedEvents)
 	}
 }
+
+func TestProbeConfigFromNames(t *testing.T) {
+	pc, err := ProbeConfigFromNames(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pc.TraceSyscalls || !pc.TraceFiles || !pc.TraceNetwork || pc.TraceProcess {
+		t.Errorf("default probes = %+v", pc)
+	}
+
+	pc, err = ProbeConfigFromNames([]string{"Network", " process "})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pc.TraceSyscalls || pc.TraceFiles || !pc.TraceNetwork || !pc.TraceProcess {
+		t.Errorf("probes = %+v, want network and process", pc)
+	}
+
+	if _, err := ProbeConfigFromNames([]string{"kprobe-all"}); err == nil {
+		t.Error("expected error for unknown probe")
+	}
+}
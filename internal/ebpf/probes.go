@@ -0,0 +1,42 @@
+package ebpf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Probe names accepted by ProbeConfigFromNames (and security.ebpf.probes).
+const (
+	ProbeSyscalls = "syscalls"
+	ProbeFiles    = "files"
+	ProbeNetwork  = "network"
+	ProbeProcess  = "process"
+)
+
+// DefaultProbes is the probe set attached when none are configured.
+var DefaultProbes = []string{ProbeSyscalls, ProbeFiles, ProbeNetwork}
+
+// ProbeConfigFromNames builds a ProbeConfig from probe names. An empty list
+// selects DefaultProbes; unknown names are an error.
+func ProbeConfigFromNames(names []string) (ProbeConfig, error) {
+	if len(names) == 0 {
+		names = DefaultProbes
+	}
+	var pc ProbeConfig
+	for _, n := range names {
+		switch strings.ToLower(strings.TrimSpace(n)) {
+		case ProbeSyscalls:
+			pc.TraceSyscalls = true
+		case ProbeFiles:
+			pc.TraceFiles = true
+		case ProbeNetwork:
+			pc.TraceNetwork = true
+		case ProbeProcess:
+			pc.TraceProcess = true
+		default:
+			return ProbeConfig{}, fmt.Errorf("unknown eBPF probe %q (supported: %s, %s, %s, %s)",
+				n, ProbeSyscalls, ProbeFiles, ProbeNetwork, ProbeProcess)
+		}
+	}
+	return pc, nil
+}
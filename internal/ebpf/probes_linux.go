@@ -49,27 +49,36 @@ func (m *Monitor) loadProbes(ctx context.Context) error {
 		return fmt.Errorf("loading objects: %w", err)
 	}
 
-	// Attach probes
+	// Attach only the configured probes.
 	var links []link.Link
 
 	// 1. Syscall tracer
-	tpSys, err := link.Tracepoint("raw_syscalls", "sys_enter", objs.TraceSysEnter, nil)
-	if err != nil {
-		objs.Close()
-		return fmt.Errorf("opening sys_enter tracepoint: %w", err)
+	if m.config.TraceSyscalls {
+		tpSys, err := link.Tracepoint("raw_syscalls", "sys_enter", objs.TraceSysEnter, nil)
+		if err != nil {
+			objs.Close()
+			return fmt.Errorf("opening sys_enter tracepoint: %w", err)
+		}
+		links = append(links, tpSys)
 	}
-	links = append(links, tpSys)
 
 	// 2. Openat tracer
-	tpOpen, err := link.Tracepoint("syscalls", "sys_enter_openat", objs.TraceOpenat, nil)
-	if err == nil {
-		links = append(links, tpOpen)
+	if m.config.TraceFiles {
+		if tpOpen, err := link.Tracepoint("syscalls", "sys_enter_openat", objs.TraceOpenat, nil); err == nil {
+			links = append(links, tpOpen)
+		}
 	}
 
 	// 3. TCP Connect tracer
-	kpNet, err := link.Kprobe("tcp_v4_connect", objs.TraceTcpV4Connect, nil)
-	if err == nil {
-		links = append(links, kpNet)
+	if m.config.TraceNetwork {
+		if kpNet, err := link.Kprobe("tcp_v4_connect", objs.TraceTcpV4Connect, nil); err == nil {
+			links = append(links, kpNet)
+		}
+	}
+
+	if len(links) == 0 {
+		objs.Close()
+		return fmt.Errorf("no eBPF probes attached")
 	}
 
 	// Open a ringbuf reader
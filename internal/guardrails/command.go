@@ -0,0 +1,45 @@
+package guardrails
+
+import (
+	"regexp"
+	"strings"
+)
+
+// defaultCommandRules check a command line about to be executed. The
+// harmful-instruction patterns are reused, since a skill whose arguments
+// come from the user can be steered into the very invocations those
+// patterns describe.
+func defaultCommandRules() []Rule {
+	return []Rule{
+		{Name: "harmful_command", Severity: SeverityHigh, CheckFn: checkHarmfulCommand},
+	}
+}
+
+// commandPatterns catch destructive invocations that only take their final
+// shape once arguments are substituted, e.g. a path argument of "/".
+var commandPatterns = []*regexp.Regexp{
+	// Recursive delete of the root filesystem, including "rm -rf /" at the
+	// end of the line, which the output pattern cannot see, and a root that
+	// was shell-quoted on substitution.
+	regexp.MustCompile(`(?i)\brm\s+(?:-\S+\s+)*-\S*r\S*\s+(?:-\S+\s+)*(?:--\s+)?['"]?/\*?['"]?(?:\s|;|&|\||$)`),
+	regexp.MustCompile(`(?i)\bmkfs(?:\.\w+)?\s+(?:-\S+\s+)*/dev/`),
+	regexp.MustCompile(`(?i)\bdd\s+(?:\S+\s+)*of=/dev/(?:sd|hd|vd|xvd|nvme|mmcblk)`),
+}
+
+func checkHarmfulCommand(text string) []Violation {
+	v := scanPatterns(text, "harmful_command", SeverityHigh, harmfulPatterns, "Potentially harmful command")
+	return append(v, scanPatterns(text, "harmful_command", SeverityHigh, commandPatterns, "Potentially harmful command")...)
+}
+
+// CheckCommand validates a fully resolved command line — binary plus
+// arguments after user input has been substituted — before it is executed.
+// Arguments are joined with spaces, so a shell script passed to "sh -c" is
+// scanned as written.
+func (e *Engine) CheckCommand(argv []string) *Result {
+	text := strings.Join(argv, " ")
+	var violations []Violation
+	for _, r := range e.commandRules {
+		violations = append(violations, r.CheckFn(text)...)
+	}
+	return newResult(violations)
+}
@@ -0,0 +1,127 @@
+package guardrails
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ContextWindow is how many of the most recent history turns
+// CheckInputWithContext considers alongside the current message.
+const ContextWindow = 4
+
+// deferralPatterns mark an instruction meant to take effect on a later turn.
+var deferralPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\b(in|on|for|with)\s+(your|the)\s+(next|following)\s+(response|reply|message|answer|turn|output)`),
+	regexp.MustCompile(`(?i)\b(after|from)\s+(this|my|your)\s+(next\s+)?(message|reply|response|turn)\b`),
+}
+
+// triggerPattern captures the trigger word of "when I say X, ..." style
+// instructions, which arm a payload for whatever later turn contains X.
+var triggerPattern = regexp.MustCompile(`(?i)\bwhen(ever)?\s+i\s+(say|type|write)\s+["'“]?([\p{L}\p{N}_-]{2,})`)
+
+// overridePattern is an instruction to drop the model's rules. On its own it
+// is too broad to flag; combined with a deferral it is a delayed injection.
+var overridePattern = regexp.MustCompile(`(?i)\b(ignore|disregard|forget|override|bypass|drop|abandon|stop\s+following|no\s+longer\s+follow)\b[^.!?\n]{0,40}\b(rules|instructions|guidelines|restrictions|polic(y|ies)|filters|safety|guardrails|system\s+prompt)\b`)
+
+// CheckInputWithContext validates a prompt like CheckInput, and also against
+// the last ContextWindow turns of history (oldest first) to catch multi-turn
+// attacks no single message reveals: an injection phrase split across turns
+// and completed by text, or an instruction deferred from an earlier turn
+// ("in your next response, ignore the rules") that text now triggers.
+func (e *Engine) CheckInputWithContext(history []string, text string) *Result {
+	res := e.CheckInput(text)
+	recent := history[max(0, len(history)-ContextWindow):]
+	if len(recent) == 0 {
+		return res
+	}
+	violations := append(res.Violations, checkSplitInjection(recent, text)...)
+	violations = append(violations, checkDeferredInjection(recent, text)...)
+	return newResult(violations)
+}
+
+// checkSplitInjection reports injection and jailbreak phrases that match the
+// joined conversation but neither the history alone nor text alone: text
+// completes a phrase started in earlier turns.
+func checkSplitInjection(recent []string, text string) []Violation {
+	before := strings.Join(recent, "\n")
+	joined := before + "\n" + text
+	all, prior, current := newScanText(joined), newScanText(before), newScanText(text)
+
+	completes := func(pat *regexp.Regexp) bool {
+		_, inAll, _ := all.find(pat)
+		if !inAll {
+			return false
+		}
+		_, inPrior, _ := prior.find(pat)
+		_, inCurrent, _ := current.find(pat)
+		return !inPrior && !inCurrent
+	}
+
+	var violations []Violation
+	split := func(rule, label string) {
+		violations = append(violations, Violation{
+			Rule:     "split_injection",
+			Severity: SeverityCritical,
+			Message:  fmt.Sprintf("%s split across conversation turns (completed by this message; per-message rule %s)", label, rule),
+		})
+	}
+	for _, pat := range injectionPatterns {
+		if completes(pat) {
+			split("prompt_injection", "Prompt injection")
+			return violations
+		}
+	}
+	cAll, cPrior, cText := compact(joined), compact(before), compact(text)
+	for _, sig := range compactInjectionSignatures {
+		if strings.Contains(cAll, sig) && !strings.Contains(cPrior, sig) && !strings.Contains(cText, sig) {
+			split("prompt_injection", "Prompt injection")
+			return violations
+		}
+	}
+	for _, pat := range jailbreakPatterns {
+		if completes(pat) {
+			split("jailbreak_attempt", "Jailbreak pattern")
+			return violations
+		}
+	}
+	return violations
+}
+
+// checkDeferredInjection reports an override instruction armed in an
+// earlier turn that takes effect now: one deferred to "the next response"
+// in the previous turn, or one bound to a trigger word text contains.
+func checkDeferredInjection(recent []string, text string) []Violation {
+	last := normalize(recent[len(recent)-1])
+	if loc := overridePattern.FindStringIndex(last); loc != nil {
+		for _, pat := range deferralPatterns {
+			if pat.MatchString(last) {
+				return []Violation{{
+					Rule:     "deferred_injection",
+					Severity: SeverityHigh,
+					Message:  fmt.Sprintf("Instruction deferred from the previous turn takes effect now: %q", last[loc[0]:loc[1]]),
+				}}
+			}
+		}
+	}
+
+	current := strings.ToLower(normalize(text))
+	for _, turn := range recent {
+		msg := normalize(turn)
+		loc := overridePattern.FindStringIndex(msg)
+		if loc == nil {
+			continue
+		}
+		for _, m := range triggerPattern.FindAllStringSubmatch(msg, -1) {
+			word := regexp.MustCompile(`\b` + regexp.QuoteMeta(strings.ToLower(m[3])) + `\b`)
+			if word.MatchString(current) {
+				return []Violation{{
+					Rule:     "deferred_injection",
+					Severity: SeverityHigh,
+					Message:  fmt.Sprintf("Message contains trigger %q armed in an earlier turn to %q", m[3], msg[loc[0]:loc[1]]),
+				}}
+			}
+		}
+	}
+	return nil
+}
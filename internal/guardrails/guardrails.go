@@ -38,6 +38,35 @@ type Result struct {
 	Violations []Violation `json:"violations,omitempty"`
 	Sanitized  string      `json:"sanitized,omitempty"` // cleaned text if output mode
 	Source     string      `json:"source,omitempty"`    // origin label for data checks
+	// MaxSeverity is the most severe violation's severity, empty if none.
+	MaxSeverity Severity `json:"max_severity,omitempty"`
+	// ViolationCounts counts violations by severity.
+	ViolationCounts map[Severity]int `json:"violation_counts,omitempty"`
+}
+
+// newResult builds the Result for violations, computing Allowed and the
+// aggregates so callers need not walk Violations themselves.
+func newResult(violations []Violation) *Result {
+	r := &Result{
+		Allowed:    !hasCriticalOrHigh(violations),
+		Violations: violations,
+	}
+	for _, v := range violations {
+		if r.ViolationCounts == nil {
+			r.ViolationCounts = map[Severity]int{}
+		}
+		r.ViolationCounts[v.Severity]++
+		if v.Severity.rank() > r.MaxSeverity.rank() {
+			r.MaxSeverity = v.Severity
+		}
+	}
+	return r
+}
+
+// Blocked reports whether the checked content should be stopped: a high or
+// critical violation was found. It is the inverse of Allowed.
+func (r *Result) Blocked() bool {
+	return !r.Allowed
 }
 
 // Rule is a single guardrail check.
@@ -49,17 +78,19 @@ type Rule struct {
 
 // Engine evaluates text against a set of guardrail rules.
 type Engine struct {
-	inputRules  []Rule
-	outputRules []Rule
-	dataRules   []Rule
+	inputRules   []Rule
+	outputRules  []Rule
+	dataRules    []Rule
+	commandRules []Rule
 }
 
 // NewEngine creates a guardrail engine with default rules.
 func NewEngine() *Engine {
 	return &Engine{
-		inputRules:  defaultInputRules(),
-		outputRules: defaultOutputRules(),
-		dataRules:   defaultDataRules(),
+		inputRules:   defaultInputRules(),
+		outputRules:  defaultOutputRules(),
+		dataRules:    defaultDataRules(),
+		commandRules: defaultCommandRules(),
 	}
 }
 
@@ -69,10 +100,7 @@ func (e *Engine) CheckInput(text string) *Result {
 	for _, r := range e.inputRules {
 		violations = append(violations, r.CheckFn(text)...)
 	}
-	return &Result{
-		Allowed:    !hasCriticalOrHigh(violations),
-		Violations: violations,
-	}
+	return newResult(violations)
 }
 
 // CheckOutput validates an LLM response before returning to the user.
@@ -87,11 +115,9 @@ func (e *Engine) CheckOutput(text string) *Result {
 		sanitized = sanitizeOutput(text, violations)
 	}
 
-	return &Result{
-		Allowed:    !hasCriticalOrHigh(violations),
-		Violations: violations,
-		Sanitized:  sanitized,
-	}
+	res := newResult(violations)
+	res.Sanitized = sanitized
+	return res
 }
 
 // CheckData scans untrusted content the agent ingests — tool outputs, fetched
@@ -103,11 +129,9 @@ func (e *Engine) CheckData(source, text string) *Result {
 	for _, r := range e.dataRules {
 		violations = append(violations, r.CheckFn(text)...)
 	}
-	return &Result{
-		Allowed:    !hasCriticalOrHigh(violations),
-		Violations: violations,
-		Source:     source,
-	}
+	res := newResult(violations)
+	res.Source = source
+	return res
 }
 
 // AddInputRule adds a custom rule for input checking.
@@ -119,6 +143,9 @@ func (e *Engine) AddOutputRule(r Rule) { e.outputRules = append(e.outputRules, r
 // AddDataRule adds a custom rule for untrusted-data checking.
 func (e *Engine) AddDataRule(r Rule) { e.dataRules = append(e.dataRules, r) }
 
+// AddCommandRule adds a custom rule for resolved-command checking.
+func (e *Engine) AddCommandRule(r Rule) { e.commandRules = append(e.commandRules, r) }
+
 func hasCriticalOrHigh(violations []Violation) bool {
 	for _, v := range violations {
 		if v.Severity == SeverityCritical || v.Severity == SeverityHigh {
@@ -267,6 +294,14 @@ var secretPatterns = []*regexp.Regexp{
 	regexp.MustCompile(`(?i)(ghp_[a-zA-Z0-9]{36,})`),
 	regexp.MustCompile(`(?i)(AKIA[0-9A-Z]{16})`),
 	regexp.MustCompile(`(?i)password\s*[:=]\s*["']?([^\s"']{8,})`),
+	regexp.MustCompile(`\beyJ[a-zA-Z0-9_-]{8,}\.eyJ[a-zA-Z0-9_-]{8,}\.[a-zA-Z0-9_-]{8,}`), // JWT
+}
+
+// SecretPatterns returns the credential-shaped patterns used by the secret
+// leak rule, for callers (such as the output redactor) that scrub secrets
+// they were never told about.
+func SecretPatterns() []*regexp.Regexp {
+	return append([]*regexp.Regexp(nil), secretPatterns...)
 }
 
 func checkSecretLeak(text string) []Violation {
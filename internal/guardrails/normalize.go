@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"regexp"
 	"strings"
+	"unicode/utf8"
 )
 
 // zeroWidthRunes are invisible characters attackers splice between letters to
@@ -44,6 +45,9 @@ var homoglyphs = map[rune]rune{
 // stripInvisible removes zero-width and other invisible characters.
 func stripInvisible(s string) string {
 	return strings.Map(func(r rune) rune {
+		if r < utf8.RuneSelf {
+			return r // all invisible runes are non-ASCII
+		}
 		if zeroWidthRunes[r] {
 			return -1
 		}
@@ -54,6 +58,9 @@ func stripInvisible(s string) string {
 // foldConfusables rewrites homoglyphs and fullwidth characters to plain ASCII.
 func foldConfusables(s string) string {
 	return strings.Map(func(r rune) rune {
+		if r < utf8.RuneSelf {
+			return r
+		}
 		if ascii, ok := homoglyphs[r]; ok {
 			return ascii
 		}
@@ -69,11 +76,27 @@ func foldConfusables(s string) string {
 	}, s)
 }
 
-var whitespaceRun = regexp.MustCompile(`\s+`)
+// isSpace reports whether b is in the regexp \s class: tab, newline, form
+// feed, carriage return or space.
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\f' || b == '\r'
+}
 
 // collapseSpace flattens runs of whitespace to a single space.
 func collapseSpace(s string) string {
-	return strings.TrimSpace(whitespaceRun.ReplaceAllString(s, " "))
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if !isSpace(s[i]) {
+			b.WriteByte(s[i])
+			continue
+		}
+		b.WriteByte(' ')
+		for i+1 < len(s) && isSpace(s[i+1]) {
+			i++
+		}
+	}
+	return strings.TrimSpace(b.String())
 }
 
 // normalize returns text with evasion tricks neutralised: invisible characters
@@ -82,20 +105,80 @@ func normalize(s string) string {
 	return collapseSpace(foldConfusables(stripInvisible(s)))
 }
 
-var allSeparators = regexp.MustCompile(`[\s.,_*|~/\\()\[\]{}<>:;!?'"+=-]+`)
+// separators are the whitespace and punctuation compact strips.
+const separators = " \t\n\f\r.,_*|~/\\()[]{}<>:;!?'\"+=-"
 
 // compact strips all whitespace and separator punctuation, folding a fully
 // spaced-out phrase ("i g n o r e   a l l") back into one solid lowercase
 // string for signature matching.
 func compact(s string) string {
 	folded := foldConfusables(stripInvisible(s))
-	return strings.ToLower(allSeparators.ReplaceAllString(folded, ""))
+	var b strings.Builder
+	b.Grow(len(folded))
+	for i := 0; i < len(folded); i++ {
+		// Separators are ASCII, so this never splits a multi-byte rune.
+		if strings.IndexByte(separators, folded[i]) < 0 {
+			b.WriteByte(folded[i])
+		}
+	}
+	return strings.ToLower(b.String())
 }
 
-var (
-	base64Blob = regexp.MustCompile(`[A-Za-z0-9+/]{20,}={0,2}`)
-	hexBlob    = regexp.MustCompile(`(?:[0-9A-Fa-f]{2}){16,}`)
-)
+func isBase64Char(b byte) bool {
+	return 'A' <= b && b <= 'Z' || 'a' <= b && b <= 'z' || '0' <= b && b <= '9' || b == '+' || b == '/'
+}
+
+func isHexChar(b byte) bool {
+	return '0' <= b && b <= '9' || 'a' <= b && b <= 'f' || 'A' <= b && b <= 'F'
+}
+
+// base64Blobs returns the runs of at least 20 base64 characters in s, each
+// with up to two trailing "=", as `[A-Za-z0-9+/]{20,}={0,2}` would find
+// them. A hand-written scan is used because that counted repetition makes
+// the regexp slow on large texts.
+func base64Blobs(s string) []string {
+	var out []string
+	for i := 0; i < len(s); {
+		if !isBase64Char(s[i]) {
+			i++
+			continue
+		}
+		j := i
+		for j < len(s) && isBase64Char(s[j]) {
+			j++
+		}
+		if j-i >= 20 {
+			for k := 0; k < 2 && j < len(s) && s[j] == '='; k++ {
+				j++
+			}
+			out = append(out, s[i:j])
+		}
+		i = j
+	}
+	return out
+}
+
+// hexBlobs returns the runs of at least 16 hex-digit pairs in s, as
+// `(?:[0-9A-Fa-f]{2}){16,}` would find them: an odd trailing digit is left
+// out.
+func hexBlobs(s string) []string {
+	var out []string
+	for i := 0; i < len(s); {
+		if !isHexChar(s[i]) {
+			i++
+			continue
+		}
+		j := i
+		for j < len(s) && isHexChar(s[j]) {
+			j++
+		}
+		if n := (j - i) &^ 1; n >= 32 {
+			out = append(out, s[i:i+n])
+		}
+		i = j
+	}
+	return out
+}
 
 // isMostlyPrintable reports whether a decoded byte slice is plausible text
 // rather than binary noise, so random base64-looking tokens are ignored.
@@ -116,7 +199,7 @@ func isMostlyPrintable(b []byte) bool {
 // payloads smuggled in an encoded form can still be inspected.
 func decodeEmbedded(s string) []string {
 	var out []string
-	for _, m := range base64Blob.FindAllString(s, -1) {
+	for _, m := range base64Blobs(s) {
 		trimmed := strings.TrimRight(m, "=")
 		for _, enc := range []*base64.Encoding{base64.RawStdEncoding, base64.RawURLEncoding} {
 			if dec, err := enc.DecodeString(trimmed); err == nil && isMostlyPrintable(dec) {
@@ -125,7 +208,7 @@ func decodeEmbedded(s string) []string {
 			}
 		}
 	}
-	for _, m := range hexBlob.FindAllString(s, -1) {
+	for _, m := range hexBlobs(s) {
 		if dec, err := hex.DecodeString(m); err == nil && isMostlyPrintable(dec) {
 			out = append(out, string(dec))
 		}
@@ -135,9 +218,11 @@ func decodeEmbedded(s string) []string {
 
 // scanText holds the variants of a piece of text used for evasion-resistant
 // matching: the original, a normalised form, and any decoded base64/hex
-// payloads.
+// payloads. folded holds each variant folded with foldCase, for the anchor
+// pre-filter (see prefilter.go).
 type scanText struct {
 	variants []string
+	folded   []string
 }
 
 func newScanText(s string) scanText {
@@ -154,7 +239,11 @@ func newScanText(s string) scanText {
 		add(dec)
 		add(normalize(dec))
 	}
-	return scanText{variants: variants}
+	folded := make([]string, len(variants))
+	for i, v := range variants {
+		folded[i] = foldCase(v)
+	}
+	return scanText{variants: variants, folded: folded}
 }
 
 // find reports whether pat matches any variant. When the match is in the
@@ -162,6 +251,9 @@ func newScanText(s string) scanText {
 // de-obfuscation reports evaded=true with a zero span.
 func (st scanText) find(pat *regexp.Regexp) (span [2]int, matched, evaded bool) {
 	for i, v := range st.variants {
+		if !mayMatch(pat, st.folded[i]) {
+			continue
+		}
 		loc := pat.FindStringIndex(v)
 		if loc == nil {
 			continue
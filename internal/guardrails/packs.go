@@ -0,0 +1,169 @@
+package guardrails
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Pack is a set of injection and jailbreak patterns for one language. The
+// English patterns are built into every Engine; packs add coverage for
+// attacks phrased in other languages.
+type Pack struct {
+	Name      string
+	Injection []*regexp.Regexp
+	Jailbreak []*regexp.Regexp
+}
+
+// builtinPacks are the packs that can be enabled by name.
+var builtinPacks = map[string]Pack{
+	"es": {
+		Name: "es",
+		Injection: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)ignora(r|d)?\s+(todas\s+)?(las\s+)?(instrucciones|indicaciones|reglas|órdenes)\s+(anteriores|previas)`),
+			regexp.MustCompile(`(?i)olvida(r|d)?\s+(todo|todas\s+(las\s+)?(instrucciones|reglas))`),
+			regexp.MustCompile(`(?i)a\s+partir\s+de\s+ahora[,\s]+(eres|ignora|olvida|actúa|actua|responde)`),
+			regexp.MustCompile(`(?i)ahora\s+eres\s+(un|una|el|la)\s+`),
+			regexp.MustCompile(`(?i)nuevas\s+instrucciones\s*:`),
+			regexp.MustCompile(`(?i)(revela|muestra|imprime|repite|dime)\s+(tu|tus|el|las)\s+(prompt|instrucciones|reglas)`),
+		},
+		Jailbreak: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)modo\s+(desarrollador|dios|administrador)\s+(activado|habilitado|activo)`),
+			regexp.MustCompile(`(?i)(actúa|actua)\s+como\s+[^.!?\n]{0,30}sin\s+(restricciones|filtros|censura|límites|limites)`),
+			regexp.MustCompile(`(?i)finge\s+que\s+no\s+tienes\s+(restricciones|límites|limites|reglas)`),
+		},
+	},
+	"fr": {
+		Name: "fr",
+		Injection: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)ignore[rz]?\s+(toutes\s+)?(les\s+)?(instructions|consignes|règles|regles)\s+(précédentes|precedentes|antérieures|anterieures)`),
+			regexp.MustCompile(`(?i)oublie[rz]?\s+(tout|toutes\s+(les\s+)?(instructions|consignes|règles))`),
+			regexp.MustCompile(`(?i)(à|a)\s+partir\s+de\s+maintenant[,\s]+(tu\s+es|ignore|oublie|réponds|reponds|agis)`),
+			regexp.MustCompile(`(?i)tu\s+es\s+maintenant\s+(un|une|le|la)\s+`),
+			regexp.MustCompile(`(?i)nouvelles\s+(instructions|consignes)\s*:`),
+			regexp.MustCompile(`(?i)(révèle|revele|montre|affiche|répète|repete)(-moi)?\s+(ton|tes|le|les)\s+(prompt|instructions|consignes)`),
+		},
+		Jailbreak: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)mode\s+(développeur|developpeur|dieu|admin)\s+(activé|active)`),
+			regexp.MustCompile(`(?i)agis\s+comme\s+[^.!?\n]{0,30}sans\s+(restrictions|filtres|censure|limites)`),
+			regexp.MustCompile(`(?i)fais\s+comme\s+si\s+tu\s+n'?avais\s+(aucune|pas\s+de)\s+(restriction|limite|règle)`),
+		},
+	},
+	"de": {
+		Name: "de",
+		Injection: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)ignorier(e|en)?\s+(sie\s+)?(alle\s+)?(vorherigen|bisherigen|obigen|früheren)\s+(anweisungen|instruktionen|regeln|befehle)`),
+			regexp.MustCompile(`(?i)vergiss\s+(alles|alle\s+(vorherigen\s+)?(anweisungen|regeln))`),
+			regexp.MustCompile(`(?i)ab\s+(jetzt|sofort)[,\s]+(bist\s+du|ignorierst|vergisst|antwortest)`),
+			regexp.MustCompile(`(?i)du\s+bist\s+(jetzt|nun)\s+(ein|eine|der|die|das)\s+`),
+			regexp.MustCompile(`(?i)neue\s+anweisungen?\s*:`),
+			regexp.MustCompile(`(?i)(zeige?|verrate|gib)\s+(mir\s+)?(deinen|deine|den|die)\s+(system-?\s*)?(prompt|anweisungen|regeln)`),
+		},
+		Jailbreak: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)(entwickler|gott|admin)modus\s+(aktiviert|an|ein)`),
+			regexp.MustCompile(`(?i)(tu\s+so|tue\s+so),?\s+als\s+(hättest|hattest)\s+du\s+keine\s+(einschränkungen|regeln|grenzen|filter)`),
+			regexp.MustCompile(`(?i)ohne\s+(einschränkungen|filter|zensur)\s+(antworten|handeln|agieren)`),
+		},
+	},
+}
+
+// PackNames lists the built-in packs, sorted. English ("en") is always on
+// and is not a pack.
+func PackNames() []string {
+	names := make([]string, 0, len(builtinPacks))
+	for n := range builtinPacks {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// packFile is the YAML form of a custom pack.
+type packFile struct {
+	Name      string   `yaml:"name"`
+	Injection []string `yaml:"injection"`
+	Jailbreak []string `yaml:"jailbreak"`
+}
+
+// LoadPackFile reads a custom pack from YAML:
+//
+//	name: it
+//	injection: ['(?i)ignora\s+le\s+istruzioni\s+precedenti']
+//	jailbreak: ['(?i)modalità\s+sviluppatore\s+attiva']
+func LoadPackFile(path string) (Pack, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Pack{}, fmt.Errorf("failed to read guardrail pack: %w", err)
+	}
+	var f packFile
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return Pack{}, fmt.Errorf("failed to parse guardrail pack %s: %w", path, err)
+	}
+	if f.Name == "" {
+		return Pack{}, fmt.Errorf("guardrail pack %s has no name", path)
+	}
+	p := Pack{Name: f.Name}
+	for _, list := range []struct {
+		src []string
+		dst *[]*regexp.Regexp
+	}{{f.Injection, &p.Injection}, {f.Jailbreak, &p.Jailbreak}} {
+		for _, s := range list.src {
+			re, err := regexp.Compile(s)
+			if err != nil {
+				return Pack{}, fmt.Errorf("guardrail pack %s: invalid pattern %q: %w", path, s, err)
+			}
+			*list.dst = append(*list.dst, re)
+		}
+	}
+	return p, nil
+}
+
+// ResolvePack returns the built-in pack with the given name or, if ref is a
+// path to a .yaml/.yml file, the pack loaded from it.
+func ResolvePack(ref string) (Pack, error) {
+	if p, ok := builtinPacks[strings.ToLower(ref)]; ok {
+		return p, nil
+	}
+	if strings.HasSuffix(ref, ".yaml") || strings.HasSuffix(ref, ".yml") {
+		return LoadPackFile(ref)
+	}
+	return Pack{}, fmt.Errorf("unknown guardrail pack %q (built in: %s, or a .yaml file)", ref, strings.Join(PackNames(), ", "))
+}
+
+// AddPack enables p's patterns for input and untrusted-data checks, under
+// the same rule names as the English patterns.
+func (e *Engine) AddPack(p Pack) {
+	label := fmt.Sprintf(" [%s]", p.Name)
+	injection := Rule{Name: "prompt_injection", Severity: SeverityCritical, CheckFn: func(text string) []Violation {
+		return scanPatterns(text, "prompt_injection", SeverityCritical, p.Injection, "Potential prompt injection"+label)
+	}}
+	jailbreak := Rule{Name: "jailbreak_attempt", Severity: SeverityHigh, CheckFn: func(text string) []Violation {
+		return scanPatterns(text, "jailbreak_attempt", SeverityHigh, p.Jailbreak, "Jailbreak pattern"+label)
+	}}
+	e.AddInputRule(injection)
+	e.AddInputRule(jailbreak)
+	e.AddDataRule(injection)
+	e.AddDataRule(jailbreak)
+}
+
+// NewEngineWithPacks creates an engine with the default rules plus the
+// named packs (see ResolvePack). "en" is accepted and ignored, since the
+// English patterns are always active.
+func NewEngineWithPacks(refs []string) (*Engine, error) {
+	e := NewEngine()
+	for _, ref := range refs {
+		if strings.EqualFold(ref, "en") {
+			continue
+		}
+		p, err := ResolvePack(ref)
+		if err != nil {
+			return nil, err
+		}
+		e.AddPack(p)
+	}
+	return e, nil
+}
@@ -0,0 +1,127 @@
+package guardrails
+
+import (
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Go's regexp engine has no DFA, so a case-insensitive pattern without a
+// literal prefix costs a full NFA pass over the text even when nothing can
+// match — on a large document that is most of the scan time. Before running
+// a pattern, the scan therefore checks for its anchors: literals at least
+// one of which every match must contain (e.g. "ignore" or "disregard" for
+// the first injection pattern). A text containing none of them cannot
+// match, so skipping the regex never changes a result; when an anchor is
+// present the regex runs as before and reports the same span.
+
+// anchorCache maps a pattern to its anchors, folded with foldCase; a nil
+// slice means the pattern has no usable anchors and always runs.
+var anchorCache sync.Map // *regexp.Regexp -> []string
+
+// anchorsFor returns pat's anchors, computing them on first use.
+func anchorsFor(pat *regexp.Regexp) []string {
+	if a, ok := anchorCache.Load(pat); ok {
+		return a.([]string)
+	}
+	var anchors []string
+	if re, err := syntax.Parse(pat.String(), syntax.Perl); err == nil {
+		anchors = requiredLiterals(re.Simplify())
+		for i, a := range anchors {
+			anchors[i] = foldCase(a)
+		}
+	}
+	anchorCache.Store(pat, anchors)
+	return anchors
+}
+
+// requiredLiterals returns a set of literals one of which appears in every
+// match of re, or nil if no such set is known.
+func requiredLiterals(re *syntax.Regexp) []string {
+	switch re.Op {
+	case syntax.OpLiteral:
+		if len(re.Rune) == 0 {
+			return nil
+		}
+		return []string{string(re.Rune)}
+	case syntax.OpCapture, syntax.OpPlus:
+		return requiredLiterals(re.Sub[0])
+	case syntax.OpRepeat:
+		if re.Min < 1 {
+			return nil
+		}
+		return requiredLiterals(re.Sub[0])
+	case syntax.OpConcat:
+		// Every part must match, so any part's literals will do; prefer
+		// the set whose shortest literal is longest, as it is the most
+		// selective.
+		var best []string
+		for _, sub := range re.Sub {
+			if lits := requiredLiterals(sub); lits != nil && shortest(lits) > shortest(best) {
+				best = lits
+			}
+		}
+		return best
+	case syntax.OpAlternate:
+		var out []string
+		for _, sub := range re.Sub {
+			lits := requiredLiterals(sub)
+			if lits == nil {
+				return nil
+			}
+			out = append(out, lits...)
+		}
+		return out
+	}
+	return nil
+}
+
+// shortest returns the length of the shortest literal, 0 for none.
+func shortest(lits []string) int {
+	if len(lits) == 0 {
+		return 0
+	}
+	n := len(lits[0])
+	for _, l := range lits[1:] {
+		n = min(n, len(l))
+	}
+	return n
+}
+
+// foldCase maps every rune to the smallest rune it case-folds with, the
+// same equivalence (?i) matches under, so a case-insensitive literal is in
+// a text exactly when its folded form is in the folded text. Unlike
+// strings.ToLower it also folds e.g. the long s "ſ" with "s".
+func foldCase(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < utf8.RuneSelf {
+			if 'a' <= r && r <= 'z' {
+				return r - 'a' + 'A'
+			}
+			return r
+		}
+		least := r
+		for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+			least = min(least, f)
+		}
+		return least
+	}, s)
+}
+
+// mayMatch reports whether text, already folded with foldCase, contains
+// one of pat's anchors. It is always true for a pattern without anchors.
+func mayMatch(pat *regexp.Regexp, folded string) bool {
+	anchors := anchorsFor(pat)
+	if anchors == nil {
+		return true
+	}
+	for _, a := range anchors {
+		if strings.Contains(folded, a) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package guardrails
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ExitViolation is the exit code CLI gates use when content fails a check.
+const ExitViolation = 1
+
+// rank orders severities for threshold comparisons; unknown values rank 0.
+func (s Severity) rank() int {
+	switch s {
+	case SeverityLow:
+		return 1
+	case SeverityMedium:
+		return 2
+	case SeverityHigh:
+		return 3
+	case SeverityCritical:
+		return 4
+	default:
+		return 0
+	}
+}
+
+// ParseSeverity parses a severity name (low, medium, high, critical).
+func ParseSeverity(s string) (Severity, error) {
+	sev := Severity(strings.ToLower(strings.TrimSpace(s)))
+	if sev.rank() == 0 {
+		return "", fmt.Errorf("invalid severity %q (want low, medium, high, or critical)", s)
+	}
+	return sev, nil
+}
+
+// ExitCode returns the exit code for using a check as a pipeline gate:
+// ExitViolation when the result is blocked or, if failAt is set, when any
+// violation is at or above failAt; otherwise 0.
+func (r *Result) ExitCode(failAt Severity) int {
+	if r.Blocked() || (failAt != "" && r.MaxSeverity.rank() >= failAt.rank()) {
+		return ExitViolation
+	}
+	return 0
+}
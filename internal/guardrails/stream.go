@@ -0,0 +1,150 @@
+package guardrails
+
+import (
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// DefaultStreamHoldback is how many trailing bytes a StreamGuard withholds so
+// a credential split across chunk boundaries is seen whole before it is
+// forwarded. It comfortably exceeds every pattern in secretPatterns.
+const DefaultStreamHoldback = 512
+
+// StreamGuard is an io.Writer that redacts credentials from a chunked stream
+// before forwarding it. Unlike a per-chunk redactor it keeps a holdback buffer,
+// so a secret split across two writes is still matched and masked. Call Flush
+// when the stream ends to forward the withheld tail.
+type StreamGuard struct {
+	mu       sync.Mutex
+	w        io.Writer
+	secrets  []string
+	patterns []*regexp.Regexp
+	holdback int
+	pending  []byte
+}
+
+// NewStreamGuard wraps w, masking secretPatterns matches plus any of the given
+// literal secrets.
+func NewStreamGuard(w io.Writer, secrets ...string) *StreamGuard {
+	g := &StreamGuard{w: w, patterns: SecretPatterns(), holdback: DefaultStreamHoldback}
+	for _, s := range secrets {
+		if len(s) > 4 {
+			g.secrets = append(g.secrets, s)
+			if len(s) > g.holdback {
+				g.holdback = len(s)
+			}
+		}
+	}
+	return g
+}
+
+// Write buffers p and forwards everything that can no longer be part of an
+// incomplete match. It always reports len(p) written on success.
+func (g *StreamGuard) Write(p []byte) (int, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.pending = append(g.pending, p...)
+	if len(g.pending) <= g.holdback {
+		return len(p), nil
+	}
+
+	text := string(g.pending)
+	spans := g.matches(text)
+
+	cut := len(text) - g.holdback
+	// Never cut through a match: hold back the whole match instead. Repeat
+	// until stable since moving the cut can land inside an overlapping match.
+	for moved := true; moved; {
+		moved = false
+		for _, s := range spans {
+			if s[0] < cut && cut < s[1] {
+				cut = s[0]
+				moved = true
+			}
+		}
+	}
+	for cut > 0 && cut < len(text) && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	if cut <= 0 {
+		return len(p), nil
+	}
+
+	out := redactSpans(text[:cut], spans)
+	g.pending = append(g.pending[:0], text[cut:]...)
+	if _, err := io.WriteString(g.w, out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Flush redacts and forwards any withheld bytes.
+func (g *StreamGuard) Flush() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if len(g.pending) == 0 {
+		return nil
+	}
+	text := string(g.pending)
+	g.pending = g.pending[:0]
+	_, err := io.WriteString(g.w, redactSpans(text, g.matches(text)))
+	return err
+}
+
+// matches returns the byte spans of every secret or pattern match in text,
+// sorted by start offset.
+func (g *StreamGuard) matches(text string) [][2]int {
+	var spans [][2]int
+	for _, s := range g.secrets {
+		for off := 0; ; {
+			i := strings.Index(text[off:], s)
+			if i < 0 {
+				break
+			}
+			spans = append(spans, [2]int{off + i, off + i + len(s)})
+			off += i + len(s)
+		}
+	}
+	for _, p := range g.patterns {
+		for _, loc := range p.FindAllStringIndex(text, -1) {
+			spans = append(spans, [2]int{loc[0], loc[1]})
+		}
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i][0] < spans[j][0] })
+	return spans
+}
+
+// redactSpans replaces the portions of text covered by spans with
+// [REDACTED]. Spans extending past len(text) are clipped; overlapping spans
+// are merged.
+func redactSpans(text string, spans [][2]int) string {
+	var b strings.Builder
+	last := 0
+	for _, s := range spans {
+		start, end := s[0], s[1]
+		if start >= len(text) {
+			break
+		}
+		if end > len(text) {
+			end = len(text)
+		}
+		if end <= last {
+			continue
+		}
+		if start < last {
+			start = last
+		} else {
+			b.WriteString(text[last:start])
+			b.WriteString("[REDACTED]")
+		}
+		last = end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
@@ -0,0 +1,55 @@
+package guardrails
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestStreamGuard_RedactsSecretSplitAcrossChunks(t *testing.T) {
+	var out bytes.Buffer
+	g := NewStreamGuard(&out, "hunter2-super-secret")
+
+	key := "sk-abcdefghijklmnopqrstuvwxyz0123456789"
+	filler := strings.Repeat("x", DefaultStreamHoldback)
+	stream := filler + " token=" + key + " pw=hunter2-super-secret " + filler + " done"
+
+	// Write in small chunks so both secrets straddle chunk boundaries.
+	for i := 0; i < len(stream); i += 7 {
+		end := i + 7
+		if end > len(stream) {
+			end = len(stream)
+		}
+		if _, err := g.Write([]byte(stream[i:end])); err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(out.String(), "sk-abcdef") || strings.Contains(out.String(), "hunter2") {
+			t.Fatalf("secret fragment forwarded before redaction: %q", out.String())
+		}
+	}
+	if err := g.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := out.String()
+	if strings.Contains(got, key) || strings.Contains(got, "hunter2-super-secret") {
+		t.Fatalf("secret leaked: %q", got)
+	}
+	if strings.Count(got, "[REDACTED]") != 2 {
+		t.Errorf("expected 2 redactions, got %q", got)
+	}
+	if !strings.HasSuffix(got, " done") {
+		t.Errorf("tail not flushed: %q", got[len(got)-20:])
+	}
+}
+
+func TestStreamGuard_PassesCleanText(t *testing.T) {
+	var out bytes.Buffer
+	g := NewStreamGuard(&out)
+	g.Write([]byte("hello "))
+	g.Write([]byte("world"))
+	g.Flush()
+	if out.String() != "hello world" {
+		t.Errorf("got %q", out.String())
+	}
+}
@@ -31,10 +31,25 @@ type Supervisor struct {
 	// addresses through the egress proxy. Default false (SSRF protection on);
 	// cloud metadata endpoints stay blocked regardless.
 	AllowPrivateEgress bool
+	// BlockedDomains and BlockedCIDRs are refused even when allowed; see
+	// proxy.EgressProxy.BlockedDomains. The proxy's default blocklist
+	// (cloud metadata) is kept.
+	BlockedDomains []string
+	BlockedCIDRs   []string
+	// UpstreamProxy and NoProxy forward allowed egress through a parent
+	// proxy (empty values fall back to HTTPS_PROXY/NO_PROXY).
+	UpstreamProxy string
+	NoProxy       []string
+	// DLP blocks plaintext requests whose URL or body matches a credential
+	// pattern; see proxy.EgressProxy.DLP.
+	DLP bool
 	// GuardMode controls indirect-prompt-injection scanning of plaintext HTTP
 	// responses the agent fetches through the egress proxy: "off", "warn", or
 	// "block" (empty defaults to "warn").
 	GuardMode string
+	// GuardPacks are guardrail language packs scanned for besides English;
+	// see guardrails.NewEngineWithPacks.
+	GuardPacks []string
 	// Launcher runs the prepared command. Defaults to ProcessLauncher.
 	Launcher Launcher
 	// WorkDir is the agent's working directory ("" inherits the cwd).
@@ -58,7 +73,7 @@ type Supervisor struct {
 // agent, and blocks until it exits. It returns the agent's exit code. Secrets
 // resolved for injection are released when Run returns.
 func (s *Supervisor) Run(ctx context.Context, adapter AgentAdapter, userArgs []string, stdout, stderr io.Writer) (int, error) {
-	actor := "harness:" + adapter.Name()
+	actor := audit.SystemActor("harness:" + adapter.Name())
 
 	command, err := adapter.PrepareCommand(userArgs)
 	if err != nil {
@@ -79,11 +94,21 @@ func (s *Supervisor) Run(ctx context.Context, adapter AgentAdapter, userArgs []s
 	allowed := mergeDomains(s.AllowedDomains, adapter.DefaultEgressDomains())
 	ep := proxy.NewEgressProxy(allowed, s.Logger)
 	ep.BlockPrivateIPs = !s.AllowPrivateEgress // SSRF protection on by default
+	ep.BlockedDomains = s.BlockedDomains
+	ep.BlockedCIDRs = append(ep.BlockedCIDRs, s.BlockedCIDRs...)
+	ep.DLP = s.DLP
+	if err := ep.SetUpstream(s.UpstreamProxy, s.NoProxy); err != nil {
+		return -1, err
+	}
 	guardMode := s.GuardMode
 	if guardMode == "" {
 		guardMode = "warn"
 	}
-	ep.Guard = guardrails.NewEngine()
+	guard, err := guardrails.NewEngineWithPacks(s.GuardPacks)
+	if err != nil {
+		return -1, err
+	}
+	ep.Guard = guard
 	ep.GuardMode = guardMode // scan fetched plaintext responses for injection
 	proxyURL, err := ep.Start()
 	if err != nil {
@@ -204,11 +229,11 @@ func (s *Supervisor) launcher() Launcher {
 	return ProcessLauncher{}
 }
 
-func (s *Supervisor) audit(action, decision, actor string, details map[string]any) {
+func (s *Supervisor) audit(action, decision string, actor audit.Actor, details map[string]any) {
 	if s.Logger == nil {
 		return
 	}
-	_ = s.Logger.Log(action, nil, decision, actor, details)
+	_ = s.Logger.LogAs(action, nil, decision, actor, details)
 }
 
 // clearSecrets drops resolved secret values from the map so they are no longer
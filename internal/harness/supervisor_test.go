@@ -91,6 +91,9 @@ func TestSupervisorRun(t *testing.T) {
 		if strings.Contains(string(raw), secretVal) {
 			t.Fatalf("secret value leaked into audit entry: %s", raw)
 		}
+		if strings.HasPrefix(e.Action, "harness.") && (e.Identity == nil || *e.Identity != audit.SystemActor("harness:"+adapter.Name())) {
+			t.Errorf("%s attributed to %q / %+v, want the harness for %s", e.Action, e.Actor, e.Identity, adapter.Name())
+		}
 	}
 
 	for _, want := range []string{"harness.plane.network", "harness.secret.inject", "harness.ingress.register", "harness.start", "harness.stop"} {
@@ -238,7 +238,7 @@ func (p *Proxy) audit(action, decision, model string, detail map[string]any) {
 	if model != "" {
 		detail["model"] = model
 	}
-	_ = p.Logger.Log(action, nil, decision, "llm-proxy", detail)
+	_ = p.Logger.LogAs(action, nil, decision, audit.SystemActor("llm-proxy"), detail)
 }
 
 func (p *Proxy) writeError(w http.ResponseWriter, status int, msg string) {
@@ -10,6 +10,8 @@ import (
 	"sort"
 	"strings"
 	"time"
+
+	"github.com/mackeh/AegisClaw/internal/skill"
 )
 
 // SecurityBadge indicates the security verification level of a skill.
@@ -32,7 +34,12 @@ type SkillEntry struct {
 	Downloads   int           `json:"downloads"`
 	Tags        []string      `json:"tags,omitempty"`
 	ManifestURL string        `json:"manifest_url"`
+	BundleURL   string        `json:"bundle_url,omitempty"`
 	UpdatedAt   string        `json:"updated_at"`
+	// Provenance mirrors the manifest's provenance block, if published.
+	Provenance *skill.Provenance `json:"provenance,omitempty"`
+	// Source names the registry the entry was fetched from.
+	Source string `json:"source,omitempty"`
 }
 
 // Index is the full marketplace index.
@@ -125,9 +132,13 @@ func BadgeIcon(b SecurityBadge) string {
 // FormatEntry returns a display string for a marketplace entry.
 func FormatEntry(e SkillEntry) string {
 	stars := fmt.Sprintf("%.1f", e.Rating)
-	return fmt.Sprintf("%s %s v%s  %s  %s  (%d downloads)\n    %s",
+	s := fmt.Sprintf("%s %s v%s  %s  %s  (%d downloads)\n    %s",
 		BadgeIcon(e.Badge), e.Name, e.Version, stars, strings.Join(e.Tags, ", "),
 		e.Downloads, e.Description)
+	if e.Source != "" {
+		s += "\n    from " + e.Source
+	}
+	return s
 }
 
 func matchTags(tags []string, query string) bool {
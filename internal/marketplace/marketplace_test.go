@@ -1,6 +1,7 @@
 package marketplace
 
 import (
+	"errors"
 	"testing"
 )
 
@@ -111,3 +112,46 @@ func TestFormatEntry(t *testing.T) {
 		t.Error("expected non-empty formatted entry")
 	}
 }
+
+func TestCheckOrigin(t *testing.T) {
+	const registry = "https://registry.example.com/index.json"
+
+	onHost := SkillEntry{Name: "ok", ManifestURL: "https://registry.example.com/skills/ok/skill.yaml"}
+	if err := CheckOrigin(registry, onHost, nil); err != nil {
+		t.Errorf("on-host entry rejected: %v", err)
+	}
+
+	relative := SkillEntry{Name: "rel", ManifestURL: "/skills/rel/skill.yaml"}
+	if err := CheckOrigin(registry, relative, nil); err != nil {
+		t.Errorf("relative entry rejected: %v", err)
+	}
+
+	offHost := SkillEntry{Name: "evil", ManifestURL: "https://attacker.test/skill.yaml"}
+	if err := CheckOrigin(registry, offHost, nil); !errors.Is(err, ErrOffRegistry) {
+		t.Errorf("off-host manifest: got %v, want ErrOffRegistry", err)
+	}
+
+	offBundle := SkillEntry{Name: "bundle", ManifestURL: onHost.ManifestURL, BundleURL: "https://attacker.test/b.tgz"}
+	if err := CheckOrigin(registry, offBundle, nil); !errors.Is(err, ErrOffRegistry) {
+		t.Errorf("off-host bundle: got %v, want ErrOffRegistry", err)
+	}
+
+	cdn := SkillEntry{Name: "cdn", ManifestURL: "https://cdn.example.net/skill.yaml"}
+	if err := CheckOrigin(registry, cdn, []string{"cdn.example.net"}); err != nil {
+		t.Errorf("allowlisted host rejected: %v", err)
+	}
+}
+
+func TestFilterOnRegistry(t *testing.T) {
+	entries := []SkillEntry{
+		{Name: "ok", ManifestURL: "https://registry.example.com/ok.yaml"},
+		{Name: "evil", ManifestURL: "https://attacker.test/evil.yaml"},
+	}
+	kept, rejected := FilterOnRegistry("https://registry.example.com", entries, nil)
+	if len(kept) != 1 || kept[0].Name != "ok" {
+		t.Errorf("kept = %+v, want only ok", kept)
+	}
+	if len(rejected) != 1 {
+		t.Errorf("rejected = %v, want 1 error", rejected)
+	}
+}
@@ -0,0 +1,78 @@
+package marketplace
+
+import "github.com/mackeh/AegisClaw/internal/skill"
+
+// Source is a registry the marketplace index is built from.
+type Source struct {
+	Name string
+	URL  string
+	// Badge is the trust level given to every entry from this registry. It
+	// comes from local config rather than the registry itself, so a registry
+	// cannot promote its own listings. Empty means community.
+	Badge SecurityBadge
+}
+
+// Label names the source in listings: its Name, or its URL if unnamed.
+func (s Source) Label() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return s.URL
+}
+
+// FromRegistry converts a fetched registry index into marketplace entries
+// tagged with src.
+func FromRegistry(src Source, reg *skill.RegistryIndex) []SkillEntry {
+	badge := src.Badge
+	if badge == "" {
+		badge = BadgeCommunity
+	}
+	entries := make([]SkillEntry, 0, len(reg.Skills))
+	for _, s := range reg.Skills {
+		entries = append(entries, SkillEntry{
+			Name:        s.Name,
+			Version:     s.Version,
+			Description: s.Description,
+			Badge:       badge,
+			ManifestURL: s.ManifestURL,
+			BundleURL:   s.BundleURL,
+			Source:      src.Label(),
+		})
+	}
+	return entries
+}
+
+// Merge combines entry lists from several registries, given in priority
+// order, into one. Entries are deduplicated by name and version: the one
+// with the higher-trust badge wins, and on equal badges the earlier list
+// wins. Different versions of a skill are all kept.
+func Merge(lists ...[]SkillEntry) []SkillEntry {
+	var merged []SkillEntry
+	pos := map[string]int{}
+	for _, list := range lists {
+		for _, e := range list {
+			key := e.Name + "@" + e.Version
+			i, dup := pos[key]
+			if !dup {
+				pos[key] = len(merged)
+				merged = append(merged, e)
+				continue
+			}
+			if badgeRank(e.Badge) > badgeRank(merged[i].Badge) {
+				merged[i] = e
+			}
+		}
+	}
+	return merged
+}
+
+func badgeRank(b SecurityBadge) int {
+	switch b {
+	case BadgeVerified:
+		return 2
+	case BadgeSigned:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,56 @@
+package marketplace
+
+import (
+	"testing"
+
+	"github.com/mackeh/AegisClaw/internal/skill"
+)
+
+func TestMerge_OverlappingSkill(t *testing.T) {
+	official := FromRegistry(Source{Name: "official", URL: "https://registry.example.com", Badge: BadgeVerified}, &skill.RegistryIndex{
+		Skills: []skill.RegistrySkill{
+			{Name: "web-search", Version: "2.0.0", Description: "official build"},
+			{Name: "git-stats", Version: "1.0.0"},
+		},
+	})
+	private := FromRegistry(Source{URL: "https://skills.corp.internal"}, &skill.RegistryIndex{
+		Skills: []skill.RegistrySkill{
+			{Name: "web-search", Version: "2.0.0", Description: "internal fork"},
+			{Name: "web-search", Version: "2.1.0", Description: "internal only"},
+			{Name: "deploy", Version: "0.3.0"},
+		},
+	})
+
+	// The private registry is listed first, but the verified official
+	// entry still wins the name+version conflict.
+	merged := Merge(private, official)
+	if len(merged) != 4 {
+		t.Fatalf("got %d entries, want 4: %+v", len(merged), merged)
+	}
+	byKey := map[string]SkillEntry{}
+	for _, e := range merged {
+		byKey[e.Name+"@"+e.Version] = e
+	}
+	ws := byKey["web-search@2.0.0"]
+	if ws.Source != "official" || ws.Badge != BadgeVerified || ws.Description != "official build" {
+		t.Errorf("conflict resolved to %+v, want the verified official entry", ws)
+	}
+	if e := byKey["web-search@2.1.0"]; e.Source != "https://skills.corp.internal" || e.Badge != BadgeCommunity {
+		t.Errorf("distinct version should be kept from its source, got %+v", e)
+	}
+	if _, ok := byKey["deploy@0.3.0"]; !ok {
+		t.Error("private-only skill missing")
+	}
+	if _, ok := byKey["git-stats@1.0.0"]; !ok {
+		t.Error("official-only skill missing")
+	}
+}
+
+func TestMerge_EqualBadgesPreferEarlierSource(t *testing.T) {
+	a := []SkillEntry{{Name: "x", Version: "1", Badge: BadgeSigned, Source: "a"}}
+	b := []SkillEntry{{Name: "x", Version: "1", Badge: BadgeSigned, Source: "b"}}
+	merged := Merge(a, b)
+	if len(merged) != 1 || merged[0].Source != "a" {
+		t.Errorf("Merge = %+v, want the entry from a", merged)
+	}
+}
@@ -0,0 +1,71 @@
+package marketplace
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrOffRegistry is returned for entries whose manifest or bundle URL points
+// at a host other than the registry's. A compromised or malicious registry
+// index could otherwise send installs to an attacker-controlled server.
+var ErrOffRegistry = errors.New("entry points off-registry")
+
+// CheckOrigin verifies that an entry's ManifestURL and BundleURL are served
+// from the registry's own host or one of allowHosts. Relative URLs resolve
+// against the registry and always pass.
+func CheckOrigin(registryURL string, e SkillEntry, allowHosts []string) error {
+	reg, err := url.Parse(registryURL)
+	if err != nil || reg.Hostname() == "" {
+		return fmt.Errorf("invalid registry URL %q", registryURL)
+	}
+	for _, f := range []struct{ name, value string }{
+		{"manifest_url", e.ManifestURL},
+		{"bundle_url", e.BundleURL},
+	} {
+		if f.value == "" {
+			continue
+		}
+		u, err := url.Parse(f.value)
+		if err != nil {
+			return fmt.Errorf("skill %q: invalid %s %q: %w", e.Name, f.name, f.value, err)
+		}
+		host := u.Hostname()
+		if host == "" {
+			continue // relative to the registry
+		}
+		if !hostAllowed(host, reg.Hostname(), allowHosts) {
+			return fmt.Errorf("%w: skill %q %s host %q does not match registry host %q",
+				ErrOffRegistry, e.Name, f.name, host, reg.Hostname())
+		}
+	}
+	return nil
+}
+
+// FilterOnRegistry splits entries into those passing CheckOrigin and the
+// errors for those that do not.
+func FilterOnRegistry(registryURL string, entries []SkillEntry, allowHosts []string) ([]SkillEntry, []error) {
+	var kept []SkillEntry
+	var rejected []error
+	for _, e := range entries {
+		if err := CheckOrigin(registryURL, e, allowHosts); err != nil {
+			rejected = append(rejected, err)
+			continue
+		}
+		kept = append(kept, e)
+	}
+	return kept, rejected
+}
+
+func hostAllowed(host, registryHost string, allowHosts []string) bool {
+	if strings.EqualFold(host, registryHost) {
+		return true
+	}
+	for _, a := range allowHosts {
+		if strings.EqualFold(host, strings.TrimSpace(a)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/mackeh/AegisClaw/internal/guardrails"
+	"github.com/mackeh/AegisClaw/internal/security/redactor"
+)
+
+// ToolCapability is what a tool can do to the host, which decides whether
+// an assistant may call it.
+type ToolCapability string
+
+const (
+	// CapRead tools only inspect state and are always allowed.
+	CapRead ToolCapability = "read"
+	// CapWrite tools change AegisClaw state, e.g. policy or approvals.
+	CapWrite ToolCapability = "write"
+	// CapExec tools run skills or commands.
+	CapExec ToolCapability = "exec"
+)
+
+// maxAuditArgBytes caps the tool arguments copied into an audit entry.
+const maxAuditArgBytes = 2048
+
+// SetCapabilities enables the gated tool capabilities (mcp.capabilities);
+// read-only tools are callable regardless.
+func (s *Server) SetCapabilities(caps []string) error {
+	enabled := map[ToolCapability]bool{}
+	for _, c := range caps {
+		switch ToolCapability(c) {
+		case CapRead, CapWrite, CapExec:
+			enabled[ToolCapability(c)] = true
+		default:
+			return fmt.Errorf("unknown MCP tool capability %q (want read, write, or exec)", c)
+		}
+	}
+	s.enabled = enabled
+	return nil
+}
+
+// authorize reports whether the named tool may be called, and the
+// capability it needs. A tool declaring no capability is treated as exec,
+// so one added without thought is gated rather than open. Unknown tools
+// are left to the dispatcher to reject.
+func (s *Server) authorize(name string) (ToolCapability, bool) {
+	for _, t := range s.tools {
+		if t.Name != name {
+			continue
+		}
+		capability := t.Capability
+		if capability == "" {
+			capability = CapExec
+		}
+		return capability, capability == CapRead || s.enabled[capability]
+	}
+	return "", true
+}
+
+var argRedactor = redactor.NewWithPatterns(nil, guardrails.SecretPatterns())
+
+// auditArgs renders tool-call arguments for the audit log: credential-shaped
+// values redacted and the whole capped at maxAuditArgBytes.
+func auditArgs(args json.RawMessage) string {
+	if len(args) == 0 {
+		return ""
+	}
+	text := argRedactor.Redact(string(args))
+	if len(text) > maxAuditArgBytes {
+		text = text[:maxAuditArgBytes] + "…(truncated)"
+	}
+	return text
+}
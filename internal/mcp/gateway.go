@@ -43,6 +43,7 @@ type Gateway struct {
 
 	limiter     *rateLimiter
 	quarantined map[string]bool
+	client      string // name the client announced in initialize
 	mu          sync.Mutex
 }
 
@@ -88,6 +89,14 @@ func (g *Gateway) Run(ctx context.Context) error {
 func (g *Gateway) handleRequest(ctx context.Context, req request) response {
 	switch req.Method {
 	case "initialize":
+		var params struct {
+			ClientInfo struct {
+				Name string `json:"name"`
+			} `json:"clientInfo"`
+		}
+		if json.Unmarshal(req.Params, &params) == nil {
+			g.client = params.ClientInfo.Name
+		}
 		raw, err := g.Downstream.Initialize(ctx, req.Params)
 		if err != nil {
 			return response{JSONRPC: "2.0", ID: req.ID, Error: &rpcError{Code: -32603, Message: err.Error()}}
@@ -249,7 +258,8 @@ func (g *Gateway) audit(action, decision, tool string, detail map[string]any) {
 	if detail == nil {
 		detail = map[string]any{}
 	}
-	_ = g.Logger.Log(action, nil, decision, tool, detail)
+	detail["tool"] = tool
+	_ = g.Logger.LogAs(action, nil, decision, audit.MCPClientActor(g.client), detail)
 }
 
 func (g *Gateway) writeResponse(resp response) {
@@ -3,8 +3,10 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"path/filepath"
 	"testing"
 
+	"github.com/mackeh/AegisClaw/internal/audit"
 	"github.com/mackeh/AegisClaw/internal/policy"
 )
 
@@ -92,6 +94,39 @@ func TestGatewayPolicyDenyBlocks(t *testing.T) {
 	}
 }
 
+func TestGatewayAuditsCallsAsClient(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "gateway.log")
+	logger, err := audit.NewLogger(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+	g := NewGateway(&fakeDownstream{})
+	g.Policy = policyEngine(t, denyAll)
+	g.Logger = logger
+
+	g.handleRequest(context.Background(), request{
+		JSONRPC: "2.0", ID: json.RawMessage(`0`), Method: "initialize",
+		Params: json.RawMessage(`{"clientInfo":{"name":"cursor","version":"1.0"}}`),
+	})
+	g.handleRequest(context.Background(), callReq("rm_rf", `{}`))
+
+	entries, err := audit.ReadAll(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected one audit entry, got %d", len(entries))
+	}
+	e := entries[0]
+	if e.Action != "mcp.tool_call" || e.Decision != "deny" || e.Details["tool"] != "rm_rf" {
+		t.Errorf("entry = %+v", e)
+	}
+	if e.Identity == nil || *e.Identity != audit.MCPClientActor("cursor") {
+		t.Errorf("actor = %q / %+v, want the mcp-client cursor", e.Actor, e.Identity)
+	}
+}
+
 func TestGatewayRequireApprovalDeniesWithoutGrant(t *testing.T) {
 	down := &fakeDownstream{}
 	g := NewGateway(down)
@@ -0,0 +1,96 @@
+package mcp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// maxResultBytes caps the serialized items in one page of a list-type tool,
+// so a page of large entries cannot flood the assistant's context window.
+// A page always holds at least one item.
+var maxResultBytes = 64 << 10
+
+// pageArgs are the pagination arguments list-type tools accept.
+type pageArgs struct {
+	Cursor string `json:"cursor"`
+	Limit  int    `json:"limit"`
+}
+
+// encodeCursor makes an opaque cursor for offset. Clients pass it back
+// unchanged; its contents are not part of the tool contract.
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("o:" + strconv.Itoa(offset)))
+}
+
+// decodeCursor returns the offset in cursor; "" is offset 0.
+func decodeCursor(cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(raw) < 3 || string(raw[:2]) != "o:" {
+		return 0, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	n, err := strconv.Atoi(string(raw[2:]))
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	return n, nil
+}
+
+// pageForward returns up to limit items starting at the cursor, and the
+// cursor for the next page ("" on the last page).
+func pageForward[T any](items []T, cursor string, limit int) ([]T, string, error) {
+	offset, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	if offset >= len(items) {
+		return []T{}, "", nil
+	}
+	end := min(offset+limit, len(items))
+	page := items[offset:end]
+	page = page[:fitBytes(len(page), func(i int) T { return page[i] })]
+	if next := offset + len(page); next < len(items) {
+		return page, encodeCursor(next), nil
+	}
+	return page, "", nil
+}
+
+// pageBackward pages from the end of a chronological log: the first page is
+// the newest limit items, and each next page holds older ones. Items within
+// a page stay in chronological order.
+func pageBackward[T any](items []T, cursor string, limit int) ([]T, string, error) {
+	skip, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	if skip >= len(items) {
+		return []T{}, "", nil
+	}
+	end := len(items) - skip
+	page := items[max(0, end-limit):end]
+	// Keep the newest items that fit.
+	n := fitBytes(len(page), func(i int) T { return page[len(page)-1-i] })
+	page = page[len(page)-n:]
+	if next := skip + len(page); next < len(items) {
+		return page, encodeCursor(next), nil
+	}
+	return page, "", nil
+}
+
+// fitBytes reports how many of the n items, taken in the order at returns
+// them, fit in maxResultBytes (at least one when n > 0).
+func fitBytes[T any](n int, at func(int) T) int {
+	total := 0
+	for i := 0; i < n; i++ {
+		data, _ := json.Marshal(at(i))
+		total += len(data)
+		if total > maxResultBytes && i > 0 {
+			return i
+		}
+	}
+	return n
+}
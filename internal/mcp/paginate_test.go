@@ -0,0 +1,96 @@
+package mcp
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mackeh/AegisClaw/internal/audit"
+)
+
+// auditHome writes n audit entries, actors "a0".."a<n-1>", under a temp HOME.
+func auditHome(t *testing.T, n int) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	l, err := audit.NewLogger(filepath.Join(home, ".aegisclaw", "audit", "audit.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	for i := 0; i < n; i++ {
+		if err := l.Log("test", nil, "allow", "a"+string(rune('0'+i)), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestToolAuditQuery_PagesWithCursor(t *testing.T) {
+	auditHome(t, 5)
+	s := NewServer()
+
+	var actors []string
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > 5 {
+			t.Fatal("pagination did not terminate")
+		}
+		args, _ := json.Marshal(pageArgs{Cursor: cursor, Limit: 2})
+		res, err := s.toolAuditQuery(args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := res.(map[string]interface{})
+		var page []string
+		for _, e := range m["entries"].([]audit.Entry) {
+			page = append(page, e.Actor)
+		}
+		// Each page is chronological and holds older entries than the last.
+		actors = append(page, actors...)
+		next, ok := m["nextCursor"].(string)
+		if !ok {
+			break
+		}
+		cursor = next
+	}
+	if got := strings.Join(actors, ","); got != "a0,a1,a2,a3,a4" {
+		t.Errorf("paged entries = %s, want every entry once in order", got)
+	}
+
+	if _, err := s.toolAuditQuery(json.RawMessage(`{"cursor":"not-a-cursor"}`)); err == nil {
+		t.Error("an invalid cursor should be rejected")
+	}
+}
+
+func TestToolAuditQuery_CapsResultSize(t *testing.T) {
+	auditHome(t, 5)
+	orig := maxResultBytes
+	maxResultBytes = 1
+	t.Cleanup(func() { maxResultBytes = orig })
+
+	res, err := NewServer().toolAuditQuery(json.RawMessage(`{"limit":5}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := res.(map[string]interface{})
+	entries := m["entries"].([]audit.Entry)
+	if len(entries) != 1 || entries[0].Actor != "a4" {
+		t.Errorf("entries = %+v, want only the newest entry", entries)
+	}
+	if _, ok := m["nextCursor"]; !ok {
+		t.Error("a truncated page should return nextCursor")
+	}
+}
+
+func TestPageForward(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+	page, next, err := pageForward(items, "", 3)
+	if err != nil || len(page) != 3 || next == "" {
+		t.Fatalf("first page = %v, %q, %v", page, next, err)
+	}
+	page, next, err = pageForward(items, next, 3)
+	if err != nil || len(page) != 2 || page[0] != 4 || next != "" {
+		t.Errorf("last page = %v, %q, %v", page, next, err)
+	}
+}
@@ -0,0 +1,133 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mackeh/AegisClaw/internal/audit"
+)
+
+const (
+	// recentAuditResourceLimit is how many entries aegisclaw://audit/recent returns.
+	recentAuditResourceLimit = 50
+	// rpcResourceNotFound is the MCP error code for an unknown resource URI.
+	rpcResourceNotFound = -32002
+)
+
+// Resource describes an MCP resource: read-only context an assistant can
+// load without a tool call.
+type Resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	MimeType    string `json:"mimeType"`
+}
+
+// resourceEntry pairs an advertised resource with the function that reads it.
+type resourceEntry struct {
+	Resource
+	read func(s *Server) (interface{}, error)
+}
+
+// defaultResources returns the resources exposed by every server. Readers
+// reuse the equivalent tools so both paths return the same data.
+func defaultResources() []resourceEntry {
+	return []resourceEntry{
+		{
+			Resource: Resource{
+				URI:         "aegisclaw://audit/recent",
+				Name:        "Recent audit entries",
+				Description: fmt.Sprintf("The last %d entries of the hash-chained audit log", recentAuditResourceLimit),
+				MimeType:    "application/json",
+			},
+			read: func(s *Server) (interface{}, error) {
+				return s.toolAuditQuery(json.RawMessage(fmt.Sprintf(`{"limit":%d}`, recentAuditResourceLimit)))
+			},
+		},
+		{
+			Resource: Resource{
+				URI:         "aegisclaw://posture",
+				Name:        "Security posture",
+				Description: "Current security posture score with per-category breakdown",
+				MimeType:    "application/json",
+			},
+			read: func(s *Server) (interface{}, error) { return s.toolPosture() },
+		},
+		{
+			Resource: Resource{
+				URI:         "aegisclaw://skills",
+				Name:        "Installed skills",
+				Description: "Installed skills with their versions, scopes, and commands",
+				MimeType:    "application/json",
+			},
+			read: func(s *Server) (interface{}, error) { return s.toolListSkills(nil) },
+		},
+	}
+}
+
+// listResources answers resources/list.
+func (s *Server) listResources(req request) response {
+	list := make([]Resource, 0, len(s.resources))
+	for _, r := range s.resources {
+		list = append(list, r.Resource)
+	}
+	return response{JSONRPC: "2.0", ID: req.ID, Result: map[string]interface{}{"resources": list}}
+}
+
+// readResource answers resources/read. Reads share the tool-call rate limit
+// and are audited like tool calls.
+func (s *Server) readResource(req request) response {
+	var params struct {
+		URI string `json:"uri"`
+	}
+	if err := json.Unmarshal(req.Params, &params); err != nil || params.URI == "" {
+		return response{JSONRPC: "2.0", ID: req.ID, Error: &rpcError{Code: -32602, Message: "Invalid params: uri required"}}
+	}
+
+	var entry *resourceEntry
+	for i := range s.resources {
+		if s.resources[i].URI == params.URI {
+			entry = &s.resources[i]
+			break
+		}
+	}
+	if entry == nil {
+		return response{JSONRPC: "2.0", ID: req.ID, Error: &rpcError{Code: rpcResourceNotFound, Message: fmt.Sprintf("Resource not found: %s", params.URI)}}
+	}
+
+	if s.limiter != nil && !s.limiter.allow(time.Now()) {
+		s.logResourceRead(params.URI, "rate_limited", nil)
+		return response{JSONRPC: "2.0", ID: req.ID, Error: &rpcError{Code: rpcRateLimited, Message: "Rate limit exceeded: too many requests"}}
+	}
+
+	result, err := entry.read(s)
+	if err != nil {
+		s.logResourceRead(params.URI, "error", map[string]any{"error": err.Error()})
+		return response{JSONRPC: "2.0", ID: req.ID, Error: &rpcError{Code: -32603, Message: fmt.Sprintf("Failed to read %s: %v", params.URI, err)}}
+	}
+	s.logResourceRead(params.URI, "allow", nil)
+
+	text, _ := json.MarshalIndent(result, "", "  ")
+	return response{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Result: map[string]interface{}{
+			"contents": []map[string]interface{}{
+				{"uri": entry.URI, "mimeType": entry.MimeType, "text": string(text)},
+			},
+		},
+	}
+}
+
+// logResourceRead records a resource read to the audit log, if available.
+func (s *Server) logResourceRead(uri, decision string, detail map[string]any) {
+	if s.logger == nil {
+		return
+	}
+	if detail == nil {
+		detail = map[string]any{}
+	}
+	detail["uri"] = uri
+	_ = s.logger.LogAs("mcp.resource_read", nil, decision, audit.MCPClientActor(s.client), detail)
+}
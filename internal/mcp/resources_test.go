@@ -0,0 +1,98 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHandleRequest_ResourcesList(t *testing.T) {
+	s := NewServer()
+	resp := s.handleRequest(context.Background(), request{JSONRPC: "2.0", ID: json.RawMessage(`1`), Method: "resources/list"})
+	if resp.Error != nil {
+		t.Fatalf("unexpected error: %s", resp.Error.Message)
+	}
+
+	resources := resp.Result.(map[string]interface{})["resources"].([]Resource)
+	want := map[string]bool{"aegisclaw://audit/recent": false, "aegisclaw://posture": false, "aegisclaw://skills": false}
+	for _, r := range resources {
+		if _, ok := want[r.URI]; ok {
+			want[r.URI] = true
+		}
+		if r.Name == "" || r.MimeType == "" {
+			t.Errorf("resource %s missing name or mime type", r.URI)
+		}
+	}
+	for uri, found := range want {
+		if !found {
+			t.Errorf("resource %s not listed", uri)
+		}
+	}
+}
+
+func TestHandleRequest_InitializeAdvertisesResources(t *testing.T) {
+	s := NewServer()
+	resp := s.handleRequest(context.Background(), request{JSONRPC: "2.0", ID: json.RawMessage(`1`), Method: "initialize"})
+	caps := resp.Result.(map[string]interface{})["capabilities"].(map[string]interface{})
+	if _, ok := caps["resources"]; !ok {
+		t.Error("initialize should advertise the resources capability")
+	}
+}
+
+func TestHandleRequest_ResourcesReadPosture(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	cfgDir := filepath.Join(home, ".aegisclaw")
+	if err := os.MkdirAll(cfgDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	cfg := "security:\n  sandbox_backend: docker\n  require_approval: true\n"
+	if err := os.WriteFile(filepath.Join(cfgDir, "config.yaml"), []byte(cfg), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewServer()
+	resp := s.handleRequest(context.Background(), request{
+		JSONRPC: "2.0",
+		ID:      json.RawMessage(`2`),
+		Method:  "resources/read",
+		Params:  json.RawMessage(`{"uri":"aegisclaw://posture"}`),
+	})
+	if resp.Error != nil {
+		t.Fatalf("unexpected error: %s", resp.Error.Message)
+	}
+
+	contents := resp.Result.(map[string]interface{})["contents"].([]map[string]interface{})
+	if len(contents) != 1 || contents[0]["uri"] != "aegisclaw://posture" {
+		t.Fatalf("unexpected contents: %v", contents)
+	}
+	var score struct {
+		Max        int           `json:"max"`
+		Categories []interface{} `json:"categories"`
+	}
+	if err := json.Unmarshal([]byte(contents[0]["text"].(string)), &score); err != nil {
+		t.Fatalf("posture resource is not JSON: %v", err)
+	}
+	if score.Max == 0 || len(score.Categories) == 0 {
+		t.Errorf("expected a scored posture, got %s", contents[0]["text"])
+	}
+}
+
+func TestHandleRequest_ResourcesReadUnknown(t *testing.T) {
+	s := NewServer()
+	resp := s.handleRequest(context.Background(), request{
+		JSONRPC: "2.0",
+		ID:      json.RawMessage(`3`),
+		Method:  "resources/read",
+		Params:  json.RawMessage(`{"uri":"aegisclaw://nope"}`),
+	})
+	if resp.Error == nil || resp.Error.Code != rpcResourceNotFound {
+		t.Fatalf("expected resource-not-found error, got %+v", resp.Error)
+	}
+	if !strings.Contains(resp.Error.Message, "aegisclaw://nope") {
+		t.Errorf("error should name the URI: %s", resp.Error.Message)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"time"
 
 	"github.com/mackeh/AegisClaw/internal/audit"
@@ -26,6 +27,9 @@ const (
 	defaultMCPRateLimitPerMin = 120
 	// maxAuditQueryLimit caps how many audit entries a single query returns.
 	maxAuditQueryLimit = 1000
+	// defaultSkillPageLimit is how many skills one list call returns unless
+	// the caller asks for a different page size.
+	defaultSkillPageLimit = 100
 	// rpcRateLimited is the JSON-RPC error code for a throttled request
 	// (within the -32000..-32099 server-error range).
 	rpcRateLimited = -32000
@@ -56,31 +60,50 @@ type Tool struct {
 	Name        string      `json:"name"`
 	Description string      `json:"description"`
 	InputSchema interface{} `json:"inputSchema"`
+	// Capability gates calls to the tool; see authorize. It is not part of
+	// the MCP tool description.
+	Capability ToolCapability `json:"-"`
 }
 
 // Server implements the MCP stdio protocol.
 type Server struct {
-	tools   []Tool
-	limiter *rateLimiter
-	logger  *audit.Logger // tamper-evident log of tool calls; nil if unavailable
+	tools     []Tool
+	resources []resourceEntry
+	limiter   *rateLimiter
+	logger    *audit.Logger           // tamper-evident log of tool calls; nil if unavailable
+	trace     *traceLog               // optional request trace file; nil unless SetLogFile
+	enabled   map[ToolCapability]bool // gated capabilities turned on by SetCapabilities
+	client    string                  // name the client announced in initialize
 }
 
 // NewServer creates an MCP server with AegisClaw tools.
 func NewServer() *Server {
 	return &Server{
-		limiter: newRateLimiter(defaultMCPRateLimitPerMin, time.Minute),
+		limiter:   newRateLimiter(defaultMCPRateLimitPerMin, time.Minute),
+		resources: defaultResources(),
 		tools: []Tool{
 			{
 				Name:        "aegisclaw_list_skills",
-				Description: "List all installed AegisClaw skills",
+				Description: "List installed AegisClaw skills by name, one page at a time",
+				Capability:  CapRead,
 				InputSchema: map[string]interface{}{
-					"type":       "object",
-					"properties": map[string]interface{}{},
+					"type": "object",
+					"properties": map[string]interface{}{
+						"limit": map[string]interface{}{
+							"type":        "number",
+							"description": "Maximum number of skills to return",
+						},
+						"cursor": map[string]interface{}{
+							"type":        "string",
+							"description": "Cursor from a previous call's nextCursor, to fetch the next page",
+						},
+					},
 				},
 			},
 			{
 				Name:        "aegisclaw_audit_query",
-				Description: "Query AegisClaw audit log entries",
+				Description: "Query AegisClaw audit log entries, newest page first; pass nextCursor back to page through older entries",
+				Capability:  CapRead,
 				InputSchema: map[string]interface{}{
 					"type": "object",
 					"properties": map[string]interface{}{
@@ -88,12 +111,17 @@ func NewServer() *Server {
 							"type":        "number",
 							"description": "Maximum number of entries to return",
 						},
+						"cursor": map[string]interface{}{
+							"type":        "string",
+							"description": "Cursor from a previous call's nextCursor, to fetch the next page",
+						},
 					},
 				},
 			},
 			{
 				Name:        "aegisclaw_posture",
 				Description: "Get the current AegisClaw security posture score",
+				Capability:  CapRead,
 				InputSchema: map[string]interface{}{
 					"type":       "object",
 					"properties": map[string]interface{}{},
@@ -102,6 +130,7 @@ func NewServer() *Server {
 			{
 				Name:        "aegisclaw_verify_logs",
 				Description: "Verify the integrity of the AegisClaw audit log hash chain",
+				Capability:  CapRead,
 				InputSchema: map[string]interface{}{
 					"type":       "object",
 					"properties": map[string]interface{}{},
@@ -110,6 +139,7 @@ func NewServer() *Server {
 			{
 				Name:        "aegisclaw_compliance",
 				Description: "Assess AegisClaw against the OWASP Top 10 for Agentic Applications (ASI01-ASI10). Returns per-control coverage, scores, and gaps.",
+				Capability:  CapRead,
 				InputSchema: map[string]interface{}{
 					"type":       "object",
 					"properties": map[string]interface{}{},
@@ -118,6 +148,7 @@ func NewServer() *Server {
 			{
 				Name:        "aegisclaw_compliance_report",
 				Description: "Generate a full compliance report combining OWASP ASI assessment, audit trail summary, and policy evaluation history",
+				Capability:  CapRead,
 				InputSchema: map[string]interface{}{
 					"type":       "object",
 					"properties": map[string]interface{}{},
@@ -126,6 +157,7 @@ func NewServer() *Server {
 			{
 				Name:        "aegisclaw_lineage",
 				Description: "Query data lineage records tracking inputs, outputs, secrets accessed, and API calls for skill executions",
+				Capability:  CapRead,
 				InputSchema: map[string]interface{}{
 					"type": "object",
 					"properties": map[string]interface{}{
@@ -141,6 +173,10 @@ func NewServer() *Server {
 							"type":        "number",
 							"description": "Maximum number of records to return",
 						},
+						"cursor": map[string]interface{}{
+							"type":        "string",
+							"description": "Cursor from a previous call's nextCursor, to fetch the next page",
+						},
 					},
 				},
 			},
@@ -169,15 +205,20 @@ func openMCPAuditLogger() (*audit.Logger, error) {
 	return audit.NewLogger(filepath.Join(auditDir, "mcp.log"))
 }
 
-// logToolCall records an MCP tool invocation to the audit log, if available.
-func (s *Server) logToolCall(tool, decision string, detail map[string]any) {
+// logToolCall records an MCP tool invocation to the audit log, if
+// available, attributed to the connected client. Arguments are redacted.
+func (s *Server) logToolCall(tool, decision string, args json.RawMessage, detail map[string]any) {
 	if s.logger == nil || tool == "" {
 		return
 	}
 	if detail == nil {
 		detail = map[string]any{}
 	}
-	_ = s.logger.Log("mcp.tool_call", nil, decision, tool, detail)
+	detail["tool"] = tool
+	if a := auditArgs(args); a != "" {
+		detail["arguments"] = a
+	}
+	_ = s.logger.LogAs("mcp.tool_call", nil, decision, audit.MCPClientActor(s.client), detail)
 }
 
 // Run starts the MCP server on stdio, reading JSON-RPC requests and writing responses.
@@ -203,7 +244,7 @@ func (s *Server) Run(ctx context.Context) error {
 			continue
 		}
 
-		resp := s.handleRequest(ctx, req)
+		resp := s.dispatch(ctx, req)
 		s.writeResponse(resp)
 	}
 
@@ -213,13 +254,22 @@ func (s *Server) Run(ctx context.Context) error {
 func (s *Server) handleRequest(ctx context.Context, req request) response {
 	switch req.Method {
 	case "initialize":
+		var params struct {
+			ClientInfo struct {
+				Name string `json:"name"`
+			} `json:"clientInfo"`
+		}
+		if json.Unmarshal(req.Params, &params) == nil {
+			s.client = params.ClientInfo.Name
+		}
 		return response{
 			JSONRPC: "2.0",
 			ID:      req.ID,
 			Result: map[string]interface{}{
 				"protocolVersion": "2024-11-05",
 				"capabilities": map[string]interface{}{
-					"tools": map[string]interface{}{},
+					"tools":     map[string]interface{}{},
+					"resources": map[string]interface{}{},
 				},
 				"serverInfo": map[string]interface{}{
 					"name":    "aegisclaw",
@@ -240,6 +290,12 @@ func (s *Server) handleRequest(ctx context.Context, req request) response {
 	case "tools/call":
 		return s.handleToolCall(ctx, req)
 
+	case "resources/list":
+		return s.listResources(req)
+
+	case "resources/read":
+		return s.readResource(req)
+
 	default:
 		return response{
 			JSONRPC: "2.0",
@@ -263,16 +319,30 @@ func (s *Server) handleToolCall(ctx context.Context, req request) response {
 	}
 
 	if s.limiter != nil && !s.limiter.allow(time.Now()) {
-		s.logToolCall(params.Name, "rate_limited", nil)
+		s.logToolCall(params.Name, "rate_limited", params.Arguments, nil)
 		return response{JSONRPC: "2.0", ID: req.ID, Error: &rpcError{Code: rpcRateLimited, Message: "Rate limit exceeded: too many tool calls"}}
 	}
 
+	if capability, ok := s.authorize(params.Name); !ok {
+		s.logToolCall(params.Name, "deny", params.Arguments, map[string]any{"reason": "capability disabled", "capability": string(capability)})
+		return response{
+			JSONRPC: "2.0",
+			ID:      req.ID,
+			Result: map[string]interface{}{
+				"content": []map[string]interface{}{
+					{"type": "text", "text": fmt.Sprintf("⛔ AegisClaw blocked this call: tool %q needs the %q capability, which is disabled. Enable it with mcp.capabilities in config.yaml.", params.Name, capability)},
+				},
+				"isError": true,
+			},
+		}
+	}
+
 	var result interface{}
 	var err error
 
 	switch params.Name {
 	case "aegisclaw_list_skills":
-		result, err = s.toolListSkills()
+		result, err = s.toolListSkills(params.Arguments)
 	case "aegisclaw_audit_query":
 		result, err = s.toolAuditQuery(params.Arguments)
 	case "aegisclaw_posture":
@@ -286,12 +356,12 @@ func (s *Server) handleToolCall(ctx context.Context, req request) response {
 	case "aegisclaw_lineage":
 		result, err = s.toolLineage(params.Arguments)
 	default:
-		s.logToolCall(params.Name, "unknown_tool", nil)
+		s.logToolCall(params.Name, "unknown_tool", params.Arguments, nil)
 		return response{JSONRPC: "2.0", ID: req.ID, Error: &rpcError{Code: -32602, Message: fmt.Sprintf("Unknown tool: %s", params.Name)}}
 	}
 
 	if err != nil {
-		s.logToolCall(params.Name, "error", map[string]any{"error": err.Error()})
+		s.logToolCall(params.Name, "error", params.Arguments, map[string]any{"error": err.Error()})
 		return response{
 			JSONRPC: "2.0",
 			ID:      req.ID,
@@ -304,7 +374,7 @@ func (s *Server) handleToolCall(ctx context.Context, req request) response {
 		}
 	}
 
-	s.logToolCall(params.Name, "allow", nil)
+	s.logToolCall(params.Name, "allow", params.Arguments, nil)
 
 	text, _ := json.MarshalIndent(result, "", "  ")
 	return response{
@@ -318,15 +388,24 @@ func (s *Server) handleToolCall(ctx context.Context, req request) response {
 	}
 }
 
-func (s *Server) toolListSkills() (interface{}, error) {
+func (s *Server) toolListSkills(args json.RawMessage) (interface{}, error) {
+	var params pageArgs
+	if len(args) > 0 {
+		json.Unmarshal(args, &params)
+	}
+	if params.Limit <= 0 {
+		params.Limit = defaultSkillPageLimit
+	}
+	if params.Limit > maxAuditQueryLimit {
+		params.Limit = maxAuditQueryLimit
+	}
+
 	cfgDir, err := config.DefaultConfigDir()
 	if err != nil {
 		return nil, err
 	}
 
-	manifests, _ := skill.ListSkills(filepath.Join(cfgDir, "skills"))
-	localManifests, _ := skill.ListSkills("skills")
-	manifests = append(manifests, localManifests...)
+	manifests := skill.LoadSkills(skill.SearchPaths(cfgDir)...)
 
 	type skillInfo struct {
 		Name        string   `json:"name"`
@@ -336,12 +415,20 @@ func (s *Server) toolListSkills() (interface{}, error) {
 		Commands    []string `json:"commands"`
 	}
 
-	var skills []skillInfo
+	// Sorted so a cursor means the same position on every call.
+	sort.SliceStable(manifests, func(i, j int) bool { return manifests[i].Name < manifests[j].Name })
+	manifests, next, err := pageForward(manifests, params.Cursor, params.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	skills := []skillInfo{}
 	for _, m := range manifests {
 		var cmds []string
 		for name := range m.Commands {
 			cmds = append(cmds, name)
 		}
+		sort.Strings(cmds)
 		skills = append(skills, skillInfo{
 			Name:        m.Name,
 			Version:     m.Version,
@@ -351,13 +438,11 @@ func (s *Server) toolListSkills() (interface{}, error) {
 		})
 	}
 
-	return map[string]interface{}{"skills": skills, "count": len(skills)}, nil
+	return withNextCursor(map[string]interface{}{"skills": skills, "count": len(skills)}, next), nil
 }
 
 func (s *Server) toolAuditQuery(args json.RawMessage) (interface{}, error) {
-	var params struct {
-		Limit int `json:"limit"`
-	}
+	var params pageArgs
 	if len(args) > 0 {
 		json.Unmarshal(args, &params)
 	}
@@ -379,12 +464,11 @@ func (s *Server) toolAuditQuery(args json.RawMessage) (interface{}, error) {
 		return nil, err
 	}
 
-	// Return last N entries
-	if len(entries) > params.Limit {
-		entries = entries[len(entries)-params.Limit:]
+	entries, next, err := pageBackward(entries, params.Cursor, params.Limit)
+	if err != nil {
+		return nil, err
 	}
-
-	return map[string]interface{}{"entries": entries, "total": len(entries)}, nil
+	return withNextCursor(map[string]interface{}{"entries": entries, "total": len(entries)}, next), nil
 }
 
 func (s *Server) toolPosture() (interface{}, error) {
@@ -440,6 +524,7 @@ func (s *Server) toolLineage(args json.RawMessage) (interface{}, error) {
 		Skill       string `json:"skill"`
 		ExecutionID string `json:"execution_id"`
 		Limit       int    `json:"limit"`
+		Cursor      string `json:"cursor"`
 	}
 	if len(args) > 0 {
 		json.Unmarshal(args, &params)
@@ -469,11 +554,19 @@ func (s *Server) toolLineage(args json.RawMessage) (interface{}, error) {
 		return nil, err
 	}
 
-	if len(records) > params.Limit {
-		records = records[len(records)-params.Limit:]
+	records, next, err := pageBackward(records, params.Cursor, params.Limit)
+	if err != nil {
+		return nil, err
 	}
+	return withNextCursor(map[string]interface{}{"records": records, "total": len(records)}, next), nil
+}
 
-	return map[string]interface{}{"records": records, "total": len(records)}, nil
+// withNextCursor adds nextCursor to a list result when more pages remain.
+func withNextCursor(result map[string]interface{}, next string) map[string]interface{} {
+	if next != "" {
+		result["nextCursor"] = next
+	}
+	return result
 }
 
 func (s *Server) writeResponse(resp response) {
@@ -0,0 +1,113 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// traceEntry is one line of the MCP request trace log.
+type traceEntry struct {
+	Time       time.Time `json:"time"`
+	Seq        uint64    `json:"seq"`
+	Method     string    `json:"method"`
+	Tool       string    `json:"tool,omitempty"`
+	DurationMS float64   `json:"duration_ms"`
+	Error      string    `json:"error,omitempty"`
+	ToolCalls  uint64    `json:"tool_calls,omitempty"`  // calls to this tool so far
+	ToolAvgMS  float64   `json:"tool_avg_ms,omitempty"` // mean latency of this tool so far
+}
+
+type toolStat struct {
+	calls uint64
+	total time.Duration
+}
+
+// traceLog writes request/response traces to a file. It must never touch
+// stdout or stderr: stdout is the JSON-RPC channel.
+type traceLog struct {
+	mu    sync.Mutex
+	w     io.WriteCloser
+	seq   uint64
+	tools map[string]*toolStat
+}
+
+// SetLogFile enables request tracing to path (JSON lines, appended). Each
+// line records the method, tool, latency, error, a request counter, and the
+// running per-tool mean latency.
+func (s *Server) SetLogFile(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open MCP log file: %w", err)
+	}
+	if s.trace != nil {
+		s.trace.close()
+	}
+	s.trace = &traceLog{w: f, tools: make(map[string]*toolStat)}
+	return nil
+}
+
+// dispatch handles a request and, when tracing is enabled, records it.
+func (s *Server) dispatch(ctx context.Context, req request) response {
+	if s.trace == nil {
+		return s.handleRequest(ctx, req)
+	}
+	start := time.Now()
+	resp := s.handleRequest(ctx, req)
+	s.trace.record(req, resp, time.Since(start))
+	return resp
+}
+
+func (t *traceLog) record(req request, resp response, d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.seq++
+	e := traceEntry{
+		Time:       time.Now().UTC(),
+		Seq:        t.seq,
+		Method:     req.Method,
+		DurationMS: float64(d.Microseconds()) / 1000,
+	}
+	if resp.Error != nil {
+		e.Error = resp.Error.Message
+	}
+	if req.Method == "tools/call" {
+		var p struct {
+			Name string `json:"name"`
+		}
+		_ = json.Unmarshal(req.Params, &p)
+		e.Tool = p.Name
+		if p.Name != "" {
+			st, ok := t.tools[p.Name]
+			if !ok {
+				st = &toolStat{}
+				t.tools[p.Name] = st
+			}
+			st.calls++
+			st.total += d
+			e.ToolCalls = st.calls
+			e.ToolAvgMS = float64((st.total / time.Duration(st.calls)).Microseconds()) / 1000
+		}
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	_, _ = t.w.Write(append(data, '\n'))
+}
+
+func (t *traceLog) close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_ = t.w.Close()
+}
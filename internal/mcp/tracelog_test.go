@@ -0,0 +1,49 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetLogFile_RecordsRequests(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mcp.log")
+	s := NewServer()
+	if err := s.SetLogFile(path); err != nil {
+		t.Fatalf("SetLogFile: %v", err)
+	}
+
+	s.dispatch(context.Background(), request{JSONRPC: "2.0", ID: json.RawMessage(`1`), Method: "tools/list"})
+	params, _ := json.Marshal(map[string]any{"name": "nonexistent_tool", "arguments": map[string]any{}})
+	s.dispatch(context.Background(), request{JSONRPC: "2.0", ID: json.RawMessage(`2`), Method: "tools/call", Params: params})
+	s.trace.close()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var entries []traceEntry
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var e traceEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			t.Fatalf("bad trace line %q: %v", sc.Text(), err)
+		}
+		entries = append(entries, e)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 trace entries, got %d", len(entries))
+	}
+	if entries[0].Method != "tools/list" || entries[0].Seq != 1 {
+		t.Errorf("unexpected first entry: %+v", entries[0])
+	}
+	call := entries[1]
+	if call.Tool != "nonexistent_tool" || call.Seq != 2 || call.ToolCalls != 1 || call.Error == "" {
+		t.Errorf("unexpected tool call entry: %+v", call)
+	}
+}
@@ -0,0 +1,161 @@
+package openclaw
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Circuit breaker states.
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half-open"
+)
+
+// Breaker defaults used for the shared per-endpoint breakers.
+const (
+	DefaultFailureThreshold = 5
+	DefaultBreakerCooldown  = 30 * time.Second
+)
+
+// ErrCircuitOpen is returned when a call is short-circuited by an open breaker.
+var ErrCircuitOpen = errors.New("openclaw circuit breaker is open")
+
+// Breaker is a consecutive-failure circuit breaker. After threshold failures
+// in a row it opens and rejects calls until cooldown has elapsed; it then
+// half-opens and lets a single probe through. A successful probe closes the
+// breaker, a failed one re-opens it for another cooldown.
+type Breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	state     string
+	openedAt  time.Time
+	probing   bool
+	now       func() time.Time
+}
+
+// NewBreaker creates a closed breaker.
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	if threshold <= 0 {
+		threshold = DefaultFailureThreshold
+	}
+	if cooldown <= 0 {
+		cooldown = DefaultBreakerCooldown
+	}
+	return &Breaker{threshold: threshold, cooldown: cooldown, state: BreakerClosed, now: time.Now}
+}
+
+// Allow reports whether a call may proceed. It returns ErrCircuitOpen while
+// the breaker is open or while a half-open probe is already in flight.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.advance()
+	switch b.state {
+	case BreakerOpen:
+		return ErrCircuitOpen
+	case BreakerHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// Success records a successful call and closes the breaker.
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.probing = false
+	b.state = BreakerClosed
+}
+
+// Failure records a failed call, opening the breaker once the threshold is
+// reached or immediately if the failing call was a half-open probe.
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		b.state = BreakerOpen
+		b.openedAt = b.now()
+		b.probing = false
+	}
+}
+
+// Release ends a call that neither succeeded nor failed, such as one the
+// caller cancelled: the breaker state is unchanged, but a half-open breaker
+// may send another probe.
+func (b *Breaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// State returns the current breaker state.
+func (b *Breaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.advance()
+	return b.state
+}
+
+// advance moves an open breaker to half-open once its cooldown has elapsed.
+// Callers must hold b.mu.
+func (b *Breaker) advance() {
+	if b.state == BreakerOpen && b.now().Sub(b.openedAt) >= b.cooldown {
+		b.state = BreakerHalfOpen
+		b.probing = false
+	}
+}
+
+var (
+	breakersMu sync.Mutex
+	breakers   = map[string]*Breaker{}
+
+	// sharedTransport pools connections across every Client so repeated
+	// calls to the same endpoint reuse keep-alive connections.
+	sharedTransport = func() *http.Transport {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.MaxIdleConns = 100
+		t.MaxIdleConnsPerHost = 10
+		t.IdleConnTimeout = 90 * time.Second
+		t.DialContext = (&net.Dialer{Timeout: 5 * time.Second, KeepAlive: 30 * time.Second}).DialContext
+		return t
+	}()
+)
+
+// breakerFor returns the shared breaker for an endpoint, creating it on first use.
+func breakerFor(endpoint string) *Breaker {
+	key := strings.TrimRight(strings.TrimSpace(endpoint), "/")
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+	b, ok := breakers[key]
+	if !ok {
+		b = NewBreaker(DefaultFailureThreshold, DefaultBreakerCooldown)
+		breakers[key] = b
+	}
+	return b
+}
+
+// BreakerState reports the shared breaker state for an endpoint. Endpoints
+// that have never been called report closed.
+func BreakerState(endpoint string) string {
+	key := strings.TrimRight(strings.TrimSpace(endpoint), "/")
+	breakersMu.Lock()
+	b, ok := breakers[key]
+	breakersMu.Unlock()
+	if !ok {
+		return BreakerClosed
+	}
+	return b.State()
+}
@@ -0,0 +1,163 @@
+package openclaw
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBreaker_OpensAndHalfOpensAfterCooldown(t *testing.T) {
+	now := time.Unix(1000, 0)
+	b := NewBreaker(3, 10*time.Second)
+	b.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if err := b.Allow(); err != nil {
+			t.Fatalf("call %d rejected before threshold: %v", i, err)
+		}
+		b.Failure()
+	}
+	if got := b.State(); got != BreakerOpen {
+		t.Fatalf("state = %s, want open", got)
+	}
+	if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Allow() = %v, want ErrCircuitOpen", err)
+	}
+
+	now = now.Add(10 * time.Second)
+	if got := b.State(); got != BreakerHalfOpen {
+		t.Fatalf("state after cooldown = %s, want half-open", got)
+	}
+	if err := b.Allow(); err != nil {
+		t.Fatalf("probe rejected: %v", err)
+	}
+	if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatal("second concurrent probe should be rejected")
+	}
+
+	// Failed probe re-opens immediately.
+	b.Failure()
+	if got := b.State(); got != BreakerOpen {
+		t.Fatalf("state after failed probe = %s, want open", got)
+	}
+
+	now = now.Add(10 * time.Second)
+	_ = b.Allow()
+	b.Success()
+	if got := b.State(); got != BreakerClosed {
+		t.Fatalf("state after successful probe = %s, want closed", got)
+	}
+}
+
+func TestClient_RepeatedFailuresShortCircuit(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	c, err := NewClientWithKey(AdapterConfig{Enabled: true, Endpoint: srv.URL}, "k")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < DefaultFailureThreshold; i++ {
+		if _, err := c.Do(context.Background(), http.MethodGet, "/", nil); err != nil {
+			t.Fatalf("call %d: %v", i, err)
+		}
+	}
+	if _, err := c.Do(context.Background(), http.MethodGet, "/", nil); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if got := atomic.LoadInt32(&hits); got != DefaultFailureThreshold {
+		t.Errorf("server hit %d times, want %d", got, DefaultFailureThreshold)
+	}
+
+	// Health reports degraded without probing the failing endpoint.
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "adapters"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	cfg := "enabled: true\nendpoint: " + srv.URL + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "adapters", "openclaw.yaml"), []byte(cfg), 0600); err != nil {
+		t.Fatal(err)
+	}
+	h := CheckHealth(dir)
+	if h.Status != StatusDegraded || h.Breaker != BreakerOpen {
+		t.Errorf("health = %s/%s, want degraded/open", h.Status, h.Breaker)
+	}
+}
+
+// halfOpenClient returns a client for srv whose breaker has opened on one
+// failure and is now half-open.
+func halfOpenClient(t *testing.T, srv *httptest.Server) (*Client, *Breaker) {
+	t.Helper()
+	c, err := NewClientWithKey(AdapterConfig{Enabled: true, Endpoint: srv.URL}, "k")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1000, 0)
+	b := NewBreaker(1, 10*time.Second)
+	b.now = func() time.Time { return now }
+	_ = b.Allow()
+	b.Failure()
+	now = now.Add(10 * time.Second)
+	if got := b.State(); got != BreakerHalfOpen {
+		t.Fatalf("state = %s, want half-open", got)
+	}
+	c.breaker = b
+	return c, b
+}
+
+func TestClient_MalformedRequestReleasesProbe(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	c, b := halfOpenClient(t, srv)
+
+	if _, err := c.Do(context.Background(), "BAD METHOD", "/", nil); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("malformed request: err = %v, want a request error", err)
+	}
+	if _, err := c.Do(context.Background(), http.MethodGet, "/", nil); err != nil {
+		t.Fatalf("probe after a malformed request: %v", err)
+	}
+	if got := b.State(); got != BreakerClosed {
+		t.Errorf("state = %s, want closed after a successful probe", got)
+	}
+}
+
+func TestClient_CallerCancellationIsNotAFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+	c, b := halfOpenClient(t, srv)
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.Do(cancelled, http.MethodGet, "/", nil); err == nil {
+		t.Fatal("expected an error for a cancelled context")
+	}
+	timeout, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := c.Do(timeout, http.MethodGet, "/", nil); errors.Is(err, ErrCircuitOpen) || err == nil {
+		t.Fatalf("timed-out probe: err = %v", err)
+	}
+	if got := b.State(); got != BreakerHalfOpen {
+		t.Errorf("state = %s, want half-open: the caller gave up, not the endpoint", got)
+	}
+
+	closed := NewBreaker(1, 10*time.Second)
+	c.breaker = closed
+	cancelled, cancel = context.WithCancel(context.Background())
+	cancel()
+	_, _ = c.Do(cancelled, http.MethodGet, "/", nil)
+	if got := closed.State(); got != BreakerClosed {
+		t.Errorf("state = %s, want closed after a cancelled call", got)
+	}
+}
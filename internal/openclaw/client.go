@@ -0,0 +1,173 @@
+package openclaw
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mackeh/AegisClaw/internal/guardrails"
+	"github.com/mackeh/AegisClaw/internal/secrets"
+	"github.com/mackeh/AegisClaw/internal/security/redactor"
+	"gopkg.in/yaml.v3"
+)
+
+// maxResponseBytes caps how much of an OpenClaw response the client buffers
+// for guardrail scanning and redaction.
+const maxResponseBytes = 8 << 20 // 8MB
+
+// ErrResponseBlocked is returned by Do in block mode when guardrails withhold
+// a response; the returned Response still carries the violations.
+var ErrResponseBlocked = errors.New("guardrails blocked OpenClaw response")
+
+// Response is an OpenClaw reply after guardrail scanning and redaction.
+type Response struct {
+	StatusCode int                    `json:"status_code"`
+	Body       string                 `json:"body"`
+	Violations []guardrails.Violation `json:"violations,omitempty"`
+}
+
+// Client forwards agent requests to the configured OpenClaw endpoint. It
+// authenticates with the API key resolved from the secret store and passes
+// every response through guardrails (indirect prompt injection) and the
+// redactor (credential scrubbing) before returning it.
+type Client struct {
+	Endpoint string
+	// GuardMode is "off", "warn" (default), or "block". In block mode a
+	// response carrying a critical/high violation is withheld.
+	GuardMode string
+
+	apiKey   string
+	http     *http.Client
+	breaker  *Breaker
+	guard    *guardrails.Engine
+	redactor *redactor.Redactor
+}
+
+// LoadAdapterConfig reads ~/.aegisclaw/adapters/openclaw.yaml from cfgDir.
+func LoadAdapterConfig(cfgDir string) (AdapterConfig, error) {
+	data, err := os.ReadFile(filepath.Join(cfgDir, "adapters", "openclaw.yaml"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return AdapterConfig{}, fmt.Errorf("openclaw adapter config not found")
+		}
+		return AdapterConfig{}, fmt.Errorf("failed to read adapter config: %w", err)
+	}
+	var cfg AdapterConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return AdapterConfig{}, fmt.Errorf("invalid adapter config: %w", err)
+	}
+	return cfg, nil
+}
+
+// NewClient builds a Client from the adapter config in cfgDir, resolving the
+// API key from the encrypted secret store.
+func NewClient(cfgDir string) (*Client, error) {
+	cfg, err := LoadAdapterConfig(cfgDir)
+	if err != nil {
+		return nil, err
+	}
+	secretName := strings.TrimSpace(cfg.APIKeySecret)
+	if secretName == "" {
+		return nil, fmt.Errorf("openclaw adapter: api_key_secret is not configured")
+	}
+	apiKey, err := secrets.NewManager(filepath.Join(cfgDir, "secrets")).Get(secretName)
+	if err != nil {
+		return nil, fmt.Errorf("openclaw adapter: failed to resolve API key %q: %w", secretName, err)
+	}
+	return NewClientWithKey(cfg, apiKey)
+}
+
+// NewClientWithKey builds a Client from an already-resolved API key.
+func NewClientWithKey(cfg AdapterConfig, apiKey string) (*Client, error) {
+	if !cfg.Enabled {
+		return nil, fmt.Errorf("openclaw adapter is disabled")
+	}
+	endpoint := strings.TrimRight(strings.TrimSpace(cfg.Endpoint), "/")
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("invalid endpoint URL: %q", cfg.Endpoint)
+	}
+	timeout := 30 * time.Second
+	if cfg.TimeoutMS > 0 {
+		timeout = time.Duration(cfg.TimeoutMS) * time.Millisecond
+	}
+	return &Client{
+		Endpoint:  endpoint,
+		GuardMode: "warn",
+		apiKey:    apiKey,
+		http:      &http.Client{Timeout: timeout, Transport: sharedTransport},
+		breaker:   breakerFor(endpoint),
+		guard:     guardrails.NewEngine(),
+		redactor:  redactor.NewWithPatterns([]string{apiKey}, guardrails.SecretPatterns()),
+	}, nil
+}
+
+// Do sends an authenticated request to path (relative to the endpoint) and
+// returns the scanned, redacted response. Calls fail fast with ErrCircuitOpen
+// while the endpoint's breaker is open; transport errors and 5xx responses
+// count as failures, except when ctx was cancelled or timed out.
+func (c *Client) Do(ctx context.Context, method, path string, body []byte) (*Response, error) {
+	if err := c.breaker.Allow(); err != nil {
+		return nil, err
+	}
+	// outcome is how this call counts against the breaker. Until the
+	// endpoint has answered or failed, it counts as neither: a malformed
+	// request or one the caller cancelled says nothing about the endpoint,
+	// but must still free a half-open probe slot.
+	outcome := c.breaker.Release
+	defer func() { outcome() }()
+
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.Endpoint+"/"+strings.TrimLeft(path, "/"), reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("openclaw request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		if ctx.Err() == nil {
+			outcome = c.breaker.Failure
+		}
+		return nil, fmt.Errorf("openclaw request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		if ctx.Err() == nil {
+			outcome = c.breaker.Failure
+		}
+		return nil, fmt.Errorf("openclaw response: %w", err)
+	}
+	if resp.StatusCode >= 500 {
+		outcome = c.breaker.Failure
+	} else {
+		outcome = c.breaker.Success
+	}
+
+	out := &Response{StatusCode: resp.StatusCode}
+	if c.GuardMode != "off" {
+		res := c.guard.CheckData("openclaw:"+path, string(data))
+		out.Violations = res.Violations
+		if c.GuardMode == "block" && !res.Allowed {
+			return out, fmt.Errorf("%w: %d violation(s)", ErrResponseBlocked, len(res.Violations))
+		}
+	}
+	out.Body = c.redactor.Redact(string(data))
+	return out, nil
+}
@@ -47,6 +47,7 @@ type Health struct {
 	HTTPStatus       int    `json:"http_status,omitempty"`
 	SecretConfigured bool   `json:"secret_configured"`
 	SecretPresent    bool   `json:"secret_present"`
+	Breaker          string `json:"breaker,omitempty"`
 	Message          string `json:"message"`
 }
 
@@ -104,6 +105,14 @@ func CheckHealth(cfgDir string) Health {
 		return h
 	}
 
+	// Don't probe an endpoint the request path has already given up on.
+	h.Breaker = BreakerState(h.Endpoint)
+	if h.Breaker == BreakerOpen {
+		h.Status = StatusDegraded
+		h.Message = "circuit breaker is open after repeated request failures"
+		return h
+	}
+
 	timeout := 3 * time.Second
 	if cfg.TimeoutMS > 0 {
 		timeout = time.Duration(cfg.TimeoutMS) * time.Millisecond
@@ -0,0 +1,205 @@
+package policy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mackeh/AegisClaw/internal/scope"
+)
+
+// Constraints limits when a scope may be allowed. Empty fields do not
+// constrain.
+type Constraints struct {
+	// Hours is a daily window such as "09:00-17:00" (end exclusive). A
+	// window whose end is before its start wraps past midnight.
+	Hours string
+	// Days lists weekdays as ranges or names, e.g. "Mon-Fri" or "Sat,Sun".
+	Days string
+	// TZ is the IANA time zone the window is read in; empty means local time.
+	TZ string
+}
+
+// Rule applies Constraints to every request for one scope name or, for a
+// broad scope such as "files", every scope beneath it ("files.read",
+// "files.write"). Build rules with NewRule.
+type Rule struct {
+	Scope       string
+	Constraints Constraints
+	// Outside is the decision that replaces an allow outside the window:
+	// Deny or RequireApproval.
+	Outside Decision
+
+	window window
+}
+
+// window is the compiled form of Constraints.
+type window struct {
+	start, end int // minutes since midnight; start == end means all day
+	days       [7]bool
+	loc        *time.Location
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// NewRule validates and compiles a time-window rule. outside is "deny" or
+// "require_approval"; empty means require_approval.
+func NewRule(scopeName string, c Constraints, outside string) (Rule, error) {
+	r := Rule{Scope: scopeName, Constraints: c}
+	if strings.TrimSpace(scopeName) == "" {
+		return r, fmt.Errorf("rule has no scope")
+	}
+	switch outside {
+	case "", "require_approval":
+		r.Outside = RequireApproval
+	case "deny":
+		r.Outside = Deny
+	default:
+		return r, fmt.Errorf("rule for %s: invalid outside decision %q (want deny or require_approval)", scopeName, outside)
+	}
+
+	w, err := compileWindow(c)
+	if err != nil {
+		return r, fmt.Errorf("rule for %s: %w", scopeName, err)
+	}
+	r.window = w
+	return r, nil
+}
+
+func compileWindow(c Constraints) (window, error) {
+	w := window{loc: time.Local}
+	if c.TZ != "" {
+		loc, err := time.LoadLocation(c.TZ)
+		if err != nil {
+			return w, fmt.Errorf("invalid tz %q: %w", c.TZ, err)
+		}
+		w.loc = loc
+	}
+
+	if c.Hours != "" {
+		from, to, ok := strings.Cut(c.Hours, "-")
+		if !ok {
+			return w, fmt.Errorf("invalid hours %q (want HH:MM-HH:MM)", c.Hours)
+		}
+		var err error
+		if w.start, err = parseClock(from); err != nil {
+			return w, fmt.Errorf("invalid hours %q: %w", c.Hours, err)
+		}
+		if w.end, err = parseClock(to); err != nil {
+			return w, fmt.Errorf("invalid hours %q: %w", c.Hours, err)
+		}
+		if w.start == w.end {
+			return w, fmt.Errorf("invalid hours %q: empty window", c.Hours)
+		}
+	}
+
+	if c.Days == "" {
+		w.days = [7]bool{true, true, true, true, true, true, true}
+		return w, nil
+	}
+	for _, part := range strings.Split(c.Days, ",") {
+		from, to, isRange := strings.Cut(strings.TrimSpace(part), "-")
+		first, ok := weekdays[strings.ToLower(strings.TrimSpace(from))]
+		if !ok {
+			return w, fmt.Errorf("invalid days %q: unknown day %q", c.Days, from)
+		}
+		last := first
+		if isRange {
+			if last, ok = weekdays[strings.ToLower(strings.TrimSpace(to))]; !ok {
+				return w, fmt.Errorf("invalid days %q: unknown day %q", c.Days, to)
+			}
+		}
+		// Ranges may wrap the week, e.g. Fri-Mon.
+		for d := first; ; d = (d + 1) % 7 {
+			w.days[d] = true
+			if d == last {
+				break
+			}
+		}
+	}
+	return w, nil
+}
+
+// parseClock parses "HH:MM" into minutes since midnight. "24:00" is
+// accepted as the end of the day.
+func parseClock(s string) (int, error) {
+	h, m, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok {
+		return 0, fmt.Errorf("want HH:MM, got %q", s)
+	}
+	hour, err1 := strconv.Atoi(h)
+	minute, err2 := strconv.Atoi(m)
+	if err1 != nil || err2 != nil || hour < 0 || minute < 0 || minute > 59 || hour > 24 || (hour == 24 && minute != 0) {
+		return 0, fmt.Errorf("want HH:MM, got %q", s)
+	}
+	return hour*60 + minute, nil
+}
+
+// contains reports whether t falls inside the window. For windows that wrap
+// midnight, the day is the one the window opened on.
+func (w window) contains(t time.Time) bool {
+	t = t.In(w.loc)
+	minute := t.Hour()*60 + t.Minute()
+	day := t.Weekday()
+	switch {
+	case w.start == w.end:
+		// No hours constraint.
+	case w.start < w.end:
+		if minute < w.start || minute >= w.end {
+			return false
+		}
+	default:
+		if minute >= w.end && minute < w.start {
+			return false
+		}
+		if minute < w.end {
+			day = (day + 6) % 7
+		}
+	}
+	return w.days[day]
+}
+
+// SetRules replaces the engine's time-window rules.
+func (e *Engine) SetRules(rules []Rule) {
+	e.rules = rules
+}
+
+// checkConstraints applies the rules for s to the policy's decision. Only
+// the most specific matching rules count: a "files.read" rule overrides a
+// "files" rule for files.read. Inside every such window the decision
+// stands; outside one, an allow becomes the rule's Outside decision, and a
+// deny rule also overrides an approval.
+func (e *Engine) checkConstraints(s scope.Scope, d Decision, tr *Trace) Decision {
+	if d == Deny {
+		return d
+	}
+	now := time.Now()
+	if e.now != nil {
+		now = e.now()
+	}
+	specific := ""
+	for _, r := range e.rules {
+		if scope.Covers(r.Scope, s.Name) && len(r.Scope) > len(specific) {
+			specific = r.Scope
+		}
+	}
+	for _, r := range e.rules {
+		if r.Scope != specific {
+			continue
+		}
+		if r.window.contains(now) {
+			tr.add("time-window rule for %s (%s): inside the window, %s stands", r.Scope, describeConstraints(r.Constraints), d)
+			continue
+		}
+		tr.add("time-window rule for %s (%s): outside the window, %s", r.Scope, describeConstraints(r.Constraints), r.Outside)
+		if r.Outside == Deny {
+			return Deny
+		}
+		d = RequireApproval
+	}
+	return d
+}
@@ -0,0 +1,151 @@
+package policy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mackeh/AegisClaw/internal/scope"
+)
+
+const allowAllRego = `
+package aegisclaw.policy
+import rego.v1
+
+default decision = "allow"
+`
+
+func TestCheckConstraints_BusinessHours(t *testing.T) {
+	ctx := context.Background()
+	engine, err := NewEngine(ctx, allowAllRego)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rule, err := NewRule("shell.exec", Constraints{Hours: "09:00-17:00", Days: "Mon-Fri", TZ: "Europe/London"}, "deny")
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine.SetRules([]Rule{rule})
+
+	london, _ := time.LoadLocation("Europe/London")
+	shell, _ := scope.Parse("shell.exec")
+	read, _ := scope.Parse("files.read:/tmp")
+
+	tests := []struct {
+		name  string
+		now   time.Time
+		scope scope.Scope
+		want  Decision
+	}{
+		{"weekday in hours", time.Date(2026, 3, 4, 10, 30, 0, 0, london), shell, Allow},
+		{"weekday before hours", time.Date(2026, 3, 4, 8, 59, 0, 0, london), shell, Deny},
+		{"weekday at close", time.Date(2026, 3, 4, 17, 0, 0, 0, london), shell, Deny},
+		{"saturday in hours", time.Date(2026, 3, 7, 10, 0, 0, 0, london), shell, Deny},
+		{"other zone, in London hours", time.Date(2026, 3, 4, 5, 0, 0, 0, time.FixedZone("EST", -5*3600)), shell, Allow},
+		{"unconstrained scope", time.Date(2026, 3, 7, 3, 0, 0, 0, london), read, Allow},
+	}
+	for _, tt := range tests {
+		engine.now = func() time.Time { return tt.now }
+		got, err := engine.Evaluate(ctx, tt.scope)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCheckConstraints_OutsideRequiresApproval(t *testing.T) {
+	ctx := context.Background()
+	engine, err := NewEngine(ctx, allowAllRego)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rule, err := NewRule("secrets.write", Constraints{Hours: "22:00-06:00", TZ: "UTC"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine.SetRules([]Rule{rule})
+	s, _ := scope.Parse("secrets.write")
+
+	engine.now = func() time.Time { return time.Date(2026, 3, 4, 23, 0, 0, 0, time.UTC) }
+	if got, _ := engine.Evaluate(ctx, s); got != Allow {
+		t.Errorf("inside wrapped window: got %v, want allow", got)
+	}
+	engine.now = func() time.Time { return time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC) }
+	if got, _ := engine.Evaluate(ctx, s); got != RequireApproval {
+		t.Errorf("outside window: got %v, want require_approval", got)
+	}
+}
+
+func TestWindow_WrapsMidnightOnOpeningDay(t *testing.T) {
+	w, err := compileWindow(Constraints{Hours: "22:00-02:00", Days: "Fri", TZ: "UTC"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !w.contains(time.Date(2026, 3, 7, 1, 0, 0, 0, time.UTC)) { // Saturday 01:00
+		t.Error("Friday's window should still be open early Saturday")
+	}
+	if w.contains(time.Date(2026, 3, 6, 1, 0, 0, 0, time.UTC)) { // Friday 01:00
+		t.Error("Friday 01:00 belongs to Thursday's window")
+	}
+}
+
+func TestNewRule_Invalid(t *testing.T) {
+	bad := []struct {
+		scope   string
+		c       Constraints
+		outside string
+	}{
+		{"", Constraints{}, ""},
+		{"shell.exec", Constraints{Hours: "9-17"}, ""},
+		{"shell.exec", Constraints{Hours: "09:00-25:00"}, ""},
+		{"shell.exec", Constraints{Hours: "09:00-09:00"}, ""},
+		{"shell.exec", Constraints{Days: "Mon-Funday"}, ""},
+		{"shell.exec", Constraints{TZ: "Mars/Olympus"}, ""},
+		{"shell.exec", Constraints{}, "allow"},
+	}
+	for _, b := range bad {
+		if _, err := NewRule(b.scope, b.c, b.outside); err == nil {
+			t.Errorf("NewRule(%q, %+v, %q) should fail", b.scope, b.c, b.outside)
+		}
+	}
+	if _, err := NewRule("shell.exec", Constraints{Hours: "00:00-24:00", Days: "Fri-Mon, wed"}, "deny"); err != nil {
+		t.Errorf("valid rule rejected: %v", err)
+	}
+}
+
+func TestCheckConstraints_ScopeHierarchy(t *testing.T) {
+	ctx := context.Background()
+	engine, err := NewEngine(ctx, allowAllRego)
+	if err != nil {
+		t.Fatal(err)
+	}
+	broad, err := NewRule("files", Constraints{Hours: "09:00-17:00", TZ: "UTC"}, "deny")
+	if err != nil {
+		t.Fatal(err)
+	}
+	specific, err := NewRule("files.read", Constraints{}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine.SetRules([]Rule{specific, broad})
+	engine.now = func() time.Time { return time.Date(2026, 3, 4, 20, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		scope string
+		want  Decision
+	}{
+		{"files.write", Deny},      // covered by the broad rule
+		{"files.read", Allow},      // the specific rule wins
+		{"files", Deny},            // the broad rule matches itself
+		{"filesystem.read", Allow}, // a shared prefix is not a parent
+	}
+	for _, tt := range tests {
+		s, _ := scope.Parse(tt.scope)
+		if got, _ := engine.Evaluate(ctx, s); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.scope, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mackeh/AegisClaw/internal/scope"
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/topdown"
+)
+
+// Trace explains one scope's decision: the steps Evaluate took, in order,
+// naming the override pattern, Rego rules and time-window rules that
+// produced it.
+type Trace struct {
+	Scope    string   `json:"scope"`
+	Decision string   `json:"decision"`
+	Steps    []string `json:"steps"`
+}
+
+// Explain is Evaluate that also returns how the decision was reached.
+func (e *Engine) Explain(ctx context.Context, s scope.Scope) (Decision, Trace, error) {
+	tr := Trace{Scope: s.String()}
+	d, err := e.evaluate(ctx, s, &tr)
+	tr.Decision = d.String()
+	return d, tr, err
+}
+
+// String renders the trace as an indented list for terminal output.
+func (t Trace) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s → %s\n", t.Scope, t.Decision)
+	for _, step := range t.Steps {
+		fmt.Fprintf(&b, "   • %s\n", step)
+	}
+	return b.String()
+}
+
+// add records a step; it is a no-op on a nil trace so evaluate can call it
+// unconditionally.
+func (t *Trace) add(format string, args ...any) {
+	if t == nil {
+		return
+	}
+	t.Steps = append(t.Steps, fmt.Sprintf(format, args...))
+}
+
+// addRegoRules records each Rego rule (including helper functions) that
+// evaluated to a value, once, in the order evaluation finished with it.
+func (t *Trace) addRegoRules(events []*topdown.Event) {
+	seen := map[*ast.Rule]bool{}
+	for _, ev := range events {
+		if ev.Op != topdown.ExitOp {
+			continue
+		}
+		r, ok := ev.Node.(*ast.Rule)
+		if !ok || seen[r] {
+			continue
+		}
+		seen[r] = true
+		t.add("%s", describeRule(r))
+	}
+}
+
+// describeRule renders a rule as "policy.rego:12: rule matched: <source>",
+// with the source on one line.
+func describeRule(r *ast.Rule) string {
+	var text string
+	switch {
+	case r.Default:
+		// A default rule's location covers only the keyword.
+		text = "default rule: default " + r.Head.String()
+	case r.Location != nil && len(r.Location.Text) > 0:
+		text = "rule matched: " + strings.Join(strings.Fields(string(r.Location.Text)), " ")
+	default:
+		text = "rule matched: " + r.Head.String()
+	}
+	if r.Location == nil {
+		return text
+	}
+	return fmt.Sprintf("%s:%d: %s", r.Location.File, r.Location.Row, text)
+}
+
+// describeConstraints renders a rule's window for a trace, e.g.
+// "hours 09:00-17:00, days Mon-Fri, tz Europe/London".
+func describeConstraints(c Constraints) string {
+	var parts []string
+	if c.Hours != "" {
+		parts = append(parts, "hours "+c.Hours)
+	}
+	if c.Days != "" {
+		parts = append(parts, "days "+c.Days)
+	}
+	if c.TZ != "" {
+		parts = append(parts, "tz "+c.TZ)
+	}
+	if len(parts) == 0 {
+		return "no window"
+	}
+	return strings.Join(parts, ", ")
+}
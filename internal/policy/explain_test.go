@@ -0,0 +1,129 @@
+package policy
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mackeh/AegisClaw/internal/scope"
+)
+
+const denyShellRego = `package aegisclaw.policy
+
+import rego.v1
+
+default decision = "require_approval"
+
+decision = "deny" if {
+	input.scope.name == "shell.exec"
+	not trusted(input.scope.resource)
+}
+
+decision = "allow" if input.scope.name == "time.read"
+
+trusted(cmd) if startswith(cmd, "/usr/bin/safe")
+`
+
+// hasStep reports whether any step of tr contains all of substrs.
+func hasStep(tr Trace, substrs ...string) bool {
+	for _, step := range tr.Steps {
+		all := true
+		for _, s := range substrs {
+			all = all && strings.Contains(step, s)
+		}
+		if all {
+			return true
+		}
+	}
+	return false
+}
+
+func TestExplain_RegoRule(t *testing.T) {
+	ctx := context.Background()
+	engine, err := NewEngine(ctx, denyShellRego)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shell, _ := scope.Parse("shell.exec:rm")
+	d, tr, err := engine.Explain(ctx, shell)
+	if err != nil || d != Deny {
+		t.Fatalf("Explain = %v, %v; want deny", d, err)
+	}
+	if tr.Decision != "deny" || tr.Scope != "shell.exec:rm" {
+		t.Errorf("trace header = %q %q", tr.Scope, tr.Decision)
+	}
+	if !hasStep(tr, "policy.rego:7:", `decision = "deny"`, `input.scope.name == "shell.exec"`) {
+		t.Errorf("denial should name the rule that matched, got %q", tr.Steps)
+	}
+
+	fallback, _ := scope.Parse("files.read:/etc")
+	_, tr, _ = engine.Explain(ctx, fallback)
+	if !hasStep(tr, "policy.rego:5:", "default rule") {
+		t.Errorf("fallback should name the default rule, got %q", tr.Steps)
+	}
+
+	// The untraced path is unchanged.
+	if d, _ := engine.Evaluate(ctx, shell); d != Deny {
+		t.Errorf("Evaluate = %v, want deny", d)
+	}
+}
+
+func TestExplain_OverrideAndTimeWindow(t *testing.T) {
+	ctx := context.Background()
+	engine, err := NewEngine(ctx, allowAllRego)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine.SetOverride(&Override{Deny: []string{"files.write"}})
+	write, _ := scope.Parse("files.write:/etc")
+	if d, tr, _ := engine.Explain(ctx, write); d != Deny || !hasStep(tr, "skill override", `"files.write"`) {
+		t.Errorf("override denial = %v %q, want the override pattern", d, tr.Steps)
+	}
+
+	rule, err := NewRule("shell.exec", Constraints{Hours: "09:00-17:00", Days: "Mon-Fri", TZ: "UTC"}, "deny")
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine.SetRules([]Rule{rule})
+	engine.now = func() time.Time { return time.Date(2026, 3, 7, 22, 0, 0, 0, time.UTC) }
+	shell, _ := scope.Parse("shell.exec")
+	d, tr, _ := engine.Explain(ctx, shell)
+	if d != Deny || !hasStep(tr, "time-window rule for shell.exec", "hours 09:00-17:00, days Mon-Fri", "outside the window, deny") {
+		t.Errorf("time-window denial = %v %q, want the rule and its window", d, tr.Steps)
+	}
+
+	engine.SetUnknownScope(UnknownScopeDeny)
+	custom, _ := scope.Parse("custom.thing")
+	if d, tr, _ := engine.Explain(ctx, custom); d != Deny || !hasStep(tr, "unknown scope", "custom.thing") {
+		t.Errorf("unknown scope = %v %q", d, tr.Steps)
+	}
+}
+
+func TestExplainRequest_StopsAtDeny(t *testing.T) {
+	ctx := context.Background()
+	engine, err := NewEngine(ctx, denyShellRego)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := scope.ScopeRequest{RequestedBy: "test"}
+	for _, raw := range []string{"time.read", "shell.exec:rm", "files.read:/tmp"} {
+		s, _ := scope.Parse(raw)
+		req.Scopes = append(req.Scopes, s)
+	}
+
+	d, risky, traces, err := engine.ExplainRequest(ctx, req)
+	if err != nil || d != Deny {
+		t.Fatalf("ExplainRequest = %v, %v; want deny", d, err)
+	}
+	if len(risky) != 1 || risky[0].Name != "shell.exec" {
+		t.Errorf("denied scopes = %v", risky)
+	}
+	if len(traces) != 2 || traces[1].Decision != "deny" || traces[0].Decision != "allow" {
+		t.Fatalf("traces = %+v, want time.read then the denied shell.exec", traces)
+	}
+	if !strings.Contains(traces[1].String(), `decision = "deny"`) {
+		t.Errorf("rendered trace:\n%s", traces[1])
+	}
+}
@@ -0,0 +1,53 @@
+package policy
+
+import "github.com/mackeh/AegisClaw/internal/scope"
+
+// Override decides scopes for one skill before the Rego policy is
+// consulted. Patterns match hierarchically (see scope.Covers): the most
+// specific pattern wins, and on a tie Deny beats RequireApproval beats
+// Allow. Scopes no pattern covers fall through to the policy.
+type Override struct {
+	Allow           []string
+	RequireApproval []string
+	Deny            []string
+}
+
+// Merge adds other's patterns to o.
+func (o *Override) Merge(other Override) {
+	o.Allow = append(o.Allow, other.Allow...)
+	o.RequireApproval = append(o.RequireApproval, other.RequireApproval...)
+	o.Deny = append(o.Deny, other.Deny...)
+}
+
+// Decide returns the override's decision for a scope name, if it has one.
+func (o *Override) Decide(name string) (Decision, bool) {
+	d, _, ok := o.decide(name)
+	return d, ok
+}
+
+// decide is Decide that also returns the winning pattern.
+func (o *Override) decide(name string) (Decision, string, bool) {
+	if o == nil {
+		return RequireApproval, "", false
+	}
+	best, found := "", false
+	decision := RequireApproval
+	// Listed from strongest to weakest, so a tie keeps the stronger one.
+	for _, set := range []struct {
+		d        Decision
+		patterns []string
+	}{{Deny, o.Deny}, {RequireApproval, o.RequireApproval}, {Allow, o.Allow}} {
+		for _, p := range set.patterns {
+			if scope.Covers(p, name) && (!found || len(p) > len(best)) {
+				best, found, decision = p, true, set.d
+			}
+		}
+	}
+	return decision, best, found
+}
+
+// SetOverride makes Evaluate consult o before the policy. Engines are
+// built per run, so the override applies to the skill being run.
+func (e *Engine) SetOverride(o *Override) {
+	e.override = o
+}
@@ -0,0 +1,60 @@
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mackeh/AegisClaw/internal/scope"
+)
+
+func TestOverride_Decide(t *testing.T) {
+	o := &Override{
+		Allow:           []string{"files", "http.request"},
+		RequireApproval: []string{"http.request"},
+		Deny:            []string{"files.write"},
+	}
+	tests := []struct {
+		name string
+		want Decision
+		ok   bool
+	}{
+		{"files.read", Allow, true},             // broad allow
+		{"files.write", Deny, true},             // more specific deny wins
+		{"http.request", RequireApproval, true}, // tie: approval beats allow
+		{"shell.exec", RequireApproval, false},
+	}
+	for _, tt := range tests {
+		got, ok := o.Decide(tt.name)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("Decide(%s) = %v, %v; want %v, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+	if _, ok := (*Override)(nil).Decide("files.read"); ok {
+		t.Error("nil override should decide nothing")
+	}
+}
+
+func TestEvaluate_OverrideBeforePolicy(t *testing.T) {
+	ctx := context.Background()
+	engine, err := NewEngine(ctx, "package aegisclaw.policy\nimport rego.v1\ndefault decision = \"require_approval\"\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine.SetOverride(&Override{Allow: []string{"files.read"}})
+	read, _ := scope.Parse("files.read:/srv")
+	write, _ := scope.Parse("files.write:/srv")
+	if got, _ := engine.Evaluate(ctx, read); got != Allow {
+		t.Errorf("overridden scope: got %v, want allow", got)
+	}
+	if got, _ := engine.Evaluate(ctx, write); got != RequireApproval {
+		t.Errorf("uncovered scope: got %v, want the policy's require_approval", got)
+	}
+
+	// Unknown-scope denial still comes first.
+	engine.SetOverride(&Override{Allow: []string{"custom"}})
+	engine.SetUnknownScope(UnknownScopeDeny)
+	custom, _ := scope.Parse("custom.thing")
+	if got, _ := engine.Evaluate(ctx, custom); got != Deny {
+		t.Errorf("unknown scope: got %v, want deny", got)
+	}
+}
@@ -6,9 +6,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/mackeh/AegisClaw/internal/scope"
 	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/topdown"
 )
 
 // Decision represents the outcome of a policy evaluation
@@ -33,9 +36,51 @@ func (d Decision) String() string {
 	}
 }
 
+// UnknownScopeMode controls how Evaluate treats scope names that are not in
+// scope.Registry, which usually means a typo'd or malformed skill manifest.
+type UnknownScopeMode string
+
+const (
+	// UnknownScopeApprove leaves unknown scopes to the Rego policy, whose
+	// default is to ask for approval. This is the default.
+	UnknownScopeApprove UnknownScopeMode = "approve"
+	// UnknownScopeDeny denies unknown scopes outright.
+	UnknownScopeDeny UnknownScopeMode = "deny"
+)
+
+// ParseUnknownScopeMode parses a policy.unknown_scope setting; empty means
+// UnknownScopeApprove.
+func ParseUnknownScopeMode(s string) (UnknownScopeMode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", string(UnknownScopeApprove):
+		return UnknownScopeApprove, nil
+	case string(UnknownScopeDeny):
+		return UnknownScopeDeny, nil
+	default:
+		return UnknownScopeApprove, fmt.Errorf("invalid unknown_scope mode %q (want approve or deny)", s)
+	}
+}
+
 // Engine evaluates policy rules against scope requests using OPA
 type Engine struct {
-	query rego.PreparedEvalQuery
+	query        rego.PreparedEvalQuery
+	unknownScope UnknownScopeMode
+	rules        []Rule
+	override     *Override
+	skillSigned  bool
+	now          func() time.Time // nil means time.Now; set by tests
+}
+
+// SetUnknownScope sets how scopes missing from scope.Registry are decided.
+func (e *Engine) SetUnknownScope(mode UnknownScopeMode) {
+	e.unknownScope = mode
+}
+
+// SetSkillSigned records whether the skill being evaluated has a manifest
+// signature that verifies against a trusted key. Policies see it as
+// input.skill_signed; it is false unless set.
+func (e *Engine) SetSkillSigned(signed bool) {
+	e.skillSigned = signed
 }
 
 // NewEngine creates a new policy engine from a Rego policy string
@@ -75,66 +120,146 @@ func LoadDefaultPolicy(ctx context.Context) (*Engine, error) {
 	}
 	
 	// Fallback to a safe default if file not found (or could embed default policy)
-	defaultPolicy := `
-package aegisclaw.policy
+	return NewEngine(ctx, DefaultPolicy)
+}
+
+// DefaultPolicy is the policy used when policy.rego is missing: every scope
+// needs approval.
+const DefaultPolicy = `package aegisclaw.policy
+
 import rego.v1
+
 default decision = "require_approval"
 `
-	return NewEngine(ctx, defaultPolicy)
-}
 
-// Evaluate checks a scope request against the policy and returns a decision
+// Evaluate checks a scope request against the policy and returns a decision.
+// Precedence: unknown-scope denial, then the skill override (if one covers
+// the scope), then the Rego policy; time-window rules apply to the result.
+// An exclusion scope only takes access away, so it is always allowed.
 func (e *Engine) Evaluate(ctx context.Context, s scope.Scope) (Decision, error) {
+	return e.evaluate(ctx, s, nil)
+}
+
+// evaluate implements Evaluate, recording each step in tr if it is non-nil.
+func (e *Engine) evaluate(ctx context.Context, s scope.Scope, tr *Trace) (Decision, error) {
+	if s.Exclude {
+		tr.add("exclusion scope: it only narrows other scopes")
+		return Allow, nil
+	}
+	if e.unknownScope == UnknownScopeDeny && !scope.IsKnown(s.Name) {
+		tr.add("unknown scope %q denied (policy.unknown_scope: deny)", s.Name)
+		return Deny, nil
+	}
+	if d, pattern, ok := e.override.decide(s.Name); ok {
+		tr.add("skill override: %s pattern %q covers %s", d, pattern, s.Name)
+		return e.checkConstraints(s, d, tr), nil
+	}
+
 	input := map[string]interface{}{
 		"scope": map[string]interface{}{
 			"name":     s.Name,
 			"resource": s.Resource,
 			"risk":     s.RiskLevel.String(),
+			// parents lets a policy cover a whole family, e.g.
+			// "files" in input.scope.parents for files.read and files.write.
+			"parents": parentsOf(s.Name),
 		},
+		"skill_signed": e.skillSigned,
 	}
 
-	results, err := e.query.Eval(ctx, rego.EvalInput(input))
+	opts := []rego.EvalOption{rego.EvalInput(input)}
+	var tracer *topdown.BufferTracer
+	if tr != nil {
+		tracer = topdown.NewBufferTracer()
+		opts = append(opts, rego.EvalQueryTracer(tracer))
+	}
+	results, err := e.query.Eval(ctx, opts...)
 	if err != nil {
+		tr.add("policy evaluation failed: %v", err)
 		return RequireApproval, err
 	}
 
 	if len(results) == 0 || len(results[0].Expressions) == 0 {
 		// No decision matched, return safe default
+		tr.add("no policy rule decided %s; defaulting to require_approval", s.Name)
 		return RequireApproval, nil
 	}
 
 	decisionStr, ok := results[0].Expressions[0].Value.(string)
 	if !ok {
+		tr.add("policy returned a non-string decision")
 		return RequireApproval, fmt.Errorf("policy returned non-string decision")
 	}
+	if tracer != nil {
+		tr.addRegoRules(*tracer)
+	}
 
-	return parseDecision(decisionStr), nil
+	return e.checkConstraints(s, parseDecision(decisionStr), tr), nil
 }
 
-// EvaluateRequest evaluates all scopes in a request
+// EvaluateRequest evaluates all scopes in a request. Exclusions win: a
+// scope that an exclusion in the same request carves out is denied before
+// the policy is consulted.
 func (e *Engine) EvaluateRequest(ctx context.Context, req scope.ScopeRequest) (Decision, []scope.Scope, error) {
+	d, risky, _, err := e.evaluateRequest(ctx, req, false)
+	return d, risky, err
+}
+
+// ExplainRequest is EvaluateRequest that also returns a Trace for each
+// scope it evaluated, in order. Evaluation stops at the first deny, so on a
+// denial the last trace explains it.
+func (e *Engine) ExplainRequest(ctx context.Context, req scope.ScopeRequest) (Decision, []scope.Scope, []Trace, error) {
+	return e.evaluateRequest(ctx, req, true)
+}
+
+func (e *Engine) evaluateRequest(ctx context.Context, req scope.ScopeRequest, explain bool) (Decision, []scope.Scope, []Trace, error) {
 	requiresApproval := []scope.Scope{}
-	
+	var traces []Trace
+
 	for _, s := range req.Scopes {
-		decision, err := e.Evaluate(ctx, s)
+		var tr *Trace
+		if explain {
+			tr = &Trace{Scope: s.String()}
+		}
+		var decision Decision
+		var err error
+		if x, ok := scope.ExcludedBy(req.Scopes, s); ok {
+			tr.add("excluded by %s (exclusions always win)", x)
+			decision = Deny
+		} else {
+			decision, err = e.evaluate(ctx, s, tr)
+		}
+		if tr != nil {
+			tr.Decision = decision.String()
+			traces = append(traces, *tr)
+		}
 		if err != nil {
 			// Fail secure on error
-			return RequireApproval, []scope.Scope{s}, err
+			return RequireApproval, []scope.Scope{s}, traces, err
 		}
-		
+
 		switch decision {
 		case Deny:
-			return Deny, []scope.Scope{s}, nil
+			return Deny, []scope.Scope{s}, traces, nil
 		case RequireApproval:
 			requiresApproval = append(requiresApproval, s)
 		}
 	}
 
 	if len(requiresApproval) > 0 {
-		return RequireApproval, requiresApproval, nil
+		return RequireApproval, requiresApproval, traces, nil
 	}
 
-	return Allow, nil, nil
+	return Allow, nil, traces, nil
+}
+
+// parentsOf returns scope.Parents as a non-nil slice, so Rego always sees
+// an array.
+func parentsOf(name string) []string {
+	if p := scope.Parents(name); p != nil {
+		return p
+	}
+	return []string{}
 }
 
 func parseDecision(s string) Decision {
@@ -59,3 +59,131 @@ decision = "require_approval" if {
 		}
 	}
 }
+
+func TestEvaluate_UnknownScopeMode(t *testing.T) {
+	policyRego := `
+package aegisclaw.policy
+import rego.v1
+
+default decision = "require_approval"
+
+decision = "allow" if {
+	input.scope.name == "files.read"
+}
+`
+	ctx := context.Background()
+	typo, _ := scope.Parse("filez.read:/tmp")
+	known, _ := scope.Parse("files.read:/tmp")
+	capability := scope.Scope{Name: scope.CapabilityName, Resource: "NET_BIND_SERVICE", RiskLevel: scope.RiskLow}
+
+	tests := []struct {
+		mode  UnknownScopeMode
+		scope scope.Scope
+		want  Decision
+	}{
+		{UnknownScopeApprove, typo, RequireApproval},
+		{UnknownScopeApprove, known, Allow},
+		{UnknownScopeDeny, typo, Deny},
+		{UnknownScopeDeny, known, Allow},
+		{UnknownScopeDeny, capability, RequireApproval},
+	}
+	for _, tt := range tests {
+		engine, err := NewEngine(ctx, policyRego)
+		if err != nil {
+			t.Fatalf("Failed to create engine: %v", err)
+		}
+		engine.SetUnknownScope(tt.mode)
+		got, err := engine.Evaluate(ctx, tt.scope)
+		if err != nil {
+			t.Fatalf("Evaluate(%s) error = %v", tt.scope, err)
+		}
+		if got != tt.want {
+			t.Errorf("mode %s: Evaluate(%s) = %v, want %v", tt.mode, tt.scope, got, tt.want)
+		}
+	}
+}
+
+func TestParseUnknownScopeMode(t *testing.T) {
+	for in, want := range map[string]UnknownScopeMode{"": UnknownScopeApprove, "approve": UnknownScopeApprove, "DENY": UnknownScopeDeny} {
+		got, err := ParseUnknownScopeMode(in)
+		if err != nil || got != want {
+			t.Errorf("ParseUnknownScopeMode(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseUnknownScopeMode("block"); err == nil {
+		t.Error("expected error for invalid mode")
+	}
+}
+
+func TestEvaluate_ScopeParents(t *testing.T) {
+	policyRego := `
+package aegisclaw.policy
+import rego.v1
+
+default decision = "require_approval"
+
+decision = "allow" if "files" in input.scope.parents
+`
+	ctx := context.Background()
+	engine, err := NewEngine(ctx, policyRego)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]Decision{
+		"files.read":  Allow,
+		"files.write": Allow,
+		"files":       RequireApproval,
+		"shell.exec":  RequireApproval,
+	} {
+		s, _ := scope.Parse(name)
+		if got, err := engine.Evaluate(ctx, s); err != nil || got != want {
+			t.Errorf("%s: got %v (%v), want %v", name, got, err, want)
+		}
+	}
+}
+
+func TestEvaluateRequest_Exclusions(t *testing.T) {
+	ctx := context.Background()
+	engine, err := NewEngine(ctx, `package aegisclaw.policy
+import rego.v1
+
+default decision = "require_approval"
+
+decision = "allow" if {
+	input.scope.name == "files.read"
+	startswith(input.scope.resource, "/data")
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := func(scopes ...string) scope.ScopeRequest {
+		var req scope.ScopeRequest
+		for _, s := range scopes {
+			parsed, err := scope.Parse(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Scopes = append(req.Scopes, parsed)
+		}
+		return req
+	}
+
+	// The broader path stays allowed alongside its exclusion.
+	d, _, err := engine.EvaluateRequest(ctx, request("files.read:/data", "!files.read:/data/secrets"))
+	if err != nil || d != Allow {
+		t.Errorf("broader path = %v, %v; want allow", d, err)
+	}
+
+	// The policy allows anything under /data, but the exclusion wins.
+	d, risky, traces, err := engine.ExplainRequest(ctx, request("files.read:/data/secrets/db.key", "!files.read:/data/secrets"))
+	if err != nil || d != Deny {
+		t.Fatalf("excluded path = %v, %v; want deny", d, err)
+	}
+	if len(risky) != 1 || risky[0].Resource != "/data/secrets/db.key" {
+		t.Errorf("denied scope = %v", risky)
+	}
+	if len(traces) != 1 || len(traces[0].Steps) != 1 || traces[0].Steps[0] != "excluded by !files.read:/data/secrets (exclusions always win)" {
+		t.Errorf("trace = %+v", traces)
+	}
+}
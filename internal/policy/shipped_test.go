@@ -0,0 +1,62 @@
+package policy
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/mackeh/AegisClaw/internal/scope"
+)
+
+// loadShipped builds an engine from a policy bundled under configs/.
+func loadShipped(t *testing.T, name string) *Engine {
+	t.Helper()
+	data, err := os.ReadFile("../../configs/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine, err := NewEngine(context.Background(), string(data))
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	return engine
+}
+
+func TestShippedPolicies_ScopeFamilies(t *testing.T) {
+	tests := []struct {
+		policy string
+		scope  string
+		risk   scope.Risk
+		signed bool
+		want   Decision
+	}{
+		{"policy.rego", "files.read:/tmp/x", scope.RiskLow, false, Allow},
+		{"policy.rego", "shell.exec", scope.RiskCritical, false, RequireApproval},
+		{"policy.rego", "secrets.access:API_KEY", scope.RiskHigh, false, RequireApproval},
+		{"policy.rego", "secrets.write:API_KEY", scope.RiskCritical, false, RequireApproval},
+		{"policies/standard.rego", "secrets.access:API_KEY", scope.RiskHigh, false, RequireApproval},
+		{"policies/standard.rego", "secrets.write:API_KEY", scope.RiskCritical, false, Deny},
+		{"policies/standard.rego", "secrets.write:API_KEY", scope.RiskCritical, true, RequireApproval},
+		{"policies/standard.rego", "shell.exec", scope.RiskCritical, false, Deny},
+		{"policies/standard.rego", "shell.exec", scope.RiskCritical, true, RequireApproval},
+		{"policies/standard.rego", "files.read:/tmp/x", scope.RiskLow, false, Allow},
+		{"policies/standard.rego", "files.read:/etc/hosts", scope.RiskLow, false, RequireApproval},
+		{"policies/standard.rego", "files.read:/etc/hosts", scope.RiskLow, true, Allow},
+		{"policies/permissive.rego", "http.request:example.com", scope.RiskHigh, false, RequireApproval},
+		{"policies/permissive.rego", "http.request:example.com", scope.RiskHigh, true, Allow},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		s, err := scope.Parse(tt.scope)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.RiskLevel = tt.risk
+		engine := loadShipped(t, tt.policy)
+		engine.SetSkillSigned(tt.signed)
+		got, err := engine.Evaluate(ctx, s)
+		if err != nil || got != tt.want {
+			t.Errorf("%s %s (signed=%v): got %v (%v), want %v", tt.policy, tt.scope, tt.signed, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package posture
+
+import (
+	"sort"
+	"strings"
+)
+
+// Recommendation is a single remediation step and the points it would add.
+type Recommendation struct {
+	Category string `json:"category"`
+	Action   string `json:"action"`
+	Gain     int    `json:"gain"`
+}
+
+// Advise returns, for every category below its maximum, the next concrete
+// action and its point gain, sorted by highest gain first.
+func Advise(s *Score) []Recommendation {
+	var recs []Recommendation
+	for _, c := range s.Categories {
+		if c.Points >= c.Max {
+			continue
+		}
+		if r, ok := adviseCategory(c); ok {
+			recs = append(recs, r)
+		}
+	}
+	sort.SliceStable(recs, func(i, j int) bool {
+		if recs[i].Gain != recs[j].Gain {
+			return recs[i].Gain > recs[j].Gain
+		}
+		return recs[i].Category < recs[j].Category
+	})
+	return recs
+}
+
+// adviseCategory maps a category's current score onto the next rung of the
+// scoring ladder used by the score* functions above.
+func adviseCategory(c CategoryScore) (Recommendation, bool) {
+	r := Recommendation{Category: c.Name}
+	switch c.Name {
+	case "Sandboxing":
+		switch {
+		case c.Points < 20:
+			r.Action = "Enable gVisor (security.sandbox_runtime: runsc)"
+			r.Gain = 20 - c.Points
+		case c.Points < 25:
+			r.Action = "Switch to Kata Containers (security.sandbox_runtime: kata-runtime)"
+			r.Gain = 25 - c.Points
+		default:
+			r.Action = "Switch to Firecracker microVMs (security.sandbox_runtime: kata-fc)"
+			r.Gain = c.Max - c.Points
+		}
+	case "Secrets":
+		if c.Points < 15 {
+			r.Action = "Initialize the encrypted secret store (aegisclaw secrets init)"
+			r.Gain = 15 - c.Points
+		} else {
+			r.Action = "Move API keys into the secret store (aegisclaw secrets set)"
+			r.Gain = c.Max - c.Points
+		}
+	case "Policy":
+		if c.Points < 10 {
+			r.Action = "Add a policy file (~/.aegisclaw/policy.rego, created by aegisclaw init)"
+			r.Gain = 10 - c.Points
+		} else {
+			r.Action = "Require approval for risky actions (security.require_approval: true)"
+			r.Gain = c.Max - c.Points
+		}
+	case "Audit":
+		if c.Points < 10 {
+			r.Action = "Enable audit logging (security.audit_enabled: true)"
+			r.Gain = 10 - c.Points
+		} else {
+			r.Action = "Repair the audit hash chain (aegisclaw logs verify)"
+			r.Gain = c.Max - c.Points
+		}
+	case "Network":
+		r.Action = "Enable default-deny egress (network.default_deny: true)"
+		r.Gain = c.Max - c.Points
+	case "Image Trust":
+		if strings.HasSuffix(c.Detail, noRegistryAllowlist) {
+			r.Action = "Restrict skill images to trusted registries (security.allowed_registries)"
+			r.Gain = 5
+		} else {
+			r.Action = "Pin skill images by digest (image: name@sha256:...)"
+			r.Gain = c.Max - c.Points
+		}
+	default:
+		return r, false
+	}
+	return r, true
+}
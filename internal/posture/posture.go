@@ -2,11 +2,14 @@
 package posture
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 
 	"github.com/mackeh/AegisClaw/internal/audit"
 	"github.com/mackeh/AegisClaw/internal/config"
+	"github.com/mackeh/AegisClaw/internal/sandbox"
+	"github.com/mackeh/AegisClaw/internal/skill"
 )
 
 // Grade represents the overall security grade.
@@ -27,6 +30,7 @@ type Score struct {
 	Percentage int             `json:"percentage"`
 	Grade      Grade           `json:"grade"`
 	Categories []CategoryScore `json:"categories"`
+	Profile    string          `json:"profile,omitempty"` // active config profile
 }
 
 // CategoryScore holds the score for a single category.
@@ -66,6 +70,9 @@ func Calculate() (*Score, error) {
 	// Network (15 points)
 	categories = append(categories, scoreNetwork(cfg))
 
+	// Image Trust (10 points)
+	categories = append(categories, scoreImageTrust(cfg, skill.LoadSkills(skill.SearchPaths(cfgDir)...)))
+
 	total, max := 0, 0
 	for _, c := range categories {
 		total += c.Points
@@ -83,6 +90,7 @@ func Calculate() (*Score, error) {
 		Percentage: pct,
 		Grade:      gradeFromPct(pct),
 		Categories: categories,
+		Profile:    cfg.Profile,
 	}, nil
 }
 
@@ -185,6 +193,40 @@ func scoreNetwork(cfg *config.Config) CategoryScore {
 	return cat
 }
 
+// noRegistryAllowlist ends the Image Trust detail when
+// security.allowed_registries is empty; Advise keys off it.
+const noRegistryAllowlist = "no registry allowlist"
+
+// scoreImageTrust awards 5 points for a registry allowlist and up to 5 for
+// the share of skills whose image is pinned by digest. With no skills there
+// is nothing unpinned, so pinning scores in full.
+func scoreImageTrust(cfg *config.Config, skills []*skill.Manifest) CategoryScore {
+	cat := CategoryScore{Name: "Image Trust", Max: 10}
+
+	pinned := 0
+	for _, m := range skills {
+		if sandbox.PinnedByDigest(m.Image) {
+			pinned++
+		}
+	}
+	if len(skills) == 0 {
+		cat.Points = 5
+		cat.Detail = "no skills installed"
+	} else {
+		cat.Points = 5 * pinned / len(skills)
+		cat.Detail = fmt.Sprintf("%d/%d skill images pinned by digest", pinned, len(skills))
+	}
+
+	if len(cfg.Security.AllowedRegistries) > 0 {
+		cat.Points += 5
+		cat.Detail += ", registry allowlist configured"
+	} else {
+		cat.Detail += ", " + noRegistryAllowlist
+	}
+
+	return cat
+}
+
 func gradeFromPct(pct int) Grade {
 	switch {
 	case pct >= 90:
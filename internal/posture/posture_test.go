@@ -1,9 +1,11 @@
 package posture
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/mackeh/AegisClaw/internal/config"
+	"github.com/mackeh/AegisClaw/internal/skill"
 )
 
 func TestGradeFromPct(t *testing.T) {
@@ -101,3 +103,92 @@ func TestScoreAudit_Disabled(t *testing.T) {
 		t.Errorf("expected 0 points for disabled audit, got %d", cat.Points)
 	}
 }
+
+func TestAdvise_RanksByGain(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Security.SandboxRuntime = "runc"
+	cfgDir := t.TempDir()
+
+	score := &Score{Categories: []CategoryScore{
+		scoreSandbox(cfg),
+		scoreSecrets(cfgDir),
+		scorePolicy(cfg, cfgDir),
+		scoreAudit(cfg, cfgDir),
+		scoreNetwork(cfg),
+	}}
+
+	recs := Advise(score)
+	if len(recs) != 5 {
+		t.Fatalf("expected 5 recommendations, got %d: %+v", len(recs), recs)
+	}
+	for i := 1; i < len(recs); i++ {
+		if recs[i].Gain > recs[i-1].Gain {
+			t.Errorf("recommendations not sorted by gain: %+v", recs)
+		}
+	}
+
+	want := map[string]int{"Sandboxing": 5, "Secrets": 15, "Policy": 10, "Audit": 10, "Network": 15}
+	for _, r := range recs {
+		if r.Gain != want[r.Category] {
+			t.Errorf("%s gain = %d, want %d", r.Category, r.Gain, want[r.Category])
+		}
+		if r.Action == "" {
+			t.Errorf("%s has empty action", r.Category)
+		}
+	}
+	if recs[len(recs)-1].Category != "Sandboxing" {
+		t.Errorf("lowest-impact step = %s, want Sandboxing", recs[len(recs)-1].Category)
+	}
+}
+
+func TestAdvise_MaxedOut(t *testing.T) {
+	score := &Score{Categories: []CategoryScore{{Name: "Network", Points: 15, Max: 15}}}
+	if recs := Advise(score); len(recs) != 0 {
+		t.Errorf("expected no recommendations, got %+v", recs)
+	}
+}
+
+func TestScoreImageTrust(t *testing.T) {
+	pinned := &skill.Manifest{Name: "a", Image: "alpine@sha256:0123456789abcdef"}
+	tagged := &skill.Manifest{Name: "b", Image: "alpine:latest"}
+	allowlist := config.SecurityConfig{AllowedRegistries: []string{"docker.io"}}
+
+	tests := []struct {
+		name     string
+		security config.SecurityConfig
+		skills   []*skill.Manifest
+		expected int
+	}{
+		{"pinned with allowlist", allowlist, []*skill.Manifest{pinned}, 10},
+		{"pinned without allowlist", config.SecurityConfig{}, []*skill.Manifest{pinned}, 5},
+		{"unpinned with allowlist", allowlist, []*skill.Manifest{tagged}, 5},
+		{"half pinned", config.SecurityConfig{}, []*skill.Manifest{pinned, tagged}, 2},
+		{"unpinned without allowlist", config.SecurityConfig{}, []*skill.Manifest{tagged}, 0},
+		{"no skills", config.SecurityConfig{}, nil, 5},
+	}
+
+	for _, tt := range tests {
+		cat := scoreImageTrust(&config.Config{Security: tt.security}, tt.skills)
+		if cat.Points != tt.expected {
+			t.Errorf("scoreImageTrust(%s) = %d points, want %d (%s)", tt.name, cat.Points, tt.expected, cat.Detail)
+		}
+		if cat.Max != 10 {
+			t.Errorf("expected max 10, got %d", cat.Max)
+		}
+	}
+}
+
+func TestAdvise_ImageTrust(t *testing.T) {
+	tagged := []*skill.Manifest{{Name: "b", Image: "alpine:latest"}}
+
+	recs := Advise(&Score{Categories: []CategoryScore{scoreImageTrust(&config.Config{}, tagged)}})
+	if len(recs) != 1 || !strings.Contains(recs[0].Action, "allowed_registries") || recs[0].Gain != 5 {
+		t.Errorf("without allowlist: got %+v", recs)
+	}
+
+	cfg := &config.Config{Security: config.SecurityConfig{AllowedRegistries: []string{"ghcr.io"}}}
+	recs = Advise(&Score{Categories: []CategoryScore{scoreImageTrust(cfg, tagged)}})
+	if len(recs) != 1 || !strings.Contains(recs[0].Action, "digest") || recs[0].Gain != 5 {
+		t.Errorf("with allowlist: got %+v", recs)
+	}
+}
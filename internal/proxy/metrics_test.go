@@ -0,0 +1,55 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mackeh/AegisClaw/internal/telemetry"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestProxyRecordsLatencyPerDomain(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer upstream.Close()
+
+	// The allowlist entry, not the full host, is the label.
+	p := NewEgressProxy([]string{"127.0.0.1"}, nil)
+	p.BlockPrivateIPs = false
+	const domain = "127.0.0.1"
+	before := histogramCount(t, telemetry.ProxyRequestDuration.WithLabelValues(domain).(prometheus.Histogram))
+	ok0 := testutil.ToFloat64(telemetry.ProxyResponsesTotal.WithLabelValues(domain, "200"))
+	nf0 := testutil.ToFloat64(telemetry.ProxyResponsesTotal.WithLabelValues(domain, "404"))
+
+	for _, path := range []string{"/ok", "/ok", "/missing"} {
+		rec := httptest.NewRecorder()
+		p.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, upstream.URL+path, nil))
+	}
+
+	if got := histogramCount(t, telemetry.ProxyRequestDuration.WithLabelValues(domain).(prometheus.Histogram)) - before; got != 3 {
+		t.Errorf("latency observations = %d, want 3", got)
+	}
+	if got := testutil.ToFloat64(telemetry.ProxyResponsesTotal.WithLabelValues(domain, "200")) - ok0; got != 2 {
+		t.Errorf("200 responses = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(telemetry.ProxyResponsesTotal.WithLabelValues(domain, "404")) - nf0; got != 1 {
+		t.Errorf("404 responses = %v, want 1", got)
+	}
+}
+
+func histogramCount(t *testing.T, h prometheus.Histogram) uint64 {
+	t.Helper()
+	var m dto.Metric
+	if err := h.Write(&m); err != nil {
+		t.Fatal(err)
+	}
+	return m.GetHistogram().GetSampleCount()
+}
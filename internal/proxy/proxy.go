@@ -7,11 +7,15 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/mackeh/AegisClaw/internal/audit"
 	"github.com/mackeh/AegisClaw/internal/guardrails"
+	"github.com/mackeh/AegisClaw/internal/scope"
+	"github.com/mackeh/AegisClaw/internal/telemetry"
 )
 
 // dlpMaxBody bounds how much of a plaintext request body the proxy will buffer
@@ -28,15 +32,43 @@ const scanMaxBody = 4 << 20 // 4MB
 // blocked by default even when private-network egress is otherwise allowed.
 var metadataIPs = []string{"169.254.169.254", "100.100.100.200", "fd00:ec2::254"}
 
+// proxyActor is who egress decisions are attributed to in the audit log.
+var proxyActor = audit.SystemActor("proxy")
+
+// DefaultBlockedCIDRs is the blocklist NewEgressProxy starts with: the cloud
+// metadata endpoints, so they stay unreachable even if BlockMetadata is
+// turned off.
+var DefaultBlockedCIDRs = []string{"169.254.169.254/32", "100.100.100.200/32", "fd00:ec2::254/128"}
+
 // EgressProxy is a filtering forward proxy. Beyond a domain allowlist it
 // enforces SSRF protection (no loopback/private/link-local/metadata
 // destinations, validated at dial time to defeat DNS rebinding) and optional
-// outbound DLP (blocks plaintext requests carrying known secret values).
+// outbound DLP (blocks plaintext requests carrying known or credential-shaped
+// secret values).
 type EgressProxy struct {
 	AllowedDomains []string
 	Port           int
 	Logger         *audit.Logger
 
+	// ListenAddr is the IP Start binds to. Empty means 127.0.0.1; the
+	// sandbox sets the Docker bridge gateway so containers can reach it.
+	ListenAddr string
+
+	// DeniedDomains are carved out by exclusion scopes such as
+	// "!http.request:internal.example.com". Entries may be domains, IPs or
+	// CIDRs; they win over AllowedDomains and are checked again against
+	// resolved addresses at dial time.
+	DeniedDomains []string
+
+	// BlockedDomains and BlockedCIDRs are an operator blocklist, checked
+	// before the allowlist and before any DNS lookup: a match is always
+	// refused, also when AllowedDomains is empty (default allow). Domains
+	// cover their subdomains; CIDR entries (or bare IPs) are also checked
+	// against resolved addresses at dial time. Blocks are audited as
+	// "network.egress.blocked".
+	BlockedDomains []string
+	BlockedCIDRs   []string
+
 	// BlockPrivateIPs blocks destinations that resolve to loopback, private,
 	// link-local, or unspecified addresses. Default true.
 	BlockPrivateIPs bool
@@ -51,6 +83,16 @@ type EgressProxy struct {
 	Guard     *guardrails.Engine
 	GuardMode string // "off" (default), "warn", or "block"
 
+	// Upstream, when set, is a parent proxy that allowed traffic is forwarded
+	// through; hosts matching NoProxy are dialed directly. See SetUpstream.
+	Upstream *url.URL
+	NoProxy  []string
+
+	// DLP extends outbound DLP from registered secrets (AddSecret) to any
+	// plaintext request whose URL or body matches the guardrails secret
+	// patterns (API keys, tokens, private keys). Off by default.
+	DLP bool
+
 	secrets []string                            // known secret values for outbound DLP
 	resolve func(host string) ([]net.IP, error) // injectable for tests
 	server  *http.Server
@@ -60,6 +102,7 @@ func NewEgressProxy(allowed []string, logger *audit.Logger) *EgressProxy {
 	return &EgressProxy{
 		AllowedDomains:  allowed,
 		Logger:          logger,
+		BlockedCIDRs:    append([]string(nil), DefaultBlockedCIDRs...),
 		BlockPrivateIPs: true,
 		BlockMetadata:   true,
 		resolve:         net.LookupIP,
@@ -83,10 +126,14 @@ func (p *EgressProxy) isAllowed(host string) bool {
 
 	allowed := false
 	match := ""
+	excluded := p.excludedBy(h)
 
-	if len(p.AllowedDomains) == 0 {
+	switch {
+	case excluded != "":
+		// Exclusions always win over the allowlist.
+	case len(p.AllowedDomains) == 0:
 		allowed = true // Default allow if no domains specified
-	} else {
+	default:
 		for _, a := range p.AllowedDomains {
 			if h == a || strings.HasSuffix(h, "."+a) {
 				allowed = true
@@ -102,6 +149,8 @@ func (p *EgressProxy) isAllowed(host string) bool {
 		} else {
 			fmt.Printf("✅ Allowed egress to: %s (default allow)\n", h)
 		}
+	} else if excluded != "" {
+		fmt.Printf("🚫 Denied egress to: %s (excluded by %s)\n", h, excluded)
 	} else {
 		fmt.Printf("🚫 Denied egress to: %s\n", h)
 	}
@@ -111,10 +160,14 @@ func (p *EgressProxy) isAllowed(host string) bool {
 		if allowed {
 			decision = "allow"
 		}
-		_ = p.Logger.Log("network.egress", nil, decision, "proxy", map[string]any{
+		details := map[string]any{
 			"host":    h,
 			"matched": match,
-		})
+		}
+		if excluded != "" {
+			details["excluded"] = excluded
+		}
+		_ = p.Logger.LogAs("network.egress", nil, decision, proxyActor, details)
 	}
 
 	return allowed
@@ -123,6 +176,13 @@ func (p *EgressProxy) isAllowed(host string) bool {
 func (p *EgressProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	host := hostnameOnly(r.Host)
 
+	// Operator blocklist: refused before the allowlist or any DNS lookup.
+	if entry := p.blocklisted(host); entry != "" {
+		p.auditBlocked(host, entry)
+		http.Error(w, "Egress to this destination is blocklisted by AegisClaw", http.StatusForbidden)
+		return
+	}
+
 	// SSRF guard: reject internal/metadata destinations before anything else.
 	if blocked, reason := p.destBlocked(host); blocked {
 		p.auditDeny(host, reason)
@@ -149,18 +209,25 @@ func (p *EgressProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Standard HTTP Proxy with timeout and SSRF-safe dialing.
+	transport := &http.Transport{DialContext: p.safeDial}
+	if p.useUpstream(r.Host) {
+		transport = p.upstreamTransport()
+	}
 	client := &http.Client{
 		Timeout:   30 * time.Second,
-		Transport: &http.Transport{DialContext: p.safeDial},
+		Transport: transport,
 	}
 	r.RequestURI = ""
+	start := time.Now()
 	resp, err := client.Do(r)
 	if err != nil {
+		p.observe(host, start, http.StatusServiceUnavailable)
 		fmt.Printf("❌ Proxy HTTP request failed to %s: %v\n", r.URL.Host, err)
 		http.Error(w, err.Error(), http.StatusServiceUnavailable)
 		return
 	}
 	defer resp.Body.Close()
+	p.observe(host, start, resp.StatusCode)
 
 	fmt.Printf("✅ Proxy received response from %s with status: %d\n", r.URL.Host, resp.StatusCode)
 
@@ -199,7 +266,7 @@ func (p *EgressProxy) scanRelay(w http.ResponseWriter, resp *http.Response, host
 		}
 		fmt.Printf("⚠️  Guardrail violation in response from %s: %s\n", host, violationSummary(res.Violations))
 		if p.Logger != nil {
-			_ = p.Logger.Log("network.egress.response", nil, "warn", "proxy", map[string]any{
+			_ = p.Logger.LogAs("network.egress.response", nil, "warn", proxyActor, map[string]any{
 				"host": host, "violations": violationSummary(res.Violations),
 			})
 		}
@@ -216,13 +283,23 @@ func (p *EgressProxy) scanRelay(w http.ResponseWriter, resp *http.Response, host
 }
 
 func (p *EgressProxy) handleConnect(w http.ResponseWriter, r *http.Request) {
-	// Dial through the SSRF-safe dialer so the IP is validated at connect time.
-	destConn, err := p.safeDial(r.Context(), "tcp", r.Host)
+	// Dial through the SSRF-safe dialer so the IP is validated at connect time,
+	// or tunnel through the upstream proxy when one is configured.
+	var destConn net.Conn
+	var err error
+	start := time.Now()
+	if p.useUpstream(r.Host) {
+		destConn, err = p.dialUpstream(r.Context(), r.Host)
+	} else {
+		destConn, err = p.safeDial(r.Context(), "tcp", r.Host)
+	}
 	if err != nil {
+		p.observe(hostnameOnly(r.Host), start, http.StatusServiceUnavailable)
 		fmt.Printf("❌ Proxy CONNECT to %s failed: %v\n", r.Host, err)
 		http.Error(w, err.Error(), http.StatusServiceUnavailable)
 		return
 	}
+	p.observe(hostnameOnly(r.Host), start, http.StatusOK)
 	w.WriteHeader(http.StatusOK)
 
 	hijacker, ok := w.(http.Hijacker)
@@ -250,6 +327,22 @@ func (p *EgressProxy) handleConnect(w http.ResponseWriter, r *http.Request) {
 	}()
 }
 
+// observe records a proxied request's latency and status code under the
+// allowlist entry the host matched; with no allowlist the host itself is
+// the label. Either way telemetry.DomainLabel keeps the label set bounded.
+func (p *EgressProxy) observe(host string, start time.Time, code int) {
+	domain := host
+	for _, a := range p.AllowedDomains {
+		if host == a || strings.HasSuffix(host, "."+a) {
+			domain = a
+			break
+		}
+	}
+	domain = telemetry.DomainLabel(domain)
+	telemetry.ProxyRequestDuration.WithLabelValues(domain).Observe(time.Since(start).Seconds())
+	telemetry.ProxyResponsesTotal.WithLabelValues(domain, strconv.Itoa(code)).Inc()
+}
+
 // safeDial resolves the target, blocks it if any resolved IP is a forbidden
 // destination, and dials only a validated IP — closing the DNS-rebinding gap
 // between an allowlist check and the actual connection.
@@ -314,9 +407,15 @@ func (p *EgressProxy) destBlocked(host string) (bool, string) {
 
 // ipBlocked classifies a destination IP against the SSRF policy.
 func (p *EgressProxy) ipBlocked(ip net.IP) (bool, string) {
+	if x := p.excludedBy(ip.String()); x != "" {
+		return true, "excluded by scope (" + x + ")"
+	}
 	if p.BlockMetadata && isMetadataIP(ip) {
 		return true, "cloud metadata endpoint (" + ip.String() + ")"
 	}
+	if x := p.blockedCIDR(ip); x != "" {
+		return true, "blocklisted (" + x + ")"
+	}
 	if !p.BlockPrivateIPs {
 		return false, ""
 	}
@@ -333,6 +432,48 @@ func (p *EgressProxy) ipBlocked(ip net.IP) (bool, string) {
 	return false, ""
 }
 
+// excludedBy returns the DeniedDomains entry covering host, a name or an
+// IP address, or "" if none does.
+func (p *EgressProxy) excludedBy(host string) string {
+	for _, d := range p.DeniedDomains {
+		if scope.ResourceCovers(d, host) {
+			return d
+		}
+	}
+	return ""
+}
+
+// blocklisted returns the BlockedDomains or BlockedCIDRs entry covering
+// host, a name or an IP literal, or "" if none does. Names resolving into
+// a blocked CIDR are caught by ipBlocked.
+func (p *EgressProxy) blocklisted(host string) string {
+	if ip := net.ParseIP(host); ip != nil {
+		return p.blockedCIDR(ip)
+	}
+	h := strings.TrimSuffix(strings.ToLower(host), ".")
+	for _, d := range p.BlockedDomains {
+		d = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(d)), ".")
+		if d != "" && (h == d || strings.HasSuffix(h, "."+d)) {
+			return d
+		}
+	}
+	return ""
+}
+
+// blockedCIDR returns the BlockedCIDRs entry containing ip, or "".
+func (p *EgressProxy) blockedCIDR(ip net.IP) string {
+	for _, c := range p.BlockedCIDRs {
+		if _, network, err := net.ParseCIDR(c); err == nil {
+			if network.Contains(ip) {
+				return c
+			}
+		} else if parsed := net.ParseIP(c); parsed != nil && parsed.Equal(ip) {
+			return c
+		}
+	}
+	return ""
+}
+
 func (p *EgressProxy) resolveHost(host string) ([]net.IP, error) {
 	if p.resolve != nil {
 		return p.resolve(host)
@@ -340,41 +481,85 @@ func (p *EgressProxy) resolveHost(host string) ([]net.IP, error) {
 	return net.LookupIP(host)
 }
 
-// dlpRequest blocks a plaintext request that carries a known secret in its URL
-// or body. HTTPS (CONNECT) bodies are encrypted and cannot be inspected here —
+// dlpRequest blocks a plaintext request that carries a known secret, or in
+// DLP mode anything matching the guardrails secret patterns, in its URL or
+// body. HTTPS (CONNECT) bodies are encrypted and cannot be inspected here —
 // the LLM-proxy redactor covers that plane.
+//
+// Bodies of any length, including chunked ones, are inspected without
+// buffering them whole: up to dlpMaxBody bytes are read and scanned, then
+// spliced back in front of the unread remainder for forwarding. Content past
+// dlpMaxBody is not scanned.
 func (p *EgressProxy) dlpRequest(r *http.Request) (bool, string) {
-	if len(p.secrets) == 0 {
+	if len(p.secrets) == 0 && !p.DLP {
 		return false, ""
 	}
-	if containsAny(r.URL.String(), p.secrets) {
-		return true, "known secret present in request URL"
+	if reason := p.dlpMatch(r.URL.String()); reason != "" {
+		return true, reason + " present in request URL"
+	}
+	if r.Body == nil || r.Body == http.NoBody {
+		return false, ""
+	}
+	head, err := io.ReadAll(io.LimitReader(r.Body, dlpMaxBody))
+	if err != nil {
+		_ = r.Body.Close()
+		return true, "request body could not be read for inspection"
 	}
-	if r.Body != nil && r.ContentLength >= 0 && r.ContentLength <= dlpMaxBody {
-		body, err := io.ReadAll(io.LimitReader(r.Body, dlpMaxBody))
+	if reason := p.dlpMatch(string(head)); reason != "" {
 		_ = r.Body.Close()
-		if err == nil {
-			if containsAny(string(body), p.secrets) {
-				return true, "known secret present in request body"
+		return true, reason + " present in request body"
+	}
+	// Restore for forwarding: the scanned prefix, then whatever is left.
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), r.Body), r.Body}
+	return false, ""
+}
+
+// dlpMatch describes what s leaks, or returns "" if it is clean.
+func (p *EgressProxy) dlpMatch(s string) string {
+	if containsAny(s, p.secrets) {
+		return "known secret"
+	}
+	if p.DLP {
+		for _, pat := range guardrails.SecretPatterns() {
+			if pat.MatchString(s) {
+				return "credential-shaped value"
 			}
-			r.Body = io.NopCloser(bytes.NewReader(body)) // restore for forwarding
 		}
 	}
-	return false, ""
+	return ""
 }
 
 func (p *EgressProxy) auditDeny(host, reason string) {
 	fmt.Printf("🚫 Blocked egress to %s: %s\n", host, reason)
 	if p.Logger != nil {
-		_ = p.Logger.Log("network.egress", nil, "deny", "proxy", map[string]any{
+		_ = p.Logger.LogAs("network.egress", nil, "deny", proxyActor, map[string]any{
 			"host": host, "reason": reason,
 		})
 	}
 }
 
-// Start starts the proxy listening on 127.0.0.1:0
+// auditBlocked records a blocklist refusal under its own action, so
+// blocklist hits can be told apart from allowlist and SSRF denials.
+func (p *EgressProxy) auditBlocked(host, entry string) {
+	fmt.Printf("⛔ Blocklisted egress to %s (matched %s)\n", host, entry)
+	if p.Logger != nil {
+		_ = p.Logger.LogAs("network.egress.blocked", nil, "deny", proxyActor, map[string]any{
+			"host": host, "blocklist": entry,
+		})
+	}
+}
+
+// Start starts the proxy listening on a random port of ListenAddr
+// (127.0.0.1 by default).
 func (p *EgressProxy) Start() (string, error) {
-	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	host := p.ListenAddr
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	listener, err := net.Listen("tcp", net.JoinHostPort(host, "0"))
 	if err != nil {
 		return "", err
 	}
@@ -383,7 +568,7 @@ func (p *EgressProxy) Start() (string, error) {
 
 	go p.server.Serve(listener)
 
-	return fmt.Sprintf("http://127.0.0.1:%d", p.Port), nil
+	return "http://" + net.JoinHostPort(host, strconv.Itoa(p.Port)), nil
 }
 
 func (p *EgressProxy) Stop() error {
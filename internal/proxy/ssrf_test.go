@@ -5,8 +5,11 @@ import (
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/mackeh/AegisClaw/internal/audit"
 )
 
 // staticResolver returns a fixed IP set for any host, for deterministic SSRF tests.
@@ -131,3 +134,91 @@ func TestPublicDestinationNotBlockedBySSRF(t *testing.T) {
 		t.Errorf("public destination should not be blocked, got %q", reason)
 	}
 }
+
+func TestDeniedDomainsWinOverAllowlist(t *testing.T) {
+	p := NewEgressProxy([]string{"example.com"}, nil)
+	p.DeniedDomains = []string{"internal.example.com", "203.0.113.0/24"}
+
+	for host, want := range map[string]bool{
+		"api.example.com":         true,
+		"internal.example.com":    false,
+		"db.internal.example.com": false,
+	} {
+		if got := p.isAllowed(host); got != want {
+			t.Errorf("isAllowed(%s) = %v, want %v", host, got, want)
+		}
+	}
+
+	// A name that resolves into an excluded range is refused at dial time,
+	// even though private-network blocking is off.
+	p.BlockPrivateIPs = false
+	p.resolve = staticResolver("203.0.113.9")
+	if _, err := p.safeDial(context.Background(), "tcp", "api.example.com:443"); err == nil || !strings.Contains(err.Error(), "excluded") {
+		t.Errorf("safeDial into an excluded range: %v", err)
+	}
+}
+
+func TestBlocklistDeniesMetadataByDefault(t *testing.T) {
+	p := NewEgressProxy(nil, nil)
+	// Even with the SSRF checks switched off, the default blocklist keeps
+	// the metadata endpoint out of reach.
+	p.BlockMetadata = false
+	p.BlockPrivateIPs = false
+
+	req := httptest.NewRequest(http.MethodGet, "http://169.254.169.254/latest/meta-data/", nil)
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "blocklisted") {
+		t.Fatalf("metadata request: %d %q, want a 403 blocklist refusal", rec.Code, rec.Body.String())
+	}
+
+	// A name resolving to it is refused at dial time too.
+	p.resolve = staticResolver("169.254.169.254")
+	if _, err := p.safeDial(context.Background(), "tcp", "metadata.internal:80"); err == nil || !strings.Contains(err.Error(), "blocklisted") {
+		t.Errorf("safeDial to a name resolving to the metadata IP: %v", err)
+	}
+}
+
+func TestBlockedDomainRefusedWithEmptyAllowlist(t *testing.T) {
+	auditPath := filepath.Join(t.TempDir(), "audit.log")
+	logger, err := audit.NewLogger(auditPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	p := NewEgressProxy(nil, logger) // empty allowlist: default allow
+	p.BlockedDomains = []string{"malware.example"}
+	p.resolve = func(host string) ([]net.IP, error) {
+		t.Errorf("blocklisted host %s was resolved", host)
+		return nil, nil
+	}
+
+	for _, target := range []string{"http://malware.example/payload", "http://cdn.MALWARE.example/x"} {
+		rec := httptest.NewRecorder()
+		p.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("%s: status %d, want 403", target, rec.Code)
+		}
+	}
+	if !p.isAllowed("example.com") {
+		t.Error("a host not on the blocklist should stay allowed")
+	}
+
+	entries, err := audit.ReadAll(auditPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var blocked int
+	for _, e := range entries {
+		if e.Action == "network.egress.blocked" {
+			blocked++
+			if e.Decision != "deny" || e.Details["blocklist"] != "malware.example" {
+				t.Errorf("blocked entry = %q %v", e.Decision, e.Details)
+			}
+		}
+	}
+	if blocked != 2 {
+		t.Errorf("want 2 network.egress.blocked entries, got %d", blocked)
+	}
+}
@@ -0,0 +1,190 @@
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// UpstreamFromEnv returns the parent proxy and no-proxy list from the
+// standard HTTPS_PROXY / HTTP_PROXY / NO_PROXY variables (either case).
+func UpstreamFromEnv() (string, []string) {
+	upstream := firstEnv("HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy")
+	return upstream, SplitNoProxy(firstEnv("NO_PROXY", "no_proxy"))
+}
+
+// SplitNoProxy parses a comma-separated NO_PROXY value.
+func SplitNoProxy(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// SetUpstream configures a parent proxy that allowed traffic is forwarded
+// through, e.g. a mandatory corporate proxy. An empty rawURL falls back to
+// the HTTPS_PROXY/HTTP_PROXY environment; noProxy entries (and NO_PROXY when
+// noProxy is nil) name hosts that are dialed directly instead.
+//
+// Filtering is unchanged: the allowlist, SSRF and DLP checks run before
+// anything is forwarded. The upstream itself is operator-configured and is
+// exempt from the private-address block, since corporate proxies usually
+// live on internal addresses.
+func (p *EgressProxy) SetUpstream(rawURL string, noProxy []string) error {
+	envURL, envNoProxy := UpstreamFromEnv()
+	if rawURL == "" {
+		rawURL = envURL
+	}
+	if noProxy == nil {
+		noProxy = envNoProxy
+	}
+	if rawURL == "" {
+		p.Upstream = nil
+		p.NoProxy = noProxy
+		return nil
+	}
+
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid upstream proxy %q: %w", rawURL, err)
+	}
+	if u.Scheme != "http" || u.Host == "" {
+		return fmt.Errorf("invalid upstream proxy %q: must be an http:// proxy URL", rawURL)
+	}
+	p.Upstream = u
+	p.NoProxy = noProxy
+	return nil
+}
+
+// useUpstream reports whether traffic to host should go via the upstream.
+func (p *EgressProxy) useUpstream(host string) bool {
+	if p.Upstream == nil {
+		return false
+	}
+	return !matchNoProxy(hostnameOnly(host), p.NoProxy)
+}
+
+// matchNoProxy implements the common NO_PROXY conventions: "*" matches
+// everything, IPs and CIDRs match addresses, and a domain (with or without
+// a leading dot) matches itself and its subdomains.
+func matchNoProxy(host string, entries []string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	ip := net.ParseIP(host)
+	for _, e := range entries {
+		e = strings.ToLower(strings.TrimSpace(e))
+		if e == "" {
+			continue
+		}
+		if e == "*" {
+			return true
+		}
+		if ip != nil {
+			if _, cidr, err := net.ParseCIDR(e); err == nil {
+				if cidr.Contains(ip) {
+					return true
+				}
+				continue
+			}
+		}
+		e = hostnameOnly(e)
+		if eip := net.ParseIP(e); eip != nil {
+			if ip != nil && eip.Equal(ip) {
+				return true
+			}
+			continue
+		}
+		e = strings.TrimPrefix(e, "*")
+		e = strings.TrimPrefix(e, ".")
+		if host == e || strings.HasSuffix(host, "."+e) {
+			return true
+		}
+	}
+	return false
+}
+
+// upstreamTransport forwards plaintext requests through the upstream proxy.
+func (p *EgressProxy) upstreamTransport() *http.Transport {
+	d := &net.Dialer{Timeout: 10 * time.Second}
+	return &http.Transport{
+		Proxy:       http.ProxyURL(p.Upstream),
+		DialContext: d.DialContext,
+	}
+}
+
+// dialUpstream opens a tunnel to addr through the upstream proxy using
+// CONNECT, returning the established connection.
+func (p *EgressProxy) dialUpstream(ctx context.Context, addr string) (net.Conn, error) {
+	d := net.Dialer{Timeout: 10 * time.Second}
+	conn, err := d.DialContext(ctx, "tcp", p.Upstream.Host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach upstream proxy %s: %w", p.Upstream.Host, err)
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if u := p.Upstream.User; u != nil {
+		pass, _ := u.Password()
+		cred := base64.StdEncoding.EncodeToString([]byte(u.Username() + ":" + pass))
+		req.Header.Set("Proxy-Authorization", "Basic "+cred)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("upstream CONNECT to %s failed: %w", addr, err)
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("upstream CONNECT to %s failed: %w", addr, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("upstream proxy refused CONNECT to %s: %s", addr, resp.Status)
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	if br.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, r: br}, nil
+	}
+	return conn, nil
+}
+
+// bufferedConn drains bytes the CONNECT response reader over-read before
+// falling through to the raw connection.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) { return c.r.Read(b) }
+
+func firstEnv(keys ...string) string {
+	for _, k := range keys {
+		if v := os.Getenv(k); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,221 @@
+package proxy
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+)
+
+// fakeUpstream is a minimal parent proxy that records what it was asked to
+// forward. Plaintext requests get a canned body; CONNECT tunnels echo.
+type fakeUpstream struct {
+	mu      sync.Mutex
+	targets []string
+	auth    string
+}
+
+func (f *fakeUpstream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.targets = append(f.targets, r.Host)
+	f.auth = r.Header.Get("Proxy-Authorization")
+	f.mu.Unlock()
+
+	if r.Method != http.MethodConnect {
+		_, _ = io.WriteString(w, "via-upstream")
+		return
+	}
+	conn, buf, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	_, _ = io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+	_, _ = io.Copy(conn, buf)
+}
+
+func (f *fakeUpstream) seen() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.targets...)
+}
+
+func startChainedProxy(t *testing.T, p *EgressProxy) *url.URL {
+	t.Helper()
+	addr, err := p.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = p.Stop() })
+	u, _ := url.Parse(addr)
+	return u
+}
+
+func TestUpstreamForwardsAllowedHTTP(t *testing.T) {
+	up := &fakeUpstream{}
+	upSrv := httptest.NewServer(up)
+	defer upSrv.Close()
+
+	p := NewEgressProxy([]string{"example.com"}, nil)
+	p.resolve = staticResolver("93.184.216.34")
+	if err := p.SetUpstream(upSrv.URL, []string{}); err != nil {
+		t.Fatal(err)
+	}
+	proxyURL := startChainedProxy(t, p)
+
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+	resp, err := client.Get("http://example.com/data")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if string(body) != "via-upstream" {
+		t.Errorf("body = %q, want response relayed from upstream", body)
+	}
+	if got := up.seen(); len(got) != 1 || got[0] != "example.com" {
+		t.Errorf("upstream saw %v, want [example.com]", got)
+	}
+
+	// Denied hosts never reach the upstream.
+	resp, err = client.Get("http://blocked.test/")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("status = %d, want 403 for denied host", resp.StatusCode)
+	}
+	if got := up.seen(); len(got) != 1 {
+		t.Errorf("denied request leaked to upstream: %v", got)
+	}
+}
+
+func TestUpstreamTunnelsConnect(t *testing.T) {
+	up := &fakeUpstream{}
+	upSrv := httptest.NewServer(up)
+	defer upSrv.Close()
+
+	p := NewEgressProxy([]string{"example.com"}, nil)
+	p.resolve = staticResolver("93.184.216.34")
+	upURL, _ := url.Parse(upSrv.URL)
+	upURL.User = url.UserPassword("corp", "s3cret")
+	if err := p.SetUpstream(upURL.String(), []string{}); err != nil {
+		t.Fatal(err)
+	}
+	proxyURL := startChainedProxy(t, p)
+
+	conn, err := net.Dial("tcp", proxyURL.Host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_, _ = io.WriteString(conn, "CONNECT example.com:443 HTTP/1.1\r\nHost: example.com:443\r\n\r\n")
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, &http.Request{Method: http.MethodConnect})
+	if err != nil {
+		t.Fatalf("reading CONNECT response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("CONNECT status = %d", resp.StatusCode)
+	}
+
+	_, _ = io.WriteString(conn, "ping")
+	echo := make([]byte, 4)
+	if _, err := io.ReadFull(br, echo); err != nil || string(echo) != "ping" {
+		t.Errorf("tunnel echo = %q, %v", echo, err)
+	}
+	if got := up.seen(); len(got) != 1 || got[0] != "example.com:443" {
+		t.Errorf("upstream saw %v, want [example.com:443]", got)
+	}
+	if up.auth == "" {
+		t.Error("expected Proxy-Authorization from upstream userinfo")
+	}
+}
+
+func TestNoProxyHostGoesDirect(t *testing.T) {
+	up := &fakeUpstream{}
+	upSrv := httptest.NewServer(up)
+	defer upSrv.Close()
+
+	direct := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "direct")
+	}))
+	defer direct.Close()
+
+	p := NewEgressProxy(nil, nil)
+	p.BlockPrivateIPs = false // the direct target is a loopback test server
+	if err := p.SetUpstream(upSrv.URL, []string{"127.0.0.0/8"}); err != nil {
+		t.Fatal(err)
+	}
+	proxyURL := startChainedProxy(t, p)
+
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+	resp, err := client.Get(direct.URL)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if string(body) != "direct" {
+		t.Errorf("body = %q, want direct response", body)
+	}
+	if got := up.seen(); len(got) != 0 {
+		t.Errorf("no-proxy host was sent upstream: %v", got)
+	}
+}
+
+func TestMatchNoProxy(t *testing.T) {
+	entries := []string{"corp.internal", ".svc.local", "10.0.0.0/8", "192.168.1.5", "git.example.com:8443"}
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"corp.internal", true},
+		{"wiki.corp.internal", true},
+		{"notcorp.internal", false},
+		{"api.svc.local", true},
+		{"10.1.2.3", true},
+		{"11.1.2.3", false},
+		{"192.168.1.5", true},
+		{"git.example.com", true},
+		{"example.com", false},
+	}
+	for _, tt := range tests {
+		if got := matchNoProxy(tt.host, entries); got != tt.want {
+			t.Errorf("matchNoProxy(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+	if !matchNoProxy("anything.com", []string{"*"}) {
+		t.Error("* should match every host")
+	}
+}
+
+func TestSetUpstreamFromEnv(t *testing.T) {
+	t.Setenv("HTTPS_PROXY", "proxy.corp:3128")
+	t.Setenv("NO_PROXY", "localhost, .corp")
+
+	p := NewEgressProxy(nil, nil)
+	if err := p.SetUpstream("", nil); err != nil {
+		t.Fatal(err)
+	}
+	if p.Upstream == nil || p.Upstream.Host != "proxy.corp:3128" {
+		t.Fatalf("upstream = %v, want proxy.corp:3128", p.Upstream)
+	}
+	if p.useUpstream("build.corp:443") {
+		t.Error("NO_PROXY domain should bypass the upstream")
+	}
+	if !p.useUpstream("api.github.com:443") {
+		t.Error("external host should use the upstream")
+	}
+
+	if err := p.SetUpstream("socks5://proxy.corp:1080", nil); err == nil {
+		t.Error("expected non-http upstream to be rejected")
+	}
+}
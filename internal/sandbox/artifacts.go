@@ -0,0 +1,140 @@
+package sandbox
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+// DefaultMaxArtifactBytes caps the total size of collected outputs when
+// Config.MaxArtifactBytes is unset.
+const DefaultMaxArtifactBytes = 64 * 1024 * 1024
+
+// ErrArtifactTooLarge is reported when outputs exceed the size limit.
+var ErrArtifactTooLarge = errors.New("artifact exceeds size limit")
+
+// containerArchiver is the subset of the Docker client used to collect outputs.
+type containerArchiver interface {
+	CopyFromContainer(ctx context.Context, containerID, srcPath string) (io.ReadCloser, container.PathStat, error)
+}
+
+// ValidateOutputs checks that every declared output is a clean absolute
+// path inside OutputDir, the only writable location that survives the run.
+func ValidateOutputs(outputs []string) error {
+	for _, p := range outputs {
+		if !path.IsAbs(p) || path.Clean(p) != p {
+			return fmt.Errorf("invalid output path %q (want a clean absolute path)", p)
+		}
+		if p != OutputDir && !strings.HasPrefix(p, OutputDir+"/") {
+			return fmt.Errorf("output path %q must be inside %s", p, OutputDir)
+		}
+	}
+	return nil
+}
+
+// collectArtifacts copies each output out of a stopped container into
+// destDir, keeping its path relative to OutputDir. Only regular files and
+// directories are extracted; the total is capped at limit bytes. It returns
+// the host paths written and a joined error for outputs that were skipped.
+func collectArtifacts(ctx context.Context, cli containerArchiver, containerID string, outputs []string, destDir string, limit int64) ([]string, error) {
+	if limit <= 0 {
+		limit = DefaultMaxArtifactBytes
+	}
+	if err := os.MkdirAll(destDir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create artifacts directory: %w", err)
+	}
+
+	var written []string
+	var errs []error
+	remaining := limit
+	for _, out := range outputs {
+		rc, stat, err := cli.CopyFromContainer(ctx, containerID, out)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("output %s: %w", out, err))
+			continue
+		}
+		if !stat.Mode.IsDir() && stat.Size > remaining {
+			rc.Close()
+			errs = append(errs, fmt.Errorf("output %s: %w (%d bytes, %d remaining)", out, ErrArtifactTooLarge, stat.Size, remaining))
+			continue
+		}
+		// Archive entries are named from the output's base name down, e.g.
+		// "data/rows.csv" for /aegisclaw/output/data.
+		rel := strings.TrimPrefix(out, OutputDir)
+		files, n, err := extractArtifacts(rc, path.Base(out), filepath.Join(destDir, filepath.FromSlash(rel)), destDir, remaining)
+		rc.Close()
+		remaining -= n
+		written = append(written, files...)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("output %s: %w", out, err))
+		}
+	}
+	return written, errors.Join(errs...)
+}
+
+// extractArtifacts writes the regular files and directories of a tar stream
+// whose entries start with prefix to dest, refusing any entry that would
+// land outside root or push the total past limit. Partial files from an
+// oversized entry are removed.
+func extractArtifacts(r io.Reader, prefix, dest, root string, limit int64) ([]string, int64, error) {
+	var written []string
+	var total int64
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return written, total, nil
+		}
+		if err != nil {
+			return written, total, err
+		}
+
+		name := strings.TrimSuffix(hdr.Name, "/")
+		if name != prefix && !strings.HasPrefix(name, prefix+"/") {
+			return written, total, fmt.Errorf("unexpected artifact entry %q", hdr.Name)
+		}
+		target := filepath.Join(dest, filepath.FromSlash(strings.TrimPrefix(name, prefix)))
+		if rel, err := filepath.Rel(root, target); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return written, total, fmt.Errorf("artifact entry %q escapes the artifacts directory", hdr.Name)
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0700); err != nil {
+				return written, total, err
+			}
+		case tar.TypeReg:
+			if hdr.Size > limit-total {
+				return written, total, fmt.Errorf("%s: %w", hdr.Name, ErrArtifactTooLarge)
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+				return written, total, err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+			if err != nil {
+				return written, total, err
+			}
+			n, err := io.Copy(f, io.LimitReader(tr, limit-total+1))
+			f.Close()
+			if err == nil && n > limit-total {
+				err = fmt.Errorf("%s: %w", hdr.Name, ErrArtifactTooLarge)
+			}
+			if err != nil {
+				os.Remove(target)
+				return written, total, err
+			}
+			total += n
+			written = append(written, target)
+		default:
+			// Symlinks, devices and the like are never copied to the host.
+		}
+	}
+}
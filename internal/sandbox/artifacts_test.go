@@ -0,0 +1,158 @@
+package sandbox
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+// fakeArchiver serves CopyFromContainer from in-memory tars keyed by path.
+type fakeArchiver struct {
+	archives map[string][]byte
+	stats    map[string]container.PathStat
+}
+
+func (f *fakeArchiver) CopyFromContainer(ctx context.Context, containerID, srcPath string) (io.ReadCloser, container.PathStat, error) {
+	data, ok := f.archives[srcPath]
+	if !ok {
+		return nil, container.PathStat{}, errors.New("no such file")
+	}
+	return io.NopCloser(bytes.NewReader(data)), f.stats[srcPath], nil
+}
+
+type tarEntry struct {
+	name string
+	body string
+	dir  bool
+}
+
+func buildTar(t *testing.T, entries ...tarEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: 0644, Size: int64(len(e.body)), Typeflag: tar.TypeReg}
+		if e.dir {
+			hdr = &tar.Header{Name: e.name, Mode: 0755, Typeflag: tar.TypeDir}
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if !e.dir {
+			tw.Write([]byte(e.body))
+		}
+	}
+	tw.Close()
+	return buf.Bytes()
+}
+
+func TestCollectArtifacts_CopiesDeclaredOutputs(t *testing.T) {
+	fa := &fakeArchiver{
+		archives: map[string][]byte{
+			OutputDir + "/report.txt": buildTar(t, tarEntry{name: "report.txt", body: "all good"}),
+			OutputDir + "/data": buildTar(t,
+				tarEntry{name: "data/", dir: true},
+				tarEntry{name: "data/rows.csv", body: "a,b"},
+			),
+		},
+		stats: map[string]container.PathStat{
+			OutputDir + "/report.txt": {Size: 8},
+			OutputDir + "/data":       {Mode: os.ModeDir},
+		},
+	}
+	dest := t.TempDir()
+
+	files, err := collectArtifacts(context.Background(), fa, "c1", []string{OutputDir + "/report.txt", OutputDir + "/data"}, dest, 0)
+	if err != nil {
+		t.Fatalf("collectArtifacts: %v", err)
+	}
+	if len(files) != 2 {
+		t.Errorf("collected %v, want 2 files", files)
+	}
+	for rel, want := range map[string]string{"report.txt": "all good", "data/rows.csv": "a,b"} {
+		got, err := os.ReadFile(filepath.Join(dest, rel))
+		if err != nil || string(got) != want {
+			t.Errorf("%s = %q, %v; want %q", rel, got, err, want)
+		}
+	}
+}
+
+func TestCollectArtifacts_RejectsOversized(t *testing.T) {
+	big := string(bytes.Repeat([]byte("x"), 100))
+	fa := &fakeArchiver{
+		archives: map[string][]byte{
+			OutputDir + "/big.bin":   buildTar(t, tarEntry{name: "big.bin", body: big}),
+			OutputDir + "/small.txt": buildTar(t, tarEntry{name: "small.txt", body: "ok"}),
+			// A directory whose stat size is not known up front.
+			OutputDir + "/dir": buildTar(t, tarEntry{name: "dir/", dir: true}, tarEntry{name: "dir/huge", body: big}),
+		},
+		stats: map[string]container.PathStat{
+			OutputDir + "/big.bin":   {Size: 100},
+			OutputDir + "/small.txt": {Size: 2},
+			OutputDir + "/dir":       {Mode: os.ModeDir},
+		},
+	}
+	dest := t.TempDir()
+
+	outputs := []string{OutputDir + "/big.bin", OutputDir + "/small.txt", OutputDir + "/dir"}
+	files, err := collectArtifacts(context.Background(), fa, "c1", outputs, dest, 50)
+	if !errors.Is(err, ErrArtifactTooLarge) {
+		t.Fatalf("err = %v, want ErrArtifactTooLarge", err)
+	}
+	if len(files) != 1 || filepath.Base(files[0]) != "small.txt" {
+		t.Errorf("collected %v, want only small.txt", files)
+	}
+	for _, rel := range []string{"big.bin", "dir/huge"} {
+		if _, err := os.Stat(filepath.Join(dest, rel)); !os.IsNotExist(err) {
+			t.Errorf("oversized %s should not be on disk", rel)
+		}
+	}
+}
+
+func TestCollectArtifacts_RejectsEscapingEntries(t *testing.T) {
+	fa := &fakeArchiver{
+		archives: map[string][]byte{OutputDir + "/out": buildTar(t, tarEntry{name: "out/../../evil", body: "x"})},
+		stats:    map[string]container.PathStat{OutputDir + "/out": {Mode: os.ModeDir}},
+	}
+	dest := filepath.Join(t.TempDir(), "artifacts")
+
+	if _, err := collectArtifacts(context.Background(), fa, "c1", []string{OutputDir + "/out"}, dest, 0); err == nil {
+		t.Fatal("expected an error for an escaping entry")
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dest), "evil")); !os.IsNotExist(err) {
+		t.Error("escaping entry was written outside the artifacts directory")
+	}
+}
+
+func TestValidateOutputs(t *testing.T) {
+	if err := ValidateOutputs([]string{OutputDir, OutputDir + "/report.json"}); err != nil {
+		t.Errorf("valid outputs rejected: %v", err)
+	}
+	for _, p := range []string{"report.json", "/etc/passwd", "/tmp/out", OutputDir + "/../input/x", OutputDir + "x/y"} {
+		if err := ValidateOutputs([]string{p}); err == nil {
+			t.Errorf("ValidateOutputs(%q) should fail", p)
+		}
+	}
+}
+
+func TestPrepareWorkspace_OutputDir(t *testing.T) {
+	fc := &fakeCopier{}
+	if err := prepareWorkspace(context.Background(), fc, "c1", Config{Outputs: []string{OutputDir + "/r.txt"}}); err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(bytes.NewReader(fc.archive))
+	hdr, err := tr.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hdr.Name != "output/" || hdr.Uid != 1000 || hdr.Mode&0200 == 0 {
+		t.Errorf("output dir entry = %s uid=%d mode=%o, want output/ writable by 1000", hdr.Name, hdr.Uid, hdr.Mode)
+	}
+}
@@ -0,0 +1,120 @@
+package sandbox
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mackeh/AegisClaw/internal/scope"
+)
+
+// CapabilityScope is the scope name under which requested Linux capabilities
+// are evaluated by policy, e.g. "sandbox.capability:NET_BIND_SERVICE".
+const CapabilityScope = scope.CapabilityName
+
+// capabilityRisk classifies every Linux capability a skill may request.
+// Capabilities absent from this map are rejected; forbiddenCapabilities are
+// never granted regardless of policy or approval.
+var capabilityRisk = map[string]scope.Risk{
+	"NET_BIND_SERVICE": scope.RiskLow,
+	"KILL":             scope.RiskMedium,
+	"AUDIT_WRITE":      scope.RiskMedium,
+	"SYS_NICE":         scope.RiskMedium,
+	"SYS_RESOURCE":     scope.RiskMedium,
+	"IPC_LOCK":         scope.RiskMedium,
+	"WAKE_ALARM":       scope.RiskMedium,
+	"LEASE":            scope.RiskMedium,
+	"CHOWN":            scope.RiskHigh,
+	"FOWNER":           scope.RiskHigh,
+	"FSETID":           scope.RiskHigh,
+	"IPC_OWNER":        scope.RiskHigh,
+	"MKNOD":            scope.RiskHigh,
+	"SETGID":           scope.RiskHigh,
+	"SETUID":           scope.RiskHigh,
+	"SETFCAP":          scope.RiskHigh,
+	"SETPCAP":          scope.RiskHigh,
+	"SYS_CHROOT":       scope.RiskHigh,
+	"SYS_TTY_CONFIG":   scope.RiskHigh,
+	"BLOCK_SUSPEND":    scope.RiskHigh,
+	"NET_RAW":          scope.RiskCritical,
+	"NET_ADMIN":        scope.RiskCritical,
+	"DAC_OVERRIDE":     scope.RiskCritical,
+	"SYS_PACCT":        scope.RiskCritical,
+}
+
+// forbiddenCapabilities grant effective host control or kernel access and are
+// a well-known container-escape path.
+var forbiddenCapabilities = map[string]bool{
+	"ALL":                true,
+	"SYS_ADMIN":          true,
+	"SYS_MODULE":         true,
+	"SYS_RAWIO":          true,
+	"SYS_PTRACE":         true,
+	"SYS_BOOT":           true,
+	"SYS_TIME":           true,
+	"SYSLOG":             true,
+	"MAC_ADMIN":          true,
+	"MAC_OVERRIDE":       true,
+	"DAC_READ_SEARCH":    true,
+	"LINUX_IMMUTABLE":    true,
+	"AUDIT_CONTROL":      true,
+	"AUDIT_READ":         true,
+	"BPF":                true,
+	"PERFMON":            true,
+	"CHECKPOINT_RESTORE": true,
+}
+
+// NormalizeCapability upper-cases a capability name and strips any CAP_ prefix.
+func NormalizeCapability(name string) string {
+	return strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(name)), "CAP_")
+}
+
+// CapabilityScopes validates requested capabilities and returns one scope per
+// capability for policy evaluation. Forbidden or unknown capabilities are an
+// error. Capabilities at RiskCritical always require interactive approval.
+func CapabilityScopes(caps []string) ([]scope.Scope, error) {
+	seen := make(map[string]bool)
+	var scopes []scope.Scope
+	for _, c := range caps {
+		name := NormalizeCapability(c)
+		if forbiddenCapabilities[name] {
+			return nil, fmt.Errorf("capability %s is forbidden for skills", name)
+		}
+		risk, ok := capabilityRisk[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown capability %q", c)
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		scopes = append(scopes, scope.Scope{Name: CapabilityScope, Resource: name, RiskLevel: risk})
+	}
+	return scopes, nil
+}
+
+// EffectiveCapabilities returns the capabilities from capAdd that a
+// container process running as u actually holds. Docker raises added
+// capabilities into the permitted and effective sets only for root; a
+// non-root process has no ambient or inheritable capabilities, and
+// no-new-privileges stops file capabilities from granting them, so for it
+// they reach the bounding set and nothing more.
+func EffectiveCapabilities(u User, capAdd []string) []string {
+	if u.UID != 0 {
+		return nil
+	}
+	var caps []string
+	for _, c := range capAdd {
+		caps = append(caps, NormalizeCapability(c))
+	}
+	return caps
+}
+
+// checkCapabilities refuses capAdd when the process running as u would not
+// hold the capabilities, rather than starting a skill that silently lacks
+// what it asked for.
+func checkCapabilities(u User, capAdd []string) error {
+	if len(capAdd) == 0 || len(EffectiveCapabilities(u, capAdd)) > 0 {
+		return nil
+	}
+	return fmt.Errorf("capabilities %s have no effect for non-root user %s: only a root process holds added capabilities, so run the skill as root (security.allow_root_user) or drop them", strings.Join(capAdd, ", "), u)
+}
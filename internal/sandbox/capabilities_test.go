@@ -0,0 +1,80 @@
+package sandbox
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mackeh/AegisClaw/internal/scope"
+)
+
+func TestCapabilityScopes_TranslatesToCapAdd(t *testing.T) {
+	scopes, err := CapabilityScopes([]string{"cap_net_bind_service", "NET_BIND_SERVICE", "NET_RAW"})
+	if err != nil {
+		t.Fatalf("CapabilityScopes: %v", err)
+	}
+	if len(scopes) != 2 {
+		t.Fatalf("expected duplicates collapsed to 2 scopes, got %d", len(scopes))
+	}
+	if scopes[0].String() != "sandbox.capability:NET_BIND_SERVICE" || scopes[0].RiskLevel != scope.RiskLow {
+		t.Errorf("unexpected scope %+v", scopes[0])
+	}
+	if scopes[1].RiskLevel != scope.RiskCritical {
+		t.Errorf("NET_RAW risk = %s, want critical", scopes[1].RiskLevel)
+	}
+
+	_, hostCfg := hardenedConfigs(Config{Image: "alpine", CapAdd: []string{"NET_BIND_SERVICE"}}, nil)
+	if !reflect.DeepEqual([]string(hostCfg.CapAdd), []string{"NET_BIND_SERVICE"}) {
+		t.Errorf("CapAdd = %v", hostCfg.CapAdd)
+	}
+	if !reflect.DeepEqual([]string(hostCfg.CapDrop), []string{"ALL"}) {
+		t.Errorf("CapDrop = %v, want [ALL] baseline", hostCfg.CapDrop)
+	}
+}
+
+func TestCapabilityScopes_ForbidsDangerousCaps(t *testing.T) {
+	for _, c := range []string{"SYS_ADMIN", "cap_sys_module", "ALL", "BPF"} {
+		if _, err := CapabilityScopes([]string{c}); err == nil {
+			t.Errorf("expected %s to be forbidden", c)
+		}
+	}
+	if _, err := CapabilityScopes([]string{"MADE_UP"}); err == nil {
+		t.Error("expected unknown capability to be rejected")
+	}
+}
+
+func TestEffectiveCapabilities(t *testing.T) {
+	tests := []struct {
+		user User
+		caps []string
+		want []string
+	}{
+		{User{0, 0}, []string{"cap_net_bind_service"}, []string{"NET_BIND_SERVICE"}},
+		{User{0, 1000}, []string{"KILL"}, []string{"KILL"}},
+		{User{1000, 1000}, []string{"NET_BIND_SERVICE"}, nil},
+		{User{1000, 0}, []string{"NET_BIND_SERVICE"}, nil},
+		{User{1000, 1000}, nil, nil},
+	}
+	for _, tt := range tests {
+		if got := EffectiveCapabilities(tt.user, tt.caps); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("EffectiveCapabilities(%s, %v) = %v, want %v", tt.user, tt.caps, got, tt.want)
+		}
+	}
+}
+
+func TestRun_RefusesCapabilitiesForNonRootUser(t *testing.T) {
+	exec := &DockerExecutor{}
+	cfg := Config{Image: "alpine", CapAdd: []string{"NET_BIND_SERVICE"}}
+	if _, err := exec.Run(context.Background(), cfg); err == nil || !strings.Contains(err.Error(), "non-root") {
+		t.Errorf("Run: err = %v, want capabilities refused for the default user", err)
+	}
+	if _, err := exec.Start(context.Background(), cfg, nil, nil); err == nil || !strings.Contains(err.Error(), "non-root") {
+		t.Errorf("Start: err = %v, want capabilities refused for the default user", err)
+	}
+
+	u, err := containerUser(Config{User: "0:0", AllowRoot: true, CapAdd: cfg.CapAdd})
+	if err != nil || !reflect.DeepEqual(EffectiveCapabilities(u, cfg.CapAdd), cfg.CapAdd) {
+		t.Errorf("root with CapAdd: user %+v, err %v; want the capability effective", u, err)
+	}
+}
@@ -23,7 +23,7 @@ type ComposeConfig struct {
 	ComposeFile string                    // Path to docker-compose.yml
 	SkillName   string                    // For labeling and network naming
 	Services    map[string]ComposeService // Per-service scope declarations
-	Env         []string                  // Environment variables injected into all services
+	Env         []string                  // Environment variables injected into all services; the host environment is not inherited
 	AuditLogger *audit.Logger
 }
 
@@ -57,10 +57,7 @@ func (e *ComposeExecutor) Run(ctx context.Context, cfg ComposeConfig) (*ComposeR
 	defer removeNetwork(ctx, networkName)
 
 	// 2. Build environment
-	envVars := append(os.Environ(), cfg.Env...)
-	envVars = append(envVars,
-		fmt.Sprintf("AEGISCLAW_NETWORK=%s", networkName),
-	)
+	envVars := composeEnv(cfg.Env, networkName)
 
 	// 3. Prepare compose command
 	composeDir := filepath.Dir(cfg.ComposeFile)
@@ -86,7 +83,7 @@ func (e *ComposeExecutor) Run(ctx context.Context, cfg ComposeConfig) (*ComposeR
 
 	// 5. Log to audit trail
 	if cfg.AuditLogger != nil {
-		cfg.AuditLogger.Log("compose.exec", nil, "allow", cfg.SkillName, map[string]any{
+		cfg.AuditLogger.LogAs("compose.exec", nil, "allow", audit.SkillActor(cfg.SkillName), map[string]any{
 			"compose_file": cfg.ComposeFile,
 			"network":      networkName,
 			"services":     serviceNames(cfg.Services),
@@ -126,6 +123,28 @@ func (e *ComposeExecutor) Run(ctx context.Context, cfg ComposeConfig) (*ComposeR
 	}, nil
 }
 
+// composeHostEnv are the host variables the docker CLI itself needs to find
+// its compose plugin and reach the daemon. Nothing else is passed on: compose
+// interpolates ${VAR} and pass-through entries from its own environment, so
+// an inherited AWS_* or GITHUB_TOKEN would reach every service.
+var composeHostEnv = []string{
+	"PATH", "HOME", "XDG_RUNTIME_DIR",
+	"DOCKER_HOST", "DOCKER_CONTEXT", "DOCKER_CONFIG", "DOCKER_CERT_PATH", "DOCKER_TLS_VERIFY",
+}
+
+// composeEnv builds the environment of the docker compose process: the
+// docker CLI's own variables from the host, then the skill's declared env.
+func composeEnv(declared []string, networkName string) []string {
+	var env []string
+	for _, key := range composeHostEnv {
+		if v, ok := os.LookupEnv(key); ok {
+			env = append(env, key+"="+v)
+		}
+	}
+	env = append(env, declared...)
+	return append(env, "AEGISCLAW_NETWORK="+networkName)
+}
+
 func createNetwork(ctx context.Context, name string) error {
 	cmd := exec.CommandContext(ctx, "docker", "network", "create", "--internal", name)
 	out, err := cmd.CombinedOutput()
@@ -0,0 +1,55 @@
+package sandbox
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestComposeRun_DoesNotInheritHostEnv(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the docker binary")
+	}
+	dir := t.TempDir()
+	dump := filepath.Join(dir, "up.env")
+	// A fake docker CLI that records the environment `compose up` sees.
+	script := "#!/bin/sh\ncase \"$*\" in *\" up \"*) env > " + dump + " ;; esac\nexit 0\n"
+	if err := os.WriteFile(filepath.Join(dir, "docker"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	composeFile := filepath.Join(dir, "docker-compose.yml")
+	if err := os.WriteFile(composeFile, []byte("services: {}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "host-secret")
+	t.Setenv("GITHUB_TOKEN", "ghp_hosttoken")
+	t.Setenv("DOCKER_HOST", "unix:///tmp/docker.sock")
+
+	_, err := NewComposeExecutor().Run(context.Background(), ComposeConfig{
+		ComposeFile: composeFile,
+		SkillName:   "stack",
+		Env:         []string{"API_MODE=test"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(dump)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := string(data)
+	for _, leaked := range []string{"AWS_SECRET_ACCESS_KEY", "GITHUB_TOKEN", "host-secret"} {
+		if strings.Contains(env, leaked) {
+			t.Errorf("compose environment contains host %s", leaked)
+		}
+	}
+	for _, want := range []string{"API_MODE=test", "AEGISCLAW_NETWORK=aegisclaw-stack-", "DOCKER_HOST=unix:///tmp/docker.sock", "PATH="} {
+		if !strings.Contains(env, want) {
+			t.Errorf("compose environment is missing %s:\n%s", want, env)
+		}
+	}
+}
@@ -13,12 +13,10 @@ import (
 
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
-	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/api/types/network"
 	"github.com/docker/docker/client"
 	"github.com/docker/docker/pkg/stdcopy"
-	"github.com/mackeh/AegisClaw/internal/proxy"
 )
 
 // DockerExecutor implements Executor using Docker
@@ -37,36 +35,51 @@ func NewDockerExecutor() (*DockerExecutor, error) {
 
 // Run executes a command in a hardened Docker container
 func (e *DockerExecutor) Run(ctx context.Context, cfg Config) (*Result, error) {
+	user, err := containerUser(cfg)
+	if err != nil {
+		return nil, err
+	}
+	cfg.User = user.String()
+	if err := e.requireUsernsRemap(ctx, cfg); err != nil {
+		return nil, err
+	}
+	if err := CheckImageRegistry(cfg.Image, cfg.AllowedRegistries); err != nil {
+		return nil, err
+	}
+	if err := e.prepareRuntime(ctx, &cfg); err != nil {
+		return nil, err
+	}
+
+	if err := validateFiles(cfg.Files); err != nil {
+		return nil, err
+	}
+	if err := ValidateOutputs(cfg.Outputs); err != nil {
+		return nil, err
+	}
+	if err := ValidateMounts(cfg); err != nil {
+		return nil, err
+	}
+
 	// 1. Ensure image exists
-	if err := e.ensureImage(ctx, cfg.Image); err != nil {
+	if err := ensureImage(ctx, e.cli, cfg.Image, cfg.PullPolicy, cfg.pullProgress()); err != nil {
 		return nil, err
 	}
 
-	// Dynamic Network Configuration
-	var egressProxy *proxy.EgressProxy
-	proxyEnv := []string{}
+	// Dynamic Network Configuration: every networked run goes through the
+	// egress proxy so the blocklist and SSRF guards always apply.
+	egressProxy, proxyEnv, err := startEgressProxy(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if egressProxy != nil {
+		defer egressProxy.Stop()
+	}
 
-	if cfg.Network { // If network is requested, enable filtering if domains are specified
-		// Start egress proxy on host, listening on 127.0.0.1
-		if len(cfg.AllowedDomains) > 0 {
-			fmt.Printf("🌐 Enabling egress filtering for domains: %v\n", cfg.AllowedDomains)
-			egressProxy = proxy.NewEgressProxy(cfg.AllowedDomains, cfg.AuditLogger)
-			_, err := egressProxy.Start() // Proxy binds to 127.0.0.1
-			if err != nil {
-				return nil, fmt.Errorf("failed to start egress proxy: %w", err)
-			}
-			defer egressProxy.Stop()
-
-			containerProxyURL := fmt.Sprintf("http://host.docker.internal:%d", egressProxy.Port)
-			proxyEnv = []string{
-				"http_proxy=" + containerProxyURL,
-				"https_proxy=" + containerProxyURL,
-				"HTTP_PROXY=" + containerProxyURL,
-				"HTTPS_PROXY=" + containerProxyURL,
-				"NO_PROXY=127.0.0.1,localhost", // Ensure no bypass for local traffic
-			}
-		}
+	cleanupSecrets, err := mountSecretFiles(&cfg)
+	if err != nil {
+		return nil, err
 	}
+	defer cleanupSecrets()
 
 	// 2. Build hardened container + host config (shared with Start).
 	config, hostConfig := hardenedConfigs(cfg, proxyEnv)
@@ -78,10 +91,32 @@ func (e *DockerExecutor) Run(ctx context.Context, cfg Config) (*Result, error) {
 
 	containerID := resp.ID
 
+	// 3. Inject input files before the command can run
+	if err := prepareWorkspace(ctx, e.cli, containerID, cfg); err != nil {
+		_ = e.cli.ContainerRemove(context.Background(), containerID, container.RemoveOptions{Force: true, RemoveVolumes: true})
+		return nil, err
+	}
+
+	var stdin *stdinStream
+	if cfg.Stdin != nil {
+		if stdin, err = attachStdin(ctx, e.cli, containerID, cfg.Stdin); err != nil {
+			_ = e.cli.ContainerRemove(context.Background(), containerID, container.RemoveOptions{Force: true, RemoveVolumes: true})
+			return nil, err
+		}
+		defer stdin.Close()
+	}
+
 	// 4. Start Container
 	if err := e.cli.ContainerStart(ctx, containerID, container.StartOptions{}); err != nil {
 		return nil, fmt.Errorf("failed to start container: %w", err)
 	}
+	if stdin != nil {
+		go stdin.feed()
+	}
+
+	if cfg.OnStart != nil {
+		cfg.OnStart(containerID)
+	}
 
 	// 4. Attach to logs
 	out, err := e.cli.ContainerLogs(ctx, containerID, container.LogsOptions{
@@ -112,53 +147,74 @@ func (e *DockerExecutor) Run(ctx context.Context, cfg Config) (*Result, error) {
 	case err := <-errCh:
 		return nil, fmt.Errorf("error waiting for container: %w", err)
 	case status := <-statusCh:
-		_ = e.cli.ContainerRemove(context.Background(), containerID, container.RemoveOptions{})
+		result := &Result{
+			ExitCode:    int(status.StatusCode),
+			Reason:      e.exitReason(context.Background(), containerID, int(status.StatusCode)),
+			Stdout:      stdoutReader,
+			Stderr:      stderrReader,
+			ImageDigest: e.imageDigest(context.Background(), cfg.Image),
+		}
+		// Outputs live in the workspace volume, so collect them before the
+		// container (and volume) are removed.
+		if len(cfg.Outputs) > 0 && cfg.ArtifactsDir != "" {
+			result.Artifacts, result.ArtifactErr = collectArtifacts(ctx, e.cli, containerID, cfg.Outputs, cfg.ArtifactsDir, cfg.MaxArtifactBytes)
+		}
+		if shouldRetain(cfg, result) {
+			result.Retained = true
+			fmt.Printf("🔍 Keeping failed container %.12s for %s: docker logs %.12s\n", containerID, retainFor(cfg), containerID)
+		} else {
+			_ = e.cli.ContainerRemove(context.Background(), containerID, container.RemoveOptions{RemoveVolumes: true})
+		}
 
-		return &Result{
-			ExitCode: int(status.StatusCode),
-			Stdout:   stdoutReader,
-			Stderr:   stderrReader,
-		}, nil
+		return result, nil
 	case <-ctx.Done():
 		_ = e.cli.ContainerKill(ctx, containerID, "SIGKILL")
 		return nil, ctx.Err()
 	}
 }
 
-// ensureImage pulls the image if it is not already present locally.
-func (e *DockerExecutor) ensureImage(ctx context.Context, img string) error {
-	if _, _, err := e.cli.ImageInspectWithRaw(ctx, img); err == nil {
-		return nil
-	} else if !client.IsErrNotFound(err) {
-		return fmt.Errorf("failed to inspect image: %w", err)
-	}
-
-	fmt.Printf("📥 Pulling image %s...\n", img)
-	reader, err := e.cli.ImagePull(ctx, img, image.PullOptions{})
+// imageDigest resolves the content digest of img, preferring a registry
+// repo digest and falling back to the local image ID.
+func (e *DockerExecutor) imageDigest(ctx context.Context, img string) string {
+	inspect, _, err := e.cli.ImageInspectWithRaw(ctx, img)
 	if err != nil {
-		return fmt.Errorf("failed to pull image: %w", err)
+		return ""
 	}
-	defer reader.Close()
-	_, _ = io.Copy(io.Discard, reader)
-	return nil
+	if len(inspect.RepoDigests) > 0 {
+		return inspect.RepoDigests[0]
+	}
+	return inspect.ID
 }
 
 // hardenedConfigs builds the security-hardened container and host configuration
-// shared by Run (one-shot skills) and Start (detached agents). extraEnv is
-// appended to the caller's environment, e.g. egress proxy variables.
-func hardenedConfigs(cfg Config, extraEnv []string) (*container.Config, *container.HostConfig) {
+// shared by Run (one-shot skills) and Start (detached agents). proxyEnv holds
+// the egress proxy variables, if egress is proxied, and is appended to the
+// caller's environment.
+func hardenedConfigs(cfg Config, proxyEnv []string) (*container.Config, *container.HostConfig) {
+	memory, nanoCPUs, pids := cfg.MemoryBytes, cfg.NanoCPUs, cfg.PidsLimit
+	if memory <= 0 {
+		memory = DefaultMemoryBytes
+	}
+	if nanoCPUs <= 0 {
+		nanoCPUs = DefaultNanoCPUs
+	}
+	if pids <= 0 {
+		pids = DefaultPidsLimit
+	}
+
 	hostConfig := &container.HostConfig{
 		Runtime:        cfg.Runtime,
 		CapDrop:        []string{"ALL"},               // Drop ALL capabilities
+		CapAdd:         cfg.CapAdd,                    // ...then re-add only approved ones
 		SecurityOpt:    []string{"no-new-privileges"}, // No privilege escalation
 		ReadonlyRootfs: true,                          // Read-only root filesystem
 		Resources: container.Resources{
-			Memory:     512 * 1024 * 1024, // 512MB RAM limit
-			MemorySwap: 512 * 1024 * 1024, // No swap
-			NanoCPUs:   1000000000,        // 1 CPU
-			PidsLimit:  &[]int64{100}[0],  // Limit processes
+			Memory:     memory,   // RAM limit
+			MemorySwap: memory,   // No swap
+			NanoCPUs:   nanoCPUs, // CPU quota
+			PidsLimit:  &pids,    // Limit processes
 		},
-		ExtraHosts: []string{"host.docker.internal:host-gateway"}, // Reach host proxy
+		ExtraHosts: []string{ContainerHostAlias + ":host-gateway"}, // Reach host proxy
 	}
 
 	// Apply Seccomp profile if provided (read content for local-daemon compatibility).
@@ -179,27 +235,71 @@ func hardenedConfigs(cfg Config, extraEnv []string) (*container.Config, *contain
 			ReadOnly: m.ReadOnly,
 		})
 	}
-	// Always provide a writable tmpfs for /tmp since the rootfs is read-only.
-	mounts = append(mounts, mount.Mount{Type: mount.TypeTmpfs, Target: "/tmp"})
+	mounts = append(mounts, exclusionMasks(cfg)...)
+	mounts = append(mounts, tmpfsMounts(cfg)...)
 	hostConfig.Mounts = mounts
 
+	env := append(cfg.Env, proxyEnv...)
+	if len(cfg.Files) > 0 || len(cfg.Outputs) > 0 {
+		hostConfig.Mounts = append(hostConfig.Mounts, workspaceMount())
+	}
+	if len(cfg.Files) > 0 {
+		env = append(env, "AEGISCLAW_INPUT_DIR="+InputDir)
+	}
+	if len(cfg.Outputs) > 0 {
+		env = append(env, "AEGISCLAW_OUTPUT_DIR="+OutputDir)
+	}
+
 	if cfg.Network {
 		hostConfig.NetworkMode = "bridge"
+		hostConfig.DNS = cfg.DNS
+		if len(proxyEnv) > 0 {
+			// The proxy resolves names on the host; a resolver inside the
+			// container would only be a way around it (DNS tunnelling).
+			hostConfig.DNS = []string{sinkholeDNS}
+		}
+		// "." stops Docker copying the host's search domains into the
+		// container, which would reveal the host's network.
+		hostConfig.DNSSearch = []string{"."}
+		if !cfg.IPv6 {
+			hostConfig.Sysctls = map[string]string{
+				"net.ipv6.conf.all.disable_ipv6":     "1",
+				"net.ipv6.conf.default.disable_ipv6": "1",
+			}
+		}
 	} else {
 		hostConfig.NetworkMode = "none" // Default-deny network
 	}
 
+	user := cfg.User
+	if user == "" {
+		user = DefaultUser
+	}
 	config := &container.Config{
 		Image:        cfg.Image,
 		Cmd:          cfg.Command,
-		Env:          append(cfg.Env, extraEnv...),
+		Env:          env,
+		Hostname:     SandboxHostname, // not the container ID, nothing host-derived
 		WorkingDir:   cfg.WorkDir,
-		User:         "1000:1000", // Non-root user
+		User:         user, // Non-root unless an approved override allows root
 		AttachStdout: true,
 		AttachStderr: true,
 		Tty:          false,
 		Labels:       map[string]string{"managed_by": "aegisclaw"},
 	}
+	if cfg.Stdin != nil {
+		config.AttachStdin = true
+		config.OpenStdin = true
+		config.StdinOnce = true
+	}
+	for k, v := range cfg.Labels {
+		if k != "managed_by" {
+			config.Labels[k] = v
+		}
+	}
+	if cfg.RetainOnFailure {
+		config.Labels[RetainForLabel] = retainFor(cfg).String()
+	}
 	return config, hostConfig
 }
 
@@ -225,10 +325,42 @@ type procExit struct {
 // filtering inject proxy environment variables via cfg.Env and set cfg.Network
 // to true. Cancelling ctx force-stops the container.
 func (e *DockerExecutor) Start(ctx context.Context, cfg Config, stdout, stderr io.Writer) (*Process, error) {
-	if err := e.ensureImage(ctx, cfg.Image); err != nil {
+	if cfg.Stdin != nil {
+		return nil, fmt.Errorf("stdin cannot be piped into a detached container")
+	}
+	user, err := containerUser(cfg)
+	if err != nil {
+		return nil, err
+	}
+	cfg.User = user.String()
+	if err := CheckImageRegistry(cfg.Image, cfg.AllowedRegistries); err != nil {
+		return nil, err
+	}
+	if err := e.prepareRuntime(ctx, &cfg); err != nil {
+		return nil, err
+	}
+	if err := validateFiles(cfg.Files); err != nil {
+		return nil, err
+	}
+	if err := ValidateMounts(cfg); err != nil {
+		return nil, err
+	}
+	if err := ensureImage(ctx, e.cli, cfg.Image, cfg.PullPolicy, cfg.pullProgress()); err != nil {
 		return nil, err
 	}
 
+	// Staged secrets live until the container is removed.
+	cleanupSecrets, err := mountSecretFiles(&cfg)
+	if err != nil {
+		return nil, err
+	}
+	started := false
+	defer func() {
+		if !started {
+			cleanupSecrets()
+		}
+	}()
+
 	config, hostConfig := hardenedConfigs(cfg, nil)
 	resp, err := e.cli.ContainerCreate(ctx, config, hostConfig, &network.NetworkingConfig{}, nil, "")
 	if err != nil {
@@ -236,14 +368,19 @@ func (e *DockerExecutor) Start(ctx context.Context, cfg Config, stdout, stderr i
 	}
 	id := resp.ID
 
+	if err := prepareWorkspace(ctx, e.cli, id, cfg); err != nil {
+		_ = e.cli.ContainerRemove(context.Background(), id, container.RemoveOptions{Force: true, RemoveVolumes: true})
+		return nil, err
+	}
+
 	if err := e.cli.ContainerStart(ctx, id, container.StartOptions{}); err != nil {
-		_ = e.cli.ContainerRemove(context.Background(), id, container.RemoveOptions{Force: true})
+		_ = e.cli.ContainerRemove(context.Background(), id, container.RemoveOptions{Force: true, RemoveVolumes: true})
 		return nil, fmt.Errorf("failed to start container: %w", err)
 	}
 
 	logs, err := e.cli.ContainerLogs(ctx, id, container.LogsOptions{ShowStdout: true, ShowStderr: true, Follow: true})
 	if err != nil {
-		_ = e.cli.ContainerRemove(context.Background(), id, container.RemoveOptions{Force: true})
+		_ = e.cli.ContainerRemove(context.Background(), id, container.RemoveOptions{Force: true, RemoveVolumes: true})
 		return nil, fmt.Errorf("failed to attach logs: %w", err)
 	}
 	go func() {
@@ -268,7 +405,8 @@ func (e *DockerExecutor) Start(ctx context.Context, cfg Config, stdout, stderr i
 			p.exitCh <- procExit{code: int(status.StatusCode)}
 		}
 		close(p.done)
-		_ = e.cli.ContainerRemove(context.Background(), id, container.RemoveOptions{Force: true})
+		_ = e.cli.ContainerRemove(context.Background(), id, container.RemoveOptions{Force: true, RemoveVolumes: true})
+		cleanupSecrets()
 	}()
 
 	// Tie ctx cancellation to container termination.
@@ -280,6 +418,7 @@ func (e *DockerExecutor) Start(ctx context.Context, cfg Config, stdout, stderr i
 		}
 	}()
 
+	started = true
 	return p, nil
 }
 
@@ -298,6 +437,52 @@ func (p *Process) Stop() error {
 // ContainerID returns the underlying container ID.
 func (p *Process) ContainerID() string { return p.containerID }
 
+// Done is closed once the container has exited.
+func (p *Process) Done() <-chan struct{} { return p.done }
+
+// ExecProbe runs cmd inside a running container, e.g. a skill's health
+// check, and returns an error unless it exits 0.
+func (e *DockerExecutor) ExecProbe(ctx context.Context, id string, cmd []string) error {
+	created, err := e.cli.ContainerExecCreate(ctx, id, container.ExecOptions{Cmd: cmd, AttachStdout: true, AttachStderr: true})
+	if err != nil {
+		return fmt.Errorf("failed to create probe: %w", err)
+	}
+	attach, err := e.cli.ContainerExecAttach(ctx, created.ID, container.ExecAttachOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to run probe: %w", err)
+	}
+	_, _ = stdcopy.StdCopy(io.Discard, io.Discard, attach.Reader)
+	attach.Close()
+	inspect, err := e.cli.ContainerExecInspect(ctx, created.ID)
+	if err != nil {
+		return fmt.Errorf("failed to inspect probe: %w", err)
+	}
+	if inspect.Running {
+		return fmt.Errorf("probe still running")
+	}
+	if inspect.ExitCode != 0 {
+		return fmt.Errorf("probe exited with code %d", inspect.ExitCode)
+	}
+	return nil
+}
+
+// ContainerIP returns the address of a running container on its network.
+// Containers without network access have none.
+func (e *DockerExecutor) ContainerIP(ctx context.Context, id string) (string, error) {
+	inspect, err := e.cli.ContainerInspect(ctx, id)
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect container: %w", err)
+	}
+	if inspect.NetworkSettings != nil {
+		for _, n := range inspect.NetworkSettings.Networks {
+			if n != nil && n.IPAddress != "" {
+				return n.IPAddress, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("container %.12s has no network address", id)
+}
+
 // KillAll force-stops and removes all containers managed by AegisClaw
 func (e *DockerExecutor) KillAll(ctx context.Context) error {
 	filters := filters.NewArgs()
@@ -318,11 +503,39 @@ func (e *DockerExecutor) KillAll(ctx context.Context) error {
 	return nil
 }
 
-// Cleanup is a no-op for now as we remove containers after run
-func (e *DockerExecutor) Cleanup(ctx context.Context) error {
+// KillContainer force-stops a single container.
+func (e *DockerExecutor) KillContainer(ctx context.Context, id string) error {
+	if err := e.cli.ContainerKill(ctx, id, "SIGKILL"); err != nil {
+		return fmt.Errorf("failed to kill container %s: %w", id, err)
+	}
 	return nil
 }
 
+// FindByLabel returns the IDs of running AegisClaw containers whose label
+// key equals value.
+func (e *DockerExecutor) FindByLabel(ctx context.Context, key, value string) ([]string, error) {
+	f := filters.NewArgs()
+	f.Add("label", "managed_by=aegisclaw")
+	f.Add("label", key+"="+value)
+
+	containers, err := e.cli.ContainerList(ctx, container.ListOptions{Filters: f})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+	ids := make([]string, 0, len(containers))
+	for _, c := range containers {
+		ids = append(ids, c.ID)
+	}
+	return ids, nil
+}
+
+// Cleanup removes retained containers whose retention window has passed;
+// see ReapRetained.
+func (e *DockerExecutor) Cleanup(ctx context.Context) error {
+	_, err := e.ReapRetained(ctx, time.Now())
+	return err
+}
+
 // getBridgeIP attempts to find the IP of the host on the default docker0 bridge
 // This is a fallback/helper, for temporary networks we use the network's gateway IP.
 func getBridgeIP() string {
@@ -0,0 +1,86 @@
+package sandbox
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/mackeh/AegisClaw/internal/proxy"
+)
+
+// ContainerHostAlias is the name a sandboxed container uses to reach
+// host-side services (the egress proxy, the secrets write callback). It is
+// mapped to the Docker host gateway via ExtraHosts.
+const ContainerHostAlias = "host.docker.internal"
+
+// bridgeInterface is the default Docker bridge on Linux hosts.
+const bridgeInterface = "docker0"
+
+// HostGatewayAddr returns the host IP that host-side services should bind
+// to so containers on the default bridge can reach them: the docker0
+// gateway address on Linux, or 127.0.0.1 where Docker Desktop forwards
+// host.docker.internal to the host's loopback.
+func HostGatewayAddr() string {
+	iface, err := net.InterfaceByName(bridgeInterface)
+	if err != nil {
+		return "127.0.0.1"
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return "127.0.0.1"
+	}
+	for _, a := range addrs {
+		if ipn, ok := a.(*net.IPNet); ok && ipn.IP.To4() != nil {
+			return ipn.IP.String()
+		}
+	}
+	return "127.0.0.1"
+}
+
+// startEgressProxy starts the filtering proxy for a networked run and
+// returns the environment that points the container at it. Every networked
+// run is proxied, including default-allow ones with no AllowedDomains, so
+// the operator blocklist and the metadata/SSRF guards always apply. A run
+// without network access gets no proxy (nil, nil, nil).
+func startEgressProxy(cfg Config) (*proxy.EgressProxy, []string, error) {
+	if !cfg.Network {
+		return nil, nil, nil
+	}
+	if len(cfg.AllowedDomains) > 0 {
+		fmt.Printf("🌐 Enabling egress filtering for domains: %v\n", cfg.AllowedDomains)
+	} else {
+		fmt.Println("🌐 Enabling egress filtering (default allow, blocklist enforced)")
+	}
+	if len(cfg.DeniedDomains) > 0 {
+		fmt.Printf("🚫 Excluded from egress: %v\n", cfg.DeniedDomains)
+	}
+	p := proxy.NewEgressProxy(cfg.AllowedDomains, cfg.AuditLogger)
+	p.ListenAddr = HostGatewayAddr()
+	p.DeniedDomains = cfg.DeniedDomains
+	p.BlockedDomains = cfg.BlockedDomains
+	p.BlockedCIDRs = append(p.BlockedCIDRs, cfg.BlockedCIDRs...)
+	p.DLP = cfg.DLP
+	if err := p.SetUpstream(cfg.UpstreamProxy, cfg.NoProxy); err != nil {
+		return nil, nil, err
+	}
+	if _, err := p.Start(); err != nil {
+		return nil, nil, fmt.Errorf("failed to start egress proxy: %w", err)
+	}
+	return p, proxyEnv(p.Port), nil
+}
+
+// proxyEnv is the environment that routes a container's HTTP(S) traffic
+// through the egress proxy on port. Host-side callbacks reached via
+// ContainerHostAlias (e.g. the secrets write API) bypass it: the proxy
+// would refuse them as a private destination.
+func proxyEnv(port int) []string {
+	url := fmt.Sprintf("http://%s:%d", ContainerHostAlias, port)
+	noProxy := "127.0.0.1,localhost," + ContainerHostAlias
+	return []string{
+		"http_proxy=" + url,
+		"https_proxy=" + url,
+		"HTTP_PROXY=" + url,
+		"HTTPS_PROXY=" + url,
+		"no_proxy=" + noProxy,
+		"NO_PROXY=" + noProxy,
+	}
+}
@@ -0,0 +1,136 @@
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/mackeh/AegisClaw/internal/secrets"
+)
+
+func TestStartEgressProxy_DefaultAllowStillBlocks(t *testing.T) {
+	// A bare http.request skill: networked, no allowlist.
+	p, env, err := startEgressProxy(Config{Network: true, BlockedDomains: []string{"evil.example"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p == nil {
+		t.Fatal("networked default-allow run started no egress proxy")
+	}
+	defer p.Stop()
+
+	wantProxy := "HTTP_PROXY=http://" + ContainerHostAlias + ":" + strconv.Itoa(p.Port)
+	found := false
+	for _, e := range env {
+		found = found || e == wantProxy
+	}
+	if !found {
+		t.Errorf("proxy env %v missing %s", env, wantProxy)
+	}
+
+	proxyURL := &url.URL{Scheme: "http", Host: net.JoinHostPort(p.ListenAddr, strconv.Itoa(p.Port))}
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+	for _, target := range []string{"http://evil.example/", "http://api.evil.example/x", "http://169.254.169.254/latest/meta-data/"} {
+		resp, err := client.Get(target)
+		if err != nil {
+			t.Fatalf("GET %s: %v", target, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("GET %s via default-allow proxy = %d, want 403", target, resp.StatusCode)
+		}
+	}
+}
+
+func TestStartEgressProxy_OfflineRunHasNoProxy(t *testing.T) {
+	p, env, err := startEgressProxy(Config{BlockedDomains: []string{"evil.example"}})
+	if err != nil || p != nil || env != nil {
+		t.Errorf("offline run: proxy=%v env=%v err=%v, want none", p, env, err)
+	}
+}
+
+func TestProxyEnv_HostCallbacksBypassProxy(t *testing.T) {
+	for _, e := range proxyEnv(1234) {
+		if k, v, _ := strings.Cut(e, "="); strings.EqualFold(k, "no_proxy") {
+			if !strings.Contains(v, ContainerHostAlias) {
+				t.Errorf("%s does not exempt %s", e, ContainerHostAlias)
+			}
+		}
+	}
+}
+
+type memStore map[string]string
+
+func (m memStore) Get(k string) (string, error) { return m[k], nil }
+func (m memStore) Set(k, v string) error        { m[k] = v; return nil }
+func (m memStore) Delete(k string) error        { delete(m, k); return nil }
+func (m memStore) List() ([]string, error)      { return nil, nil }
+
+// TestSecretsWriteFromProxiedSandbox replays what a skill's HTTP client does
+// inside a proxied container: honour HTTP_PROXY/NO_PROXY from its env and
+// resolve host.docker.internal to the host gateway.
+func TestSecretsWriteFromProxiedSandbox(t *testing.T) {
+	p, env, err := startEgressProxy(Config{Network: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+
+	store := memStore{}
+	api, err := secrets.NewWriteAPI(store, []string{"NEW_TOKEN"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	api.ListenAddr = HostGatewayAddr()
+	if err := api.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer api.Stop()
+
+	vars := map[string]string{}
+	for _, e := range env {
+		k, v, _ := strings.Cut(e, "=")
+		vars[k] = v
+	}
+	proxyURL, err := url.Parse(vars["HTTP_PROXY"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport := &http.Transport{
+		Proxy: func(r *http.Request) (*url.URL, error) {
+			for _, h := range strings.Split(vars["NO_PROXY"], ",") {
+				if r.URL.Hostname() == h {
+					return nil, nil
+				}
+			}
+			return proxyURL, nil
+		},
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			host, port, _ := net.SplitHostPort(addr)
+			if host == ContainerHostAlias {
+				addr = net.JoinHostPort(HostGatewayAddr(), port)
+			}
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		},
+	}
+
+	target := fmt.Sprintf("http://%s:%d/v1/secrets/NEW_TOKEN", ContainerHostAlias, api.Port)
+	req, _ := http.NewRequest(http.MethodPut, target, strings.NewReader("s3cr3t"))
+	req.Header.Set("Authorization", "Bearer "+api.Token())
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	if err != nil {
+		t.Fatalf("PUT from proxied sandbox: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("PUT from proxied sandbox = %d, want 204", resp.StatusCode)
+	}
+	if store["NEW_TOKEN"] != "s3cr3t" {
+		t.Errorf("stored %q, want s3cr3t", store["NEW_TOKEN"])
+	}
+}
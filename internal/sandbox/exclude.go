@@ -0,0 +1,50 @@
+package sandbox
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/docker/docker/api/types/mount"
+	"github.com/mackeh/AegisClaw/internal/scope"
+)
+
+// ValidateMounts refuses a bind mount whose host source lies in one of
+// cfg.ExcludedPaths. Exclusions always win, so no grant can mount an
+// excluded path or anything beneath it.
+func ValidateMounts(cfg Config) error {
+	for _, m := range cfg.Mounts {
+		for _, x := range cfg.ExcludedPaths {
+			if scope.ResourceCovers(x, m.Source) {
+				return fmt.Errorf("cannot mount %s: %s is excluded by a files exclusion scope", m.Source, x)
+			}
+		}
+	}
+	return nil
+}
+
+// exclusionMasks hides the excluded parts of bind mounts that contain
+// them: each gets an empty, read-only tmpfs over the matching container
+// path, so /data can be mounted while /data/secrets stays out of reach.
+func exclusionMasks(cfg Config) []mount.Mount {
+	var masks []mount.Mount
+	for _, m := range cfg.Mounts {
+		src := path.Clean(m.Source)
+		for _, x := range cfg.ExcludedPaths {
+			x = path.Clean(x)
+			if x == src || !scope.ResourceCovers(src, x) {
+				continue
+			}
+			rel := x[len(src):]
+			if src == "/" {
+				rel = x
+			}
+			masks = append(masks, mount.Mount{
+				Type:         mount.TypeTmpfs,
+				Target:       path.Join(m.Target, rel),
+				ReadOnly:     true,
+				TmpfsOptions: &mount.TmpfsOptions{SizeBytes: 4096},
+			})
+		}
+	}
+	return masks
+}
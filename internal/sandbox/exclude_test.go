@@ -0,0 +1,44 @@
+package sandbox
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/mount"
+)
+
+func TestValidateMounts_Exclusions(t *testing.T) {
+	excluded := []string{"/data/secrets"}
+
+	// The broader path can be mounted...
+	if err := ValidateMounts(Config{Mounts: []Mount{{Source: "/data", Target: "/data"}}, ExcludedPaths: excluded}); err != nil {
+		t.Errorf("mounting /data: %v", err)
+	}
+	// ...but not the excluded one, nor anything beneath it.
+	for _, src := range []string{"/data/secrets", "/data/secrets/keys"} {
+		if err := ValidateMounts(Config{Mounts: []Mount{{Source: src, Target: "/in"}}, ExcludedPaths: excluded}); err == nil {
+			t.Errorf("mounting %s should be refused", src)
+		}
+	}
+	if err := ValidateMounts(Config{Mounts: []Mount{{Source: "/data/secrets-public", Target: "/in"}}, ExcludedPaths: excluded}); err != nil {
+		t.Errorf("a sibling path is not excluded: %v", err)
+	}
+}
+
+func TestHardenedConfigs_MasksExcludedPaths(t *testing.T) {
+	cfg := Config{
+		Image:         "alpine",
+		Mounts:        []Mount{{Source: "/data", Target: "/mnt/data", ReadOnly: true}},
+		ExcludedPaths: []string{"/data/secrets", "/elsewhere"},
+	}
+	_, host := hardenedConfigs(cfg, nil)
+
+	var masks []mount.Mount
+	for _, m := range host.Mounts {
+		if m.Type == mount.TypeTmpfs && m.Target != "/tmp" {
+			masks = append(masks, m)
+		}
+	}
+	if len(masks) != 1 || masks[0].Target != "/mnt/data/secrets" || !masks[0].ReadOnly {
+		t.Errorf("exclusion masks = %+v, want a read-only tmpfs over /mnt/data/secrets", masks)
+	}
+}
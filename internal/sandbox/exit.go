@@ -0,0 +1,47 @@
+package sandbox
+
+import (
+	"context"
+
+	"github.com/docker/docker/api/types"
+)
+
+// Exit reasons reported in Result.Reason and on run records. Completed
+// covers any exit the command made itself, zero or not; ExitCode tells
+// success from failure.
+const (
+	ExitCompleted = "completed"
+	ExitOOMKilled = "oom_killed"
+	ExitTimeout   = "timeout"
+	ExitKilled    = "killed"
+	ExitError     = "error"
+)
+
+// ExitReason classifies how a container stopped from its inspected state
+// and the exit code ContainerWait reported. A nil state (inspect failed)
+// falls back to the exit code: above 128 the process died from signal
+// code-128, e.g. 137 for SIGKILL.
+func ExitReason(state *types.ContainerState, exitCode int) string {
+	if state != nil {
+		switch {
+		case state.OOMKilled:
+			return ExitOOMKilled
+		case state.Error != "":
+			return ExitError
+		}
+	}
+	if exitCode > 128 {
+		return ExitKilled
+	}
+	return ExitCompleted
+}
+
+// exitReason inspects the stopped container to classify its exit; it must
+// run before the container is removed.
+func (e *DockerExecutor) exitReason(ctx context.Context, containerID string, exitCode int) string {
+	info, err := e.cli.ContainerInspect(ctx, containerID)
+	if err != nil || info.ContainerJSONBase == nil {
+		return ExitReason(nil, exitCode)
+	}
+	return ExitReason(info.State, exitCode)
+}
@@ -0,0 +1,30 @@
+package sandbox
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types"
+)
+
+func TestExitReason(t *testing.T) {
+	tests := []struct {
+		name     string
+		state    *types.ContainerState
+		exitCode int
+		want     string
+	}{
+		{"oom killed", &types.ContainerState{OOMKilled: true, ExitCode: 137}, 137, ExitOOMKilled},
+		{"clean exit", &types.ContainerState{ExitCode: 0}, 0, ExitCompleted},
+		{"non-zero exit", &types.ContainerState{ExitCode: 1}, 1, ExitCompleted},
+		{"sigkill", &types.ContainerState{ExitCode: 137}, 137, ExitKilled},
+		{"sigterm", &types.ContainerState{ExitCode: 143}, 143, ExitKilled},
+		{"daemon error", &types.ContainerState{Error: "oci runtime error"}, 127, ExitError},
+		{"inspect failed", nil, 137, ExitKilled},
+		{"inspect failed clean", nil, 0, ExitCompleted},
+	}
+	for _, tt := range tests {
+		if got := ExitReason(tt.state, tt.exitCode); got != tt.want {
+			t.Errorf("%s: ExitReason = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
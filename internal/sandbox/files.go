@@ -0,0 +1,162 @@
+package sandbox
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+)
+
+// Container paths of the per-run workspace. Skills see InputDir as
+// $AEGISCLAW_INPUT_DIR and OutputDir as $AEGISCLAW_OUTPUT_DIR.
+const (
+	WorkspaceDir = "/aegisclaw"
+	InputDir     = WorkspaceDir + "/input"  // read-only Config.Files
+	OutputDir    = WorkspaceDir + "/output" // writable; Config.Outputs are collected from here
+)
+
+// MaxInputBytes caps the total size of Config.Files. Inputs are meant for
+// small config or data files, not datasets.
+const MaxInputBytes = 16 * 1024 * 1024
+
+// containerCopier is the subset of the Docker client used to inject files.
+type containerCopier interface {
+	CopyToContainer(ctx context.Context, containerID, dstPath string, content io.Reader, options container.CopyToContainerOptions) error
+}
+
+// validateFiles checks that every Files key is a clean relative path that
+// stays inside InputDir, and that the total size is within MaxInputBytes.
+func validateFiles(files map[string][]byte) error {
+	total := 0
+	for name, data := range files {
+		clean := path.Clean(name)
+		if name == "" || path.IsAbs(name) || clean != name || clean == "." || clean == ".." || strings.HasPrefix(clean, "../") {
+			return fmt.Errorf("invalid input file name %q (want a relative path inside %s)", name, InputDir)
+		}
+		total += len(data)
+	}
+	if total > MaxInputBytes {
+		return fmt.Errorf("input files total %d bytes, over the %d byte limit", total, MaxInputBytes)
+	}
+	return nil
+}
+
+// workspaceArchive builds the tar copied to WorkspaceDir: files under
+// input/, readable by the sandbox user but not writable, and, if
+// withOutput, an empty output/ directory the sandbox user owns. Entries are
+// sorted for a stable archive.
+func workspaceArchive(files map[string][]byte, withOutput bool, owner User) ([]byte, error) {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	written := map[string]bool{}
+	for _, name := range names {
+		entry := "input/" + name
+		// Parent directories must precede their files in the archive.
+		for _, dir := range parentDirs(entry) {
+			if written[dir] {
+				continue
+			}
+			written[dir] = true
+			if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: dir + "/", Mode: 0555, Uid: owner.UID, Gid: owner.GID}); err != nil {
+				return nil, err
+			}
+		}
+		data := files[name]
+		if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: entry, Mode: 0444, Size: int64(len(data)), Uid: owner.UID, Gid: owner.GID}); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return nil, err
+		}
+	}
+	if withOutput {
+		if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: "output/", Mode: 0755, Uid: owner.UID, Gid: owner.GID}); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// parentDirs returns the ancestors of name, outermost first.
+func parentDirs(name string) []string {
+	var dirs []string
+	for dir := path.Dir(name); dir != "."; dir = path.Dir(dir) {
+		dirs = append([]string{dir}, dirs...)
+	}
+	return dirs
+}
+
+// workspaceMount is the container-private volume backing WorkspaceDir. It
+// is an anonymous Docker volume, not a host bind mount, and is removed with
+// the container. (A tmpfs cannot be used: it is only mounted once the
+// container starts, after the copy, and is gone by the time outputs are
+// collected.)
+func workspaceMount() mount.Mount {
+	return mount.Mount{Type: mount.TypeVolume, Target: WorkspaceDir}
+}
+
+// prepareWorkspace populates WorkspaceDir of a created, not yet started,
+// container with cfg.Files and, if cfg.Outputs is set, the output directory.
+func prepareWorkspace(ctx context.Context, cli containerCopier, containerID string, cfg Config) error {
+	if len(cfg.Files) == 0 && len(cfg.Outputs) == 0 {
+		return nil
+	}
+	owner, err := ParseUser(cfg.User)
+	if err != nil {
+		return err
+	}
+	archive, err := workspaceArchive(cfg.Files, len(cfg.Outputs) > 0, owner)
+	if err != nil {
+		return fmt.Errorf("failed to archive input files: %w", err)
+	}
+	if err := cli.CopyToContainer(ctx, containerID, WorkspaceDir, bytes.NewReader(archive), container.CopyToContainerOptions{}); err != nil {
+		return fmt.Errorf("failed to copy input files into container: %w", err)
+	}
+	return nil
+}
+
+// ReadInputFiles loads host files for Config.Files from specs of the form
+// "path" (placed under its base name) or "name=path".
+func ReadInputFiles(specs []string) (map[string][]byte, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+	files := make(map[string][]byte, len(specs))
+	for _, spec := range specs {
+		name, src, ok := strings.Cut(spec, "=")
+		if !ok {
+			src = spec
+			name = filepath.Base(spec)
+		}
+		data, err := os.ReadFile(src)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read input %s: %w", src, err)
+		}
+		if _, dup := files[name]; dup {
+			return nil, fmt.Errorf("duplicate input file name %q", name)
+		}
+		files[name] = data
+	}
+	if err := validateFiles(files); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
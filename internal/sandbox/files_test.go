@@ -0,0 +1,158 @@
+package sandbox
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+)
+
+type fakeCopier struct {
+	containerID string
+	dstPath     string
+	archive     []byte
+}
+
+func (f *fakeCopier) CopyToContainer(ctx context.Context, containerID, dstPath string, content io.Reader, options container.CopyToContainerOptions) error {
+	f.containerID = containerID
+	f.dstPath = dstPath
+	f.archive, _ = io.ReadAll(content)
+	return nil
+}
+
+func TestPrepareWorkspace_TarContents(t *testing.T) {
+	files := map[string][]byte{
+		"config.json":   []byte(`{"k":"v"}`),
+		"data/rows.csv": []byte("a,b\n1,2\n"),
+	}
+	fc := &fakeCopier{}
+	if err := prepareWorkspace(context.Background(), fc, "abc123", Config{Files: files}); err != nil {
+		t.Fatalf("prepareWorkspace: %v", err)
+	}
+	if fc.containerID != "abc123" || fc.dstPath != WorkspaceDir {
+		t.Fatalf("copied to %s:%s, want abc123:%s", fc.containerID, fc.dstPath, WorkspaceDir)
+	}
+
+	got := map[string][]byte{}
+	sawDataDir := false
+	tr := tar.NewReader(bytes.NewReader(fc.archive))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Uid != 1000 || hdr.Mode&0222 != 0 {
+			t.Errorf("%s: uid=%d mode=%o, want uid 1000 read-only", hdr.Name, hdr.Uid, hdr.Mode)
+		}
+		if hdr.Typeflag == tar.TypeDir {
+			if hdr.Name == "input/data/" {
+				if _, ok := got["input/data/rows.csv"]; ok {
+					t.Error("directory entry must precede its files")
+				}
+				sawDataDir = true
+			}
+			if hdr.Name == "output/" {
+				t.Error("no output directory expected without outputs")
+			}
+			continue
+		}
+		got[hdr.Name], _ = io.ReadAll(tr)
+	}
+	if !sawDataDir {
+		t.Error("expected an input/data/ directory entry")
+	}
+	for name, want := range files {
+		if !bytes.Equal(got["input/"+name], want) {
+			t.Errorf("%s = %q, want %q", name, got[name], want)
+		}
+	}
+}
+
+func TestPrepareWorkspace_NoneSkipsCopy(t *testing.T) {
+	fc := &fakeCopier{}
+	if err := prepareWorkspace(context.Background(), fc, "abc123", Config{}); err != nil {
+		t.Fatal(err)
+	}
+	if fc.containerID != "" {
+		t.Error("CopyToContainer should not be called without files")
+	}
+}
+
+func TestValidateFiles(t *testing.T) {
+	for _, name := range []string{"", "/etc/passwd", "../escape", "a/../../b", "./x", "."} {
+		if err := validateFiles(map[string][]byte{name: nil}); err == nil {
+			t.Errorf("validateFiles(%q) should fail", name)
+		}
+	}
+	if err := validateFiles(map[string][]byte{"ok.txt": nil, "sub/ok.txt": nil}); err != nil {
+		t.Errorf("valid names rejected: %v", err)
+	}
+	if err := validateFiles(map[string][]byte{"big": make([]byte, MaxInputBytes+1)}); err == nil {
+		t.Error("oversized input should fail")
+	}
+}
+
+func TestHardenedConfigs_InputFiles(t *testing.T) {
+	cfg, host := hardenedConfigs(Config{Image: "alpine", Files: map[string][]byte{"in.txt": []byte("hi")}}, nil)
+	var found bool
+	for _, m := range host.Mounts {
+		if m.Target == WorkspaceDir {
+			found = true
+			if m.Type != mount.TypeVolume {
+				t.Errorf("input mount type = %s, want volume (no host bind)", m.Type)
+			}
+		}
+		if m.Type == mount.TypeBind {
+			t.Errorf("unexpected bind mount %s", m.Source)
+		}
+	}
+	if !found {
+		t.Error("expected a mount at WorkspaceDir")
+	}
+	var envSet bool
+	for _, e := range cfg.Env {
+		envSet = envSet || e == "AEGISCLAW_INPUT_DIR="+InputDir
+	}
+	if !envSet {
+		t.Error("expected AEGISCLAW_INPUT_DIR in env")
+	}
+
+	_, host = hardenedConfigs(Config{Image: "alpine"}, nil)
+	for _, m := range host.Mounts {
+		if m.Target == WorkspaceDir {
+			t.Error("no workspace mount expected without files")
+		}
+	}
+}
+
+func TestReadInputFiles(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "settings.yaml")
+	if err := os.WriteFile(src, []byte("a: 1"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := ReadInputFiles([]string{src, "conf/app.yaml=" + src})
+	if err != nil {
+		t.Fatalf("ReadInputFiles: %v", err)
+	}
+	if string(files["settings.yaml"]) != "a: 1" || string(files["conf/app.yaml"]) != "a: 1" {
+		t.Errorf("unexpected files: %v", files)
+	}
+
+	if _, err := ReadInputFiles([]string{"../x=" + src}); err == nil {
+		t.Error("escaping name should be rejected")
+	}
+	if _, err := ReadInputFiles([]string{src, src}); err == nil {
+		t.Error("duplicate name should be rejected")
+	}
+}
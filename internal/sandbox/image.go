@@ -0,0 +1,43 @@
+package sandbox
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PinnedByDigest reports whether img names an immutable image, e.g.
+// "alpine@sha256:...". Such an image, once present, never needs a pull.
+func PinnedByDigest(img string) bool {
+	return strings.Contains(img, "@sha256:")
+}
+
+// ImageRegistry returns the registry host img is pulled from, following
+// Docker's reference rules: the first path component is a registry when it
+// contains "." or ":" or is "localhost"; otherwise the image is on Docker Hub
+// ("docker.io").
+func ImageRegistry(img string) string {
+	name, _, _ := strings.Cut(img, "@")
+	first, _, ok := strings.Cut(name, "/")
+	if !ok || (!strings.ContainsAny(first, ".:") && first != "localhost") {
+		return "docker.io"
+	}
+	if first == "index.docker.io" || first == "registry-1.docker.io" {
+		return "docker.io"
+	}
+	return strings.ToLower(first)
+}
+
+// CheckImageRegistry refuses img unless its registry is in allowed. An empty
+// allowed permits any registry.
+func CheckImageRegistry(img string, allowed []string) error {
+	if len(allowed) == 0 {
+		return nil
+	}
+	reg := ImageRegistry(img)
+	for _, a := range allowed {
+		if strings.EqualFold(strings.TrimSpace(a), reg) {
+			return nil
+		}
+	}
+	return fmt.Errorf("image %q is from registry %q, which is not in security.allowed_registries %v", img, reg, allowed)
+}
@@ -0,0 +1,47 @@
+package sandbox
+
+import "testing"
+
+func TestImageRegistry(t *testing.T) {
+	tests := map[string]string{
+		"alpine":                           "docker.io",
+		"alpine:3.20":                      "docker.io",
+		"library/alpine@sha256:abc":        "docker.io",
+		"mackeh/skill:1.0":                 "docker.io",
+		"index.docker.io/library/alpine":   "docker.io",
+		"ghcr.io/mackeh/skill:1.0":         "ghcr.io",
+		"registry.corp:5000/team/img":      "registry.corp:5000",
+		"localhost/img":                    "localhost",
+		"GHCR.IO/mackeh/skill@sha256:abcd": "ghcr.io",
+	}
+	for img, want := range tests {
+		if got := ImageRegistry(img); got != want {
+			t.Errorf("ImageRegistry(%q) = %q, want %q", img, got, want)
+		}
+	}
+}
+
+func TestCheckImageRegistry(t *testing.T) {
+	if err := CheckImageRegistry("alpine:latest", nil); err != nil {
+		t.Errorf("empty allowlist should permit any image: %v", err)
+	}
+	allowed := []string{"ghcr.io", "docker.io"}
+	if err := CheckImageRegistry("alpine:latest", allowed); err != nil {
+		t.Errorf("docker.io image refused: %v", err)
+	}
+	if err := CheckImageRegistry("ghcr.io/mackeh/skill:1.0", allowed); err != nil {
+		t.Errorf("ghcr.io image refused: %v", err)
+	}
+	if err := CheckImageRegistry("quay.io/evil/img", allowed); err == nil {
+		t.Error("expected quay.io image to be refused")
+	}
+}
+
+func TestPinnedByDigest(t *testing.T) {
+	if !PinnedByDigest("alpine@sha256:abc") {
+		t.Error("digest reference not recognised as pinned")
+	}
+	if PinnedByDigest("alpine:latest") {
+		t.Error("tag reported as pinned")
+	}
+}
@@ -0,0 +1,36 @@
+package sandbox
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHardenedConfigs_DNSAndHostname(t *testing.T) {
+	proxyEnv := []string{"HTTPS_PROXY=http://host.docker.internal:1234"}
+	cfg, host := hardenedConfigs(Config{Image: "alpine", Network: true, DNS: []string{"9.9.9.9"}}, proxyEnv)
+	if cfg.Hostname != SandboxHostname {
+		t.Errorf("Hostname = %q, want %q", cfg.Hostname, SandboxHostname)
+	}
+	if !reflect.DeepEqual(host.DNS, []string{sinkholeDNS}) {
+		t.Errorf("proxied DNS = %v, want the sinkhole", host.DNS)
+	}
+	if !reflect.DeepEqual(host.DNSSearch, []string{"."}) {
+		t.Errorf("DNSSearch = %v, want [.] (no host search domains)", host.DNSSearch)
+	}
+	if host.Sysctls["net.ipv6.conf.all.disable_ipv6"] != "1" {
+		t.Errorf("IPv6 should be disabled by default, sysctls = %v", host.Sysctls)
+	}
+
+	_, host = hardenedConfigs(Config{Image: "alpine", Network: true, DNS: []string{"9.9.9.9"}, IPv6: true}, nil)
+	if !reflect.DeepEqual(host.DNS, []string{"9.9.9.9"}) {
+		t.Errorf("unproxied DNS = %v, want the configured resolver", host.DNS)
+	}
+	if _, set := host.Sysctls["net.ipv6.conf.all.disable_ipv6"]; set {
+		t.Error("IPv6 requested but disabled")
+	}
+
+	cfg, host = hardenedConfigs(Config{Image: "alpine"}, nil)
+	if cfg.Hostname != SandboxHostname || host.NetworkMode != "none" || len(host.DNS) != 0 {
+		t.Errorf("offline container: hostname=%q mode=%s dns=%v", cfg.Hostname, host.NetworkMode, host.DNS)
+	}
+}
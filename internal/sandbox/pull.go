@@ -0,0 +1,211 @@
+package sandbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+// imageClient is the subset of the Docker client used to make an image
+// available locally.
+type imageClient interface {
+	ImageInspectWithRaw(ctx context.Context, imageID string) (types.ImageInspect, []byte, error)
+	ImagePull(ctx context.Context, refStr string, options image.PullOptions) (io.ReadCloser, error)
+}
+
+// PullEvent is one layer update from an image pull, e.g. "Downloading"
+// with Current of Total bytes. Layer is empty for image-level messages such
+// as the final digest line.
+type PullEvent struct {
+	Layer   string
+	Status  string
+	Current int64
+	Total   int64
+}
+
+// progressInterval throttles pull progress lines so a large pull reports
+// steadily without flooding a log or SSE stream.
+const progressInterval = time.Second
+
+// pullProgress returns where cfg's pull progress goes.
+func (cfg Config) pullProgress() io.Writer {
+	if cfg.PullProgress != nil {
+		return cfg.PullProgress
+	}
+	return os.Stdout
+}
+
+// Image pull policies for Config.PullPolicy.
+const (
+	PullIfMissing = "missing" // default: pull an image that is not present
+	PullNever     = "never"   // never contact a registry; for air-gapped hosts
+)
+
+// ErrImageNotPresent is returned when an image is missing locally and the
+// pull policy forbids fetching it.
+var ErrImageNotPresent = errors.New("image not present locally")
+
+// ValidatePullPolicy rejects unknown pull policies; empty means PullIfMissing.
+func ValidatePullPolicy(policy string) error {
+	switch policy {
+	case "", PullIfMissing, PullNever:
+		return nil
+	}
+	return fmt.Errorf("invalid image pull policy %q (want %s or %s)", policy, PullIfMissing, PullNever)
+}
+
+// ensureImage makes img available locally, reporting cache status and pull
+// progress to progress. A present image is used as is: a digest-pinned one
+// cannot be stale, and a tagged one is refreshed only by an explicit pull.
+// With policy PullNever a missing image fails at once, before any registry
+// is contacted.
+func ensureImage(ctx context.Context, cli imageClient, img, policy string, progress io.Writer) error {
+	if err := ValidatePullPolicy(policy); err != nil {
+		return err
+	}
+	if _, _, err := cli.ImageInspectWithRaw(ctx, img); err == nil {
+		if PinnedByDigest(img) {
+			fmt.Fprintf(progress, "📦 Image %s is cached (pinned by digest); skipping pull\n", img)
+		} else {
+			fmt.Fprintf(progress, "📦 Using cached image %s\n", img)
+		}
+		return nil
+	} else if !client.IsErrNotFound(err) {
+		return fmt.Errorf("failed to inspect image: %w", err)
+	}
+
+	if policy == PullNever {
+		return fmt.Errorf("%w: %s (the image pull policy is %q; load it with 'docker load' or pull it while online)", ErrImageNotPresent, img, PullNever)
+	}
+
+	fmt.Fprintf(progress, "📥 Pulling image %s...\n", img)
+	reader, err := cli.ImagePull(ctx, img, image.PullOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to pull image: %w", err)
+	}
+	defer reader.Close()
+
+	rep := newPullReporter(progress)
+	if err := readPullProgress(reader, rep.update); err != nil {
+		return fmt.Errorf("failed to pull image %s: %w", img, err)
+	}
+	rep.done(img)
+	return nil
+}
+
+// readPullProgress decodes the JSON message stream of an image pull,
+// calling fn for each message. An error message in the stream (e.g. an
+// unknown tag or failed download) is returned as an error.
+func readPullProgress(r io.Reader, fn func(PullEvent)) error {
+	dec := json.NewDecoder(r)
+	for {
+		var msg jsonmessage.JSONMessage
+		if err := dec.Decode(&msg); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("failed to read pull progress: %w", err)
+		}
+		if msg.Error != nil {
+			return errors.New(msg.Error.Message)
+		}
+		if msg.ErrorMessage != "" {
+			return errors.New(msg.ErrorMessage)
+		}
+		ev := PullEvent{Layer: msg.ID, Status: msg.Status}
+		if strings.HasPrefix(msg.Status, "Pulling from ") {
+			ev.Layer = "" // the ID is the tag being pulled, not a layer
+		}
+		if msg.Progress != nil {
+			ev.Current, ev.Total = msg.Progress.Current, msg.Progress.Total
+		}
+		fn(ev)
+	}
+}
+
+// pullReporter aggregates layer events into periodic summary lines such as
+// "📥 2/5 layers, 12.3MB / 40.1MB".
+type pullReporter struct {
+	w        io.Writer
+	now      func() time.Time
+	last     time.Time
+	current  map[string]int64
+	total    map[string]int64
+	finished map[string]bool
+}
+
+func newPullReporter(w io.Writer) *pullReporter {
+	return &pullReporter{
+		w:        w,
+		now:      time.Now,
+		current:  map[string]int64{},
+		total:    map[string]int64{},
+		finished: map[string]bool{},
+	}
+}
+
+func (p *pullReporter) update(ev PullEvent) {
+	if ev.Layer == "" {
+		return
+	}
+	if _, ok := p.current[ev.Layer]; !ok {
+		p.current[ev.Layer] = 0
+	}
+	switch ev.Status {
+	case "Downloading":
+		p.current[ev.Layer] = ev.Current
+		if ev.Total > 0 {
+			p.total[ev.Layer] = ev.Total
+		}
+	case "Download complete":
+		p.current[ev.Layer] = p.total[ev.Layer]
+	case "Pull complete", "Already exists":
+		p.finished[ev.Layer] = true
+	}
+	if t := p.now(); t.Sub(p.last) >= progressInterval {
+		p.last = t
+		p.print()
+	}
+}
+
+func (p *pullReporter) print() {
+	var cur, tot int64
+	for id, c := range p.current {
+		cur += c
+		tot += p.total[id]
+	}
+	layers := len(p.current)
+	if layers == 0 {
+		return
+	}
+	fmt.Fprintf(p.w, "📥 %d/%d layers, %s / %s\n", len(p.finished), layers, formatBytes(cur), formatBytes(tot))
+}
+
+func (p *pullReporter) done(img string) {
+	p.print()
+	fmt.Fprintf(p.w, "✅ Pulled %s\n", img)
+}
+
+// formatBytes renders n in decimal units, as docker pull does.
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1e9:
+		return fmt.Sprintf("%.1fGB", float64(n)/1e9)
+	case n >= 1e6:
+		return fmt.Sprintf("%.1fMB", float64(n)/1e6)
+	case n >= 1e3:
+		return fmt.Sprintf("%.1fkB", float64(n)/1e3)
+	default:
+		return fmt.Sprintf("%dB", n)
+	}
+}
@@ -0,0 +1,179 @@
+package sandbox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
+)
+
+// samplePull is an abridged `docker pull` progress stream.
+const samplePull = `{"status":"Pulling from library/python","id":"3.12-slim"}
+{"status":"Pulling fs layer","progressDetail":{},"id":"a1"}
+{"status":"Already exists","progressDetail":{},"id":"b2"}
+{"status":"Downloading","progressDetail":{"current":1000,"total":4000},"id":"a1"}
+{"status":"Downloading","progressDetail":{"current":4000,"total":4000},"id":"a1"}
+{"status":"Download complete","progressDetail":{},"id":"a1"}
+{"status":"Extracting","progressDetail":{"current":4000,"total":4000},"id":"a1"}
+{"status":"Pull complete","progressDetail":{},"id":"a1"}
+{"status":"Digest: sha256:0123"}
+{"status":"Status: Downloaded newer image for python:3.12-slim"}
+`
+
+type fakeImages struct {
+	present bool
+	stream  string
+	pulls   int
+}
+
+func (f *fakeImages) ImageInspectWithRaw(ctx context.Context, imageID string) (types.ImageInspect, []byte, error) {
+	if !f.present {
+		return types.ImageInspect{}, nil, errdefs.NotFound(io.EOF)
+	}
+	return types.ImageInspect{ID: "sha256:abc"}, nil, nil
+}
+
+func (f *fakeImages) ImagePull(ctx context.Context, refStr string, options image.PullOptions) (io.ReadCloser, error) {
+	f.pulls++
+	return io.NopCloser(strings.NewReader(f.stream)), nil
+}
+
+func TestReadPullProgress(t *testing.T) {
+	var events []PullEvent
+	if err := readPullProgress(strings.NewReader(samplePull), func(ev PullEvent) { events = append(events, ev) }); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 10 {
+		t.Fatalf("got %d events, want 10", len(events))
+	}
+	if events[0].Layer != "" {
+		t.Errorf("tag line parsed as layer %q", events[0].Layer)
+	}
+	if got := events[3]; got.Layer != "a1" || got.Status != "Downloading" || got.Current != 1000 || got.Total != 4000 {
+		t.Errorf("download event = %+v", got)
+	}
+
+	stream := samplePull[:strings.Index(samplePull, "\n")+1] + `{"errorDetail":{"message":"manifest unknown"},"error":"manifest unknown"}` + "\n"
+	if err := readPullProgress(strings.NewReader(stream), func(PullEvent) {}); err == nil || !strings.Contains(err.Error(), "manifest unknown") {
+		t.Errorf("stream error not surfaced: %v", err)
+	}
+}
+
+func TestPullReporter(t *testing.T) {
+	var out bytes.Buffer
+	rep := newPullReporter(&out)
+	clock := time.Unix(0, 0)
+	rep.now = func() time.Time { clock = clock.Add(2 * progressInterval); return clock }
+	if err := readPullProgress(strings.NewReader(samplePull), rep.update); err != nil {
+		t.Fatal(err)
+	}
+	rep.done("python:3.12-slim")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if !strings.Contains(lines[2], "1/2 layers, 1.0kB / 4.0kB") {
+		t.Errorf("mid-download line = %q", lines[2])
+	}
+	if last := lines[len(lines)-2]; !strings.Contains(last, "2/2 layers, 4.0kB / 4.0kB") {
+		t.Errorf("final progress line = %q", last)
+	}
+}
+
+func TestEnsureImage_PinnedPresentSkipsPull(t *testing.T) {
+	cli := &fakeImages{present: true}
+	var out bytes.Buffer
+	if err := ensureImage(context.Background(), cli, "alpine@sha256:0123", "", &out); err != nil {
+		t.Fatal(err)
+	}
+	if cli.pulls != 0 {
+		t.Errorf("present digest-pinned image was pulled %d time(s)", cli.pulls)
+	}
+	if !strings.Contains(out.String(), "pinned by digest") {
+		t.Errorf("cache status not reported: %q", out.String())
+	}
+}
+
+func TestEnsureImage_MissingPulls(t *testing.T) {
+	cli := &fakeImages{stream: samplePull}
+	var out bytes.Buffer
+	if err := ensureImage(context.Background(), cli, "python:3.12-slim", "", &out); err != nil {
+		t.Fatal(err)
+	}
+	if cli.pulls != 1 {
+		t.Errorf("pulls = %d, want 1", cli.pulls)
+	}
+	if !strings.Contains(out.String(), "✅ Pulled python:3.12-slim") {
+		t.Errorf("missing completion line: %q", out.String())
+	}
+}
+
+func TestEnsureImage_NeverPolicyMissingFails(t *testing.T) {
+	cli := &fakeImages{stream: samplePull}
+	var out bytes.Buffer
+	err := ensureImage(context.Background(), cli, "python:3.12-slim", PullNever, &out)
+	if !errors.Is(err, ErrImageNotPresent) {
+		t.Fatalf("expected ErrImageNotPresent, got %v", err)
+	}
+	if cli.pulls != 0 {
+		t.Errorf("pull attempted %d time(s) with policy never", cli.pulls)
+	}
+
+	cli.present = true
+	if err := ensureImage(context.Background(), cli, "python:3.12-slim", PullNever, &out); err != nil {
+		t.Errorf("present image refused under policy never: %v", err)
+	}
+	if err := ensureImage(context.Background(), cli, "python:3.12-slim", "always", &out); err == nil {
+		t.Error("expected an error for an unknown pull policy")
+	}
+}
+
+// TestRun_NeverPullsMissingImage drives Run against a fake Docker API that
+// knows no images and fails the test on any pull.
+func TestRun_NeverPullsMissingImage(t *testing.T) {
+	var pulls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/json") && strings.Contains(r.URL.Path, "/images/"):
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"message":"No such image: alpine:3.20"}`)
+		case strings.HasSuffix(r.URL.Path, "/images/create"):
+			pulls++
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			t.Errorf("unexpected Docker API call %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	cli, err := client.NewClientWithOpts(client.WithHost("tcp://"+srv.Listener.Addr().String()), client.WithVersion("1.45"), client.WithHTTPClient(srv.Client()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec := &DockerExecutor{cli: cli}
+
+	start := time.Now()
+	_, err = exec.Run(context.Background(), Config{Image: "alpine:3.20", Command: []string{"true"}, PullPolicy: PullNever, PullProgress: io.Discard})
+	if !errors.Is(err, ErrImageNotPresent) {
+		t.Fatalf("expected ErrImageNotPresent, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "alpine:3.20") {
+		t.Errorf("error should name the image: %v", err)
+	}
+	if pulls != 0 {
+		t.Errorf("pull attempted %d time(s)", pulls)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("Run took %s; it should fail fast", d)
+	}
+}
@@ -0,0 +1,80 @@
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+)
+
+// RetainForLabel marks a container that is kept if its run fails. Its
+// value is the retention window as a Go duration, counted from when the
+// container stopped.
+const RetainForLabel = "aegisclaw.retain_for"
+
+// DefaultRetainFor is how long a failed run's container is kept when
+// Config.RetainFor is unset.
+const DefaultRetainFor = 24 * time.Hour
+
+func retainFor(cfg Config) time.Duration {
+	if cfg.RetainFor > 0 {
+		return cfg.RetainFor
+	}
+	return DefaultRetainFor
+}
+
+// shouldRetain reports whether the container behind res is kept rather
+// than removed: only for a failed run, and only when cfg asks for it.
+func shouldRetain(cfg Config, res *Result) bool {
+	if !cfg.RetainOnFailure || res == nil {
+		return false
+	}
+	return res.ExitCode != 0 || res.Reason != ExitCompleted
+}
+
+// retentionExpired reports whether a retained container that stopped at
+// finishedAt is due for removal at now. A container whose RetainForLabel
+// cannot be parsed falls back to DefaultRetainFor.
+func retentionExpired(labels map[string]string, finishedAt, now time.Time) bool {
+	window, err := time.ParseDuration(labels[RetainForLabel])
+	if err != nil || window <= 0 {
+		window = DefaultRetainFor
+	}
+	return !finishedAt.IsZero() && now.Sub(finishedAt) >= window
+}
+
+// ReapRetained removes stopped AegisClaw containers kept by
+// RetainOnFailure whose window has passed at now, returning how many it
+// removed. Running containers and those still within their window are
+// left alone.
+func (e *DockerExecutor) ReapRetained(ctx context.Context, now time.Time) (int, error) {
+	f := filters.NewArgs()
+	f.Add("label", "managed_by=aegisclaw")
+	f.Add("label", RetainForLabel)
+	f.Add("status", "exited")
+	f.Add("status", "dead")
+
+	containers, err := e.cli.ContainerList(ctx, container.ListOptions{All: true, Filters: f})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list retained containers: %w", err)
+	}
+
+	reaped := 0
+	for _, c := range containers {
+		info, err := e.cli.ContainerInspect(ctx, c.ID)
+		if err != nil || info.ContainerJSONBase == nil || info.State == nil {
+			continue
+		}
+		finished, err := time.Parse(time.RFC3339Nano, info.State.FinishedAt)
+		if err != nil || !retentionExpired(c.Labels, finished, now) {
+			continue
+		}
+		if err := e.cli.ContainerRemove(ctx, c.ID, container.RemoveOptions{RemoveVolumes: true}); err != nil {
+			return reaped, fmt.Errorf("failed to remove retained container %.12s: %w", c.ID, err)
+		}
+		reaped++
+	}
+	return reaped, nil
+}
@@ -0,0 +1,66 @@
+package sandbox
+
+import (
+	"testing"
+	"time"
+)
+
+func TestShouldRetain(t *testing.T) {
+	failed := &Result{ExitCode: 1, Reason: ExitCompleted}
+	oom := &Result{ExitCode: 137, Reason: ExitOOMKilled}
+	succeeded := &Result{ExitCode: 0, Reason: ExitCompleted}
+
+	tests := []struct {
+		name   string
+		retain bool
+		res    *Result
+		want   bool
+	}{
+		{"failed run, retention on", true, failed, true},
+		{"oom-killed run, retention on", true, oom, true},
+		{"successful run, retention on", true, succeeded, false},
+		{"failed run, retention off", false, failed, false},
+		{"successful run, retention off", false, succeeded, false},
+	}
+	for _, tt := range tests {
+		if got := shouldRetain(Config{RetainOnFailure: tt.retain}, tt.res); got != tt.want {
+			t.Errorf("%s: retained = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHardenedConfigs_RetainLabel(t *testing.T) {
+	config, _ := hardenedConfigs(Config{Image: "alpine"}, nil)
+	if _, ok := config.Labels[RetainForLabel]; ok {
+		t.Error("a container without RetainOnFailure must not be labeled for retention")
+	}
+
+	config, _ = hardenedConfigs(Config{Image: "alpine", RetainOnFailure: true, RetainFor: 2 * time.Hour}, nil)
+	if got := config.Labels[RetainForLabel]; got != "2h0m0s" {
+		t.Errorf("%s = %q, want 2h0m0s", RetainForLabel, got)
+	}
+	config, _ = hardenedConfigs(Config{Image: "alpine", RetainOnFailure: true}, nil)
+	if got := config.Labels[RetainForLabel]; got != DefaultRetainFor.String() {
+		t.Errorf("%s = %q, want the default window", RetainForLabel, got)
+	}
+}
+
+func TestRetentionExpired(t *testing.T) {
+	finished := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	labels := map[string]string{RetainForLabel: "1h0m0s"}
+
+	if retentionExpired(labels, finished, finished.Add(30*time.Minute)) {
+		t.Error("a container inside its window must be kept")
+	}
+	if !retentionExpired(labels, finished, finished.Add(time.Hour)) {
+		t.Error("a container past its window must be reaped")
+	}
+	// An unparseable window falls back to the default.
+	bad := map[string]string{RetainForLabel: "soon"}
+	if retentionExpired(bad, finished, finished.Add(time.Hour)) || !retentionExpired(bad, finished, finished.Add(DefaultRetainFor)) {
+		t.Error("an invalid window should fall back to DefaultRetainFor")
+	}
+	if retentionExpired(labels, time.Time{}, finished) {
+		t.Error("a container with no finish time must not be reaped")
+	}
+}
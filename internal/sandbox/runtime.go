@@ -0,0 +1,62 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrRuntimeUnavailable is returned when the configured OCI runtime is not
+// registered with the Docker daemon. Falling back to runc would silently give
+// weaker isolation than the operator asked for, so the run is refused.
+var ErrRuntimeUnavailable = errors.New("sandbox runtime not available")
+
+// RuntimeInstalled reports whether name is one of the daemon's registered
+// runtimes (the keys of `docker info` .Runtimes). The default runtime ("")
+// is always available.
+func RuntimeInstalled(runtimes []string, name string) bool {
+	if name == "" {
+		return true
+	}
+	for _, r := range runtimes {
+		if r == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Runtimes lists the OCI runtimes registered with the daemon.
+func (e *DockerExecutor) Runtimes(ctx context.Context) ([]string, error) {
+	info, err := e.cli.Info(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query docker info: %w", err)
+	}
+	names := make([]string, 0, len(info.Runtimes))
+	for name := range info.Runtimes {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// prepareRuntime normalises cfg.Runtime to its Docker --runtime value (so
+// "gvisor" and "runsc" both work) and checks the daemon actually provides it.
+func (e *DockerExecutor) prepareRuntime(ctx context.Context, cfg *Config) error {
+	rt, err := ResolveRuntime(cfg.Runtime)
+	if err != nil {
+		return err
+	}
+	cfg.Runtime = rt
+	if rt == "" {
+		return nil
+	}
+	runtimes, err := e.Runtimes(ctx)
+	if err != nil {
+		return err
+	}
+	if !RuntimeInstalled(runtimes, rt) {
+		return fmt.Errorf("%w: %q is not registered with the Docker daemon (available: %v); install it or change security.sandbox_runtime (see: aegisclaw doctor)",
+			ErrRuntimeUnavailable, rt, runtimes)
+	}
+	return nil
+}
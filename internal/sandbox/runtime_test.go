@@ -0,0 +1,32 @@
+package sandbox
+
+import "testing"
+
+func TestHardenedConfigsThreadsRuntime(t *testing.T) {
+	_, hostCfg := hardenedConfigs(Config{Image: "alpine", Runtime: "runsc"}, nil)
+	if hostCfg.Runtime != "runsc" {
+		t.Errorf("HostConfig.Runtime = %q, want runsc", hostCfg.Runtime)
+	}
+
+	_, hostCfg = hardenedConfigs(Config{Image: "alpine"}, nil)
+	if hostCfg.Runtime != "" {
+		t.Errorf("HostConfig.Runtime = %q, want daemon default", hostCfg.Runtime)
+	}
+}
+
+func TestRuntimeInstalled(t *testing.T) {
+	registered := []string{"runc", "runsc"}
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"", true},
+		{"runsc", true},
+		{"kata-fc", false},
+	}
+	for _, tt := range tests {
+		if got := RuntimeInstalled(registered, tt.name); got != tt.want {
+			t.Errorf("RuntimeInstalled(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/mackeh/AegisClaw/internal/audit"
 )
@@ -16,11 +17,31 @@ const (
 	RuntimeFirecracker = "firecracker" // Docker with Firecracker (via kata-fc)
 )
 
+// RunIDLabel is the container label carrying the agent run ID.
+const RunIDLabel = "aegisclaw.run_id"
+
+// SandboxHostname is the hostname of every sandbox container, so skills
+// cannot fingerprint a run or the host through it.
+const SandboxHostname = "sandbox"
+
+// sinkholeDNS is the resolver given to proxied containers. Nothing listens
+// there, so lookups fail fast instead of leaving the container.
+const sinkholeDNS = "127.0.0.1"
+
 // Result represents the outcome of a sandbox execution
 type Result struct {
-	ExitCode int
-	Stdout   io.Reader
-	Stderr   io.Reader
+	ExitCode    int
+	Reason      string // how the container stopped; one of the Exit* constants
+	Stdout      io.Reader
+	Stderr      io.Reader
+	ImageDigest string // repo digest (or image ID) of the image actually run
+	// Artifacts lists host paths of collected Config.Outputs. ArtifactErr
+	// reports outputs that were missing, invalid or over the size limit.
+	Artifacts   []string
+	ArtifactErr error
+	// Retained reports that the container was kept after a failed run for
+	// post-mortem inspection; see Config.RetainOnFailure.
+	Retained bool
 }
 
 // Config represents the configuration for a sandbox
@@ -33,10 +54,97 @@ type Config struct {
 	Network        bool     // Allow network access?
 	AllowedDomains []string // Specific domains to allow if Network is true
 	AuditLogger    *audit.Logger
-	SeccompPath    string // Path to seccomp profile
-	Runtime        string // e.g. "runsc" (gVisor), "kata-runtime" (kata), "runc" (default)
+	SeccompPath    string   // Path to seccomp profile
+	Runtime        string   // e.g. "runsc" (gVisor), "kata-runtime" (kata), "runc" (default)
+	CapAdd         []string // Capabilities re-added on top of CapDrop ALL (validated via CapabilityScopes)
+	// DeniedDomains are domains, IPs or CIDRs carved out by exclusion
+	// scopes; the egress proxy refuses them even when allowed.
+	DeniedDomains []string
+	// BlockedDomains and BlockedCIDRs extend the egress proxy's blocklist.
+	// Every networked run is proxied, so they apply to default-allow runs.
+	BlockedDomains []string
+	BlockedCIDRs   []string
+	// ExcludedPaths are host paths carved out by files exclusion scopes;
+	// see ValidateMounts.
+	ExcludedPaths []string
+	// Files are written under InputDir inside the container before it
+	// starts, keyed by relative path, so small inputs reach a skill without
+	// bind-mounting a host directory.
+	Files map[string][]byte
+	// SecretFiles are mounted read-only under SecretsDir, one file per key,
+	// from a host tmpfs, keeping them out of the environment.
+	SecretFiles map[string][]byte
+	// Stdin, if set, is piped into the command's standard input, which sees
+	// EOF once it is drained. Only Run supports it.
+	Stdin io.Reader
+	// Outputs are container paths under OutputDir copied to ArtifactsDir
+	// on the host after the command exits; see Result.Artifacts.
+	Outputs      []string
+	ArtifactsDir string
+	// MaxArtifactBytes caps the total size of collected outputs; zero uses
+	// DefaultMaxArtifactBytes.
+	MaxArtifactBytes int64
+	// Labels are added to the container alongside managed_by=aegisclaw,
+	// e.g. RunIDLabel so a run's container can be found from another process.
+	Labels map[string]string
+	// OnStart, if set, is called with the container ID once it is running.
+	OnStart func(containerID string)
+	// PullProgress receives image cache status and pull progress lines;
+	// nil means os.Stdout.
+	PullProgress io.Writer
+	// PullPolicy is PullIfMissing (default) or PullNever, which fails fast
+	// with ErrImageNotPresent instead of reaching a registry.
+	PullPolicy string
+	// UpstreamProxy and NoProxy chain the egress proxy through a parent
+	// proxy; see proxy.EgressProxy.SetUpstream.
+	UpstreamProxy string
+	NoProxy       []string
+	// DNS lists resolvers for containers with direct network access; empty
+	// keeps Docker's default. Containers whose egress goes through the
+	// proxy get an unreachable resolver instead, so DNS cannot bypass it.
+	DNS []string
+	// IPv6 keeps IPv6 enabled in networked containers. Off by default: the
+	// egress path is IPv4 and an unused stack only widens the surface.
+	IPv6 bool
+	// DLP enables pattern-based outbound DLP on the egress proxy; see
+	// proxy.EgressProxy.DLP.
+	DLP bool
+	// RequireUsernsRemap refuses to run unless the daemon remaps container
+	// UIDs into a user namespace.
+	RequireUsernsRemap bool
+	// AllowedRegistries, when set, refuses images from any other registry;
+	// see CheckImageRegistry.
+	AllowedRegistries []string
+	// Resource limits; zero values use DefaultMemoryBytes, DefaultNanoCPUs,
+	// and DefaultPidsLimit.
+	MemoryBytes int64
+	NanoCPUs    int64
+	PidsLimit   int64
+	// TmpBytes caps the /tmp tmpfs; zero uses DefaultTmpfsBytes.
+	TmpBytes int64
+	// Tmpfs lists extra size-capped in-memory mounts; see ValidateTmpfs.
+	Tmpfs []Tmpfs
+	// User is the numeric "UID:GID" the command runs as; empty uses
+	// DefaultUser. Root (UID or GID 0) is refused unless AllowRoot, which
+	// callers set only once policy or the user has approved it.
+	User      string
+	AllowRoot bool
+	// RetainOnFailure keeps the container of a run that failed (non-zero
+	// exit or abnormal stop) instead of removing it, labeled with
+	// RetainForLabel so `docker logs` and `docker inspect` still work.
+	// Cleanup removes it once RetainFor (zero: DefaultRetainFor) has passed
+	// since it stopped. Successful runs are always removed.
+	RetainOnFailure bool
+	RetainFor       time.Duration
 }
 
+// Default resource limits applied when a Config leaves them unset.
+const (
+	DefaultMemoryBytes = 512 * 1024 * 1024 // 512MB
+	DefaultNanoCPUs    = 1000000000        // 1 CPU
+	DefaultPidsLimit   = 100
+)
+
 // Mount represents a filesystem mount
 type Mount struct {
 	Source   string
@@ -72,7 +180,7 @@ func NewExecutor(runtime string) (Executor, error) {
 // ResolveRuntime maps a user-facing runtime name to the Docker --runtime flag value.
 func ResolveRuntime(name string) (string, error) {
 	switch name {
-	case "", RuntimeDocker:
+	case "", RuntimeDocker, "runc":
 		return "", nil // Docker default (runc)
 	case RuntimeGVisor, "runsc":
 		return "runsc", nil
@@ -18,6 +18,7 @@ func TestResolveRuntime(t *testing.T) {
 		{"kata-runtime", "kata-runtime", false},
 		{"firecracker", "kata-fc", false},
 		{"kata-fc", "kata-fc", false},
+		{"runc", "", false},
 		{"unknown", "", true},
 	}
 
@@ -0,0 +1,88 @@
+package sandbox
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// SecretsDir is where Config.SecretFiles appear inside the container, one
+// read-only file per key. Skills see it as $AEGISCLAW_SECRETS_DIR.
+const SecretsDir = "/run/secrets"
+
+// secretsTmpfsRoot is the host tmpfs file-mode secrets are staged in, so
+// they never touch disk. A variable for tests.
+var secretsTmpfsRoot = "/dev/shm"
+
+var secretFileName = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]*$`)
+
+// stageSecretFiles writes secrets to a fresh directory in the host tmpfs for
+// bind-mounting at SecretsDir, and returns it with a cleanup func. Unlike
+// env injection, the values do not show in docker inspect or
+// /proc/<pid>/environ.
+//
+// The files must be readable by the container user, whose host UID is not
+// known (userns-remap), so they are world-readable; they sit in a
+// randomly named directory inside a mode 0711 one, which no other host user
+// can list, so they cannot be found.
+func stageSecretFiles(secrets map[string][]byte) (dir string, cleanup func(), err error) {
+	for key := range secrets {
+		if !secretFileName.MatchString(key) {
+			return "", nil, fmt.Errorf("invalid secret file name %q: must match %s", key, secretFileName)
+		}
+	}
+	if info, err := os.Stat(secretsTmpfsRoot); err != nil || !info.IsDir() {
+		return "", nil, fmt.Errorf("file-mode secrets need a host tmpfs at %s, so they never reach disk", secretsTmpfsRoot)
+	}
+
+	outer, err := os.MkdirTemp(secretsTmpfsRoot, "aegisclaw-secrets-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to stage secrets: %w", err)
+	}
+	cleanup = func() { _ = os.RemoveAll(outer) }
+	fail := func(err error) (string, func(), error) {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to stage secrets: %w", err)
+	}
+	if err := os.Chmod(outer, 0711); err != nil {
+		return fail(err)
+	}
+	name := make([]byte, 16)
+	if _, err := rand.Read(name); err != nil {
+		return fail(err)
+	}
+	dir = filepath.Join(outer, hex.EncodeToString(name))
+	if err := os.Mkdir(dir, 0711); err != nil {
+		return fail(err)
+	}
+	for key, value := range secrets {
+		path := filepath.Join(dir, key)
+		if err := os.WriteFile(path, value, 0444); err != nil {
+			return fail(err)
+		}
+		// WriteFile's mode is subject to the umask.
+		if err := os.Chmod(path, 0444); err != nil {
+			return fail(err)
+		}
+	}
+	return dir, cleanup, nil
+}
+
+// mountSecretFiles stages cfg.SecretFiles and adds the read-only mount and
+// $AEGISCLAW_SECRETS_DIR to cfg. The cleanup func removes the staged files
+// and must run once the container is gone.
+func mountSecretFiles(cfg *Config) (cleanup func(), err error) {
+	if len(cfg.SecretFiles) == 0 {
+		return func() {}, nil
+	}
+	dir, cleanup, err := stageSecretFiles(cfg.SecretFiles)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Mounts = append(append([]Mount{}, cfg.Mounts...), Mount{Source: dir, Target: SecretsDir, ReadOnly: true})
+	cfg.Env = append(append([]string{}, cfg.Env...), "AEGISCLAW_SECRETS_DIR="+SecretsDir)
+	return cleanup, nil
+}
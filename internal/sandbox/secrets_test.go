@@ -0,0 +1,132 @@
+package sandbox
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/client"
+)
+
+func TestRun_FileModeSecrets(t *testing.T) {
+	secretsTmpfsRoot = t.TempDir()
+	t.Cleanup(func() { secretsTmpfsRoot = "/dev/shm" })
+
+	const value = "tok-4f1c9a7e"
+	var created struct {
+		container.Config
+		HostConfig container.HostConfig
+	}
+	var staged []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := r.URL.Path
+		switch {
+		case strings.Contains(p, "/images/") && strings.HasSuffix(p, "/json"):
+			io.WriteString(w, `{"Id":"sha256:feed"}`)
+		case strings.HasSuffix(p, "/containers/create"):
+			json.NewDecoder(r.Body).Decode(&created)
+			// Read the staged file while the container "runs".
+			for _, m := range created.HostConfig.Mounts {
+				if m.Target == SecretsDir {
+					staged, _ = os.ReadFile(filepath.Join(m.Source, "API_TOKEN"))
+				}
+			}
+			w.WriteHeader(http.StatusCreated)
+			io.WriteString(w, `{"Id":"c1"}`)
+		case strings.HasSuffix(p, "/containers/c1/start"):
+			w.WriteHeader(http.StatusNoContent)
+		case strings.HasSuffix(p, "/containers/c1/logs"):
+		case strings.HasSuffix(p, "/containers/c1/wait"):
+			io.WriteString(w, `{"StatusCode":0}`)
+		case strings.HasSuffix(p, "/containers/c1/json"):
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"message":"gone"}`)
+		case r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected Docker API call %s %s", r.Method, p)
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+	cli, err := client.NewClientWithOpts(client.WithHost("tcp://"+srv.Listener.Addr().String()), client.WithVersion("1.45"), client.WithHTTPClient(srv.Client()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec := &DockerExecutor{cli: cli}
+
+	if _, err := exec.Run(context.Background(), Config{
+		Image:        "alpine:3.20",
+		Command:      []string{"cat", SecretsDir + "/API_TOKEN"},
+		Env:          []string{"MODE=test"},
+		SecretFiles:  map[string][]byte{"API_TOKEN": []byte(value)},
+		PullProgress: io.Discard,
+	}); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if string(staged) != value {
+		t.Errorf("staged secret file = %q, want the secret", staged)
+	}
+	for _, kv := range created.Env {
+		if strings.Contains(kv, value) {
+			t.Errorf("secret leaked into the container env: %s", kv)
+		}
+	}
+	var secretMount *mount.Mount
+	for i, m := range created.HostConfig.Mounts {
+		if m.Target == SecretsDir {
+			secretMount = &created.HostConfig.Mounts[i]
+		}
+	}
+	if secretMount == nil || !secretMount.ReadOnly || secretMount.Type != mount.TypeBind {
+		t.Fatalf("secrets mount = %+v, want a read-only bind at %s", secretMount, SecretsDir)
+	}
+	if !strings.HasPrefix(secretMount.Source, secretsTmpfsRoot) {
+		t.Errorf("secrets staged at %s, want under the tmpfs root %s", secretMount.Source, secretsTmpfsRoot)
+	}
+	if _, err := os.Stat(secretMount.Source); !os.IsNotExist(err) {
+		t.Errorf("staged secrets not removed after the run: %v", err)
+	}
+}
+
+func TestStageSecretFiles(t *testing.T) {
+	secretsTmpfsRoot = t.TempDir()
+	t.Cleanup(func() { secretsTmpfsRoot = "/dev/shm" })
+
+	dir, cleanup, err := stageSecretFiles(map[string][]byte{"DB_PASSWORD": []byte("hunter2")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(filepath.Join(dir, "DB_PASSWORD"))
+	if err != nil || info.Mode().Perm() != 0444 {
+		t.Errorf("secret file: %v %v, want mode 0444", info, err)
+	}
+	outer, err := os.Stat(filepath.Dir(dir))
+	if err != nil || outer.Mode().Perm() != 0711 {
+		t.Errorf("staging dir: %v %v, want mode 0711 so it cannot be listed", outer, err)
+	}
+	cleanup()
+	if _, err := os.Stat(filepath.Dir(dir)); !os.IsNotExist(err) {
+		t.Errorf("cleanup left %s behind", filepath.Dir(dir))
+	}
+
+	for _, bad := range []string{"../etc/passwd", "a/b", ".hidden", ""} {
+		if _, _, err := stageSecretFiles(map[string][]byte{bad: nil}); err == nil {
+			t.Errorf("expected secret name %q to be rejected", bad)
+		}
+	}
+
+	secretsTmpfsRoot = filepath.Join(t.TempDir(), "no-tmpfs")
+	if _, _, err := stageSecretFiles(map[string][]byte{"K": nil}); err == nil {
+		t.Error("expected an error without a host tmpfs")
+	}
+}
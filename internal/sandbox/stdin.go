@@ -0,0 +1,43 @@
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+)
+
+// stdinAttacher is the part of the Docker client attachStdin needs.
+type stdinAttacher interface {
+	ContainerAttach(ctx context.Context, containerID string, options container.AttachOptions) (types.HijackedResponse, error)
+}
+
+// stdinStream is a container's attached standard input.
+type stdinStream struct {
+	hijacked types.HijackedResponse
+	src      io.Reader
+}
+
+// attachStdin connects to the stdin of a created container. Call it before
+// the container starts so no input is lost, then feed once it has started.
+func attachStdin(ctx context.Context, cli stdinAttacher, containerID string, src io.Reader) (*stdinStream, error) {
+	hijacked, err := cli.ContainerAttach(ctx, containerID, container.AttachOptions{Stream: true, Stdin: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to attach stdin: %w", err)
+	}
+	return &stdinStream{hijacked: hijacked, src: src}, nil
+}
+
+// feed copies the input into the container and half-closes the connection,
+// so the command reads EOF; StdinOnce then closes its stdin for good.
+func (s *stdinStream) feed() {
+	_, _ = io.Copy(s.hijacked.Conn, s.src)
+	_ = s.hijacked.CloseWrite()
+}
+
+// Close releases the attach connection.
+func (s *stdinStream) Close() {
+	s.hijacked.Close()
+}
@@ -0,0 +1,110 @@
+package sandbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// stdinDaemon is a fake Docker API whose one container upper-cases what it
+// reads on stdin and writes it to stdout, exiting once stdin is closed.
+func stdinDaemon(t *testing.T) (*httptest.Server, *container.Config) {
+	t.Helper()
+	created := &container.Config{}
+	received := make(chan []byte, 1)
+	var output []byte
+	wait := func() {
+		if data, ok := <-received; ok {
+			output = bytes.ToUpper(data)
+			close(received)
+		}
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := r.URL.Path
+		switch {
+		case strings.Contains(p, "/images/") && strings.HasSuffix(p, "/json"):
+			io.WriteString(w, `{"Id":"sha256:feed"}`)
+		case strings.HasSuffix(p, "/containers/create"):
+			json.NewDecoder(r.Body).Decode(created)
+			w.WriteHeader(http.StatusCreated)
+			io.WriteString(w, `{"Id":"c1"}`)
+		case strings.HasSuffix(p, "/containers/c1/attach"):
+			if r.URL.Query().Get("stdin") != "1" {
+				t.Errorf("attach without stdin: %s", r.URL.RawQuery)
+			}
+			conn, buf, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer conn.Close()
+			io.WriteString(conn, "HTTP/1.1 101 UPGRADED\r\nContent-Type: application/vnd.docker.raw-stream\r\nConnection: Upgrade\r\nUpgrade: tcp\r\n\r\n")
+			data, _ := io.ReadAll(io.MultiReader(buf.Reader, conn))
+			received <- data
+		case strings.HasSuffix(p, "/containers/c1/start"):
+			w.WriteHeader(http.StatusNoContent)
+		case strings.HasSuffix(p, "/containers/c1/logs"):
+			wait()
+			stdcopy.NewStdWriter(w, stdcopy.Stdout).Write(output)
+		case strings.HasSuffix(p, "/containers/c1/wait"):
+			wait()
+			io.WriteString(w, `{"StatusCode":0}`)
+		case strings.HasSuffix(p, "/containers/c1/json"):
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"message":"gone"}`)
+		case r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected Docker API call %s %s", r.Method, p)
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, created
+}
+
+func TestRun_PipesStdin(t *testing.T) {
+	srv, created := stdinDaemon(t)
+	cli, err := client.NewClientWithOpts(client.WithHost("tcp://"+srv.Listener.Addr().String()), client.WithVersion("1.45"), client.WithHTTPClient(srv.Client()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec := &DockerExecutor{cli: cli}
+
+	res, err := exec.Run(context.Background(), Config{
+		Image:        "alpine:3.20",
+		Command:      []string{"tr", "a-z", "A-Z"},
+		Stdin:        strings.NewReader("hello from stdin\n"),
+		PullProgress: io.Discard,
+	})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !created.OpenStdin || !created.AttachStdin || !created.StdinOnce {
+		t.Errorf("container not created with stdin open: %+v", created)
+	}
+	out, _ := io.ReadAll(res.Stdout)
+	if string(out) != "HELLO FROM STDIN\n" {
+		t.Errorf("stdout = %q, want the processed stdin", out)
+	}
+	if res.ExitCode != 0 {
+		t.Errorf("exit code = %d", res.ExitCode)
+	}
+}
+
+func TestStart_RejectsStdin(t *testing.T) {
+	exec := &DockerExecutor{}
+	if _, err := exec.Start(context.Background(), Config{Image: "alpine", Stdin: strings.NewReader("x")}, nil, nil); err == nil {
+		t.Error("expected detached containers to refuse stdin")
+	}
+}
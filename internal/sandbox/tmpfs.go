@@ -0,0 +1,64 @@
+package sandbox
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/docker/docker/api/types/mount"
+)
+
+// DefaultTmpfsBytes caps /tmp and any extra tmpfs that sets no size. A
+// tmpfs lives in memory, so without a cap a skill could fill host RAM
+// through /tmp.
+const DefaultTmpfsBytes = 64 * 1024 * 1024 // 64MB
+
+// Tmpfs is an extra in-memory mount, e.g. a scratch directory.
+type Tmpfs struct {
+	Target    string
+	SizeBytes int64 // 0 means DefaultTmpfsBytes
+}
+
+// ValidateTmpfs checks that extra tmpfs targets are clean absolute paths
+// that do not shadow /tmp, the workspace, or the root filesystem, and that
+// no target is declared twice.
+func ValidateTmpfs(mounts []Tmpfs) error {
+	seen := map[string]bool{}
+	for _, m := range mounts {
+		t := m.Target
+		if !path.IsAbs(t) || path.Clean(t) != t || t == "/" {
+			return fmt.Errorf("invalid tmpfs path %q (want a clean absolute path below /)", t)
+		}
+		if t == "/tmp" || t == WorkspaceDir || strings.HasPrefix(t, WorkspaceDir+"/") {
+			return fmt.Errorf("tmpfs path %q is managed by the sandbox", t)
+		}
+		if seen[t] {
+			return fmt.Errorf("duplicate tmpfs path %q", t)
+		}
+		seen[t] = true
+		if m.SizeBytes < 0 {
+			return fmt.Errorf("tmpfs %s: negative size", t)
+		}
+	}
+	return nil
+}
+
+// tmpfsMounts returns the size-capped /tmp mount and cfg's extra tmpfs
+// mounts. The rootfs is read-only, so /tmp is always provided.
+func tmpfsMounts(cfg Config) []mount.Mount {
+	sized := func(target string, size int64) mount.Mount {
+		if size <= 0 {
+			size = DefaultTmpfsBytes
+		}
+		return mount.Mount{
+			Type:         mount.TypeTmpfs,
+			Target:       target,
+			TmpfsOptions: &mount.TmpfsOptions{SizeBytes: size},
+		}
+	}
+	mounts := []mount.Mount{sized("/tmp", cfg.TmpBytes)}
+	for _, t := range cfg.Tmpfs {
+		mounts = append(mounts, sized(t.Target, t.SizeBytes))
+	}
+	return mounts
+}
@@ -0,0 +1,59 @@
+package sandbox
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/mount"
+)
+
+func tmpfsSizes(t *testing.T, cfg Config) map[string]int64 {
+	t.Helper()
+	_, host := hardenedConfigs(cfg, nil)
+	sizes := map[string]int64{}
+	for _, m := range host.Mounts {
+		if m.Type != mount.TypeTmpfs {
+			continue
+		}
+		if m.TmpfsOptions == nil {
+			t.Fatalf("tmpfs %s has no size limit", m.Target)
+		}
+		sizes[m.Target] = m.TmpfsOptions.SizeBytes
+	}
+	return sizes
+}
+
+func TestHardenedConfigs_TmpfsSize(t *testing.T) {
+	if got := tmpfsSizes(t, Config{Image: "alpine"}); got["/tmp"] != DefaultTmpfsBytes || len(got) != 1 {
+		t.Errorf("default tmpfs = %v, want only /tmp at %d", got, DefaultTmpfsBytes)
+	}
+
+	got := tmpfsSizes(t, Config{
+		Image:    "alpine",
+		TmpBytes: 128 << 20,
+		Tmpfs:    []Tmpfs{{Target: "/cache", SizeBytes: 16 << 20}, {Target: "/scratch"}},
+	})
+	want := map[string]int64{"/tmp": 128 << 20, "/cache": 16 << 20, "/scratch": DefaultTmpfsBytes}
+	for target, size := range want {
+		if got[target] != size {
+			t.Errorf("%s size = %d, want %d", target, got[target], size)
+		}
+	}
+}
+
+func TestValidateTmpfs(t *testing.T) {
+	if err := ValidateTmpfs([]Tmpfs{{Target: "/cache"}, {Target: "/var/run/app", SizeBytes: 1 << 20}}); err != nil {
+		t.Errorf("valid mounts rejected: %v", err)
+	}
+	for _, bad := range [][]Tmpfs{
+		{{Target: "relative"}},
+		{{Target: "/"}},
+		{{Target: "/tmp"}},
+		{{Target: WorkspaceDir + "/input"}},
+		{{Target: "/a/../b"}},
+		{{Target: "/cache"}, {Target: "/cache"}},
+	} {
+		if err := ValidateTmpfs(bad); err == nil {
+			t.Errorf("ValidateTmpfs(%v) should fail", bad)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package sandbox
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mackeh/AegisClaw/internal/scope"
+)
+
+// DefaultUser is the UID:GID sandboxed commands run as when neither the
+// skill nor the config names one.
+const DefaultUser = "1000:1000"
+
+// RootUserScope is the scope under which policy evaluates a skill that runs
+// as root, e.g. "sandbox.root_user:0:0". It is always RiskCritical.
+const RootUserScope = scope.RootUserName
+
+// User is the numeric identity a container's command runs as.
+type User struct {
+	UID int
+	GID int
+}
+
+// ParseUser parses "UID:GID", or a bare "UID" meaning the group of the same
+// number. Only numeric IDs are accepted: a name would resolve against the
+// image's /etc/passwd, which the skill controls. Empty means DefaultUser.
+func ParseUser(s string) (User, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		s = DefaultUser
+	}
+	uidStr, gidStr, hasGID := strings.Cut(s, ":")
+	if !hasGID {
+		gidStr = uidStr
+	}
+	uid, err := parseID(uidStr)
+	if err != nil {
+		return User{}, fmt.Errorf("invalid user %q: UID %w", s, err)
+	}
+	gid, err := parseID(gidStr)
+	if err != nil {
+		return User{}, fmt.Errorf("invalid user %q: GID %w", s, err)
+	}
+	return User{UID: uid, GID: gid}, nil
+}
+
+func parseID(s string) (int, error) {
+	id, err := strconv.Atoi(s)
+	if err != nil || id < 0 || strconv.Itoa(id) != s {
+		return 0, fmt.Errorf("must be a non-negative number")
+	}
+	return id, nil
+}
+
+// String returns the "UID:GID" form Docker expects.
+func (u User) String() string {
+	return fmt.Sprintf("%d:%d", u.UID, u.GID)
+}
+
+// IsRoot reports whether u runs with root's user or group, either of which
+// owns most of an image's filesystem.
+func (u User) IsRoot() bool {
+	return u.UID == 0 || u.GID == 0
+}
+
+// containerUser resolves cfg.User and refuses root unless cfg.AllowRoot, and
+// cfg.CapAdd unless the user would actually hold the capabilities.
+func containerUser(cfg Config) (User, error) {
+	u, err := ParseUser(cfg.User)
+	if err != nil {
+		return User{}, err
+	}
+	if u.IsRoot() && !cfg.AllowRoot {
+		return User{}, fmt.Errorf("refusing to run as root (user %s) without an approved override", u)
+	}
+	if err := checkCapabilities(u, cfg.CapAdd); err != nil {
+		return User{}, err
+	}
+	return u, nil
+}
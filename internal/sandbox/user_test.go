@@ -0,0 +1,94 @@
+package sandbox
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestParseUser(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    User
+		wantErr bool
+	}{
+		{"", User{1000, 1000}, false},
+		{"1001:2000", User{1001, 2000}, false},
+		{"65534", User{65534, 65534}, false},
+		{"0:0", User{0, 0}, false},
+		{"app", User{}, true},
+		{"1000:staff", User{}, true},
+		{"-1:1000", User{}, true},
+		{"1000:", User{}, true},
+		{"+5:5", User{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseUser(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseUser(%q) err = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseUser(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestHardenedConfigs_User(t *testing.T) {
+	cfg, _ := hardenedConfigs(Config{Image: "alpine"}, nil)
+	if cfg.User != DefaultUser {
+		t.Errorf("default user = %q, want %q", cfg.User, DefaultUser)
+	}
+	cfg, _ = hardenedConfigs(Config{Image: "alpine", User: "1001:2000"}, nil)
+	if cfg.User != "1001:2000" {
+		t.Errorf("user = %q, want the declared 1001:2000", cfg.User)
+	}
+}
+
+func TestRun_RefusesRootWithoutOverride(t *testing.T) {
+	exec := &DockerExecutor{}
+	for _, user := range []string{"0:0", "0", "1000:0", "0:1000"} {
+		_, err := exec.Run(context.Background(), Config{Image: "alpine", User: user})
+		if err == nil || !strings.Contains(err.Error(), "root") {
+			t.Errorf("Run as %s: err = %v, want a refusal to run as root", user, err)
+		}
+		_, err = exec.Start(context.Background(), Config{Image: "alpine", User: user}, nil, nil)
+		if err == nil || !strings.Contains(err.Error(), "root") {
+			t.Errorf("Start as %s: err = %v, want a refusal to run as root", user, err)
+		}
+	}
+	if _, err := exec.Run(context.Background(), Config{Image: "alpine", User: "nobody"}); err == nil {
+		t.Error("expected a non-numeric user to be refused")
+	}
+}
+
+func TestContainerUser_AllowRoot(t *testing.T) {
+	u, err := containerUser(Config{User: "0:0", AllowRoot: true})
+	if err != nil || !u.IsRoot() {
+		t.Errorf("containerUser with AllowRoot = %+v, %v; want root", u, err)
+	}
+}
+
+func TestPrepareWorkspace_OwnedByUser(t *testing.T) {
+	fc := &fakeCopier{}
+	cfg := Config{Files: map[string][]byte{"in.txt": []byte("hi")}, Outputs: []string{OutputDir + "/r.txt"}, User: "1001:2000"}
+	if err := prepareWorkspace(context.Background(), fc, "c1", cfg); err != nil {
+		t.Fatalf("prepareWorkspace: %v", err)
+	}
+	tr := tar.NewReader(bytes.NewReader(fc.archive))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Uid != 1001 || hdr.Gid != 2000 {
+			t.Errorf("%s owned by %d:%d, want 1001:2000", hdr.Name, hdr.Uid, hdr.Gid)
+		}
+	}
+}
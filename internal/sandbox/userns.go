@@ -0,0 +1,47 @@
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// UsernsRemapEnabled reports whether a Docker daemon's SecurityOptions (as
+// returned by `docker info`) include user-namespace remapping, e.g.
+// "name=userns". With remapping, UID 1000 inside a container maps to an
+// unprivileged subordinate UID on the host.
+func UsernsRemapEnabled(securityOptions []string) bool {
+	for _, opt := range securityOptions {
+		for _, field := range strings.Split(opt, ",") {
+			if strings.TrimSpace(field) == "name=userns" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// UsernsRemapEnabled queries the daemon for user-namespace remapping.
+func (e *DockerExecutor) UsernsRemapEnabled(ctx context.Context) (bool, error) {
+	info, err := e.cli.Info(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to query docker info: %w", err)
+	}
+	return UsernsRemapEnabled(info.SecurityOptions), nil
+}
+
+// requireUsernsRemap refuses to run when cfg demands remapping but the
+// daemon does not provide it.
+func (e *DockerExecutor) requireUsernsRemap(ctx context.Context, cfg Config) error {
+	if !cfg.RequireUsernsRemap {
+		return nil
+	}
+	enabled, err := e.UsernsRemapEnabled(ctx)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		return fmt.Errorf("user namespace remapping is required but not enabled on the Docker daemon (set \"userns-remap\" in daemon.json)")
+	}
+	return nil
+}
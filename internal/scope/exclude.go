@@ -0,0 +1,89 @@
+package scope
+
+import (
+	"net"
+	"path"
+	"strings"
+)
+
+// ExclusionPrefix marks an exclusion scope: "!files.read:/data/secrets"
+// alongside "files.read:/data" grants /data except /data/secrets.
+// Exclusions always win over the grants they overlap, whichever is broader.
+const ExclusionPrefix = "!"
+
+// Excludes reports whether exclusion x carves out s: x names s's scope or a
+// parent of it (see Covers) and x's resource covers s's (see
+// ResourceCovers). An exclusion without a resource removes the whole scope.
+func (x Scope) Excludes(s Scope) bool {
+	return x.Exclude && !s.Exclude && Covers(x.Name, s.Name) && ResourceCovers(x.Resource, s.Resource)
+}
+
+// ExcludedBy returns the first exclusion in scopes that carves out s.
+func ExcludedBy(scopes []Scope, s Scope) (Scope, bool) {
+	for _, x := range scopes {
+		if x.Excludes(s) {
+			return x, true
+		}
+	}
+	return Scope{}, false
+}
+
+// ExcludedResources returns the distinct resources of the exclusions in
+// scopes that cover any of names, e.g. the paths carved out of
+// "files.read" and "files.write".
+func ExcludedResources(scopes []Scope, names ...string) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, x := range scopes {
+		if !x.Exclude || x.Resource == "" || seen[x.Resource] {
+			continue
+		}
+		for _, name := range names {
+			if Covers(x.Name, name) {
+				seen[x.Resource] = true
+				out = append(out, x.Resource)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// ResourceCovers reports whether the resource pattern covers resource. An
+// empty pattern covers everything; a path covers itself and everything
+// beneath it ("/data" covers "/data/secrets" but not "/database"); a CIDR
+// covers the addresses and narrower ranges inside it; a domain covers
+// itself and its subdomains. Anything else must match exactly.
+func ResourceCovers(pattern, resource string) bool {
+	switch {
+	case pattern == "":
+		return true
+	case resource == "":
+		return false
+	case strings.HasPrefix(pattern, "/"):
+		if !strings.HasPrefix(resource, "/") {
+			return false
+		}
+		p, r := path.Clean(pattern), path.Clean(resource)
+		return p == "/" || r == p || strings.HasPrefix(r, p+"/")
+	}
+	if _, network, err := net.ParseCIDR(pattern); err == nil {
+		return cidrCovers(network, resource)
+	}
+	p := strings.TrimSuffix(strings.ToLower(pattern), ".")
+	r := strings.TrimSuffix(strings.ToLower(resource), ".")
+	return r == p || strings.HasSuffix(r, "."+p)
+}
+
+func cidrCovers(network *net.IPNet, resource string) bool {
+	if ip := net.ParseIP(resource); ip != nil {
+		return network.Contains(ip)
+	}
+	_, inner, err := net.ParseCIDR(resource)
+	if err != nil {
+		return false
+	}
+	outerOnes, outerBits := network.Mask.Size()
+	innerOnes, innerBits := inner.Mask.Size()
+	return outerBits == innerBits && outerOnes <= innerOnes && network.Contains(inner.IP)
+}
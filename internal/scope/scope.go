@@ -1,7 +1,10 @@
 // Package scope defines the capability-based permission model for AegisClaw.
 package scope
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // Risk represents the risk level of a scope
 type Risk int
@@ -49,31 +52,39 @@ type Scope struct {
 	Name      string // e.g., "email.send", "shell.exec"
 	Resource  string // optional resource path, e.g., "/home/user/docs"
 	RiskLevel Risk
+	Exclude   bool // an exclusion such as "!files.read:/data/secrets"; see Excludes
 }
 
 // String returns a human-readable representation of the scope
 func (s Scope) String() string {
+	str := s.Name
 	if s.Resource != "" {
-		return fmt.Sprintf("%s:%s", s.Name, s.Resource)
+		str = fmt.Sprintf("%s:%s", s.Name, s.Resource)
+	}
+	if s.Exclude {
+		str = ExclusionPrefix + str
 	}
-	return s.Name
+	return str
 }
 
 // Predefined scopes
 var (
 	// Critical scopes - always require approval
 	ShellExec = Scope{Name: "shell.exec", RiskLevel: RiskCritical}
-	
+	// SecretsWrite lets a skill store a secret via the host callback API.
+	// Reads (secrets.access) stay read-only env injection.
+	SecretsWrite = Scope{Name: "secrets.write", RiskLevel: RiskCritical}
+
 	// High-risk scopes
-	FilesWrite   = Scope{Name: "files.write", RiskLevel: RiskHigh}
-	EmailSend    = Scope{Name: "email.send", RiskLevel: RiskHigh}
+	FilesWrite    = Scope{Name: "files.write", RiskLevel: RiskHigh}
+	EmailSend     = Scope{Name: "email.send", RiskLevel: RiskHigh}
 	SecretsAccess = Scope{Name: "secrets.access", RiskLevel: RiskHigh}
-	
+
 	// Medium-risk scopes
 	HTTPRequest  = Scope{Name: "http.request", RiskLevel: RiskMedium}
 	EmailRead    = Scope{Name: "email.read", RiskLevel: RiskMedium}
 	CalendarRead = Scope{Name: "calendar.read", RiskLevel: RiskMedium}
-	
+
 	// Low-risk scopes
 	FilesRead = Scope{Name: "files.read", RiskLevel: RiskLow}
 )
@@ -86,13 +97,68 @@ var Registry = map[string]Scope{
 	"email.send":     EmailSend,
 	"email.read":     EmailRead,
 	"secrets.access": SecretsAccess,
+	"secrets.write":  SecretsWrite,
 	"http.request":   HTTPRequest,
 	"calendar.read":  CalendarRead,
 }
 
+// CapabilityName is the scope the runtime synthesises for Linux capabilities
+// a skill requests (see sandbox.CapabilityScopes); skills never declare it.
+const CapabilityName = "sandbox.capability"
+
+// RootUserName is the scope the runtime synthesises for a skill that runs
+// as root in its container (see sandbox.RootUserScope).
+const RootUserName = "sandbox.root_user"
+
+// IsKnown reports whether name is a scope AegisClaw recognises: one in
+// Registry or one the runtime generates itself.
+func IsKnown(name string) bool {
+	if _, ok := Registry[name]; ok {
+		return true
+	}
+	return name == CapabilityName || name == RootUserName
+}
+
+// Covers reports whether a rule written for pattern applies to the scope
+// name. Scope names are dot-separated hierarchies, so "files" covers
+// "files.read" and "files.write", while "files.read" covers only itself.
+func Covers(pattern, name string) bool {
+	return pattern == name || strings.HasPrefix(name, pattern+".")
+}
+
+// Parents returns the broader scopes that cover name, outermost first:
+// "a.b.c" has parents "a" and "a.b".
+func Parents(name string) []string {
+	var parents []string
+	for i := 0; i < len(name); i++ {
+		if name[i] == '.' {
+			parents = append(parents, name[:i])
+		}
+	}
+	return parents
+}
+
 // Parse parses a scope string into a Scope struct.
-// Supported formats: "scope.name" or "scope.name:resource"
+// Supported formats: "scope.name" or "scope.name:resource", either prefixed
+// with "!" for an exclusion. Exclusions only take access away, so they are
+// low risk whatever scope they name.
 func Parse(s string) (Scope, error) {
+	if rest, ok := strings.CutPrefix(s, ExclusionPrefix); ok {
+		if rest == "" {
+			return Scope{}, fmt.Errorf("empty exclusion scope %q", s)
+		}
+		parsed, err := Parse(rest)
+		if err != nil {
+			return Scope{}, err
+		}
+		if parsed.Exclude {
+			return Scope{}, fmt.Errorf("invalid scope %q: doubled exclusion", s)
+		}
+		parsed.Exclude = true
+		parsed.RiskLevel = RiskLow
+		return parsed, nil
+	}
+
 	name := s
 	resource := ""
 
@@ -110,7 +176,7 @@ func Parse(s string) (Scope, error) {
 			RiskLevel: baseScope.RiskLevel,
 		}, nil
 	}
-	
+
 	// Unknown scope - return with unknown risk
 	return Scope{Name: name, Resource: resource, RiskLevel: RiskMedium}, nil
 }
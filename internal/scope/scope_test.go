@@ -15,6 +15,7 @@ func TestParse(t *testing.T) {
 		{"files.read:/home/user", "files.read", "/home/user", RiskLow},
 		{"unknown.scope", "unknown.scope", "", RiskMedium},
 		{"files.write:/etc/passwd", "files.write", "/etc/passwd", RiskHigh},
+		{"secrets.write:GITHUB_TOKEN", "secrets.write", "GITHUB_TOKEN", RiskCritical},
 	}
 
 	for _, tt := range tests {
@@ -30,3 +31,98 @@ func TestParse(t *testing.T) {
 		}
 	}
 }
+
+func TestSecretsWriteRiskExceedsRead(t *testing.T) {
+	read, _ := Parse("secrets.access:API_KEY")
+	write, _ := Parse("secrets.write:API_KEY")
+	if write.RiskLevel <= read.RiskLevel {
+		t.Errorf("secrets.write risk %s should exceed secrets.access risk %s", write.RiskLevel, read.RiskLevel)
+	}
+}
+
+func TestCovers(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"files", "files.read", true},
+		{"files", "files.write", true},
+		{"files.read", "files.read", true},
+		{"files.read", "files.write", false},
+		{"files.read", "files", false},
+		{"files", "filesystem.read", false},
+		{"a", "a.b.c", true},
+	}
+	for _, tt := range tests {
+		if got := Covers(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("Covers(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+	if got := Parents("a.b.c"); len(got) != 2 || got[0] != "a" || got[1] != "a.b" {
+		t.Errorf("Parents(a.b.c) = %v", got)
+	}
+}
+
+func TestParse_Exclusion(t *testing.T) {
+	x, err := Parse("!files.read:/data/secrets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !x.Exclude || x.Name != "files.read" || x.Resource != "/data/secrets" || x.RiskLevel != RiskLow {
+		t.Errorf("Parse(!files.read:/data/secrets) = %+v", x)
+	}
+	if got := x.String(); got != "!files.read:/data/secrets" {
+		t.Errorf("String() = %q", got)
+	}
+	// Excluding a critical scope takes access away, so it is not risky.
+	if sh, _ := Parse("!shell.exec"); !sh.Exclude || sh.RiskLevel != RiskLow {
+		t.Errorf("Parse(!shell.exec) = %+v", sh)
+	}
+	for _, bad := range []string{"!", "!!files.read:/data"} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("Parse(%q) should fail", bad)
+		}
+	}
+}
+
+func TestExcludes(t *testing.T) {
+	exclusions := []Scope{
+		{Name: "files.read", Resource: "/data/secrets", Exclude: true},
+		{Name: "http.request", Resource: "10.0.0.0/8", Exclude: true},
+		{Name: "http.request", Resource: "internal.example.com", Exclude: true},
+		{Name: "files", Resource: "/etc", Exclude: true},
+	}
+	tests := []struct {
+		scope    string
+		excluded bool
+	}{
+		{"files.read:/data", false},
+		{"files.read:/data/secrets", true},
+		{"files.read:/data/secrets/db.key", true},
+		{"files.read:/data/secrets-public", false},
+		{"files.read:/data/../data/secrets", true},
+		{"files.write:/etc/hosts", true},
+		{"files.read", false}, // an unrestricted grant keeps all but the excluded subset
+		{"http.request:api.example.com", false},
+		{"http.request:internal.example.com", true},
+		{"http.request:db.internal.example.com", true},
+		{"http.request:10.1.2.3", true},
+		{"http.request:10.1.0.0/16", true},
+		{"http.request:192.168.1.1", false},
+		{"!files.read:/data/secrets", false}, // exclusions never exclude each other
+	}
+	for _, tt := range tests {
+		s, _ := Parse(tt.scope)
+		if _, got := ExcludedBy(exclusions, s); got != tt.excluded {
+			t.Errorf("ExcludedBy(%s) = %v, want %v", tt.scope, got, tt.excluded)
+		}
+	}
+
+	if got := ExcludedResources(exclusions, "files.write"); len(got) != 1 || got[0] != "/etc" {
+		t.Errorf("ExcludedResources(files.write) = %v", got)
+	}
+	// "!files:/etc" covers both names but is reported once.
+	if got := ExcludedResources(exclusions, "files.read", "files.write"); len(got) != 2 || got[0] != "/data/secrets" || got[1] != "/etc" {
+		t.Errorf("ExcludedResources(files.read, files.write) = %v", got)
+	}
+}
@@ -0,0 +1,111 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"filippo.io/age"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrBadPassphrase is returned by Import when the passphrase does not open
+// the bundle.
+var ErrBadPassphrase = errors.New("wrong passphrase or not a secrets bundle")
+
+// bundleWorkFactor is the scrypt work factor (log2 N) used for exported
+// bundles. Tests lower it; importing accepts anything up to age's default
+// maximum.
+var bundleWorkFactor = 18
+
+// Export writes every stored secret to w as an age bundle encrypted with
+// passphrase (scrypt), independent of this machine's key file. It returns
+// the number of secrets written.
+func (m *Manager) Export(w io.Writer, passphrase string) (int, error) {
+	if passphrase == "" {
+		return 0, fmt.Errorf("a passphrase is required to export secrets")
+	}
+	secrets, err := m.loadAll()
+	if err != nil && !os.IsNotExist(err) {
+		return 0, err
+	}
+	if secrets == nil {
+		secrets = map[string]string{}
+	}
+
+	recipient, err := age.NewScryptRecipient(passphrase)
+	if err != nil {
+		return 0, err
+	}
+	recipient.SetWorkFactor(bundleWorkFactor)
+
+	data, err := yaml.Marshal(secrets)
+	if err != nil {
+		return 0, err
+	}
+	enc, err := age.Encrypt(w, recipient)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encrypt bundle: %w", err)
+	}
+	if _, err := enc.Write(data); err != nil {
+		return 0, err
+	}
+	if err := enc.Close(); err != nil {
+		return 0, err
+	}
+	return len(secrets), nil
+}
+
+// Import decrypts a bundle written by Export and merges it into the store.
+// Secrets that already exist locally are kept unless overwrite is set; their
+// names are returned as skipped. The store is written once, after the whole
+// bundle has been read.
+func (m *Manager) Import(r io.Reader, passphrase string, overwrite bool) (imported, skipped []string, err error) {
+	identity, err := age.NewScryptIdentity(passphrase)
+	if err != nil {
+		return nil, nil, err
+	}
+	dec, err := age.Decrypt(r, identity)
+	if err != nil {
+		var noMatch *age.NoIdentityMatchError
+		if errors.As(err, &noMatch) {
+			return nil, nil, ErrBadPassphrase
+		}
+		return nil, nil, fmt.Errorf("failed to open bundle: %w", err)
+	}
+	data, err := io.ReadAll(dec)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read bundle: %w", err)
+	}
+	var bundle map[string]string
+	if err := yaml.Unmarshal(data, &bundle); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse bundle: %w", err)
+	}
+
+	secrets, err := m.loadAll()
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, err
+	}
+	if secrets == nil {
+		secrets = make(map[string]string, len(bundle))
+	}
+	for key, val := range bundle {
+		if _, exists := secrets[key]; exists && !overwrite {
+			skipped = append(skipped, key)
+			continue
+		}
+		secrets[key] = val
+		imported = append(imported, key)
+	}
+	sort.Strings(imported)
+	sort.Strings(skipped)
+	if len(imported) == 0 {
+		return imported, skipped, nil
+	}
+	if err := m.saveAll(secrets); err != nil {
+		return nil, nil, err
+	}
+	return imported, skipped, nil
+}
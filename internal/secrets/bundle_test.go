@@ -0,0 +1,93 @@
+package secrets
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func newTestManager(t *testing.T, secrets map[string]string) *Manager {
+	t.Helper()
+	mgr := NewManager(t.TempDir())
+	if _, err := mgr.Init(); err != nil {
+		t.Fatalf("init error: %v", err)
+	}
+	for k, v := range secrets {
+		if err := mgr.Set(k, v); err != nil {
+			t.Fatalf("set %s: %v", k, err)
+		}
+	}
+	return mgr
+}
+
+func lowerWorkFactor(t *testing.T) {
+	old := bundleWorkFactor
+	bundleWorkFactor = 10
+	t.Cleanup(func() { bundleWorkFactor = old })
+}
+
+func TestBundle_RoundTrip(t *testing.T) {
+	lowerWorkFactor(t)
+	src := newTestManager(t, map[string]string{"API_KEY": "one", "DB_PASS": "two"})
+
+	var buf bytes.Buffer
+	n, err := src.Export(&buf, "correct horse")
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("exported %d secrets, want 2", n)
+	}
+	if bytes.Contains(buf.Bytes(), []byte("one")) {
+		t.Error("bundle contains a plaintext secret value")
+	}
+
+	// A different machine: its own key, one overlapping secret.
+	dst := newTestManager(t, map[string]string{"DB_PASS": "local"})
+	imported, skipped, err := dst.Import(bytes.NewReader(buf.Bytes()), "correct horse", false)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if !reflect.DeepEqual(imported, []string{"API_KEY"}) || !reflect.DeepEqual(skipped, []string{"DB_PASS"}) {
+		t.Errorf("imported=%v skipped=%v", imported, skipped)
+	}
+	if v, _ := dst.Get("API_KEY"); v != "one" {
+		t.Errorf("API_KEY = %q, want one", v)
+	}
+	if v, _ := dst.Get("DB_PASS"); v != "local" {
+		t.Errorf("DB_PASS = %q, want the local value kept", v)
+	}
+
+	if _, _, err := dst.Import(bytes.NewReader(buf.Bytes()), "correct horse", true); err != nil {
+		t.Fatalf("import --overwrite: %v", err)
+	}
+	if v, _ := dst.Get("DB_PASS"); v != "two" {
+		t.Errorf("DB_PASS = %q, want two after overwrite", v)
+	}
+}
+
+func TestBundle_WrongPassphrase(t *testing.T) {
+	lowerWorkFactor(t)
+	src := newTestManager(t, map[string]string{"API_KEY": "one"})
+	var buf bytes.Buffer
+	if _, err := src.Export(&buf, "right"); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := newTestManager(t, nil)
+	_, _, err := dst.Import(&buf, "wrong", false)
+	if !errors.Is(err, ErrBadPassphrase) {
+		t.Fatalf("err = %v, want ErrBadPassphrase", err)
+	}
+	if keys, _ := dst.List(); len(keys) != 0 {
+		t.Errorf("store changed after failed import: %v", keys)
+	}
+}
+
+func TestBundle_EmptyPassphrase(t *testing.T) {
+	mgr := newTestManager(t, nil)
+	if _, err := mgr.Export(&bytes.Buffer{}, ""); err == nil {
+		t.Error("export with an empty passphrase should fail")
+	}
+}
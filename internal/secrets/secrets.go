@@ -15,6 +15,17 @@ import (
 type Manager struct {
 	configDir string
 	keyFile   string
+	onAccess  AccessFunc
+}
+
+// AccessFunc is told about every secret read: the key and the error, if
+// the read failed. It never sees the value.
+type AccessFunc func(key string, err error)
+
+// OnAccess registers fn to be called after every Get, so callers can audit
+// which secrets were read.
+func (m *Manager) OnAccess(fn AccessFunc) {
+	m.onAccess = fn
 }
 
 // NewManager creates a new secrets manager
@@ -70,6 +81,14 @@ func (m *Manager) Set(key, value string) error {
 
 // Get retrieves and decrypts a specific secret
 func (m *Manager) Get(key string) (string, error) {
+	val, err := m.get(key)
+	if m.onAccess != nil {
+		m.onAccess(key, err)
+	}
+	return val, err
+}
+
+func (m *Manager) get(key string) (string, error) {
 	secrets, err := m.loadAll()
 	if err != nil {
 		return "", err
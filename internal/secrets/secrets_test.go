@@ -1,8 +1,10 @@
 package secrets
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -185,6 +187,36 @@ func TestAgeStore(t *testing.T) {
 	}
 }
 
+func TestAccessHook(t *testing.T) {
+	tmp := t.TempDir()
+	mgr := NewManager(tmp)
+	if _, err := mgr.Init(); err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.Set("TOKEN", "s3cr3t"); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	hook := func(key string, err error) {
+		got = append(got, fmt.Sprintf("%s:%v", key, err == nil))
+	}
+	mgr.OnAccess(hook)
+	_, _ = mgr.Get("TOKEN")
+	_, _ = mgr.Get("MISSING")
+
+	store := WithAccessHook(NewAgeStore(tmp), hook)
+	_, _ = store.Get("TOKEN")
+	if err := store.Set("OTHER", "x"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"TOKEN:true", "MISSING:false", "TOKEN:true"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("accesses = %v, want %v", got, want)
+	}
+}
+
 func TestVaultStore_MissingToken(t *testing.T) {
 	os.Unsetenv("VAULT_TOKEN_TEST_MISSING")
 	_, err := NewVaultStore(VaultConfig{
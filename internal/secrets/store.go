@@ -46,3 +46,20 @@ func (s *AgeStore) Delete(key string) error {
 func (s *AgeStore) List() ([]string, error) {
 	return s.mgr.List()
 }
+
+// WithAccessHook wraps s so fn is called after every Get, whatever the
+// backend.
+func WithAccessHook(s Store, fn AccessFunc) Store {
+	return &auditedStore{Store: s, onAccess: fn}
+}
+
+type auditedStore struct {
+	Store
+	onAccess AccessFunc
+}
+
+func (s *auditedStore) Get(key string) (string, error) {
+	val, err := s.Store.Get(key)
+	s.onAccess(key, err)
+	return val, err
+}
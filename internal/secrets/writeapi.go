@@ -0,0 +1,112 @@
+package secrets
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxSecretValueBytes bounds a value written through the callback API.
+const maxSecretValueBytes = 64 << 10
+
+// WriteAPI is a per-run host-side HTTP endpoint through which a sandboxed
+// skill granted secrets.write:KEY can store a secret it obtained. Only the
+// granted keys are writable, every request must carry the run's bearer
+// token, and nothing is ever readable — reads stay env injection.
+//
+//	PUT /v1/secrets/{KEY}   body = secret value
+type WriteAPI struct {
+	// OnWrite, if set, is called after each successful write (for auditing).
+	OnWrite func(key string)
+	// ListenAddr is the IP Start binds to. Empty means 127.0.0.1; the agent
+	// sets the Docker bridge gateway so the sandboxed skill can reach it.
+	ListenAddr string
+	// Port is the bound port, set by Start.
+	Port int
+
+	store   Store
+	allowed map[string]bool
+	token   string
+	srv     *http.Server
+}
+
+// NewWriteAPI creates a write endpoint for the given keys, backed by store.
+func NewWriteAPI(store Store, keys []string) (*WriteAPI, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("failed to generate write token: %w", err)
+	}
+	api := &WriteAPI{store: store, allowed: make(map[string]bool), token: hex.EncodeToString(buf)}
+	for _, k := range keys {
+		api.allowed[k] = true
+	}
+	return api, nil
+}
+
+// Token returns the bearer token the skill must present.
+func (a *WriteAPI) Token() string { return a.token }
+
+// Start binds the API to a random port of ListenAddr (127.0.0.1 by
+// default). Every request still needs the run's bearer token.
+func (a *WriteAPI) Start() error {
+	host := a.ListenAddr
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	ln, err := net.Listen("tcp", net.JoinHostPort(host, "0"))
+	if err != nil {
+		return fmt.Errorf("failed to start secrets write API: %w", err)
+	}
+	a.Port = ln.Addr().(*net.TCPAddr).Port
+	a.srv = &http.Server{Handler: a, ReadHeaderTimeout: 5 * time.Second}
+	go a.srv.Serve(ln)
+	return nil
+}
+
+// Stop shuts the API down.
+func (a *WriteAPI) Stop() {
+	if a.srv == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_ = a.srv.Shutdown(ctx)
+}
+
+// ServeHTTP implements http.Handler.
+func (a *WriteAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	auth := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(auth), []byte(a.token)) != 1 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	key, ok := strings.CutPrefix(r.URL.Path, "/v1/secrets/")
+	if !ok || key == "" || !a.allowed[key] {
+		http.Error(w, "secret not writable by this skill", http.StatusForbidden)
+		return
+	}
+	value, err := io.ReadAll(io.LimitReader(r.Body, maxSecretValueBytes+1))
+	if err != nil || len(value) == 0 || len(value) > maxSecretValueBytes {
+		http.Error(w, "invalid secret value", http.StatusBadRequest)
+		return
+	}
+	if err := a.store.Set(key, string(value)); err != nil {
+		http.Error(w, "failed to store secret", http.StatusInternalServerError)
+		return
+	}
+	if a.OnWrite != nil {
+		a.OnWrite(key)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
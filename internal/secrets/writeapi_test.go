@@ -0,0 +1,61 @@
+package secrets
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type memStore map[string]string
+
+func (m memStore) Get(k string) (string, error) {
+	v, ok := m[k]
+	if !ok {
+		return "", fmt.Errorf("not found")
+	}
+	return v, nil
+}
+func (m memStore) Set(k, v string) error   { m[k] = v; return nil }
+func (m memStore) Delete(k string) error   { delete(m, k); return nil }
+func (m memStore) List() ([]string, error) { return nil, nil }
+
+func TestWriteAPI(t *testing.T) {
+	store := memStore{}
+	api, err := NewWriteAPI(store, []string{"NEW_TOKEN"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var written []string
+	api.OnWrite = func(k string) { written = append(written, k) }
+
+	put := func(path, token, body string) int {
+		req := httptest.NewRequest(http.MethodPut, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		api.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := put("/v1/secrets/NEW_TOKEN", "wrong", "v"); code != http.StatusUnauthorized {
+		t.Errorf("bad token: got %d", code)
+	}
+	if code := put("/v1/secrets/OTHER", api.Token(), "v"); code != http.StatusForbidden {
+		t.Errorf("ungranted key: got %d", code)
+	}
+	if code := put("/v1/secrets/NEW_TOKEN", api.Token(), "s3cr3t"); code != http.StatusNoContent {
+		t.Errorf("granted write: got %d", code)
+	}
+	if store["NEW_TOKEN"] != "s3cr3t" || len(written) != 1 {
+		t.Errorf("store = %v, written = %v", store, written)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/secrets/NEW_TOKEN", nil)
+	req.Header.Set("Authorization", "Bearer "+api.Token())
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, req)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("read via write API should be refused, got %d", rec.Code)
+	}
+}
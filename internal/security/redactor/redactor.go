@@ -2,14 +2,16 @@ package redactor
 
 import (
 	"io"
+	"regexp"
 	"strings"
 	"sync"
 )
 
 // Redactor handles the scrubbing of sensitive information from text
 type Redactor struct {
-	mu      sync.RWMutex
-	secrets []string
+	mu       sync.RWMutex
+	secrets  []string
+	patterns []*regexp.Regexp
 }
 
 // New creates a new Redactor with an initial list of secrets
@@ -23,6 +25,20 @@ func New(secrets ...string) *Redactor {
 	return r
 }
 
+// NewWithPatterns creates a Redactor that masks both known secret values and
+// anything matching the given patterns (API key shapes, JWTs, ...). Patterns
+// catch credentials a skill emits that were never in the secret store; pass
+// guardrails.SecretPatterns() for the default set.
+func NewWithPatterns(values []string, patterns []*regexp.Regexp) *Redactor {
+	r := New(values...)
+	for _, p := range patterns {
+		if p != nil {
+			r.patterns = append(r.patterns, p)
+		}
+	}
+	return r
+}
+
 // Add adds a secret to the redaction list
 func (r *Redactor) Add(secret string) {
 	r.mu.Lock()
@@ -32,12 +48,20 @@ func (r *Redactor) Add(secret string) {
 	}
 }
 
-// Redact replaces all known secrets in the input string with [REDACTED]
+// Secrets returns a copy of the secret values r masks.
+func (r *Redactor) Secrets() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]string(nil), r.secrets...)
+}
+
+// Redact replaces all known secrets and pattern matches in the input string
+// with [REDACTED]
 func (r *Redactor) Redact(input string) string {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	if len(r.secrets) == 0 {
+	if len(r.secrets) == 0 && len(r.patterns) == 0 {
 		return input
 	}
 
@@ -47,6 +71,9 @@ func (r *Redactor) Redact(input string) string {
 			result = strings.ReplaceAll(result, secret, "[REDACTED]")
 		}
 	}
+	for _, p := range r.patterns {
+		result = p.ReplaceAllString(result, "[REDACTED]")
+	}
 	return result
 }
 
@@ -0,0 +1,136 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+
+	"github.com/mackeh/AegisClaw/internal/audit"
+	"github.com/mackeh/AegisClaw/internal/config"
+)
+
+type auditDetailsKey struct{}
+
+// audited records an audit entry for every request to a state-changing
+// endpoint once the handler has responded: the actor (from the API key),
+// the action, a decision derived from the response status, and the source
+// IP. It wraps the auth guard, so requests refused for bad credentials or
+// insufficient role are recorded as denials.
+func (s *Server) audited(action string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		details := map[string]any{}
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		h(rec, r.WithContext(context.WithValue(r.Context(), auditDetailsKey{}, details)))
+
+		details["method"] = r.Method
+		details["path"] = r.URL.Path // never the query: it may carry api_key
+		details["status"] = rec.status
+		details["source_ip"] = sourceIP(r)
+		if err := s.logAction(action, auditDecision(rec.status), apiActor(s.Auth, r), details); err != nil {
+			fmt.Printf("⚠️  Failed to audit %s: %v\n", action, err)
+		}
+	}
+}
+
+// logAction appends one entry to the audit log, for API requests and
+// server-side alerts alike. The logger is opened per entry so the current
+// audit.sinks apply; audit.Logger re-reads the chain head under a file lock
+// before each append, so entries stay chained with those of running skills.
+func (s *Server) logAction(action, decision string, actor audit.Actor, details map[string]any) error {
+	cfgDir, err := config.DefaultConfigDir()
+	if err != nil {
+		return err
+	}
+	var sinks []config.AuditSinkConfig
+	if cfg, err := s.loadConfig(); err == nil {
+		sinks = cfg.Audit.Sinks
+	}
+	logger, err := audit.NewLoggerWithSinks(filepath.Join(cfgDir, "audit", "audit.log"), sinks)
+	if err != nil {
+		return err
+	}
+	defer logger.Close()
+	return logger.LogAs(action, nil, decision, actor, details)
+}
+
+// noteAudit adds a detail, such as the skill acted on, to the audit entry
+// recorded for r. It is a no-op for requests that are not audited.
+func noteAudit(r *http.Request, key string, value any) {
+	if d, ok := r.Context().Value(auditDetailsKey{}).(map[string]any); ok {
+		d[key] = value
+	}
+}
+
+// auditDecision maps a response status to an audit decision.
+func auditDecision(status int) string {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return "deny"
+	case status >= 400:
+		return "error"
+	default:
+		return "allow"
+	}
+}
+
+// apiActor identifies who made r as an API-key actor: the key's name (its
+// role if the key is unnamed), "anonymous" when auth is on but the key is
+// missing or wrong, and no ID on a server without auth. Rendered, these are
+// "api:<name>", "api:anonymous" and "api".
+func apiActor(auth AuthConfig, r *http.Request) audit.Actor {
+	if !auth.Enabled {
+		return audit.APIKeyActor("")
+	}
+	token := extractToken(r)
+	if token == "" {
+		return audit.APIKeyActor("anonymous")
+	}
+	k, ok := lookupKey(auth.Keys, token)
+	switch {
+	case !ok:
+		return audit.APIKeyActor("anonymous")
+	case k.Name != "":
+		return audit.APIKeyActor(k.Name)
+	default:
+		return audit.APIKeyActor(string(k.Role))
+	}
+}
+
+// sourceIP is the address of the direct peer. X-Forwarded-For is not
+// trusted: the server has no notion of which proxies sit in front of it.
+func sourceIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// statusRecorder captures the response status. It forwards Flush so the
+// SSE execute stream keeps working behind it.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.status = code
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Write(p []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(p)
+}
+
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,192 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mackeh/AegisClaw/internal/audit"
+	"github.com/mackeh/AegisClaw/internal/system"
+	"github.com/mackeh/AegisClaw/internal/xray"
+)
+
+var auditTestAuth = AuthConfig{
+	Enabled: true,
+	Keys: []APIKey{
+		{Name: "ops-dashboard", Token: "op-token", Role: RoleOperator},
+		{Name: "wallboard", Token: "view-token", Role: RoleViewer},
+	},
+}
+
+// apiEntries returns the audit entries recorded under HOME for action.
+func apiEntries(t *testing.T, home, action string) []audit.Entry {
+	t.Helper()
+	entries, err := audit.Search(filepath.Join(home, ".aegisclaw", "audit", "audit.log"), audit.Query{Action: action})
+	if err != nil {
+		t.Fatalf("search audit log: %v", err)
+	}
+	return entries
+}
+
+func TestAudited_Lockdown(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Cleanup(system.Unlock)
+
+	s := &Server{Hub: NewHub(), Auth: auditTestAuth}
+	h := s.audited("api.system.lockdown", AuthMiddleware(s.Auth, RoleOperator, s.handleSystemLockdown))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/system/lockdown", nil)
+	req.Header.Set("Authorization", "Bearer op-token")
+	req.RemoteAddr = "203.0.113.7:51234"
+	rec := httptest.NewRecorder()
+	h(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("lockdown status = %d", rec.Code)
+	}
+
+	entries := apiEntries(t, home, "api.system.lockdown")
+	if len(entries) != 1 {
+		t.Fatalf("want 1 lockdown entry, got %d", len(entries))
+	}
+	e := entries[0]
+	if e.Actor != "api:ops-dashboard" || e.Decision != "allow" {
+		t.Errorf("entry actor/decision = %q/%q", e.Actor, e.Decision)
+	}
+	if e.Identity == nil || *e.Identity != audit.APIKeyActor("ops-dashboard") {
+		t.Errorf("entry identity = %+v, want the API key's name", e.Identity)
+	}
+	if e.Details["source_ip"] != "203.0.113.7" || e.Details["method"] != http.MethodPost {
+		t.Errorf("entry details = %v", e.Details)
+	}
+}
+
+func TestAudited_Install(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	s := &Server{Hub: NewHub(), Auth: auditTestAuth}
+	h := s.audited("api.skill.install", AuthMiddleware(s.Auth, RoleOperator, s.handleRegistryInstall))
+	install := func(token string) int {
+		req := httptest.NewRequest(http.MethodPost, "/api/registry/install?api_key="+token, strings.NewReader(`{"name":"web-search"}`))
+		rec := httptest.NewRecorder()
+		h(rec, req)
+		return rec.Code
+	}
+
+	// No registry is configured, so the operator's install fails — but it is
+	// still attributed to the key that asked for it.
+	if code := install("op-token"); code < 400 {
+		t.Fatalf("install without a registry returned %d", code)
+	}
+	// A viewer key is refused by auth before the handler runs.
+	if code := install("view-token"); code != http.StatusForbidden {
+		t.Fatalf("viewer install status = %d, want 403", code)
+	}
+
+	entries := apiEntries(t, home, "api.skill.install")
+	if len(entries) != 2 {
+		t.Fatalf("want 2 install entries, got %d", len(entries))
+	}
+	if e := entries[0]; e.Actor != "api:ops-dashboard" || e.Decision != "error" || e.Details["skill"] != "web-search" {
+		t.Errorf("operator entry = %q %q %v", e.Actor, e.Decision, e.Details)
+	}
+	if e := entries[1]; e.Actor != "api:wallboard" || e.Decision != "deny" {
+		t.Errorf("viewer entry = %q %q", e.Actor, e.Decision)
+	}
+	for _, e := range entries {
+		if e.Details["path"] != "/api/registry/install" {
+			t.Errorf("path detail = %v; the query, with its api_key, must not be logged", e.Details["path"])
+		}
+	}
+}
+
+func TestAPIActor(t *testing.T) {
+	req := func(token string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/execute", nil)
+		if token != "" {
+			r.Header.Set("X-API-Key", token)
+		}
+		return r
+	}
+	unnamed := AuthConfig{Enabled: true, Keys: []APIKey{{Token: "t", Role: RoleAdmin}}}
+
+	tests := []struct {
+		auth  AuthConfig
+		token string
+		want  string
+	}{
+		{AuthConfig{}, "", "api"},
+		{auditTestAuth, "op-token", "api:ops-dashboard"},
+		{auditTestAuth, "wrong", "api:anonymous"},
+		{auditTestAuth, "", "api:anonymous"},
+		{unnamed, "t", "api:admin"},
+	}
+	for _, tt := range tests {
+		got := apiActor(tt.auth, req(tt.token))
+		if got.Kind != audit.ActorAPIKey || got.String() != tt.want {
+			t.Errorf("apiActor(token %q) = %q, want %q", tt.token, got, tt.want)
+		}
+	}
+}
+
+// TestAudited_RunKillKeepsChain interleaves a run's long-lived logger with an
+// audited kill request, as POST /api/runs/{id}/kill does mid-run, and checks
+// the log still verifies.
+func TestAudited_RunKillKeepsChain(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("DOCKER_HOST", "unix://"+filepath.Join(home, "no-docker.sock"))
+	logPath := filepath.Join(home, ".aegisclaw", "audit", "audit.log")
+
+	run, err := audit.NewLogger(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer run.Close()
+	if err := run.Log("skill.start", nil, "allow", "skill:demo", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &Server{Hub: NewHub(), Auth: auditTestAuth}
+	h := s.audited("api.run.kill", AuthMiddleware(s.Auth, RoleOperator, s.handleRunKill))
+	req := httptest.NewRequest(http.MethodPost, "/api/runs/run-1/kill", nil)
+	req.Header.Set("Authorization", "Bearer op-token")
+	h(httptest.NewRecorder(), req)
+
+	if err := run.Log("skill.exit", nil, "allow", "skill:demo", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(apiEntries(t, home, "api.run.kill")) != 1 {
+		t.Fatal("kill request was not audited")
+	}
+	if ok, err := audit.Verify(logPath); !ok || err != nil {
+		t.Errorf("chain after API kill mid-run: ok=%v err=%v", ok, err)
+	}
+}
+
+func TestReportAnomaly_ChainsAfterOtherWriters(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	logPath := filepath.Join(home, ".aegisclaw", "audit", "audit.log")
+	s := &Server{Hub: NewHub()}
+
+	s.reportAnomaly(xray.Anomaly{ContainerID: "abc", Metric: "pids", Value: 90, Limit: 64})
+	run, err := audit.NewLogger(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	run.Log("skill.exit", nil, "allow", "skill:demo", nil)
+	run.Close()
+	s.reportAnomaly(xray.Anomaly{ContainerID: "abc", Metric: "pids", Value: 95, Limit: 64})
+
+	if n := len(apiEntries(t, home, "xray.anomaly")); n != 2 {
+		t.Fatalf("want 2 anomaly entries, got %d", n)
+	}
+	if ok, err := audit.Verify(logPath); !ok || err != nil {
+		t.Errorf("chain after anomaly: ok=%v err=%v", ok, err)
+	}
+}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"crypto/subtle"
 	"net/http"
 	"strings"
@@ -53,10 +54,19 @@ func AuthMiddleware(cfg AuthConfig, requiredRole Role, next http.HandlerFunc) ht
 			return
 		}
 
-		next(w, r)
+		next(w, r.WithContext(context.WithValue(r.Context(), roleKey{}, role)))
 	}
 }
 
+type roleKey struct{}
+
+// RoleFromContext returns the role AuthMiddleware authenticated the request
+// as. ok is false when the request did not pass through enabled auth.
+func RoleFromContext(ctx context.Context) (role Role, ok bool) {
+	role, ok = ctx.Value(roleKey{}).(Role)
+	return role, ok
+}
+
 func extractToken(r *http.Request) string {
 	// Check Authorization header: "Bearer <token>"
 	auth := r.Header.Get("Authorization")
@@ -78,12 +88,18 @@ func extractToken(r *http.Request) string {
 }
 
 func authenticateToken(keys []APIKey, token string) (Role, bool) {
+	k, ok := lookupKey(keys, token)
+	return k.Role, ok
+}
+
+// lookupKey returns the API key whose token matches.
+func lookupKey(keys []APIKey, token string) (APIKey, bool) {
 	for _, k := range keys {
 		if subtle.ConstantTimeCompare([]byte(k.Token), []byte(token)) == 1 {
-			return k.Role, true
+			return k, true
 		}
 	}
-	return "", false
+	return APIKey{}, false
 }
 
 // hasPermission checks if the given role meets the required role level.
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/mackeh/AegisClaw/internal/config"
+)
+
+var (
+	defaultCORSMethods = []string{http.MethodGet, http.MethodPost}
+	defaultCORSHeaders = []string{"Authorization", "Content-Type", "X-API-Key"}
+)
+
+// CORSMiddleware applies cfg to every request. Requests from origins not in
+// cfg.AllowedOrigins get no CORS headers, so browsers on other origins cannot
+// read responses; their preflights are refused before reaching auth. Same-
+// origin and non-browser requests (no Origin header) pass through untouched.
+func CORSMiddleware(cfg config.CORSConfig, next http.Handler) http.Handler {
+	methods := cfg.AllowedMethods
+	if len(methods) == 0 {
+		methods = defaultCORSMethods
+	}
+	headers := cfg.AllowedHeaders
+	if len(headers) == 0 {
+		headers = defaultCORSHeaders
+	}
+	allowMethods := strings.Join(methods, ", ")
+	allowHeaders := strings.Join(headers, ", ")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Origin")
+		if !originAllowed(cfg.AllowedOrigins, origin) {
+			if preflight {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		if preflight {
+			w.Header().Set("Access-Control-Allow-Methods", allowMethods)
+			w.Header().Set("Access-Control-Allow-Headers", allowHeaders)
+			w.Header().Set("Access-Control-Max-Age", "600")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// originAllowed reports whether origin matches an allowed entry exactly
+// (case-insensitively) or the list contains "*".
+func originAllowed(allowed []string, origin string) bool {
+	for _, a := range allowed {
+		a = strings.TrimRight(strings.TrimSpace(a), "/")
+		if a == "*" || strings.EqualFold(a, origin) {
+			return true
+		}
+	}
+	return false
+}
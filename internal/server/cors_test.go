@@ -0,0 +1,75 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mackeh/AegisClaw/internal/config"
+)
+
+func corsTestHandler(cfg config.CORSConfig) http.Handler {
+	return CORSMiddleware(cfg, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func TestCORS_DefaultDeniesCrossOrigin(t *testing.T) {
+	h := corsTestHandler(config.CORSConfig{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/skills", nil)
+	req.Header.Set("Origin", "https://evil.example")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("disallowed origin got Access-Control-Allow-Origin %q", got)
+	}
+
+	pre := httptest.NewRequest(http.MethodOptions, "/api/skills", nil)
+	pre.Header.Set("Origin", "https://evil.example")
+	pre.Header.Set("Access-Control-Request-Method", "POST")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, pre)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("disallowed preflight = %d, want 403", w.Code)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Methods"); got != "" {
+		t.Errorf("disallowed preflight got Access-Control-Allow-Methods %q", got)
+	}
+}
+
+func TestCORS_AllowedOrigin(t *testing.T) {
+	h := corsTestHandler(config.CORSConfig{AllowedOrigins: []string{"https://dash.example/"}})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/skills", nil)
+	req.Header.Set("Origin", "https://dash.example")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://dash.example" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want the request origin", got)
+	}
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200", w.Code)
+	}
+
+	pre := httptest.NewRequest(http.MethodOptions, "/execute", nil)
+	pre.Header.Set("Origin", "https://dash.example")
+	pre.Header.Set("Access-Control-Request-Method", "POST")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, pre)
+	if w.Code != http.StatusNoContent {
+		t.Errorf("preflight = %d, want 204", w.Code)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Headers"); got != "Authorization, Content-Type, X-API-Key" {
+		t.Errorf("Access-Control-Allow-Headers = %q", got)
+	}
+}
+
+func TestCORS_NoOriginPassesThrough(t *testing.T) {
+	h := corsTestHandler(config.CORSConfig{})
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if w.Code != http.StatusOK || w.Header().Get("Vary") != "" {
+		t.Errorf("same-origin request altered: code=%d headers=%v", w.Code, w.Header())
+	}
+}
@@ -0,0 +1,32 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/mackeh/AegisClaw/internal/agent"
+)
+
+func TestExecuteStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{agent.ErrPolicyDenied, http.StatusForbidden},
+		{agent.ErrUserDenied, http.StatusForbidden},
+		{agent.ErrApprovalUnavailable, http.StatusForbidden},
+		{agent.ErrLockedDown, http.StatusServiceUnavailable},
+		{agent.ErrAtCapacity, http.StatusTooManyRequests},
+		{fmt.Errorf("%w: run-1", agent.ErrRunKilled), http.StatusConflict},
+		{&agent.OutputBlockedError{Skill: "echoer"}, http.StatusForbidden},
+		{fmt.Errorf("%w: docker down", agent.ErrExecutionFailed), http.StatusInternalServerError},
+		{errors.New("other"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		if got := executeStatus(tt.err); got != tt.want {
+			t.Errorf("executeStatus(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
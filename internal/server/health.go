@@ -0,0 +1,122 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/docker/docker/client"
+	"github.com/mackeh/AegisClaw/internal/config"
+)
+
+// readinessTimeout bounds each dependency check so a hung Docker daemon
+// fails the probe instead of stalling it.
+const readinessTimeout = 2 * time.Second
+
+// readinessCheck is one dependency /readyz verifies.
+type readinessCheck struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+// DependencyStatus is the outcome of one readiness check.
+type DependencyStatus struct {
+	Name   string `json:"name"`
+	Status string `json:"status"` // "ok" or "fail"
+	Error  string `json:"error,omitempty"`
+}
+
+// Readiness is the /readyz response body.
+type Readiness struct {
+	Status string             `json:"status"` // "ready" or "not_ready"
+	Checks []DependencyStatus `json:"checks"`
+}
+
+// handleLivez reports that the process is up and serving. It checks no
+// dependencies, so a restart is never triggered by, say, Docker being down.
+func (s *Server) handleLivez(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"status":"alive"}`))
+}
+
+// handleReadyz runs the dependency checks and answers 200 only if all pass,
+// otherwise 503, with per-dependency status in the body either way.
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	checks := s.readyChecks
+	if checks == nil {
+		checks = s.defaultReadinessChecks()
+	}
+	resp := runReadiness(r.Context(), checks)
+
+	w.Header().Set("Content-Type", "application/json")
+	if resp.Status != "ready" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func runReadiness(ctx context.Context, checks []readinessCheck) Readiness {
+	resp := Readiness{Status: "ready", Checks: make([]DependencyStatus, 0, len(checks))}
+	for _, c := range checks {
+		cctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+		err := c.check(cctx)
+		cancel()
+		st := DependencyStatus{Name: c.name, Status: "ok"}
+		if err != nil {
+			st.Status, st.Error = "fail", err.Error()
+			resp.Status = "not_ready"
+		}
+		resp.Checks = append(resp.Checks, st)
+	}
+	return resp
+}
+
+// defaultReadinessChecks verifies what skill execution needs: a reachable
+// Docker daemon, a loadable config, and a writable audit log.
+func (s *Server) defaultReadinessChecks() []readinessCheck {
+	return []readinessCheck{
+		{name: "docker", check: checkDocker},
+		{name: "config", check: func(context.Context) error {
+			cfg, err := s.loadConfig()
+			if err != nil {
+				return err
+			}
+			return cfg.Validate()
+		}},
+		{name: "audit", check: checkAuditWritable},
+	}
+}
+
+func checkDocker(ctx context.Context) error {
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+	if _, err := cli.Ping(ctx); err != nil {
+		return fmt.Errorf("daemon unreachable: %w", err)
+	}
+	return nil
+}
+
+// checkAuditWritable opens the audit log for appending, as audit.NewLogger
+// does, without writing an entry.
+func checkAuditWritable(context.Context) error {
+	cfgDir, err := config.DefaultConfigDir()
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(cfgDir, "audit", "audit.log")
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func readyz(t *testing.T, s *Server) (int, Readiness) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	s.handleReadyz(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	var body Readiness
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	return w.Code, body
+}
+
+func okCheck(name string) readinessCheck {
+	return readinessCheck{name: name, check: func(context.Context) error { return nil }}
+}
+
+func TestReadyz_AllPass(t *testing.T) {
+	s := NewServer(0)
+	s.readyChecks = []readinessCheck{okCheck("docker"), okCheck("config"), okCheck("audit")}
+
+	code, body := readyz(t, s)
+	if code != http.StatusOK || body.Status != "ready" || len(body.Checks) != 3 {
+		t.Fatalf("code=%d body=%+v", code, body)
+	}
+}
+
+func TestReadyz_FailingDependency(t *testing.T) {
+	s := NewServer(0)
+	s.readyChecks = []readinessCheck{
+		okCheck("config"),
+		{name: "docker", check: func(context.Context) error { return errors.New("daemon unreachable") }},
+	}
+
+	code, body := readyz(t, s)
+	if code != http.StatusServiceUnavailable || body.Status != "not_ready" {
+		t.Fatalf("code=%d status=%q, want 503 not_ready", code, body.Status)
+	}
+	if c := body.Checks[0]; c.Status != "ok" {
+		t.Errorf("config check = %+v", c)
+	}
+	if c := body.Checks[1]; c.Name != "docker" || c.Status != "fail" || c.Error != "daemon unreachable" {
+		t.Errorf("docker check = %+v", c)
+	}
+}
+
+func TestReadyz_AuditNotWritable(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	// A file where the audit directory belongs cannot be written through,
+	// even by root.
+	if err := os.MkdirAll(filepath.Join(home, ".aegisclaw"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".aegisclaw", "audit"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewServer(0)
+	s.readyChecks = []readinessCheck{okCheck("docker"), {name: "audit", check: checkAuditWritable}}
+	code, body := readyz(t, s)
+	if code != http.StatusServiceUnavailable || body.Checks[1].Status != "fail" {
+		t.Errorf("code=%d body=%+v, want audit failure", code, body)
+	}
+
+	os.Remove(filepath.Join(home, ".aegisclaw", "audit"))
+	if err := checkAuditWritable(context.Background()); err != nil {
+		t.Errorf("writable audit dir failed: %v", err)
+	}
+}
+
+func TestLivez(t *testing.T) {
+	s := NewServer(0)
+	// Dependencies do not affect liveness.
+	s.readyChecks = []readinessCheck{{name: "docker", check: func(context.Context) error { return errors.New("down") }}}
+	w := httptest.NewRecorder()
+	s.handleLivez(w, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	if w.Code != http.StatusOK || w.Body.String() != `{"status":"alive"}` {
+		t.Errorf("livez = %d %q", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,23 @@
+package server
+
+import "testing"
+
+func TestRunIDFromKillPath(t *testing.T) {
+	tests := []struct {
+		path string
+		id   string
+		ok   bool
+	}{
+		{"/api/runs/20260101T000000-abcd/kill", "20260101T000000-abcd", true},
+		{"/api/runs//kill", "", false},
+		{"/api/runs/abc", "", false},
+		{"/api/runs/a/b/kill", "", false},
+		{"/api/other/abc/kill", "", false},
+	}
+	for _, tt := range tests {
+		id, ok := runIDFromKillPath(tt.path)
+		if id != tt.id || ok != tt.ok {
+			t.Errorf("runIDFromKillPath(%q) = %q, %v; want %q, %v", tt.path, id, ok, tt.id, tt.ok)
+		}
+	}
+}
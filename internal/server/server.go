@@ -1,24 +1,28 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/mackeh/AegisClaw/internal/agent"
 	"github.com/mackeh/AegisClaw/internal/audit"
 	"github.com/mackeh/AegisClaw/internal/compliance"
 	"github.com/mackeh/AegisClaw/internal/config"
+	"github.com/mackeh/AegisClaw/internal/guardrails"
 	"github.com/mackeh/AegisClaw/internal/harness"
 	"github.com/mackeh/AegisClaw/internal/harness/adapters"
 	"github.com/mackeh/AegisClaw/internal/lineage"
 	"github.com/mackeh/AegisClaw/internal/openclaw"
 	"github.com/mackeh/AegisClaw/internal/sandbox"
-	"github.com/mackeh/AegisClaw/internal/server/ui"
+	"github.com/mackeh/AegisClaw/internal/secrets"
 	"github.com/mackeh/AegisClaw/internal/skill"
 	"github.com/mackeh/AegisClaw/internal/system"
 	"github.com/mackeh/AegisClaw/internal/xray"
@@ -40,6 +44,7 @@ type InstallRequest struct {
 // Response represents the result of a skill execution
 type Response struct {
 	ExitCode int    `json:"exit_code"`
+	Reason   string `json:"reason,omitempty"`
 	Stdout   string `json:"stdout"`
 	Stderr   string `json:"stderr"`
 	Error    string `json:"error,omitempty"`
@@ -55,6 +60,14 @@ type Server struct {
 	Insecure bool
 	// Auth holds the API authentication config, loaded by Start.
 	Auth AuthConfig
+	// CORS controls cross-origin access, loaded by Start from server.cors.
+	CORS config.CORSConfig
+	// Config hot-reloads config.yaml while the server runs. Nil when no
+	// config file exists; handlers then fall back to config.LoadDefault.
+	Config *config.Watcher
+
+	readyChecks []readinessCheck // nil means defaultReadinessChecks; set by tests
+	anchorSink  audit.AnchorSink // from audit.anchor; nil when not configured
 }
 
 func NewServer(port int) *Server {
@@ -67,23 +80,35 @@ func (s *Server) Start() error {
 		return err
 	}
 	s.Auth = auth
+	s.Hub.SetAuth(auth)
 
 	if err := validateBindAddress(s.Host, auth.configured(), s.Insecure); err != nil {
 		return err
 	}
 
+	s.startConfigWatcher()
+	if cfg, err := s.loadConfig(); err == nil {
+		s.CORS = cfg.Server.CORS
+		s.Hub.SetAllowedOrigins(s.CORS.AllowedOrigins)
+		s.startXrayWatch(cfg)
+		s.startAnchoring(cfg)
+	}
+
 	// guard wraps a handler with API-token authentication and RBAC. When auth
 	// is not configured it is a pass-through, preserving local-only behaviour.
 	guard := func(role Role, h http.HandlerFunc) http.HandlerFunc {
 		return AuthMiddleware(s.Auth, role, h)
 	}
 
-	// UI shell and health probe stay unauthenticated.
-	http.HandleFunc("/", s.handleIndex)
+	// Dashboard assets and health probes stay unauthenticated. /health
+	// predates /livez and is kept for existing monitors.
+	http.HandleFunc("/", s.handleStatic)
 	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("OK"))
 	})
+	http.HandleFunc("/livez", s.handleLivez)
+	http.HandleFunc("/readyz", s.handleReadyz)
 
 	// Read-only endpoints — viewer and above.
 	http.HandleFunc("/api/skills", guard(RoleViewer, s.handleListSkills))
@@ -100,14 +125,16 @@ func (s *Server) Start() error {
 	http.HandleFunc("/api/lineage", guard(RoleViewer, s.handleLineage))
 	http.HandleFunc("/api/ws", guard(RoleViewer, s.Hub.ServeWS))
 
-	// Action endpoints — operator and above.
-	http.HandleFunc("/api/registry/install", guard(RoleOperator, s.handleRegistryInstall))
-	http.HandleFunc("/api/execute/stream", guard(RoleOperator, s.handleExecuteStream))
-	http.HandleFunc("/api/system/lockdown", guard(RoleOperator, s.handleSystemLockdown))
-	http.HandleFunc("/execute", guard(RoleOperator, s.handleExecute))
+	// Action endpoints — operator and above. Every request to an endpoint
+	// that changes state is audited, including ones auth refuses.
+	http.HandleFunc("/api/registry/install", s.audited("api.skill.install", guard(RoleOperator, s.handleRegistryInstall)))
+	http.HandleFunc("/api/execute/stream", s.audited("api.skill.execute", guard(RoleOperator, s.handleExecuteStream)))
+	http.HandleFunc("/api/system/lockdown", s.audited("api.system.lockdown", guard(RoleOperator, s.handleSystemLockdown)))
+	http.HandleFunc("/execute", s.audited("api.skill.execute", guard(RoleOperator, s.handleExecute)))
+	http.HandleFunc("/api/runs/", s.audited("api.run.kill", guard(RoleOperator, s.handleRunKill)))
 
 	// Privileged endpoints — admin only.
-	http.HandleFunc("/api/system/unlock", guard(RoleAdmin, s.handleSystemUnlock))
+	http.HandleFunc("/api/system/unlock", s.audited("api.system.unlock", guard(RoleAdmin, s.handleSystemUnlock)))
 
 	addr := net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
 	display := s.Host
@@ -123,29 +150,119 @@ func (s *Server) Start() error {
 	} else {
 		fmt.Println("⚠️  API authentication: disabled on a NON-LOOPBACK bind (--insecure)")
 	}
-	return http.ListenAndServe(addr, nil)
+	if len(s.CORS.AllowedOrigins) > 0 {
+		fmt.Printf("🌍 CORS: allowing origins %v\n", s.CORS.AllowedOrigins)
+	}
+	return http.ListenAndServe(addr, CORSMiddleware(s.CORS, http.DefaultServeMux))
 }
 
-func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
-	if r.URL.Path != "/" {
-		http.NotFound(w, r)
+// startConfigWatcher begins hot-reloading config.yaml. Guardrail mode,
+// egress allowlist, and registry settings are read per request via
+// loadConfig, so a reload takes effect on the next request.
+func (s *Server) startConfigWatcher() {
+	cfgDir, err := config.DefaultConfigDir()
+	if err != nil {
 		return
 	}
-	content, err := ui.Content.ReadFile("index.html")
+	w, err := config.NewWatcher(filepath.Join(cfgDir, "config.yaml"))
 	if err != nil {
-		http.Error(w, "Dashboard not found", http.StatusInternalServerError)
+		fmt.Printf("⚠️  Config hot-reload disabled: %v\n", err)
 		return
 	}
-	w.Header().Set("Content-Type", "text/html")
-	w.Write(content)
+	w.OnChange(func(_, _ *config.Config) {
+		fmt.Println("🔄 Configuration reloaded")
+	})
+	s.Config = w
+	go func() {
+		if err := w.Run(context.Background()); err != nil {
+			fmt.Printf("⚠️  Config watcher stopped: %v\n", err)
+		}
+	}()
+}
+
+// startXrayWatch alerts on containers that stay over the xray thresholds in
+// config.yaml: each anomaly is broadcast as an EventAnomaly and written to
+// the audit log, whose sinks forward it. Thresholds are read once at start.
+func (s *Server) startXrayWatch(cfg *config.Config) {
+	th, interval := xray.FromConfig(cfg.XRay)
+	if !th.Enabled() {
+		return
+	}
+	inspector, err := xray.NewInspector()
+	if err != nil {
+		fmt.Printf("⚠️  X-Ray watch disabled: %v\n", err)
+		return
+	}
+	go inspector.Watch(context.Background(), th, interval, s.reportAnomaly)
+}
+
+// reportAnomaly broadcasts an X-Ray anomaly and audits it. The entry goes
+// through logAction rather than a logger held for the server's lifetime, so
+// it chains onto whatever runs and API calls appended in the meantime.
+func (s *Server) reportAnomaly(a xray.Anomaly) {
+	s.Hub.Broadcast(WSEvent{Type: EventAnomaly, Data: a})
+	if err := s.logAction("xray.anomaly", "alert", audit.SystemActor("xray"), a.Details()); err != nil {
+		fmt.Printf("⚠️  Failed to audit X-Ray anomaly: %v\n", err)
+	}
+}
+
+// startAnchoring sends the audit chain head to the audit.anchor sinks
+// every audit.anchor.interval. The sink is kept so lockdowns anchor too.
+func (s *Server) startAnchoring(cfg *config.Config) {
+	sink, err := audit.NewAnchorSink(cfg.Audit.Anchor)
+	if err != nil {
+		fmt.Printf("⚠️  Audit anchoring disabled: %v\n", err)
+		return
+	}
+	s.anchorSink = sink
+	if sink == nil || cfg.Audit.Anchor.Interval <= 0 {
+		return
+	}
+	go func() {
+		t := time.NewTicker(cfg.Audit.Anchor.Interval)
+		defer t.Stop()
+		for range t.C {
+			s.anchor("interval")
+		}
+	}()
+}
+
+// anchor records the current audit chain head, if anchoring is configured.
+func (s *Server) anchor(reason string) {
+	if s.anchorSink == nil {
+		return
+	}
+	cfgDir, err := config.DefaultConfigDir()
+	if err != nil {
+		return
+	}
+	if _, err := audit.Anchor(filepath.Join(cfgDir, "audit", "audit.log"), s.anchorSink, reason); err != nil {
+		fmt.Printf("⚠️  Audit anchor failed: %v\n", err)
+	}
+}
+
+// loadConfig returns the live config when hot-reload is active, otherwise
+// it reads config.yaml from disk.
+func (s *Server) loadConfig() (*config.Config, error) {
+	if s.Config != nil {
+		return s.Config.Config(), nil
+	}
+	return config.LoadDefault()
+}
+
+// registryAuth resolves the registry credentials configured in
+// registry.auth_secret; nil when the registry is anonymous.
+func registryAuth(cfg *config.Config) (*skill.RegistryAuth, error) {
+	cfgDir, err := config.DefaultConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	return skill.ResolveRegistryAuth(cfg.Registry, secrets.NewManager(filepath.Join(cfgDir, "secrets")).Get)
 }
 
 func (s *Server) handleListSkills(w http.ResponseWriter, r *http.Request) {
 	cfgDir, _ := config.DefaultConfigDir()
-	skillsDir := filepath.Join(cfgDir, "skills")
-	manifests, _ := skill.ListSkills(skillsDir)
-	localManifests, _ := skill.ListSkills("skills")
-	manifests = append(manifests, localManifests...)
+	manifests := skill.LoadSkills(skill.SearchPaths(cfgDir)...)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(manifests)
@@ -271,6 +388,8 @@ func (s *Server) handleSystemLockdown(w http.ResponseWriter, r *http.Request) {
 
 	fmt.Println("🚨 EMERGENCY LOCKDOWN TRIGGERED!")
 	system.Lockdown()
+	// Pin the log as it stood when the lockdown began.
+	s.anchor("lockdown")
 
 	// Kill all containers
 	exec, err := sandbox.NewDockerExecutor()
@@ -284,6 +403,46 @@ func (s *Server) handleSystemLockdown(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(`{"status":"lockdown"}`))
 }
 
+// handleRunKill serves POST /api/runs/{id}/kill.
+func (s *Server) handleRunKill(w http.ResponseWriter, r *http.Request) {
+	id, ok := runIDFromKillPath(r.URL.Path)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	noteAudit(r, "run_id", id)
+	if err := agent.KillRun(r.Context(), id, apiActor(s.Auth, r)); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, agent.ErrRunNotFound) {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	fmt.Printf("🛑 Run %s killed via API\n", id)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "killed", "run_id": id})
+}
+
+// runIDFromKillPath extracts {id} from /api/runs/{id}/kill.
+func runIDFromKillPath(path string) (string, bool) {
+	rest, ok := strings.CutPrefix(path, "/api/runs/")
+	if !ok {
+		return "", false
+	}
+	id, ok := strings.CutSuffix(rest, "/kill")
+	if !ok || id == "" || strings.ContainsAny(id, "/\\") {
+		return "", false
+	}
+	return id, true
+}
+
 func (s *Server) handleSystemUnlock(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -323,7 +482,7 @@ func (s *Server) handleVerifyLogs(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) handleRegistrySearch(w http.ResponseWriter, r *http.Request) {
-	cfg, err := config.LoadDefault()
+	cfg, err := s.loadConfig()
 	if err != nil {
 		http.Error(w, "Failed to load config", http.StatusInternalServerError)
 		return
@@ -334,7 +493,12 @@ func (s *Server) handleRegistrySearch(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	index, err := skill.SearchRegistry(cfg.Registry.URL)
+	auth, err := registryAuth(cfg)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	index, err := skill.SearchRegistryWithAuth(cfg.Registry.URL, auth)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Registry error: %v", err), http.StatusBadGateway)
 		return
@@ -368,8 +532,9 @@ func (s *Server) handleRegistryInstall(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	noteAudit(r, "skill", req.Name)
 
-	cfg, err := config.LoadDefault()
+	cfg, err := s.loadConfig()
 	if err != nil {
 		http.Error(w, "Failed to load config", http.StatusInternalServerError)
 		return
@@ -383,7 +548,12 @@ func (s *Server) handleRegistryInstall(w http.ResponseWriter, r *http.Request) {
 	cfgDir, _ := config.DefaultConfigDir()
 	skillsDir := filepath.Join(cfgDir, "skills")
 
-	if err := skill.InstallSkill(req.Name, skillsDir, cfg.Registry.URL, cfg.Registry.TrustKeys); err != nil {
+	auth, err := registryAuth(cfg)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := skill.InstallSkillWithOptions(req.Name, skillsDir, cfg.Registry.URL, cfg.Registry.TrustKeys, skill.InstallOptions{Auth: auth}); err != nil {
 		http.Error(w, fmt.Sprintf("Install failed: %v", err), http.StatusInternalServerError)
 		return
 	}
@@ -397,7 +567,6 @@ func (s *Server) handleExecuteStream(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 
 	// Flush headers immediately
 	flusher, ok := w.(http.Flusher)
@@ -413,6 +582,9 @@ func (s *Server) handleExecuteStream(w http.ResponseWriter, r *http.Request) {
 	// Better way: accept POST with JSON body for args, but EventSource implies GET.
 	// We'll stick to basic no-args for "Whoo" demo or simple query param
 
+	noteAudit(r, "skill", skillName)
+	noteAudit(r, "command", cmdName)
+
 	if skillName == "" || cmdName == "" {
 		fmt.Fprintf(w, "event: error\ndata: Missing skill or command\n\n")
 		return
@@ -420,9 +592,8 @@ func (s *Server) handleExecuteStream(w http.ResponseWriter, r *http.Request) {
 
 	// 1. Find manifest
 	cfgDir, _ := config.DefaultConfigDir()
-	searchDirs := []string{filepath.Join(cfgDir, "skills"), "skills"}
 	var m *skill.Manifest
-	for _, dir := range searchDirs {
+	for _, dir := range skill.SearchPaths(cfgDir) {
 		manifestPath := filepath.Join(dir, skillName, "skill.yaml")
 		found, err := skill.LoadManifest(manifestPath)
 		if err == nil {
@@ -436,13 +607,19 @@ func (s *Server) handleExecuteStream(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// 2. Prepare Writers
+	// 2. Prepare Writers. The stream guard holds back enough output to catch
+	// credentials split across chunks before they reach the browser.
 	sseWriter := &SSEWriter{w: w, f: flusher}
+	guarded := guardrails.NewStreamGuard(sseWriter)
 
 	// 3. Execute
-	_, err := agent.ExecuteSkillWithStream(r.Context(), m, cmdName, []string{}, sseWriter, sseWriter)
+	_, err := agent.ExecuteSkillWithStream(r.Context(), m, cmdName, []string{}, nil, guarded, guarded)
+	_ = guarded.Flush()
 
 	if err != nil {
+		// The stream has already answered 200, so the outcome goes in the
+		// audit details instead of the status.
+		noteAudit(r, "error", err.Error())
 		fmt.Fprintf(w, "event: error\ndata: %s\n\n", err.Error())
 	} else {
 		fmt.Fprintf(w, "event: done\ndata: Execution complete\n\n")
@@ -483,18 +660,15 @@ func (s *Server) handleExecute(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	noteAudit(r, "skill", req.Skill)
+	noteAudit(r, "command", req.Command)
 
 	// 1. Find the skill manifest
 	cfgDir, _ := config.DefaultConfigDir()
 
-	// Check standard locations
-	searchDirs := []string{
-		filepath.Join(cfgDir, "skills"),
-		"skills",
-	}
-
+	// Check standard locations, in precedence order
 	var m *skill.Manifest
-	for _, dir := range searchDirs {
+	for _, dir := range skill.SearchPaths(cfgDir) {
 		manifestPath := filepath.Join(dir, req.Skill, "skill.yaml")
 		found, err := skill.LoadManifest(manifestPath)
 		if err == nil {
@@ -511,18 +685,42 @@ func (s *Server) handleExecute(w http.ResponseWriter, r *http.Request) {
 	// 2. Execute
 	result, err := agent.ExecuteSkill(r.Context(), m, req.Command, req.Args)
 	if err != nil {
-		s.sendResponse(w, http.StatusInternalServerError, Response{Error: err.Error()})
+		status := executeStatus(err)
+		if status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "5")
+		}
+		noteAudit(r, "error", err.Error())
+		s.sendResponse(w, status, Response{Error: err.Error()})
 		return
 	}
 
 	// 3. Return result
 	s.sendResponse(w, http.StatusOK, Response{
 		ExitCode: result.ExitCode,
+		Reason:   result.Reason,
 		Stdout:   result.Stdout,
 		Stderr:   result.Stderr,
 	})
 }
 
+// executeStatus maps an agent execution error to an HTTP status: refusals
+// and blocked output are 403, a killed run is 409, lockdown and a full run
+// queue are retryable, and anything else is a server error.
+func executeStatus(err error) int {
+	switch {
+	case agent.IsDenied(err), errors.Is(err, agent.ErrOutputBlocked):
+		return http.StatusForbidden
+	case errors.Is(err, agent.ErrRunKilled):
+		return http.StatusConflict
+	case errors.Is(err, agent.ErrLockedDown):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, agent.ErrAtCapacity):
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
 func (s *Server) sendResponse(w http.ResponseWriter, status int, resp Response) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
@@ -535,7 +733,7 @@ func (s *Server) handleCompliance(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cfg, err := config.LoadDefault()
+	cfg, err := s.loadConfig()
 	if err != nil {
 		http.Error(w, fmt.Sprintf("config: %v", err), http.StatusInternalServerError)
 		return
@@ -560,7 +758,7 @@ func (s *Server) handleComplianceReport(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	cfg, err := config.LoadDefault()
+	cfg, err := s.loadConfig()
 	if err != nil {
 		http.Error(w, fmt.Sprintf("config: %v", err), http.StatusInternalServerError)
 		return
@@ -0,0 +1,148 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/mackeh/AegisClaw/internal/server/ui"
+)
+
+// staticAsset is one embedded dashboard file, prepared once at startup.
+type staticAsset struct {
+	contentType  string
+	cacheControl string
+	etag         string
+	body         []byte
+	gzipped      []byte // nil when the asset is not worth compressing
+}
+
+// staticAssets maps request paths ("/" and "/dashboard.js") to the embedded
+// dashboard files.
+var staticAssets = mustLoadStaticAssets(ui.Content)
+
+// mustLoadStaticAssets prepares every file in fsys. index.html is served at
+// "/" and revalidated on each load, since it names the other assets; those
+// are cacheable for an hour. Both carry an ETag so revalidation is a 304.
+func mustLoadStaticAssets(fsys fs.FS) map[string]*staticAsset {
+	assets := map[string]*staticAsset{}
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		body, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(body)
+		a := &staticAsset{
+			contentType:  mime.TypeByExtension(path.Ext(name)),
+			cacheControl: "public, max-age=3600",
+			etag:         `"` + hex.EncodeToString(sum[:8]) + `"`,
+			body:         body,
+		}
+		if a.contentType == "" {
+			a.contentType = http.DetectContentType(body)
+		}
+		if strings.HasPrefix(a.contentType, "text/") || strings.Contains(a.contentType, "javascript") {
+			a.gzipped = gzipBytes(body)
+		}
+		if name == "index.html" {
+			a.cacheControl = "no-cache"
+			assets["/"] = a
+		}
+		assets["/"+name] = a
+		return nil
+	})
+	if err != nil {
+		panic("server: loading dashboard assets: " + err.Error())
+	}
+	return assets
+}
+
+func gzipBytes(body []byte) []byte {
+	var buf bytes.Buffer
+	zw, _ := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	zw.Write(body)
+	zw.Close()
+	if buf.Len() >= len(body) {
+		return nil
+	}
+	return buf.Bytes()
+}
+
+// handleStatic serves the embedded dashboard. Text assets are gzipped for
+// clients that accept it, under their own ETag; a matching If-None-Match
+// gets 304 Not Modified.
+func (s *Server) handleStatic(w http.ResponseWriter, r *http.Request) {
+	a, ok := staticAssets[r.URL.Path]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, etag := a.body, a.etag
+	h := w.Header()
+	if a.gzipped != nil {
+		h.Set("Vary", "Accept-Encoding")
+		if acceptsGzip(r) {
+			body, etag = a.gzipped, strings.TrimSuffix(a.etag, `"`)+`-gz"`
+			h.Set("Content-Encoding", "gzip")
+		}
+	}
+	h.Set("Content-Type", a.contentType)
+	h.Set("Cache-Control", a.cacheControl)
+	h.Set("ETag", etag)
+
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	h.Set("Content-Length", strconv.Itoa(len(body)))
+	if r.Method == http.MethodHead {
+		return
+	}
+	w.Write(body)
+}
+
+// acceptsGzip reports whether r's Accept-Encoding allows gzip, honouring an
+// explicit "gzip;q=0".
+func acceptsGzip(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(coding), "gzip") {
+			continue
+		}
+		q, ok := strings.CutPrefix(strings.TrimSpace(params), "q=")
+		if !ok {
+			return true
+		}
+		weight, err := strconv.ParseFloat(q, 64)
+		return err == nil && weight > 0
+	}
+	return false
+}
+
+// etagMatches reports whether an If-None-Match header lists etag, compared
+// weakly as RFC 9110 requires for If-None-Match.
+func etagMatches(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func getStatic(t *testing.T, path string, header http.Header) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	w := httptest.NewRecorder()
+	NewServer(0).handleStatic(w, req)
+	return w
+}
+
+func TestStatic_ServesCSS(t *testing.T) {
+	w := getStatic(t, "/dashboard.css", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/css") {
+		t.Errorf("Content-Type = %q, want text/css", ct)
+	}
+	if w.Header().Get("ETag") == "" || w.Header().Get("Cache-Control") == "" {
+		t.Errorf("missing caching headers: %v", w.Header())
+	}
+	if w.Header().Get("Content-Encoding") != "" || !strings.Contains(w.Body.String(), "background-color") {
+		t.Errorf("expected plain CSS without Accept-Encoding")
+	}
+}
+
+func TestStatic_IndexRevalidates(t *testing.T) {
+	w := getStatic(t, "/", nil)
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") {
+		t.Fatalf("status = %d, Content-Type = %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	if cc := w.Header().Get("Cache-Control"); cc != "no-cache" {
+		t.Errorf("index Cache-Control = %q, want no-cache", cc)
+	}
+	if !strings.Contains(w.Body.String(), `href="/dashboard.css"`) {
+		t.Error("index does not reference the stylesheet")
+	}
+}
+
+func TestStatic_ConditionalNotModified(t *testing.T) {
+	etag := getStatic(t, "/dashboard.css", nil).Header().Get("ETag")
+
+	w := getStatic(t, "/dashboard.css", http.Header{"If-None-Match": {`"stale", ` + etag}})
+	if w.Code != http.StatusNotModified {
+		t.Fatalf("status = %d, want 304", w.Code)
+	}
+	if w.Body.Len() != 0 || w.Header().Get("ETag") != etag {
+		t.Errorf("304 should carry the ETag and no body")
+	}
+
+	if w := getStatic(t, "/dashboard.css", http.Header{"If-None-Match": {`"stale"`}}); w.Code != http.StatusOK {
+		t.Errorf("mismatched ETag: status = %d, want 200", w.Code)
+	}
+}
+
+func TestStatic_Gzip(t *testing.T) {
+	plain := getStatic(t, "/dashboard.js", nil)
+	w := getStatic(t, "/dashboard.js", http.Header{"Accept-Encoding": {"br, gzip"}})
+	if w.Header().Get("Content-Encoding") != "gzip" || w.Header().Get("Vary") != "Accept-Encoding" {
+		t.Fatalf("headers = %v", w.Header())
+	}
+	if w.Header().Get("ETag") == plain.Header().Get("ETag") {
+		t.Error("gzip and identity encodings share an ETag")
+	}
+	zr, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(zr)
+	if !bytes.Equal(body, plain.Body.Bytes()) {
+		t.Error("gzipped body differs from the asset")
+	}
+
+	if w := getStatic(t, "/dashboard.js", http.Header{"Accept-Encoding": {"gzip;q=0"}}); w.Header().Get("Content-Encoding") != "" {
+		t.Error("gzip;q=0 should disable compression")
+	}
+}
+
+func TestStatic_UnknownPath(t *testing.T) {
+	if w := getStatic(t, "/nope.js", nil); w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
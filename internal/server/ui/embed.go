@@ -2,5 +2,5 @@ package ui
 
 import "embed"
 
-//go:embed index.html
+//go:embed *.html *.css *.js
 var Content embed.FS
@@ -1,12 +1,16 @@
 // Package server WebSocket hub for real-time event streaming.
 // Clients connect to /api/ws and receive JSON events for audit entries,
-// system status changes, and skill execution updates.
+// system status changes, and skill execution updates. When API auth is
+// enabled the handshake must carry a token, and viewers receive only the
+// fields allowlisted for each event type.
 package server
 
 import (
 	"encoding/json"
 	"log"
 	"net/http"
+	"net/url"
+	"strings"
 	"sync"
 	"time"
 
@@ -32,34 +36,83 @@ type WSEvent struct {
 	Data      interface{} `json:"data"`
 }
 
+// viewerFields are the event data keys viewers may see, per event type.
+// Everything else — guardrail matches, commands, output, errors, and any
+// field added later — is operator-only until it is listed here. Events of
+// an unlisted type reach viewers without data.
+var viewerFields = map[EventType][]string{
+	EventAudit:     {"seq", "timestamp", "action", "decision", "actor"},
+	EventStatus:    {"status", "message", "clients"},
+	EventExecution: {"run_id", "skill", "status", "exit_code", "started_at", "duration_ms"},
+	EventAnomaly:   {"container_id", "container_name", "image", "metric", "value", "limit", "since", "duration_ns"},
+	EventLockdown:  {"status"},
+	EventPosture:   {"total", "max", "percentage", "grade", "categories", "profile"},
+}
+
 // Hub manages WebSocket connections and broadcasts events.
 type Hub struct {
-	mu      sync.RWMutex
-	clients map[*wsClient]struct{}
+	mu             sync.RWMutex
+	clients        map[*wsClient]struct{}
+	auth           AuthConfig
+	allowedOrigins []string
+	upgrader       websocket.Upgrader
 }
 
 type wsClient struct {
 	conn *websocket.Conn
 	send chan []byte
+	role Role
 }
 
-var upgrader = websocket.Upgrader{
-	ReadBufferSize:  1024,
-	WriteBufferSize: 1024,
-	CheckOrigin: func(r *http.Request) bool {
-		// Allow same-origin only in production; localhost for dev
-		return true
-	},
-}
-
-// NewHub creates a new WebSocket hub.
+// NewHub creates a new WebSocket hub. It accepts same-origin handshakes
+// only until SetAllowedOrigins says otherwise.
 func NewHub() *Hub {
-	return &Hub{
+	h := &Hub{
 		clients: make(map[*wsClient]struct{}),
 	}
+	h.upgrader = websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin:     h.checkOrigin,
+	}
+	return h
+}
+
+// SetAllowedOrigins lets browsers on the given origins open a WebSocket,
+// as server.cors.allowed_origins lets them call the REST endpoints.
+func (h *Hub) SetAllowedOrigins(origins []string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.allowedOrigins = origins
+}
+
+// checkOrigin refuses cross-site handshakes, which browsers make without
+// any CORS preflight: without it, any page could read the event stream
+// from a local server running without auth. Requests without an Origin
+// header are not from a browser and pass.
+func (h *Hub) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return originAllowed(h.allowedOrigins, origin)
+}
+
+// SetAuth makes ServeWS require API-key auth on the handshake, using the
+// same keys and token locations as the REST endpoints.
+func (h *Hub) SetAuth(cfg AuthConfig) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.auth = cfg
 }
 
-// Broadcast sends an event to all connected clients.
+// Broadcast sends an event to all connected clients. Viewers receive a copy
+// reduced to viewerFields.
 func (h *Hub) Broadcast(evt WSEvent) {
 	if evt.Timestamp == "" {
 		evt.Timestamp = time.Now().UTC().Format(time.RFC3339)
@@ -69,19 +122,62 @@ func (h *Hub) Broadcast(evt WSEvent) {
 	if err != nil {
 		return
 	}
+	var viewerData []byte
 
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
 	for c := range h.clients {
+		msg := data
+		if !hasPermission(c.role, RoleOperator) {
+			if viewerData == nil {
+				viewerData = viewerEvent(evt)
+			}
+			msg = viewerData
+		}
 		select {
-		case c.send <- data:
+		case c.send <- msg:
 		default:
 			// Client buffer full — drop message
 		}
 	}
 }
 
+// encodeFor encodes evt as role may see it.
+func encodeFor(role Role, evt WSEvent) []byte {
+	if !hasPermission(role, RoleOperator) {
+		return viewerEvent(evt)
+	}
+	data, _ := json.Marshal(evt)
+	return data
+}
+
+// viewerEvent encodes evt with its data reduced to the viewerFields of its
+// type. Data that is not a JSON object is withheld entirely.
+func viewerEvent(evt WSEvent) []byte {
+	evt.Data = viewerData(evt.Type, evt.Data)
+	out, _ := json.Marshal(evt)
+	return out
+}
+
+func viewerData(t EventType, data any) any {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(raw, &fields); err != nil || fields == nil {
+		return nil
+	}
+	out := map[string]any{}
+	for _, k := range viewerFields[t] {
+		if v, ok := fields[k]; ok {
+			out[k] = v
+		}
+	}
+	return out
+}
+
 // ClientCount returns the number of active connections.
 func (h *Hub) ClientCount() int {
 	h.mu.RLock()
@@ -102,9 +198,17 @@ func (h *Hub) unregister(c *wsClient) {
 	close(c.send)
 }
 
-// ServeWS handles the /api/ws endpoint.
+// ServeWS handles the /api/ws endpoint. With auth enabled the handshake is
+// rejected before upgrading unless it carries a valid token (Authorization,
+// X-API-Key, or ?api_key= for browsers, which cannot set WS headers).
 func (h *Hub) ServeWS(w http.ResponseWriter, r *http.Request) {
-	conn, err := upgrader.Upgrade(w, r, nil)
+	role, ok := h.authenticate(r)
+	if !ok {
+		http.Error(w, `{"error":"authentication required"}`, http.StatusUnauthorized)
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Printf("ws upgrade: %v", err)
 		return
@@ -113,6 +217,7 @@ func (h *Hub) ServeWS(w http.ResponseWriter, r *http.Request) {
 	c := &wsClient{
 		conn: conn,
 		send: make(chan []byte, 64),
+		role: role,
 	}
 	h.register(c)
 
@@ -127,9 +232,34 @@ func (h *Hub) ServeWS(w http.ResponseWriter, r *http.Request) {
 	go h.readPump(c)
 }
 
+// authenticate resolves the client's role. Requests already vetted by
+// AuthMiddleware carry their role in the context; with auth disabled the
+// local-only server grants full access, as the REST endpoints do.
+func (h *Hub) authenticate(r *http.Request) (Role, bool) {
+	if role, ok := RoleFromContext(r.Context()); ok {
+		return role, true
+	}
+	h.mu.RLock()
+	auth := h.auth
+	h.mu.RUnlock()
+	if !auth.Enabled {
+		return RoleAdmin, true
+	}
+	token := extractToken(r)
+	if token == "" {
+		return "", false
+	}
+	role, ok := authenticateToken(auth.Keys, token)
+	if !ok || !hasPermission(role, RoleViewer) {
+		return "", false
+	}
+	return role, true
+}
+
+// sendOne sends evt to c alone, filtered for c's role like Broadcast.
 func (h *Hub) sendOne(c *wsClient, evt WSEvent) {
-	data, err := json.Marshal(evt)
-	if err != nil {
+	data := encodeFor(c.role, evt)
+	if data == nil {
 		return
 	}
 	select {
@@ -99,3 +99,179 @@ func TestHub_BroadcastToClient(t *testing.T) {
 		t.Errorf("expected audit event, got %s", evt.Type)
 	}
 }
+
+func TestHub_RejectsUnauthenticatedUpgrade(t *testing.T) {
+	h := NewHub()
+	h.SetAuth(AuthConfig{Enabled: true, Keys: []APIKey{{Name: "v", Token: "viewer-token", Role: RoleViewer}}})
+
+	srv := httptest.NewServer(http.HandlerFunc(h.ServeWS))
+	defer srv.Close()
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http")
+
+	_, resp, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err == nil {
+		t.Fatal("expected unauthenticated handshake to fail")
+	}
+	if resp == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %v", resp)
+	}
+
+	_, resp, err = websocket.DefaultDialer.Dial(wsURL+"?api_key=wrong", nil)
+	if err == nil || resp == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401 for invalid token, got err=%v", err)
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL+"?api_key=viewer-token", nil)
+	if err != nil {
+		t.Fatalf("valid token rejected: %v", err)
+	}
+	conn.Close()
+}
+
+func TestHub_ViewerDoesNotSeeOperatorFields(t *testing.T) {
+	h := NewHub()
+	h.SetAuth(AuthConfig{Enabled: true, Keys: []APIKey{
+		{Name: "v", Token: "viewer-token", Role: RoleViewer},
+		{Name: "o", Token: "operator-token", Role: RoleOperator},
+	}})
+
+	srv := httptest.NewServer(http.HandlerFunc(h.ServeWS))
+	defer srv.Close()
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http")
+
+	dial := func(token string) *websocket.Conn {
+		hdr := http.Header{}
+		hdr.Set("Authorization", "Bearer "+token)
+		conn, _, err := websocket.DefaultDialer.Dial(wsURL, hdr)
+		if err != nil {
+			t.Fatalf("dial: %v", err)
+		}
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		conn.ReadMessage() // welcome
+		return conn
+	}
+	viewer := dial("viewer-token")
+	defer viewer.Close()
+	operator := dial("operator-token")
+	defer operator.Close()
+	time.Sleep(50 * time.Millisecond)
+
+	h.Broadcast(WSEvent{Type: EventAudit, Data: map[string]any{
+		"action":     "guardrail.violation",
+		"violations": []string{"critical:secret_leak"},
+		"details":    map[string]any{"match": "sk-live-abc"},
+	}})
+
+	read := func(conn *websocket.Conn) map[string]any {
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		var evt struct {
+			Data map[string]any `json:"data"`
+		}
+		if err := json.Unmarshal(msg, &evt); err != nil {
+			t.Fatal(err)
+		}
+		return evt.Data
+	}
+
+	vd := read(viewer)
+	if vd["action"] != "guardrail.violation" {
+		t.Errorf("viewer lost non-sensitive field: %v", vd)
+	}
+	if _, ok := vd["violations"]; ok {
+		t.Errorf("viewer received violations: %v", vd)
+	}
+	if _, ok := vd["details"]; ok {
+		t.Errorf("viewer received details: %v", vd)
+	}
+
+	od := read(operator)
+	if _, ok := od["violations"]; !ok {
+		t.Errorf("operator should receive violations: %v", od)
+	}
+	if _, ok := od["details"]; !ok {
+		t.Errorf("operator should receive details: %v", od)
+	}
+}
+
+func TestViewerEvent_StripsUnlistedFields(t *testing.T) {
+	decode := func(msg []byte) map[string]any {
+		var evt struct {
+			Data map[string]any `json:"data"`
+		}
+		if err := json.Unmarshal(msg, &evt); err != nil {
+			t.Fatal(err)
+		}
+		return evt.Data
+	}
+
+	evt := WSEvent{Type: EventExecution, Data: map[string]any{
+		"run_id": "r1", "skill": "demo", "exit_code": 1,
+		"stdout": "AKIA...", "output": "token=abc", "reason": "oom", "error": "dial 10.0.0.5",
+	}}
+	vd := decode(viewerEvent(evt))
+	for _, k := range []string{"stdout", "output", "reason", "error"} {
+		if _, ok := vd[k]; ok {
+			t.Errorf("viewer received unlisted field %q: %v", k, vd)
+		}
+	}
+	if vd["run_id"] != "r1" || vd["skill"] != "demo" {
+		t.Errorf("viewer lost allowlisted fields: %v", vd)
+	}
+
+	if vd := decode(viewerEvent(WSEvent{Type: "custom", Data: map[string]any{"anything": 1}})); len(vd) != 0 {
+		t.Errorf("unknown event type leaked data to viewer: %v", vd)
+	}
+
+	// sendOne goes through the same filter.
+	c := &wsClient{send: make(chan []byte, 1), role: RoleViewer}
+	NewHub().sendOne(c, evt)
+	if vd := decode(<-c.send); vd["stdout"] != nil || vd["run_id"] != "r1" {
+		t.Errorf("sendOne to viewer = %v, want filtered data", vd)
+	}
+	c.role = RoleOperator
+	NewHub().sendOne(c, evt)
+	if od := decode(<-c.send); od["stdout"] == nil {
+		t.Errorf("sendOne to operator dropped fields: %v", od)
+	}
+}
+
+func TestHub_RefusesCrossOriginUpgrade(t *testing.T) {
+	h := NewHub()
+	srv := httptest.NewServer(http.HandlerFunc(h.ServeWS))
+	defer srv.Close()
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http")
+
+	dial := func(origin string) (*http.Response, error) {
+		conn, resp, err := websocket.DefaultDialer.Dial(wsURL, http.Header{"Origin": {origin}})
+		if err == nil {
+			conn.Close()
+		}
+		return resp, err
+	}
+
+	// Auth is off, as in the default local mode: the origin check is all
+	// that keeps another site's page off the event stream.
+	resp, err := dial("http://evil.example")
+	if err == nil {
+		t.Fatal("cross-origin handshake was accepted")
+	}
+	if resp == nil || resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expected 403, got %v", resp)
+	}
+
+	if _, err := dial(srv.URL); err != nil {
+		t.Errorf("same-origin handshake refused: %v", err)
+	}
+
+	h.SetAllowedOrigins([]string{"https://dashboard.example"})
+	if _, err := dial("https://dashboard.example"); err != nil {
+		t.Errorf("allowed origin refused: %v", err)
+	}
+	if _, err := dial("http://evil.example"); err == nil {
+		t.Error("origin outside server.cors.allowed_origins was accepted")
+	}
+}
@@ -0,0 +1,133 @@
+package simulate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mackeh/AegisClaw/internal/skill"
+)
+
+// BatchResult is the simulation of one skill in a directory. Err is set,
+// and Report nil, when the manifest could not be loaded.
+type BatchResult struct {
+	Path   string  `json:"path"`
+	Report *Report `json:"report,omitempty"`
+	Err    error   `json:"-"`
+}
+
+// Name identifies the result: the skill name, or the manifest's directory
+// when it did not load.
+func (b BatchResult) Name() string {
+	if b.Report != nil {
+		return b.Report.SkillName
+	}
+	return filepath.Base(filepath.Dir(b.Path))
+}
+
+// RunDir simulates every skill in dir, laid out as the skills directory is
+// (<dir>/<skill>/skill.yaml), in directory order. Unlike skill.ListSkills it
+// keeps manifests that fail to load, as results with Err set, so a review
+// of the whole set does not silently skip them.
+func RunDir(ctx context.Context, dir string, opts Options) ([]BatchResult, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read skills directory: %w", err)
+	}
+	var results []BatchResult
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, e.Name(), "skill.yaml")
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		res := BatchResult{Path: path}
+		m, err := skill.LoadManifest(path)
+		if err == nil {
+			res.Report, err = RunWithOptions(ctx, m, opts)
+		}
+		res.Err = err
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// Threshold is a --fail-on gate: a skill fails it when its risk reaches
+// Risk or its policy decision is at least as strict as Decision. Empty
+// fields are not checked.
+type Threshold struct {
+	Risk     string
+	Decision string
+}
+
+var riskRank = map[string]int{"low": 0, "medium": 1, "high": 2, "critical": 3}
+
+// decisionRank orders policy decisions by strictness. A decision that could
+// not be evaluated ranks strictest, so a broken policy fails the gate.
+func decisionRank(d string) int {
+	switch {
+	case strings.HasPrefix(d, "allow"):
+		return 0
+	case d == "require_approval":
+		return 1
+	default:
+		return 2
+	}
+}
+
+// ParseThreshold parses a --fail-on value: a comma-separated list of a risk
+// level (low, medium, high, critical) and/or a decision (require_approval,
+// deny), e.g. "high" or "critical,deny".
+func ParseThreshold(s string) (Threshold, error) {
+	var t Threshold
+	for _, part := range strings.Split(s, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		_, isRisk := riskRank[part]
+		switch {
+		case part == "":
+		case isRisk:
+			t.Risk = part
+		case part == "require_approval" || part == "deny":
+			t.Decision = part
+		default:
+			return Threshold{}, fmt.Errorf("invalid --fail-on %q (want a risk level low, medium, high or critical, and/or a decision require_approval or deny)", part)
+		}
+	}
+	if t == (Threshold{}) {
+		return t, fmt.Errorf("--fail-on needs a risk level or decision")
+	}
+	return t, nil
+}
+
+// Exceeded reports whether r fails the gate, and why.
+func (t Threshold) Exceeded(r *Report) (bool, string) {
+	if t.Risk != "" {
+		if rank, ok := riskRank[r.RiskLevel]; !ok || rank >= riskRank[t.Risk] {
+			return true, fmt.Sprintf("risk %s", r.RiskLevel)
+		}
+	}
+	if t.Decision != "" && decisionRank(r.PolicyDecision) >= decisionRank(t.Decision) {
+		return true, fmt.Sprintf("policy %s", r.PolicyDecision)
+	}
+	return false, ""
+}
+
+// Failures returns the results that fail t, with the reason for each. A
+// manifest that did not load always fails.
+func Failures(results []BatchResult, t Threshold) map[string]string {
+	failures := map[string]string{}
+	for _, res := range results {
+		if res.Err != nil {
+			failures[res.Path] = "manifest did not load"
+			continue
+		}
+		if bad, why := t.Exceeded(res.Report); bad {
+			failures[res.Path] = why
+		}
+	}
+	return failures
+}
@@ -0,0 +1,137 @@
+package simulate
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeSkills lays out a skills directory: one low-risk skill, one
+// critical-risk skill, and one whose manifest does not load.
+func writeSkills(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	manifests := map[string]string{
+		"reader": "name: reader\nversion: 1.0.0\nimage: alpine:3.20\nscopes:\n  - files.read:/tmp\n",
+		"shell":  "name: shell\nversion: 1.0.0\nimage: alpine:3.20\nscopes:\n  - shell.exec\n",
+		"broken": "name: broken\nversion: 1.0.0\n",
+	}
+	for name, body := range manifests {
+		if err := os.MkdirAll(filepath.Join(dir, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name, "skill.yaml"), []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Not a skill: no manifest.
+	if err := os.MkdirAll(filepath.Join(dir, "notes"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestRunDir(t *testing.T) {
+	results, err := RunDir(context.Background(), writeSkills(t), Options{})
+	if err != nil {
+		t.Fatalf("RunDir: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3: %+v", len(results), results)
+	}
+	risks := map[string]string{}
+	for _, res := range results {
+		if res.Err != nil {
+			risks[res.Name()] = "error"
+			continue
+		}
+		risks[res.Name()] = res.Report.RiskLevel
+	}
+	want := map[string]string{"reader": "low", "shell": "critical", "broken": "error"}
+	for name, risk := range want {
+		if risks[name] != risk {
+			t.Errorf("%s: risk = %q, want %q", name, risks[name], risk)
+		}
+	}
+}
+
+func TestRunDir_Missing(t *testing.T) {
+	if _, err := RunDir(context.Background(), filepath.Join(t.TempDir(), "nope"), Options{}); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
+
+func TestParseThreshold(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Threshold
+		wantErr bool
+	}{
+		{"high", Threshold{Risk: "high"}, false},
+		{"Critical, deny", Threshold{Risk: "critical", Decision: "deny"}, false},
+		{"require_approval", Threshold{Decision: "require_approval"}, false},
+		{"", Threshold{}, true},
+		{"severe", Threshold{}, true},
+		{"allow", Threshold{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseThreshold(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseThreshold(%q) err = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseThreshold(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestThreshold_Exceeded(t *testing.T) {
+	tests := []struct {
+		gate     Threshold
+		risk     string
+		decision string
+		want     bool
+	}{
+		{Threshold{Risk: "high"}, "medium", "allow", false},
+		{Threshold{Risk: "high"}, "high", "allow", true},
+		{Threshold{Risk: "high"}, "critical", "allow", true},
+		{Threshold{Decision: "deny"}, "critical", "require_approval", false},
+		{Threshold{Decision: "deny"}, "low", "deny", true},
+		{Threshold{Decision: "require_approval"}, "low", "require_approval", true},
+		{Threshold{Decision: "require_approval"}, "low", "allow (no scopes)", false},
+		{Threshold{Decision: "require_approval"}, "low", "unknown (policy not loaded)", true},
+	}
+	for _, tt := range tests {
+		got, why := tt.gate.Exceeded(&Report{RiskLevel: tt.risk, PolicyDecision: tt.decision})
+		if got != tt.want {
+			t.Errorf("%+v on risk=%s decision=%s: got %v (%s), want %v", tt.gate, tt.risk, tt.decision, got, why, tt.want)
+		}
+	}
+}
+
+func TestFailures(t *testing.T) {
+	results, err := RunDir(context.Background(), writeSkills(t), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := func(failures map[string]string) map[string]bool {
+		names := map[string]bool{}
+		for _, res := range results {
+			if _, ok := failures[res.Path]; ok {
+				names[res.Name()] = true
+			}
+		}
+		return names
+	}
+
+	got := byName(Failures(results, Threshold{Risk: "critical"}))
+	if !got["shell"] || !got["broken"] || got["reader"] {
+		t.Errorf("--fail-on critical failed %v, want shell and broken", got)
+	}
+	got = byName(Failures(results, Threshold{Risk: "low"}))
+	if len(got) != 3 {
+		t.Errorf("--fail-on low failed %v, want every skill", got)
+	}
+}
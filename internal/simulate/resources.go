@@ -0,0 +1,99 @@
+package simulate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mackeh/AegisClaw/internal/sandbox"
+	"github.com/mackeh/AegisClaw/internal/skill"
+)
+
+// ResourceLimits are the effective sandbox limits a skill will run with.
+type ResourceLimits struct {
+	MemoryBytes int64   `json:"memory_bytes"`
+	CPUs        float64 `json:"cpus"`
+	PidsLimit   int64   `json:"pids_limit"`
+	Declared    bool    `json:"declared"` // false when all limits are defaults
+}
+
+// ImageFloor is the minimum memory an image family realistically needs.
+type ImageFloor struct {
+	Match       string // substring of the image reference, e.g. "node"
+	MemoryBytes int64
+}
+
+// MinMemoryBytes is the floor applied to any image not listed in ImageFloors.
+var MinMemoryBytes int64 = 32 << 20
+
+// ImageMemoryFloors lists known runtime needs; the first match wins.
+var ImageMemoryFloors = []ImageFloor{
+	{Match: "openjdk", MemoryBytes: 256 << 20},
+	{Match: "eclipse-temurin", MemoryBytes: 256 << 20},
+	{Match: "dotnet", MemoryBytes: 256 << 20},
+	{Match: "node", MemoryBytes: 128 << 20},
+	{Match: "golang", MemoryBytes: 128 << 20},
+	{Match: "python", MemoryBytes: 64 << 20},
+	{Match: "ruby", MemoryBytes: 64 << 20},
+}
+
+// memoryFloor returns the floor for an image reference.
+func memoryFloor(image string) int64 {
+	ref := strings.ToLower(image)
+	for _, f := range ImageMemoryFloors {
+		if strings.Contains(ref, f.Match) {
+			return f.MemoryBytes
+		}
+	}
+	return MinMemoryBytes
+}
+
+// analyseResources resolves the effective limits and returns any warnings.
+func analyseResources(m *skill.Manifest) (ResourceLimits, []string) {
+	limits := ResourceLimits{
+		MemoryBytes: sandbox.DefaultMemoryBytes,
+		CPUs:        float64(sandbox.DefaultNanoCPUs) / 1e9,
+		PidsLimit:   sandbox.DefaultPidsLimit,
+	}
+	var warnings []string
+
+	if m.Resources == nil {
+		warnings = append(warnings, fmt.Sprintf("no resource limits declared — falling back to defaults (%s memory, %.1f CPU, %d PIDs)",
+			formatBytes(limits.MemoryBytes), limits.CPUs, limits.PidsLimit))
+		return limits, warnings
+	}
+
+	limits.Declared = true
+	mem, err := m.Resources.MemoryBytes()
+	switch {
+	case err != nil:
+		warnings = append(warnings, err.Error())
+	case mem > 0:
+		limits.MemoryBytes = mem
+	}
+	if m.Resources.CPUs > 0 {
+		limits.CPUs = m.Resources.CPUs
+	}
+	if m.Resources.Pids > 0 {
+		limits.PidsLimit = m.Resources.Pids
+	}
+
+	if floor := memoryFloor(m.Image); limits.MemoryBytes < floor {
+		warnings = append(warnings, fmt.Sprintf("memory limit %s is likely too low for image %s (recommended at least %s) — the skill may be OOM-killed",
+			formatBytes(limits.MemoryBytes), m.Image, formatBytes(floor)))
+	}
+	if limits.PidsLimit < 10 {
+		warnings = append(warnings, fmt.Sprintf("PID limit %d is very low — process-spawning commands may fail", limits.PidsLimit))
+	}
+	return limits, warnings
+}
+
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<30 && n%(1<<30) == 0:
+		return fmt.Sprintf("%dGB", n>>30)
+	case n >= 1<<20:
+		return fmt.Sprintf("%dMB", n>>20)
+	default:
+		return fmt.Sprintf("%dKB", n>>10)
+	}
+}
@@ -15,17 +15,20 @@ import (
 
 // Report holds the results of a skill simulation.
 type Report struct {
-	SkillName     string          `json:"skill_name"`
-	Version       string          `json:"version"`
-	Image         string          `json:"image"`
-	Platform      string          `json:"platform"`
-	Commands      []string        `json:"commands"`
-	Scopes        []ScopeAnalysis `json:"scopes"`
-	NetworkAccess []string        `json:"network_access"`
-	FileAccess    []string        `json:"file_access"`
-	RiskLevel     string          `json:"risk_level"` // low, medium, high, critical
-	PolicyDecision string         `json:"policy_decision"`
-	Warnings      []string        `json:"warnings,omitempty"`
+	SkillName      string            `json:"skill_name"`
+	Version        string            `json:"version"`
+	Image          string            `json:"image"`
+	Platform       string            `json:"platform"`
+	Commands       []string          `json:"commands"`
+	Scopes         []ScopeAnalysis   `json:"scopes"`
+	NetworkAccess  []string          `json:"network_access"`
+	FileAccess     []string          `json:"file_access"`
+	RiskLevel      string            `json:"risk_level"` // low, medium, high, critical
+	PolicyDecision string            `json:"policy_decision"`
+	PolicyTrace    []policy.Trace    `json:"policy_trace,omitempty"`
+	Resources      ResourceLimits    `json:"resources"`
+	Provenance     *skill.Provenance `json:"provenance,omitempty"`
+	Warnings       []string          `json:"warnings,omitempty"`
 }
 
 // ScopeAnalysis describes a single scope declaration.
@@ -36,8 +39,28 @@ type ScopeAnalysis struct {
 	Risk     string `json:"risk"`
 }
 
-// Run performs a dry-run analysis of a skill manifest.
+// Options tunes a simulation to match the agent's configuration.
+type Options struct {
+	// UnknownScope mirrors policy.unknown_scope; empty means approve.
+	UnknownScope policy.UnknownScopeMode
+	// Rules are the time-window rules from policy.rules, evaluated at the
+	// time of the simulation.
+	Rules []policy.Rule
+	// TrustKeys mirrors registry.trust_keys: a manifest signed by one of
+	// them is evaluated as a signed skill.
+	TrustKeys []string
+	// Explain records how the policy decided each scope in
+	// Report.PolicyTrace.
+	Explain bool
+}
+
+// Run performs a dry-run analysis of a skill manifest with default options.
 func Run(ctx context.Context, m *skill.Manifest) (*Report, error) {
+	return RunWithOptions(ctx, m, Options{})
+}
+
+// RunWithOptions performs a dry-run analysis of a skill manifest.
+func RunWithOptions(ctx context.Context, m *skill.Manifest, opts Options) (*Report, error) {
 	report := &Report{
 		SkillName: m.Name,
 		Version:   m.Version,
@@ -74,6 +97,9 @@ func Run(ctx context.Context, m *skill.Manifest) (*Report, error) {
 		if s.RiskLevel > highestRisk {
 			highestRisk = s.RiskLevel
 		}
+		if !scope.IsKnown(s.Name) {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("unknown scope name: %s", s.Name))
+		}
 
 		// Categorize by type
 		switch {
@@ -82,13 +108,20 @@ func Run(ctx context.Context, m *skill.Manifest) (*Report, error) {
 			if target == "" {
 				target = "(any)"
 			}
+			if s.Exclude {
+				target = "except " + target
+			}
 			report.NetworkAccess = append(report.NetworkAccess, target)
 		case strings.HasPrefix(s.Name, "files."):
 			path := s.Resource
 			if path == "" {
 				path = "(any)"
 			}
-			report.FileAccess = append(report.FileAccess, fmt.Sprintf("%s:%s", s.Name, path))
+			entry := fmt.Sprintf("%s:%s", s.Name, path)
+			if s.Exclude {
+				entry = "except " + entry
+			}
+			report.FileAccess = append(report.FileAccess, entry)
 		}
 	}
 
@@ -98,43 +131,98 @@ func Run(ctx context.Context, m *skill.Manifest) (*Report, error) {
 	if m.Signature == "" {
 		report.Warnings = append(report.Warnings, "skill manifest is unsigned")
 	}
+	report.Provenance = m.Provenance
+	if !m.HasProvenance() {
+		report.Warnings = append(report.Warnings, "no provenance metadata (source repo, SBOM or attestation)")
+	}
 	if len(m.Scopes) == 0 {
 		report.Warnings = append(report.Warnings, "no scopes declared — skill may lack necessary permissions")
 	}
+	for _, l := range m.LiteralSecrets() {
+		report.Warnings = append(report.Warnings, l.String())
+	}
+
+	limits, resWarnings := analyseResources(m)
+	report.Resources = limits
+	report.Warnings = append(report.Warnings, resWarnings...)
 
 	// Evaluate policy
-	report.PolicyDecision = evaluatePolicy(ctx, m)
+	report.PolicyDecision, report.PolicyTrace = evaluatePolicy(ctx, m, opts)
 
 	return report, nil
 }
 
-func evaluatePolicy(ctx context.Context, m *skill.Manifest) string {
+func evaluatePolicy(ctx context.Context, m *skill.Manifest, opts Options) (string, []policy.Trace) {
 	engine, err := policy.LoadDefaultPolicy(ctx)
 	if err != nil {
-		return "unknown (policy not loaded)"
+		return "unknown (policy not loaded)", nil
+	}
+	if opts.UnknownScope != "" {
+		engine.SetUnknownScope(opts.UnknownScope)
+	}
+	engine.SetRules(opts.Rules)
+	if ok, _ := m.VerifySignature(opts.TrustKeys); ok {
+		engine.SetSkillSigned(true)
 	}
 
+	// A deny on any scope wins over approval prompts on earlier ones, as
+	// in policy.Engine.EvaluateRequest.
+	needsApproval := false
+	var traces []policy.Trace
+	var scopes []scope.Scope
 	for _, sStr := range m.Scopes {
 		s, err := scope.Parse(sStr)
 		if err != nil {
 			continue
 		}
-		decision, err := engine.Evaluate(ctx, s)
+		// As in the agent, policy sees the stored secret key, not the
+		// name it is injected under.
+		if s.Name == scope.SecretsAccess.Name {
+			if ref, err := skill.ParseSecretRef(s.Resource); err == nil {
+				s.Resource = ref.Key
+			}
+		}
+		scopes = append(scopes, s)
+	}
+	for _, s := range scopes {
+		// Exclusions win over the policy, as in EvaluateRequest.
+		if x, ok := scope.ExcludedBy(scopes, s); ok {
+			if opts.Explain {
+				traces = append(traces, policy.Trace{
+					Scope:    s.String(),
+					Decision: policy.Deny.String(),
+					Steps:    []string{fmt.Sprintf("excluded by %s (exclusions always win)", x)},
+				})
+			}
+			return "deny", traces
+		}
+		var decision policy.Decision
+		var err error
+		if opts.Explain {
+			var tr policy.Trace
+			decision, tr, err = engine.Explain(ctx, s)
+			traces = append(traces, tr)
+		} else {
+			decision, err = engine.Evaluate(ctx, s)
+		}
 		if err != nil {
 			continue
 		}
 		switch decision {
 		case policy.Deny:
-			return "deny"
+			return "deny", traces
 		case policy.RequireApproval:
-			return "require_approval"
+			needsApproval = true
 		}
 	}
 
+	if needsApproval {
+		return "require_approval", traces
+	}
 	if len(m.Scopes) == 0 {
-		return "allow (no scopes)"
+		return "allow (no scopes)", traces
 	}
-	return "allow"
+	return "allow", traces
 }
 
 func riskLabel(r scope.Risk) string {
@@ -2,8 +2,10 @@ package simulate
 
 import (
 	"context"
+	"strings"
 	"testing"
 
+	"github.com/mackeh/AegisClaw/internal/policy"
 	"github.com/mackeh/AegisClaw/internal/skill"
 )
 
@@ -131,6 +133,33 @@ func TestRun_FileScope(t *testing.T) {
 	}
 }
 
+func TestRun_ExclusionScope(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	m := &skill.Manifest{
+		Name:    "file-skill",
+		Version: "1.0.0",
+		Image:   "alpine:latest",
+		Scopes:  []string{"files.read:/data/secrets/db.key", "!files.read:/data/secrets"},
+		Commands: map[string]skill.Command{
+			"process": {Args: []string{"cat"}},
+		},
+	}
+
+	report, err := RunWithOptions(context.Background(), m, Options{Explain: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.PolicyDecision != "deny" {
+		t.Errorf("policy decision = %q, want deny for an excluded path", report.PolicyDecision)
+	}
+	if len(report.FileAccess) != 2 || report.FileAccess[1] != "except files.read:/data/secrets" {
+		t.Errorf("file access = %v", report.FileAccess)
+	}
+	if n := len(report.PolicyTrace); n == 0 || !strings.Contains(report.PolicyTrace[n-1].Steps[0], "excluded by !files.read:/data/secrets") {
+		t.Errorf("policy trace = %+v", report.PolicyTrace)
+	}
+}
+
 func TestRun_DefaultPlatform(t *testing.T) {
 	m := &skill.Manifest{
 		Name:    "default-platform",
@@ -169,3 +198,135 @@ func TestRiskLabel(t *testing.T) {
 		}
 	}
 }
+
+func TestRun_MemoryBelowImageFloor(t *testing.T) {
+	m := &skill.Manifest{
+		Name:      "node-skill",
+		Version:   "1.0.0",
+		Image:     "node:20-alpine",
+		Scopes:    []string{"files.read:/tmp"},
+		Resources: &skill.Resources{Memory: "64m"},
+	}
+
+	report, err := Run(context.Background(), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Resources.MemoryBytes != 64<<20 || !report.Resources.Declared {
+		t.Errorf("unexpected effective limits: %+v", report.Resources)
+	}
+	if !hasWarning(report, "likely too low") {
+		t.Errorf("expected low-memory warning, got %v", report.Warnings)
+	}
+
+	// The same limit is fine for a minimal image.
+	m.Image = "alpine:latest"
+	report, _ = Run(context.Background(), m)
+	if hasWarning(report, "likely too low") {
+		t.Errorf("unexpected low-memory warning for alpine: %v", report.Warnings)
+	}
+}
+
+func TestRun_DefaultResourceLimits(t *testing.T) {
+	m := &skill.Manifest{Name: "s", Version: "1.0.0", Image: "alpine:latest", Scopes: []string{"files.read:/tmp"}}
+
+	report, err := Run(context.Background(), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Resources.MemoryBytes != 512<<20 || report.Resources.Declared {
+		t.Errorf("expected 512MB default, got %+v", report.Resources)
+	}
+	if !hasWarning(report, "no resource limits declared") {
+		t.Errorf("expected default-limits warning, got %v", report.Warnings)
+	}
+}
+
+func hasWarning(r *Report, substr string) bool {
+	for _, w := range r.Warnings {
+		if strings.Contains(w, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRun_ProvenanceWarning(t *testing.T) {
+	m := &skill.Manifest{
+		Name:    "no-provenance",
+		Version: "1.0.0",
+		Image:   "alpine:latest",
+		Scopes:  []string{"files.read:/tmp"},
+	}
+
+	report, err := Run(context.Background(), m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !hasWarning(report, "no provenance") {
+		t.Error("expected missing-provenance warning")
+	}
+
+	m.Provenance = &skill.Provenance{SourceRepo: "https://github.com/example/skill"}
+	report, err = Run(context.Background(), m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if hasWarning(report, "no provenance") {
+		t.Error("unexpected missing-provenance warning")
+	}
+	if report.Provenance == nil || report.Provenance.SourceRepo != m.Provenance.SourceRepo {
+		t.Errorf("provenance not surfaced in report: %+v", report.Provenance)
+	}
+}
+
+func TestRun_UnknownScopeModes(t *testing.T) {
+	// Use the built-in default policy rather than one in the real home dir.
+	t.Setenv("HOME", t.TempDir())
+	m := &skill.Manifest{
+		Name:    "typo",
+		Version: "1.0.0",
+		Image:   "alpine:latest",
+		Scopes:  []string{"files.read:/tmp", "filez.read:/tmp"},
+	}
+
+	report, err := Run(context.Background(), m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.PolicyDecision != "require_approval" {
+		t.Errorf("approve mode: decision = %q, want require_approval", report.PolicyDecision)
+	}
+	if !hasWarning(report, "unknown scope name: filez.read") {
+		t.Errorf("expected unknown scope warning, got %v", report.Warnings)
+	}
+
+	report, err = RunWithOptions(context.Background(), m, Options{UnknownScope: policy.UnknownScopeDeny})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.PolicyDecision != "deny" {
+		t.Errorf("deny mode: decision = %q, want deny", report.PolicyDecision)
+	}
+}
+
+func TestRun_LiteralSecretWarning(t *testing.T) {
+	m := &skill.Manifest{
+		Name:    "hardcoded",
+		Version: "1.0.0",
+		Image:   "alpine:latest",
+		Commands: map[string]skill.Command{
+			"chat": {Args: []string{"python", "chat.py"}, Env: []string{"OPENAI_API_KEY=sk-proj-4f1c9a7e2b8d6c0a1e3f5b7d"}},
+		},
+	}
+	report, err := Run(context.Background(), m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !hasWarning(report, "secrets.access:OPENAI_API_KEY") {
+		t.Errorf("expected a literal-secret warning, got %v", report.Warnings)
+	}
+	if hasWarning(report, "sk-proj") {
+		t.Error("warning repeats the secret")
+	}
+}
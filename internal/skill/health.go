@@ -0,0 +1,86 @@
+package skill
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Default health probe timing.
+const (
+	DefaultHealthInterval = time.Second
+	DefaultHealthTimeout  = time.Minute
+)
+
+// Health declares how to tell that a long-running skill (one that serves
+// rather than exits) is ready. Exactly one of Command and HTTP is set:
+//
+//	health:
+//	  command: ["wget", "-qO-", "http://127.0.0.1:8080/healthz"]
+//	  interval: 2s
+//	  timeout: 30s
+//
+// Command runs inside the container and must exit 0. HTTP is a GET from
+// the host to the container's address, which must answer 2xx; it needs a
+// network scope, since a container without one has no address.
+type Health struct {
+	Command  []string   `yaml:"command,omitempty" json:"command,omitempty"`
+	HTTP     *HTTPProbe `yaml:"http,omitempty" json:"http,omitempty"`
+	Interval string     `yaml:"interval,omitempty" json:"interval,omitempty"` // between probes; default 1s
+	Timeout  string     `yaml:"timeout,omitempty" json:"timeout,omitempty"`   // until the skill counts as failed; default 1m
+}
+
+// HTTPProbe is a GET of Path on Port.
+type HTTPProbe struct {
+	Port int    `yaml:"port" json:"port"`
+	Path string `yaml:"path,omitempty" json:"path,omitempty"` // default "/"
+}
+
+// Validate checks that h declares exactly one well-formed probe. A nil
+// Health is valid.
+func (h *Health) Validate() error {
+	if h == nil {
+		return nil
+	}
+	switch {
+	case len(h.Command) > 0 && h.HTTP != nil:
+		return fmt.Errorf("health: set command or http, not both")
+	case len(h.Command) == 0 && h.HTTP == nil:
+		return fmt.Errorf("health: a command or http probe is required")
+	case h.HTTP != nil && (h.HTTP.Port < 1 || h.HTTP.Port > 65535):
+		return fmt.Errorf("health: invalid http port %d", h.HTTP.Port)
+	case h.HTTP != nil && h.HTTP.Path != "" && !strings.HasPrefix(h.HTTP.Path, "/"):
+		return fmt.Errorf("health: http path %q must start with /", h.HTTP.Path)
+	}
+	_, _, err := h.Timing()
+	return err
+}
+
+// Timing returns the probe interval and overall timeout, with defaults for
+// unset values.
+func (h *Health) Timing() (interval, timeout time.Duration, err error) {
+	interval, timeout = DefaultHealthInterval, DefaultHealthTimeout
+	if h == nil {
+		return interval, timeout, nil
+	}
+	if h.Interval != "" {
+		if interval, err = time.ParseDuration(h.Interval); err != nil || interval <= 0 {
+			return 0, 0, fmt.Errorf("health: invalid interval %q", h.Interval)
+		}
+	}
+	if h.Timeout != "" {
+		if timeout, err = time.ParseDuration(h.Timeout); err != nil || timeout <= 0 {
+			return 0, 0, fmt.Errorf("health: invalid timeout %q", h.Timeout)
+		}
+	}
+	return interval, timeout, nil
+}
+
+// URL returns the probe URL for a container reachable at host.
+func (p *HTTPProbe) URL(host string) string {
+	path := p.Path
+	if path == "" {
+		path = "/"
+	}
+	return fmt.Sprintf("http://%s:%d%s", host, p.Port, path)
+}
@@ -0,0 +1,49 @@
+package skill
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHealthValidate(t *testing.T) {
+	var none *Health
+	if err := none.Validate(); err != nil {
+		t.Errorf("nil health: %v", err)
+	}
+	valid := []*Health{
+		{Command: []string{"true"}},
+		{HTTP: &HTTPProbe{Port: 8080, Path: "/healthz"}, Interval: "2s", Timeout: "30s"},
+	}
+	for _, h := range valid {
+		if err := h.Validate(); err != nil {
+			t.Errorf("Validate(%+v) = %v", h, err)
+		}
+	}
+	invalid := []*Health{
+		{},
+		{Command: []string{"true"}, HTTP: &HTTPProbe{Port: 80}},
+		{HTTP: &HTTPProbe{Port: 0}},
+		{HTTP: &HTTPProbe{Port: 80, Path: "healthz"}},
+		{Command: []string{"true"}, Interval: "soon"},
+		{Command: []string{"true"}, Timeout: "-1s"},
+	}
+	for _, h := range invalid {
+		if err := h.Validate(); err == nil {
+			t.Errorf("Validate(%+v) should fail", h)
+		}
+	}
+}
+
+func TestHealthTiming(t *testing.T) {
+	interval, timeout, err := (&Health{Command: []string{"true"}}).Timing()
+	if err != nil || interval != DefaultHealthInterval || timeout != DefaultHealthTimeout {
+		t.Errorf("defaults = %v, %v, %v", interval, timeout, err)
+	}
+	interval, timeout, err = (&Health{Interval: "250ms", Timeout: "10s"}).Timing()
+	if err != nil || interval != 250*time.Millisecond || timeout != 10*time.Second {
+		t.Errorf("configured = %v, %v, %v", interval, timeout, err)
+	}
+	if got := (&HTTPProbe{Port: 8080}).URL("172.17.0.2"); got != "http://172.17.0.2:8080/" {
+		t.Errorf("URL = %s", got)
+	}
+}
@@ -0,0 +1,45 @@
+package skill
+
+import "fmt"
+
+// Hooks declares setup and teardown commands run around every command of
+// the skill:
+//
+//	hooks:
+//	  before:
+//	    args: ["sh", "-c", "curl -fsS -X POST https://api.example.com/sandboxes"]
+//	  after:
+//	    args: ["sh", "-c", "curl -fsS -X DELETE https://api.example.com/sandboxes/ci"]
+//
+// Each hook runs in its own container configured exactly like the main
+// command's: same image, scopes, egress rules, secrets and limits. The
+// containers share no filesystem, so hooks prepare and clean up state the
+// skill's scopes reach, not files. The after hook runs even when the before
+// hook or the main command fails.
+type Hooks struct {
+	Before *Command `yaml:"before,omitempty" json:"before,omitempty"`
+	After  *Command `yaml:"after,omitempty" json:"after,omitempty"`
+}
+
+// Validate checks that each hook has a command and takes no arguments:
+// hooks run the same way whichever command the user invoked. A nil Hooks
+// is valid.
+func (h *Hooks) Validate() error {
+	if h == nil {
+		return nil
+	}
+	for _, hook := range []struct {
+		name string
+		cmd  *Command
+	}{{"before", h.Before}, {"after", h.After}} {
+		switch {
+		case hook.cmd == nil:
+			continue
+		case len(hook.cmd.Args) == 0:
+			return fmt.Errorf("hooks.%s: args are required", hook.name)
+		case len(hook.cmd.Params) > 0 || hook.cmd.IsTemplated():
+			return fmt.Errorf("hooks.%s: hooks take no arguments", hook.name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package skill
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHooksValidate(t *testing.T) {
+	var none *Hooks
+	if err := none.Validate(); err != nil {
+		t.Errorf("nil hooks: %v", err)
+	}
+	valid := []*Hooks{
+		{},
+		{Before: &Command{Args: []string{"curl", "-fsS", "-X", "POST", "https://api.example.com/sandboxes"}}},
+		{After: &Command{Args: []string{"sh", "-c", "curl -fsS -X DELETE \"$SANDBOX_URL\""}, Env: []string{"SANDBOX_URL=https://api.example.com/sandboxes/ci"}}},
+	}
+	for _, h := range valid {
+		if err := h.Validate(); err != nil {
+			t.Errorf("Validate(%+v) = %v", h, err)
+		}
+	}
+	invalid := []*Hooks{
+		{Before: &Command{}},
+		{After: &Command{Args: []string{"rm", "{{.Args.path}}"}, Params: []Param{{Name: "path"}}}},
+		{Before: &Command{Args: []string{"echo", "{{.Args.0}}"}}},
+	}
+	for _, h := range invalid {
+		if err := h.Validate(); err == nil {
+			t.Errorf("Validate(%+v) should fail", h)
+		}
+	}
+}
+
+func TestLoadManifest_Hooks(t *testing.T) {
+	write := func(t *testing.T, hooks string) string {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "skill.yaml")
+		content := "name: test\nversion: \"1.0.0\"\nimage: alpine:latest\nscopes: []\n" + hooks
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	m, err := LoadManifest(write(t, "hooks:\n  before:\n    args: [\"touch\", \"/tmp/ready\"]\n  after:\n    args: [\"rm\", \"/tmp/ready\"]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Hooks == nil || m.Hooks.Before == nil || m.Hooks.After == nil || m.Hooks.After.Args[0] != "rm" {
+		t.Errorf("hooks = %+v", m.Hooks)
+	}
+
+	_, err = LoadManifest(write(t, "hooks:\n  after:\n    args: []\n"))
+	if err == nil || !strings.Contains(err.Error(), "hooks.after") {
+		t.Errorf("expected an empty after hook to be rejected, got %v", err)
+	}
+}
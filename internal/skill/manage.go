@@ -0,0 +1,123 @@
+package skill
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/mackeh/AegisClaw/internal/audit"
+	"gopkg.in/yaml.v3"
+)
+
+// UpdateStatus describes the outcome of UpdateSkill.
+type UpdateStatus struct {
+	Name      string
+	Installed string
+	Available string
+	Updated   bool
+}
+
+// RemoveSkill deletes an installed skill's directory from destDir and, when
+// logger is non-nil, records the removal in the audit log.
+func RemoveSkill(skillName, destDir string, logger *audit.Logger) error {
+	if err := validateSkillName(skillName); err != nil {
+		return err
+	}
+
+	// The version is only for the audit entry: even a skill.yaml that no
+	// longer parses must not keep its skill installed.
+	m, err := installedManifest(skillName, destDir)
+	if err != nil && !errors.Is(err, errManifestUnparsable) {
+		return err
+	}
+	version := ""
+	if m != nil {
+		version = m.Version
+	}
+
+	if err := os.RemoveAll(filepath.Join(destDir, skillName)); err != nil {
+		return fmt.Errorf("failed to remove skill directory: %w", err)
+	}
+
+	if logger != nil {
+		_ = logger.LogAs("skill.remove", nil, "allow", audit.CLIActor(), map[string]any{
+			"skill":   skillName,
+			"version": version,
+		})
+	}
+	return nil
+}
+
+// errManifestUnparsable is returned by installedManifest for a skill.yaml
+// that is not valid YAML.
+var errManifestUnparsable = errors.New("failed to parse skill manifest")
+
+// installedManifest reads skillName's installed skill.yaml without
+// validating it, so that a skill LoadManifest now rejects (for a newer
+// min_aegisclaw_version, say, or a literal secret) can still be removed or
+// updated.
+func installedManifest(skillName, destDir string) (*Manifest, error) {
+	root, err := os.OpenRoot(filepath.Join(destDir, skillName))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("skill '%s' is not installed", skillName)
+		}
+		return nil, fmt.Errorf("failed to open skill directory: %w", err)
+	}
+	defer root.Close()
+
+	data, err := fs.ReadFile(root.FS(), "skill.yaml")
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("skill '%s' is not installed", skillName)
+		}
+		return nil, fmt.Errorf("failed to read skill manifest: %w", err)
+	}
+	var m Manifest
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("%w: %w", errManifestUnparsable, err)
+	}
+	return &m, nil
+}
+
+// UpdateSkill re-installs skillName from the registry only when the registry
+// advertises a newer version than the one installed. The downgrade guard and
+// signature verification of InstallSkillWithOptions still apply.
+func UpdateSkill(skillName, destDir, registryURL string, trustKeys []string, opts InstallOptions) (*UpdateStatus, error) {
+	if err := validateSkillName(skillName); err != nil {
+		return nil, err
+	}
+
+	m, err := installedManifest(skillName, destDir)
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := SearchRegistryWithAuth(registryURL, opts.Auth)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &UpdateStatus{Name: skillName, Installed: m.Version}
+	for _, s := range index.Skills {
+		if s.Name == skillName {
+			status.Available = s.Version
+			break
+		}
+	}
+	if status.Available == "" {
+		return nil, fmt.Errorf("skill '%s' not found in registry", skillName)
+	}
+
+	if CompareVersions(status.Available, status.Installed) <= 0 {
+		return status, nil
+	}
+
+	if err := InstallSkillWithOptions(skillName, destDir, registryURL, trustKeys, opts); err != nil {
+		return status, err
+	}
+	status.Updated = true
+	return status, nil
+}
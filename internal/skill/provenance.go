@@ -0,0 +1,46 @@
+package skill
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// Provenance records where a skill was built from so users can trace it back
+// to source. All fields are optional, but a skill with none of them set is
+// treated as lacking provenance.
+type Provenance struct {
+	SourceRepo  string `yaml:"source_repo,omitempty" json:"source_repo,omitempty"` // e.g. https://github.com/org/skill
+	Commit      string `yaml:"commit,omitempty" json:"commit,omitempty"`           // source revision the image was built from
+	SBOM        string `yaml:"sbom,omitempty" json:"sbom,omitempty"`               // URL of an SPDX/CycloneDX document
+	Attestation string `yaml:"attestation,omitempty" json:"attestation,omitempty"` // URL of a build attestation (e.g. SLSA)
+}
+
+// HasProvenance reports whether the manifest carries any provenance metadata.
+func (m *Manifest) HasProvenance() bool {
+	p := m.Provenance
+	return p != nil && (p.SourceRepo != "" || p.SBOM != "" || p.Attestation != "")
+}
+
+// Validate checks that every provenance URL is an absolute http(s) URL.
+func (p *Provenance) Validate() error {
+	if p == nil {
+		return nil
+	}
+	for _, f := range []struct{ name, value string }{
+		{"source_repo", p.SourceRepo},
+		{"sbom", p.SBOM},
+		{"attestation", p.Attestation},
+	} {
+		if f.value == "" {
+			continue
+		}
+		u, err := url.Parse(f.value)
+		if err != nil {
+			return fmt.Errorf("invalid provenance %s %q: %w", f.name, f.value, err)
+		}
+		if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("invalid provenance %s %q: must be an absolute http(s) URL", f.name, f.value)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package skill
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/mackeh/AegisClaw/internal/config"
+)
+
+// ErrRegistryAuth is returned when a registry answers 401 or 403.
+var ErrRegistryAuth = errors.New("registry authentication failed")
+
+// RegistryAuth holds credentials for a private registry: Token is sent as a
+// bearer token, otherwise Username and Password as basic auth.
+type RegistryAuth struct {
+	Token    string
+	Username string
+	Password string
+}
+
+// ResolveRegistryAuth reads the credentials named by registry.auth_secret
+// through get (e.g. a secrets.Manager's Get). It returns nil when no secret
+// is configured. With auth_type basic the secret holds "user:password".
+func ResolveRegistryAuth(c config.RegistryConfig, get func(key string) (string, error)) (*RegistryAuth, error) {
+	name := strings.TrimSpace(c.AuthSecret)
+	if name == "" {
+		return nil, nil
+	}
+	value, err := get(name)
+	if err != nil {
+		return nil, fmt.Errorf("registry: failed to resolve auth secret %q: %w", name, err)
+	}
+	switch strings.ToLower(strings.TrimSpace(c.AuthType)) {
+	case "", "bearer":
+		return &RegistryAuth{Token: value}, nil
+	case "basic":
+		user, pass, ok := strings.Cut(value, ":")
+		if !ok || user == "" {
+			return nil, fmt.Errorf("registry: auth secret %q must hold user:password for basic auth", name)
+		}
+		return &RegistryAuth{Username: user, Password: pass}, nil
+	default:
+		return nil, fmt.Errorf("registry: invalid auth_type %q", c.AuthType)
+	}
+}
+
+// apply sets the credentials on req.
+func (a *RegistryAuth) apply(req *http.Request) {
+	if a == nil {
+		return
+	}
+	if a.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+a.Token)
+	} else if a.Username != "" {
+		req.SetBasicAuth(a.Username, a.Password)
+	}
+}
+
+// registryGet fetches rawURL, adding auth only when rawURL is on the
+// registry's own host so credentials never leak to a CDN or a URL an index
+// entry points elsewhere. Any status other than 200 is an error; 401 and 403
+// wrap ErrRegistryAuth.
+func registryGet(rawURL, registryURL string, auth *RegistryAuth) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if sameHost(rawURL, registryURL) {
+		auth.apply(req)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp, nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		resp.Body.Close()
+		if auth == nil {
+			return nil, fmt.Errorf("%w: %s returned %s; set registry.auth_secret to a secret holding the registry's credentials", ErrRegistryAuth, rawURL, resp.Status)
+		}
+		return nil, fmt.Errorf("%w: %s returned %s; check the credentials in registry.auth_secret", ErrRegistryAuth, rawURL, resp.Status)
+	default:
+		resp.Body.Close()
+		return nil, fmt.Errorf("registry returned error: %s", resp.Status)
+	}
+}
+
+// sameHost reports whether a and b share scheme and host.
+func sameHost(a, b string) bool {
+	ua, errA := url.Parse(a)
+	ub, errB := url.Parse(b)
+	return errA == nil && errB == nil && ua.Host != "" && ua.Scheme == ub.Scheme && strings.EqualFold(ua.Host, ub.Host)
+}
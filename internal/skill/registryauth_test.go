@@ -0,0 +1,147 @@
+package skill
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mackeh/AegisClaw/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// privateRegistry serves a one-skill index and manifest that both require
+// the Authorization header want. When manifestSrv, an unstarted server, is
+// set the index points the manifest there instead.
+func privateRegistry(t *testing.T, want string, manifestSrv *httptest.Server) (*httptest.Server, string) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest := func(w http.ResponseWriter) {
+		m := Manifest{Name: "demo", Version: "1.0.0", Image: "alpine:latest", Scopes: []string{}, Commands: map[string]Command{}}
+		data, _ := json.Marshal(m)
+		m.Signature = hex.EncodeToString(ed25519.Sign(priv, data))
+		yaml.NewEncoder(w).Encode(m)
+	}
+	if manifestSrv != nil {
+		manifestSrv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "" {
+				t.Errorf("credentials sent to another host: %q", r.Header.Get("Authorization"))
+			}
+			manifest(w)
+		})
+		manifestSrv.Start()
+		t.Cleanup(manifestSrv.Close)
+	}
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != want {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/index.json":
+			base := srv.URL
+			if manifestSrv != nil {
+				base = manifestSrv.URL
+			}
+			json.NewEncoder(w).Encode(RegistryIndex{Skills: []RegistrySkill{{
+				Name: "demo", Version: "1.0.0", ManifestURL: base + "/demo.yaml",
+			}}})
+		case "/demo.yaml":
+			manifest(w)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, hex.EncodeToString(pub)
+}
+
+func TestInstallSkill_PrivateRegistryBearer(t *testing.T) {
+	srv, key := privateRegistry(t, "Bearer s3cret", nil)
+	dir := t.TempDir()
+
+	err := InstallSkill("demo", dir, srv.URL, []string{key})
+	if !errors.Is(err, ErrRegistryAuth) {
+		t.Fatalf("anonymous install: expected ErrRegistryAuth, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "registry.auth_secret") {
+		t.Errorf("error should point at registry.auth_secret: %v", err)
+	}
+
+	err = InstallSkillWithOptions("demo", dir, srv.URL, []string{key}, InstallOptions{Auth: &RegistryAuth{Token: "wrong"}})
+	if !errors.Is(err, ErrRegistryAuth) {
+		t.Fatalf("wrong token: expected ErrRegistryAuth, got %v", err)
+	}
+
+	if err := InstallSkillWithOptions("demo", dir, srv.URL, []string{key}, InstallOptions{Auth: &RegistryAuth{Token: "s3cret"}}); err != nil {
+		t.Fatalf("install with token: %v", err)
+	}
+	if got := installedVersion(t, dir); got != "1.0.0" {
+		t.Errorf("installed version = %s, want 1.0.0", got)
+	}
+}
+
+func TestInstallSkill_PrivateRegistryBasic(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "http://example", nil)
+	req.SetBasicAuth("ci", "pa:ss")
+	srv, key := privateRegistry(t, req.Header.Get("Authorization"), nil)
+
+	secrets := map[string]string{"REGISTRY_CREDS": "ci:pa:ss"}
+	get := func(k string) (string, error) { return secrets[k], nil }
+	auth, err := ResolveRegistryAuth(config.RegistryConfig{AuthSecret: "REGISTRY_CREDS", AuthType: "basic"}, get)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := InstallSkillWithOptions("demo", t.TempDir(), srv.URL, []string{key}, InstallOptions{Auth: auth}); err != nil {
+		t.Fatalf("install with basic auth: %v", err)
+	}
+}
+
+func TestInstallSkill_AuthNotSentToOtherHosts(t *testing.T) {
+	cdn := httptest.NewUnstartedServer(nil)
+	srv, key := privateRegistry(t, "Bearer s3cret", cdn)
+
+	if err := InstallSkillWithOptions("demo", t.TempDir(), srv.URL, []string{key}, InstallOptions{Auth: &RegistryAuth{Token: "s3cret"}}); err != nil {
+		t.Fatalf("install: %v", err)
+	}
+}
+
+func TestResolveRegistryAuth(t *testing.T) {
+	get := func(k string) (string, error) {
+		if k == "TOKEN" {
+			return "abc", nil
+		}
+		return "", errors.New("not found")
+	}
+	tests := []struct {
+		name    string
+		cfg     config.RegistryConfig
+		want    *RegistryAuth
+		wantErr bool
+	}{
+		{"anonymous", config.RegistryConfig{}, nil, false},
+		{"bearer default", config.RegistryConfig{AuthSecret: "TOKEN"}, &RegistryAuth{Token: "abc"}, false},
+		{"missing secret", config.RegistryConfig{AuthSecret: "NOPE"}, nil, true},
+		{"basic without colon", config.RegistryConfig{AuthSecret: "TOKEN", AuthType: "basic"}, nil, true},
+		{"unknown type", config.RegistryConfig{AuthSecret: "TOKEN", AuthType: "digest"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveRegistryAuth(tt.cfg, get)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package skill
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Resources declares the sandbox limits a skill needs. Zero values fall back
+// to the sandbox defaults.
+type Resources struct {
+	Memory string  `yaml:"memory,omitempty" json:"memory,omitempty"` // e.g. "256m", "1g"
+	CPUs   float64 `yaml:"cpus,omitempty" json:"cpus,omitempty"`     // e.g. 0.5
+	Pids   int64   `yaml:"pids,omitempty" json:"pids,omitempty"`     // max processes
+	// Tmp is the size of the /tmp tmpfs, e.g. "128m".
+	Tmp string `yaml:"tmp,omitempty" json:"tmp,omitempty"`
+	// Tmpfs declares extra in-memory mounts.
+	Tmpfs []TmpfsMount `yaml:"tmpfs,omitempty" json:"tmpfs,omitempty"`
+}
+
+// TmpfsMount is an extra tmpfs, e.g. {path: /cache, size: 32m}. An empty
+// size uses the sandbox default.
+type TmpfsMount struct {
+	Path string `yaml:"path" json:"path"`
+	Size string `yaml:"size,omitempty" json:"size,omitempty"`
+}
+
+// TmpBytes parses the declared /tmp size. It returns 0 when unset.
+func (r *Resources) TmpBytes() (int64, error) {
+	if r == nil || strings.TrimSpace(r.Tmp) == "" {
+		return 0, nil
+	}
+	n, err := ParseMemory(r.Tmp)
+	if err != nil {
+		return 0, fmt.Errorf("tmp: %w", err)
+	}
+	return n, nil
+}
+
+// MemoryBytes parses the declared memory limit. It returns 0 when unset.
+func (r *Resources) MemoryBytes() (int64, error) {
+	if r == nil || strings.TrimSpace(r.Memory) == "" {
+		return 0, nil
+	}
+	return ParseMemory(r.Memory)
+}
+
+// ParseMemory parses a Docker-style memory size ("512m", "1g", "65536k",
+// "1048576") into bytes.
+func ParseMemory(s string) (int64, error) {
+	v := strings.ToLower(strings.TrimSpace(s))
+	v = strings.TrimSuffix(v, "b")
+	mult := int64(1)
+	if n := len(v); n > 0 {
+		switch v[n-1] {
+		case 'k':
+			mult = 1 << 10
+		case 'm':
+			mult = 1 << 20
+		case 'g':
+			mult = 1 << 30
+		}
+		if mult != 1 {
+			v = v[:n-1]
+		}
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid memory size %q", s)
+	}
+	return n * mult, nil
+}
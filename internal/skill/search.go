@@ -0,0 +1,113 @@
+package skill
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// LocalDir is the project-local skills directory, relative to the working
+// directory.
+const LocalDir = "skills"
+
+// SearchPaths returns the directories skills are loaded from, highest
+// precedence first: skills installed under cfgDir, then LocalDir. When both
+// hold a skill with the same name, the installed one is used and the local
+// one is shadowed.
+func SearchPaths(cfgDir string) []string {
+	return []string{filepath.Join(cfgDir, "skills"), LocalDir}
+}
+
+// LoadSkills lists the skills in dirs, earlier directories taking
+// precedence: a name already loaded from an earlier directory hides later
+// skills of that name. Missing directories are skipped.
+func LoadSkills(dirs ...string) []*Manifest {
+	var out []*Manifest
+	for _, l := range loadListings(dirs) {
+		out = append(out, l.Manifest)
+	}
+	return out
+}
+
+// Listing is a loaded skill as `skills list --json` reports it: the full
+// manifest plus where it was loaded from and whether its signature checks
+// out.
+type Listing struct {
+	*Manifest
+	// Dir is the skill's directory, the one holding its skill.yaml.
+	Dir string `json:"dir"`
+	// Signed reports whether the manifest carries a signature at all.
+	Signed bool `json:"signed"`
+	// Trusted reports whether that signature verifies against one of the
+	// trust keys.
+	Trusted bool `json:"trusted"`
+}
+
+// ListDetailed is LoadSkills with each skill's directory and signature
+// status, verified against trustKeys.
+func ListDetailed(trustKeys []string, dirs ...string) []Listing {
+	out := loadListings(dirs)
+	for i := range out {
+		out[i].Signed = out[i].Signature != ""
+		if out[i].Signed {
+			out[i].Trusted, _ = out[i].VerifySignature(trustKeys)
+		}
+	}
+	return out
+}
+
+// loadListings implements LoadSkills' precedence, keeping each skill's
+// directory.
+func loadListings(dirs []string) []Listing {
+	seen := map[string]bool{}
+	var out []Listing
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+			skillDir := filepath.Join(dir, entry.Name())
+			m, err := LoadManifest(filepath.Join(skillDir, "skill.yaml"))
+			if err != nil || seen[m.Name] {
+				continue
+			}
+			seen[m.Name] = true
+			out = append(out, Listing{Manifest: m, Dir: skillDir})
+		}
+	}
+	return out
+}
+
+// Collision is a skill name defined in more than one search directory.
+// Dirs are in precedence order, so Dirs[0] is the one used.
+type Collision struct {
+	Name string
+	Dirs []string
+}
+
+// FindCollisions reports skill names defined in more than one of dirs
+// (or twice within one), sorted by name.
+func FindCollisions(dirs ...string) []Collision {
+	found := map[string][]string{}
+	for _, dir := range dirs {
+		manifests, err := ListSkills(dir)
+		if err != nil {
+			continue
+		}
+		for _, m := range manifests {
+			found[m.Name] = append(found[m.Name], dir)
+		}
+	}
+	var out []Collision
+	for name, in := range found {
+		if len(in) > 1 {
+			out = append(out, Collision{Name: name, Dirs: in})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
@@ -0,0 +1,111 @@
+package skill
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeManifest(t *testing.T, dir, name, version string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(path, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	data := "name: " + name + "\nversion: " + version + "\nimage: alpine:latest\n"
+	if err := os.WriteFile(filepath.Join(path, "skill.yaml"), []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadSkills_EarlierDirWins(t *testing.T) {
+	installed, local := t.TempDir(), t.TempDir()
+	writeManifest(t, installed, "scanner", "2.0.0")
+	writeManifest(t, local, "scanner", "1.0.0-dev")
+	writeManifest(t, local, "notes", "0.1.0")
+
+	got := map[string]string{}
+	for _, m := range LoadSkills(installed, local, filepath.Join(local, "missing")) {
+		if _, dup := got[m.Name]; dup {
+			t.Errorf("%s listed twice", m.Name)
+		}
+		got[m.Name] = m.Version
+	}
+	if got["scanner"] != "2.0.0" || got["notes"] != "0.1.0" {
+		t.Errorf("loaded %v, want the installed scanner and the local notes", got)
+	}
+
+	collisions := FindCollisions(installed, local)
+	if len(collisions) != 1 || collisions[0].Name != "scanner" || collisions[0].Dirs[0] != installed {
+		t.Errorf("collisions = %+v", collisions)
+	}
+}
+
+// signManifest appends a signature by priv to the skill.yaml under dir/name.
+func signManifest(t *testing.T, dir, name string, priv ed25519.PrivateKey) {
+	t.Helper()
+	path := filepath.Join(dir, name, "skill.yaml")
+	m, err := LoadManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(m)
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString("signature: " + hex.EncodeToString(ed25519.Sign(priv, data)) + "\n"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestListDetailed_JSON(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherPriv, _ := ed25519.GenerateKey(nil)
+
+	dir := t.TempDir()
+	writeManifest(t, dir, "trusted", "1.0.0")
+	signManifest(t, dir, "trusted", priv)
+	writeManifest(t, dir, "foreign", "1.0.0")
+	signManifest(t, dir, "foreign", otherPriv)
+	writeManifest(t, dir, "unsigned", "1.0.0")
+
+	out, err := json.Marshal(ListDetailed([]string{hex.EncodeToString(pub)}, dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var parsed []struct {
+		Name    string
+		Image   string
+		Dir     string `json:"dir"`
+		Signed  bool   `json:"signed"`
+		Trusted bool   `json:"trusted"`
+	}
+	if err := json.Unmarshal(out, &parsed); err != nil {
+		t.Fatalf("parse %s: %v", out, err)
+	}
+
+	want := map[string][2]bool{ // name -> {signed, trusted}
+		"trusted":  {true, true},
+		"foreign":  {true, false},
+		"unsigned": {false, false},
+	}
+	if len(parsed) != len(want) {
+		t.Fatalf("listed %d skills, want %d: %s", len(parsed), len(want), out)
+	}
+	for _, p := range parsed {
+		if got := [2]bool{p.Signed, p.Trusted}; got != want[p.Name] {
+			t.Errorf("%s signed/trusted = %v, want %v", p.Name, got, want[p.Name])
+		}
+		if p.Dir != filepath.Join(dir, p.Name) || p.Image != "alpine:latest" {
+			t.Errorf("%s dir/image = %q/%q", p.Name, p.Dir, p.Image)
+		}
+	}
+}
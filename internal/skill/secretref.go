@@ -0,0 +1,61 @@
+package skill
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// secretMapArrow separates the stored key from the injected name in a
+// secrets.access resource.
+const secretMapArrow = "->"
+
+var envName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// SecretRef is a parsed secrets.access resource: the key the secret is
+// stored under and the name the skill sees it as, which differ for
+// "secrets.access:PROD_OPENAI->OPENAI_API_KEY".
+type SecretRef struct {
+	Key     string
+	EnvName string
+}
+
+// ParseSecretRef parses "KEY" or "KEY->ENV_NAME". A plain KEY is injected
+// under its own name.
+func ParseSecretRef(resource string) (SecretRef, error) {
+	key, name, mapped := strings.Cut(resource, secretMapArrow)
+	key, name = strings.TrimSpace(key), strings.TrimSpace(name)
+	if key == "" || strings.ContainsAny(key, " \t\r\n") {
+		return SecretRef{}, fmt.Errorf("invalid secrets.access %q: missing or malformed secret key", resource)
+	}
+	if !mapped {
+		return SecretRef{Key: key, EnvName: key}, nil
+	}
+	if !envName.MatchString(name) {
+		return SecretRef{}, fmt.Errorf("invalid secrets.access %q: %q is not a valid environment variable name", resource, name)
+	}
+	return SecretRef{Key: key, EnvName: name}, nil
+}
+
+// SecretRefs returns the secrets the manifest requests, in scope order. Two
+// secrets may not be injected under the same name.
+func (m *Manifest) SecretRefs() ([]SecretRef, error) {
+	var refs []SecretRef
+	seen := map[string]string{}
+	for _, s := range m.Scopes {
+		resource, ok := strings.CutPrefix(s, "secrets.access:")
+		if !ok {
+			continue
+		}
+		ref, err := ParseSecretRef(resource)
+		if err != nil {
+			return nil, err
+		}
+		if prev, dup := seen[ref.EnvName]; dup && prev != ref.Key {
+			return nil, fmt.Errorf("secrets %s and %s are both injected as %s", prev, ref.Key, ref.EnvName)
+		}
+		seen[ref.EnvName] = ref.Key
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
@@ -0,0 +1,82 @@
+package skill
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mackeh/AegisClaw/internal/guardrails"
+)
+
+// ErrLiteralSecret is returned for a manifest with a credential hardcoded
+// in a command's env or args. Manifests are shared and often signed and
+// published, so the secret would leak with them.
+var ErrLiteralSecret = errors.New("manifest contains a literal secret")
+
+// LiteralSecret locates a credential-shaped value in a manifest. It never
+// holds the value itself, so it is safe to print.
+type LiteralSecret struct {
+	Command string // command name
+	Field   string // e.g. "env[0]" or "args[2]"
+	EnvName string // the variable's name, for env entries
+}
+
+// String describes where the secret is and what to do instead.
+func (l LiteralSecret) String() string {
+	key := l.EnvName
+	if key == "" {
+		key = "<NAME>"
+	}
+	return fmt.Sprintf("command %q %s holds a literal secret; store it with 'aegisclaw secrets set %s <value>' and declare a secrets.access:%s scope instead",
+		l.Command, l.Field, key, key)
+}
+
+// LiteralSecrets scans command env values and args against the guardrails
+// secret patterns. Matches that reference a variable or placeholder, such
+// as PASSWORD=$DB_PASSWORD or {{.Args.token}}, are not secrets.
+func (m *Manifest) LiteralSecrets() []LiteralSecret {
+	var names []string
+	for name := range m.Commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var found []LiteralSecret
+	for _, name := range names {
+		c := m.Commands[name]
+		for i, kv := range c.Env {
+			if containsSecret(kv) {
+				envName, _, _ := strings.Cut(kv, "=")
+				found = append(found, LiteralSecret{Command: name, Field: fmt.Sprintf("env[%d]", i), EnvName: envName})
+			}
+		}
+		for i, a := range c.Args {
+			if containsSecret(a) {
+				found = append(found, LiteralSecret{Command: name, Field: fmt.Sprintf("args[%d]", i)})
+			}
+		}
+	}
+	return found
+}
+
+// checkLiteralSecrets returns ErrLiteralSecret describing the first literal
+// secret in m, if any.
+func (m *Manifest) checkLiteralSecrets() error {
+	found := m.LiteralSecrets()
+	if len(found) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrLiteralSecret, found[0])
+}
+
+func containsSecret(s string) bool {
+	for _, pat := range guardrails.SecretPatterns() {
+		for _, match := range pat.FindAllString(s, -1) {
+			if !strings.Contains(match, "$") && !strings.Contains(match, "{{") {
+				return true
+			}
+		}
+	}
+	return false
+}
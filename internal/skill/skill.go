@@ -7,26 +7,74 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
-	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/mackeh/AegisClaw/internal/audit"
 	"gopkg.in/yaml.v3"
 )
 
+// ErrDowngrade is returned when an install would replace a skill with an
+// older version and downgrades were not explicitly allowed.
+var ErrDowngrade = errors.New("skill downgrade refused")
+
+// InstallOptions tunes InstallSkillWithOptions.
+type InstallOptions struct {
+	// AllowDowngrade permits replacing an installed skill with an older
+	// registry version. Rollbacks are a supply-chain risk, so this is off
+	// by default.
+	AllowDowngrade bool
+	// AuditLogger, if set, records downgrade attempts, attributed to the
+	// CLI user.
+	AuditLogger *audit.Logger
+	// Auth, if set, authenticates requests to a private registry.
+	Auth *RegistryAuth
+}
+
 // Manifest represents a skill definition (skill.yaml)
 type Manifest struct {
 	Name        string             `yaml:"name"`
 	Version     string             `yaml:"version"`
 	Description string             `yaml:"description"`
 	Image       string             `yaml:"image"`
-	Platform    string             `yaml:"platform,omitempty"`     // "docker" (default) or "docker-compose"
+	Platform    string             `yaml:"platform,omitempty"`     // "docker" (default), "docker-compose" or "openclaw"
 	ComposeFile string             `yaml:"compose_file,omitempty"` // path to docker-compose.yml (relative to manifest)
 	Scopes      []string           `yaml:"scopes"`
 	Services    map[string]Service `yaml:"services,omitempty"` // per-service scope declarations for compose
 	Commands    map[string]Command `yaml:"commands"`
-	Signature   string             `yaml:"signature,omitempty"` // Ed25519 signature of the manifest content
+	// Capabilities lists Linux capabilities re-added on top of the sandbox's
+	// CapDrop ALL baseline, e.g. NET_BIND_SERVICE. Only a root user holds
+	// them, so a skill that lists any must also set user to root. The json
+	// tag keeps the signed canonical form unchanged for manifests that
+	// request none.
+	Capabilities []string `yaml:"capabilities,omitempty" json:"capabilities,omitempty"`
+	// Resources overrides the sandbox's default memory/CPU/PID limits.
+	Resources *Resources `yaml:"resources,omitempty" json:"resources,omitempty"`
+	// Outputs lists container paths under /aegisclaw/output collected into
+	// the run's artifacts directory after the command exits.
+	Outputs []string `yaml:"outputs,omitempty" json:"outputs,omitempty"`
+	// SecretDelivery chooses, per secrets.access key, how the secret
+	// reaches the skill: SecretEnv (default) or SecretFile, a read-only file
+	// under /run/secrets that stays out of docker inspect and
+	// /proc/<pid>/environ. Either is named after the scope's ENV_NAME when
+	// it maps one ("secrets.access:STORE_KEY->ENV_NAME").
+	SecretDelivery map[string]string `yaml:"secret_delivery,omitempty" json:"secret_delivery,omitempty"`
+	// User is the numeric "UID:GID" (or "UID") the skill's commands run as,
+	// for images whose app user is not 1000 or that need a specific group
+	// for a mounted volume. Empty uses security.sandbox_user.
+	User string `yaml:"user,omitempty" json:"user,omitempty"`
+	// Health, for skills that serve rather than exit, tells `run --detached`
+	// when the skill is ready.
+	Health *Health `yaml:"health,omitempty" json:"health,omitempty"`
+	// Hooks are setup and teardown commands run around every command.
+	Hooks *Hooks `yaml:"hooks,omitempty" json:"hooks,omitempty"`
+	// MinAegisClawVersion is the oldest AegisClaw release that understands
+	// this manifest; older binaries refuse to load or install it.
+	MinAegisClawVersion string `yaml:"min_aegisclaw_version,omitempty" json:"min_aegisclaw_version,omitempty"`
+	// Provenance links the skill to its source, SBOM and build attestation.
+	Provenance *Provenance `yaml:"provenance,omitempty" json:"provenance,omitempty"`
+	Signature  string      `yaml:"signature,omitempty"` // Ed25519 signature of the manifest content
 }
 
 // Service describes per-service configuration in a compose skill.
@@ -39,12 +87,20 @@ func (m *Manifest) IsCompose() bool {
 	return m.Platform == "docker-compose"
 }
 
+// IsOpenClaw returns true if this skill is backed by the OpenClaw adapter:
+// each command is a request to the configured OpenClaw endpoint, with Args
+// holding the HTTP method and path, instead of a container run.
+func (m *Manifest) IsOpenClaw() bool {
+	return m.Platform == "openclaw"
+}
+
 // RegistrySkill represents a skill available in the registry
 type RegistrySkill struct {
 	Name        string `json:"name"`
 	Version     string `json:"version"`
 	Description string `json:"description"`
 	ManifestURL string `json:"manifest_url"`
+	BundleURL   string `json:"bundle_url,omitempty"`
 }
 
 // RegistryIndex represents the registry search index
@@ -57,6 +113,9 @@ type RegistryIndex struct {
 type Command struct {
 	Args []string `yaml:"args"`
 	Env  []string `yaml:"env,omitempty"`
+	// Params declares the user arguments referenced by {{.Args.<name>}}
+	// placeholders in Args; see Render.
+	Params []Param `yaml:"params,omitempty" json:"params,omitempty"`
 }
 
 // LoadManifest reads and verifies a skill manifest
@@ -85,16 +144,77 @@ func LoadManifest(path string) (*Manifest, error) {
 	if m.Name == "" {
 		return nil, fmt.Errorf("invalid manifest: name is required")
 	}
-	if m.Image == "" && !m.IsCompose() {
+	if err := m.CheckMinVersion(); err != nil {
+		return nil, err
+	}
+	if m.Image == "" && !m.IsCompose() && !m.IsOpenClaw() {
 		return nil, fmt.Errorf("invalid manifest: image is required for non-compose skills")
 	}
 	if m.IsCompose() && m.ComposeFile == "" {
 		return nil, fmt.Errorf("invalid manifest: compose_file is required for docker-compose skills")
 	}
+	if err := m.Provenance.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if err := m.Health.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if err := m.Hooks.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	for name, c := range m.Commands {
+		if err := c.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid manifest: command %q: %w", name, err)
+		}
+	}
+	if err := m.validateSecretDelivery(); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if err := m.checkLiteralSecrets(); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
 
 	return &m, nil
 }
 
+// Secret delivery modes for Manifest.SecretDelivery.
+const (
+	SecretEnv  = "env"
+	SecretFile = "file"
+)
+
+// SecretMode returns how the secret key is delivered: SecretEnv unless the
+// manifest asks for SecretFile.
+func (m *Manifest) SecretMode(key string) string {
+	if m.SecretDelivery[key] == SecretFile {
+		return SecretFile
+	}
+	return SecretEnv
+}
+
+// validateSecretDelivery checks each mode and that it names a secret the
+// skill actually requests, catching typos that would silently fall back to
+// env injection.
+func (m *Manifest) validateSecretDelivery() error {
+	refs, err := m.SecretRefs()
+	if err != nil {
+		return err
+	}
+	requested := map[string]bool{}
+	for _, ref := range refs {
+		requested[ref.Key] = true
+	}
+	for key, mode := range m.SecretDelivery {
+		if mode != SecretEnv && mode != SecretFile {
+			return fmt.Errorf("secret_delivery for %s is %q (want env or file)", key, mode)
+		}
+		if !requested[key] {
+			return fmt.Errorf("secret_delivery names %s, which no secrets.access scope requests", key)
+		}
+	}
+	return nil
+}
+
 // ListSkills scans the given directory for skill manifests
 func ListSkills(dir string) ([]*Manifest, error) {
 	entries, err := os.ReadDir(dir)
@@ -159,16 +279,18 @@ func (m *Manifest) VerifySignature(trustKeys []string) (bool, error) {
 
 // SearchRegistry fetches the registry index
 func SearchRegistry(registryURL string) (*RegistryIndex, error) {
-	resp, err := http.Get(registryURL + "/index.json")
+	return SearchRegistryWithAuth(registryURL, nil)
+}
+
+// SearchRegistryWithAuth fetches the index of a registry that may require
+// authentication; a nil auth is anonymous.
+func SearchRegistryWithAuth(registryURL string, auth *RegistryAuth) (*RegistryIndex, error) {
+	resp, err := registryGet(registryURL+"/index.json", registryURL, auth)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch registry index: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("registry returned error: %s", resp.Status)
-	}
-
 	var index RegistryIndex
 	if err := json.NewDecoder(resp.Body).Decode(&index); err != nil {
 		return nil, fmt.Errorf("failed to decode registry index: %w", err)
@@ -179,11 +301,17 @@ func SearchRegistry(registryURL string) (*RegistryIndex, error) {
 
 // InstallSkill downloads and installs a skill from the registry
 func InstallSkill(skillName, destDir, registryURL string, trustKeys []string) error {
+	return InstallSkillWithOptions(skillName, destDir, registryURL, trustKeys, InstallOptions{})
+}
+
+// InstallSkillWithOptions downloads and installs a skill from the registry,
+// refusing to downgrade an installed skill unless opts.AllowDowngrade is set.
+func InstallSkillWithOptions(skillName, destDir, registryURL string, trustKeys []string, opts InstallOptions) error {
 	if err := validateSkillName(skillName); err != nil {
 		return err
 	}
 
-	index, err := SearchRegistry(registryURL)
+	index, err := SearchRegistryWithAuth(registryURL, opts.Auth)
 	if err != nil {
 		return err
 	}
@@ -200,8 +328,12 @@ func InstallSkill(skillName, destDir, registryURL string, trustKeys []string) er
 		return fmt.Errorf("skill '%s' not found in registry", skillName)
 	}
 
-	// Fetch Manifest
-	resp, err := http.Get(target.ManifestURL)
+	return installFromRegistry(skillName, target, destDir, registryURL, trustKeys, opts)
+}
+
+// installFromRegistry fetches and installs the manifest of an index entry.
+func installFromRegistry(skillName string, target *RegistrySkill, destDir, registryURL string, trustKeys []string, opts InstallOptions) error {
+	resp, err := registryGet(target.ManifestURL, registryURL, opts.Auth)
 	if err != nil {
 		return fmt.Errorf("failed to fetch manifest: %w", err)
 	}
@@ -212,6 +344,30 @@ func InstallSkill(skillName, destDir, registryURL string, trustKeys []string) er
 		return fmt.Errorf("failed to parse manifest from registry: %w", err)
 	}
 
+	return installManifest(skillName, &m, nil, destDir, trustKeys, opts)
+}
+
+// InstallSignedManifest installs a skill from the raw bytes of its signed
+// skill.yaml, such as a bundle pushed by a cluster leader, applying the same
+// signature, provenance and downgrade checks as a registry install.
+func InstallSignedManifest(data []byte, destDir string, trustKeys []string, opts InstallOptions) (*Manifest, error) {
+	var m Manifest
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse skill manifest: %w", err)
+	}
+	if err := validateSkillName(m.Name); err != nil {
+		return nil, err
+	}
+	if err := installManifest(m.Name, &m, data, destDir, trustKeys, opts); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// installManifest verifies m and saves it as skillName's skill.yaml under
+// destDir: raw verbatim if given, so the installed file still verifies,
+// otherwise m re-encoded.
+func installManifest(skillName string, m *Manifest, raw []byte, destDir string, trustKeys []string, opts InstallOptions) error {
 	// Verify Signature
 	valid, err := m.VerifySignature(trustKeys)
 	if err != nil {
@@ -221,6 +377,19 @@ func InstallSkill(skillName, destDir, registryURL string, trustKeys []string) er
 		return fmt.Errorf("SECURITY ALERT: Skill signature verification failed! Possible tampering detected")
 	}
 
+	if err := m.Provenance.Validate(); err != nil {
+		return fmt.Errorf("invalid manifest from registry: %w", err)
+	}
+	if err := m.checkLiteralSecrets(); err != nil {
+		return fmt.Errorf("invalid manifest from registry: %w", err)
+	}
+	if err := m.CheckMinVersion(); err != nil {
+		return err
+	}
+	if !m.HasProvenance() {
+		fmt.Printf("⚠️  Skill '%s' has no provenance metadata (source repo, SBOM or attestation)\n", skillName)
+	}
+
 	// Create directory and save
 	root, err := os.OpenRoot(destDir)
 	if err != nil {
@@ -232,12 +401,41 @@ func InstallSkill(skillName, destDir, registryURL string, trustKeys []string) er
 		return fmt.Errorf("failed to create skill directory: %w", err)
 	}
 
-	data, err := yaml.Marshal(m)
-	if err != nil {
-		return fmt.Errorf("failed to encode skill manifest: %w", err)
+	manifestPath := filepath.Join(skillName, "skill.yaml")
+
+	// Guard against silent rollbacks to an older (possibly vulnerable) version.
+	if existing, err := root.Open(manifestPath); err == nil {
+		var prev Manifest
+		decodeErr := yaml.NewDecoder(existing).Decode(&prev)
+		existing.Close()
+		if decodeErr == nil && prev.Version != "" && CompareVersions(m.Version, prev.Version) < 0 {
+			decision := "deny"
+			if opts.AllowDowngrade {
+				decision = "allow"
+			}
+			if opts.AuditLogger != nil {
+				_ = opts.AuditLogger.LogAs("skill.downgrade", nil, decision, audit.CLIActor(), map[string]any{
+					"skill":             skillName,
+					"installed_version": prev.Version,
+					"new_version":       m.Version,
+				})
+			}
+			if !opts.AllowDowngrade {
+				return fmt.Errorf("%w: '%s' v%s is installed, registry offers older v%s (use --allow-downgrade to proceed)",
+					ErrDowngrade, skillName, prev.Version, m.Version)
+			}
+			fmt.Printf("⚠️  Downgrading skill '%s' from v%s to v%s\n", skillName, prev.Version, m.Version)
+		}
+	}
+
+	data := raw
+	if data == nil {
+		var err error
+		if data, err = yaml.Marshal(m); err != nil {
+			return fmt.Errorf("failed to encode skill manifest: %w", err)
+		}
 	}
 
-	manifestPath := filepath.Join(skillName, "skill.yaml")
 	f, err := root.OpenFile(manifestPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
 	if err != nil {
 		return fmt.Errorf("failed to save skill manifest: %w", err)
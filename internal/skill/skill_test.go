@@ -4,8 +4,10 @@ import (
 	"crypto/ed25519"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -96,3 +98,197 @@ func TestValidateSkillName(t *testing.T) {
 		}
 	}
 }
+
+func TestLoadManifest_Provenance(t *testing.T) {
+	write := func(t *testing.T, provenance string) string {
+		t.Helper()
+		dir := t.TempDir()
+		path := filepath.Join(dir, "skill.yaml")
+		content := "name: test\nversion: \"1.0.0\"\nimage: alpine:latest\nscopes: []\n" + provenance
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	m, err := LoadManifest(write(t, "provenance:\n  source_repo: https://github.com/example/skill\n  commit: abc123\n  sbom: https://example.com/skill.spdx.json\n  attestation: https://example.com/skill.intoto.jsonl\n"))
+	if err != nil {
+		t.Fatalf("LoadManifest: %v", err)
+	}
+	if !m.HasProvenance() {
+		t.Fatal("expected provenance to be detected")
+	}
+	if m.Provenance.SourceRepo != "https://github.com/example/skill" || m.Provenance.Commit != "abc123" {
+		t.Errorf("unexpected provenance: %+v", m.Provenance)
+	}
+	if m.Provenance.SBOM != "https://example.com/skill.spdx.json" {
+		t.Errorf("sbom = %q", m.Provenance.SBOM)
+	}
+
+	if _, err := LoadManifest(write(t, "provenance:\n  sbom: not-a-url\n")); err == nil {
+		t.Error("expected malformed sbom URL to be rejected")
+	}
+	if _, err := LoadManifest(write(t, "provenance:\n  attestation: ftp://example.com/a\n")); err == nil {
+		t.Error("expected non-http attestation URL to be rejected")
+	}
+
+	m, err = LoadManifest(write(t, ""))
+	if err != nil {
+		t.Fatalf("LoadManifest: %v", err)
+	}
+	if m.HasProvenance() {
+		t.Error("manifest without provenance block reported provenance")
+	}
+}
+
+func TestLoadManifest_MinAegisClawVersion(t *testing.T) {
+	defer func(v string) { RunningVersion = v }(RunningVersion)
+	RunningVersion = "0.10.0"
+
+	write := func(t *testing.T, min string) string {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "skill.yaml")
+		content := "name: test\nversion: \"1.0.0\"\nimage: alpine:latest\nscopes: []\nmin_aegisclaw_version: \"" + min + "\"\n"
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	for _, min := range []string{"", "0.9.2", "0.10.0", "v0.10"} {
+		if _, err := LoadManifest(write(t, min)); err != nil {
+			t.Errorf("min_aegisclaw_version %q: unexpected error %v", min, err)
+		}
+	}
+
+	_, err := LoadManifest(write(t, "0.11.0"))
+	if !errors.Is(err, ErrAegisClawTooOld) {
+		t.Fatalf("expected ErrAegisClawTooOld, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "v0.11.0") || !strings.Contains(err.Error(), "aegisclaw upgrade") {
+		t.Errorf("error should name the required version and how to upgrade: %v", err)
+	}
+
+	RunningVersion = "dev"
+	if _, err := LoadManifest(write(t, "99.0.0")); err != nil {
+		t.Errorf("dev builds should accept any requirement: %v", err)
+	}
+}
+
+func TestLoadManifest_SecretDelivery(t *testing.T) {
+	write := func(t *testing.T, delivery string) string {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "skill.yaml")
+		content := "name: test\nversion: \"1.0.0\"\nimage: alpine:latest\nscopes:\n  - secrets.access:API_TOKEN\n  - secrets.access:DB_URL\n" + delivery
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	m, err := LoadManifest(write(t, "secret_delivery:\n  API_TOKEN: file\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.SecretMode("API_TOKEN") != SecretFile || m.SecretMode("DB_URL") != SecretEnv {
+		t.Errorf("modes = %s, %s; want file for API_TOKEN and the env default for DB_URL", m.SecretMode("API_TOKEN"), m.SecretMode("DB_URL"))
+	}
+
+	for name, delivery := range map[string]string{
+		"unknown mode":       "secret_delivery:\n  API_TOKEN: volume\n",
+		"unrequested secret": "secret_delivery:\n  API_TOKN: file\n",
+	} {
+		if _, err := LoadManifest(write(t, delivery)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestLoadManifest_LiteralSecret(t *testing.T) {
+	write := func(t *testing.T, commands string) string {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "skill.yaml")
+		content := "name: test\nversion: \"1.0.0\"\nimage: alpine:latest\ncommands:\n" + commands
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	const key = "sk-proj-4f1c9a7e2b8d6c0a1e3f5b7d"
+	_, err := LoadManifest(write(t, "  chat:\n    args: [\"python\", \"chat.py\"]\n    env: [\"MODE=prod\", \"OPENAI_API_KEY="+key+"\"]\n"))
+	if !errors.Is(err, ErrLiteralSecret) {
+		t.Fatalf("inline sk- env value: err = %v, want ErrLiteralSecret", err)
+	}
+	for _, want := range []string{`"chat" env[1]`, "secrets.access:OPENAI_API_KEY"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q should mention %s", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), key) {
+		t.Error("the error repeats the secret")
+	}
+
+	if _, err := LoadManifest(write(t, "  login:\n    args: [\"curl\", \"-H\", \"Authorization: Bearer "+key+"\"]\n")); !errors.Is(err, ErrLiteralSecret) {
+		t.Errorf("inline secret in args: err = %v, want ErrLiteralSecret", err)
+	}
+
+	// References to a variable or a param are fine.
+	ok := "  run:\n    args: [\"sh\", \"-c\", \"psql --password=$DB_PASSWORD\", \"{{.Args.token}}\"]\n    params: [{name: token}]\n    env: [\"PASSWORD=${DB_PASSWORD}\", \"LOG=debug\"]\n"
+	if _, err := LoadManifest(write(t, ok)); err != nil {
+		t.Errorf("manifest without literal secrets: %v", err)
+	}
+}
+
+func TestParseSecretRef(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    SecretRef
+		wantErr bool
+	}{
+		{"OPENAI_API_KEY", SecretRef{"OPENAI_API_KEY", "OPENAI_API_KEY"}, false},
+		{"PROD_OPENAI->OPENAI_API_KEY", SecretRef{"PROD_OPENAI", "OPENAI_API_KEY"}, false},
+		{"PROD_OPENAI -> OPENAI_API_KEY", SecretRef{"PROD_OPENAI", "OPENAI_API_KEY"}, false},
+		{"PROD_OPENAI->", SecretRef{}, true},
+		{"->OPENAI_API_KEY", SecretRef{}, true},
+		{"PROD_OPENAI->1BAD", SecretRef{}, true},
+		{"PROD_OPENAI->A=B", SecretRef{}, true},
+		{"PROD_OPENAI->A->B", SecretRef{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSecretRef(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseSecretRef(%q) = %+v, %v; want %+v, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestLoadManifest_SecretMapping(t *testing.T) {
+	write := func(t *testing.T, scopes string) string {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "skill.yaml")
+		content := "name: test\nversion: \"1.0.0\"\nimage: alpine:latest\nscopes:\n" + scopes
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	m, err := LoadManifest(write(t, "  - secrets.access:PROD_OPENAI->OPENAI_API_KEY\nsecret_delivery:\n  PROD_OPENAI: file\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refs, _ := m.SecretRefs(); len(refs) != 1 || refs[0].EnvName != "OPENAI_API_KEY" {
+		t.Errorf("refs = %+v", refs)
+	}
+
+	for name, scopes := range map[string]string{
+		"bad env name":   "  - secrets.access:PROD_OPENAI->OPENAI-API-KEY\n",
+		"empty key":      "  - secrets.access:->OPENAI_API_KEY\n",
+		"same env twice": "  - secrets.access:PROD_OPENAI->OPENAI_API_KEY\n  - secrets.access:DEV_OPENAI->OPENAI_API_KEY\n",
+	} {
+		if _, err := LoadManifest(write(t, scopes)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
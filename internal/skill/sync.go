@@ -0,0 +1,167 @@
+package skill
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/mackeh/AegisClaw/internal/config"
+)
+
+// Sync actions reported in SyncResult.Action.
+const (
+	SyncInstall    = "install"    // declared but not installed
+	SyncUpdate     = "update"     // installed at another version
+	SyncReinstall  = "reinstall"  // installed copy fails verification
+	SyncUnchanged  = "unchanged"  // installed at the wanted version
+	SyncPrune      = "prune"      // undeclared and removed
+	SyncUndeclared = "undeclared" // undeclared, kept without --prune
+)
+
+// SyncTarget is a declared skill resolved to the registry it installs from.
+type SyncTarget struct {
+	Name string
+	// Version pins an exact release; empty follows the registry.
+	Version     string
+	RegistryURL string
+	// Auth holds the registry's credentials, if it has any.
+	Auth *RegistryAuth
+}
+
+// SyncOptions tunes Sync. InstallOptions.Auth is ignored in favour of each
+// target's own.
+type SyncOptions struct {
+	InstallOptions
+	// Prune removes installed skills that are not declared.
+	Prune bool
+}
+
+// SyncResult is what Sync did, or failed to do, for one skill.
+type SyncResult struct {
+	Name   string
+	Action string
+	From   string // installed version, if any
+	To     string // version installed by this sync, if any
+	Err    error
+}
+
+// SyncTargets resolves the skills declared in cfg. auth, the credentials for
+// registry.url, is only attached to skills coming from that host.
+func SyncTargets(cfg *config.Config, auth *RegistryAuth) ([]SyncTarget, error) {
+	targets := make([]SyncTarget, 0, len(cfg.Skills))
+	for _, s := range cfg.Skills {
+		url, err := cfg.SkillRegistryURL(s)
+		if err != nil {
+			return nil, err
+		}
+		t := SyncTarget{Name: s.Name, Version: s.Version, RegistryURL: url}
+		if sameHost(url, cfg.Registry.URL) {
+			t.Auth = auth
+		}
+		targets = append(targets, t)
+	}
+	return targets, nil
+}
+
+// Sync reconciles the skills installed in destDir with targets: missing
+// skills are installed, skills at another version than the registry's (or
+// the pinned one) are updated, and installed copies whose signature no
+// longer verifies are reinstalled. Every install goes through the usual
+// signature, provenance and downgrade checks. With opts.Prune, installed
+// skills that are not declared are removed.
+//
+// A failure for one skill is recorded in its result and does not stop the
+// others.
+func Sync(targets []SyncTarget, destDir string, trustKeys []string, opts SyncOptions) ([]SyncResult, error) {
+	if opts.Prune && len(targets) == 0 {
+		return nil, fmt.Errorf("no skills are declared; refusing to prune every installed skill")
+	}
+	if err := os.MkdirAll(destDir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create skills directory: %w", err)
+	}
+
+	indexes := map[string]*RegistryIndex{}
+	declared := map[string]bool{}
+	var results []SyncResult
+	for _, t := range targets {
+		declared[t.Name] = true
+		results = append(results, syncOne(t, indexes, destDir, trustKeys, opts.InstallOptions))
+	}
+
+	installed, _ := ListSkills(destDir)
+	for _, m := range installed {
+		if declared[m.Name] {
+			continue
+		}
+		res := SyncResult{Name: m.Name, Action: SyncUndeclared, From: m.Version}
+		if opts.Prune {
+			res.Action = SyncPrune
+			res.Err = RemoveSkill(m.Name, destDir, opts.AuditLogger)
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// syncOne brings one declared skill to its wanted version, fetching each
+// registry's index once per sync.
+func syncOne(t SyncTarget, indexes map[string]*RegistryIndex, destDir string, trustKeys []string, opts InstallOptions) (res SyncResult) {
+	res = SyncResult{Name: t.Name}
+	if err := validateSkillName(t.Name); err != nil {
+		res.Err = err
+		return res
+	}
+
+	index, ok := indexes[t.RegistryURL]
+	if !ok {
+		var err error
+		if index, err = SearchRegistryWithAuth(t.RegistryURL, t.Auth); err != nil {
+			res.Err = err
+			return res
+		}
+		indexes[t.RegistryURL] = index
+	}
+	var entry *RegistrySkill
+	for i := range index.Skills {
+		if index.Skills[i].Name == t.Name {
+			entry = &index.Skills[i]
+			break
+		}
+	}
+	if entry == nil {
+		res.Err = fmt.Errorf("skill '%s' not found in registry %s", t.Name, t.RegistryURL)
+		return res
+	}
+	if t.Version != "" && CompareVersions(entry.Version, t.Version) != 0 {
+		res.Err = fmt.Errorf("config pins v%s but registry %s offers v%s", t.Version, t.RegistryURL, entry.Version)
+		return res
+	}
+
+	current, err := LoadManifest(filepath.Join(destDir, t.Name, "skill.yaml"))
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		res.Action = SyncInstall
+	case err != nil:
+		res.Action = SyncReinstall
+	default:
+		res.From = current.Version
+		if valid, _ := current.VerifySignature(trustKeys); !valid {
+			res.Action = SyncReinstall
+		} else if cmp := CompareVersions(entry.Version, current.Version); cmp > 0 || (cmp < 0 && t.Version != "") {
+			// Only a pin moves a skill back; the downgrade guard
+			// still applies.
+			res.Action = SyncUpdate
+		} else {
+			res.Action = SyncUnchanged
+			return res
+		}
+	}
+
+	opts.Auth = t.Auth
+	if res.Err = installFromRegistry(t.Name, entry, destDir, t.RegistryURL, trustKeys, opts); res.Err == nil {
+		res.To = entry.Version
+	}
+	return res
+}
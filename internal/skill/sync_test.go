@@ -0,0 +1,209 @@
+package skill
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mackeh/AegisClaw/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// syncRegistry serves signed manifests for every skill in versions, which
+// tests may change between syncs.
+func syncRegistry(t *testing.T, versions map[string]string) (*httptest.Server, string) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/index.json" {
+			var index RegistryIndex
+			for name, v := range versions {
+				index.Skills = append(index.Skills, RegistrySkill{Name: name, Version: v, ManifestURL: srv.URL + "/" + name + ".yaml"})
+			}
+			json.NewEncoder(w).Encode(index)
+			return
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/"), ".yaml")
+		v, ok := versions[name]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		m := Manifest{Name: name, Version: v, Image: "alpine:latest", Scopes: []string{}, Commands: map[string]Command{}}
+		data, _ := json.Marshal(m)
+		m.Signature = hex.EncodeToString(ed25519.Sign(priv, data))
+		yaml.NewEncoder(w).Encode(m)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, hex.EncodeToString(pub)
+}
+
+func syncResult(t *testing.T, results []SyncResult, name string) SyncResult {
+	t.Helper()
+	for _, r := range results {
+		if r.Name == name {
+			return r
+		}
+	}
+	t.Fatalf("no sync result for %s in %+v", name, results)
+	return SyncResult{}
+}
+
+func TestSync_InstallsMissingDeclaredSkill(t *testing.T) {
+	versions := map[string]string{"fetcher": "1.0.0", "other": "2.0.0"}
+	srv, key := syncRegistry(t, versions)
+	dir := filepath.Join(t.TempDir(), "skills")
+
+	targets := []SyncTarget{{Name: "fetcher", RegistryURL: srv.URL}}
+	results, err := Sync(targets, dir, []string{key}, SyncOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := syncResult(t, results, "fetcher"); r.Action != SyncInstall || r.To != "1.0.0" || r.Err != nil {
+		t.Errorf("result = %+v, want fetcher installed at 1.0.0", r)
+	}
+	m, err := LoadManifest(filepath.Join(dir, "fetcher", "skill.yaml"))
+	if err != nil || m.Version != "1.0.0" {
+		t.Fatalf("installed manifest = %+v, %v", m, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "other")); !os.IsNotExist(err) {
+		t.Error("an undeclared registry skill was installed")
+	}
+
+	// A second sync has nothing to do; a newer release is then picked up.
+	results, _ = Sync(targets, dir, []string{key}, SyncOptions{})
+	if r := syncResult(t, results, "fetcher"); r.Action != SyncUnchanged {
+		t.Errorf("second sync = %+v, want unchanged", r)
+	}
+	versions["fetcher"] = "1.1.0"
+	results, _ = Sync(targets, dir, []string{key}, SyncOptions{})
+	if r := syncResult(t, results, "fetcher"); r.Action != SyncUpdate || r.From != "1.0.0" || r.To != "1.1.0" {
+		t.Errorf("sync after release = %+v, want an update to 1.1.0", r)
+	}
+}
+
+func TestSync_PruneRemovesUndeclaredSkill(t *testing.T) {
+	srv, key := syncRegistry(t, map[string]string{"fetcher": "1.0.0", "legacy": "0.9.0"})
+	dir := t.TempDir()
+	for _, name := range []string{"fetcher", "legacy"} {
+		if err := InstallSkill(name, dir, srv.URL, []string{key}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	targets := []SyncTarget{{Name: "fetcher", RegistryURL: srv.URL}}
+
+	results, err := Sync(targets, dir, []string{key}, SyncOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := syncResult(t, results, "legacy"); r.Action != SyncUndeclared {
+		t.Errorf("without prune, legacy = %+v, want it reported and kept", r)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "legacy")); err != nil {
+		t.Fatalf("legacy removed without --prune: %v", err)
+	}
+
+	results, err = Sync(targets, dir, []string{key}, SyncOptions{Prune: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := syncResult(t, results, "legacy"); r.Action != SyncPrune || r.Err != nil {
+		t.Errorf("legacy = %+v, want it pruned", r)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "legacy")); !os.IsNotExist(err) {
+		t.Error("legacy is still installed after prune")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "fetcher", "skill.yaml")); err != nil {
+		t.Errorf("declared skill was pruned: %v", err)
+	}
+
+	if _, err := Sync(nil, dir, []string{key}, SyncOptions{Prune: true}); err == nil {
+		t.Error("expected prune with nothing declared to be refused")
+	}
+}
+
+func TestSync_VerifiesSignatures(t *testing.T) {
+	versions := map[string]string{"fetcher": "1.0.0"}
+	srv, key := syncRegistry(t, versions)
+	dir := t.TempDir()
+	targets := []SyncTarget{{Name: "fetcher", RegistryURL: srv.URL}}
+
+	_, otherKey := syncRegistry(t, versions)
+	results, _ := Sync(targets, dir, []string{otherKey}, SyncOptions{})
+	if r := syncResult(t, results, "fetcher"); r.Err == nil || !strings.Contains(r.Err.Error(), "signature") {
+		t.Errorf("untrusted signer = %+v, want a signature failure", r)
+	}
+
+	if _, err := Sync(targets, dir, []string{key}, SyncOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "fetcher", "skill.yaml")
+	data, _ := os.ReadFile(path)
+	tampered := strings.Replace(string(data), "alpine:latest", "evil:latest", 1)
+	if err := os.WriteFile(path, []byte(tampered), 0600); err != nil {
+		t.Fatal(err)
+	}
+	results, _ = Sync(targets, dir, []string{key}, SyncOptions{})
+	if r := syncResult(t, results, "fetcher"); r.Action != SyncReinstall || r.Err != nil {
+		t.Errorf("tampered copy = %+v, want it reinstalled", r)
+	}
+	if m, err := LoadManifest(path); err != nil || m.Image != "alpine:latest" {
+		t.Errorf("reinstalled manifest = %+v, %v", m, err)
+	}
+}
+
+func TestSync_PinnedVersion(t *testing.T) {
+	versions := map[string]string{"fetcher": "1.1.0"}
+	srv, key := syncRegistry(t, versions)
+	dir := t.TempDir()
+
+	results, _ := Sync([]SyncTarget{{Name: "fetcher", Version: "1.0.0", RegistryURL: srv.URL}}, dir, []string{key}, SyncOptions{})
+	if r := syncResult(t, results, "fetcher"); r.Err == nil || !strings.Contains(r.Err.Error(), "pins v1.0.0") {
+		t.Errorf("pin mismatch = %+v, want an error", r)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "fetcher")); !os.IsNotExist(err) {
+		t.Error("a skill was installed at a version other than the pinned one")
+	}
+
+	results, _ = Sync([]SyncTarget{{Name: "fetcher", Version: "1.1.0", RegistryURL: srv.URL}}, dir, []string{key}, SyncOptions{})
+	if r := syncResult(t, results, "fetcher"); r.Action != SyncInstall || r.Err != nil {
+		t.Errorf("matching pin = %+v, want an install", r)
+	}
+}
+
+func TestSyncTargets(t *testing.T) {
+	cfg := &config.Config{
+		Registry: config.RegistryConfig{
+			URL:     "https://registry.example.com",
+			Sources: []config.RegistrySource{{Name: "internal", URL: "https://skills.corp.example"}},
+		},
+		Skills: []config.SkillSpec{
+			{Name: "fetcher", Version: "1.2.0"},
+			{Name: "builder", Registry: "internal"},
+		},
+	}
+	auth := &RegistryAuth{Token: "t0ken"}
+	targets, err := SyncTargets(cfg, auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(targets) != 2 {
+		t.Fatalf("targets = %+v", targets)
+	}
+	if f := targets[0]; f.RegistryURL != cfg.Registry.URL || f.Version != "1.2.0" || f.Auth != auth {
+		t.Errorf("fetcher target = %+v", f)
+	}
+	if b := targets[1]; b.RegistryURL != "https://skills.corp.example" || b.Auth != nil {
+		t.Errorf("builder target = %+v, want the internal source without registry.url's credentials", b)
+	}
+}
@@ -0,0 +1,185 @@
+package skill
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Param declares a user argument a command accepts. User arguments are
+// matched to params by position, so the first argument is params[0].
+type Param struct {
+	Name string `yaml:"name" json:"name"`
+	// Pattern, if set, is a regular expression the whole value must match.
+	Pattern string `yaml:"pattern,omitempty" json:"pattern,omitempty"`
+	// Optional params may be omitted; their placeholders render as "".
+	Optional bool `yaml:"optional,omitempty" json:"optional,omitempty"`
+}
+
+var paramName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// placeholder matches {{.Args.0}} (positional) and {{.Args.name}} (named).
+var placeholder = regexp.MustCompile(`\{\{\s*\.Args\.([A-Za-z_][A-Za-z0-9_]*|[0-9]+)\s*\}\}`)
+
+// shells are interpreters whose -c script is re-parsed into tokens, so
+// values substituted into it are shell-quoted.
+var shells = map[string]bool{"sh": true, "bash": true, "ash": true, "dash": true, "zsh": true}
+
+// IsTemplated reports whether any of the command's args contain a
+// placeholder. Templated commands consume user arguments through their
+// placeholders instead of having them appended.
+func (c Command) IsTemplated() bool {
+	for _, a := range c.Args {
+		if placeholder.MatchString(a) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that params are well formed and that every named
+// placeholder refers to a declared param.
+func (c Command) Validate() error {
+	seen := map[string]bool{}
+	for _, p := range c.Params {
+		if !paramName.MatchString(p.Name) {
+			return fmt.Errorf("invalid param name %q", p.Name)
+		}
+		if seen[p.Name] {
+			return fmt.Errorf("duplicate param %q", p.Name)
+		}
+		seen[p.Name] = true
+		if p.Pattern != "" {
+			if _, err := regexp.Compile(p.Pattern); err != nil {
+				return fmt.Errorf("param %q: invalid pattern: %w", p.Name, err)
+			}
+		}
+	}
+	for i, a := range c.Args {
+		for _, m := range placeholder.FindAllStringSubmatch(a, -1) {
+			if _, err := strconv.Atoi(m[1]); err != nil && !seen[m[1]] {
+				return fmt.Errorf("placeholder %s refers to undeclared param %q", m[0], m[1])
+			}
+		}
+		if c.isScript(i) {
+			for _, loc := range placeholder.FindAllStringIndex(a, -1) {
+				if quotedAt(a, loc[0]) {
+					return fmt.Errorf("placeholder %s in a shell script must not be quoted; values are quoted automatically", a[loc[0]:loc[1]])
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// isScript reports whether Args[i] is the script of a "<shell> -c" command.
+func (c Command) isScript(i int) bool {
+	return i > 1 && c.Args[i-1] == "-c" && shells[path.Base(c.Args[0])]
+}
+
+// quotedAt reports whether byte offset pos of a shell script lies inside
+// single or double quotes.
+func quotedAt(script string, pos int) bool {
+	var quote byte
+	for i := 0; i < pos; i++ {
+		switch ch := script[i]; {
+		case quote != '\'' && ch == '\\':
+			i++ // escaped character
+		case quote == 0 && (ch == '\'' || ch == '"'):
+			quote = ch
+		case ch == quote:
+			quote = 0
+		}
+	}
+	return quote != 0
+}
+
+// Render builds the argv for a run. Untemplated commands get userArgs
+// appended, as before. Templated commands substitute each placeholder
+// with its user argument, validated against Params:
+//
+//   - a value only ever fills its own argv element and is never split, so
+//     it cannot add arguments;
+//   - a value filling a whole element may not start with "-", so it cannot
+//     be read as an option;
+//   - inside a shell -c script the value is single-quoted, so the shell
+//     sees exactly one word (Validate rejects placeholders the script
+//     quotes itself);
+//   - NUL and newlines are rejected outright.
+func (c Command) Render(userArgs []string) ([]string, error) {
+	if !c.IsTemplated() {
+		return append(append([]string{}, c.Args...), userArgs...), nil
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	values, err := c.bindParams(userArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]string, 0, len(c.Args))
+	for i, a := range c.Args {
+		script := c.isScript(i)
+		var renderErr error
+		rendered := placeholder.ReplaceAllStringFunc(a, func(ph string) string {
+			key := placeholder.FindStringSubmatch(ph)[1]
+			val, ok := values[key]
+			if !ok {
+				renderErr = fmt.Errorf("missing argument for %s", ph)
+				return ""
+			}
+			if strings.ContainsAny(val, "\x00\n\r") {
+				renderErr = fmt.Errorf("argument for %s contains a control character", ph)
+				return ""
+			}
+			if script {
+				return shellQuote(val)
+			}
+			if strings.TrimSpace(a) == ph && strings.HasPrefix(val, "-") {
+				renderErr = fmt.Errorf("argument for %s may not start with '-'", ph)
+				return ""
+			}
+			return val
+		})
+		if renderErr != nil {
+			return nil, renderErr
+		}
+		out = append(out, rendered)
+	}
+	return out, nil
+}
+
+// bindParams maps user arguments to placeholder keys: every argument by
+// position, and declared params also by name.
+func (c Command) bindParams(userArgs []string) (map[string]string, error) {
+	if len(c.Params) > 0 && len(userArgs) > len(c.Params) {
+		return nil, fmt.Errorf("too many arguments: got %d, command accepts %d", len(userArgs), len(c.Params))
+	}
+	values := make(map[string]string, len(userArgs)*2)
+	for i, v := range userArgs {
+		values[strconv.Itoa(i)] = v
+	}
+	for i, p := range c.Params {
+		if i >= len(userArgs) {
+			if !p.Optional {
+				return nil, fmt.Errorf("missing required argument %q", p.Name)
+			}
+			values[p.Name] = ""
+			continue
+		}
+		v := userArgs[i]
+		if p.Pattern != "" && !regexp.MustCompile(`^(?:`+p.Pattern+`)$`).MatchString(v) {
+			return nil, fmt.Errorf("argument %q does not match pattern %s", p.Name, p.Pattern)
+		}
+		values[p.Name] = v
+	}
+	return values, nil
+}
+
+// shellQuote single-quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,103 @@
+package skill
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRender_Positional(t *testing.T) {
+	c := Command{Args: []string{"curl", "-sS", "--url", "{{.Args.0}}", "-o", "/tmp/{{ .Args.1 }}.html"}}
+	got, err := c.Render([]string{"https://example.com/a b", "page"})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	want := []string{"curl", "-sS", "--url", "https://example.com/a b", "-o", "/tmp/page.html"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Render = %q, want %q", got, want)
+	}
+}
+
+func TestRender_Named(t *testing.T) {
+	c := Command{
+		Args:   []string{"fetch", "--url={{.Args.url}}", "--depth", "{{.Args.depth}}"},
+		Params: []Param{{Name: "url", Pattern: `https://\S+`}, {Name: "depth", Pattern: `[0-9]+`, Optional: true}},
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.Render([]string{"https://example.com", "2"})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if want := []string{"fetch", "--url=https://example.com", "--depth", "2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Render = %q, want %q", got, want)
+	}
+
+	for _, args := range [][]string{
+		{"http://plain.example"},             // pattern mismatch
+		{"https://example.com", "2; ls"},     // pattern must match the whole value
+		{},                                   // missing required
+		{"https://example.com", "1", "xtra"}, // more args than params
+	} {
+		if _, err := c.Render(args); err == nil {
+			t.Errorf("Render(%q) should fail", args)
+		}
+	}
+}
+
+func TestRender_UntemplatedAppends(t *testing.T) {
+	c := Command{Args: []string{"echo", "hi"}}
+	got, err := c.Render([]string{"there"})
+	if err != nil || !reflect.DeepEqual(got, []string{"echo", "hi", "there"}) {
+		t.Fatalf("Render = %q, %v", got, err)
+	}
+	if len(c.Args) != 2 {
+		t.Error("Render must not modify the manifest's args")
+	}
+}
+
+func TestRender_MaliciousArgCannotBreakOut(t *testing.T) {
+	c := Command{Args: []string{"grep", "-r", "{{.Args.0}}", "/data"}}
+	got, err := c.Render([]string{"foo /etc/passwd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 4 || got[2] != "foo /etc/passwd" {
+		t.Errorf("value must stay one argument, got %q", got)
+	}
+	if _, err := c.Render([]string{"--include=/etc/shadow"}); err == nil {
+		t.Error("a value that would be parsed as an option should be rejected")
+	}
+	if _, err := c.Render([]string{"x\nrm -rf /"}); err == nil {
+		t.Error("newlines should be rejected")
+	}
+
+	sh := Command{Args: []string{"/bin/sh", "-c", "echo {{.Args.0}} > /aegisclaw/output/out"}}
+	got, err = sh.Render([]string{"x'; rm -rf / #"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `echo 'x'\''; rm -rf / #' > /aegisclaw/output/out`; got[2] != want {
+		t.Errorf("script = %q, want %q", got[2], want)
+	}
+}
+
+func TestValidate_Templates(t *testing.T) {
+	bad := []Command{
+		{Args: []string{"x", "{{.Args.url}}"}},                            // undeclared
+		{Args: []string{"x"}, Params: []Param{{Name: "a"}, {Name: "a"}}},  // duplicate
+		{Args: []string{"x"}, Params: []Param{{Name: "0"}}},               // numeric name
+		{Args: []string{"x"}, Params: []Param{{Name: "a", Pattern: "("}}}, // bad pattern
+		{Args: []string{"sh", "-c", `echo "{{.Args.0}}"`}},                // quoted in script
+		{Args: []string{"bash", "-c", "echo '{{.Args.0}}'"}},              // quoted in script
+	}
+	for _, c := range bad {
+		if err := c.Validate(); err == nil {
+			t.Errorf("Validate(%q) should fail", c.Args)
+		}
+	}
+	ok := Command{Args: []string{"sh", "-c", `echo "a" {{.Args.0}} 'b'`}}
+	if err := ok.Validate(); err != nil {
+		t.Errorf("unquoted placeholder rejected: %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package skill
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RunningVersion is the AegisClaw version manifests' min_aegisclaw_version
+// is checked against. The CLI sets it from its build version; "dev" builds
+// accept every manifest.
+var RunningVersion = "dev"
+
+// ErrAegisClawTooOld is returned for a skill that needs a newer AegisClaw.
+var ErrAegisClawTooOld = errors.New("skill requires a newer AegisClaw")
+
+// CheckMinVersion refuses m if it declares a min_aegisclaw_version newer
+// than RunningVersion.
+func (m *Manifest) CheckMinVersion() error {
+	if m.MinAegisClawVersion == "" || RunningVersion == "dev" {
+		return nil
+	}
+	if CompareVersions(RunningVersion, m.MinAegisClawVersion) < 0 {
+		return fmt.Errorf("%w: '%s' needs AegisClaw v%s or later, this is v%s (run 'aegisclaw upgrade')",
+			ErrAegisClawTooOld, m.Name, strings.TrimPrefix(m.MinAegisClawVersion, "v"), RunningVersion)
+	}
+	return nil
+}
+
+// CompareVersions compares two dotted versions ("1.2.3", "v1.2", "2.0.0-rc1")
+// and returns -1, 0, or 1. Numeric components are compared numerically;
+// missing components count as zero; a pre-release suffix sorts before the
+// release it precedes. Non-numeric components fall back to string order.
+func CompareVersions(a, b string) int {
+	aCore, aPre := splitVersion(a)
+	bCore, bPre := splitVersion(b)
+
+	aParts := strings.Split(aCore, ".")
+	bParts := strings.Split(bCore, ".")
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		ap, bp := "0", "0"
+		if i < len(aParts) && aParts[i] != "" {
+			ap = aParts[i]
+		}
+		if i < len(bParts) && bParts[i] != "" {
+			bp = bParts[i]
+		}
+		if c := compareComponent(ap, bp); c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+	return strings.Compare(aPre, bPre)
+}
+
+func splitVersion(v string) (core, pre string) {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.IndexByte(v, '+'); i >= 0 {
+		v = v[:i] // build metadata does not affect precedence
+	}
+	if i := strings.IndexByte(v, '-'); i >= 0 {
+		return v[:i], v[i+1:]
+	}
+	return v, ""
+}
+
+func compareComponent(a, b string) int {
+	an, aErr := strconv.Atoi(a)
+	bn, bErr := strconv.Atoi(b)
+	if aErr == nil && bErr == nil {
+		switch {
+		case an < bn:
+			return -1
+		case an > bn:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(a, b)
+}
@@ -0,0 +1,77 @@
+package telemetry
+
+import "sync"
+
+// DefaultMaxLabelValues bounds each open-ended metric label (skill names,
+// egress domains) when telemetry.max_label_values is unset.
+const DefaultMaxLabelValues = 100
+
+// OtherLabel is the value new label values collapse into once a label has
+// reached its limit.
+const OtherLabel = "other"
+
+// LabelLimiter bounds the distinct values a metric label can take, so a
+// host running many skills or reaching many domains cannot blow up the
+// /metrics series count. The first values seen keep their own series;
+// later new ones are counted under OtherLabel.
+type LabelLimiter struct {
+	mu   sync.Mutex
+	max  int
+	seen map[string]bool
+}
+
+// NewLabelLimiter returns a limiter allowing max distinct values; max <= 0
+// means DefaultMaxLabelValues.
+func NewLabelLimiter(max int) *LabelLimiter {
+	l := &LabelLimiter{seen: map[string]bool{}}
+	l.SetMax(max)
+	return l
+}
+
+// SetMax changes the limit. Values already admitted keep their series.
+func (l *LabelLimiter) SetMax(max int) {
+	if max <= 0 {
+		max = DefaultMaxLabelValues
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.max = max
+}
+
+// Value returns v if it already has a series or there is room for one,
+// otherwise OtherLabel.
+func (l *LabelLimiter) Value(v string) string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.seen[v] {
+		return v
+	}
+	if len(l.seen) >= l.max {
+		return OtherLabel
+	}
+	l.seen[v] = true
+	return v
+}
+
+var (
+	skillLabels  = NewLabelLimiter(0)
+	domainLabels = NewLabelLimiter(0)
+)
+
+// SetMaxLabelValues applies telemetry.max_label_values to the skill and
+// domain labels.
+func SetMaxLabelValues(max int) {
+	skillLabels.SetMax(max)
+	domainLabels.SetMax(max)
+}
+
+// SkillLabel returns the bounded "skill" label value for a skill name.
+func SkillLabel(name string) string {
+	return skillLabels.Value(name)
+}
+
+// DomainLabel returns the bounded "domain" label value for an egress
+// domain.
+func DomainLabel(domain string) string {
+	return domainLabels.Value(domain)
+}
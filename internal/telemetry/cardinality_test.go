@@ -0,0 +1,49 @@
+package telemetry
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestLabelLimiter_CollapsesIntoOther(t *testing.T) {
+	l := NewLabelLimiter(2)
+	vec := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test_total", Help: "test"}, []string{"skill"})
+
+	for _, name := range []string{"alpha", "beta", "gamma", "delta", "alpha"} {
+		vec.WithLabelValues(l.Value(name)).Inc()
+	}
+
+	if n := testutil.CollectAndCount(vec); n != 3 {
+		t.Errorf("series = %d, want 3 (alpha, beta, other)", n)
+	}
+	if got := testutil.ToFloat64(vec.WithLabelValues("alpha")); got != 2 {
+		t.Errorf("alpha = %v, want 2: admitted values keep their series", got)
+	}
+	if got := testutil.ToFloat64(vec.WithLabelValues(OtherLabel)); got != 2 {
+		t.Errorf("other = %v, want 2 (gamma, delta)", got)
+	}
+
+	// Raising the limit admits new values; existing ones are untouched.
+	l.SetMax(3)
+	if got := l.Value("gamma"); got != "gamma" {
+		t.Errorf("after raising the limit, gamma = %q", got)
+	}
+	if got := l.Value("epsilon"); got != OtherLabel {
+		t.Errorf("beyond the raised limit, epsilon = %q", got)
+	}
+}
+
+func TestLabelLimiter_Default(t *testing.T) {
+	l := NewLabelLimiter(0)
+	for i := 0; i < DefaultMaxLabelValues; i++ {
+		if v := l.Value(fmt.Sprintf("skill-%d", i)); v == OtherLabel {
+			t.Fatalf("value %d collapsed below the default limit", i)
+		}
+	}
+	if v := l.Value("one-too-many"); v != OtherLabel {
+		t.Errorf("value beyond the default limit = %q, want %q", v, OtherLabel)
+	}
+}
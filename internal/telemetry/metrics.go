@@ -44,6 +44,27 @@ var (
 		[]string{"decision"},
 	)
 
+	// ProxyRequestDuration tracks egress proxy latency per allowed domain:
+	// until response headers for HTTP, until the tunnel is up for CONNECT
+	ProxyRequestDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "aegisclaw_proxy_request_seconds",
+			Help:    "Duration of proxied egress requests per allowed domain",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"domain"},
+	)
+
+	// ProxyResponsesTotal tracks egress proxy responses per allowed domain
+	// and status code
+	ProxyResponsesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "aegisclaw_proxy_responses_total",
+			Help: "Total number of proxied egress responses by status code",
+		},
+		[]string{"domain", "code"},
+	)
+
 	// ActiveExecutions tracks the number of currently running skill executions
 	ActiveExecutions = promauto.NewGauge(
 		prometheus.GaugeOpts{
@@ -52,6 +73,15 @@ var (
 		},
 	)
 
+	// QueuedExecutions tracks skill executions waiting for a free slot under
+	// agent.max_concurrent_runs
+	QueuedExecutions = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "aegisclaw_queued_executions",
+			Help: "Number of skill executions waiting for a concurrency slot",
+		},
+	)
+
 	// ComplianceScore tracks the current OWASP ASI compliance score
 	ComplianceScore = promauto.NewGauge(
 		prometheus.GaugeOpts{
@@ -12,8 +12,21 @@ import (
 	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 )
 
+// Options tunes the tracer provider.
+type Options struct {
+	// SampleRatio is the fraction of new traces recorded, from
+	// telemetry.sample_ratio. Zero, or anything >= 1, records every trace.
+	// Child spans follow their parent's decision.
+	SampleRatio float64
+}
+
 // Setup initializes the OpenTelemetry tracer provider.
 func Setup(ctx context.Context, serviceName, version string, enabled bool, writer io.Writer) (func(context.Context) error, error) {
+	return SetupWithOptions(ctx, serviceName, version, enabled, writer, Options{})
+}
+
+// SetupWithOptions is Setup with sampling control.
+func SetupWithOptions(ctx context.Context, serviceName, version string, enabled bool, writer io.Writer, opts Options) (func(context.Context) error, error) {
 	if !enabled {
 		return func(context.Context) error { return nil }, nil
 	}
@@ -47,6 +60,7 @@ func Setup(ctx context.Context, serviceName, version string, enabled bool, write
 	tp := sdktrace.NewTracerProvider(
 		sdktrace.WithBatcher(exporter),
 		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sampler(opts.SampleRatio)),
 	)
 
 	// Set global TracerProvider
@@ -54,3 +68,12 @@ func Setup(ctx context.Context, serviceName, version string, enabled bool, write
 
 	return tp.Shutdown, nil
 }
+
+// sampler records ratio of root traces, or all of them for ratio <= 0 or
+// >= 1.
+func sampler(ratio float64) sdktrace.Sampler {
+	if ratio <= 0 || ratio >= 1 {
+		return sdktrace.ParentBased(sdktrace.AlwaysSample())
+	}
+	return sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))
+}
